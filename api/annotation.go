@@ -0,0 +1,38 @@
+package api
+
+// DomainOwnerAnnotation is the annotation on CustomDomain referencing the
+// first registration of the domain, in form of <namespace>/<name>.
+const DomainOwnerAnnotation = "domain.skygear.io/owner"
+
+// DomainRetainAnnotation is the annotation on CustomDomain to opt-out from
+// deletion when it has no registrations, with value "true".
+const DomainRetainAnnotation = "domain.skygear.io/retain"
+
+// PausedAnnotation is the annotation on CustomDomain and
+// CustomDomainRegistration to pause reconciliation, with value "true".
+const PausedAnnotation = "domain.skygear.io/paused"
+
+// ForceDeleteAnnotation is the annotation on CustomDomainRegistration to
+// allow deletion while the domain is still serving traffic, with value
+// "true".
+const ForceDeleteAnnotation = "domain.skygear.io/force-delete"
+
+// VerifyNowAnnotation is the annotation on CustomDomainRegistration to
+// request verification, with value of request time in RFC 3339 format. The
+// domain is verified if not verified after the request time.
+const VerifyNowAnnotation = "domain.skygear.io/verify-now"
+
+// ApprovedAnnotation is the annotation on CustomDomainRegistration to
+// approve the registration when approval is required, with value "true".
+// Changing it requires the approve verb on customdomainregistrations.
+const ApprovedAnnotation = "domain.skygear.io/approved"
+
+// DefaultDomainBackendAnnotation is the annotation on Namespace overriding
+// the backend Service of its default domain, in format of name:port.
+const DefaultDomainBackendAnnotation = "domain.skygear.io/default-domain-backend"
+
+// VerificationKeyRotatedAtAnnotation is the annotation on CustomDomain
+// recording the time that verification key is last rotated, in RFC 3339
+// format. It is written together with the rotated keys, so that keys are
+// never rotated again before the rotation is observed.
+const VerificationKeyRotatedAtAnnotation = "domain.skygear.io/verification-key-rotated-at"
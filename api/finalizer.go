@@ -1,3 +1,7 @@
 package api
 
 const DomainFinalizer = "finalizer.domain.skygear.io"
+
+// DNSRecordsFinalizer is the finalizer on CustomDomain to delete DNS records
+// created by DNS provider.
+const DNSRecordsFinalizer = "dns.finalizer.domain.skygear.io"
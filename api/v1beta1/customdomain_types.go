@@ -28,10 +28,82 @@ type CustomDomainSpec struct {
 	LoadBalancerProvider *string `json:"loadBalancerProvider,omitempty"`
 	// VerificationKey is the domain verification token key.
 	VerificationKey *string `json:"verificationKey,omitempty"`
+	// PreviousVerificationKey is the verification key before last rotation,
+	// accepted during the grace period after rotation.
+	// +optional
+	PreviousVerificationKey *string `json:"previousVerificationKey,omitempty"`
+	// VerificationKeyRotation is the interval of rotating verification key,
+	// overriding the default interval of controller. Zero disables rotation.
+	// +optional
+	VerificationKeyRotation *metav1.Duration `json:"verificationKeyRotation,omitempty"`
+	// VerificationNameservers are DNS resolvers used to verify the domain,
+	// overriding resolvers of controller. It is useful for domains only
+	// resolvable by specific servers, e.g. private zones.
+	// +optional
+	VerificationNameservers []string `json:"verificationNameservers,omitempty"`
+	// VerificationRecordPrefix is the label prefixing the name of
+	// verification TXT record of the domain, overriding the prefix of
+	// controller, e.g. to brand records of white-label platforms.
+	// +optional
+	VerificationRecordPrefix *string `json:"verificationRecordPrefix,omitempty"`
 	// Registrations are registrations from apps.
-	Registrations []corev1.ObjectReference `json:"registrations,omitempty"`
+	Registrations []CustomDomainRegistrationReference `json:"registrations,omitempty"`
 	// OwnerApp is the app which the registration is accepted
 	OwnerApp *string `json:"ownerApp,omitempty"`
+	// OwnerRef is the registration which the domain is owned by
+	OwnerRef *corev1.ObjectReference `json:"ownerRef,omitempty"`
+	// OwnerCluster is the name of workload cluster of the owner registration
+	// in multi-cluster mode. Empty if the owner is in the same cluster as the
+	// domain.
+	// +optional
+	OwnerCluster string `json:"ownerCluster,omitempty"`
+}
+
+// CustomDomainRegistrationReference is a reference to a registration of the domain
+type CustomDomainRegistrationReference struct {
+	corev1.ObjectReference `json:",inline"`
+	// Role is the role of registration. Defaults to Primary.
+	// +optional
+	Role CustomDomainRegistrationRole `json:"role,omitempty"`
+	// Cluster is the name of workload cluster of the registration in
+	// multi-cluster mode. Empty if the registration is in the same cluster
+	// as the domain.
+	// +optional
+	Cluster string `json:"cluster,omitempty"`
+	// Verified is whether the registration is verified. It is synced by the
+	// controller of workload cluster, and used only if Cluster is not empty.
+	// +optional
+	Verified bool `json:"verified,omitempty"`
+	// LastHeartbeatTime is the time that the reference is last refreshed by
+	// the controller of workload cluster, and used only if Cluster is not
+	// empty. References not refreshed for a while are removed, e.g. when the
+	// workload cluster is gone.
+	// +optional
+	LastHeartbeatTime *metav1.Time `json:"lastHeartbeatTime,omitempty"`
+	// LoadBalancerClass is the load balancer class requested by the
+	// registration.
+	// +optional
+	LoadBalancerClass string `json:"loadBalancerClass,omitempty"`
+	// DedicatedIP is whether the registration requests a dedicated IP
+	// address.
+	// +optional
+	DedicatedIP bool `json:"dedicatedIP,omitempty"`
+}
+
+// IsRemote returns whether the referenced registration is in another cluster.
+func (r *CustomDomainRegistrationReference) IsRemote() bool {
+	return r.Cluster != ""
+}
+
+// IsOwnedBy returns whether the domain is owned by the app (namespace) in the
+// workload cluster. Cluster is empty for the cluster of the domain.
+func (d *CustomDomain) IsOwnedBy(cluster string, namespace string) bool {
+	return d.Spec.OwnerApp != nil && *d.Spec.OwnerApp == namespace && d.Spec.OwnerCluster == cluster
+}
+
+// IsPrimary returns whether the referenced registration is primary registration.
+func (r *CustomDomainRegistrationReference) IsPrimary() bool {
+	return r.Role == "" || r.Role == RegistrationRolePrimary
 }
 
 // CustomDomainDNSRecord is a DNS record associated with the domain
@@ -50,6 +122,13 @@ type CustomDomainConditionType string
 const (
 	// DomainLoadBalancerProvisioned indicates the required domain resource is provisioned.
 	DomainLoadBalancerProvisioned CustomDomainRegistrationConditionType = "LoadBalancerProvisioned"
+	// DomainDNSRecordsProvisioned indicates the DNS records of domain are provisioned.
+	DomainDNSRecordsProvisioned CustomDomainRegistrationConditionType = "DNSRecordsProvisioned"
+	// DomainPaused indicates the reconciliation of domain is paused.
+	DomainPaused CustomDomainRegistrationConditionType = "Paused"
+	// DomainDedicatedIPAllocated indicates the dedicated IP address of domain
+	// is allocated.
+	DomainDedicatedIPAllocated CustomDomainRegistrationConditionType = "DedicatedIPAllocated"
 )
 
 // CustomDomainStatusLoadBalancer defines the status of the domain load balancer
@@ -59,6 +138,66 @@ type CustomDomainStatusLoadBalancer struct {
 	// DNSRecords are DNS records that should be associated with the domain
 	// +optional
 	DNSRecords []CustomDomainDNSRecord `json:"dnsRecords,omitempty"`
+	// AliasTarget is the hostname of load balancer that an apex domain
+	// should be aliased to, using ALIAS, ANAME or CNAME flattening records
+	// if supported by the DNS provider. Otherwise, the A/AAAA records
+	// resolved from the hostname should be used.
+	// +optional
+	AliasTarget *string `json:"aliasTarget,omitempty"`
+}
+
+// CustomDomainPhase is a summary of CustomDomain conditions
+type CustomDomainPhase string
+
+const (
+	// DomainPhasePending indicates the domain load balancer is being provisioned.
+	DomainPhasePending CustomDomainPhase = "Pending"
+	// DomainPhaseReady indicates the domain load balancer is provisioned.
+	DomainPhaseReady CustomDomainPhase = "Ready"
+	// DomainPhaseTerminating indicates the domain is being released.
+	DomainPhaseTerminating CustomDomainPhase = "Terminating"
+)
+
+// CustomDomainTakeoverStatus is the status of pending takeover of domain
+// ownership, after the owner registration becomes unverified
+type CustomDomainTakeoverStatus struct {
+	// OwnerUnverifiedSince is the time that the owner registration becomes
+	// unverified
+	OwnerUnverifiedSince metav1.Time `json:"ownerUnverifiedSince"`
+	// OwnerFailureCount is the number of consecutive failed verifications of
+	// the owner registration observing the verification record missing or
+	// mismatched
+	OwnerFailureCount int `json:"ownerFailureCount"`
+	// Claimant is the verified registration of another app claiming the
+	// domain
+	// +optional
+	Claimant *corev1.ObjectReference `json:"claimant,omitempty"`
+	// ClaimantVerifiedSince is the time that the claimant registration
+	// becomes verified
+	// +optional
+	ClaimantVerifiedSince *metav1.Time `json:"claimantVerifiedSince,omitempty"`
+}
+
+// CustomDomainSummary summarizes the registrations and published DNS
+// targets of the domain
+type CustomDomainSummary struct {
+	// Registrations is the number of active registrations of the domain
+	Registrations int `json:"registrations"`
+	// VerifiedRegistrations is the number of verified registrations
+	VerifiedRegistrations int `json:"verifiedRegistrations"`
+	// PendingRegistrations is the number of registrations not verified yet
+	PendingRegistrations int `json:"pendingRegistrations"`
+	// Owner is the registration owning the domain, in format of
+	// namespace/name
+	// +optional
+	Owner string `json:"owner,omitempty"`
+	// LastVerificationTime is the latest verification time of the
+	// registrations in this cluster
+	// +optional
+	LastVerificationTime *metav1.Time `json:"lastVerificationTime,omitempty"`
+	// DNSTargets are the values of DNS records published for the domain
+	// +optional
+	DNSTargets []string `json:"dnsTargets,omitempty"`
 }
 
 // CustomDomainStatus defines the observed state of CustomDomain
@@ -68,13 +207,63 @@ type CustomDomainStatus struct {
 	// +patchMergeKey=type
 	// +patchStrategy=merge
 	Conditions []api.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+	// ObservedGeneration is the most recent generation observed by controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Phase is a summary of current state of domain.
+	// +optional
+	Phase CustomDomainPhase `json:"phase,omitempty"`
+	// VerificationKeyRotatedAt is the time that verification key is last rotated
+	// +optional
+	VerificationKeyRotatedAt *metav1.Time `json:"verificationKeyRotatedAt,omitempty"`
+	// PrimaryRegistration is the primary registration owning the domain
+	// +optional
+	PrimaryRegistration *corev1.ObjectReference `json:"primaryRegistration,omitempty"`
+	// OrphanedAt is the time that the domain is observed to have no registrations
+	// +optional
+	OrphanedAt *metav1.Time `json:"orphanedAt,omitempty"`
+	// ReleasedAt is the time that the verified owner registration of the
+	// domain is last deleted
+	// +optional
+	ReleasedAt *metav1.Time `json:"releasedAt,omitempty"`
+	// ReleasedApp is the app (namespace) of the owner registration last
+	// deleted. It may re-claim the domain during quarantine.
+	// +optional
+	ReleasedApp string `json:"releasedApp,omitempty"`
+	// ReleasedCluster is the workload cluster of the owner registration last
+	// deleted in multi-cluster mode.
+	// +optional
+	ReleasedCluster string `json:"releasedCluster,omitempty"`
+	// QuarantineUntil is the time until which the released domain cannot be
+	// claimed by other apps
+	// +optional
+	QuarantineUntil *metav1.Time `json:"quarantineUntil,omitempty"`
+	// Takeover is the status of pending takeover of domain ownership
+	// +optional
+	Takeover *CustomDomainTakeoverStatus `json:"takeover,omitempty"`
+	// Summary summarizes the registrations and published DNS targets of the
+	// domain
+	// +optional
+	Summary *CustomDomainSummary `json:"summary,omitempty"`
 	// LoadBalancer is the status of the domain load balancer
 	LoadBalancer *CustomDomainStatusLoadBalancer `json:"loadBalancer,omitempty"`
+	// Zone is the registrable domain (public suffix plus one label) that the
+	// domain is under.
+	// +optional
+	Zone string `json:"zone,omitempty"`
 }
 
+// +genclient
+// +genclient:nonNamespaced
 // +kubebuilder:object:root=true
-// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:resource:scope=Cluster,shortName=cd
+// +kubebuilder:storageversion
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Owner",type=string,JSONPath=`.spec.ownerApp`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Provider",type=string,JSONPath=`.status.loadBalancer.provider`
+// +kubebuilder:printcolumn:name="DNS Target",type=string,JSONPath=`.status.loadBalancer.dnsRecords[0].value`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // CustomDomain is the Schema for the customdomains API
 type CustomDomain struct {
@@ -85,6 +274,39 @@ type CustomDomain struct {
 	Status CustomDomainStatus `json:"status,omitempty"`
 }
 
+// LoadBalancerClass returns the load balancer class requested by the owner
+// registration, or by the first primary registration if the domain is not
+// owned yet.
+func (d *CustomDomain) LoadBalancerClass() string {
+	if ref := d.loadBalancerRegistration(); ref != nil {
+		return ref.LoadBalancerClass
+	}
+	return ""
+}
+
+// DedicatedIP returns whether a dedicated IP address is requested by the
+// owner registration, or by the first primary registration if the domain is
+// not owned yet.
+func (d *CustomDomain) DedicatedIP() bool {
+	if ref := d.loadBalancerRegistration(); ref != nil {
+		return ref.DedicatedIP
+	}
+	return false
+}
+
+func (d *CustomDomain) loadBalancerRegistration() *CustomDomainRegistrationReference {
+	for i, ref := range d.Spec.Registrations {
+		if d.Spec.OwnerRef != nil {
+			if ref.UID == d.Spec.OwnerRef.UID {
+				return &d.Spec.Registrations[i]
+			}
+		} else if ref.IsPrimary() {
+			return &d.Spec.Registrations[i]
+		}
+	}
+	return nil
+}
+
 // +kubebuilder:object:root=true
 
 // CustomDomainList contains a list of CustomDomain
@@ -16,13 +16,20 @@ limitations under the License.
 package v1beta1
 
 import (
+	"fmt"
+
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
 	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	"github.com/skygeario/k8s-controller/api"
+	"github.com/skygeario/k8s-controller/pkg/domain/verification"
+	"github.com/skygeario/k8s-controller/pkg/util/dnsname"
 )
 
 // log is for logging in this package.
@@ -34,6 +41,31 @@ func (r *CustomDomain) SetupWebhookWithManager(mgr ctrl.Manager) error {
 		Complete()
 }
 
+// +kubebuilder:webhook:verbs=create,path=/mutate-domain-skygear-io-v1beta1-customdomain,mutating=true,failurePolicy=fail,groups=domain.skygear.io,resources=customdomains,versions=v1beta1,name=mcustomdomain.kb.io
+
+var _ webhook.Defaulter = &CustomDomain{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (r *CustomDomain) Default() {
+	if name, err := dnsname.Normalize(dnsname.DomainName(r.Name)); err != nil {
+		customdomainlog.Info("cannot normalize domain name", "name", r.Name, "error", err.Error())
+	} else {
+		r.Name = dnsname.ResourceName(name)
+	}
+
+	if r.Spec.VerificationKey == nil {
+		r.Spec.VerificationKey = pointer.StringPtr(verification.GenerateDomainKey())
+	}
+
+	if _, ok := r.Annotations[api.DomainOwnerAnnotation]; !ok && len(r.Spec.Registrations) > 0 {
+		reg := r.Spec.Registrations[0]
+		if r.Annotations == nil {
+			r.Annotations = map[string]string{}
+		}
+		r.Annotations[api.DomainOwnerAnnotation] = fmt.Sprintf("%s/%s", reg.Namespace, reg.Name)
+	}
+}
+
 // +kubebuilder:webhook:verbs=create;update;delete,path=/validate-domain-skygear-io-v1beta1-customdomain,mutating=false,failurePolicy=fail,groups=domain.skygear.io,resources=customdomains,versions=v1beta1,name=vcustomdomain.kb.io
 
 var _ webhook.Validator = &CustomDomain{}
@@ -58,6 +90,11 @@ func (r *CustomDomain) validate(old *CustomDomain) error {
 	if old != nil && old.Name != r.Name {
 		errs = append(errs, field.Invalid(field.NewPath("metadata", "name"), r.Name, "resource name cannot be changed"))
 	}
+	if old == nil {
+		if err := dnsname.Validate(dnsname.DomainName(r.Name)); err != nil {
+			errs = append(errs, field.Invalid(field.NewPath("metadata", "name"), r.Name, fmt.Sprintf("invalid domain name: %s", err)))
+		}
+	}
 	for i, reg := range r.Spec.Registrations {
 		if reg.GroupVersionKind() != GroupVersion.WithKind("CustomDomainRegistration") {
 			errs = append(errs, field.Invalid(field.NewPath("spec", "registrations").Index(i), r.Name, "only CustomDomainRegistration is supported"))
@@ -68,6 +105,16 @@ func (r *CustomDomain) validate(old *CustomDomain) error {
 		(r.Spec.LoadBalancerProvider == nil || *old.Spec.LoadBalancerProvider != *r.Spec.LoadBalancerProvider) {
 		errs = append(errs, field.Invalid(field.NewPath("spec", "loadBalancerProvider"), r.Name, "load balancer provider cannot be changed"))
 	}
+	for i, server := range r.Spec.VerificationNameservers {
+		if _, err := verification.ParseServer(server); err != nil {
+			errs = append(errs, field.Invalid(field.NewPath("spec", "verificationNameservers").Index(i), server, err.Error()))
+		}
+	}
+	if prefix := r.Spec.VerificationRecordPrefix; prefix != nil {
+		if err := verification.ValidateDNSRecordPrefix(*prefix); err != nil {
+			errs = append(errs, field.Invalid(field.NewPath("spec", "verificationRecordPrefix"), *prefix, err.Error()))
+		}
+	}
 
 	if len(errs) != 0 {
 		return apierrors.NewInvalid(
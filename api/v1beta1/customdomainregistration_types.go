@@ -16,11 +16,17 @@ limitations under the License.
 package v1beta1
 
 import (
+	"strings"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/skygeario/k8s-controller/api"
+	"github.com/skygeario/k8s-controller/pkg/util/dnsname"
 )
 
+// CustomDomainConfig is the configuration of custom domain. spec.backend of
+// v1beta2 is stored in BackendServiceName, BackendServicePort and
+// RedirectToURL, from which the registration Ingress is generated.
 type CustomDomainConfig struct {
 	// BackendServiceName is the name of backend Service.
 	BackendServiceName string `json:"backendServiceName"`
@@ -32,15 +38,224 @@ type CustomDomainConfig struct {
 	RedirectToURL *string `json:"redirectToURL,omitempty"`
 }
 
+// CustomDomainIssuerReference is a reference to a cert-manager issuer
+type CustomDomainIssuerReference struct {
+	// Kind is the kind of issuer, either ClusterIssuer or Issuer.
+	// Defaults to ClusterIssuer.
+	// +optional
+	Kind string `json:"kind,omitempty"`
+	// Name is the name of issuer.
+	Name string `json:"name"`
+}
+
+// CustomDomainTLS is the TLS configuration of custom domain
+type CustomDomainTLS struct {
+	// IssuerRef is the issuer used to issue TLS certificate, overriding the
+	// default issuer of controller.
+	// +optional
+	IssuerRef *CustomDomainIssuerReference `json:"issuerRef,omitempty"`
+	// SecretName is the name of Secret storing the certificate issued by
+	// built-in ACME client.
+	// +optional
+	SecretName *string `json:"secretName,omitempty"`
+}
+
+// CustomDomainRoutingMode is the mode of routing traffic to custom domain
+// +kubebuilder:validation:Enum=Ingress;GatewayAPI;Istio
+type CustomDomainRoutingMode string
+
+const (
+	// RoutingModeIngress routes traffic using Ingress.
+	RoutingModeIngress CustomDomainRoutingMode = "Ingress"
+	// RoutingModeGatewayAPI routes traffic using Gateway API HTTPRoute.
+	RoutingModeGatewayAPI CustomDomainRoutingMode = "GatewayAPI"
+	// RoutingModeIstio routes traffic using Istio Gateway and VirtualService.
+	RoutingModeIstio CustomDomainRoutingMode = "Istio"
+)
+
+// CustomDomainRouting is the routing configuration of custom domain
+type CustomDomainRouting struct {
+	// Mode is the mode of routing. Defaults to Ingress.
+	// +optional
+	Mode CustomDomainRoutingMode `json:"mode,omitempty"`
+}
+
+// CustomDomainHealthCheckScheme is the scheme of health probes
+// +kubebuilder:validation:Enum=HTTP;HTTPS
+type CustomDomainHealthCheckScheme string
+
+const (
+	// HealthCheckSchemeHTTP probes the domain with HTTP requests.
+	HealthCheckSchemeHTTP CustomDomainHealthCheckScheme = "HTTP"
+	// HealthCheckSchemeHTTPS probes the domain with HTTPS requests.
+	HealthCheckSchemeHTTPS CustomDomainHealthCheckScheme = "HTTPS"
+)
+
+// CustomDomainHealthCheck is the configuration of health probes against the
+// custom domain, performed after routing of the domain is configured
+type CustomDomainHealthCheck struct {
+	// Path is the path of probe requests. Defaults to /.
+	// +optional
+	Path string `json:"path,omitempty"`
+	// Scheme is the scheme of probe requests. Defaults to HTTPS.
+	// +optional
+	Scheme CustomDomainHealthCheckScheme `json:"scheme,omitempty"`
+	// Interval is the interval between probes. Defaults to 1 minute.
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// DefaultRedirectStatusCode is the default HTTP status code of redirect.
+const DefaultRedirectStatusCode = 302
+
+// CustomDomainRedirect is the HTTP redirect configuration of custom domain
+type CustomDomainRedirect struct {
+	// URL is the target URL that requests are redirected to.
+	URL string `json:"url"`
+	// StatusCode is the HTTP status code of redirect. Defaults to 302.
+	// +kubebuilder:validation:Enum=301;302;303;307;308
+	// +optional
+	StatusCode int `json:"statusCode,omitempty"`
+	// PreservePath indicates the path and query of requests are appended to
+	// the target URL, which must not contain path in this case.
+	// +optional
+	PreservePath bool `json:"preservePath,omitempty"`
+}
+
+// CustomDomainVerificationMethod is the method of verifying domain ownership
+// +kubebuilder:validation:Enum=DNS;HTTP;CNAME;Email
+type CustomDomainVerificationMethod string
+
+const (
+	// VerificationMethodDNS verifies domain using DNS TXT record.
+	VerificationMethodDNS CustomDomainVerificationMethod = "DNS"
+	// VerificationMethodHTTP verifies domain using token served at
+	// http://<domain>/.well-known/skygear-domain-verification/<token>.
+	VerificationMethodHTTP CustomDomainVerificationMethod = "HTTP"
+	// VerificationMethodCNAME verifies domain using CNAME record from
+	// _skygear-challenge.<domain> to controller-managed challenge zone.
+	VerificationMethodCNAME CustomDomainVerificationMethod = "CNAME"
+	// VerificationMethodEmail verifies domain by confirmation link sent to
+	// contact emails of the registrable domain found in RDAP. Experimental.
+	VerificationMethodEmail CustomDomainVerificationMethod = "Email"
+)
+
+// CustomDomainVerificationRecordType is the type of DNS record of DNS
+// verification
+// +kubebuilder:validation:Enum=TXT;CNAME
+type CustomDomainVerificationRecordType string
+
+const (
+	// VerificationRecordTypeTXT verifies domain using TXT record at
+	// _skygear.<root domain>.
+	VerificationRecordTypeTXT CustomDomainVerificationRecordType = "TXT"
+	// VerificationRecordTypeCNAME verifies domain using CNAME record from
+	// <token hash>.<domain> to the verification target of the platform, for
+	// DNS hosting that does not allow underscore TXT records.
+	VerificationRecordTypeCNAME CustomDomainVerificationRecordType = "CNAME"
+)
+
+// CustomDomainVerification is the verification configuration of custom domain
+type CustomDomainVerification struct {
+	// Method is the method of verification. Defaults to DNS.
+	// +optional
+	Method CustomDomainVerificationMethod `json:"method,omitempty"`
+	// RecordType is the type of DNS record of DNS verification. Defaults to
+	// TXT.
+	// +optional
+	RecordType CustomDomainVerificationRecordType `json:"recordType,omitempty"`
+	// DeadlineSeconds is the duration in seconds that the domain must be
+	// verified within, since creation of registration or domain becoming
+	// unverified. The registration is marked as failed after the deadline.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	DeadlineSeconds *int64 `json:"deadlineSeconds,omitempty"`
+}
+
+// CustomDomainRegistrationRole is the role of registration sharing a domain
+// +kubebuilder:validation:Enum=Primary;Secondary
+type CustomDomainRegistrationRole string
+
+const (
+	// RegistrationRolePrimary registration owns the domain.
+	RegistrationRolePrimary CustomDomainRegistrationRole = "Primary"
+	// RegistrationRoleSecondary registration shares the domain owned by
+	// primary registration.
+	RegistrationRoleSecondary CustomDomainRegistrationRole = "Secondary"
+)
+
 // CustomDomainRegistrationSpec defines the desired state of CustomDomainRegistration
 type CustomDomainRegistrationSpec struct {
 	// DomainName is the custom domain name registered with the app.
+	// Wildcard domain name (e.g. *.example.com) is allowed, in which case the
+	// resource name should be zz--wildcard.example.com. Internationalized
+	// domain name is allowed, in which case the resource name should be its
+	// punycode form.
 	DomainName string `json:"domainName"`
 	// DomainConfig is the configuration of custom domain
 	DomainConfig CustomDomainConfig `json:"domainConfig"`
+	// Role is the role of registration. Defaults to Primary.
+	// +optional
+	Role CustomDomainRegistrationRole `json:"role,omitempty"`
+	// LoadBalancerClass selects the load balancer that DNS records of the
+	// domain target, when the platform runs multiple load balancers. Defaults
+	// to the load balancer configured in controller.
+	// +optional
+	LoadBalancerClass string `json:"loadBalancerClass,omitempty"`
+	// DedicatedIP requests a dedicated IP address for the domain, allocated
+	// from IP pools of the platform. It cannot be changed after creation.
+	// +optional
+	DedicatedIP bool `json:"dedicatedIP,omitempty"`
+	// TLS is the TLS configuration of custom domain
+	// +optional
+	TLS *CustomDomainTLS `json:"tls,omitempty"`
+	// Routing is the routing configuration of custom domain
+	// +optional
+	Routing *CustomDomainRouting `json:"routing,omitempty"`
+	// HealthCheck enables health probes against the custom domain, reported
+	// in EndpointHealthy condition.
+	// +optional
+	HealthCheck *CustomDomainHealthCheck `json:"healthCheck,omitempty"`
+	// Redirect redirects requests of custom domain to the target URL,
+	// instead of serving traffic using backend Service.
+	// +optional
+	Redirect *CustomDomainRedirect `json:"redirect,omitempty"`
+	// RedirectSibling registers the sibling host (www subdomain of apex
+	// domain, or apex domain of www subdomain) redirecting to this domain.
+	// +optional
+	RedirectSibling bool `json:"redirectSibling,omitempty"`
+	// Verification is the verification configuration of custom domain
+	// +optional
+	Verification *CustomDomainVerification `json:"verification,omitempty"`
+	// Approved is whether the registration is approved by operators, if
+	// approval is required by controller or domain policies. Changing it
+	// requires the approve verb on customdomainregistrations.
+	// +optional
+	Approved bool `json:"approved,omitempty"`
 	// VerifyAt is the time that next verification should be performed
 	// +optional
 	VerifyAt *metav1.Time `json:"verifyAt,omitempty"`
+	// ReverificationInterval is the interval between re-verification of
+	// verified domain. Zero disables re-verification.
+	// +optional
+	ReverificationInterval *metav1.Duration `json:"reverificationInterval,omitempty"`
+	// TransferTo is the registration (in format of namespace/name) that
+	// ownership of the domain should be transferred to. Ownership is
+	// transferred once the target registration is verified.
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?/[a-z0-9]([-.a-z0-9]*[a-z0-9])?$`
+	// +optional
+	TransferTo *string `json:"transferTo,omitempty"`
+	// ExpiresAt is the time that the registration is deleted, if the domain
+	// is not verified by then. The domain becomes claimable by other
+	// registrations after deletion.
+	// +optional
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+	// TTLSecondsAfterVerificationFailure is the duration in seconds that the
+	// registration is kept after it failed verification deadline, before it
+	// is deleted.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	TTLSecondsAfterVerificationFailure *int64 `json:"ttlSecondsAfterVerificationFailure,omitempty"`
 }
 
 // CustomDomainRegistrationConditionType is a valid CustomDomainRegistration condition type
@@ -51,12 +266,236 @@ const (
 	RegistrationVerified CustomDomainRegistrationConditionType = "Verified"
 	// RegistrationAccepted indicates the registration is accepted.
 	RegistrationAccepted CustomDomainRegistrationConditionType = "Accepted"
-	// RegistrationCertReady indicates TLS certificate for the registration is ready.
+	// RegistrationApproved indicates the registration is approved by
+	// operators. It is reported only if approval is required.
+	RegistrationApproved CustomDomainRegistrationConditionType = "Approved"
+	// RegistrationCertReady indicates TLS certificate for the registration is
+	// ready. If not ready, reason and message of pending issuance reported by
+	// the TLS provider (e.g. cert-manager Certificate) are included.
 	RegistrationCertReady CustomDomainRegistrationConditionType = "CertReady"
 	// RegistrationIngressReady indicates ingress for the registration is ready.
 	RegistrationIngressReady CustomDomainRegistrationConditionType = "IngressReady"
+	// RegistrationDomainConflict indicates the domain overlaps with a wildcard
+	// domain or a sub-domain owned by another app.
+	RegistrationDomainConflict CustomDomainRegistrationConditionType = "DomainConflict"
+	// RegistrationRejected indicates the registration is rejected.
+	RegistrationRejected CustomDomainRegistrationConditionType = "Rejected"
+	// RegistrationQuotaExceeded indicates the registration exceeds domain
+	// quota of its namespace.
+	RegistrationQuotaExceeded CustomDomainRegistrationConditionType = "QuotaExceeded"
+	// RegistrationPolicyViolation indicates the registration violates domain
+	// policy of its namespace.
+	RegistrationPolicyViolation CustomDomainRegistrationConditionType = "PolicyViolation"
+	// RegistrationSuspiciousDomain indicates the domain is visually
+	// confusable with a brand protected by domain policy of its namespace.
+	RegistrationSuspiciousDomain CustomDomainRegistrationConditionType = "SuspiciousDomain"
+	// RegistrationFailed indicates the domain is not verified before the
+	// verification deadline. Verification is no longer retried until spec
+	// is changed.
+	RegistrationFailed CustomDomainRegistrationConditionType = "Failed"
+	// RegistrationPaused indicates the reconciliation of registration is
+	// paused.
+	RegistrationPaused CustomDomainRegistrationConditionType = "Paused"
+	// RegistrationDNSConfigured indicates DNS records of the domain point to
+	// the load balancer.
+	RegistrationDNSConfigured CustomDomainRegistrationConditionType = "DNSConfigured"
+	// RegistrationCertificateExpiringSoon indicates TLS certificate of the
+	// domain is expiring soon.
+	RegistrationCertificateExpiringSoon CustomDomainRegistrationConditionType = "CertificateExpiringSoon"
+	// RegistrationTransferPending indicates ownership of the domain is
+	// pending transfer to another registration.
+	RegistrationTransferPending CustomDomainRegistrationConditionType = "TransferPending"
+	// RegistrationTransferAccepted indicates ownership of the domain is
+	// transferred to another registration.
+	RegistrationTransferAccepted CustomDomainRegistrationConditionType = "TransferAccepted"
+	// RegistrationDomainBackendUnavailable indicates the CustomDomain
+	// resource is not installed in the cluster, so the domain cannot be
+	// registered.
+	RegistrationDomainBackendUnavailable CustomDomainRegistrationConditionType = "DomainBackendUnavailable"
+	// RegistrationDedicatedIPAllocated indicates the dedicated IP address
+	// requested by the registration is allocated to the domain.
+	RegistrationDedicatedIPAllocated CustomDomainRegistrationConditionType = "DedicatedIPAllocated"
+	// RegistrationEndpointHealthy indicates the health probes against the
+	// domain succeed.
+	RegistrationEndpointHealthy CustomDomainRegistrationConditionType = "EndpointHealthy"
 )
 
+const (
+	// ReasonAlreadyOwned indicates the domain is already owned by another app.
+	ReasonAlreadyOwned string = "AlreadyOwned"
+	// ReasonPendingApproval indicates the registration is waiting for
+	// approval of operators.
+	ReasonPendingApproval string = "PendingApproval"
+	// ReasonReserved indicates the domain is reserved by a DomainReservation
+	// for other namespaces.
+	ReasonReserved string = "Reserved"
+	// ReasonVerificationDeadlineExceeded indicates the domain is not verified
+	// before the verification deadline.
+	ReasonVerificationDeadlineExceeded string = "VerificationDeadlineExceeded"
+	// ReasonInvalidDomainName indicates the domain name is not a valid host
+	// name.
+	ReasonInvalidDomainName string = "InvalidDomainName"
+	// ReasonIPPoolExhausted indicates no addresses of IP pools are available
+	// for dedicated IP address.
+	ReasonIPPoolExhausted string = "IPPoolExhausted"
+	// ReasonProbeFailed indicates the health probe against the domain
+	// failed.
+	ReasonProbeFailed string = "ProbeFailed"
+	// ReasonCertificateExpired indicates the TLS certificate is expired.
+	ReasonCertificateExpired string = "CertificateExpired"
+	// ReasonSecretNotOwned indicates the certificate Secret exists and is not
+	// managed for the registration, so the certificate is not stored.
+	ReasonSecretNotOwned string = "SecretNotOwned"
+	// ReasonResourceNotInstalled indicates the CustomDomain resource is not
+	// installed in the cluster.
+	ReasonResourceNotInstalled string = "ResourceNotInstalled"
+	// ReasonExternalAttestation indicates the domain is verified by
+	// attestation of an external verifier.
+	ReasonExternalAttestation string = "ExternalAttestation"
+	// ReasonParentDomainVerified indicates the domain is verified by
+	// ownership of a parent domain in the same namespace.
+	ReasonParentDomainVerified string = "ParentDomainVerified"
+	// ReasonDefaultDomain indicates the domain is verified as the default
+	// domain of namespace under the platform-owned suffix.
+	ReasonDefaultDomain string = "DefaultDomain"
+)
+
+// CustomDomainRegistrationPhase is a summary of CustomDomainRegistration conditions
+type CustomDomainRegistrationPhase string
+
+const (
+	// RegistrationPhasePending indicates the registration is pending acceptance.
+	RegistrationPhasePending CustomDomainRegistrationPhase = "Pending"
+	// RegistrationPhaseAccepted indicates the registration is accepted, and
+	// resources are being provisioned.
+	RegistrationPhaseAccepted CustomDomainRegistrationPhase = "Accepted"
+	// RegistrationPhaseReady indicates the domain is ready to serve traffic.
+	RegistrationPhaseReady CustomDomainRegistrationPhase = "Ready"
+	// RegistrationPhaseRejected indicates the registration is rejected.
+	RegistrationPhaseRejected CustomDomainRegistrationPhase = "Rejected"
+	// RegistrationPhaseFailed indicates the domain is not verified before
+	// the verification deadline.
+	RegistrationPhaseFailed CustomDomainRegistrationPhase = "Failed"
+	// RegistrationPhaseTransferred indicates ownership of the domain is
+	// transferred to another registration.
+	RegistrationPhaseTransferred CustomDomainRegistrationPhase = "Transferred"
+	// RegistrationPhaseTerminating indicates the registration is being deleted.
+	RegistrationPhaseTerminating CustomDomainRegistrationPhase = "Terminating"
+)
+
+// CustomDomainVerificationFailure describes what is observed in the last
+// failed verification
+type CustomDomainVerificationFailure struct {
+	// Reason is the reason of failure
+	Reason string `json:"reason"`
+	// RecordName is the DNS record name or URL queried
+	// +optional
+	RecordName string `json:"recordName,omitempty"`
+	// ExpectedValue is the expected record value or token
+	// +optional
+	ExpectedValue string `json:"expectedValue,omitempty"`
+	// ObservedValues are the record values or token actually observed
+	// +optional
+	ObservedValues []string `json:"observedValues,omitempty"`
+	// Resolver is the DNS resolver used
+	// +optional
+	Resolver string `json:"resolver,omitempty"`
+}
+
+// CustomDomainDNSInstruction is an instruction to configure a DNS record of
+// the domain
+type CustomDomainDNSInstruction struct {
+	// Name is name of DNS record
+	Name string `json:"name"`
+	// Type is type of DNS record
+	Type string `json:"type"`
+	// Value is value of DNS record
+	Value string `json:"value"`
+	// Description is a human-readable instruction to configure the record
+	Description string `json:"description"`
+	// Command is an example command to check the record is configured
+	// +optional
+	Command string `json:"command,omitempty"`
+}
+
+// CustomDomainDNSRecordStatus is the observed state of a DNS record
+type CustomDomainDNSRecordStatus struct {
+	// Name is name of DNS record
+	Name string `json:"name"`
+	// Type is type of DNS record
+	Type string `json:"type"`
+	// Value is the expected value of DNS record
+	Value string `json:"value"`
+	// ObservedValues are the values actually observed in DNS
+	// +optional
+	ObservedValues []string `json:"observedValues,omitempty"`
+	// Configured indicates the record is configured as expected
+	Configured bool `json:"configured"`
+}
+
+// CustomDomainDNSCheckStatus is the status of checking DNS records of domain
+type CustomDomainDNSCheckStatus struct {
+	// LastCheckTime is the time that DNS records are last checked
+	// +optional
+	LastCheckTime *metav1.Time `json:"lastCheckTime,omitempty"`
+	// Records are the observed states of DNS records
+	// +optional
+	Records []CustomDomainDNSRecordStatus `json:"records,omitempty"`
+}
+
+// CustomDomainHealthCheckStatus is the status of health probes against the
+// custom domain
+type CustomDomainHealthCheckStatus struct {
+	// LastProbeTime is the time that the domain is last probed
+	// +optional
+	LastProbeTime *metav1.Time `json:"lastProbeTime,omitempty"`
+	// StatusCode is the HTTP status code of last probe, omitted if the
+	// request failed
+	// +optional
+	StatusCode int `json:"statusCode,omitempty"`
+	// LatencyMilliseconds is the latency of last probe in milliseconds
+	// +optional
+	LatencyMilliseconds int64 `json:"latencyMilliseconds,omitempty"`
+}
+
+// CustomDomainTLSStatus is the status of TLS certificate of domain
+type CustomDomainTLSStatus struct {
+	// NotAfter is the expiry time of TLS certificate
+	// +optional
+	NotAfter *metav1.Time `json:"notAfter,omitempty"`
+	// Issuer is the issuer of TLS certificate
+	// +optional
+	Issuer string `json:"issuer,omitempty"`
+}
+
+// CustomDomainAttestationStatus is an attestation of domain ownership
+// submitted by an external verifier
+type CustomDomainAttestationStatus struct {
+	// Time is the time of attestation
+	Time metav1.Time `json:"time"`
+	// ObservedGeneration is the generation of registration that is attested.
+	// Attestation is invalidated when the registration is changed.
+	ObservedGeneration int64 `json:"observedGeneration"`
+	// Attester identifies the external verifier submitting the attestation
+	// +optional
+	Attester string `json:"attester,omitempty"`
+}
+
+// CustomDomainEmailChallengeStatus is the status of confirmation email sent
+// to contacts of domain
+type CustomDomainEmailChallengeStatus struct {
+	// Recipients are the masked email addresses that the email is sent to
+	// +optional
+	Recipients []string `json:"recipients,omitempty"`
+	// SentTime is the time that the email is sent
+	SentTime metav1.Time `json:"sentTime"`
+	// ExpiryTime is the time that the confirmation link expires
+	ExpiryTime metav1.Time `json:"expiryTime"`
+	// ObservedGeneration is the generation of registration that the email is
+	// sent for. The link is invalidated when the registration is changed.
+	ObservedGeneration int64 `json:"observedGeneration"`
+}
+
 // CustomDomainRegistrationStatus defines the observed state of CustomDomainRegistration
 type CustomDomainRegistrationStatus struct {
 	// Current state of registration.
@@ -64,19 +503,92 @@ type CustomDomainRegistrationStatus struct {
 	// +patchMergeKey=type
 	// +patchStrategy=merge
 	Conditions []api.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+	// ObservedGeneration is the most recent generation observed by controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Phase is a summary of current state of registration.
+	// +optional
+	Phase CustomDomainRegistrationPhase `json:"phase,omitempty"`
+	// DomainName is the registered domain name in ASCII (punycode) form
+	// +optional
+	DomainName string `json:"domainName,omitempty"`
+	// UnicodeDomainName is the registered domain name in Unicode form
+	// +optional
+	UnicodeDomainName string `json:"unicodeDomainName,omitempty"`
 	// DNSRecords are DNS records that should be associated with the domain
 	// +optional
 	DNSRecords []CustomDomainDNSRecord `json:"dnsRecords,omitempty"`
+	// Instructions are human-readable instructions to configure DNS records
+	// of the domain
+	// +optional
+	Instructions []CustomDomainDNSInstruction `json:"instructions,omitempty"`
 	// LastVerificationTime is the time that last verification is performed
 	// +optional
 	LastVerificationTime *metav1.Time `json:"lastVerificationTime,omitempty"`
+	// NextVerificationTime is the time that next verification is scheduled
+	// +optional
+	NextVerificationTime *metav1.Time `json:"nextVerificationTime,omitempty"`
+	// VerificationURL is the URL that should serve the verification token,
+	// when verifying using HTTP.
+	// +optional
+	VerificationURL *string `json:"verificationURL,omitempty"`
+	// VerificationFailureCount is the number of consecutive failed verifications
+	// +optional
+	VerificationFailureCount int `json:"verificationFailureCount,omitempty"`
+	// ConclusiveFailureCount is the number of consecutive failed
+	// verifications observing the verification record missing or
+	// mismatched. Inconclusive failures, e.g. lookup timeouts, are not
+	// counted and do not reset the count.
+	// +optional
+	ConclusiveFailureCount int `json:"conclusiveFailureCount,omitempty"`
+	// VerificationFailure describes the last failed verification
+	// +optional
+	VerificationFailure *CustomDomainVerificationFailure `json:"verificationFailure,omitempty"`
+	// VerificationView is the DNS view used in the last verification:
+	// Local if looked up using resolvers of the cluster, or External if
+	// looked up using public resolvers.
+	// +optional
+	VerificationView string `json:"verificationView,omitempty"`
+	// DNSCheck is the status of checking DNS records pointing the domain
+	// to load balancer
+	// +optional
+	DNSCheck *CustomDomainDNSCheckStatus `json:"dnsCheck,omitempty"`
+	// HealthCheck is the status of health probes against the domain
+	// +optional
+	HealthCheck *CustomDomainHealthCheckStatus `json:"healthCheck,omitempty"`
 	// CertSecretName is the name of TLS certificate secret
 	// +optional
 	CertSecretName *string `json:"certSecretName,omitempty"`
+
+	// TLS is the status of TLS certificate
+	// +optional
+	TLS *CustomDomainTLSStatus `json:"tls,omitempty"`
+
+	// Attestation is the last attestation of domain ownership submitted by
+	// an external verifier
+	// +optional
+	Attestation *CustomDomainAttestationStatus `json:"attestation,omitempty"`
+	// EmailChallenge is the status of confirmation email of Email
+	// verification
+	// +optional
+	EmailChallenge *CustomDomainEmailChallengeStatus `json:"emailChallenge,omitempty"`
+	// Zone is the registrable domain (public suffix plus one label) that the
+	// domain is under.
+	// +optional
+	Zone string `json:"zone,omitempty"`
 }
 
+// +genclient
 // +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=cdr
+// +kubebuilder:storageversion
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Domain",type=string,JSONPath=`.spec.domainName`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Verified",type=string,JSONPath=`.status.conditions[?(@.type=="Verified")].status`
+// +kubebuilder:printcolumn:name="Accepted",type=string,JSONPath=`.status.conditions[?(@.type=="Accepted")].status`
+// +kubebuilder:printcolumn:name="DNS Target",type=string,JSONPath=`.status.dnsRecords[0].value`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // CustomDomainRegistration is the Schema for the customdomainregistrations API
 type CustomDomainRegistration struct {
@@ -87,6 +599,59 @@ type CustomDomainRegistration struct {
 	Status CustomDomainRegistrationStatus `json:"status,omitempty"`
 }
 
+// IsPrimary returns whether the registration is primary registration.
+func (r *CustomDomainRegistration) IsPrimary() bool {
+	return r.Spec.Role == "" || r.Spec.Role == RegistrationRolePrimary
+}
+
+// ASCIIDomainName returns the registered domain name in its ASCII (punycode)
+// form, which should be used in DNS and TLS operations.
+// IsApproved returns whether the registration is approved by operators,
+// by spec.approved or annotation.
+func (r *CustomDomainRegistration) IsApproved() bool {
+	return r.Spec.Approved || r.Annotations[api.ApprovedAnnotation] == "true"
+}
+
+func (r *CustomDomainRegistration) ASCIIDomainName() string {
+	name, err := dnsname.Normalize(r.Spec.DomainName)
+	if err != nil {
+		// invalid domain names are rejected by webhook
+		return r.Spec.DomainName
+	}
+	return name
+}
+
+// RedirectConfig returns the effective redirect configuration, or nil if
+// requests are served by backend Service. Redirect configured using
+// deprecated RedirectToURL has zero status code, so that routing providers
+// use their own default.
+func (r *CustomDomainRegistration) RedirectConfig() *CustomDomainRedirect {
+	if r.Spec.Redirect != nil {
+		redirect := *r.Spec.Redirect
+		if redirect.StatusCode == 0 {
+			redirect.StatusCode = DefaultRedirectStatusCode
+		}
+		return &redirect
+	}
+	if r.Spec.DomainConfig.RedirectToURL != nil {
+		return &CustomDomainRedirect{URL: *r.Spec.DomainConfig.RedirectToURL}
+	}
+	return nil
+}
+
+// TransferTarget returns the registration that ownership of the domain should
+// be transferred to.
+func (r *CustomDomainRegistration) TransferTarget() (namespace string, name string, ok bool) {
+	if r.Spec.TransferTo == nil {
+		return "", "", false
+	}
+	parts := strings.SplitN(*r.Spec.TransferTo, "/", 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
 // +kubebuilder:object:root=true
 
 // CustomDomainRegistrationList contains a list of CustomDomainRegistration
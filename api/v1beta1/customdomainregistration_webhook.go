@@ -16,51 +16,360 @@ limitations under the License.
 package v1beta1
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/skygeario/k8s-controller/api"
+	"github.com/skygeario/k8s-controller/pkg/util/dnsname"
 )
 
 // log is for logging in this package.
 var customdomainregistrationlog = logf.Log.WithName("customdomainregistration-resource")
 
+// registrationValidatingPath is the path of validating webhook of
+// registrations.
+const registrationValidatingPath = "/validate-domain-skygear-io-v1beta1-customdomainregistration"
+
 func (r *CustomDomainRegistration) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	mgr.GetWebhookServer().Register(registrationValidatingPath, &webhook.Admission{
+		Handler: &CustomDomainRegistrationValidator{},
+	})
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(r).
 		Complete()
 }
 
-// +kubebuilder:webhook:verbs=create;update,path=/validate-domain-skygear-io-v1beta1-customdomainregistration,mutating=false,failurePolicy=fail,groups=domain.skygear.io,resources=customdomainregistrations,versions=v1beta1,name=vcustomdomainregistration.kb.io
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+// +kubebuilder:webhook:verbs=create;update;delete,path=/validate-domain-skygear-io-v1beta1-customdomainregistration,mutating=false,failurePolicy=fail,groups=domain.skygear.io,resources=customdomainregistrations,versions=v1beta1,name=vcustomdomainregistration.kb.io
+
+// CustomDomainRegistrationValidator validates registrations. Client checks
+// domain quota and policy of created registrations, and authorizes changes
+// of approval. APIReader reads namespaces and owners of registrations being
+// deleted uncached, as deletion cascades right after owners are deleted.
+// Mapper resolves the scope of CustomDomain owners. They are injected by the
+// manager.
+// +kubebuilder:object:generate=false
+type CustomDomainRegistrationValidator struct {
+	Client    client.Client
+	APIReader client.Reader
+	Mapper    meta.RESTMapper
+
+	decoder *admission.Decoder
+}
+
+var _ admission.Handler = &CustomDomainRegistrationValidator{}
 
-var _ webhook.Validator = &CustomDomainRegistration{}
+func (v *CustomDomainRegistrationValidator) InjectClient(c client.Client) error {
+	v.Client = c
+	return nil
+}
+
+func (v *CustomDomainRegistrationValidator) InjectAPIReader(r client.Reader) error {
+	v.APIReader = r
+	return nil
+}
+
+func (v *CustomDomainRegistrationValidator) InjectMapper(m meta.RESTMapper) error {
+	v.Mapper = m
+	return nil
+}
 
-// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
-func (r *CustomDomainRegistration) ValidateCreate() error {
-	return r.validate(nil)
+func (v *CustomDomainRegistrationValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
 }
 
-// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
-func (r *CustomDomainRegistration) ValidateUpdate(old runtime.Object) error {
-	return r.validate(old.(*CustomDomainRegistration))
+func (v *CustomDomainRegistrationValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	var err error
+	reg := &CustomDomainRegistration{}
+	switch req.Operation {
+	case admissionv1beta1.Create:
+		if err := v.decoder.Decode(req, reg); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		err = v.ValidateCreate(ctx, reg)
+		if err == nil {
+			err = v.checkApproval(ctx, req.UserInfo, reg, &CustomDomainRegistration{})
+		}
+	case admissionv1beta1.Update:
+		old := &CustomDomainRegistration{}
+		if err := v.decoder.DecodeRaw(req.Object, reg); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if err := v.decoder.DecodeRaw(req.OldObject, old); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		err = reg.validate(old)
+		if err == nil {
+			err = v.checkApproval(ctx, req.UserInfo, reg, old)
+		}
+	case admissionv1beta1.Delete:
+		// OldObject contains the object being deleted
+		if err := v.decoder.DecodeRaw(req.OldObject, reg); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		err = v.ValidateDelete(ctx, reg)
+	}
+	if err != nil {
+		return admission.Denied(err.Error())
+	}
+	return admission.Allowed("")
 }
 
-// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
-func (r *CustomDomainRegistration) ValidateDelete() error {
+// ValidateCreate validates the registration, and checks it against domain
+// policies, reservations and quotas.
+func (v *CustomDomainRegistrationValidator) ValidateCreate(ctx context.Context, r *CustomDomainRegistration) error {
+	if err := r.validate(nil); err != nil {
+		return err
+	}
+	if v.Client == nil {
+		return errors.New("registration validator is not set up with a client")
+	}
+
+	for _, check := range []func(context.Context, client.Client, *CustomDomainRegistration) (string, error){
+		CheckPolicy,
+		CheckReservation,
+		CheckQuota,
+	} {
+		msg, err := check(ctx, v.Client, r)
+		if err != nil {
+			return err
+		}
+		if msg != "" {
+			return apierrors.NewForbidden(
+				schema.GroupResource{Group: GroupVersion.Group, Resource: "customdomainregistrations"},
+				r.Name, errors.New(msg))
+		}
+	}
 	return nil
 }
 
+// ApproveVerb is the verb on customdomainregistrations required to approve
+// registrations, so that tenants allowed to edit registrations cannot approve
+// their own registrations.
+const ApproveVerb = "approve"
+
+// checkApproval rejects changing approval of the registration, unless the
+// user is allowed to approve it.
+func (v *CustomDomainRegistrationValidator) checkApproval(ctx context.Context, user authenticationv1.UserInfo, r *CustomDomainRegistration, old *CustomDomainRegistration) error {
+	if r.Spec.Approved == old.Spec.Approved &&
+		r.Annotations[api.ApprovedAnnotation] == old.Annotations[api.ApprovedAnnotation] {
+		return nil
+	}
+	if v.Client == nil {
+		return errors.New("registration validator is not set up with a client")
+	}
+
+	extra := map[string]authorizationv1.ExtraValue{}
+	for key, value := range user.Extra {
+		extra[key] = authorizationv1.ExtraValue(value)
+	}
+	review := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: r.Namespace,
+				Verb:      ApproveVerb,
+				Group:     GroupVersion.Group,
+				Resource:  "customdomainregistrations",
+				Name:      r.Name,
+			},
+			User:   user.Username,
+			Groups: user.Groups,
+			UID:    user.UID,
+			Extra:  extra,
+		},
+	}
+	if err := v.Client.Create(ctx, review); err != nil {
+		return err
+	}
+	if !review.Status.Allowed {
+		return apierrors.NewForbidden(
+			schema.GroupResource{Group: GroupVersion.Group, Resource: "customdomainregistrations"},
+			r.Name, fmt.Errorf("user %q cannot change approval of registrations", user.Username))
+	}
+	return nil
+}
+
+// ValidateDelete rejects direct deletion of serving registrations.
+func (v *CustomDomainRegistrationValidator) ValidateDelete(ctx context.Context, r *CustomDomainRegistration) error {
+	if r.Annotations[api.ForceDeleteAnnotation] == "true" || r.isOwnedByRegistration() {
+		return nil
+	}
+	if !r.isServing() {
+		return nil
+	}
+	if v.APIReader == nil || v.Mapper == nil {
+		return errors.New("registration validator is not set up with an API reader and mapper")
+	}
+	cascading, err := v.isDeletedWithOwner(ctx, r)
+	if err != nil {
+		return err
+	}
+	if cascading {
+		return nil
+	}
+	return apierrors.NewForbidden(
+		schema.GroupResource{Group: GroupVersion.Group, Resource: "customdomainregistrations"},
+		r.Name, fmt.Errorf("domain %s is serving traffic; set annotation %s=true to delete it", r.Spec.DomainName, api.ForceDeleteAnnotation))
+}
+
+// isServing returns whether the verified domain has active routing or
+// certificate, so that deleting the registration would cause outage.
+func (r *CustomDomainRegistration) isServing() bool {
+	isTrue := func(condType CustomDomainRegistrationConditionType) bool {
+		for _, cond := range r.Status.Conditions {
+			if cond.Type == string(condType) {
+				return cond.Status == metav1.ConditionTrue
+			}
+		}
+		return false
+	}
+	return isTrue(RegistrationVerified) &&
+		(isTrue(RegistrationIngressReady) || isTrue(RegistrationCertReady))
+}
+
+// isOwnedByRegistration returns whether the registration is managed by
+// another registration (e.g. www/apex sibling), and is deleted along with it.
+func (r *CustomDomainRegistration) isOwnedByRegistration() bool {
+	for _, ref := range r.OwnerReferences {
+		if ref.Kind == "CustomDomainRegistration" && strings.HasPrefix(ref.APIVersion, GroupVersion.Group+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// isDeletedWithOwner returns whether the registration is deleted along with
+// its namespace by namespace controller, or along with its CustomDomain by
+// garbage collector. Only direct deletion of serving registrations is
+// rejected.
+func (v *CustomDomainRegistrationValidator) isDeletedWithOwner(ctx context.Context, r *CustomDomainRegistration) (bool, error) {
+	var ns corev1.Namespace
+	err := v.APIReader.Get(ctx, types.NamespacedName{Name: r.Namespace}, &ns)
+	if apierrors.IsNotFound(err) || (err == nil && ns.DeletionTimestamp != nil) {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	for _, ref := range r.OwnerReferences {
+		if ref.Kind != "CustomDomain" || !strings.HasPrefix(ref.APIVersion, GroupVersion.Group+"/") {
+			continue
+		}
+		namespace, err := v.ownerNamespace(r)
+		if err != nil {
+			return false, err
+		}
+		var owner CustomDomain
+		err = v.APIReader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, &owner)
+		if apierrors.IsNotFound(err) || (err == nil && (owner.UID != ref.UID || owner.DeletionTimestamp != nil)) {
+			return true, nil
+		} else if err != nil {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// ownerNamespace returns the namespace of CustomDomain owners of the
+// registration. Owner references across namespaces are not supported, so
+// owners are in the namespace of the registration if CustomDomain is
+// installed as namespaced.
+func (v *CustomDomainRegistrationValidator) ownerNamespace(r *CustomDomainRegistration) (string, error) {
+	gvk := GroupVersion.WithKind("CustomDomain")
+	mapping, err := v.Mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return "", err
+	}
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		return r.Namespace, nil
+	}
+	return "", nil
+}
+
 func (r *CustomDomainRegistration) validate(old *CustomDomainRegistration) error {
 	var errs field.ErrorList
 	if old != nil && old.Name != r.Name {
 		errs = append(errs, field.Invalid(field.NewPath("metadata", "name"), r.Name, "resource name cannot be changed"))
 	}
-	if r.Name != r.Spec.DomainName {
+	if old != nil && r.Spec.DedicatedIP != old.Spec.DedicatedIP {
+		errs = append(errs, field.Invalid(field.NewPath("spec", "dedicatedIP"), r.Spec.DedicatedIP, "dedicatedIP cannot be changed"))
+	}
+	domainName, err := dnsname.Normalize(r.Spec.DomainName)
+	if err != nil {
+		errs = append(errs, field.Invalid(field.NewPath("spec", "domainName"), r.Spec.DomainName, fmt.Sprintf("invalid domain name: %s", err)))
+		domainName = r.Spec.DomainName
+	} else if err := dnsname.Validate(domainName); err != nil && old == nil {
+		// existing registrations are not affected
+		errs = append(errs, field.Invalid(field.NewPath("spec", "domainName"), r.Spec.DomainName, fmt.Sprintf("invalid domain name: %s", err)))
+	} else if r.Name != dnsname.ResourceName(domainName) {
 		errs = append(errs, field.Invalid(field.NewPath("spec", "domainName"), r.Spec.DomainName, "domainName must be same as resource name"))
 	}
+	if dnsname.IsWildcard(domainName) {
+		if _, err := publicsuffix.EffectiveTLDPlusOne(dnsname.TrimWildcard(domainName)); err != nil {
+			errs = append(errs, field.Invalid(field.NewPath("spec", "domainName"), r.Spec.DomainName, "wildcard domain must be under a registrable domain"))
+		}
+	}
+	if v := r.Spec.Verification; v != nil && v.RecordType != "" && v.RecordType != VerificationRecordTypeTXT &&
+		v.Method != "" && v.Method != VerificationMethodDNS {
+		errs = append(errs, field.Invalid(field.NewPath("spec", "verification", "recordType"), v.RecordType, "only applicable to DNS verification"))
+	}
+	if class := r.Spec.LoadBalancerClass; class != "" {
+		// The class names LoadBalancerEndpoint and labels load balancer
+		// Services.
+		for _, msg := range append(validation.IsDNS1123Subdomain(class), validation.IsValidLabelValue(class)...) {
+			errs = append(errs, field.Invalid(field.NewPath("spec", "loadBalancerClass"), class, msg))
+		}
+	}
+	if redirect := r.Spec.Redirect; redirect != nil {
+		redirectPath := field.NewPath("spec", "redirect")
+		if r.Spec.DomainConfig.RedirectToURL != nil {
+			errs = append(errs, field.Forbidden(redirectPath, "cannot be used with spec.domainConfig.redirectToURL"))
+		}
+		u, err := url.Parse(redirect.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, field.Invalid(redirectPath.Child("url"), redirect.URL, "must be an absolute HTTP or HTTPS URL"))
+		} else if redirect.PreservePath && ((u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "") {
+			errs = append(errs, field.Invalid(redirectPath.Child("url"), redirect.URL, "cannot contain path, query or fragment when path is preserved"))
+		}
+	}
+	if r.Spec.RedirectSibling {
+		if _, ok := dnsname.Sibling(domainName); !ok {
+			errs = append(errs, field.Invalid(field.NewPath("spec", "redirectSibling"), r.Spec.RedirectSibling, "domain must be an apex domain or its www subdomain"))
+		}
+	}
+	if namespace, name, ok := r.TransferTarget(); ok {
+		if !r.IsPrimary() {
+			errs = append(errs, field.Invalid(field.NewPath("spec", "transferTo"), *r.Spec.TransferTo, "only primary registration can transfer domain"))
+		} else if name != r.Name {
+			errs = append(errs, field.Invalid(field.NewPath("spec", "transferTo"), *r.Spec.TransferTo, "target registration must register the same domain"))
+		} else if namespace == r.Namespace {
+			errs = append(errs, field.Invalid(field.NewPath("spec", "transferTo"), *r.Spec.TransferTo, "cannot transfer domain to the registration itself"))
+		}
+	}
 
 	if len(errs) != 0 {
 		return apierrors.NewInvalid(
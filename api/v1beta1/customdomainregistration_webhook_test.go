@@ -0,0 +1,270 @@
+package v1beta1
+
+import (
+	"context"
+	"testing"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/skygeario/k8s-controller/api"
+)
+
+func TestValidateDelete(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	now := metav1.Now()
+	activeNamespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "app"}}
+	terminatingNamespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "app", DeletionTimestamp: &now}}
+	activeDomain := &CustomDomain{ObjectMeta: metav1.ObjectMeta{Name: "example.com", UID: "domain-uid"}}
+	terminatingDomain := &CustomDomain{ObjectMeta: metav1.ObjectMeta{Name: "example.com", UID: "domain-uid", DeletionTimestamp: &now}}
+	recreatedDomain := &CustomDomain{ObjectMeta: metav1.ObjectMeta{Name: "example.com", UID: "new-domain-uid"}}
+	namespacedDomain := &CustomDomain{ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "example.com", UID: "domain-uid"}}
+	terminatingNamespacedDomain := &CustomDomain{ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "example.com", UID: "domain-uid", DeletionTimestamp: &now}}
+
+	newMapper := func(scope meta.RESTScope) meta.RESTMapper {
+		mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{GroupVersion})
+		mapper.Add(GroupVersion.WithKind("CustomDomain"), scope)
+		return mapper
+	}
+
+	servingConditions := []api.Condition{
+		{Type: string(RegistrationVerified), Status: metav1.ConditionTrue},
+		{Type: string(RegistrationIngressReady), Status: metav1.ConditionTrue},
+	}
+	newReg := func(conditions []api.Condition, annotations map[string]string) *CustomDomainRegistration {
+		controller := true
+		return &CustomDomainRegistration{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "app",
+				Name:        "example.com",
+				Annotations: annotations,
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: GroupVersion.String(),
+					Kind:       "CustomDomain",
+					Name:       "example.com",
+					UID:        "domain-uid",
+					Controller: &controller,
+				}},
+			},
+			Spec:   CustomDomainRegistrationSpec{DomainName: "example.com"},
+			Status: CustomDomainRegistrationStatus{Conditions: conditions},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		reg        *CustomDomainRegistration
+		objects    []runtime.Object
+		namespaced bool
+		forbidden  bool
+	}{
+		{
+			name:    "not serving",
+			reg:     newReg(servingConditions[:1], nil),
+			objects: []runtime.Object{activeNamespace, activeDomain},
+		},
+		{
+			name:    "force delete",
+			reg:     newReg(servingConditions, map[string]string{api.ForceDeleteAnnotation: "true"}),
+			objects: []runtime.Object{activeNamespace, activeDomain},
+		},
+		{
+			name:      "direct delete of serving registration",
+			reg:       newReg(servingConditions, nil),
+			objects:   []runtime.Object{activeNamespace, activeDomain},
+			forbidden: true,
+		},
+		{
+			name:    "namespace terminating",
+			reg:     newReg(servingConditions, nil),
+			objects: []runtime.Object{terminatingNamespace, activeDomain},
+		},
+		{
+			name:    "owner terminating",
+			reg:     newReg(servingConditions, nil),
+			objects: []runtime.Object{activeNamespace, terminatingDomain},
+		},
+		{
+			name:    "owner deleted",
+			reg:     newReg(servingConditions, nil),
+			objects: []runtime.Object{activeNamespace},
+		},
+		{
+			name:    "owner recreated",
+			reg:     newReg(servingConditions, nil),
+			objects: []runtime.Object{activeNamespace, recreatedDomain},
+		},
+		{
+			name:       "direct delete of serving registration with namespaced domain",
+			reg:        newReg(servingConditions, nil),
+			objects:    []runtime.Object{activeNamespace, namespacedDomain},
+			namespaced: true,
+			forbidden:  true,
+		},
+		{
+			name:       "namespaced owner terminating",
+			reg:        newReg(servingConditions, nil),
+			objects:    []runtime.Object{activeNamespace, terminatingNamespacedDomain},
+			namespaced: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scope := meta.RESTScopeRoot
+			if tt.namespaced {
+				scope = meta.RESTScopeNamespace
+			}
+			v := &CustomDomainRegistrationValidator{
+				APIReader: fake.NewFakeClientWithScheme(scheme, tt.objects...),
+				Mapper:    newMapper(scope),
+			}
+			err := v.ValidateDelete(context.Background(), tt.reg)
+			if tt.forbidden && !apierrors.IsForbidden(err) {
+				t.Errorf("expected forbidden, got %v", err)
+			} else if !tt.forbidden && err != nil {
+				t.Errorf("expected allowed, got %v", err)
+			}
+		})
+	}
+}
+
+func TestValidatorNotSetUp(t *testing.T) {
+	v := &CustomDomainRegistrationValidator{}
+	reg := &CustomDomainRegistration{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "example.com"},
+		Spec:       CustomDomainRegistrationSpec{DomainName: "example.com"},
+		Status: CustomDomainRegistrationStatus{Conditions: []api.Condition{
+			{Type: string(RegistrationVerified), Status: metav1.ConditionTrue},
+			{Type: string(RegistrationIngressReady), Status: metav1.ConditionTrue},
+		}},
+	}
+	if err := v.ValidateCreate(context.Background(), reg); err == nil {
+		t.Error("expected create to fail without client")
+	}
+	if err := v.ValidateDelete(context.Background(), reg); err == nil {
+		t.Error("expected delete to fail without API reader and mapper")
+	}
+}
+
+// reviewClient allows subject access reviews of operator only.
+type reviewClient struct {
+	client.Client
+	reviews []*authorizationv1.SubjectAccessReview
+}
+
+func (c *reviewClient) Create(ctx context.Context, obj runtime.Object, opts ...client.CreateOption) error {
+	if review, ok := obj.(*authorizationv1.SubjectAccessReview); ok {
+		c.reviews = append(c.reviews, review)
+		review.Status.Allowed = review.Spec.User == "operator" &&
+			review.Spec.ResourceAttributes.Verb == ApproveVerb
+		return nil
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func TestCheckApproval(t *testing.T) {
+	newReg := func(approved bool, annotations map[string]string) *CustomDomainRegistration {
+		return &CustomDomainRegistration{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "example.com", Annotations: annotations},
+			Spec:       CustomDomainRegistrationSpec{DomainName: "example.com", Approved: approved},
+		}
+	}
+	approvedAnnotation := map[string]string{api.ApprovedAnnotation: "true"}
+
+	tests := []struct {
+		name      string
+		user      string
+		reg       *CustomDomainRegistration
+		old       *CustomDomainRegistration
+		reviewed  bool
+		forbidden bool
+	}{
+		{
+			name: "create unapproved",
+			user: "tenant",
+			reg:  newReg(false, nil),
+			old:  &CustomDomainRegistration{},
+		},
+		{
+			name:      "create approved by tenant",
+			user:      "tenant",
+			reg:       newReg(true, nil),
+			old:       &CustomDomainRegistration{},
+			reviewed:  true,
+			forbidden: true,
+		},
+		{
+			name:     "create approved by operator",
+			user:     "operator",
+			reg:      newReg(true, nil),
+			old:      &CustomDomainRegistration{},
+			reviewed: true,
+		},
+		{
+			name:      "approve by annotation by tenant",
+			user:      "tenant",
+			reg:       newReg(false, approvedAnnotation),
+			old:       newReg(false, nil),
+			reviewed:  true,
+			forbidden: true,
+		},
+		{
+			name:     "approve by annotation by operator",
+			user:     "operator",
+			reg:      newReg(false, approvedAnnotation),
+			old:      newReg(false, nil),
+			reviewed: true,
+		},
+		{
+			name:      "revoke by tenant",
+			user:      "tenant",
+			reg:       newReg(false, nil),
+			old:       newReg(true, nil),
+			reviewed:  true,
+			forbidden: true,
+		},
+		{
+			name: "update approved registration by tenant",
+			user: "tenant",
+			reg:  newReg(true, approvedAnnotation),
+			old:  newReg(true, approvedAnnotation),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &reviewClient{Client: fake.NewFakeClient()}
+			v := &CustomDomainRegistrationValidator{Client: c}
+			err := v.checkApproval(context.Background(), authenticationv1.UserInfo{Username: tt.user}, tt.reg, tt.old)
+			if tt.forbidden && !apierrors.IsForbidden(err) {
+				t.Errorf("expected forbidden, got %v", err)
+			} else if !tt.forbidden && err != nil {
+				t.Errorf("expected allowed, got %v", err)
+			}
+			if reviewed := len(c.reviews) != 0; reviewed != tt.reviewed {
+				t.Errorf("reviewed = %v, expected %v", reviewed, tt.reviewed)
+			}
+			for _, review := range c.reviews {
+				attrs := review.Spec.ResourceAttributes
+				if attrs.Namespace != "app" || attrs.Name != "example.com" || attrs.Resource != "customdomainregistrations" {
+					t.Errorf("unexpected review attributes: %#v", attrs)
+				}
+			}
+		})
+	}
+}
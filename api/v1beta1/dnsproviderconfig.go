@@ -0,0 +1,50 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// FindDNSProviderConfig finds the DNS provider config managing records of the
+// domain, and the matched zone. The config with the longest matching zone is
+// used; ties are broken by config name. It returns nil if no configs match.
+func FindDNSProviderConfig(ctx context.Context, c client.Client, domain string) (*DNSProviderConfig, string, error) {
+	var configs DNSProviderConfigList
+	if err := c.List(ctx, &configs); err != nil {
+		return nil, "", err
+	}
+
+	var matched *DNSProviderConfig
+	matchedZone := ""
+	for i, config := range configs.Items {
+		for _, zone := range config.Spec.Zones {
+			zone = strings.ToLower(strings.TrimSuffix(zone, "."))
+			if !isInZone(domain, zone) {
+				continue
+			}
+			if matched == nil || len(zone) > len(matchedZone) ||
+				(len(zone) == len(matchedZone) && config.Name < matched.Name) {
+				matched = &configs.Items[i]
+				matchedZone = zone
+			}
+		}
+	}
+	return matched, matchedZone, nil
+}
@@ -0,0 +1,117 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SecretKeyReference is a reference to a key of Secret
+type SecretKeyReference struct {
+	// Namespace is the namespace of Secret.
+	Namespace string `json:"namespace"`
+	// Name is the name of Secret.
+	Name string `json:"name"`
+	// Key is the key of Secret data.
+	Key string `json:"key"`
+}
+
+// DNSEndpointProviderConfig configures emitting DNSEndpoint resources to be
+// consumed by external-dns
+type DNSEndpointProviderConfig struct {
+	// Namespace is the namespace of DNSEndpoint resources created.
+	Namespace string `json:"namespace"`
+	// RecordTTL is the TTL in seconds of records. Defaults to external-dns
+	// default.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	RecordTTL int64 `json:"recordTTL,omitempty"`
+	// Labels are additional labels of DNSEndpoint resources, e.g. to match
+	// label filter of the external-dns instance managing the zones.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// CloudflareProviderConfig configures managing records using Cloudflare API
+type CloudflareProviderConfig struct {
+	// APITokenSecretRef is the Secret key storing the API token, with
+	// permission to edit DNS records of the zones.
+	APITokenSecretRef SecretKeyReference `json:"apiTokenSecretRef"`
+	// RecordTTL is the TTL in seconds of records. Defaults to automatic TTL.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	RecordTTL int64 `json:"recordTTL,omitempty"`
+	// Proxied is whether traffic of records is proxied by Cloudflare.
+	// +optional
+	Proxied bool `json:"proxied,omitempty"`
+}
+
+// GoogleCloudDNSProviderConfig configures managing records using Google
+// Cloud DNS API. Requests are authorized with ambient credentials of GKE
+// Workload Identity: access tokens of the Google service account bound to
+// the Kubernetes service account of the controller, refreshed before
+// expiry. No key is stored in the cluster.
+type GoogleCloudDNSProviderConfig struct {
+	// Project is the Google Cloud project of the managed zones.
+	Project string `json:"project"`
+	// RecordTTL is the TTL in seconds of records. Defaults to 300.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	RecordTTL int64 `json:"recordTTL,omitempty"`
+}
+
+// DNSProviderConfigSpec defines the desired state of DNSProviderConfig.
+// Exactly one provider should be configured.
+type DNSProviderConfigSpec struct {
+	// Zones are the DNS zones managed by the provider. Records of domains
+	// under the zones are managed by the provider, in preference to
+	// providers of parent zones.
+	// +kubebuilder:validation:MinItems=1
+	Zones []string `json:"zones"`
+	// DNSEndpoint emits DNSEndpoint resources consumed by external-dns.
+	// +optional
+	DNSEndpoint *DNSEndpointProviderConfig `json:"dnsEndpoint,omitempty"`
+	// Cloudflare manages records using Cloudflare API.
+	// +optional
+	Cloudflare *CloudflareProviderConfig `json:"cloudflare,omitempty"`
+	// GoogleCloudDNS manages records using Google Cloud DNS API.
+	// +optional
+	GoogleCloudDNS *GoogleCloudDNSProviderConfig `json:"googleCloudDNS,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+
+// DNSProviderConfig is the Schema for the dnsproviderconfigs API
+type DNSProviderConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec DNSProviderConfigSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// DNSProviderConfigList contains a list of DNSProviderConfig
+type DNSProviderConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DNSProviderConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DNSProviderConfig{}, &DNSProviderConfigList{})
+}
@@ -0,0 +1,116 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DomainImportItem is a domain to be imported
+type DomainImportItem struct {
+	// DomainName is the domain name to import.
+	DomainName string `json:"domainName"`
+	// Namespace is the namespace of registration created.
+	Namespace string `json:"namespace"`
+	// Name is the name of registration created. Defaults to the domain
+	// name.
+	// +optional
+	Name string `json:"name,omitempty"`
+	// DomainConfig is the configuration of registration created.
+	DomainConfig CustomDomainConfig `json:"domainConfig"`
+}
+
+// DomainImportSpec defines the desired state of DomainImport
+type DomainImportSpec struct {
+	// Items are the domains to import.
+	Items []DomainImportItem `json:"items"`
+}
+
+// DomainImportItemPhase is the phase of importing a domain
+type DomainImportItemPhase string
+
+const (
+	// DomainImportItemPending indicates the registration is not created yet.
+	DomainImportItemPending DomainImportItemPhase = "Pending"
+	// DomainImportItemImported indicates the registration is created and
+	// attested.
+	DomainImportItemImported DomainImportItemPhase = "Imported"
+	// DomainImportItemFailed indicates the registration cannot be created.
+	DomainImportItemFailed DomainImportItemPhase = "Failed"
+)
+
+// DomainImportItemStatus is the status of importing a domain
+type DomainImportItemStatus struct {
+	// DomainName is the imported domain name.
+	DomainName string `json:"domainName"`
+	// Namespace is the namespace of registration.
+	Namespace string `json:"namespace"`
+	// Name is the name of registration.
+	// +optional
+	Name string `json:"name,omitempty"`
+	// Phase is the phase of importing the domain.
+	Phase DomainImportItemPhase `json:"phase"`
+	// Message describes why the domain cannot be imported.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// DomainImportStatus defines the observed state of DomainImport
+type DomainImportStatus struct {
+	// ObservedGeneration is the most recent generation observed by controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Items are the status of imported domains, in order of spec.
+	// +optional
+	Items []DomainImportItemStatus `json:"items,omitempty"`
+	// Imported is the number of imported domains.
+	// +optional
+	Imported int `json:"imported,omitempty"`
+	// Failed is the number of domains failed to import.
+	// +optional
+	Failed int `json:"failed,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Imported",type=integer,JSONPath=`.status.imported`
+// +kubebuilder:printcolumn:name="Failed",type=integer,JSONPath=`.status.failed`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// DomainImport is the Schema for the domainimports API. It creates
+// registrations of existing domains, attested by operator so that they are
+// verified without verification records.
+type DomainImport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DomainImportSpec   `json:"spec,omitempty"`
+	Status DomainImportStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// DomainImportList contains a list of DomainImport
+type DomainImportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DomainImport `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DomainImport{}, &DomainImportList{})
+}
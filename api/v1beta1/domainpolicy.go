@@ -0,0 +1,160 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/skygeario/k8s-controller/pkg/util/dnsname"
+)
+
+// CheckPolicy checks whether the registration violates domain policies of
+// its namespace. It returns a message describing the violation, or empty
+// string if the registration is allowed.
+func CheckPolicy(ctx context.Context, c client.Client, reg *CustomDomainRegistration) (string, error) {
+	var policies DomainPolicyList
+	if err := c.List(ctx, &policies); err != nil {
+		return "", err
+	}
+
+	for _, policy := range policies.Items {
+		if !appliesToNamespace(policy.Spec.Namespaces, reg.Namespace) {
+			continue
+		}
+		if msg := policy.check(reg.ASCIIDomainName()); msg != "" {
+			return fmt.Sprintf("%s (policy %s)", msg, policy.Name), nil
+		}
+	}
+	return "", nil
+}
+
+// RequiresApproval returns whether the registration requires approval of
+// operators, by the default mode of controller or domain policies of its
+// namespace.
+func RequiresApproval(ctx context.Context, c client.Client, reg *CustomDomainRegistration, defaultMode ApprovalMode) (bool, error) {
+	domain := reg.ASCIIDomainName()
+	if defaultMode.appliesTo(domain) {
+		return true, nil
+	}
+
+	var policies DomainPolicyList
+	if err := c.List(ctx, &policies); err != nil {
+		return false, err
+	}
+	for _, policy := range policies.Items {
+		if !appliesToNamespace(policy.Spec.Namespaces, reg.Namespace) {
+			continue
+		}
+		if policy.Spec.RequireApproval.appliesTo(domain) {
+			return true, nil
+		}
+		if policy.Spec.RequireApprovalOfSuspiciousDomains && policy.confusableBrand(domain) != "" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// CheckSuspicious returns a message if the registered domain is visually
+// confusable with a brand protected by domain policies of its namespace.
+func CheckSuspicious(ctx context.Context, c client.Client, reg *CustomDomainRegistration) (string, error) {
+	var policies DomainPolicyList
+	if err := c.List(ctx, &policies); err != nil {
+		return "", err
+	}
+
+	for _, policy := range policies.Items {
+		if !appliesToNamespace(policy.Spec.Namespaces, reg.Namespace) {
+			continue
+		}
+		if brand := policy.confusableBrand(reg.ASCIIDomainName()); brand != "" {
+			return fmt.Sprintf("domain is confusable with protected brand %s (policy %s)", brand, policy.Name), nil
+		}
+	}
+	return "", nil
+}
+
+// confusableBrand returns the protected brand that a label of domain is
+// visually confusable with.
+func (p *DomainPolicy) confusableBrand(domain string) string {
+	if len(p.Spec.ProtectedBrands) == 0 {
+		return ""
+	}
+	name, err := dnsname.ToUnicode(domain)
+	if err != nil {
+		name = domain
+	}
+	for _, label := range strings.Split(dnsname.TrimWildcard(name), ".") {
+		for _, brand := range p.Spec.ProtectedBrands {
+			if dnsname.Confusable(label, brand) {
+				return brand
+			}
+		}
+	}
+	return ""
+}
+
+func (m ApprovalMode) appliesTo(domain string) bool {
+	switch m {
+	case ApprovalModeAll:
+		return true
+	case ApprovalModeApexDomains:
+		apex, err := publicsuffix.EffectiveTLDPlusOne(dnsname.TrimWildcard(domain))
+		return err == nil && apex == dnsname.TrimWildcard(domain)
+	}
+	return false
+}
+
+func (p *DomainPolicy) check(domain string) string {
+	if len(p.Spec.AllowedSuffixes) > 0 {
+		allowed := false
+		for _, suffix := range p.Spec.AllowedSuffixes {
+			if isInZone(domain, suffix) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return "domain is not under allowed domains"
+		}
+	}
+
+	if apex, err := publicsuffix.EffectiveTLDPlusOne(dnsname.TrimWildcard(domain)); err == nil {
+		for _, forbidden := range p.Spec.ForbiddenApexDomains {
+			if apex == forbidden {
+				return fmt.Sprintf("domain under %s is forbidden", forbidden)
+			}
+		}
+	}
+
+	for _, pattern := range p.Spec.DenyPatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			// ignore invalid patterns
+			continue
+		}
+		if re.MatchString(domain) {
+			return fmt.Sprintf("domain matches denied pattern %s", pattern)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,93 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ApprovalMode is the set of registrations requiring approval of operators
+// before accepted.
+// +kubebuilder:validation:Enum=All;ApexDomains
+type ApprovalMode string
+
+const (
+	// ApprovalModeAll requires approval of all registrations.
+	ApprovalModeAll ApprovalMode = "All"
+	// ApprovalModeApexDomains requires approval of registrations of apex
+	// domains.
+	ApprovalModeApexDomains ApprovalMode = "ApexDomains"
+)
+
+// DomainPolicySpec defines the desired state of DomainPolicy
+type DomainPolicySpec struct {
+	// Namespaces are the namespaces that the policy applies to. The policy
+	// applies to all namespaces if empty.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+	// AllowedSuffixes are the domains that registered domains must be under.
+	// All domains are allowed if empty.
+	// +optional
+	AllowedSuffixes []string `json:"allowedSuffixes,omitempty"`
+	// ForbiddenApexDomains are the apex domains that registered domains must
+	// not be under.
+	// +optional
+	ForbiddenApexDomains []string `json:"forbiddenApexDomains,omitempty"`
+	// DenyPatterns are the regular expressions that registered domains must
+	// not match. Invalid patterns are ignored.
+	// +optional
+	DenyPatterns []string `json:"denyPatterns,omitempty"`
+	// RequireApproval requires registrations to be approved by operators
+	// before accepted, by setting spec.approved or annotation
+	// domain.skygear.io/approved.
+	// +optional
+	RequireApproval ApprovalMode `json:"requireApproval,omitempty"`
+	// ProtectedBrands are the brand names that registered domains must not
+	// be visually confusable with, e.g. IDN homographs such as
+	// "ѕkygear.io" for brand "skygear". Registrations of confusable domains
+	// are flagged with condition SuspiciousDomain.
+	// +optional
+	ProtectedBrands []string `json:"protectedBrands,omitempty"`
+	// RequireApprovalOfSuspiciousDomains requires registrations of domains
+	// confusable with protected brands to be approved by operators before
+	// accepted.
+	// +optional
+	RequireApprovalOfSuspiciousDomains bool `json:"requireApprovalOfSuspiciousDomains,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+
+// DomainPolicy is the Schema for the domainpolicies API
+type DomainPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec DomainPolicySpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// DomainPolicyList contains a list of DomainPolicy
+type DomainPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DomainPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DomainPolicy{}, &DomainPolicyList{})
+}
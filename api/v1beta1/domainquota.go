@@ -0,0 +1,102 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/skygeario/k8s-controller/pkg/util/dnsname"
+)
+
+// CheckQuota checks whether the registration exceeds domain quotas of its
+// namespace, counting registrations created before it. It returns a message
+// describing the exceeded quota, or empty string if within quota.
+func CheckQuota(ctx context.Context, c client.Client, reg *CustomDomainRegistration) (string, error) {
+	var quotas DomainQuotaList
+	if err := c.List(ctx, &quotas); err != nil {
+		return "", err
+	}
+
+	var regs CustomDomainRegistrationList
+	if err := c.List(ctx, &regs, client.InNamespace(reg.Namespace)); err != nil {
+		return "", err
+	}
+	var prior []CustomDomainRegistration
+	for _, r := range regs.Items {
+		if r.Name == reg.Name || r.DeletionTimestamp != nil {
+			continue
+		}
+		if !reg.CreationTimestamp.IsZero() && !isCreatedBefore(&r, reg) {
+			continue
+		}
+		prior = append(prior, r)
+	}
+
+	for _, quota := range quotas.Items {
+		if !appliesToNamespace(quota.Spec.Namespaces, reg.Namespace) {
+			continue
+		}
+		if quota.Spec.MaxRegistrations != nil && len(prior) >= *quota.Spec.MaxRegistrations {
+			return fmt.Sprintf("namespace has reached the maximum of %d registrations (quota %s)",
+				*quota.Spec.MaxRegistrations, quota.Name), nil
+		}
+		for _, limit := range quota.Spec.ZoneLimits {
+			if !isInZone(reg.ASCIIDomainName(), limit.Zone) {
+				continue
+			}
+			n := 0
+			for _, r := range prior {
+				if isInZone(r.ASCIIDomainName(), limit.Zone) {
+					n++
+				}
+			}
+			if n >= limit.MaxRegistrations {
+				return fmt.Sprintf("namespace has reached the maximum of %d registrations under %s (quota %s)",
+					limit.MaxRegistrations, limit.Zone, quota.Name), nil
+			}
+		}
+	}
+	return "", nil
+}
+
+func appliesToNamespace(namespaces []string, namespace string) bool {
+	if len(namespaces) == 0 {
+		return true
+	}
+	for _, ns := range namespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+func isCreatedBefore(a, b *CustomDomainRegistration) bool {
+	if a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.Name < b.Name
+	}
+	return a.CreationTimestamp.Before(&b.CreationTimestamp)
+}
+
+func isInZone(domain string, zone string) bool {
+	domain = dnsname.TrimWildcard(domain)
+	zone = strings.TrimSuffix(zone, ".")
+	return domain == zone || strings.HasSuffix(domain, "."+zone)
+}
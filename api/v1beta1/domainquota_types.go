@@ -0,0 +1,67 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DomainQuotaZoneLimit is the limit of registrations under a zone
+type DomainQuotaZoneLimit struct {
+	// Zone is the domain that the limit applies to, including its sub-domains.
+	Zone string `json:"zone"`
+	// MaxRegistrations is the maximum number of registrations under the zone
+	// in each namespace.
+	MaxRegistrations int `json:"maxRegistrations"`
+}
+
+// DomainQuotaSpec defines the desired state of DomainQuota
+type DomainQuotaSpec struct {
+	// Namespaces are the namespaces that the quota applies to. The quota
+	// applies to all namespaces if empty.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+	// MaxRegistrations is the maximum number of registrations in each namespace.
+	// +optional
+	MaxRegistrations *int `json:"maxRegistrations,omitempty"`
+	// ZoneLimits are the limits of registrations under specific zones.
+	// +optional
+	ZoneLimits []DomainQuotaZoneLimit `json:"zoneLimits,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+
+// DomainQuota is the Schema for the domainquotas API
+type DomainQuota struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec DomainQuotaSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// DomainQuotaList contains a list of DomainQuota
+type DomainQuotaList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DomainQuota `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DomainQuota{}, &DomainQuotaList{})
+}
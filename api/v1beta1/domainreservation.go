@@ -0,0 +1,63 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/skygeario/k8s-controller/pkg/util/dnsname"
+)
+
+// CheckReservation checks whether the registration is for a hostname
+// reserved for other namespaces. It returns a message describing the
+// reservation, or empty string if the registration is allowed.
+func CheckReservation(ctx context.Context, c client.Client, reg *CustomDomainRegistration) (string, error) {
+	var reservations DomainReservationList
+	if err := c.List(ctx, &reservations); err != nil {
+		return "", err
+	}
+
+	domain := reg.ASCIIDomainName()
+	for _, reservation := range reservations.Items {
+		if len(reservation.Spec.Namespaces) > 0 && appliesToNamespace(reservation.Spec.Namespaces, reg.Namespace) {
+			continue
+		}
+		for _, hostname := range reservation.Spec.Hostnames {
+			if matchesReservedHostname(domain, hostname) {
+				return fmt.Sprintf("domain is reserved by %s (reservation %s)", hostname, reservation.Name), nil
+			}
+		}
+	}
+	return "", nil
+}
+
+func matchesReservedHostname(domain string, hostname string) bool {
+	hostname = strings.ToLower(strings.TrimSuffix(hostname, "."))
+	switch {
+	case strings.HasPrefix(hostname, "*."):
+		zone := dnsname.TrimWildcard(hostname)
+		return domain == hostname || strings.HasSuffix(dnsname.TrimWildcard(domain), "."+zone)
+	case strings.HasSuffix(hostname, ".*"):
+		label := strings.TrimSuffix(hostname, ".*")
+		return strings.HasPrefix(dnsname.TrimWildcard(domain), label+".")
+	default:
+		return domain == hostname
+	}
+}
@@ -0,0 +1,59 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DomainReservationSpec defines the desired state of DomainReservation
+type DomainReservationSpec struct {
+	// Hostnames are the reserved hostnames. A hostname may be exact (e.g.
+	// example.com), match all subdomains (e.g. *.example.com), or match
+	// the first label under any domain (e.g. admin.*).
+	Hostnames []string `json:"hostnames"`
+	// Namespaces are the namespaces allowed to register reserved hostnames.
+	// No namespaces are allowed if empty.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Description describes the purpose of the reservation.
+	// +optional
+	Description string `json:"description,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+
+// DomainReservation is the Schema for the domainreservations API
+type DomainReservation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec DomainReservationSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// DomainReservationList contains a list of DomainReservation
+type DomainReservationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DomainReservation `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DomainReservation{}, &DomainReservationList{})
+}
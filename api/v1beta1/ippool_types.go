@@ -0,0 +1,75 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IPPoolSpec defines the desired state of IPPool
+type IPPoolSpec struct {
+	// Addresses are the IP addresses allocated to domains requesting
+	// dedicated IP addresses.
+	// +kubebuilder:validation:MinItems=1
+	Addresses []string `json:"addresses"`
+	// Service is the cloud load balancer Service serving the addresses.
+	Service LoadBalancerEndpointReference `json:"service"`
+	// Annotation is the annotation of Service listing the allocated
+	// addresses, separated by commas, e.g.
+	// metallb.universe.tf/loadBalancerIPs.
+	Annotation string `json:"annotation"`
+}
+
+// IPPoolAllocation is an address of IP pool allocated to a domain
+type IPPoolAllocation struct {
+	// Address is the allocated IP address.
+	Address string `json:"address"`
+	// Domain is the domain name that the address is allocated to.
+	Domain string `json:"domain"`
+}
+
+// IPPoolStatus defines the observed state of IPPool
+type IPPoolStatus struct {
+	// Allocations are the addresses allocated to domains.
+	// +optional
+	Allocations []IPPoolAllocation `json:"allocations,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+
+// IPPool is the Schema for the ippools API
+type IPPool struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   IPPoolSpec   `json:"spec,omitempty"`
+	Status IPPoolStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// IPPoolList contains a list of IPPool
+type IPPoolList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []IPPool `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&IPPool{}, &IPPoolList{})
+}
@@ -0,0 +1,68 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// LoadBalancerEndpointReference is a reference to a load balancer Service or
+// Ingress
+type LoadBalancerEndpointReference struct {
+	// Namespace is the namespace of the load balancer.
+	Namespace string `json:"namespace"`
+	// Name is the name of the load balancer.
+	Name string `json:"name"`
+}
+
+// LoadBalancerEndpointSpec defines the desired state of LoadBalancerEndpoint.
+// Exactly one of Service and Ingress should be set.
+type LoadBalancerEndpointSpec struct {
+	// Service is the load balancer Service, whose load balancer status are
+	// the DNS targets of domains.
+	// +optional
+	Service *LoadBalancerEndpointReference `json:"service,omitempty"`
+	// Ingress is the load balancer Ingress, whose load balancer status are
+	// the DNS targets of domains.
+	// +optional
+	Ingress *LoadBalancerEndpointReference `json:"ingress,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+
+// LoadBalancerEndpoint is the Schema for the loadbalancerendpoints API. The
+// name of LoadBalancerEndpoint is the load balancer class selected by
+// loadBalancerClass of registrations.
+type LoadBalancerEndpoint struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec LoadBalancerEndpointSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// LoadBalancerEndpointList contains a list of LoadBalancerEndpoint
+type LoadBalancerEndpointList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []LoadBalancerEndpoint `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&LoadBalancerEndpoint{}, &LoadBalancerEndpointList{})
+}
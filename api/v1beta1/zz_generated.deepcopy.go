@@ -22,9 +22,26 @@ package v1beta1
 import (
 	"github.com/skygeario/k8s-controller/api"
 	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudflareProviderConfig) DeepCopyInto(out *CloudflareProviderConfig) {
+	*out = *in
+	out.APITokenSecretRef = in.APITokenSecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudflareProviderConfig.
+func (in *CloudflareProviderConfig) DeepCopy() *CloudflareProviderConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudflareProviderConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CustomDomain) DeepCopyInto(out *CustomDomain) {
 	*out = *in
@@ -52,6 +69,22 @@ func (in *CustomDomain) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomDomainAttestationStatus) DeepCopyInto(out *CustomDomainAttestationStatus) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomDomainAttestationStatus.
+func (in *CustomDomainAttestationStatus) DeepCopy() *CustomDomainAttestationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomDomainAttestationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CustomDomainConfig) DeepCopyInto(out *CustomDomainConfig) {
 	*out = *in
@@ -77,6 +110,47 @@ func (in *CustomDomainConfig) DeepCopy() *CustomDomainConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomDomainDNSCheckStatus) DeepCopyInto(out *CustomDomainDNSCheckStatus) {
+	*out = *in
+	if in.LastCheckTime != nil {
+		in, out := &in.LastCheckTime, &out.LastCheckTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Records != nil {
+		in, out := &in.Records, &out.Records
+		*out = make([]CustomDomainDNSRecordStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomDomainDNSCheckStatus.
+func (in *CustomDomainDNSCheckStatus) DeepCopy() *CustomDomainDNSCheckStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomDomainDNSCheckStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomDomainDNSInstruction) DeepCopyInto(out *CustomDomainDNSInstruction) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomDomainDNSInstruction.
+func (in *CustomDomainDNSInstruction) DeepCopy() *CustomDomainDNSInstruction {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomDomainDNSInstruction)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CustomDomainDNSRecord) DeepCopyInto(out *CustomDomainDNSRecord) {
 	*out = *in
@@ -92,6 +166,102 @@ func (in *CustomDomainDNSRecord) DeepCopy() *CustomDomainDNSRecord {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomDomainDNSRecordStatus) DeepCopyInto(out *CustomDomainDNSRecordStatus) {
+	*out = *in
+	if in.ObservedValues != nil {
+		in, out := &in.ObservedValues, &out.ObservedValues
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomDomainDNSRecordStatus.
+func (in *CustomDomainDNSRecordStatus) DeepCopy() *CustomDomainDNSRecordStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomDomainDNSRecordStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomDomainEmailChallengeStatus) DeepCopyInto(out *CustomDomainEmailChallengeStatus) {
+	*out = *in
+	if in.Recipients != nil {
+		in, out := &in.Recipients, &out.Recipients
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.SentTime.DeepCopyInto(&out.SentTime)
+	in.ExpiryTime.DeepCopyInto(&out.ExpiryTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomDomainEmailChallengeStatus.
+func (in *CustomDomainEmailChallengeStatus) DeepCopy() *CustomDomainEmailChallengeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomDomainEmailChallengeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomDomainHealthCheck) DeepCopyInto(out *CustomDomainHealthCheck) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomDomainHealthCheck.
+func (in *CustomDomainHealthCheck) DeepCopy() *CustomDomainHealthCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomDomainHealthCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomDomainHealthCheckStatus) DeepCopyInto(out *CustomDomainHealthCheckStatus) {
+	*out = *in
+	if in.LastProbeTime != nil {
+		in, out := &in.LastProbeTime, &out.LastProbeTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomDomainHealthCheckStatus.
+func (in *CustomDomainHealthCheckStatus) DeepCopy() *CustomDomainHealthCheckStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomDomainHealthCheckStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomDomainIssuerReference) DeepCopyInto(out *CustomDomainIssuerReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomDomainIssuerReference.
+func (in *CustomDomainIssuerReference) DeepCopy() *CustomDomainIssuerReference {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomDomainIssuerReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CustomDomainList) DeepCopyInto(out *CustomDomainList) {
 	*out = *in
@@ -124,6 +294,21 @@ func (in *CustomDomainList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomDomainRedirect) DeepCopyInto(out *CustomDomainRedirect) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomDomainRedirect.
+func (in *CustomDomainRedirect) DeepCopy() *CustomDomainRedirect {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomDomainRedirect)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CustomDomainRegistration) DeepCopyInto(out *CustomDomainRegistration) {
 	*out = *in
@@ -183,14 +368,78 @@ func (in *CustomDomainRegistrationList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomDomainRegistrationReference) DeepCopyInto(out *CustomDomainRegistrationReference) {
+	*out = *in
+	out.ObjectReference = in.ObjectReference
+	if in.LastHeartbeatTime != nil {
+		in, out := &in.LastHeartbeatTime, &out.LastHeartbeatTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomDomainRegistrationReference.
+func (in *CustomDomainRegistrationReference) DeepCopy() *CustomDomainRegistrationReference {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomDomainRegistrationReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CustomDomainRegistrationSpec) DeepCopyInto(out *CustomDomainRegistrationSpec) {
 	*out = *in
 	in.DomainConfig.DeepCopyInto(&out.DomainConfig)
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(CustomDomainTLS)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Routing != nil {
+		in, out := &in.Routing, &out.Routing
+		*out = new(CustomDomainRouting)
+		**out = **in
+	}
+	if in.HealthCheck != nil {
+		in, out := &in.HealthCheck, &out.HealthCheck
+		*out = new(CustomDomainHealthCheck)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Redirect != nil {
+		in, out := &in.Redirect, &out.Redirect
+		*out = new(CustomDomainRedirect)
+		**out = **in
+	}
+	if in.Verification != nil {
+		in, out := &in.Verification, &out.Verification
+		*out = new(CustomDomainVerification)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.VerifyAt != nil {
 		in, out := &in.VerifyAt, &out.VerifyAt
 		*out = (*in).DeepCopy()
 	}
+	if in.ReverificationInterval != nil {
+		in, out := &in.ReverificationInterval, &out.ReverificationInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.TransferTo != nil {
+		in, out := &in.TransferTo, &out.TransferTo
+		*out = new(string)
+		**out = **in
+	}
+	if in.ExpiresAt != nil {
+		in, out := &in.ExpiresAt, &out.ExpiresAt
+		*out = (*in).DeepCopy()
+	}
+	if in.TTLSecondsAfterVerificationFailure != nil {
+		in, out := &in.TTLSecondsAfterVerificationFailure, &out.TTLSecondsAfterVerificationFailure
+		*out = new(int64)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomDomainRegistrationSpec.
@@ -218,15 +467,59 @@ func (in *CustomDomainRegistrationStatus) DeepCopyInto(out *CustomDomainRegistra
 		*out = make([]CustomDomainDNSRecord, len(*in))
 		copy(*out, *in)
 	}
+	if in.Instructions != nil {
+		in, out := &in.Instructions, &out.Instructions
+		*out = make([]CustomDomainDNSInstruction, len(*in))
+		copy(*out, *in)
+	}
 	if in.LastVerificationTime != nil {
 		in, out := &in.LastVerificationTime, &out.LastVerificationTime
 		*out = (*in).DeepCopy()
 	}
+	if in.NextVerificationTime != nil {
+		in, out := &in.NextVerificationTime, &out.NextVerificationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.VerificationURL != nil {
+		in, out := &in.VerificationURL, &out.VerificationURL
+		*out = new(string)
+		**out = **in
+	}
+	if in.VerificationFailure != nil {
+		in, out := &in.VerificationFailure, &out.VerificationFailure
+		*out = new(CustomDomainVerificationFailure)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DNSCheck != nil {
+		in, out := &in.DNSCheck, &out.DNSCheck
+		*out = new(CustomDomainDNSCheckStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HealthCheck != nil {
+		in, out := &in.HealthCheck, &out.HealthCheck
+		*out = new(CustomDomainHealthCheckStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.CertSecretName != nil {
 		in, out := &in.CertSecretName, &out.CertSecretName
 		*out = new(string)
 		**out = **in
 	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(CustomDomainTLSStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Attestation != nil {
+		in, out := &in.Attestation, &out.Attestation
+		*out = new(CustomDomainAttestationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EmailChallenge != nil {
+		in, out := &in.EmailChallenge, &out.EmailChallenge
+		*out = new(CustomDomainEmailChallengeStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomDomainRegistrationStatus.
@@ -252,16 +545,43 @@ func (in *CustomDomainSpec) DeepCopyInto(out *CustomDomainSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.PreviousVerificationKey != nil {
+		in, out := &in.PreviousVerificationKey, &out.PreviousVerificationKey
+		*out = new(string)
+		**out = **in
+	}
+	if in.VerificationKeyRotation != nil {
+		in, out := &in.VerificationKeyRotation, &out.VerificationKeyRotation
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.VerificationNameservers != nil {
+		in, out := &in.VerificationNameservers, &out.VerificationNameservers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.VerificationRecordPrefix != nil {
+		in, out := &in.VerificationRecordPrefix, &out.VerificationRecordPrefix
+		*out = new(string)
+		**out = **in
+	}
 	if in.Registrations != nil {
 		in, out := &in.Registrations, &out.Registrations
-		*out = make([]v1.ObjectReference, len(*in))
-		copy(*out, *in)
+		*out = make([]CustomDomainRegistrationReference, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.OwnerApp != nil {
 		in, out := &in.OwnerApp, &out.OwnerApp
 		*out = new(string)
 		**out = **in
 	}
+	if in.OwnerRef != nil {
+		in, out := &in.OwnerRef, &out.OwnerRef
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomDomainSpec.
@@ -289,6 +609,37 @@ func (in *CustomDomainStatus) DeepCopyInto(out *CustomDomainStatus) {
 		*out = new(CustomDomainStatusLoadBalancer)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.VerificationKeyRotatedAt != nil {
+		in, out := &in.VerificationKeyRotatedAt, &out.VerificationKeyRotatedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.PrimaryRegistration != nil {
+		in, out := &in.PrimaryRegistration, &out.PrimaryRegistration
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
+	if in.OrphanedAt != nil {
+		in, out := &in.OrphanedAt, &out.OrphanedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.ReleasedAt != nil {
+		in, out := &in.ReleasedAt, &out.ReleasedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.QuarantineUntil != nil {
+		in, out := &in.QuarantineUntil, &out.QuarantineUntil
+		*out = (*in).DeepCopy()
+	}
+	if in.Takeover != nil {
+		in, out := &in.Takeover, &out.Takeover
+		*out = new(CustomDomainTakeoverStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Summary != nil {
+		in, out := &in.Summary, &out.Summary
+		*out = new(CustomDomainSummary)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomDomainStatus.
@@ -309,6 +660,11 @@ func (in *CustomDomainStatusLoadBalancer) DeepCopyInto(out *CustomDomainStatusLo
 		*out = make([]CustomDomainDNSRecord, len(*in))
 		copy(*out, *in)
 	}
+	if in.AliasTarget != nil {
+		in, out := &in.AliasTarget, &out.AliasTarget
+		*out = new(string)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomDomainStatusLoadBalancer.
@@ -320,3 +676,925 @@ func (in *CustomDomainStatusLoadBalancer) DeepCopy() *CustomDomainStatusLoadBala
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomDomainRouting) DeepCopyInto(out *CustomDomainRouting) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomDomainRouting.
+func (in *CustomDomainRouting) DeepCopy() *CustomDomainRouting {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomDomainRouting)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomDomainSummary) DeepCopyInto(out *CustomDomainSummary) {
+	*out = *in
+	if in.LastVerificationTime != nil {
+		in, out := &in.LastVerificationTime, &out.LastVerificationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.DNSTargets != nil {
+		in, out := &in.DNSTargets, &out.DNSTargets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomDomainSummary.
+func (in *CustomDomainSummary) DeepCopy() *CustomDomainSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomDomainSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomDomainTLS) DeepCopyInto(out *CustomDomainTLS) {
+	*out = *in
+	if in.IssuerRef != nil {
+		in, out := &in.IssuerRef, &out.IssuerRef
+		*out = new(CustomDomainIssuerReference)
+		**out = **in
+	}
+	if in.SecretName != nil {
+		in, out := &in.SecretName, &out.SecretName
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomDomainTLS.
+func (in *CustomDomainTLS) DeepCopy() *CustomDomainTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomDomainTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomDomainTLSStatus) DeepCopyInto(out *CustomDomainTLSStatus) {
+	*out = *in
+	if in.NotAfter != nil {
+		in, out := &in.NotAfter, &out.NotAfter
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomDomainTLSStatus.
+func (in *CustomDomainTLSStatus) DeepCopy() *CustomDomainTLSStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomDomainTLSStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomDomainTakeoverStatus) DeepCopyInto(out *CustomDomainTakeoverStatus) {
+	*out = *in
+	in.OwnerUnverifiedSince.DeepCopyInto(&out.OwnerUnverifiedSince)
+	if in.Claimant != nil {
+		in, out := &in.Claimant, &out.Claimant
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
+	if in.ClaimantVerifiedSince != nil {
+		in, out := &in.ClaimantVerifiedSince, &out.ClaimantVerifiedSince
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomDomainTakeoverStatus.
+func (in *CustomDomainTakeoverStatus) DeepCopy() *CustomDomainTakeoverStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomDomainTakeoverStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomDomainVerification) DeepCopyInto(out *CustomDomainVerification) {
+	*out = *in
+	if in.DeadlineSeconds != nil {
+		in, out := &in.DeadlineSeconds, &out.DeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomDomainVerification.
+func (in *CustomDomainVerification) DeepCopy() *CustomDomainVerification {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomDomainVerification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomDomainVerificationFailure) DeepCopyInto(out *CustomDomainVerificationFailure) {
+	*out = *in
+	if in.ObservedValues != nil {
+		in, out := &in.ObservedValues, &out.ObservedValues
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomDomainVerificationFailure.
+func (in *CustomDomainVerificationFailure) DeepCopy() *CustomDomainVerificationFailure {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomDomainVerificationFailure)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DNSEndpointProviderConfig) DeepCopyInto(out *DNSEndpointProviderConfig) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSEndpointProviderConfig.
+func (in *DNSEndpointProviderConfig) DeepCopy() *DNSEndpointProviderConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(DNSEndpointProviderConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DNSProviderConfig) DeepCopyInto(out *DNSProviderConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSProviderConfig.
+func (in *DNSProviderConfig) DeepCopy() *DNSProviderConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(DNSProviderConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DNSProviderConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DNSProviderConfigList) DeepCopyInto(out *DNSProviderConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DNSProviderConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSProviderConfigList.
+func (in *DNSProviderConfigList) DeepCopy() *DNSProviderConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(DNSProviderConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DNSProviderConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DNSProviderConfigSpec) DeepCopyInto(out *DNSProviderConfigSpec) {
+	*out = *in
+	if in.Zones != nil {
+		in, out := &in.Zones, &out.Zones
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DNSEndpoint != nil {
+		in, out := &in.DNSEndpoint, &out.DNSEndpoint
+		*out = new(DNSEndpointProviderConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Cloudflare != nil {
+		in, out := &in.Cloudflare, &out.Cloudflare
+		*out = new(CloudflareProviderConfig)
+		**out = **in
+	}
+	if in.GoogleCloudDNS != nil {
+		in, out := &in.GoogleCloudDNS, &out.GoogleCloudDNS
+		*out = new(GoogleCloudDNSProviderConfig)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSProviderConfigSpec.
+func (in *DNSProviderConfigSpec) DeepCopy() *DNSProviderConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DNSProviderConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DomainImport) DeepCopyInto(out *DomainImport) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DomainImport.
+func (in *DomainImport) DeepCopy() *DomainImport {
+	if in == nil {
+		return nil
+	}
+	out := new(DomainImport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DomainImport) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DomainImportItem) DeepCopyInto(out *DomainImportItem) {
+	*out = *in
+	in.DomainConfig.DeepCopyInto(&out.DomainConfig)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DomainImportItem.
+func (in *DomainImportItem) DeepCopy() *DomainImportItem {
+	if in == nil {
+		return nil
+	}
+	out := new(DomainImportItem)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DomainImportItemStatus) DeepCopyInto(out *DomainImportItemStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DomainImportItemStatus.
+func (in *DomainImportItemStatus) DeepCopy() *DomainImportItemStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DomainImportItemStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DomainImportList) DeepCopyInto(out *DomainImportList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DomainImport, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DomainImportList.
+func (in *DomainImportList) DeepCopy() *DomainImportList {
+	if in == nil {
+		return nil
+	}
+	out := new(DomainImportList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DomainImportList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DomainImportSpec) DeepCopyInto(out *DomainImportSpec) {
+	*out = *in
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DomainImportItem, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DomainImportSpec.
+func (in *DomainImportSpec) DeepCopy() *DomainImportSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DomainImportSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DomainImportStatus) DeepCopyInto(out *DomainImportStatus) {
+	*out = *in
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DomainImportItemStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DomainImportStatus.
+func (in *DomainImportStatus) DeepCopy() *DomainImportStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DomainImportStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DomainPolicy) DeepCopyInto(out *DomainPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DomainPolicy.
+func (in *DomainPolicy) DeepCopy() *DomainPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(DomainPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DomainPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DomainPolicyList) DeepCopyInto(out *DomainPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DomainPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DomainPolicyList.
+func (in *DomainPolicyList) DeepCopy() *DomainPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(DomainPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DomainPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DomainPolicySpec) DeepCopyInto(out *DomainPolicySpec) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedSuffixes != nil {
+		in, out := &in.AllowedSuffixes, &out.AllowedSuffixes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ForbiddenApexDomains != nil {
+		in, out := &in.ForbiddenApexDomains, &out.ForbiddenApexDomains
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DenyPatterns != nil {
+		in, out := &in.DenyPatterns, &out.DenyPatterns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ProtectedBrands != nil {
+		in, out := &in.ProtectedBrands, &out.ProtectedBrands
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DomainPolicySpec.
+func (in *DomainPolicySpec) DeepCopy() *DomainPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DomainPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DomainQuota) DeepCopyInto(out *DomainQuota) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DomainQuota.
+func (in *DomainQuota) DeepCopy() *DomainQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(DomainQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DomainQuota) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DomainQuotaList) DeepCopyInto(out *DomainQuotaList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DomainQuota, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DomainQuotaList.
+func (in *DomainQuotaList) DeepCopy() *DomainQuotaList {
+	if in == nil {
+		return nil
+	}
+	out := new(DomainQuotaList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DomainQuotaList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DomainQuotaSpec) DeepCopyInto(out *DomainQuotaSpec) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaxRegistrations != nil {
+		in, out := &in.MaxRegistrations, &out.MaxRegistrations
+		*out = new(int)
+		**out = **in
+	}
+	if in.ZoneLimits != nil {
+		in, out := &in.ZoneLimits, &out.ZoneLimits
+		*out = make([]DomainQuotaZoneLimit, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DomainQuotaSpec.
+func (in *DomainQuotaSpec) DeepCopy() *DomainQuotaSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DomainQuotaSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DomainQuotaZoneLimit) DeepCopyInto(out *DomainQuotaZoneLimit) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DomainQuotaZoneLimit.
+func (in *DomainQuotaZoneLimit) DeepCopy() *DomainQuotaZoneLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(DomainQuotaZoneLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DomainReservation) DeepCopyInto(out *DomainReservation) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DomainReservation.
+func (in *DomainReservation) DeepCopy() *DomainReservation {
+	if in == nil {
+		return nil
+	}
+	out := new(DomainReservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DomainReservation) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DomainReservationList) DeepCopyInto(out *DomainReservationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DomainReservation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DomainReservationList.
+func (in *DomainReservationList) DeepCopy() *DomainReservationList {
+	if in == nil {
+		return nil
+	}
+	out := new(DomainReservationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DomainReservationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DomainReservationSpec) DeepCopyInto(out *DomainReservationSpec) {
+	*out = *in
+	if in.Hostnames != nil {
+		in, out := &in.Hostnames, &out.Hostnames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DomainReservationSpec.
+func (in *DomainReservationSpec) DeepCopy() *DomainReservationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DomainReservationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GoogleCloudDNSProviderConfig) DeepCopyInto(out *GoogleCloudDNSProviderConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GoogleCloudDNSProviderConfig.
+func (in *GoogleCloudDNSProviderConfig) DeepCopy() *GoogleCloudDNSProviderConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(GoogleCloudDNSProviderConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPPool) DeepCopyInto(out *IPPool) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPPool.
+func (in *IPPool) DeepCopy() *IPPool {
+	if in == nil {
+		return nil
+	}
+	out := new(IPPool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IPPool) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPPoolAllocation) DeepCopyInto(out *IPPoolAllocation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPPoolAllocation.
+func (in *IPPoolAllocation) DeepCopy() *IPPoolAllocation {
+	if in == nil {
+		return nil
+	}
+	out := new(IPPoolAllocation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPPoolList) DeepCopyInto(out *IPPoolList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]IPPool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPPoolList.
+func (in *IPPoolList) DeepCopy() *IPPoolList {
+	if in == nil {
+		return nil
+	}
+	out := new(IPPoolList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IPPoolList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPPoolSpec) DeepCopyInto(out *IPPoolSpec) {
+	*out = *in
+	if in.Addresses != nil {
+		in, out := &in.Addresses, &out.Addresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.Service = in.Service
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPPoolSpec.
+func (in *IPPoolSpec) DeepCopy() *IPPoolSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IPPoolSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPPoolStatus) DeepCopyInto(out *IPPoolStatus) {
+	*out = *in
+	if in.Allocations != nil {
+		in, out := &in.Allocations, &out.Allocations
+		*out = make([]IPPoolAllocation, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPPoolStatus.
+func (in *IPPoolStatus) DeepCopy() *IPPoolStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IPPoolStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerEndpoint) DeepCopyInto(out *LoadBalancerEndpoint) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerEndpoint.
+func (in *LoadBalancerEndpoint) DeepCopy() *LoadBalancerEndpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerEndpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LoadBalancerEndpoint) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerEndpointList) DeepCopyInto(out *LoadBalancerEndpointList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]LoadBalancerEndpoint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerEndpointList.
+func (in *LoadBalancerEndpointList) DeepCopy() *LoadBalancerEndpointList {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerEndpointList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LoadBalancerEndpointList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerEndpointReference) DeepCopyInto(out *LoadBalancerEndpointReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerEndpointReference.
+func (in *LoadBalancerEndpointReference) DeepCopy() *LoadBalancerEndpointReference {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerEndpointReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancerEndpointSpec) DeepCopyInto(out *LoadBalancerEndpointSpec) {
+	*out = *in
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		*out = new(LoadBalancerEndpointReference)
+		**out = **in
+	}
+	if in.Ingress != nil {
+		in, out := &in.Ingress, &out.Ingress
+		*out = new(LoadBalancerEndpointReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerEndpointSpec.
+func (in *LoadBalancerEndpointSpec) DeepCopy() *LoadBalancerEndpointSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancerEndpointSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretKeyReference) DeepCopyInto(out *SecretKeyReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretKeyReference.
+func (in *SecretKeyReference) DeepCopy() *SecretKeyReference {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretKeyReference)
+	in.DeepCopyInto(out)
+	return out
+}
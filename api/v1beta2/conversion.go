@@ -0,0 +1,540 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/skygeario/k8s-controller/api/v1beta1"
+)
+
+var _ conversion.Convertible = &CustomDomain{}
+var _ conversion.Convertible = &CustomDomainRegistration{}
+
+// ConvertTo converts this CustomDomainRegistration to the Hub version (v1beta1).
+func (src *CustomDomainRegistration) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1beta1.CustomDomainRegistration)
+	dst.ObjectMeta = src.ObjectMeta
+
+	dst.Spec.DomainName = src.Spec.DomainName
+	dst.Spec.Role = v1beta1.CustomDomainRegistrationRole(src.Spec.Role)
+	dst.Spec.LoadBalancerClass = src.Spec.LoadBalancerClass
+	dst.Spec.DedicatedIP = src.Spec.DedicatedIP
+	dst.Spec.DomainConfig = v1beta1.CustomDomainConfig{
+		BackendServiceName: src.Spec.Backend.ServiceName,
+		BackendServicePort: src.Spec.Backend.ServicePort,
+		RedirectToURL:      src.Spec.Backend.RedirectToURL,
+	}
+	dst.Spec.TLS = nil
+	if tls := src.Spec.TLS; tls != nil {
+		dst.Spec.DomainConfig.CertSecretName = tls.CertSecretName
+		if tls.IssuerRef != nil || tls.SecretName != nil {
+			dst.Spec.TLS = &v1beta1.CustomDomainTLS{SecretName: tls.SecretName}
+			if tls.IssuerRef != nil {
+				dst.Spec.TLS.IssuerRef = &v1beta1.CustomDomainIssuerReference{
+					Kind: tls.IssuerRef.Kind,
+					Name: tls.IssuerRef.Name,
+				}
+			}
+		}
+	}
+	dst.Spec.Routing = nil
+	if src.Spec.Routing != nil {
+		dst.Spec.Routing = &v1beta1.CustomDomainRouting{
+			Mode: v1beta1.CustomDomainRoutingMode(src.Spec.Routing.Mode),
+		}
+	}
+	dst.Spec.HealthCheck = nil
+	if hc := src.Spec.HealthCheck; hc != nil {
+		dst.Spec.HealthCheck = &v1beta1.CustomDomainHealthCheck{
+			Path:     hc.Path,
+			Scheme:   v1beta1.CustomDomainHealthCheckScheme(hc.Scheme),
+			Interval: hc.Interval,
+		}
+	}
+	dst.Spec.Redirect = nil
+	if r := src.Spec.Redirect; r != nil {
+		dst.Spec.Redirect = &v1beta1.CustomDomainRedirect{
+			URL:          r.URL,
+			StatusCode:   r.StatusCode,
+			PreservePath: r.PreservePath,
+		}
+	}
+	dst.Spec.Verification = nil
+	dst.Spec.VerifyAt = nil
+	dst.Spec.ReverificationInterval = nil
+	if v := src.Spec.Verification; v != nil {
+		if v.Method != "" || v.RecordType != "" || v.DeadlineSeconds != nil {
+			dst.Spec.Verification = &v1beta1.CustomDomainVerification{
+				Method:          v1beta1.CustomDomainVerificationMethod(v.Method),
+				RecordType:      v1beta1.CustomDomainVerificationRecordType(v.RecordType),
+				DeadlineSeconds: v.DeadlineSeconds,
+			}
+		}
+		dst.Spec.VerifyAt = v.VerifyAt
+		dst.Spec.ReverificationInterval = v.ReverificationInterval
+	}
+	dst.Spec.TransferTo = src.Spec.TransferTo
+	dst.Spec.RedirectSibling = src.Spec.RedirectSibling
+	dst.Spec.Approved = src.Spec.Approved
+	dst.Spec.ExpiresAt = src.Spec.ExpiresAt
+	dst.Spec.TTLSecondsAfterVerificationFailure = src.Spec.TTLSecondsAfterVerificationFailure
+
+	dst.Status.Conditions = src.Status.Conditions
+	dst.Status.ObservedGeneration = src.Status.ObservedGeneration
+	dst.Status.Zone = src.Status.Zone
+	dst.Status.Phase = v1beta1.CustomDomainRegistrationPhase(src.Status.Phase)
+	dst.Status.DomainName = src.Status.DomainName
+	dst.Status.UnicodeDomainName = src.Status.UnicodeDomainName
+	dst.Status.DNSRecords = convertDNSRecordsTo(src.Status.DNSRecords)
+	dst.Status.Instructions = convertDNSInstructionsTo(src.Status.Instructions)
+	dst.Status.LastVerificationTime = nil
+	dst.Status.NextVerificationTime = nil
+	dst.Status.VerificationURL = nil
+	dst.Status.VerificationFailureCount = 0
+	dst.Status.ConclusiveFailureCount = 0
+	dst.Status.VerificationFailure = nil
+	dst.Status.VerificationView = ""
+	if v := src.Status.Verification; v != nil {
+		dst.Status.LastVerificationTime = v.LastVerificationTime
+		dst.Status.VerificationView = v.View
+		dst.Status.NextVerificationTime = v.NextVerificationTime
+		dst.Status.VerificationURL = v.URL
+		dst.Status.VerificationFailureCount = v.FailureCount
+		dst.Status.ConclusiveFailureCount = v.ConclusiveFailureCount
+		if f := v.Failure; f != nil {
+			dst.Status.VerificationFailure = &v1beta1.CustomDomainVerificationFailure{
+				Reason:         f.Reason,
+				RecordName:     f.RecordName,
+				ExpectedValue:  f.ExpectedValue,
+				ObservedValues: f.ObservedValues,
+				Resolver:       f.Resolver,
+			}
+		}
+	}
+	dst.Status.DNSCheck = convertDNSCheckTo(src.Status.DNSCheck)
+	dst.Status.HealthCheck = nil
+	if hc := src.Status.HealthCheck; hc != nil {
+		dst.Status.HealthCheck = &v1beta1.CustomDomainHealthCheckStatus{
+			LastProbeTime:       hc.LastProbeTime,
+			StatusCode:          hc.StatusCode,
+			LatencyMilliseconds: hc.LatencyMilliseconds,
+		}
+	}
+	dst.Status.CertSecretName = src.Status.CertSecretName
+	if src.Status.TLS != nil {
+		dst.Status.TLS = &v1beta1.CustomDomainTLSStatus{
+			NotAfter: src.Status.TLS.NotAfter,
+			Issuer:   src.Status.TLS.Issuer,
+		}
+	}
+	if a := src.Status.Attestation; a != nil {
+		dst.Status.Attestation = &v1beta1.CustomDomainAttestationStatus{
+			Time:               a.Time,
+			ObservedGeneration: a.ObservedGeneration,
+			Attester:           a.Attester,
+		}
+	}
+	if c := src.Status.EmailChallenge; c != nil {
+		dst.Status.EmailChallenge = &v1beta1.CustomDomainEmailChallengeStatus{
+			Recipients:         c.Recipients,
+			SentTime:           c.SentTime,
+			ExpiryTime:         c.ExpiryTime,
+			ObservedGeneration: c.ObservedGeneration,
+		}
+	}
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v1beta1) to this version.
+func (dst *CustomDomainRegistration) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1beta1.CustomDomainRegistration)
+	dst.ObjectMeta = src.ObjectMeta
+
+	dst.Spec.DomainName = src.Spec.DomainName
+	dst.Spec.Role = RegistrationRole(src.Spec.Role)
+	dst.Spec.LoadBalancerClass = src.Spec.LoadBalancerClass
+	dst.Spec.DedicatedIP = src.Spec.DedicatedIP
+	dst.Spec.Backend = BackendSpec{
+		ServiceName:   src.Spec.DomainConfig.BackendServiceName,
+		ServicePort:   src.Spec.DomainConfig.BackendServicePort,
+		RedirectToURL: src.Spec.DomainConfig.RedirectToURL,
+	}
+	dst.Spec.TLS = nil
+	if src.Spec.DomainConfig.CertSecretName != nil || src.Spec.TLS != nil {
+		dst.Spec.TLS = &TLSSpec{CertSecretName: src.Spec.DomainConfig.CertSecretName}
+		if tls := src.Spec.TLS; tls != nil {
+			dst.Spec.TLS.SecretName = tls.SecretName
+			if tls.IssuerRef != nil {
+				dst.Spec.TLS.IssuerRef = &IssuerReference{
+					Kind: tls.IssuerRef.Kind,
+					Name: tls.IssuerRef.Name,
+				}
+			}
+		}
+	}
+	dst.Spec.Routing = nil
+	if src.Spec.Routing != nil {
+		dst.Spec.Routing = &RoutingSpec{Mode: RoutingMode(src.Spec.Routing.Mode)}
+	}
+	dst.Spec.HealthCheck = nil
+	if hc := src.Spec.HealthCheck; hc != nil {
+		dst.Spec.HealthCheck = &HealthCheckSpec{
+			Path:     hc.Path,
+			Scheme:   HealthCheckScheme(hc.Scheme),
+			Interval: hc.Interval,
+		}
+	}
+	dst.Spec.Redirect = nil
+	if r := src.Spec.Redirect; r != nil {
+		dst.Spec.Redirect = &RedirectSpec{
+			URL:          r.URL,
+			StatusCode:   r.StatusCode,
+			PreservePath: r.PreservePath,
+		}
+	}
+	dst.Spec.Verification = nil
+	if src.Spec.Verification != nil || src.Spec.VerifyAt != nil || src.Spec.ReverificationInterval != nil {
+		dst.Spec.Verification = &VerificationSpec{
+			VerifyAt:               src.Spec.VerifyAt,
+			ReverificationInterval: src.Spec.ReverificationInterval,
+		}
+		if src.Spec.Verification != nil {
+			dst.Spec.Verification.Method = VerificationMethod(src.Spec.Verification.Method)
+			dst.Spec.Verification.RecordType = VerificationRecordType(src.Spec.Verification.RecordType)
+			dst.Spec.Verification.DeadlineSeconds = src.Spec.Verification.DeadlineSeconds
+		}
+	}
+	dst.Spec.TransferTo = src.Spec.TransferTo
+	dst.Spec.RedirectSibling = src.Spec.RedirectSibling
+	dst.Spec.Approved = src.Spec.Approved
+	dst.Spec.ExpiresAt = src.Spec.ExpiresAt
+	dst.Spec.TTLSecondsAfterVerificationFailure = src.Spec.TTLSecondsAfterVerificationFailure
+
+	dst.Status.Conditions = src.Status.Conditions
+	dst.Status.ObservedGeneration = src.Status.ObservedGeneration
+	dst.Status.Zone = src.Status.Zone
+	dst.Status.Phase = string(src.Status.Phase)
+	dst.Status.DomainName = src.Status.DomainName
+	dst.Status.UnicodeDomainName = src.Status.UnicodeDomainName
+	dst.Status.DNSRecords = convertDNSRecordsFrom(src.Status.DNSRecords)
+	dst.Status.Instructions = convertDNSInstructionsFrom(src.Status.Instructions)
+	dst.Status.Verification = nil
+	if src.Status.LastVerificationTime != nil || src.Status.NextVerificationTime != nil || src.Status.VerificationURL != nil ||
+		src.Status.VerificationFailureCount != 0 || src.Status.ConclusiveFailureCount != 0 ||
+		src.Status.VerificationFailure != nil || src.Status.VerificationView != "" {
+		dst.Status.Verification = &VerificationStatus{
+			LastVerificationTime:   src.Status.LastVerificationTime,
+			NextVerificationTime:   src.Status.NextVerificationTime,
+			URL:                    src.Status.VerificationURL,
+			FailureCount:           src.Status.VerificationFailureCount,
+			ConclusiveFailureCount: src.Status.ConclusiveFailureCount,
+			View:                   src.Status.VerificationView,
+		}
+		if f := src.Status.VerificationFailure; f != nil {
+			dst.Status.Verification.Failure = &VerificationFailure{
+				Reason:         f.Reason,
+				RecordName:     f.RecordName,
+				ExpectedValue:  f.ExpectedValue,
+				ObservedValues: f.ObservedValues,
+				Resolver:       f.Resolver,
+			}
+		}
+	}
+	dst.Status.DNSCheck = convertDNSCheckFrom(src.Status.DNSCheck)
+	dst.Status.HealthCheck = nil
+	if hc := src.Status.HealthCheck; hc != nil {
+		dst.Status.HealthCheck = &HealthCheckStatus{
+			LastProbeTime:       hc.LastProbeTime,
+			StatusCode:          hc.StatusCode,
+			LatencyMilliseconds: hc.LatencyMilliseconds,
+		}
+	}
+	dst.Status.CertSecretName = src.Status.CertSecretName
+	if src.Status.TLS != nil {
+		dst.Status.TLS = &TLSStatus{
+			NotAfter: src.Status.TLS.NotAfter,
+			Issuer:   src.Status.TLS.Issuer,
+		}
+	}
+	if a := src.Status.Attestation; a != nil {
+		dst.Status.Attestation = &AttestationStatus{
+			Time:               a.Time,
+			ObservedGeneration: a.ObservedGeneration,
+			Attester:           a.Attester,
+		}
+	}
+	if c := src.Status.EmailChallenge; c != nil {
+		dst.Status.EmailChallenge = &EmailChallengeStatus{
+			Recipients:         c.Recipients,
+			SentTime:           c.SentTime,
+			ExpiryTime:         c.ExpiryTime,
+			ObservedGeneration: c.ObservedGeneration,
+		}
+	}
+	return nil
+}
+
+// ConvertTo converts this CustomDomain to the Hub version (v1beta1).
+func (src *CustomDomain) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1beta1.CustomDomain)
+	dst.ObjectMeta = src.ObjectMeta
+
+	dst.Spec.LoadBalancerProvider = src.Spec.LoadBalancerProvider
+	dst.Spec.VerificationKey = nil
+	dst.Spec.PreviousVerificationKey = nil
+	dst.Spec.VerificationKeyRotation = nil
+	dst.Spec.VerificationNameservers = nil
+	dst.Spec.VerificationRecordPrefix = nil
+	if v := src.Spec.Verification; v != nil {
+		dst.Spec.VerificationKey = v.Key
+		dst.Spec.PreviousVerificationKey = v.PreviousKey
+		dst.Spec.VerificationKeyRotation = v.KeyRotation
+		dst.Spec.VerificationNameservers = v.Nameservers
+		dst.Spec.VerificationRecordPrefix = v.RecordPrefix
+	}
+	dst.Spec.Registrations = nil
+	for _, ref := range src.Spec.Registrations {
+		dst.Spec.Registrations = append(dst.Spec.Registrations, v1beta1.CustomDomainRegistrationReference{
+			ObjectReference:   ref.ObjectReference,
+			Role:              v1beta1.CustomDomainRegistrationRole(ref.Role),
+			Cluster:           ref.Cluster,
+			Verified:          ref.Verified,
+			LastHeartbeatTime: ref.LastHeartbeatTime,
+			LoadBalancerClass: ref.LoadBalancerClass,
+			DedicatedIP:       ref.DedicatedIP,
+		})
+	}
+	dst.Spec.OwnerRef = src.Spec.OwnerRef
+	dst.Spec.OwnerCluster = src.Spec.OwnerCluster
+	dst.Spec.OwnerApp = nil
+	if src.Spec.OwnerRef != nil {
+		ownerApp := src.Spec.OwnerRef.Namespace
+		dst.Spec.OwnerApp = &ownerApp
+	}
+
+	dst.Status.Conditions = src.Status.Conditions
+	dst.Status.ObservedGeneration = src.Status.ObservedGeneration
+	dst.Status.Zone = src.Status.Zone
+	dst.Status.Phase = v1beta1.CustomDomainPhase(src.Status.Phase)
+	dst.Status.PrimaryRegistration = src.Status.PrimaryRegistration
+	dst.Status.OrphanedAt = src.Status.OrphanedAt
+	dst.Status.ReleasedAt = src.Status.ReleasedAt
+	dst.Status.ReleasedApp = src.Status.ReleasedApp
+	dst.Status.ReleasedCluster = src.Status.ReleasedCluster
+	dst.Status.QuarantineUntil = src.Status.QuarantineUntil
+	dst.Status.Takeover = nil
+	if t := src.Status.Takeover; t != nil {
+		dst.Status.Takeover = &v1beta1.CustomDomainTakeoverStatus{
+			OwnerUnverifiedSince:  t.OwnerUnverifiedSince,
+			OwnerFailureCount:     t.OwnerFailureCount,
+			Claimant:              t.Claimant,
+			ClaimantVerifiedSince: t.ClaimantVerifiedSince,
+		}
+	}
+	dst.Status.Summary = nil
+	if s := src.Status.Summary; s != nil {
+		dst.Status.Summary = &v1beta1.CustomDomainSummary{
+			Registrations:         s.Registrations,
+			VerifiedRegistrations: s.VerifiedRegistrations,
+			PendingRegistrations:  s.PendingRegistrations,
+			Owner:                 s.Owner,
+			LastVerificationTime:  s.LastVerificationTime,
+			DNSTargets:            s.DNSTargets,
+		}
+	}
+	dst.Status.VerificationKeyRotatedAt = nil
+	if src.Status.Verification != nil {
+		dst.Status.VerificationKeyRotatedAt = src.Status.Verification.KeyRotatedAt
+	}
+	dst.Status.LoadBalancer = nil
+	if lb := src.Status.LoadBalancer; lb != nil {
+		dst.Status.LoadBalancer = &v1beta1.CustomDomainStatusLoadBalancer{
+			Provider:    lb.Provider,
+			DNSRecords:  convertDNSRecordsTo(lb.DNSRecords),
+			AliasTarget: lb.AliasTarget,
+		}
+	}
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v1beta1) to this version.
+func (dst *CustomDomain) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1beta1.CustomDomain)
+	dst.ObjectMeta = src.ObjectMeta
+
+	dst.Spec.LoadBalancerProvider = src.Spec.LoadBalancerProvider
+	dst.Spec.Verification = nil
+	if src.Spec.VerificationKey != nil || src.Spec.PreviousVerificationKey != nil || src.Spec.VerificationKeyRotation != nil ||
+		len(src.Spec.VerificationNameservers) != 0 || src.Spec.VerificationRecordPrefix != nil {
+		dst.Spec.Verification = &DomainVerificationSpec{
+			Key:          src.Spec.VerificationKey,
+			PreviousKey:  src.Spec.PreviousVerificationKey,
+			KeyRotation:  src.Spec.VerificationKeyRotation,
+			Nameservers:  src.Spec.VerificationNameservers,
+			RecordPrefix: src.Spec.VerificationRecordPrefix,
+		}
+	}
+	dst.Spec.Registrations = nil
+	for _, ref := range src.Spec.Registrations {
+		dst.Spec.Registrations = append(dst.Spec.Registrations, RegistrationReference{
+			ObjectReference:   ref.ObjectReference,
+			Role:              RegistrationRole(ref.Role),
+			Cluster:           ref.Cluster,
+			Verified:          ref.Verified,
+			LastHeartbeatTime: ref.LastHeartbeatTime,
+			LoadBalancerClass: ref.LoadBalancerClass,
+			DedicatedIP:       ref.DedicatedIP,
+		})
+	}
+	dst.Spec.OwnerRef = src.Spec.OwnerRef
+	dst.Spec.OwnerCluster = src.Spec.OwnerCluster
+	if dst.Spec.OwnerRef == nil && src.Spec.OwnerApp != nil {
+		// Domains owned before owner reference is introduced have owner
+		// app only.
+		for _, ref := range src.Spec.Registrations {
+			if ref.Namespace == *src.Spec.OwnerApp {
+				ref := ref
+				dst.Spec.OwnerRef = &ref.ObjectReference
+				break
+			}
+		}
+	}
+
+	dst.Status.Conditions = src.Status.Conditions
+	dst.Status.ObservedGeneration = src.Status.ObservedGeneration
+	dst.Status.Zone = src.Status.Zone
+	dst.Status.Phase = string(src.Status.Phase)
+	dst.Status.PrimaryRegistration = src.Status.PrimaryRegistration
+	dst.Status.OrphanedAt = src.Status.OrphanedAt
+	dst.Status.ReleasedAt = src.Status.ReleasedAt
+	dst.Status.ReleasedApp = src.Status.ReleasedApp
+	dst.Status.ReleasedCluster = src.Status.ReleasedCluster
+	dst.Status.QuarantineUntil = src.Status.QuarantineUntil
+	dst.Status.Takeover = nil
+	if t := src.Status.Takeover; t != nil {
+		dst.Status.Takeover = &TakeoverStatus{
+			OwnerUnverifiedSince:  t.OwnerUnverifiedSince,
+			OwnerFailureCount:     t.OwnerFailureCount,
+			Claimant:              t.Claimant,
+			ClaimantVerifiedSince: t.ClaimantVerifiedSince,
+		}
+	}
+	dst.Status.Summary = nil
+	if s := src.Status.Summary; s != nil {
+		dst.Status.Summary = &DomainSummary{
+			Registrations:         s.Registrations,
+			VerifiedRegistrations: s.VerifiedRegistrations,
+			PendingRegistrations:  s.PendingRegistrations,
+			Owner:                 s.Owner,
+			LastVerificationTime:  s.LastVerificationTime,
+			DNSTargets:            s.DNSTargets,
+		}
+	}
+	dst.Status.Verification = nil
+	if src.Status.VerificationKeyRotatedAt != nil {
+		dst.Status.Verification = &DomainVerificationStatus{
+			KeyRotatedAt: src.Status.VerificationKeyRotatedAt,
+		}
+	}
+	dst.Status.LoadBalancer = nil
+	if lb := src.Status.LoadBalancer; lb != nil {
+		dst.Status.LoadBalancer = &LoadBalancerStatus{
+			Provider:    lb.Provider,
+			DNSRecords:  convertDNSRecordsFrom(lb.DNSRecords),
+			AliasTarget: lb.AliasTarget,
+		}
+	}
+	return nil
+}
+
+func convertDNSRecordsTo(records []DNSRecord) []v1beta1.CustomDomainDNSRecord {
+	if records == nil {
+		return nil
+	}
+	out := make([]v1beta1.CustomDomainDNSRecord, len(records))
+	for i, r := range records {
+		out[i] = v1beta1.CustomDomainDNSRecord{Name: r.Name, Type: r.Type, Value: r.Value}
+	}
+	return out
+}
+
+func convertDNSRecordsFrom(records []v1beta1.CustomDomainDNSRecord) []DNSRecord {
+	if records == nil {
+		return nil
+	}
+	out := make([]DNSRecord, len(records))
+	for i, r := range records {
+		out[i] = DNSRecord{Name: r.Name, Type: r.Type, Value: r.Value}
+	}
+	return out
+}
+
+func convertDNSCheckTo(check *DNSCheckStatus) *v1beta1.CustomDomainDNSCheckStatus {
+	if check == nil {
+		return nil
+	}
+	out := &v1beta1.CustomDomainDNSCheckStatus{LastCheckTime: check.LastCheckTime}
+	for _, r := range check.Records {
+		out.Records = append(out.Records, v1beta1.CustomDomainDNSRecordStatus{
+			Name:           r.Name,
+			Type:           r.Type,
+			Value:          r.Value,
+			ObservedValues: r.ObservedValues,
+			Configured:     r.Configured,
+		})
+	}
+	return out
+}
+
+func convertDNSCheckFrom(check *v1beta1.CustomDomainDNSCheckStatus) *DNSCheckStatus {
+	if check == nil {
+		return nil
+	}
+	out := &DNSCheckStatus{LastCheckTime: check.LastCheckTime}
+	for _, r := range check.Records {
+		out.Records = append(out.Records, DNSRecordStatus{
+			Name:           r.Name,
+			Type:           r.Type,
+			Value:          r.Value,
+			ObservedValues: r.ObservedValues,
+			Configured:     r.Configured,
+		})
+	}
+	return out
+}
+
+func convertDNSInstructionsTo(instructions []DNSInstruction) []v1beta1.CustomDomainDNSInstruction {
+	if instructions == nil {
+		return nil
+	}
+	out := make([]v1beta1.CustomDomainDNSInstruction, len(instructions))
+	for i, in := range instructions {
+		out[i] = v1beta1.CustomDomainDNSInstruction(in)
+	}
+	return out
+}
+
+func convertDNSInstructionsFrom(instructions []v1beta1.CustomDomainDNSInstruction) []DNSInstruction {
+	if instructions == nil {
+		return nil
+	}
+	out := make([]DNSInstruction, len(instructions))
+	for i, in := range instructions {
+		out[i] = DNSInstruction(in)
+	}
+	return out
+}
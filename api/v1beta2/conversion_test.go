@@ -0,0 +1,134 @@
+package v1beta2
+
+import (
+	"math/rand"
+	"testing"
+
+	fuzz "github.com/google/gofuzz"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/diff"
+
+	"github.com/skygeario/k8s-controller/api/v1beta1"
+)
+
+// fuzzIterations is the number of random objects converted in round trip
+// tests.
+const fuzzIterations = 1000
+
+// hubFuzzer returns a fuzzer generating hub objects. Objects are normalized
+// after fuzzing to the forms written by the controller and webhooks, since
+// some fields are derived from others and cannot round trip arbitrary values.
+func hubFuzzer(seed int64) *fuzz.Fuzzer {
+	return fuzz.New().NilChance(0.3).NumElements(0, 2).RandSource(rand.NewSource(seed)).Funcs(
+		func(*metav1.TypeMeta, fuzz.Continue) {
+			// Type meta is set by the scheme, not by conversion.
+		},
+		func(s *v1beta1.CustomDomainSpec, c fuzz.Continue) {
+			c.FuzzNoCustom(s)
+			// Owner app is the namespace of owner reference.
+			s.OwnerApp = nil
+			if s.OwnerRef != nil {
+				ownerApp := s.OwnerRef.Namespace
+				s.OwnerApp = &ownerApp
+			}
+		},
+		func(tls *v1beta1.CustomDomainTLS, c fuzz.Continue) {
+			c.FuzzNoCustom(tls)
+			// TLS without issuer or secret name is equivalent to no TLS.
+			if tls.SecretName == nil {
+				tls.SecretName = new(string)
+			}
+		},
+		func(v *v1beta1.CustomDomainVerification, c fuzz.Continue) {
+			c.FuzzNoCustom(v)
+			// Verification without fields is equivalent to no verification.
+			if v.Method == "" {
+				v.Method = v1beta1.VerificationMethodDNS
+			}
+		},
+	)
+}
+
+func TestCustomDomainRegistrationRoundTrip(t *testing.T) {
+	f := hubFuzzer(1)
+	for i := 0; i < fuzzIterations; i++ {
+		original := &v1beta1.CustomDomainRegistration{}
+		f.Fuzz(original)
+
+		converted := &CustomDomainRegistration{}
+		if err := converted.ConvertFrom(original.DeepCopy()); err != nil {
+			t.Fatalf("ConvertFrom: unexpected error: %v", err)
+		}
+		result := &v1beta1.CustomDomainRegistration{}
+		if err := converted.ConvertTo(result); err != nil {
+			t.Fatalf("ConvertTo: unexpected error: %v", err)
+		}
+		if !equality.Semantic.DeepEqual(original, result) {
+			t.Fatalf("round trip changed registration:\n%s", diff.ObjectReflectDiff(original, result))
+		}
+	}
+}
+
+func TestCustomDomainRoundTrip(t *testing.T) {
+	f := hubFuzzer(1)
+	for i := 0; i < fuzzIterations; i++ {
+		original := &v1beta1.CustomDomain{}
+		f.Fuzz(original)
+
+		converted := &CustomDomain{}
+		if err := converted.ConvertFrom(original.DeepCopy()); err != nil {
+			t.Fatalf("ConvertFrom: unexpected error: %v", err)
+		}
+		result := &v1beta1.CustomDomain{}
+		if err := converted.ConvertTo(result); err != nil {
+			t.Fatalf("ConvertTo: unexpected error: %v", err)
+		}
+		if !equality.Semantic.DeepEqual(original, result) {
+			t.Fatalf("round trip changed domain:\n%s", diff.ObjectReflectDiff(original, result))
+		}
+	}
+}
+
+func TestCustomDomainConvertFromOwnerApp(t *testing.T) {
+	ref := corev1.ObjectReference{Kind: "CustomDomainRegistration", Namespace: "app", Name: "example.com"}
+	other := corev1.ObjectReference{Kind: "CustomDomainRegistration", Namespace: "other", Name: "example.com"}
+	ownerApp := "app"
+	src := &v1beta1.CustomDomain{
+		Spec: v1beta1.CustomDomainSpec{
+			Registrations: []v1beta1.CustomDomainRegistrationReference{
+				{ObjectReference: other},
+				{ObjectReference: ref},
+			},
+			OwnerApp: &ownerApp,
+		},
+	}
+
+	var dst CustomDomain
+	if err := dst.ConvertFrom(src); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dst.Spec.OwnerRef == nil || *dst.Spec.OwnerRef != ref {
+		t.Errorf("OwnerRef = %v, expected %v", dst.Spec.OwnerRef, ref)
+	}
+}
+
+func TestCustomDomainRegistrationConvertToBackend(t *testing.T) {
+	src := &CustomDomainRegistration{
+		Spec: CustomDomainRegistrationSpec{
+			DomainName: "example.com",
+			Backend:    BackendSpec{ServiceName: "app", ServicePort: 8080},
+		},
+	}
+
+	var dst v1beta1.CustomDomainRegistration
+	if err := src.ConvertTo(&dst); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Ingress of the registration routes to backend in domain config.
+	expected := v1beta1.CustomDomainConfig{BackendServiceName: "app", BackendServicePort: 8080}
+	if dst.Spec.DomainConfig != expected {
+		t.Errorf("DomainConfig = %#v, expected %#v", dst.Spec.DomainConfig, expected)
+	}
+}
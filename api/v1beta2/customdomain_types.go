@@ -0,0 +1,249 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/skygeario/k8s-controller/api"
+)
+
+// RegistrationReference is a reference to a registration of the domain
+type RegistrationReference struct {
+	corev1.ObjectReference `json:",inline"`
+	// Role is the role of registration. Defaults to Primary.
+	// +optional
+	Role RegistrationRole `json:"role,omitempty"`
+	// Cluster is the name of workload cluster of the registration in
+	// multi-cluster mode. Empty if the registration is in the same cluster
+	// as the domain.
+	// +optional
+	Cluster string `json:"cluster,omitempty"`
+	// Verified is whether the registration is verified. It is synced by the
+	// controller of workload cluster, and used only if Cluster is not empty.
+	// +optional
+	Verified bool `json:"verified,omitempty"`
+	// LastHeartbeatTime is the time that the reference is last refreshed by
+	// the controller of workload cluster, and used only if Cluster is not
+	// empty. References not refreshed for a while are removed, e.g. when the
+	// workload cluster is gone.
+	// +optional
+	LastHeartbeatTime *metav1.Time `json:"lastHeartbeatTime,omitempty"`
+	// LoadBalancerClass is the load balancer class requested by the
+	// registration.
+	// +optional
+	LoadBalancerClass string `json:"loadBalancerClass,omitempty"`
+	// DedicatedIP is whether the registration requests a dedicated IP
+	// address.
+	// +optional
+	DedicatedIP bool `json:"dedicatedIP,omitempty"`
+}
+
+// DomainVerificationSpec is the verification configuration of domain
+type DomainVerificationSpec struct {
+	// Key is the domain verification token key.
+	// +optional
+	Key *string `json:"key,omitempty"`
+	// PreviousKey is the verification key before last rotation, accepted
+	// during the grace period after rotation.
+	// +optional
+	PreviousKey *string `json:"previousKey,omitempty"`
+	// KeyRotation is the interval of rotating verification key, overriding
+	// the default interval of controller. Zero disables rotation.
+	// +optional
+	KeyRotation *metav1.Duration `json:"keyRotation,omitempty"`
+	// Nameservers are DNS resolvers used to verify the domain, overriding
+	// resolvers of controller. It is useful for domains only resolvable by
+	// specific servers, e.g. private zones.
+	// +optional
+	Nameservers []string `json:"nameservers,omitempty"`
+	// RecordPrefix is the label prefixing the name of verification TXT
+	// record of the domain, overriding the prefix of controller, e.g. to
+	// brand records of white-label platforms.
+	// +optional
+	RecordPrefix *string `json:"recordPrefix,omitempty"`
+}
+
+// CustomDomainSpec defines the desired state of CustomDomain
+type CustomDomainSpec struct {
+	// LoadBalancerProvider is the load balancer provider for this domain.
+	// +optional
+	LoadBalancerProvider *string `json:"loadBalancerProvider,omitempty"`
+	// Verification is the verification configuration of domain
+	// +optional
+	Verification *DomainVerificationSpec `json:"verification,omitempty"`
+	// Registrations are registrations from apps.
+	// +optional
+	Registrations []RegistrationReference `json:"registrations,omitempty"`
+	// OwnerRef is the registration which the domain is owned by
+	// +optional
+	OwnerRef *corev1.ObjectReference `json:"ownerRef,omitempty"`
+	// OwnerCluster is the name of workload cluster of the owner registration
+	// in multi-cluster mode. Empty if the owner is in the same cluster as the
+	// domain.
+	// +optional
+	OwnerCluster string `json:"ownerCluster,omitempty"`
+}
+
+// LoadBalancerStatus defines the status of the domain load balancer
+type LoadBalancerStatus struct {
+	// Provider is the provider of this load balancer
+	Provider string `json:"provider"`
+	// DNSRecords are DNS records that should be associated with the domain
+	// +optional
+	DNSRecords []DNSRecord `json:"dnsRecords,omitempty"`
+	// AliasTarget is the hostname of load balancer that an apex domain
+	// should be aliased to, using ALIAS, ANAME or CNAME flattening records
+	// if supported by the DNS provider. Otherwise, the A/AAAA records
+	// resolved from the hostname should be used.
+	// +optional
+	AliasTarget *string `json:"aliasTarget,omitempty"`
+}
+
+// DomainVerificationStatus is the status of domain verification key
+type DomainVerificationStatus struct {
+	// KeyRotatedAt is the time that verification key is last rotated
+	// +optional
+	KeyRotatedAt *metav1.Time `json:"keyRotatedAt,omitempty"`
+}
+
+// TakeoverStatus is the status of pending takeover of domain
+// ownership, after the owner registration becomes unverified
+type TakeoverStatus struct {
+	// OwnerUnverifiedSince is the time that the owner registration becomes
+	// unverified
+	OwnerUnverifiedSince metav1.Time `json:"ownerUnverifiedSince"`
+	// OwnerFailureCount is the number of consecutive failed verifications of
+	// the owner registration observing the verification record missing or
+	// mismatched
+	OwnerFailureCount int `json:"ownerFailureCount"`
+	// Claimant is the verified registration of another app claiming the
+	// domain
+	// +optional
+	Claimant *corev1.ObjectReference `json:"claimant,omitempty"`
+	// ClaimantVerifiedSince is the time that the claimant registration
+	// becomes verified
+	// +optional
+	ClaimantVerifiedSince *metav1.Time `json:"claimantVerifiedSince,omitempty"`
+}
+
+// DomainSummary summarizes the registrations and published DNS targets of
+// the domain
+type DomainSummary struct {
+	// Registrations is the number of active registrations of the domain
+	Registrations int `json:"registrations"`
+	// VerifiedRegistrations is the number of verified registrations
+	VerifiedRegistrations int `json:"verifiedRegistrations"`
+	// PendingRegistrations is the number of registrations not verified yet
+	PendingRegistrations int `json:"pendingRegistrations"`
+	// Owner is the registration owning the domain, in format of
+	// namespace/name
+	// +optional
+	Owner string `json:"owner,omitempty"`
+	// LastVerificationTime is the latest verification time of the
+	// registrations in this cluster
+	// +optional
+	LastVerificationTime *metav1.Time `json:"lastVerificationTime,omitempty"`
+	// DNSTargets are the values of DNS records published for the domain
+	// +optional
+	DNSTargets []string `json:"dnsTargets,omitempty"`
+}
+
+// CustomDomainStatus defines the observed state of CustomDomain
+type CustomDomainStatus struct {
+	// Current state of custom domain.
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	Conditions []api.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+	// ObservedGeneration is the most recent generation observed by controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Phase is a summary of current state of domain.
+	// +optional
+	Phase string `json:"phase,omitempty"`
+	// PrimaryRegistration is the primary registration owning the domain
+	// +optional
+	PrimaryRegistration *corev1.ObjectReference `json:"primaryRegistration,omitempty"`
+	// OrphanedAt is the time that the domain is observed to have no registrations
+	// +optional
+	OrphanedAt *metav1.Time `json:"orphanedAt,omitempty"`
+	// ReleasedAt is the time that the verified owner registration of the
+	// domain is last deleted
+	// +optional
+	ReleasedAt *metav1.Time `json:"releasedAt,omitempty"`
+	// ReleasedApp is the app (namespace) of the owner registration last
+	// deleted. It may re-claim the domain during quarantine.
+	// +optional
+	ReleasedApp string `json:"releasedApp,omitempty"`
+	// ReleasedCluster is the workload cluster of the owner registration last
+	// deleted in multi-cluster mode.
+	// +optional
+	ReleasedCluster string `json:"releasedCluster,omitempty"`
+	// QuarantineUntil is the time until which the released domain cannot be
+	// claimed by other apps
+	// +optional
+	QuarantineUntil *metav1.Time `json:"quarantineUntil,omitempty"`
+	// Takeover is the status of pending takeover of domain ownership
+	// +optional
+	Takeover *TakeoverStatus `json:"takeover,omitempty"`
+	// Summary summarizes the registrations and published DNS targets of the
+	// domain
+	// +optional
+	Summary *DomainSummary `json:"summary,omitempty"`
+	// Verification is the status of domain verification key
+	// +optional
+	Verification *DomainVerificationStatus `json:"verification,omitempty"`
+	// LoadBalancer is the status of the domain load balancer
+	// +optional
+	LoadBalancer *LoadBalancerStatus `json:"loadBalancer,omitempty"`
+	// Zone is the registrable domain (public suffix plus one label) that the
+	// domain is under.
+	// +optional
+	Zone string `json:"zone,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,shortName=cd
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Owner",type=string,JSONPath=`.spec.ownerRef.namespace`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Provider",type=string,JSONPath=`.status.loadBalancer.provider`
+// +kubebuilder:printcolumn:name="DNS Target",type=string,JSONPath=`.status.loadBalancer.dnsRecords[0].value`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// CustomDomain is the Schema for the customdomains API
+type CustomDomain struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CustomDomainSpec   `json:"spec,omitempty"`
+	Status CustomDomainStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// CustomDomainList contains a list of CustomDomain
+type CustomDomainList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CustomDomain `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CustomDomain{}, &CustomDomainList{})
+}
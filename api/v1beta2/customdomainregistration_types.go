@@ -0,0 +1,447 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/skygeario/k8s-controller/api"
+)
+
+// BackendSpec is the backend serving traffic of custom domain
+type BackendSpec struct {
+	// ServiceName is the name of backend Service.
+	ServiceName string `json:"serviceName"`
+	// ServicePort is the port of backend Service.
+	ServicePort int `json:"servicePort"`
+	// RedirectToURL is where to redirect the user, instead of serving
+	// traffic using backend Service.
+	// +optional
+	RedirectToURL *string `json:"redirectToURL,omitempty"`
+}
+
+// IssuerReference is a reference to a cert-manager issuer
+type IssuerReference struct {
+	// Kind is the kind of issuer, either ClusterIssuer or Issuer.
+	// Defaults to ClusterIssuer.
+	// +optional
+	Kind string `json:"kind,omitempty"`
+	// Name is the name of issuer.
+	Name string `json:"name"`
+}
+
+// TLSSpec is the TLS configuration of custom domain
+type TLSSpec struct {
+	// CertSecretName is the name of Secret storing custom TLS certificate.
+	// +optional
+	CertSecretName *string `json:"certSecretName,omitempty"`
+	// IssuerRef is the issuer used to issue TLS certificate, overriding the
+	// default issuer of controller.
+	// +optional
+	IssuerRef *IssuerReference `json:"issuerRef,omitempty"`
+	// SecretName is the name of Secret storing the certificate issued by
+	// built-in ACME client.
+	// +optional
+	SecretName *string `json:"secretName,omitempty"`
+}
+
+// RoutingMode is the mode of routing traffic to custom domain
+// +kubebuilder:validation:Enum=Ingress;GatewayAPI;Istio
+type RoutingMode string
+
+// RoutingSpec is the routing configuration of custom domain
+type RoutingSpec struct {
+	// Mode is the mode of routing. Defaults to Ingress.
+	// +optional
+	Mode RoutingMode `json:"mode,omitempty"`
+}
+
+// HealthCheckScheme is the scheme of health probes
+// +kubebuilder:validation:Enum=HTTP;HTTPS
+type HealthCheckScheme string
+
+// HealthCheckSpec is the configuration of health probes against the custom
+// domain, performed after routing of the domain is configured
+type HealthCheckSpec struct {
+	// Path is the path of probe requests. Defaults to /.
+	// +optional
+	Path string `json:"path,omitempty"`
+	// Scheme is the scheme of probe requests. Defaults to HTTPS.
+	// +optional
+	Scheme HealthCheckScheme `json:"scheme,omitempty"`
+	// Interval is the interval between probes. Defaults to 1 minute.
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// RedirectSpec is the HTTP redirect configuration of custom domain
+type RedirectSpec struct {
+	// URL is the target URL that requests are redirected to.
+	URL string `json:"url"`
+	// StatusCode is the HTTP status code of redirect. Defaults to 302.
+	// +kubebuilder:validation:Enum=301;302;303;307;308
+	// +optional
+	StatusCode int `json:"statusCode,omitempty"`
+	// PreservePath indicates the path and query of requests are appended to
+	// the target URL, which must not contain path in this case.
+	// +optional
+	PreservePath bool `json:"preservePath,omitempty"`
+}
+
+// VerificationMethod is the method of verifying domain ownership
+// +kubebuilder:validation:Enum=DNS;HTTP;CNAME;Email
+type VerificationMethod string
+
+// VerificationRecordType is the type of DNS record of DNS verification
+// +kubebuilder:validation:Enum=TXT;CNAME
+type VerificationRecordType string
+
+// VerificationSpec is the verification configuration of custom domain
+type VerificationSpec struct {
+	// Method is the method of verification. Defaults to DNS.
+	// +optional
+	Method VerificationMethod `json:"method,omitempty"`
+	// RecordType is the type of DNS record of DNS verification. Defaults to
+	// TXT.
+	// +optional
+	RecordType VerificationRecordType `json:"recordType,omitempty"`
+	// DeadlineSeconds is the duration in seconds that the domain must be
+	// verified within, since creation of registration or domain becoming
+	// unverified. The registration is marked as failed after the deadline.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	DeadlineSeconds *int64 `json:"deadlineSeconds,omitempty"`
+	// VerifyAt is the time that next verification should be performed
+	// +optional
+	VerifyAt *metav1.Time `json:"verifyAt,omitempty"`
+	// ReverificationInterval is the interval between re-verification of
+	// verified domain. Zero disables re-verification.
+	// +optional
+	ReverificationInterval *metav1.Duration `json:"reverificationInterval,omitempty"`
+}
+
+// RegistrationRole is the role of registration sharing a domain
+// +kubebuilder:validation:Enum=Primary;Secondary
+type RegistrationRole string
+
+// CustomDomainRegistrationSpec defines the desired state of CustomDomainRegistration
+type CustomDomainRegistrationSpec struct {
+	// DomainName is the custom domain name registered with the app.
+	// Wildcard domain name (e.g. *.example.com) is allowed, in which case the
+	// resource name should be zz--wildcard.example.com. Internationalized
+	// domain name is allowed, in which case the resource name should be its
+	// punycode form.
+	DomainName string `json:"domainName"`
+	// Role is the role of registration. Defaults to Primary.
+	// +optional
+	Role RegistrationRole `json:"role,omitempty"`
+	// LoadBalancerClass selects the load balancer that DNS records of the
+	// domain target, when the platform runs multiple load balancers. Defaults
+	// to the load balancer configured in controller.
+	// +optional
+	LoadBalancerClass string `json:"loadBalancerClass,omitempty"`
+	// DedicatedIP requests a dedicated IP address for the domain, allocated
+	// from IP pools of the platform. It cannot be changed after creation.
+	// +optional
+	DedicatedIP bool `json:"dedicatedIP,omitempty"`
+	// Backend is the backend serving traffic of custom domain
+	Backend BackendSpec `json:"backend"`
+	// TLS is the TLS configuration of custom domain
+	// +optional
+	TLS *TLSSpec `json:"tls,omitempty"`
+	// Routing is the routing configuration of custom domain
+	// +optional
+	Routing *RoutingSpec `json:"routing,omitempty"`
+	// HealthCheck enables health probes against the custom domain, reported
+	// in EndpointHealthy condition.
+	// +optional
+	HealthCheck *HealthCheckSpec `json:"healthCheck,omitempty"`
+	// Redirect redirects requests of custom domain to the target URL,
+	// instead of serving traffic using backend Service.
+	// +optional
+	Redirect *RedirectSpec `json:"redirect,omitempty"`
+	// RedirectSibling registers the sibling host (www subdomain of apex
+	// domain, or apex domain of www subdomain) redirecting to this domain.
+	// +optional
+	RedirectSibling bool `json:"redirectSibling,omitempty"`
+	// Approved is whether the registration is approved by operators, if
+	// approval is required by controller or domain policies. Changing it
+	// requires the approve verb on customdomainregistrations.
+	// +optional
+	Approved bool `json:"approved,omitempty"`
+	// Verification is the verification configuration of custom domain
+	// +optional
+	Verification *VerificationSpec `json:"verification,omitempty"`
+	// TransferTo is the registration (in format of namespace/name) that
+	// ownership of the domain should be transferred to. Ownership is
+	// transferred once the target registration is verified.
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?/[a-z0-9]([-.a-z0-9]*[a-z0-9])?$`
+	// +optional
+	TransferTo *string `json:"transferTo,omitempty"`
+	// ExpiresAt is the time that the registration is deleted, if the domain
+	// is not verified by then. The domain becomes claimable by other
+	// registrations after deletion.
+	// +optional
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+	// TTLSecondsAfterVerificationFailure is the duration in seconds that the
+	// registration is kept after it failed verification deadline, before it
+	// is deleted.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	TTLSecondsAfterVerificationFailure *int64 `json:"ttlSecondsAfterVerificationFailure,omitempty"`
+}
+
+// DNSInstruction is an instruction to configure a DNS record of the domain
+type DNSInstruction struct {
+	// Name is name of DNS record
+	Name string `json:"name"`
+	// Type is type of DNS record
+	Type string `json:"type"`
+	// Value is value of DNS record
+	Value string `json:"value"`
+	// Description is a human-readable instruction to configure the record
+	Description string `json:"description"`
+	// Command is an example command to check the record is configured
+	// +optional
+	Command string `json:"command,omitempty"`
+}
+
+// DNSRecord is a DNS record associated with the domain
+type DNSRecord struct {
+	// Name is name of DNS record
+	Name string `json:"name"`
+	// Type is type of DNS record
+	Type string `json:"type"`
+	// Value is value of DNS record
+	Value string `json:"value"`
+}
+
+// VerificationFailure describes what is observed in the last failed
+// verification
+type VerificationFailure struct {
+	// Reason is the reason of failure
+	Reason string `json:"reason"`
+	// RecordName is the DNS record name or URL queried
+	// +optional
+	RecordName string `json:"recordName,omitempty"`
+	// ExpectedValue is the expected record value or token
+	// +optional
+	ExpectedValue string `json:"expectedValue,omitempty"`
+	// ObservedValues are the record values or token actually observed
+	// +optional
+	ObservedValues []string `json:"observedValues,omitempty"`
+	// Resolver is the DNS resolver used
+	// +optional
+	Resolver string `json:"resolver,omitempty"`
+}
+
+// VerificationStatus is the status of domain verification
+type VerificationStatus struct {
+	// LastVerificationTime is the time that last verification is performed
+	// +optional
+	LastVerificationTime *metav1.Time `json:"lastVerificationTime,omitempty"`
+	// NextVerificationTime is the time that next verification is scheduled
+	// +optional
+	NextVerificationTime *metav1.Time `json:"nextVerificationTime,omitempty"`
+	// URL is the URL that should serve the verification token, when
+	// verifying using HTTP.
+	// +optional
+	URL *string `json:"url,omitempty"`
+	// FailureCount is the number of consecutive failed verifications
+	// +optional
+	FailureCount int `json:"failureCount,omitempty"`
+	// ConclusiveFailureCount is the number of consecutive failed
+	// verifications observing the verification record missing or
+	// mismatched. Inconclusive failures, e.g. lookup timeouts, are not
+	// counted and do not reset the count.
+	// +optional
+	ConclusiveFailureCount int `json:"conclusiveFailureCount,omitempty"`
+	// Failure describes the last failed verification
+	// +optional
+	Failure *VerificationFailure `json:"failure,omitempty"`
+	// View is the DNS view used in the last verification: Local if looked
+	// up using resolvers of the cluster, or External if looked up using
+	// public resolvers.
+	// +optional
+	View string `json:"view,omitempty"`
+}
+
+// DNSRecordStatus is the observed state of a DNS record
+type DNSRecordStatus struct {
+	// Name is name of DNS record
+	Name string `json:"name"`
+	// Type is type of DNS record
+	Type string `json:"type"`
+	// Value is the expected value of DNS record
+	Value string `json:"value"`
+	// ObservedValues are the values actually observed in DNS
+	// +optional
+	ObservedValues []string `json:"observedValues,omitempty"`
+	// Configured indicates the record is configured as expected
+	Configured bool `json:"configured"`
+}
+
+// DNSCheckStatus is the status of checking DNS records of domain
+type DNSCheckStatus struct {
+	// LastCheckTime is the time that DNS records are last checked
+	// +optional
+	LastCheckTime *metav1.Time `json:"lastCheckTime,omitempty"`
+	// Records are the observed states of DNS records
+	// +optional
+	Records []DNSRecordStatus `json:"records,omitempty"`
+}
+
+// HealthCheckStatus is the status of health probes against the custom domain
+type HealthCheckStatus struct {
+	// LastProbeTime is the time that the domain is last probed
+	// +optional
+	LastProbeTime *metav1.Time `json:"lastProbeTime,omitempty"`
+	// StatusCode is the HTTP status code of last probe, omitted if the
+	// request failed
+	// +optional
+	StatusCode int `json:"statusCode,omitempty"`
+	// LatencyMilliseconds is the latency of last probe in milliseconds
+	// +optional
+	LatencyMilliseconds int64 `json:"latencyMilliseconds,omitempty"`
+}
+
+// TLSStatus is the status of TLS certificate of domain
+type TLSStatus struct {
+	// NotAfter is the expiry time of TLS certificate
+	// +optional
+	NotAfter *metav1.Time `json:"notAfter,omitempty"`
+	// Issuer is the issuer of TLS certificate
+	// +optional
+	Issuer string `json:"issuer,omitempty"`
+}
+
+// AttestationStatus is an attestation of domain ownership submitted by an
+// external verifier
+type AttestationStatus struct {
+	// Time is the time of attestation
+	Time metav1.Time `json:"time"`
+	// ObservedGeneration is the generation of registration that is attested.
+	// Attestation is invalidated when the registration is changed.
+	ObservedGeneration int64 `json:"observedGeneration"`
+	// Attester identifies the external verifier submitting the attestation
+	// +optional
+	Attester string `json:"attester,omitempty"`
+}
+
+// EmailChallengeStatus is the status of confirmation email sent to contacts
+// of domain
+type EmailChallengeStatus struct {
+	// Recipients are the masked email addresses that the email is sent to
+	// +optional
+	Recipients []string `json:"recipients,omitempty"`
+	// SentTime is the time that the email is sent
+	SentTime metav1.Time `json:"sentTime"`
+	// ExpiryTime is the time that the confirmation link expires
+	ExpiryTime metav1.Time `json:"expiryTime"`
+	// ObservedGeneration is the generation of registration that the email is
+	// sent for. The link is invalidated when the registration is changed.
+	ObservedGeneration int64 `json:"observedGeneration"`
+}
+
+// CustomDomainRegistrationStatus defines the observed state of CustomDomainRegistration
+type CustomDomainRegistrationStatus struct {
+	// Current state of registration.
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	Conditions []api.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+	// ObservedGeneration is the most recent generation observed by controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Phase is a summary of current state of registration.
+	// +optional
+	Phase string `json:"phase,omitempty"`
+	// DomainName is the registered domain name in ASCII (punycode) form
+	// +optional
+	DomainName string `json:"domainName,omitempty"`
+	// UnicodeDomainName is the registered domain name in Unicode form
+	// +optional
+	UnicodeDomainName string `json:"unicodeDomainName,omitempty"`
+	// DNSRecords are DNS records that should be associated with the domain
+	// +optional
+	DNSRecords []DNSRecord `json:"dnsRecords,omitempty"`
+	// Instructions are human-readable instructions to configure DNS records
+	// of the domain
+	// +optional
+	Instructions []DNSInstruction `json:"instructions,omitempty"`
+	// Verification is the status of domain verification
+	// +optional
+	Verification *VerificationStatus `json:"verification,omitempty"`
+	// DNSCheck is the status of checking DNS records pointing the domain
+	// to load balancer
+	// +optional
+	DNSCheck *DNSCheckStatus `json:"dnsCheck,omitempty"`
+	// HealthCheck is the status of health probes against the domain
+	// +optional
+	HealthCheck *HealthCheckStatus `json:"healthCheck,omitempty"`
+	// CertSecretName is the name of TLS certificate secret
+	// +optional
+	CertSecretName *string `json:"certSecretName,omitempty"`
+
+	// TLS is the status of TLS certificate
+	// +optional
+	TLS *TLSStatus `json:"tls,omitempty"`
+
+	// Attestation is the last attestation of domain ownership submitted by
+	// an external verifier
+	// +optional
+	Attestation *AttestationStatus `json:"attestation,omitempty"`
+	// EmailChallenge is the status of confirmation email of Email
+	// verification
+	// +optional
+	EmailChallenge *EmailChallengeStatus `json:"emailChallenge,omitempty"`
+	// Zone is the registrable domain (public suffix plus one label) that the
+	// domain is under.
+	// +optional
+	Zone string `json:"zone,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=cdr
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Domain",type=string,JSONPath=`.spec.domainName`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Verified",type=string,JSONPath=`.status.conditions[?(@.type=="Verified")].status`
+// +kubebuilder:printcolumn:name="Accepted",type=string,JSONPath=`.status.conditions[?(@.type=="Accepted")].status`
+// +kubebuilder:printcolumn:name="DNS Target",type=string,JSONPath=`.status.dnsRecords[0].value`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// CustomDomainRegistration is the Schema for the customdomainregistrations API
+type CustomDomainRegistration struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CustomDomainRegistrationSpec   `json:"spec,omitempty"`
+	Status CustomDomainRegistrationStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// CustomDomainRegistrationList contains a list of CustomDomainRegistration
+type CustomDomainRegistrationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CustomDomainRegistration `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CustomDomainRegistration{}, &CustomDomainRegistrationList{})
+}
@@ -0,0 +1,195 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/skygeario/k8s-controller/api"
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/util/condition"
+	"github.com/skygeario/k8s-controller/pkg/util/dnsname"
+)
+
+func (c *command) list() error {
+	regs, err := c.registrations()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(c.Out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tNAME\tDOMAIN\tZONE\tPHASE\tVERIFIED")
+	for _, reg := range regs {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			reg.Namespace, reg.Name, reg.Spec.DomainName, reg.Status.Zone, reg.Status.Phase,
+			conditionStatus(reg.Status.Conditions, domainv1beta1.RegistrationVerified))
+	}
+	return w.Flush()
+}
+
+func (c *command) reverify() error {
+	regs, err := c.registrations()
+	if err != nil {
+		return err
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{
+				api.VerifyNowAnnotation: time.Now().UTC().Format(time.RFC3339),
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, reg := range regs {
+		if c.DryRun {
+			fmt.Fprintf(c.Out, "%s/%s: would request verification (dry run)\n", reg.Namespace, reg.Name)
+			continue
+		}
+		_, err := c.Clientset.DomainV1beta1().CustomDomainRegistrations(reg.Namespace).
+			Patch(reg.Name, types.MergePatchType, patch)
+		if err != nil {
+			failed++
+			fmt.Fprintf(c.Out, "%s/%s: %v\n", reg.Namespace, reg.Name, err)
+			continue
+		}
+		fmt.Fprintf(c.Out, "%s/%s: requested verification\n", reg.Namespace, reg.Name)
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to request verification of %d registrations", failed)
+	}
+	return nil
+}
+
+// export writes a CSV report of ownership of domains of the selected
+// registrations.
+func (c *command) export() error {
+	regs, err := c.registrations()
+	if err != nil {
+		return err
+	}
+	domains, err := c.Clientset.DomainV1beta1().CustomDomains().List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	owners := map[string]*corev1.ObjectReference{}
+	for _, d := range domains.Items {
+		owners[d.Name] = d.Spec.OwnerRef
+	}
+
+	w := csv.NewWriter(c.Out)
+	_ = w.Write([]string{
+		"domain", "zone", "namespace", "name", "role", "phase",
+		"verified", "owner", "last_verification_time",
+	})
+	for _, reg := range regs {
+		owner := owners[dnsname.ResourceName(reg.ASCIIDomainName())]
+		isOwner := owner != nil && owner.UID == reg.UID
+		lastVerificationTime := ""
+		if reg.Status.LastVerificationTime != nil {
+			lastVerificationTime = reg.Status.LastVerificationTime.UTC().Format(time.RFC3339)
+		}
+		_ = w.Write([]string{
+			reg.ASCIIDomainName(),
+			reg.Status.Zone,
+			reg.Namespace,
+			reg.Name,
+			string(reg.Spec.Role),
+			string(reg.Status.Phase),
+			conditionStatus(reg.Status.Conditions, domainv1beta1.RegistrationVerified),
+			strconv.FormatBool(isOwner),
+			lastVerificationTime,
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// transfer forces ownership of domain to the registration, bypassing the
+// transfer protocol. The registration must be a primary registration of the
+// domain.
+func (c *command) transfer(domainName string, target string) error {
+	parts := strings.SplitN(target, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return fmt.Errorf("invalid registration %q: expected <namespace>/<name>", target)
+	}
+	namespace, name := parts[0], parts[1]
+
+	asciiName, err := dnsname.Normalize(domainName)
+	if err != nil {
+		return err
+	}
+	domain, err := c.Clientset.DomainV1beta1().CustomDomains().Get(dnsname.ResourceName(asciiName), metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	var ref *domainv1beta1.CustomDomainRegistrationReference
+	for i, r := range domain.Spec.Registrations {
+		if r.Namespace == namespace && r.Name == name && r.IsPrimary() {
+			ref = &domain.Spec.Registrations[i]
+			break
+		}
+	}
+	if ref == nil {
+		return fmt.Errorf("%s/%s is not a primary registration of domain %s", namespace, name, asciiName)
+	}
+	if owner := domain.Spec.OwnerRef; owner != nil && owner.UID == ref.UID {
+		fmt.Fprintf(c.Out, "%s is already owned by %s/%s\n", asciiName, namespace, name)
+		return nil
+	}
+
+	if c.DryRun {
+		fmt.Fprintf(c.Out, "%s: would transfer ownership to %s/%s (dry run)\n", asciiName, namespace, name)
+		return nil
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"ownerApp": ref.Namespace,
+			"ownerRef": ref.ObjectReference,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = c.Clientset.DomainV1beta1().CustomDomains().Patch(domain.Name, types.MergePatchType, patch)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.Out, "%s: transferred ownership to %s/%s\n", asciiName, namespace, name)
+	return nil
+}
+
+func conditionStatus(conditions []api.Condition, conditionType domainv1beta1.CustomDomainRegistrationConditionType) string {
+	if cond := condition.Lookup(conditions, string(conditionType)); cond != nil {
+		return string(cond.Status)
+	}
+	return string(metav1.ConditionUnknown)
+}
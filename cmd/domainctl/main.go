@@ -0,0 +1,173 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// domainctl is an operator CLI for batch operations on custom domains,
+// driven through the Kubernetes API.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
+	"k8s.io/client-go/tools/clientcmd"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/client/clientset/versioned"
+	"github.com/skygeario/k8s-controller/pkg/util/condition"
+)
+
+const usage = `Usage: domainctl [flags] <command> [args]
+
+Commands:
+  list                         list registrations
+  reverify                     request verification of registrations
+  export                       export domain ownership report in CSV
+  transfer <domain> <ns/name>  force ownership of domain to the registration
+
+Registrations are selected with --namespace, --selector, --condition and
+--zone.
+
+Flags:
+`
+
+type command struct {
+	Out       io.Writer
+	Clientset versioned.Interface
+	Namespace string
+	Selector  string
+	Condition *conditionFilter
+	Zone      string
+	DryRun    bool
+}
+
+// conditionFilter matches registrations with condition of type in status.
+type conditionFilter struct {
+	Type   string
+	Status metav1.ConditionStatus
+}
+
+func parseConditionFilter(value string) (*conditionFilter, error) {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return nil, fmt.Errorf("invalid condition %q: expected <type>=<status>", value)
+	}
+	switch status := metav1.ConditionStatus(parts[1]); status {
+	case metav1.ConditionTrue, metav1.ConditionFalse, metav1.ConditionUnknown:
+		return &conditionFilter{Type: parts[0], Status: status}, nil
+	default:
+		return nil, fmt.Errorf("invalid condition status %q", parts[1])
+	}
+}
+
+func main() {
+	flags := flag.NewFlagSet("domainctl", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+
+	var kubeconfig, kubeContext, conditionValue string
+	cmd := command{Out: os.Stdout}
+	flags.StringVar(&kubeconfig, "kubeconfig", "", "Path to the kubeconfig file.")
+	flags.StringVar(&kubeContext, "context", "", "Name of the kubeconfig context to use.")
+	flags.StringVar(&cmd.Namespace, "namespace", metav1.NamespaceAll, "Namespace of registrations. Defaults to all namespaces.")
+	flags.StringVar(&cmd.Selector, "selector", "", "Label selector of registrations.")
+	flags.StringVar(&conditionValue, "condition", "", "Select registrations with condition in status, e.g. Verified=False.")
+	flags.StringVar(&cmd.Zone, "zone", "", "Select registrations under the registrable zone, e.g. example.com.")
+	flags.BoolVar(&cmd.DryRun, "dry-run", false, "Print the registrations to be changed without changing them.")
+	_ = flags.Parse(os.Args[1:])
+
+	if flags.NArg() < 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if conditionValue != "" {
+		filter, err := parseConditionFilter(conditionValue)
+		if err != nil {
+			fatal(err)
+		}
+		cmd.Condition = filter
+	}
+
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = kubeconfig
+	restConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		loadingRules,
+		&clientcmd.ConfigOverrides{CurrentContext: kubeContext},
+	).ClientConfig()
+	if err != nil {
+		fatal(err)
+	}
+	cmd.Clientset, err = versioned.NewForConfig(restConfig)
+	if err != nil {
+		fatal(err)
+	}
+
+	args := flags.Args()
+	switch {
+	case args[0] == "list" && len(args) == 1:
+		err = cmd.list()
+	case args[0] == "reverify" && len(args) == 1:
+		err = cmd.reverify()
+	case args[0] == "export" && len(args) == 1:
+		err = cmd.export()
+	case args[0] == "transfer" && len(args) == 3:
+		err = cmd.transfer(args[1], args[2])
+	default:
+		flags.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fatal(err)
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintf(os.Stderr, "error: %v\n", err)
+	os.Exit(1)
+}
+
+// registrations lists the registrations selected by flags.
+func (c *command) registrations() ([]domainv1beta1.CustomDomainRegistration, error) {
+	list, err := c.Clientset.DomainV1beta1().CustomDomainRegistrations(c.Namespace).
+		List(metav1.ListOptions{LabelSelector: c.Selector})
+	if err != nil {
+		return nil, err
+	}
+
+	var regs []domainv1beta1.CustomDomainRegistration
+	for _, reg := range list.Items {
+		if c.Zone != "" && !strings.EqualFold(reg.Status.Zone, strings.TrimSuffix(c.Zone, ".")) {
+			continue
+		}
+		if c.Condition != nil {
+			cond := condition.Lookup(reg.Status.Conditions, c.Condition.Type)
+			status := metav1.ConditionUnknown
+			if cond != nil {
+				status = cond.Status
+			}
+			if status != c.Condition.Status {
+				continue
+			}
+		}
+		regs = append(regs, reg)
+	}
+	return regs, nil
+}
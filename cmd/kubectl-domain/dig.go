@@ -0,0 +1,170 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/verification"
+	"github.com/skygeario/k8s-controller/pkg/util/dnsname"
+)
+
+// dig runs the verification and DNS configuration lookups of the controller
+// against the records published in status of the registration.
+func (c *command) dig(ctx context.Context, name string) error {
+	reg, err := c.getRegistration(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	var domain domainv1beta1.CustomDomain
+	domainKey := types.NamespacedName{Namespace: c.DomainNamespace, Name: dnsname.ResourceName(reg.ASCIIDomainName())}
+	if err := c.Client.Get(ctx, domainKey, &domain); err != nil {
+		return fmt.Errorf("cannot get domain %s: %w", reg.ASCIIDomainName(), err)
+	}
+	var lbRecords []domainv1beta1.CustomDomainDNSRecord
+	if domain.Status.LoadBalancer != nil {
+		lbRecords = domain.Status.LoadBalancer.DNSRecords
+	}
+
+	resolver := verification.NewRateLimitedResolver(verification.RateLimitConfig{Servers: c.DNSServers})
+	if len(c.DNSServers) > 0 {
+		if err := resolver.Check(ctx); err != nil {
+			return err
+		}
+	}
+
+	method := verification.MethodDNS
+	if reg.Spec.Verification != nil && reg.Spec.Verification.Method != "" {
+		method = verification.Method(reg.Spec.Verification.Method)
+	}
+	if method == verification.MethodDNS && reg.Spec.Verification != nil &&
+		reg.Spec.Verification.RecordType == domainv1beta1.VerificationRecordTypeCNAME {
+		method = verification.MethodDNSCNAME
+	}
+	fmt.Fprintf(c.Out, "Domain:\t%s\n", domain.Name)
+	fmt.Fprintf(c.Out, "Resolver:\t%s\n", verification.ResolverName(resolver))
+	fmt.Fprintf(c.Out, "\nVerification (%s):\n", method)
+
+	var verifyErr error
+	switch method {
+	case verification.MethodHTTP:
+		if reg.Status.VerificationURL == nil {
+			fmt.Fprintln(c.Out, "  verification URL is not published yet")
+			break
+		}
+		token := path.Base(*reg.Status.VerificationURL)
+		verifier := verification.NewHTTPVerifier(&http.Client{Timeout: 10 * time.Second})
+		fmt.Fprintf(c.Out, "  GET %s\n", *reg.Status.VerificationURL)
+		verifyErr = verifier.VerifyDomain(ctx, domain.Name, token)
+	case verification.MethodCNAME:
+		record := findChallengeRecord(reg.Status.DNSRecords, lbRecords, "CNAME")
+		if record == nil {
+			fmt.Fprintln(c.Out, "  challenge record is not published yet")
+			break
+		}
+		fmt.Fprintf(c.Out, "  %s CNAME %s\n", record.Name, record.Value)
+		verifyErr = verification.NewCNAMEVerifier(resolver).VerifyDomain(ctx, domain.Name, record.Value)
+	case verification.MethodDNSCNAME:
+		record := findChallengeRecord(reg.Status.DNSRecords, lbRecords, "CNAME")
+		if record == nil {
+			fmt.Fprintln(c.Out, "  challenge record is not published yet")
+			break
+		}
+		fmt.Fprintf(c.Out, "  %s CNAME %s\n", record.Name, record.Value)
+		verifyErr = verification.NewCNAMEVerifier(resolver).VerifyRecord(ctx, record.Name, record.Value)
+	case verification.MethodEmail:
+		fmt.Fprintln(c.Out, "  verified by confirmation email; no records to look up")
+	default:
+		record := findChallengeRecord(reg.Status.DNSRecords, lbRecords, "TXT")
+		if record == nil {
+			fmt.Fprintln(c.Out, "  challenge record is not published yet")
+			break
+		}
+		fmt.Fprintf(c.Out, "  %s TXT %s\n", record.Name, record.Value)
+		verifyErr = verification.NewDNSVerifier(resolver).VerifyDomain(ctx, domain.Name, record.Value)
+	}
+	if verifyErr != nil {
+		c.printFailure(verifyErr)
+	} else if method != verification.MethodEmail {
+		fmt.Fprintln(c.Out, "  => verified")
+	}
+
+	fmt.Fprintln(c.Out, "\nDNS configuration:")
+	if len(lbRecords) == 0 {
+		fmt.Fprintln(c.Out, "  load balancer is not provisioned yet")
+		return nil
+	}
+	records := make([]verification.DNSRecord, len(lbRecords))
+	for i, record := range lbRecords {
+		records[i] = verification.DNSRecord{Name: record.Name, Type: record.Type, Value: record.Value}
+	}
+	configured, results, err := verification.NewDNSConfigChecker(resolver).CheckDNSConfig(ctx, domain.Name, records)
+	if err != nil {
+		c.printFailure(err)
+		return nil
+	}
+	for _, result := range results {
+		mark := "missing"
+		if result.Configured {
+			mark = "ok"
+		}
+		fmt.Fprintf(c.Out, "  %s %s %s: %s (observed: %s)\n",
+			result.Name, result.Type, result.Value, mark, strings.Join(result.ObservedValues, ", "))
+	}
+	if configured {
+		fmt.Fprintln(c.Out, "  => configured")
+	} else {
+		fmt.Fprintln(c.Out, "  => not configured")
+	}
+	return nil
+}
+
+func (c *command) printFailure(err error) {
+	fmt.Fprintf(c.Out, "  => failed (%s): %v\n", verification.FailureReason(err), err)
+	if details := verification.GetFailureDetails(err); details != nil && len(details.ObservedValues) > 0 {
+		fmt.Fprintf(c.Out, "     observed: %s\n", strings.Join(details.ObservedValues, ", "))
+	}
+}
+
+// findChallengeRecord returns the record of type in registration records
+// that is not a load balancer record.
+func findChallengeRecord(records []domainv1beta1.CustomDomainDNSRecord, lbRecords []domainv1beta1.CustomDomainDNSRecord, recordType string) *domainv1beta1.CustomDomainDNSRecord {
+	for i, record := range records {
+		if record.Type != recordType {
+			continue
+		}
+		isLB := false
+		for _, lb := range lbRecords {
+			if lb == record {
+				isLB = true
+				break
+			}
+		}
+		if !isLB {
+			return &records[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// kubectl-domain is a kubectl plugin for common operations on
+// CustomDomainRegistration resources. Install it to PATH and run as
+// `kubectl domain`.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/skygeario/k8s-controller/api"
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+)
+
+const usage = `Usage: kubectl domain [flags] <command> <name>
+
+Commands:
+  status <name>   show conditions and DNS instructions of the registration
+  verify <name>   request verification of the registration
+  dig <name>      look up DNS records of the registration as the controller does
+  release <name>  delete the registration, releasing the domain
+
+Flags:
+`
+
+type command struct {
+	Out             io.Writer
+	Client          client.Client
+	Namespace       string
+	DomainNamespace string
+	DNSServers      []string
+	Force           bool
+}
+
+func main() {
+	flags := flag.NewFlagSet("kubectl-domain", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+
+	var kubeconfig, kubeContext, namespace, dnsServers string
+	cmd := command{Out: os.Stdout}
+	flags.StringVar(&kubeconfig, "kubeconfig", "", "Path to the kubeconfig file.")
+	flags.StringVar(&kubeContext, "context", "", "Name of the kubeconfig context to use.")
+	flags.StringVar(&namespace, "namespace", "", "Namespace of the registration. Defaults to namespace of current context.")
+	flags.StringVar(&namespace, "n", "", "Shorthand for --namespace.")
+	flags.StringVar(&cmd.DomainNamespace, "domain-namespace", "", "Namespace of CustomDomain resources, as configured in the controller.")
+	flags.StringVar(&dnsServers, "dns-servers", "",
+		"Comma-separated addresses of DNS resolvers used by dig (see controller --dns-servers). Defaults to local DNS configuration.")
+	flags.BoolVar(&cmd.Force, "force", false, "Release the domain even if it is still serving traffic.")
+	_ = flags.Parse(os.Args[1:])
+
+	if flags.NArg() != 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	verb, name := flags.Arg(0), flags.Arg(1)
+	if dnsServers != "" {
+		cmd.DNSServers = strings.Split(dnsServers, ",")
+	}
+
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = kubeconfig
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		loadingRules,
+		&clientcmd.ConfigOverrides{CurrentContext: kubeContext},
+	)
+	cmd.Namespace = namespace
+	if cmd.Namespace == "" {
+		ns, _, err := clientConfig.Namespace()
+		if err != nil {
+			fatal(err)
+		}
+		cmd.Namespace = ns
+	}
+	restConfig, err := clientConfig.ClientConfig()
+	if err != nil {
+		fatal(err)
+	}
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = domainv1beta1.AddToScheme(scheme)
+	cmd.Client, err = client.New(restConfig, client.Options{Scheme: scheme})
+	if err != nil {
+		fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	switch verb {
+	case "status":
+		err = cmd.status(ctx, name)
+	case "verify":
+		err = cmd.verify(ctx, name)
+	case "dig":
+		err = cmd.dig(ctx, name)
+	case "release":
+		err = cmd.release(ctx, name)
+	default:
+		flags.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fatal(err)
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintf(os.Stderr, "error: %v\n", err)
+	os.Exit(1)
+}
+
+func (c *command) getRegistration(ctx context.Context, name string) (*domainv1beta1.CustomDomainRegistration, error) {
+	var reg domainv1beta1.CustomDomainRegistration
+	err := c.Client.Get(ctx, types.NamespacedName{Namespace: c.Namespace, Name: name}, &reg)
+	if apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("registration %s/%s not found", c.Namespace, name)
+	} else if err != nil {
+		return nil, err
+	}
+	return &reg, nil
+}
+
+func (c *command) status(ctx context.Context, name string) error {
+	reg, err := c.getRegistration(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.Out, "Domain:\t%s\n", reg.Spec.DomainName)
+	fmt.Fprintf(c.Out, "Phase:\t%s\n", reg.Status.Phase)
+	if reg.Status.NextVerificationTime != nil {
+		fmt.Fprintf(c.Out, "Next verification:\t%s\n", reg.Status.NextVerificationTime.Format(time.RFC3339))
+	}
+	if reg.Status.VerificationURL != nil {
+		fmt.Fprintf(c.Out, "Verification URL:\t%s\n", *reg.Status.VerificationURL)
+	}
+
+	fmt.Fprintln(c.Out, "\nConditions:")
+	w := tabwriter.NewWriter(c.Out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "  TYPE\tSTATUS\tREASON\tAGE\tMESSAGE")
+	for _, cond := range reg.Status.Conditions {
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n",
+			cond.Type, cond.Status, cond.Reason, age(cond.LastTransitionTime.Time), cond.Message)
+	}
+	_ = w.Flush()
+
+	if failure := reg.Status.VerificationFailure; failure != nil {
+		fmt.Fprintln(c.Out, "\nLast verification failure:")
+		fmt.Fprintf(c.Out, "  Reason:\t%s\n", failure.Reason)
+		if failure.RecordName != "" {
+			fmt.Fprintf(c.Out, "  Record:\t%s\n", failure.RecordName)
+		}
+		if failure.ExpectedValue != "" {
+			fmt.Fprintf(c.Out, "  Expected:\t%s\n", failure.ExpectedValue)
+		}
+		if len(failure.ObservedValues) > 0 {
+			fmt.Fprintf(c.Out, "  Observed:\t%v\n", failure.ObservedValues)
+		}
+		if failure.Resolver != "" {
+			fmt.Fprintf(c.Out, "  Resolver:\t%s\n", failure.Resolver)
+		}
+	}
+
+	if len(reg.Status.Instructions) > 0 {
+		fmt.Fprintln(c.Out, "\nDNS instructions:")
+		for _, inst := range reg.Status.Instructions {
+			fmt.Fprintf(c.Out, "  %s %s %s\n", inst.Name, inst.Type, inst.Value)
+			if inst.Description != "" {
+				fmt.Fprintf(c.Out, "    %s\n", inst.Description)
+			}
+			if inst.Command != "" {
+				fmt.Fprintf(c.Out, "    $ %s\n", inst.Command)
+			}
+		}
+	} else if len(reg.Status.DNSRecords) > 0 {
+		fmt.Fprintln(c.Out, "\nDNS records:")
+		for _, record := range reg.Status.DNSRecords {
+			fmt.Fprintf(c.Out, "  %s %s %s\n", record.Name, record.Type, record.Value)
+		}
+	}
+	return nil
+}
+
+func (c *command) verify(ctx context.Context, name string) error {
+	reg, err := c.getRegistration(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	patch := client.MergeFrom(reg.DeepCopy())
+	if reg.Annotations == nil {
+		reg.Annotations = map[string]string{}
+	}
+	reg.Annotations[api.VerifyNowAnnotation] = time.Now().UTC().Format(time.RFC3339)
+	if err := c.Client.Patch(ctx, reg, patch); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.Out, "requested verification of %s/%s\n", reg.Namespace, reg.Name)
+	return nil
+}
+
+func (c *command) release(ctx context.Context, name string) error {
+	reg, err := c.getRegistration(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	if c.Force && reg.Annotations[api.ForceDeleteAnnotation] != "true" {
+		patch := client.MergeFrom(reg.DeepCopy())
+		if reg.Annotations == nil {
+			reg.Annotations = map[string]string{}
+		}
+		reg.Annotations[api.ForceDeleteAnnotation] = "true"
+		if err := c.Client.Patch(ctx, reg, patch); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Client.Delete(ctx, reg); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	fmt.Fprintf(c.Out, "released %s (%s/%s)\n", reg.Spec.DomainName, reg.Namespace, reg.Name)
+	return nil
+}
+
+func age(t time.Time) string {
+	if t.IsZero() {
+		return "<unknown>"
+	}
+	return time.Since(t).Truncate(time.Second).String()
+}
@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/util/dnsname"
+)
+
+// AuditAction is a change of domain ownership recorded in audit log.
+type AuditAction string
+
+const (
+	// AuditVerificationSucceeded is recorded when a registration verifies
+	// ownership of the domain.
+	AuditVerificationSucceeded AuditAction = "VerificationSucceeded"
+	// AuditOwnershipGranted is recorded when a registration becomes owner of
+	// the domain.
+	AuditOwnershipGranted AuditAction = "OwnershipGranted"
+	// AuditOwnershipRevoked is recorded when owner registration loses
+	// ownership of the domain.
+	AuditOwnershipRevoked AuditAction = "OwnershipRevoked"
+	// AuditOwnershipTransferred is recorded when ownership of the domain is
+	// transferred on request of owner registration.
+	AuditOwnershipTransferred AuditAction = "OwnershipTransferred"
+	// AuditDomainReleased is recorded when a registration releases the
+	// domain.
+	AuditDomainReleased AuditAction = "DomainReleased"
+)
+
+// AuditLogger records ownership changes of domains as structured log entries,
+// and as events with reason Audit on the domain. Log entries are append-only
+// and should be retained by log collector for investigation of domain
+// takeovers; events are for quick inspection only.
+type AuditLogger struct {
+	Log      logr.Logger
+	Recorder record.EventRecorder
+}
+
+// Record records the action on domain performed by the actor registration.
+// It is a no-op if the logger is nil.
+func (a *AuditLogger) Record(d *domainv1beta1.CustomDomain, action AuditAction, actor corev1.ObjectReference, message string) {
+	if a == nil {
+		return
+	}
+
+	a.Log.Info(message,
+		"action", action,
+		"domain", dnsname.DomainName(d.Name),
+		"domainUID", d.UID,
+		"actorNamespace", actor.Namespace,
+		"actorName", actor.Name,
+		"actorUID", actor.UID,
+		"time", time.Now().UTC().Format(time.RFC3339),
+	)
+	if a.Recorder != nil {
+		a.Recorder.Eventf(d, corev1.EventTypeNormal, EventAudit, "%s by %s/%s (uid %s): %s",
+			action, actor.Namespace, actor.Name, actor.UID, message)
+	}
+}
+
+// registrationRef returns the reference to registration as audit actor.
+func registrationRef(reg *domainv1beta1.CustomDomainRegistration) corev1.ObjectReference {
+	return corev1.ObjectReference{
+		APIVersion: domainv1beta1.GroupVersion.String(),
+		Kind:       "CustomDomainRegistration",
+		Namespace:  reg.Namespace,
+		Name:       reg.Name,
+		UID:        reg.UID,
+	}
+}
@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/tls"
+)
+
+type stubTLSProvider struct {
+	status *tls.CertificateStatus
+	err    error
+}
+
+func (p *stubTLSProvider) Provision(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (*tls.ProvisionResult, error) {
+	return nil, nil
+}
+
+func (p *stubTLSProvider) Release(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (bool, error) {
+	return true, nil
+}
+
+func (p *stubTLSProvider) CertificateStatus(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (*tls.CertificateStatus, error) {
+	return p.status, p.err
+}
+
+func TestCertificatePendingCondition(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   *tls.CertificateStatus
+		err      error
+		expected metav1.ConditionStatus
+		reason   string
+		message  string
+	}{
+		{
+			name:     "not reported",
+			expected: metav1.ConditionFalse,
+		},
+		{
+			name:     "issuance failed",
+			status:   &tls.CertificateStatus{Status: metav1.ConditionFalse, Reason: "Failed", Message: "rate limited"},
+			expected: metav1.ConditionFalse,
+			reason:   "Failed",
+			message:  "rate limited",
+		},
+		{
+			name:     "certificate ready before secret",
+			status:   &tls.CertificateStatus{Status: metav1.ConditionTrue, Reason: "Ready"},
+			expected: metav1.ConditionFalse,
+		},
+		{
+			name:     "status unavailable",
+			err:      errors.New("cannot get certificate"),
+			expected: metav1.ConditionUnknown,
+			message:  "cannot get certificate",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &CustomDomainRegistrationReconciler{
+				TLSProvider: &stubTLSProvider{status: tt.status, err: tt.err},
+			}
+			cond := r.certificatePendingCondition(context.Background(), &domainv1beta1.CustomDomainRegistration{})
+			if cond.Type != string(domainv1beta1.RegistrationCertReady) {
+				t.Errorf("type = %s", cond.Type)
+			}
+			if cond.Status != tt.expected || cond.Reason != tt.reason || cond.Message != tt.message {
+				t.Errorf("condition = %#v", cond)
+			}
+		})
+	}
+}
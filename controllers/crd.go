@@ -0,0 +1,33 @@
+package controllers
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/util/dnsname"
+)
+
+// DomainNamespace is the namespace of CustomDomain resources, if the
+// resource is installed as namespaced. It is empty if the resource is
+// cluster-scoped.
+var DomainNamespace string
+
+// domainKey returns the key of CustomDomain resource of the domain name.
+func domainKey(domainName string) types.NamespacedName {
+	return types.NamespacedName{Namespace: DomainNamespace, Name: dnsname.ResourceName(domainName)}
+}
+
+// IsCustomDomainInstalled reports whether the CustomDomain resource is served
+// by the API server. It may be missing in clusters where only the
+// registration half of the controller is installed.
+func IsCustomDomainInstalled(mapper meta.RESTMapper) (bool, error) {
+	gvk := domainv1beta1.GroupVersion.WithKind("CustomDomain")
+	_, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
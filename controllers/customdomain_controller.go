@@ -17,22 +17,37 @@ package controllers
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/skygeario/k8s-controller/api"
 	domain "github.com/skygeario/k8s-controller/api"
 	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
 	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer/dedicatedip"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer/kubernetes"
+	"github.com/skygeario/k8s-controller/pkg/metrics"
+	"github.com/skygeario/k8s-controller/pkg/tracing"
 	"github.com/skygeario/k8s-controller/pkg/util/condition"
 	"github.com/skygeario/k8s-controller/pkg/util/deadline"
+	"github.com/skygeario/k8s-controller/pkg/util/dnsname"
 	"github.com/skygeario/k8s-controller/pkg/util/finalizer"
 	"github.com/skygeario/k8s-controller/pkg/util/slice"
 )
@@ -40,6 +55,12 @@ import (
 type LoadBalancer interface {
 	Provision(ctx context.Context, domain *domainv1beta1.CustomDomain) (providerType string, result *loadbalancer.ProvisionResult, err error)
 	Release(ctx context.Context, domain *domainv1beta1.CustomDomain) (ok bool, err error)
+	IsSource(kind string, obj metav1.Object) bool
+}
+
+type DNSProvider interface {
+	EnsureRecords(ctx context.Context, domain *domainv1beta1.CustomDomain) (ok bool, err error)
+	DeleteRecords(ctx context.Context, domain *domainv1beta1.CustomDomain) (ok bool, err error)
 }
 
 // CustomDomainReconciler reconciles a CustomDomain object
@@ -49,28 +70,84 @@ type CustomDomainReconciler struct {
 	Scheme                   *runtime.Scheme
 	Now                      func() metav1.Time
 	LoadBalancer             LoadBalancer
+	DNSProvider              DNSProvider
 	VerificationKeyGenerator func() string
+	Recorder                 record.EventRecorder
+	Audit                    *AuditLogger
+	// TakeoverConfirmations is the number of consecutive failed
+	// verifications of the owner registration required, before ownership
+	// is revoked while the owner still exists. Zero disables the check.
+	TakeoverConfirmations int
+	// TakeoverWindow is the duration that the owner registration must stay
+	// unverified, and a claimant of another app must stay verified, before
+	// ownership is revoked while the owner still exists.
+	TakeoverWindow time.Duration
+	// MaxConcurrentReconciles is the maximum number of concurrent
+	// reconciliations, defaults to 1.
+	MaxConcurrentReconciles int
+	// WatchDNSProviderConfigs enables reconciling all domains when
+	// DNSProviderConfig resources change.
+	WatchDNSProviderConfigs bool
+	// WatchLoadBalancerEndpoints enables reconciling all domains when
+	// LoadBalancerEndpoint resources change.
+	WatchLoadBalancerEndpoints bool
+	// WatchIPPools enables reconciling all domains when IPPool resources
+	// change, e.g. addresses are added to exhausted pools.
+	WatchIPPools bool
 }
 
 // +kubebuilder:rbac:groups=domain.skygear.io,resources=customdomains,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=domain.skygear.io,resources=dnsproviderconfigs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=domain.skygear.io,resources=loadbalancerendpoints,verbs=get;list;watch
+// +kubebuilder:rbac:groups=domain.skygear.io,resources=ippools,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=domain.skygear.io,resources=ippools/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=domain.skygear.io,resources=customdomains/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=externaldns.k8s.io,resources=dnsendpoints,verbs=get;list;watch;create;update;patch;delete
 
-func (r *CustomDomainReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
-	ctx := context.Background()
-	_ = r.Log.WithValues("customdomain", req.NamespacedName)
+func (r *CustomDomainReconciler) Reconcile(req ctrl.Request) (result ctrl.Result, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ReconcileTimeout)
+	defer cancel()
+	ctx, span := tracing.Start(ctx, "Reconcile CustomDomain", tracing.SpanKindInternal,
+		tracing.String("k8s.namespace.name", req.Namespace),
+		tracing.String("k8s.resource.name", req.Name),
+		tracing.String("domain", dnsname.DomainName(req.Name)),
+	)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+	log := newReconcileLogger(r.Log, dnsname.DomainName(req.Name), "customdomain", req.NamespacedName)
+	ctx = withLogger(ctx, log)
 
 	var d domainv1beta1.CustomDomain
 	if err := r.Get(ctx, req.NamespacedName, &d); err != nil {
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
+	log.V(1).Info("reconciling domain", "generation", d.Generation, "deleting", d.DeletionTimestamp != nil)
+	oldStatus := d.Status.DeepCopy()
+	d.Status.Zone = dnsname.Zone(dnsname.DomainName(d.Name))
 
-	if err := r.validateRegistrations(ctx, &d); err != nil {
+	if isPaused(&d) {
+		conditions := pausedConditions(d.Status.Conditions, string(domainv1beta1.DomainPaused))
+		return ctrl.Result{}, r.updateStatus(ctx, &d, oldStatus, conditions)
+	}
+
+	heartbeatExpireTime, err := r.validateRegistrations(ctx, &d)
+	if err != nil {
 		return ctrl.Result{}, err
 	}
 
-	var conditions []api.Condition
+	conditions := []api.Condition{{
+		Type:   string(domainv1beta1.DomainPaused),
+		Status: metav1.ConditionFalse,
+	}}
 	doFinalize := false
 	var requeueDeadline deadline.Deadline
+	if heartbeatExpireTime != nil {
+		requeueDeadline.Set(*heartbeatExpireTime)
+	}
 
 	if d.DeletionTimestamp == nil {
 		finalizerAdded, err := finalizer.Ensure(r, ctx, &d, domain.DomainFinalizer)
@@ -80,15 +157,26 @@ func (r *CustomDomainReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error
 		if finalizerAdded {
 			return ctrl.Result{Requeue: true}, nil
 		}
-
-		if len(d.Spec.Registrations) == 0 {
-			// Release custom domain when no registrations
-			err = r.Delete(ctx, &d)
+		if r.DNSProvider != nil {
+			finalizerAdded, err := finalizer.Ensure(r, ctx, &d, domain.DNSRecordsFinalizer)
 			if err != nil {
 				return ctrl.Result{}, err
 			}
+			if finalizerAdded {
+				return ctrl.Result{Requeue: true}, nil
+			}
+		}
+
+		deleted, expireTime, err := r.collectIfOrphaned(ctx, &d)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if deleted {
 			return ctrl.Result{Requeue: true}, nil
 		}
+		if expireTime != nil {
+			requeueDeadline.Set(*expireTime)
+		}
 
 		provisioned, err := r.provisionLoadBalancer(ctx, &d)
 		if err != nil {
@@ -106,89 +194,323 @@ func (r *CustomDomainReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error
 				requeueDeadline.Set(r.Now().Add(PollInterval))
 			}
 		}
+		if d.DedicatedIP() {
+			if errors.Is(err, dedicatedip.ErrPoolExhausted) {
+				conditions = append(conditions, api.Condition{
+					Type:    string(domainv1beta1.DomainDedicatedIPAllocated),
+					Status:  metav1.ConditionFalse,
+					Reason:  domainv1beta1.ReasonIPPoolExhausted,
+					Message: err.Error(),
+				})
+			} else if err != nil {
+				conditions = append(conditions, api.Condition{
+					Type:    string(domainv1beta1.DomainDedicatedIPAllocated),
+					Status:  metav1.ConditionUnknown,
+					Message: err.Error(),
+				})
+			} else {
+				conditions = append(conditions, api.Condition{
+					Type:   string(domainv1beta1.DomainDedicatedIPAllocated),
+					Status: condition.ToStatus(provisioned),
+				})
+			}
+		}
+		if d.Status.LoadBalancer != nil && d.Status.LoadBalancer.AliasTarget != nil {
+			// Resolved addresses of alias target may change.
+			requeueDeadline.Set(r.Now().Add(DNSCheckInterval))
+		}
+
+		if r.DNSProvider != nil && provisioned {
+			ensured, err := r.DNSProvider.EnsureRecords(ctx, &d)
+			if err != nil {
+				conditions = append(conditions, api.Condition{
+					Type:    string(domainv1beta1.DomainDNSRecordsProvisioned),
+					Status:  metav1.ConditionUnknown,
+					Message: err.Error(),
+				})
+			} else {
+				conditions = append(conditions, api.Condition{
+					Type:   string(domainv1beta1.DomainDNSRecordsProvisioned),
+					Status: condition.ToStatus(ensured),
+				})
+			}
+			if !ensured {
+				requeueDeadline.Set(r.Now().Add(PollInterval))
+			}
+		}
 
-		err = r.processRegistrations(ctx, &d)
+		takeoverTime, err := r.processRegistrations(ctx, &d)
 		if err != nil {
 			return ctrl.Result{}, err
 		}
+		if takeoverTime != nil {
+			requeueDeadline.Set(*takeoverTime)
+		}
+		if q := d.Status.QuarantineUntil; q != nil {
+			if r.Now().Time.Before(q.Time) {
+				requeueDeadline.Set(q.Time)
+			} else {
+				d.Status.QuarantineUntil = nil
+			}
+		}
+
+		// Rotate key after other spec patches, so that rotation time in
+		// status is not overwritten.
+		rotateTime, err := r.rotateVerificationKeyIfNeeded(ctx, &d)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if rotateTime != nil {
+			requeueDeadline.Set(*rotateTime)
+		}
+
+		d.Status.PrimaryRegistration = d.Spec.OwnerRef
+		if err := r.summarizeRegistrations(ctx, &d); err != nil {
+			return ctrl.Result{}, err
+		}
 
 	} else {
 		doFinalize = true
 
-		released, err := r.releaseLoadBalancer(ctx, &d)
-		if err != nil {
-			doFinalize = false
-			conditions = append(conditions, api.Condition{
-				Type:    string(domainv1beta1.DomainLoadBalancerProvisioned),
-				Status:  metav1.ConditionUnknown,
-				Message: err.Error(),
-			})
-		} else {
-			doFinalize = doFinalize && released
-			conditions = append(conditions, api.Condition{
-				Type:   string(domainv1beta1.DomainLoadBalancerProvisioned),
-				Status: condition.ToStatus(!released),
-			})
+		if slice.ContainsString(d.Finalizers, domain.DNSRecordsFinalizer) {
+			deleted, err := r.deleteDNSRecords(ctx, &d)
+			if err != nil {
+				conditions = append(conditions, api.Condition{
+					Type:    string(domainv1beta1.DomainDNSRecordsProvisioned),
+					Status:  metav1.ConditionUnknown,
+					Message: err.Error(),
+				})
+			} else {
+				conditions = append(conditions, api.Condition{
+					Type:   string(domainv1beta1.DomainDNSRecordsProvisioned),
+					Status: condition.ToStatus(!deleted),
+				})
+			}
+			if deleted {
+				if err := finalizer.Remove(r, ctx, &d, domain.DNSRecordsFinalizer); err != nil {
+					return ctrl.Result{}, err
+				}
+				r.Recorder.Event(&d, corev1.EventTypeNormal, EventDNSRecordsDeleted, "Deleted domain DNS records")
+			} else {
+				// Delete DNS records before releasing load balancer, so that
+				// domain never points to released targets.
+				doFinalize = false
+				requeueDeadline.Set(r.Now().Add(PollInterval))
+			}
 		}
-		if !released {
-			requeueDeadline.Set(r.Now().Add(PollInterval))
+
+		if doFinalize {
+			released, err := r.releaseLoadBalancer(ctx, &d)
+			if err != nil {
+				doFinalize = false
+				conditions = append(conditions, api.Condition{
+					Type:    string(domainv1beta1.DomainLoadBalancerProvisioned),
+					Status:  metav1.ConditionUnknown,
+					Message: err.Error(),
+				})
+			} else {
+				doFinalize = doFinalize && released
+				conditions = append(conditions, api.Condition{
+					Type:   string(domainv1beta1.DomainLoadBalancerProvisioned),
+					Status: condition.ToStatus(!released),
+				})
+			}
+			if !released {
+				requeueDeadline.Set(r.Now().Add(PollInterval))
+			}
 		}
 	}
 
-	condition.MergeFrom(conditions, d.Status.Conditions)
-	d.Status.Conditions = conditions
-	if err := r.Status().Update(ctx, &d); err != nil {
+	if err := r.updateStatus(ctx, &d, oldStatus, conditions); err != nil {
 		return ctrl.Result{}, err
 	}
 
 	if doFinalize {
-		err := finalizer.Remove(r, ctx, &d, domain.DomainFinalizer)
-		return ctrl.Result{}, err
+		if err := finalizer.Remove(r, ctx, &d, domain.DomainFinalizer); err != nil {
+			return ctrl.Result{}, err
+		}
+		r.Recorder.Event(&d, corev1.EventTypeNormal, EventDomainReleased, "Released domain load balancer")
+		return ctrl.Result{}, nil
 	}
 
+	if t := resyncTime(r.Now().Time); t != nil {
+		requeueDeadline.Set(*t)
+	}
 	return ctrl.Result{RequeueAfter: requeueDeadline.Duration(r.Now().Time)}, nil
 }
 
+// updateStatus writes status of the domain with the conditions. The update is
+// skipped if status is unchanged from oldStatus.
+func (r *CustomDomainReconciler) updateStatus(ctx context.Context, d *domainv1beta1.CustomDomain, oldStatus *domainv1beta1.CustomDomainStatus, conditions []api.Condition) error {
+	condition.MergeFrom(conditions, d.Status.Conditions)
+	condition.SetObservedGeneration(conditions, d.Generation)
+	d.Status.Conditions = conditions
+	d.Status.ObservedGeneration = d.Generation
+	d.Status.Phase = domainPhase(d.DeletionTimestamp != nil, conditions)
+	if equality.Semantic.DeepEqual(&d.Status, oldStatus) {
+		metrics.StatusUpdatesSkipped.WithLabelValues("customdomain").Inc()
+		return nil
+	}
+	loggerFrom(ctx, r.Log).V(1).Info("updating status", "phase", d.Status.Phase)
+	ctx, span := tracing.Start(ctx, "UpdateStatus CustomDomain", tracing.SpanKindClient)
+	defer span.End()
+	err := r.Status().Update(ctx, d)
+	span.RecordError(err)
+	return err
+}
+
+// patchSpec patches the domain, keeping the status computed in this
+// reconciliation, since the patched object returned by the API server
+// carries the stored status.
+func (r *CustomDomainReconciler) patchSpec(ctx context.Context, d *domainv1beta1.CustomDomain, patch client.Patch) error {
+	status := d.Status.DeepCopy()
+	if err := r.Patch(ctx, d, patch); err != nil {
+		return err
+	}
+	d.Status = *status
+	return nil
+}
+
 func (r *CustomDomainReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&domainv1beta1.CustomDomain{}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
 		Owns(&domainv1beta1.CustomDomainRegistration{}).
-		Complete(r)
+		Watches(
+			&source.Kind{Type: &corev1.Service{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: r.mapLoadBalancerSource(kubernetes.KindService)},
+		).
+		Watches(
+			&source.Kind{Type: &networkingv1beta1.Ingress{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: r.mapLoadBalancerSource(kubernetes.KindIngress)},
+		)
+	if r.WatchDNSProviderConfigs {
+		b = b.Watches(
+			&source.Kind{Type: &domainv1beta1.DNSProviderConfig{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.mapAllDomains)},
+		)
+	}
+	if r.WatchLoadBalancerEndpoints {
+		b = b.Watches(
+			&source.Kind{Type: &domainv1beta1.LoadBalancerEndpoint{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.mapAllDomains)},
+		)
+	}
+	if r.WatchIPPools {
+		b = b.Watches(
+			&source.Kind{Type: &domainv1beta1.IPPool{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.mapAllDomains)},
+		)
+	}
+	return b.Complete(r)
+}
+
+func (r *CustomDomainReconciler) mapLoadBalancerSource(kind string) handler.ToRequestsFunc {
+	return func(o handler.MapObject) []ctrl.Request {
+		if !r.LoadBalancer.IsSource(kind, o.Meta) {
+			return nil
+		}
+		return r.mapAllDomains(o)
+	}
 }
 
-func (r *CustomDomainReconciler) validateRegistrations(ctx context.Context, d *domainv1beta1.CustomDomain) error {
-	n := 0
+func (r *CustomDomainReconciler) mapAllDomains(o handler.MapObject) []ctrl.Request {
+	var list domainv1beta1.CustomDomainList
+	if err := r.List(context.Background(), &list, client.InNamespace(DomainNamespace)); err != nil {
+		r.Log.Error(err, "cannot list custom domains")
+		return nil
+	}
+	var reqs []ctrl.Request
+	for _, d := range list.Items {
+		reqs = append(reqs, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: d.Namespace, Name: d.Name}})
+	}
+	return reqs
+}
+
+// validateRegistrations removes references to registrations that are gone.
+// Registrations in other clusters are removed by controller of the workload
+// cluster, or when their heartbeat expires if the workload cluster is gone;
+// the earliest expiry of heartbeat is returned.
+func (r *CustomDomainReconciler) validateRegistrations(ctx context.Context, d *domainv1beta1.CustomDomain) (*time.Time, error) {
+	now := r.Now()
+	var expireTime deadline.Deadline
+	missing := map[types.UID]bool{}
+	noHeartbeat := map[types.UID]bool{}
 	for _, ref := range d.Spec.Registrations {
+		if ref.IsRemote() {
+			if ref.LastHeartbeatTime == nil {
+				// Referenced before heartbeats are sent; expires unless
+				// refreshed by its cluster from now on.
+				noHeartbeat[ref.UID] = true
+				expireTime.Set(now.Add(RemoteRegistrationTTL))
+			} else if expireAt, alive := remoteRegistrationExpireTime(&ref, now); alive {
+				expireTime.Set(expireAt)
+			} else {
+				missing[ref.UID] = true
+			}
+			continue
+		}
 		var reg domainv1beta1.CustomDomainRegistration
 		err := r.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, &reg)
 		if apierrors.IsNotFound(err) {
+			missing[ref.UID] = true
 			continue
 		}
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		d.Spec.Registrations[n] = ref
-		n++
-
-		if !slice.ContainsOwnerReference(reg.OwnerReferences, d) {
+		// Owner references across namespaces are not supported, so
+		// registrations are not owned by namespaced domains in other
+		// namespaces.
+		if (d.Namespace == "" || d.Namespace == reg.Namespace) && !slice.ContainsOwnerReference(reg.OwnerReferences, d) {
 			patch := client.MergeFrom(reg.DeepCopy())
 			if err := ctrl.SetControllerReference(d, &reg, r.Scheme); err != nil {
-				return err
+				return nil, err
 			}
 			if err := r.Patch(ctx, &reg, patch); err != nil {
-				return err
+				return nil, err
 			}
 		}
 	}
-	if n != len(d.Spec.Registrations) {
-		patch := client.MergeFrom(d.DeepCopy())
-		d.Spec.Registrations = d.Spec.Registrations[:n]
-		if err := r.Patch(ctx, d, patch); err != nil {
-			return err
+	if len(missing) > 0 || len(noHeartbeat) > 0 {
+		status := d.Status.DeepCopy()
+		defer func() { d.Status = *status }()
+		_, err := updateDomainRegistrations(ctx, r.Client, registrationOpCleanup, d, func(refs []domainv1beta1.CustomDomainRegistrationReference) ([]domainv1beta1.CustomDomainRegistrationReference, bool) {
+			var valid []domainv1beta1.CustomDomainRegistrationReference
+			changed := false
+			for _, ref := range refs {
+				if missing[ref.UID] {
+					changed = true
+					continue
+				}
+				if noHeartbeat[ref.UID] && ref.LastHeartbeatTime == nil {
+					ref.LastHeartbeatTime = &now
+					changed = true
+				}
+				valid = append(valid, ref)
+			}
+			return valid, changed
+		})
+		if err != nil {
+			return nil, err
 		}
 	}
-	return nil
+	if wait := expireTime.Duration(now.Time); wait > 0 {
+		t := now.Add(wait)
+		return &t, nil
+	}
+	return nil, nil
+}
+
+// remoteRegistrationExpireTime returns the time that heartbeat of the
+// registration in other cluster expires, and whether it is still alive.
+func remoteRegistrationExpireTime(ref *domainv1beta1.CustomDomainRegistrationReference, now metav1.Time) (time.Time, bool) {
+	if ref.LastHeartbeatTime == nil {
+		return time.Time{}, false
+	}
+	expireAt := ref.LastHeartbeatTime.Add(RemoteRegistrationTTL)
+	return expireAt, now.Time.Before(expireAt)
 }
 
 func (r *CustomDomainReconciler) provisionLoadBalancer(ctx context.Context, d *domainv1beta1.CustomDomain) (bool, error) {
@@ -199,7 +521,7 @@ func (r *CustomDomainReconciler) provisionLoadBalancer(ctx context.Context, d *d
 	if d.Spec.LoadBalancerProvider == nil {
 		patch := client.MergeFrom(d.DeepCopy())
 		d.Spec.LoadBalancerProvider = &providerType
-		if err := r.Patch(ctx, d, patch); err != nil {
+		if err := r.patchSpec(ctx, d, patch); err != nil {
 			return false, err
 		}
 	}
@@ -218,79 +540,516 @@ func (r *CustomDomainReconciler) provisionLoadBalancer(ctx context.Context, d *d
 			}
 		}
 		loadBalancer.DNSRecords = dnsRecords
+		if result.AliasTarget != "" {
+			loadBalancer.AliasTarget = &result.AliasTarget
+		}
 	}
 
 	d.Status.LoadBalancer = loadBalancer
+	loggerFrom(ctx, r.Log).V(1).Info("provisioned load balancer", "provider", providerType, "ready", result != nil)
 
 	return result != nil, nil
 }
 
+func (r *CustomDomainReconciler) deleteDNSRecords(ctx context.Context, d *domainv1beta1.CustomDomain) (bool, error) {
+	if r.DNSProvider == nil {
+		// DNS provider is no longer configured, records cannot be deleted.
+		loggerFrom(ctx, r.Log).Info("DNS provider is not configured, skipping deletion of DNS records")
+		return true, nil
+	}
+	return r.DNSProvider.DeleteRecords(ctx, d)
+}
+
 func (r *CustomDomainReconciler) releaseLoadBalancer(ctx context.Context, d *domainv1beta1.CustomDomain) (bool, error) {
 	return r.LoadBalancer.Release(ctx, d)
 }
 
-func (r *CustomDomainReconciler) processRegistrations(ctx context.Context, d *domainv1beta1.CustomDomain) error {
+// processRegistrations grants ownership of the domain to a verified
+// registration, and revokes it from owner registration that is gone or no
+// longer verified. It returns the time that pending takeover of ownership
+// should be checked again, if any.
+func (r *CustomDomainReconciler) processRegistrations(ctx context.Context, d *domainv1beta1.CustomDomain) (*time.Time, error) {
 	if d.Spec.VerificationKey == nil {
 		patch := client.MergeFrom(d.DeepCopy())
 		d.Spec.VerificationKey = pointer.StringPtr(r.VerificationKeyGenerator())
-		if err := r.Patch(ctx, d, patch); err != nil {
-			return err
+		if err := r.patchSpec(ctx, d, patch); err != nil {
+			return nil, err
+		}
+	}
+
+	if d.Spec.OwnerApp != nil {
+		ownerOk, err := r.checkOwner(ctx, d)
+		if err != nil {
+			return nil, err
+		}
+		if !ownerOk {
+			// Owner is gone or no longer verified, transfer ownership to
+			// next verified registration.
+			released, err := r.isOwnerReleased(ctx, d)
+			if err != nil {
+				return nil, err
+			}
+			var claimant *domainv1beta1.CustomDomainRegistrationReference
+			if !released {
+				var checkTime *time.Time
+				var confirmed bool
+				checkTime, claimant, confirmed, err = r.checkTakeover(ctx, d)
+				if err != nil {
+					return nil, err
+				}
+				if !confirmed {
+					return checkTime, nil
+				}
+			}
+			oldOwner := d.Spec.OwnerRef
+			oldOwnerApp := *d.Spec.OwnerApp
+			oldOwnerCluster := d.Spec.OwnerCluster
+			patch := client.MergeFrom(d.DeepCopy())
+			d.Spec.OwnerApp = nil
+			d.Spec.OwnerRef = nil
+			d.Spec.OwnerCluster = ""
+			if claimant != nil {
+				// Ownership is granted to the confirmed claimant only,
+				// rather than any registration verified afterwards.
+				d.Spec.OwnerApp = pointer.StringPtr(claimant.Namespace)
+				d.Spec.OwnerRef = &claimant.ObjectReference
+				d.Spec.OwnerCluster = claimant.Cluster
+			}
+			if err := r.patchSpec(ctx, d, patch); err != nil {
+				return nil, err
+			}
+			d.Status.Takeover = nil
+			if released {
+				now := r.Now()
+				d.Status.ReleasedAt = &now
+				d.Status.ReleasedApp = oldOwnerApp
+				d.Status.ReleasedCluster = oldOwnerCluster
+				d.Status.QuarantineUntil = nil
+				if ReleaseQuarantinePeriod > 0 {
+					until := metav1.NewTime(now.Add(ReleaseQuarantinePeriod))
+					d.Status.QuarantineUntil = &until
+				}
+			}
+			if oldOwner != nil {
+				r.Audit.Record(d, AuditOwnershipRevoked, *oldOwner, "owner registration is gone or no longer verified")
+			}
+			if claimant != nil {
+				r.Audit.Record(d, AuditOwnershipGranted, claimant.ObjectReference, "granted ownership to verified claimant after takeover window")
+			}
+		} else {
+			if err := r.transferOwnershipIfRequested(ctx, d); err != nil {
+				return nil, err
+			}
+			d.Status.Takeover = nil
 		}
 	}
 
 	if d.Spec.OwnerApp == nil {
-		appToAccept := ""
+		// Released domain can only be re-claimed by its previous owner app
+		// during quarantine.
+		quarantined := d.Status.QuarantineUntil != nil && r.Now().Time.Before(d.Status.QuarantineUntil.Time)
+		var owner *domainv1beta1.CustomDomainRegistrationReference
 		for _, ref := range d.Spec.Registrations {
-			var reg domainv1beta1.CustomDomainRegistration
-			if err := r.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, &reg); err != nil {
-				return err
+			if !ref.IsPrimary() {
+				continue
 			}
-
-			cond := condition.Lookup(reg.Status.Conditions, string(domainv1beta1.RegistrationVerified))
-			if cond != nil && cond.Status == metav1.ConditionTrue {
-				appToAccept = reg.Namespace
+			if quarantined && (ref.Namespace != d.Status.ReleasedApp || ref.Cluster != d.Status.ReleasedCluster) {
+				continue
+			}
+			active, verified, err := r.registrationState(ctx, &ref)
+			if err != nil {
+				return nil, err
+			}
+			if active && verified {
+				ref := ref
+				owner = &ref
 				break
 			}
 		}
 
-		if appToAccept != "" {
+		if owner != nil {
 			patch := client.MergeFrom(d.DeepCopy())
-			d.Spec.OwnerApp = &appToAccept
-			if err := r.Patch(ctx, d, patch); err != nil {
-				return err
+			d.Spec.OwnerApp = pointer.StringPtr(owner.Namespace)
+			d.Spec.OwnerRef = &owner.ObjectReference
+			d.Spec.OwnerCluster = owner.Cluster
+			if err := r.patchSpec(ctx, d, patch); err != nil {
+				return nil, err
 			}
+			r.Audit.Record(d, AuditOwnershipGranted, owner.ObjectReference, "granted ownership to verified registration")
 		}
-	} else {
-		ownerOk := false
-		for _, ref := range d.Spec.Registrations {
-			if ref.Namespace != *d.Spec.OwnerApp {
+	}
+
+	return nil, nil
+}
+
+// checkTakeover returns whether revoking ownership from the unverified
+// owner registration is confirmed, and the claimant that ownership is
+// transferred to, if any. Takeover is confirmed when the owner has failed
+// TakeoverConfirmations consecutive conclusive verifications, i.e. the
+// verification record is observed missing or mismatched, and it has been
+// unverified, and a claimant of another app verified, for TakeoverWindow.
+// Inconclusive failures, e.g. lookup timeouts, do not count towards
+// confirmation. Otherwise, the pending takeover is recorded in status, and
+// the time to check again is returned if known.
+func (r *CustomDomainReconciler) checkTakeover(ctx context.Context, d *domainv1beta1.CustomDomain) (checkTime *time.Time, claimant *domainv1beta1.CustomDomainRegistrationReference, confirmed bool, err error) {
+	if r.TakeoverConfirmations <= 0 && r.TakeoverWindow <= 0 {
+		return nil, nil, true, nil
+	}
+	if d.Spec.OwnerCluster != "" {
+		// Registrations in other clusters sync only verification state.
+		return nil, nil, true, nil
+	}
+
+	var owner domainv1beta1.CustomDomainRegistration
+	err = r.Get(ctx, types.NamespacedName{Namespace: d.Spec.OwnerRef.Namespace, Name: d.Spec.OwnerRef.Name}, &owner)
+	if apierrors.IsNotFound(err) {
+		return nil, nil, true, nil
+	} else if err != nil {
+		return nil, nil, false, err
+	}
+	ownerCond := condition.Lookup(owner.Status.Conditions, string(domainv1beta1.RegistrationVerified))
+	if ownerCond == nil || ownerCond.Status == metav1.ConditionTrue {
+		// Owner is not revoked for failed verification.
+		return nil, nil, true, nil
+	}
+
+	takeover := &domainv1beta1.CustomDomainTakeoverStatus{
+		OwnerUnverifiedSince: ownerCond.LastTransitionTime,
+		OwnerFailureCount:    owner.Status.ConclusiveFailureCount,
+	}
+	for _, ref := range d.Spec.Registrations {
+		if !ref.IsPrimary() || ref.Namespace == owner.Namespace || ref.IsRemote() {
+			continue
+		}
+		var reg domainv1beta1.CustomDomainRegistration
+		if err := r.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, &reg); err != nil {
+			if apierrors.IsNotFound(err) {
 				continue
 			}
-			var reg domainv1beta1.CustomDomainRegistration
-			if err := r.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, &reg); err != nil {
-				if !apierrors.IsNotFound(err) {
-					return err
-				}
-				break
-			}
+			return nil, nil, false, err
+		}
+		cond := condition.Lookup(reg.Status.Conditions, string(domainv1beta1.RegistrationVerified))
+		if reg.DeletionTimestamp != nil || cond == nil || cond.Status != metav1.ConditionTrue {
+			continue
+		}
+		ref := ref
+		verifiedSince := cond.LastTransitionTime
+		claimant = &ref
+		takeover.Claimant = &ref.ObjectReference
+		takeover.ClaimantVerifiedSince = &verifiedSince
+		break
+	}
+	d.Status.Takeover = takeover
 
-			cond := condition.Lookup(reg.Status.Conditions, string(domainv1beta1.RegistrationVerified))
-			if cond == nil || cond.Status != metav1.ConditionTrue {
-				break
-			}
+	now := r.Now()
+	var recheck deadline.Deadline
+	windowPassed := func(since metav1.Time) bool {
+		until := since.Add(r.TakeoverWindow)
+		if now.Time.Before(until) {
+			recheck.Set(until)
+			return false
+		}
+		return true
+	}
+	confirmed = takeover.OwnerFailureCount >= r.TakeoverConfirmations
+	confirmed = windowPassed(takeover.OwnerUnverifiedSince) && confirmed
+	// Owner is kept until another app claims the domain, so that owner
+	// failing verification transiently does not release the domain.
+	if takeover.ClaimantVerifiedSince == nil {
+		confirmed = false
+	} else {
+		confirmed = windowPassed(*takeover.ClaimantVerifiedSince) && confirmed
+	}
+	if confirmed {
+		return nil, claimant, true, nil
+	}
+	if wait := recheck.Duration(now.Time); wait > 0 {
+		t := now.Add(wait)
+		checkTime = &t
+	}
+	// Failed verifications of owner, and verification of claimants, are
+	// observed by owning the registrations.
+	return checkTime, nil, false, nil
+}
 
-			ownerOk = true
-			break
+// isOwnerReleased returns whether the owner registration of the domain is
+// deleted, rather than no longer verified.
+func (r *CustomDomainReconciler) isOwnerReleased(ctx context.Context, d *domainv1beta1.CustomDomain) (bool, error) {
+	if d.Spec.OwnerRef == nil {
+		return false, nil
+	}
+	for _, ref := range d.Spec.Registrations {
+		if ref.UID != d.Spec.OwnerRef.UID {
+			continue
 		}
+		active, _, err := r.registrationState(ctx, &ref)
+		if err != nil {
+			return false, err
+		}
+		return !active, nil
+	}
+	return true, nil
+}
 
-		if !ownerOk {
+// checkOwner returns whether the owner app still has an active and verified
+// primary registration of the domain. Apps are identified by cluster and
+// namespace; if the owner app re-created its registration, the owner
+// reference is updated to the new registration.
+func (r *CustomDomainReconciler) checkOwner(ctx context.Context, d *domainv1beta1.CustomDomain) (bool, error) {
+	for _, ref := range d.Spec.Registrations {
+		// Owner granted before owner cluster is recorded is matched by UID.
+		legacyOwner := d.Spec.OwnerCluster == "" && d.Spec.OwnerRef != nil && d.Spec.OwnerRef.UID == ref.UID
+		if !d.IsOwnedBy(ref.Cluster, ref.Namespace) && !legacyOwner {
+			continue
+		}
+		if !ref.IsPrimary() {
+			return false, nil
+		}
+		active, verified, err := r.registrationState(ctx, &ref)
+		if err != nil {
+			return false, err
+		}
+		if !active || !verified {
+			return false, nil
+		}
+		if d.Spec.OwnerRef == nil || d.Spec.OwnerRef.UID != ref.UID || d.Spec.OwnerCluster != ref.Cluster {
+			ref := ref
 			patch := client.MergeFrom(d.DeepCopy())
-			d.Spec.OwnerApp = nil
-			if err := r.Patch(ctx, d, patch); err != nil {
+			d.Spec.OwnerRef = &ref.ObjectReference
+			d.Spec.OwnerCluster = ref.Cluster
+			if err := r.patchSpec(ctx, d, patch); err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// registrationState returns whether the referenced registration is active,
+// i.e. exists and is not being deleted, and whether it is verified.
+// Registrations in other clusters are active while their heartbeat is not
+// expired, and their verification state is synced to the reference.
+func (r *CustomDomainReconciler) registrationState(ctx context.Context, ref *domainv1beta1.CustomDomainRegistrationReference) (active bool, verified bool, err error) {
+	if ref.IsRemote() {
+		if _, alive := remoteRegistrationExpireTime(ref, r.Now()); !alive {
+			return false, false, nil
+		}
+		return true, ref.Verified, nil
+	}
+
+	var reg domainv1beta1.CustomDomainRegistration
+	if err := r.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, &reg); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, false, nil
+		}
+		return false, false, err
+	}
+	if reg.DeletionTimestamp != nil {
+		return false, false, nil
+	}
+	return true, condition.IsTrue(reg.Status.Conditions, string(domainv1beta1.RegistrationVerified)), nil
+}
+
+// summarizeRegistrations summarizes the registrations and published DNS
+// targets of the domain in status, so that operators can audit the domain
+// from a single object.
+func (r *CustomDomainReconciler) summarizeRegistrations(ctx context.Context, d *domainv1beta1.CustomDomain) error {
+	summary := &domainv1beta1.CustomDomainSummary{}
+	for _, ref := range d.Spec.Registrations {
+		verified := ref.Verified
+		if !ref.IsRemote() {
+			var reg domainv1beta1.CustomDomainRegistration
+			err := r.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, &reg)
+			if apierrors.IsNotFound(err) {
+				continue
+			} else if err != nil {
 				return err
 			}
+			if reg.DeletionTimestamp != nil {
+				continue
+			}
+			verified = condition.IsTrue(reg.Status.Conditions, string(domainv1beta1.RegistrationVerified))
+			if t := reg.Status.LastVerificationTime; t != nil &&
+				(summary.LastVerificationTime == nil || summary.LastVerificationTime.Before(t)) {
+				summary.LastVerificationTime = t.DeepCopy()
+			}
+		}
+
+		summary.Registrations++
+		if verified {
+			summary.VerifiedRegistrations++
+		} else {
+			summary.PendingRegistrations++
 		}
 	}
 
+	if owner := d.Spec.OwnerRef; owner != nil {
+		summary.Owner = fmt.Sprintf("%s/%s", owner.Namespace, owner.Name)
+	}
+	if lb := d.Status.LoadBalancer; lb != nil {
+		for _, record := range lb.DNSRecords {
+			summary.DNSTargets = append(summary.DNSTargets, record.Value)
+		}
+	}
+	d.Status.Summary = summary
 	return nil
 }
+
+// transferOwnershipIfRequested transfers ownership of the domain to the
+// registration requested by the owner registration, once the target is
+// verified. Owner is replaced in a single patch, so that the domain is never
+// unowned during the transfer.
+func (r *CustomDomainReconciler) transferOwnershipIfRequested(ctx context.Context, d *domainv1beta1.CustomDomain) error {
+	if d.Spec.OwnerRef == nil || d.Spec.OwnerCluster != "" {
+		// Transfer is requested in registration of this cluster only.
+		return nil
+	}
+
+	var owner domainv1beta1.CustomDomainRegistration
+	if err := r.Get(ctx, types.NamespacedName{Namespace: d.Spec.OwnerRef.Namespace, Name: d.Spec.OwnerRef.Name}, &owner); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	namespace, name, ok := owner.TransferTarget()
+	if !ok {
+		return nil
+	}
+
+	for _, ref := range d.Spec.Registrations {
+		if ref.Namespace != namespace || ref.Name != name || !ref.IsPrimary() || ref.IsRemote() {
+			continue
+		}
+		var target domainv1beta1.CustomDomainRegistration
+		if err := r.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, &target); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		if target.DeletionTimestamp != nil ||
+			!condition.IsTrue(target.Status.Conditions, string(domainv1beta1.RegistrationVerified)) {
+			return nil
+		}
+
+		ref := ref
+		patch := client.MergeFrom(d.DeepCopy())
+		d.Spec.OwnerApp = pointer.StringPtr(ref.Namespace)
+		d.Spec.OwnerRef = &ref.ObjectReference
+		if err := r.patchSpec(ctx, d, patch); err != nil {
+			return err
+		}
+		r.Recorder.Eventf(d, corev1.EventTypeNormal, EventOwnershipTransferred,
+			"Transferred ownership from %s/%s to %s/%s", owner.Namespace, owner.Name, ref.Namespace, ref.Name)
+		r.Audit.Record(d, AuditOwnershipTransferred, registrationRef(&owner),
+			fmt.Sprintf("transferred ownership to %s/%s (uid %s)", ref.Namespace, ref.Name, ref.UID))
+		return nil
+	}
+	return nil
+}
+
+// collectIfOrphaned deletes the domain if it has no registrations for
+// OrphanedDomainTTL, unless it is annotated to be retained.
+func (r *CustomDomainReconciler) collectIfOrphaned(ctx context.Context, d *domainv1beta1.CustomDomain) (deleted bool, expireTime *time.Time, err error) {
+	if len(d.Spec.Registrations) != 0 {
+		d.Status.OrphanedAt = nil
+		return false, nil, nil
+	}
+
+	now := r.Now()
+	if d.Status.OrphanedAt == nil {
+		d.Status.OrphanedAt = &now
+	}
+	if d.Annotations[domain.DomainRetainAnnotation] == "true" {
+		return false, nil, nil
+	}
+	if d.Spec.OwnerApp != nil {
+		// Ownership is revoked, and release of domain is recorded, before
+		// the domain is collected.
+		return false, nil, nil
+	}
+
+	expireAt := d.Status.OrphanedAt.Add(OrphanedDomainTTL)
+	if q := d.Status.QuarantineUntil; q != nil && q.Time.After(expireAt) {
+		// Quarantine is kept with the domain until it ends.
+		expireAt = q.Time
+	}
+	if now.Time.Before(expireAt) {
+		return false, &expireAt, nil
+	}
+
+	// Release custom domain when no registrations
+	if err := r.Delete(ctx, d); err != nil {
+		return false, nil, err
+	}
+	return true, nil, nil
+}
+
+func (r *CustomDomainReconciler) rotateVerificationKeyIfNeeded(ctx context.Context, d *domainv1beta1.CustomDomain) (*time.Time, error) {
+	if d.Spec.VerificationKey == nil {
+		return nil, nil
+	}
+
+	interval := VerificationKeyRotationInterval
+	if d.Spec.VerificationKeyRotation != nil {
+		interval = d.Spec.VerificationKeyRotation.Duration
+	}
+
+	now := r.Now()
+	now = metav1.Unix(now.Unix(), 0) // truncate to seconds
+	rotatedAt := verificationKeyRotatedAt(d)
+	if _, ok := d.Annotations[api.VerificationKeyRotatedAtAnnotation]; ok {
+		d.Status.VerificationKeyRotatedAt = &rotatedAt
+	}
+
+	var next *time.Time
+	if interval > 0 {
+		rotateAt := rotatedAt.Add(interval)
+		if !now.Time.Before(rotateAt) {
+			// Rotation time is patched together with the keys, since
+			// status may fail to update after the patch; rotating again
+			// would discard the previous key within grace period.
+			patch := client.MergeFrom(d.DeepCopy())
+			d.Spec.PreviousVerificationKey = d.Spec.VerificationKey
+			d.Spec.VerificationKey = pointer.StringPtr(r.VerificationKeyGenerator())
+			if d.Annotations == nil {
+				d.Annotations = map[string]string{}
+			}
+			d.Annotations[api.VerificationKeyRotatedAtAnnotation] = now.UTC().Format(time.RFC3339)
+			if err := r.patchSpec(ctx, d, patch); err != nil {
+				return nil, err
+			}
+			d.Status.VerificationKeyRotatedAt = &now
+			rotatedAt = now
+			rotateAt = now.Add(interval)
+		}
+		next = &rotateAt
+	}
+
+	// Previous key is accepted during grace period after rotation.
+	if d.Spec.PreviousVerificationKey != nil {
+		expireAt := rotatedAt.Add(VerificationKeyGracePeriod)
+		if !now.Time.Before(expireAt) {
+			patch := client.MergeFrom(d.DeepCopy())
+			d.Spec.PreviousVerificationKey = nil
+			if err := r.patchSpec(ctx, d, patch); err != nil {
+				return nil, err
+			}
+		} else if next == nil || expireAt.Before(*next) {
+			next = &expireAt
+		}
+	}
+
+	return next, nil
+}
+
+// verificationKeyRotatedAt returns the time that verification key of domain
+// is last rotated, or creation time if never rotated. Status is used for
+// domains rotated before the annotation is recorded.
+func verificationKeyRotatedAt(d *domainv1beta1.CustomDomain) metav1.Time {
+	if value, ok := d.Annotations[api.VerificationKeyRotatedAtAnnotation]; ok {
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			return metav1.NewTime(t)
+		}
+	}
+	if d.Status.VerificationKeyRotatedAt != nil {
+		return *d.Status.VerificationKeyRotatedAt
+	}
+	return d.CreationTimestamp
+}
@@ -144,9 +144,9 @@ var _ = Describe("Custom Domain", func() {
 				return statusOf(namespace, domain)
 			}
 
-			domainChecker.Records["_skygear.my-app.test"] = []string{
+			dnsServer.SetTXT("_skygear.my-app.test",
 				"bf46fcae092bcfdbbfb6900e0c343c4447cc284a98e0e3cf49df0470e90085ab",
-			}
+			)
 			Expect(verify("app1", "my-app.test")).To(MatchError("verification DNS record not found"))
 			Expect(verify("app2", "my-app.test")).To(Succeed())
 			Expect(verify("app2", "sub.my-app.test")).To(Succeed())
@@ -155,9 +155,9 @@ var _ = Describe("Custom Domain", func() {
 			domainRegOld := &domainv1beta1.CustomDomainRegistration{}
 			Expect(k8sClient.Get(ctx, n, domainRegOld)).To(Succeed())
 
-			domainChecker.Records["_skygear.my-app.test"] = []string{
+			dnsServer.SetTXT("_skygear.my-app.test",
 				"c4fe13c3968005a8d8fddd37fd2738450b131c6881a501e62d8393660664330d",
-			}
+			)
 			Expect(verify("app1", "my-app.test")).To(Succeed())
 			Expect(verify("app2", "my-app.test")).To(MatchError("verification DNS record not found"))
 			Expect(verify("app2", "sub.my-app.test")).To(MatchError("verification DNS record not found"))
@@ -168,10 +168,10 @@ var _ = Describe("Custom Domain", func() {
 			interval := domainRegNew.Status.LastVerificationTime.Sub(domainRegOld.Status.LastVerificationTime.Time)
 			Expect(interval).To(BeNumerically(">=", controllers.VerificationCooldown))
 
-			domainChecker.Records["_skygear.my-app.test"] = []string{
+			dnsServer.SetTXT("_skygear.my-app.test",
 				"bf46fcae092bcfdbbfb6900e0c343c4447cc284a98e0e3cf49df0470e90085ab",
 				"c4fe13c3968005a8d8fddd37fd2738450b131c6881a501e62d8393660664330d",
-			}
+			)
 			Expect(verify("app1", "my-app.test")).To(Succeed())
 			Expect(verify("app2", "my-app.test")).To(Succeed())
 			Expect(verify("app2", "sub.my-app.test")).To(Succeed())
@@ -193,6 +193,31 @@ var _ = Describe("Custom Domain", func() {
 			Eventually(accepted("app2", "my-app.test"), timeout, interval).Should(BeFalse())
 			Eventually(accepted("app2", "sub.my-app.test"), timeout, interval).Should(BeTrue())
 		})
+		It("Should reject domain owned by another app", func() {
+			ctx := context.Background()
+			rejectReason := func(namespace, domain string) func() string {
+				return func() string {
+					n := types.NamespacedName{Namespace: namespace, Name: domain}
+					domainReg := &domainv1beta1.CustomDomainRegistration{}
+					Expect(k8sClient.Get(ctx, n, domainReg)).To(Succeed())
+
+					rejected := condition.Lookup(domainReg.Status.Conditions, string(domainv1beta1.RegistrationRejected))
+					if rejected == nil || rejected.Status != metav1.ConditionTrue {
+						return ""
+					}
+					return rejected.Reason
+				}
+			}
+
+			Eventually(rejectReason("app1", "my-app.test"), timeout, interval).Should(BeEmpty())
+			Eventually(rejectReason("app2", "my-app.test"), timeout, interval).Should(Equal(domainv1beta1.ReasonAlreadyOwned))
+			Eventually(rejectReason("app2", "sub.my-app.test"), timeout, interval).Should(BeEmpty())
+
+			d := &domainv1beta1.CustomDomain{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "my-app.test"}, d)).To(Succeed())
+			Expect(d.Spec.OwnerRef).NotTo(BeNil())
+			Expect(d.Spec.OwnerRef.Namespace).To(Equal("app1"))
+		})
 		It("Should configure Ingress and certificate Secret", func() {
 			ctx := context.Background()
 			ready := func(namespace, domain string) func() bool {
@@ -339,7 +364,13 @@ var _ = Describe("Custom Domain", func() {
 				return nil
 			}, timeout, interval).Should(Succeed())
 
-			// Secret and Ingress is deleted by GC in real env, not tested here.
+			Eventually(func() bool {
+				ingress := &networkingv1beta1.Ingress{}
+				err := k8sClient.Get(ctx, types.NamespacedName{Namespace: "app1", Name: "my-app.test"}, ingress)
+				return apierrors.IsNotFound(err)
+			}, timeout, interval).Should(BeTrue())
+
+			// Secret is deleted by GC in real env, not tested here.
 		})
 
 		It("Should not register to a terminating domain", func() {
@@ -365,6 +396,12 @@ var _ = Describe("Custom Domain", func() {
 			// CustomDomain & CustomDomainRegistration is created
 			domainUID := d.UID
 
+			Eventually(func() bool {
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: r.Spec.DomainName}, d)).To(Succeed())
+				return condition.Lookup(d.Status.Conditions, string(domainv1beta1.DomainLoadBalancerProvisioned)) != nil
+			}, timeout, interval).Should(BeTrue())
+			// Load balancer provisioning is requested, so releasing takes time
+
 			Expect(k8sClient.Delete(ctx, r.DeepCopy())).Should(Succeed())
 			Eventually(func() bool {
 				rr := &domainv1beta1.CustomDomainRegistration{}
@@ -17,28 +17,41 @@ package controllers
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
+	"golang.org/x/net/publicsuffix"
 	corev1 "k8s.io/api/core/v1"
-	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/skygeario/k8s-controller/api"
 	domain "github.com/skygeario/k8s-controller/api"
 	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
-	"github.com/skygeario/k8s-controller/pkg/domain/ingress"
+	"github.com/skygeario/k8s-controller/pkg/domain/healthcheck"
 	"github.com/skygeario/k8s-controller/pkg/domain/tls"
 	"github.com/skygeario/k8s-controller/pkg/domain/verification"
+	"github.com/skygeario/k8s-controller/pkg/metrics"
+	"github.com/skygeario/k8s-controller/pkg/notification"
+	"github.com/skygeario/k8s-controller/pkg/tracing"
 	"github.com/skygeario/k8s-controller/pkg/util/condition"
 	"github.com/skygeario/k8s-controller/pkg/util/deadline"
+	"github.com/skygeario/k8s-controller/pkg/util/dnsname"
 	"github.com/skygeario/k8s-controller/pkg/util/finalizer"
 	"github.com/skygeario/k8s-controller/pkg/util/slice"
 )
@@ -48,8 +61,9 @@ type TLSProvider interface {
 	Release(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (ok bool, err error)
 }
 
-type IngressProvider interface {
-	MakeIngress(reg *domainv1beta1.CustomDomainRegistration) (*networkingv1beta1.Ingress, error)
+type RoutingProvider interface {
+	Provision(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (ready bool, err error)
+	Release(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (ok bool, err error)
 }
 
 // CustomDomainRegistrationReconciler reconciles a CustomDomainRegistration object
@@ -59,25 +73,109 @@ type CustomDomainRegistrationReconciler struct {
 	Scheme *runtime.Scheme
 
 	Now                        func() metav1.Time
-	VerificationTokenGenerator func(key, nonce string) string
-	DomainVerifier             func(ctx context.Context, domain, token string) error
+	VerificationTokenGenerator verification.TokenGenerator
+	DomainVerifier             func(ctx context.Context, method verification.Method, domain, token string) error
+	DNSConfigChecker           func(ctx context.Context, domain string, records []verification.DNSRecord) (configured bool, results []verification.DNSRecordResult, err error)
+	VerificationChallengeZone  string
 	TLSProvider                TLSProvider
-	IngressProvider            ingress.Provider
+	RoutingProvider            RoutingProvider
+	Recorder                   record.EventRecorder
+	Audit                      *AuditLogger
+	// VerificationKeySecret is the Secret storing verification token
+	// secrets. All registrations are reconciled when it is changed.
+	VerificationKeySecret *types.NamespacedName
+	// DomainClient is the client of CustomDomain resources, defaults to
+	// Client. In multi-cluster mode, it is the client of hub cluster.
+	DomainClient client.Client
+	// DomainCache is the cache of CustomDomain resources for watches and
+	// indexes, defaults to cache of the manager. In multi-cluster mode, it is
+	// the cache of hub cluster.
+	DomainCache cache.Cache
+	// ClusterName is the name of this workload cluster in multi-cluster mode.
+	// It is recorded in registration references of domains.
+	ClusterName string
+	// Notifier is notified on lifecycle events of registrations, if not nil.
+	Notifier notification.Notifier
+	// InheritParentVerification verifies registrations without verification
+	// records, if a parent domain is verified by another registration in the
+	// same namespace.
+	InheritParentVerification bool
+	// VerificationView is the DNS view observed by DomainVerifier, recorded
+	// in status of verified registrations.
+	VerificationView string
+	// VerificationCNAMETarget is the target of CNAME records encoding
+	// verification tokens, for DNS verification with record type CNAME.
+	VerificationCNAMETarget string
+	// VerificationRecordPrefix is the label prefixing the name of
+	// verification TXT records, for domains not specifying their own prefix.
+	// Defaults to verification.DefaultDNSRecordPrefix.
+	VerificationRecordPrefix string
+	// EndpointProber probes endpoints of registrations with health checks.
+	// Health checks are disabled if nil.
+	EndpointProber func(ctx context.Context, url string) (*healthcheck.Result, error)
+	// RequireApproval is the registrations requiring approval of operators
+	// before accepted, in addition to those required by domain policies.
+	RequireApproval domainv1beta1.ApprovalMode
+	// DefaultDomainSuffix is the platform-owned suffix of default domains of
+	// namespaces. Registrations of default domain of its own namespace are
+	// verified without verification records, while the namespace matches
+	// DefaultDomainNamespaceSelector.
+	DefaultDomainSuffix            string
+	DefaultDomainNamespaceSelector labels.Selector
+	// EmailChallenger sends confirmation links of Email verification. Email
+	// verification is disabled if nil.
+	EmailChallenger *verification.EmailChallenger
+	// MaxConcurrentReconciles is the maximum number of concurrent
+	// reconciliations, defaults to 1.
+	MaxConcurrentReconciles int
 }
 
 // +kubebuilder:rbac:groups=domain.skygear.io,resources=customdomainregistrations,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=domain.skygear.io,resources=customdomainregistrations/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=domain.skygear.io,resources=domainquotas,verbs=get;list;watch
+// +kubebuilder:rbac:groups=domain.skygear.io,resources=domainpolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups=domain.skygear.io,resources=domainreservations,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=networking.istio.io,resources=gateways;virtualservices,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=projectcontour.io,resources=httpproxies,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=traefik.containo.us,resources=ingressroutes;middlewares,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=secrets;services,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
-func (r *CustomDomainRegistrationReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
-	ctx := context.Background()
-	_ = r.Log.WithValues("customdomainregistration", req.NamespacedName)
+func (r *CustomDomainRegistrationReconciler) Reconcile(req ctrl.Request) (result ctrl.Result, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ReconcileTimeout)
+	defer cancel()
+	ctx, span := tracing.Start(ctx, "Reconcile CustomDomainRegistration", tracing.SpanKindInternal,
+		tracing.String("k8s.namespace.name", req.Namespace),
+		tracing.String("k8s.resource.name", req.Name),
+		tracing.String("domain", dnsname.DomainName(req.Name)),
+	)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+	log := newReconcileLogger(r.Log, dnsname.DomainName(req.Name), "customdomainregistration", req.NamespacedName)
+	ctx = withLogger(ctx, log)
 
 	var reg domainv1beta1.CustomDomainRegistration
 	if err := r.Get(ctx, req.NamespacedName, &reg); err != nil {
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
+	log.V(1).Info("reconciling registration", "generation", reg.Generation, "deleting", reg.DeletionTimestamp != nil)
+	oldStatus := reg.Status.DeepCopy()
+	reg.Status.Zone = dnsname.Zone(reg.ASCIIDomainName())
+
+	if isPaused(&reg) {
+		conditions := pausedConditions(reg.Status.Conditions, string(domainv1beta1.RegistrationPaused))
+		return ctrl.Result{}, r.updateStatus(ctx, &reg, oldStatus, conditions)
+	}
 
-	var conditions []api.Condition
+	conditions := []api.Condition{{
+		Type:   string(domainv1beta1.RegistrationPaused),
+		Status: metav1.ConditionFalse,
+	}}
 	doFinalize := false
 	var requeueDeadline deadline.Deadline
 	if reg.DeletionTimestamp == nil {
@@ -89,58 +187,304 @@ func (r *CustomDomainRegistrationReconciler) Reconcile(req ctrl.Request) (ctrl.R
 			return ctrl.Result{Requeue: true}, nil
 		}
 
-		registered, err := r.registerDomain(ctx, &reg)
+		if expiry := registrationExpiry(&reg); expiry != nil {
+			if !r.Now().Time.Before(*expiry) {
+				// Only the observed unverified version is deleted, so that
+				// registrations verified meanwhile, which may be serving,
+				// are never deleted by expiry.
+				uid, resourceVersion := reg.UID, reg.ResourceVersion
+				err := r.Delete(ctx, &reg, client.Preconditions{UID: &uid, ResourceVersion: &resourceVersion})
+				if err != nil {
+					return ctrl.Result{}, client.IgnoreNotFound(err)
+				}
+				log.Info("deleted expired registration", "expiry", expiry)
+				r.Recorder.Event(&reg, corev1.EventTypeNormal, EventRegistrationExpired, "Deleted registration expired before domain is verified")
+				return ctrl.Result{}, nil
+			}
+			requeueDeadline.Set(*expiry)
+		}
+
+		// Registrations created without the webhook may have invalid domain
+		// names, and are not retried until spec is changed. As in the
+		// webhook, existing registrations are not affected: those already
+		// observed by verification are not failed.
+		verificationObserved := condition.Lookup(reg.Status.Conditions, string(domainv1beta1.RegistrationVerified)) != nil
+		if err := dnsname.Validate(reg.ASCIIDomainName()); err != nil && !verificationObserved {
+			conditions = append(conditions, api.Condition{
+				Type:    string(domainv1beta1.RegistrationFailed),
+				Status:  metav1.ConditionTrue,
+				Reason:  domainv1beta1.ReasonInvalidDomainName,
+				Message: fmt.Sprintf("invalid domain name: %s", err),
+			})
+			return ctrl.Result{RequeueAfter: requeueDeadline.Duration(r.Now().Time)}, r.updateStatus(ctx, &reg, oldStatus, conditions)
+		}
+
+		policyMessage, err := domainv1beta1.CheckPolicy(ctx, r.Client, &reg)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if policyMessage != "" {
+			conditions = append(conditions, api.Condition{
+				Type:    string(domainv1beta1.RegistrationPolicyViolation),
+				Status:  metav1.ConditionTrue,
+				Message: policyMessage,
+			})
+			return r.updateBlockedStatus(ctx, &reg, oldStatus, conditions, &requeueDeadline)
+		}
+		reservationMessage, err := domainv1beta1.CheckReservation(ctx, r.Client, &reg)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if reservationMessage != "" {
+			conditions = append(conditions, api.Condition{
+				Type:    string(domainv1beta1.RegistrationPolicyViolation),
+				Status:  metav1.ConditionTrue,
+				Reason:  domainv1beta1.ReasonReserved,
+				Message: reservationMessage,
+			})
+			return r.updateBlockedStatus(ctx, &reg, oldStatus, conditions, &requeueDeadline)
+		}
+		conditions = append(conditions, api.Condition{
+			Type:   string(domainv1beta1.RegistrationPolicyViolation),
+			Status: metav1.ConditionFalse,
+		})
+
+		suspiciousMessage, err := domainv1beta1.CheckSuspicious(ctx, r.Client, &reg)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		conditions = append(conditions, api.Condition{
+			Type:    string(domainv1beta1.RegistrationSuspiciousDomain),
+			Status:  condition.ToStatus(suspiciousMessage != ""),
+			Message: suspiciousMessage,
+		})
+
+		quotaMessage, err := domainv1beta1.CheckQuota(ctx, r.Client, &reg)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if quotaMessage != "" {
+			conditions = append(conditions, api.Condition{
+				Type:    string(domainv1beta1.RegistrationQuotaExceeded),
+				Status:  metav1.ConditionTrue,
+				Message: quotaMessage,
+			})
+			return r.updateBlockedStatus(ctx, &reg, oldStatus, conditions, &requeueDeadline)
+		}
+		conditions = append(conditions, api.Condition{
+			Type:   string(domainv1beta1.RegistrationQuotaExceeded),
+			Status: metav1.ConditionFalse,
+		})
+
+		// Registrations accepted before approval is required are not
+		// affected, unless they were approved and the approval is revoked.
+		approvalRevoked := !reg.IsApproved() &&
+			condition.IsTrue(reg.Status.Conditions, string(domainv1beta1.RegistrationApproved))
+		isDefaultDomain, err := r.isDefaultDomain(ctx, &reg)
 		if err != nil {
 			return ctrl.Result{}, err
 		}
+		if !isDefaultDomain && (reg.IsApproved() || approvalRevoked ||
+			!condition.IsTrue(reg.Status.Conditions, string(domainv1beta1.RegistrationAccepted))) {
+			requiresApproval, err := domainv1beta1.RequiresApproval(ctx, r.Client, &reg, r.RequireApproval)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			if requiresApproval && !reg.IsApproved() {
+				conditions = append(conditions, api.Condition{
+					Type:    string(domainv1beta1.RegistrationAccepted),
+					Status:  metav1.ConditionFalse,
+					Reason:  domainv1beta1.ReasonPendingApproval,
+					Message: "registration is pending approval of operators",
+				})
+				return r.updateBlockedStatus(ctx, &reg, oldStatus, conditions, &requeueDeadline)
+			}
+			if requiresApproval {
+				conditions = append(conditions, api.Condition{
+					Type:   string(domainv1beta1.RegistrationApproved),
+					Status: metav1.ConditionTrue,
+				})
+			}
+		}
+
+		// Failed registration is not retried until spec is changed.
+		if cond := condition.Lookup(reg.Status.Conditions, string(domainv1beta1.RegistrationFailed)); cond != nil &&
+			cond.Status == metav1.ConditionTrue && cond.ObservedGeneration == reg.Generation {
+			conditions = append(conditions, *cond)
+			return ctrl.Result{RequeueAfter: requeueDeadline.Duration(r.Now().Time)}, r.updateStatus(ctx, &reg, oldStatus, conditions)
+		}
+
+		registered, err := r.registerDomain(ctx, &reg)
+		if meta.IsNoMatchError(err) {
+			// Retried with backoff of work queue until the resource is installed.
+			conditions = append(conditions, api.Condition{
+				Type:    string(domainv1beta1.RegistrationDomainBackendUnavailable),
+				Status:  metav1.ConditionTrue,
+				Reason:  domainv1beta1.ReasonResourceNotInstalled,
+				Message: "CustomDomain resource is not installed in the cluster",
+			})
+			if err := r.updateStatus(ctx, &reg, oldStatus, conditions); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{Requeue: true}, nil
+		} else if err != nil {
+			return ctrl.Result{}, err
+		}
+		conditions = append(conditions, api.Condition{
+			Type:   string(domainv1beta1.RegistrationDomainBackendUnavailable),
+			Status: metav1.ConditionFalse,
+		})
 		if !registered {
 			return ctrl.Result{RequeueAfter: PollInterval}, nil
 		}
+		if r.ClusterName != "" {
+			// Refresh heartbeat of reference in domain of hub cluster
+			requeueDeadline.Set(r.Now().Add(RemoteRegistrationHeartbeatInterval))
+		}
 
-		requeueTime, verified, err := r.verifyDomainIfNeeded(ctx, &reg)
+		requeueTime, verified, verifiedReason, err := r.verifyDomainIfNeeded(ctx, &reg)
 		if err != nil {
 			conditions = append(conditions, api.Condition{
 				Type:    string(domainv1beta1.RegistrationVerified),
 				Status:  condition.ToStatus(verified),
+				Reason:  verification.FailureReason(err),
 				Message: err.Error(),
 			})
+		} else if cond := condition.Lookup(reg.Status.Conditions, string(domainv1beta1.RegistrationVerified)); cond != nil &&
+			verifiedReason == "" && cond.Status == condition.ToStatus(verified) {
+			// Keep reason and message of last verification, e.g. the
+			// failure while next verification is not due.
+			conditions = append(conditions, *cond)
 		} else {
 			conditions = append(conditions, api.Condition{
 				Type:   string(domainv1beta1.RegistrationVerified),
 				Status: condition.ToStatus(verified),
+				Reason: verifiedReason,
 			})
 		}
+		reg.Status.NextVerificationTime = nil
 		if requeueTime != nil {
 			requeueDeadline.Set(*requeueTime)
+			t := metav1.Unix(requeueTime.Unix(), 0)
+			reg.Status.NextVerificationTime = &t
+		}
+
+		if verifyBy := verificationDeadline(&reg); verifyBy != nil && !verified {
+			if !r.Now().Time.Before(*verifyBy) {
+				conditions = append(conditions, api.Condition{
+					Type:    string(domainv1beta1.RegistrationFailed),
+					Status:  metav1.ConditionTrue,
+					Reason:  domainv1beta1.ReasonVerificationDeadlineExceeded,
+					Message: "domain is not verified before verification deadline",
+				})
+				r.Recorder.Eventf(&reg, corev1.EventTypeWarning, EventVerificationDeadlineExceeded,
+					"Domain is not verified within %d seconds", *reg.Spec.Verification.DeadlineSeconds)
+				return ctrl.Result{}, r.updateStatus(ctx, &reg, oldStatus, conditions)
+			}
+			requeueDeadline.Set(*verifyBy)
 		}
+		conditions = append(conditions, api.Condition{
+			Type:   string(domainv1beta1.RegistrationFailed),
+			Status: metav1.ConditionFalse,
+		})
 
-		accepted, err := r.checkAcceptance(ctx, &reg)
+		accepted, rejected, err := r.checkAcceptance(ctx, &reg, verified)
 		if err != nil {
 			conditions = append(conditions, api.Condition{
 				Type:    string(domainv1beta1.RegistrationAccepted),
 				Status:  metav1.ConditionUnknown,
 				Message: err.Error(),
 			})
+			conditions = append(conditions, api.Condition{
+				Type:    string(domainv1beta1.RegistrationRejected),
+				Status:  metav1.ConditionUnknown,
+				Message: err.Error(),
+			})
 		} else {
 			conditions = append(conditions, api.Condition{
 				Type:   string(domainv1beta1.RegistrationAccepted),
 				Status: condition.ToStatus(accepted),
 			})
+			if rejected {
+				conditions = append(conditions, api.Condition{
+					Type:    string(domainv1beta1.RegistrationRejected),
+					Status:  metav1.ConditionTrue,
+					Reason:  domainv1beta1.ReasonAlreadyOwned,
+					Message: "domain is already owned by another app",
+				})
+			} else {
+				conditions = append(conditions, api.Condition{
+					Type:   string(domainv1beta1.RegistrationRejected),
+					Status: metav1.ConditionFalse,
+				})
+			}
+		}
+
+		transferPending, transferAccepted, err := r.checkTransfer(ctx, &reg)
+		if err != nil {
+			conditions = append(conditions, api.Condition{
+				Type:    string(domainv1beta1.RegistrationTransferPending),
+				Status:  metav1.ConditionUnknown,
+				Message: err.Error(),
+			})
+			conditions = append(conditions, api.Condition{
+				Type:    string(domainv1beta1.RegistrationTransferAccepted),
+				Status:  metav1.ConditionUnknown,
+				Message: err.Error(),
+			})
+		} else {
+			conditions = append(conditions, api.Condition{
+				Type:   string(domainv1beta1.RegistrationTransferPending),
+				Status: condition.ToStatus(transferPending),
+			})
+			conditions = append(conditions, api.Condition{
+				Type:   string(domainv1beta1.RegistrationTransferAccepted),
+				Status: condition.ToStatus(transferAccepted),
+			})
+		}
+
+		conflicts, err := r.checkConflicts(ctx, &reg)
+		if err != nil {
+			conditions = append(conditions, api.Condition{
+				Type:    string(domainv1beta1.RegistrationDomainConflict),
+				Status:  metav1.ConditionUnknown,
+				Message: err.Error(),
+			})
+		} else if len(conflicts) > 0 {
+			conditions = append(conditions, api.Condition{
+				Type:    string(domainv1beta1.RegistrationDomainConflict),
+				Status:  metav1.ConditionTrue,
+				Message: fmt.Sprintf("domain overlaps with domains owned by other apps: %s", strings.Join(conflicts, ", ")),
+			})
+		} else {
+			conditions = append(conditions, api.Condition{
+				Type:   string(domainv1beta1.RegistrationDomainConflict),
+				Status: metav1.ConditionFalse,
+			})
 		}
 
 		var certSecretName *string
 		if accepted {
 			tlsResult, err := r.TLSProvider.Provision(ctx, &reg)
-			if err != nil {
+			if errors.Is(err, tls.ErrSecretNotOwned) {
+				conditions = append(conditions, api.Condition{
+					Type:    string(domainv1beta1.RegistrationCertReady),
+					Status:  metav1.ConditionFalse,
+					Reason:  domainv1beta1.ReasonSecretNotOwned,
+					Message: err.Error(),
+				})
+			} else if err != nil {
 				conditions = append(conditions, api.Condition{
 					Type:    string(domainv1beta1.RegistrationCertReady),
 					Status:  metav1.ConditionUnknown,
 					Message: err.Error(),
 				})
+			} else if tlsResult == nil {
+				conditions = append(conditions, r.certificatePendingCondition(ctx, &reg))
 			} else {
 				conditions = append(conditions, api.Condition{
 					Type:   string(domainv1beta1.RegistrationCertReady),
-					Status: condition.ToStatus(tlsResult != nil),
+					Status: metav1.ConditionTrue,
 				})
 			}
 			if tlsResult == nil {
@@ -168,8 +512,38 @@ func (r *CustomDomainRegistrationReconciler) Reconcile(req ctrl.Request) (ctrl.R
 		}
 		reg.Status.CertSecretName = certSecretName
 
+		notAfter, expiringSoon, err := r.checkCertificateExpiry(ctx, &reg)
+		if err != nil {
+			conditions = append(conditions, api.Condition{
+				Type:    string(domainv1beta1.RegistrationCertificateExpiringSoon),
+				Status:  metav1.ConditionUnknown,
+				Message: err.Error(),
+			})
+		} else if expiringSoon && !r.Now().Time.Before(*notAfter) {
+			conditions = append(conditions, api.Condition{
+				Type:    string(domainv1beta1.RegistrationCertificateExpiringSoon),
+				Status:  metav1.ConditionTrue,
+				Reason:  domainv1beta1.ReasonCertificateExpired,
+				Message: fmt.Sprintf("certificate expired at %s", notAfter.Format(time.RFC3339)),
+			})
+		} else if expiringSoon {
+			conditions = append(conditions, api.Condition{
+				Type:    string(domainv1beta1.RegistrationCertificateExpiringSoon),
+				Status:  metav1.ConditionTrue,
+				Message: fmt.Sprintf("certificate expires at %s", notAfter.Format(time.RFC3339)),
+			})
+		} else {
+			conditions = append(conditions, api.Condition{
+				Type:   string(domainv1beta1.RegistrationCertificateExpiringSoon),
+				Status: metav1.ConditionFalse,
+			})
+			if notAfter != nil {
+				requeueDeadline.Set(notAfter.Add(-CertificateExpiryWarningPeriod))
+			}
+		}
+
 		if accepted {
-			ok, err := r.updateIngress(ctx, &reg)
+			ready, err := r.RoutingProvider.Provision(ctx, &reg)
 			if err != nil {
 				conditions = append(conditions, api.Condition{
 					Type:    string(domainv1beta1.RegistrationIngressReady),
@@ -179,11 +553,14 @@ func (r *CustomDomainRegistrationReconciler) Reconcile(req ctrl.Request) (ctrl.R
 			} else {
 				conditions = append(conditions, api.Condition{
 					Type:   string(domainv1beta1.RegistrationIngressReady),
-					Status: condition.ToStatus(ok),
+					Status: condition.ToStatus(ready),
 				})
 			}
+			if !ready {
+				requeueDeadline.Set(r.Now().Add(PollInterval))
+			}
 		} else {
-			ok, err := r.deleteIngress(ctx, &reg)
+			ok, err := r.RoutingProvider.Release(ctx, &reg)
 			if err != nil {
 				conditions = append(conditions, api.Condition{
 					Type:    string(domainv1beta1.RegistrationIngressReady),
@@ -198,10 +575,62 @@ func (r *CustomDomainRegistrationReconciler) Reconcile(req ctrl.Request) (ctrl.R
 			}
 		}
 
+		if err := r.reconcileSibling(ctx, &reg, accepted && reg.Spec.RedirectSibling); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		if r.DNSConfigChecker != nil {
+			requeueTime, configured, err := r.checkDNSConfigIfNeeded(ctx, &reg)
+			if err != nil {
+				conditions = append(conditions, api.Condition{
+					Type:    string(domainv1beta1.RegistrationDNSConfigured),
+					Status:  metav1.ConditionUnknown,
+					Reason:  verification.FailureReason(err),
+					Message: err.Error(),
+				})
+			} else {
+				conditions = append(conditions, api.Condition{
+					Type:   string(domainv1beta1.RegistrationDNSConfigured),
+					Status: condition.ToStatus(configured),
+				})
+			}
+			if requeueTime != nil {
+				requeueDeadline.Set(*requeueTime)
+			}
+		}
+
+		// Probe the endpoint only after routing is configured, so that
+		// failures indicate DNS pointing to the wrong place.
+		if r.EndpointProber != nil && reg.Spec.HealthCheck != nil &&
+			condition.IsTrue(conditions, string(domainv1beta1.RegistrationIngressReady)) {
+			requeueTime, cond := r.checkEndpointHealthIfNeeded(ctx, &reg)
+			conditions = append(conditions, cond)
+			requeueDeadline.Set(*requeueTime)
+		} else {
+			reg.Status.HealthCheck = nil
+		}
+
+		if reg.Spec.DedicatedIP {
+			cond, err := r.dedicatedIPCondition(ctx, &reg)
+			if err != nil {
+				conditions = append(conditions, api.Condition{
+					Type:    string(domainv1beta1.RegistrationDedicatedIPAllocated),
+					Status:  metav1.ConditionUnknown,
+					Message: err.Error(),
+				})
+			} else if cond != nil {
+				conditions = append(conditions, *cond)
+			}
+		}
+
 	} else {
 		doFinalize = true
 
 		unregistered, err := r.unregisterDomain(ctx, &reg)
+		if meta.IsNoMatchError(err) {
+			// No domain can be registered without the resource.
+			unregistered, err = true, nil
+		}
 		if err != nil {
 			doFinalize = false
 			conditions = append(conditions, api.Condition{
@@ -212,16 +641,34 @@ func (r *CustomDomainRegistrationReconciler) Reconcile(req ctrl.Request) (ctrl.R
 			requeueDeadline.Set(r.Now().Add(PollInterval))
 		} else {
 			doFinalize = doFinalize && unregistered
+			if !unregistered {
+				requeueDeadline.Set(r.Now().Add(PollInterval))
+			}
 			conditions = append(conditions, api.Condition{
 				Type:   string(domainv1beta1.RegistrationAccepted),
 				Status: condition.ToStatus(!unregistered),
 			})
 		}
+
+		deleted, err := r.RoutingProvider.Release(ctx, &reg)
+		if err != nil {
+			doFinalize = false
+			conditions = append(conditions, api.Condition{
+				Type:    string(domainv1beta1.RegistrationIngressReady),
+				Status:  metav1.ConditionUnknown,
+				Message: err.Error(),
+			})
+			requeueDeadline.Set(r.Now().Add(PollInterval))
+		} else {
+			doFinalize = doFinalize && deleted
+			conditions = append(conditions, api.Condition{
+				Type:   string(domainv1beta1.RegistrationIngressReady),
+				Status: condition.ToStatus(!deleted),
+			})
+		}
 	}
 
-	condition.MergeFrom(conditions, reg.Status.Conditions)
-	reg.Status.Conditions = conditions
-	if err := r.Status().Update(ctx, &reg); err != nil {
+	if err := r.updateStatus(ctx, &reg, oldStatus, conditions); err != nil {
 		return ctrl.Result{}, err
 	}
 
@@ -230,31 +677,317 @@ func (r *CustomDomainRegistrationReconciler) Reconcile(req ctrl.Request) (ctrl.R
 		return ctrl.Result{}, err
 	}
 
+	if t := resyncTime(r.Now().Time); t != nil {
+		requeueDeadline.Set(*t)
+	}
 	return ctrl.Result{RequeueAfter: requeueDeadline.Duration(r.Now().Time)}, nil
 }
 
+// updateStatus writes status of the registration with the conditions. The
+// update is skipped if status is unchanged from oldStatus.
+func (r *CustomDomainRegistrationReconciler) updateStatus(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration, oldStatus *domainv1beta1.CustomDomainRegistrationStatus, conditions []api.Condition) error {
+	condition.MergeFrom(conditions, reg.Status.Conditions)
+	condition.SetObservedGeneration(conditions, reg.Generation)
+	reg.Status.Conditions = conditions
+	reg.Status.ObservedGeneration = reg.Generation
+	reg.Status.Phase = registrationPhase(reg.DeletionTimestamp != nil, conditions)
+	reg.Status.Instructions = makeDNSInstructions(reg.Status.DNSRecords)
+	reg.Status.DomainName = reg.ASCIIDomainName()
+	if name, err := dnsname.ToUnicode(reg.Status.DomainName); err == nil {
+		reg.Status.UnicodeDomainName = name
+	}
+	if equality.Semantic.DeepEqual(&reg.Status, oldStatus) {
+		metrics.StatusUpdatesSkipped.WithLabelValues("customdomainregistration").Inc()
+		return nil
+	}
+	loggerFrom(ctx, r.Log).V(1).Info("updating status", "phase", reg.Status.Phase)
+	spanCtx, span := tracing.Start(ctx, "UpdateStatus CustomDomainRegistration", tracing.SpanKindClient)
+	err := r.Status().Update(spanCtx, reg)
+	span.RecordError(err)
+	span.End()
+	if err != nil {
+		return err
+	}
+	r.notifyTransitions(reg, oldStatus)
+	return nil
+}
+
+// updateBlockedStatus releases routing and TLS of the registration blocked
+// from serving its domain, and writes status with the conditions. The
+// registration may be serving if it was accepted before the block applies,
+// e.g. when a quota is lowered, a domain policy or reservation is added, or
+// its approval is revoked. Verification is skipped while blocked, so the
+// last Verified condition is kept.
+func (r *CustomDomainRegistrationReconciler) updateBlockedStatus(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration, oldStatus *domainv1beta1.CustomDomainRegistrationStatus, conditions []api.Condition, requeueDeadline *deadline.Deadline) (ctrl.Result, error) {
+	if cond := condition.Lookup(reg.Status.Conditions, string(domainv1beta1.RegistrationVerified)); cond != nil {
+		conditions = append(conditions, *cond)
+	}
+	if condition.Lookup(conditions, string(domainv1beta1.RegistrationAccepted)) == nil {
+		conditions = append(conditions, api.Condition{
+			Type:   string(domainv1beta1.RegistrationAccepted),
+			Status: metav1.ConditionFalse,
+		})
+	}
+
+	released, err := r.TLSProvider.Release(ctx, reg)
+	conditions = append(conditions, releasedCondition(domainv1beta1.RegistrationCertReady, released, err))
+	if released {
+		reg.Status.CertSecretName = nil
+	} else {
+		requeueDeadline.Set(r.Now().Add(PollInterval))
+	}
+
+	released, err = r.RoutingProvider.Release(ctx, reg)
+	conditions = append(conditions, releasedCondition(domainv1beta1.RegistrationIngressReady, released, err))
+	if !released {
+		requeueDeadline.Set(r.Now().Add(PollInterval))
+	}
+
+	if err := r.reconcileSibling(ctx, reg, false); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: requeueDeadline.Duration(r.Now().Time)}, r.updateStatus(ctx, reg, oldStatus, conditions)
+}
+
+// releasedCondition returns the condition of a resource being released.
+func releasedCondition(condType domainv1beta1.CustomDomainRegistrationConditionType, released bool, err error) api.Condition {
+	if err != nil {
+		return api.Condition{
+			Type:    string(condType),
+			Status:  metav1.ConditionUnknown,
+			Message: err.Error(),
+		}
+	}
+	return api.Condition{
+		Type:   string(condType),
+		Status: condition.ToStatus(!released),
+	}
+}
+
+// notifyTransitions notifies lifecycle events of conditions becoming true in
+// the status update.
+func (r *CustomDomainRegistrationReconciler) notifyTransitions(reg *domainv1beta1.CustomDomainRegistration, oldStatus *domainv1beta1.CustomDomainRegistrationStatus) {
+	transitions := []struct {
+		condType  domainv1beta1.CustomDomainRegistrationConditionType
+		eventType notification.EventType
+	}{
+		{domainv1beta1.RegistrationVerified, notification.EventVerified},
+		{domainv1beta1.RegistrationFailed, notification.EventFailed},
+		{domainv1beta1.RegistrationCertReady, notification.EventCertificateIssued},
+	}
+	for _, t := range transitions {
+		if condition.IsTrue(oldStatus.Conditions, string(t.condType)) ||
+			!condition.IsTrue(reg.Status.Conditions, string(t.condType)) {
+			continue
+		}
+		var message string
+		if cond := condition.Lookup(reg.Status.Conditions, string(t.condType)); cond != nil {
+			message = cond.Message
+		}
+		r.notify(reg, t.eventType, message)
+	}
+}
+
+func (r *CustomDomainRegistrationReconciler) notify(reg *domainv1beta1.CustomDomainRegistration, eventType notification.EventType, message string) {
+	if r.Notifier == nil {
+		return
+	}
+	r.Notifier.Notify(notification.Event{
+		Type:      eventType,
+		Time:      r.Now().Time,
+		Namespace: reg.Namespace,
+		Name:      reg.Name,
+		Domain:    reg.ASCIIDomainName(),
+		Message:   message,
+	})
+}
+
 func (r *CustomDomainRegistrationReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	domainCache := r.DomainCache
+	if domainCache == nil {
+		installed, err := IsCustomDomainInstalled(mgr.GetRESTMapper())
+		if err != nil {
+			return err
+		}
+		if installed {
+			domainCache = mgr.GetCache()
+		}
+	}
+	if err := setupIndexes(mgr.GetFieldIndexer(), domainCache); err != nil {
+		return err
+	}
+
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&domainv1beta1.CustomDomainRegistration{}).
-		Watches(
-			&source.Kind{Type: &domainv1beta1.CustomDomain{}},
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles})
+	if domainCache != nil {
+		domainSource := &source.Kind{Type: &domainv1beta1.CustomDomain{}}
+		if err := domainSource.InjectCache(domainCache); err != nil {
+			return err
+		}
+		b = b.Watches(
+			domainSource,
 			&handler.EnqueueRequestsFromMapFunc{
 				ToRequests: handler.ToRequestsFunc(func(o handler.MapObject) []ctrl.Request {
 					d := o.Object.(*domainv1beta1.CustomDomain)
-					reqs := make([]ctrl.Request, len(d.Spec.Registrations))
-					for i, reg := range d.Spec.Registrations {
-						reqs[i] = ctrl.Request{NamespacedName: types.NamespacedName{Namespace: reg.Namespace, Name: reg.Name}}
+					reqs := r.registrationsOfDomain(dnsname.DomainName(d.Name))
+					// ownership change may affect conflicts of overlapping domains
+					overlapping, err := r.overlappingDomains(context.Background(), dnsname.DomainName(d.Name))
+					if err != nil {
+						r.Log.Error(err, "cannot list overlapping domains", "customdomain", d.Name)
+					}
+					for _, od := range overlapping {
+						reqs = append(reqs, r.registrationsOfDomain(dnsname.DomainName(od.Name))...)
 					}
 					return reqs
 				}),
 			},
+		)
+	} else {
+		// Changes of domains are not watched until the controller is
+		// restarted after the resource is installed.
+		r.Log.Info("CustomDomain resource is not installed, domains are not watched")
+	}
+	if r.DefaultDomainSuffix != "" {
+		b = b.Watches(
+			&source.Kind{Type: &corev1.Namespace{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.mapDefaultDomainNamespace)},
+		)
+	}
+
+	return b.
+		Watches(
+			&source.Kind{Type: &domainv1beta1.DomainQuota{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.mapAllRegistrations)},
+		).
+		Watches(
+			&source.Kind{Type: &domainv1beta1.DomainPolicy{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.mapAllRegistrations)},
+		).
+		Watches(
+			&source.Kind{Type: &domainv1beta1.DomainReservation{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.mapAllRegistrations)},
+		).
+		Watches(
+			&source.Kind{Type: &corev1.Secret{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.mapSecret)},
+		).
+		Watches(
+			&source.Kind{Type: &domainv1beta1.CustomDomainRegistration{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.mapSubdomainRegistrations)},
 		).
 		Complete(r)
 }
 
+// mapDefaultDomainNamespace maps a namespace to registration of its default
+// domain, which is verified only while the namespace is selected.
+func (r *CustomDomainRegistrationReconciler) mapDefaultDomainNamespace(o handler.MapObject) []ctrl.Request {
+	name := dnsname.ResourceName(DefaultDomainName(o.Meta.GetName(), r.DefaultDomainSuffix))
+	return []ctrl.Request{{NamespacedName: types.NamespacedName{Namespace: o.Meta.GetName(), Name: name}}}
+}
+
+// domainClient returns the client of CustomDomain resources.
+func (r *CustomDomainRegistrationReconciler) domainClient() client.Client {
+	if r.DomainClient != nil {
+		return r.DomainClient
+	}
+	return r.Client
+}
+
+// mapSubdomainRegistrations maps a registration to registrations of its
+// subdomains in the same namespace, which may inherit its verification.
+func (r *CustomDomainRegistrationReconciler) mapSubdomainRegistrations(o handler.MapObject) []ctrl.Request {
+	if !r.InheritParentVerification {
+		return nil
+	}
+	parent := o.Object.(*domainv1beta1.CustomDomainRegistration)
+	suffix := "." + dnsname.TrimWildcard(parent.ASCIIDomainName())
+
+	var list domainv1beta1.CustomDomainRegistrationList
+	if err := r.List(context.Background(), &list, client.InNamespace(parent.Namespace)); err != nil {
+		r.Log.Error(err, "cannot list registrations", "namespace", parent.Namespace)
+		return nil
+	}
+	var reqs []ctrl.Request
+	for _, reg := range list.Items {
+		if reg.UID != parent.UID && strings.HasSuffix(reg.ASCIIDomainName(), suffix) {
+			reqs = append(reqs, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: reg.Namespace, Name: reg.Name}})
+		}
+	}
+	return reqs
+}
+
+// mapSecret maps a Secret to registrations referencing it as TLS secret, or
+// all registrations if it is the verification key Secret.
+func (r *CustomDomainRegistrationReconciler) mapSecret(o handler.MapObject) []ctrl.Request {
+	name := types.NamespacedName{Namespace: o.Meta.GetNamespace(), Name: o.Meta.GetName()}
+	if r.VerificationKeySecret != nil && name == *r.VerificationKeySecret {
+		return r.mapAllRegistrations(o)
+	}
+
+	var list domainv1beta1.CustomDomainRegistrationList
+	err := r.List(context.Background(), &list,
+		client.InNamespace(name.Namespace),
+		client.MatchingFields{secretNameIndex: name.Name},
+	)
+	if err != nil {
+		r.Log.Error(err, "cannot list registrations", "secret", name)
+		return nil
+	}
+	var reqs []ctrl.Request
+	for _, reg := range list.Items {
+		reqs = append(reqs, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: reg.Namespace, Name: reg.Name}})
+	}
+	return reqs
+}
+
+// registrationsOfDomain returns requests of registrations of the domain.
+func (r *CustomDomainRegistrationReconciler) registrationsOfDomain(domainName string) []ctrl.Request {
+	var list domainv1beta1.CustomDomainRegistrationList
+	if err := r.List(context.Background(), &list, client.MatchingFields{domainNameIndex: domainName}); err != nil {
+		r.Log.Error(err, "cannot list registrations", "domain", domainName)
+		return nil
+	}
+	var reqs []ctrl.Request
+	for _, reg := range list.Items {
+		reqs = append(reqs, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: reg.Namespace, Name: reg.Name}})
+	}
+	return reqs
+}
+
+func (r *CustomDomainRegistrationReconciler) mapAllRegistrations(o handler.MapObject) []ctrl.Request {
+	var list domainv1beta1.CustomDomainRegistrationList
+	if err := r.List(context.Background(), &list); err != nil {
+		r.Log.Error(err, "cannot list registrations")
+		return nil
+	}
+	var reqs []ctrl.Request
+	for _, reg := range list.Items {
+		reqs = append(reqs, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: reg.Namespace, Name: reg.Name}})
+	}
+	return reqs
+}
+
+// isDefaultDomain returns whether reg is the default domain of its
+// namespace, and the namespace is currently selected to be provisioned with
+// default domain, as NamespaceReconciler releases default domains of
+// deselected namespaces.
+// namespace under the platform-owned suffix.
+func (r *CustomDomainRegistrationReconciler) isDefaultDomain(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (bool, error) {
+	if r.DefaultDomainSuffix == "" || r.DefaultDomainNamespaceSelector == nil ||
+		reg.ASCIIDomainName() != DefaultDomainName(reg.Namespace, r.DefaultDomainSuffix) {
+		return false, nil
+	}
+	var ns corev1.Namespace
+	if err := r.Get(ctx, types.NamespacedName{Name: reg.Namespace}, &ns); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	return ns.DeletionTimestamp == nil && r.DefaultDomainNamespaceSelector.Matches(labels.Set(ns.Labels)), nil
+}
+
 func (r *CustomDomainRegistrationReconciler) registerDomain(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (registered bool, err error) {
 	var domain domainv1beta1.CustomDomain
-	err = r.Get(ctx, types.NamespacedName{Name: reg.Spec.DomainName}, &domain)
+	err = r.domainClient().Get(ctx, domainKey(reg.ASCIIDomainName()), &domain)
 	if err != nil && !apierrors.IsNotFound(err) {
 		return false, err
 	}
@@ -263,33 +996,92 @@ func (r *CustomDomainRegistrationReconciler) registerDomain(ctx context.Context,
 		return false, nil
 	}
 
-	regRef := corev1.ObjectReference{
-		APIVersion: reg.APIVersion,
-		Kind:       reg.Kind,
-		Name:       reg.Name,
-		Namespace:  reg.Namespace,
-		UID:        reg.UID,
+	regRef := domainv1beta1.CustomDomainRegistrationReference{
+		ObjectReference: corev1.ObjectReference{
+			APIVersion: reg.APIVersion,
+			Kind:       reg.Kind,
+			Name:       reg.Name,
+			Namespace:  reg.Namespace,
+			UID:        reg.UID,
+		},
+		Role:              reg.Spec.Role,
+		Cluster:           r.ClusterName,
+		LoadBalancerClass: reg.Spec.LoadBalancerClass,
+		DedicatedIP:       reg.Spec.DedicatedIP,
+	}
+	now := r.Now()
+	if r.ClusterName != "" {
+		regRef.Verified = condition.IsTrue(reg.Status.Conditions, string(domainv1beta1.RegistrationVerified))
+		regRef.LastHeartbeatTime = &now
 	}
 	if apierrors.IsNotFound(err) {
 		domain = domainv1beta1.CustomDomain{
 			ObjectMeta: metav1.ObjectMeta{
-				Name: reg.Name,
+				Namespace: DomainNamespace,
+				Name:      dnsname.ResourceName(reg.ASCIIDomainName()),
 			},
 			Spec: domainv1beta1.CustomDomainSpec{
-				Registrations: []corev1.ObjectReference{regRef},
+				Registrations: []domainv1beta1.CustomDomainRegistrationReference{regRef},
 			},
 		}
-		if err := r.Create(ctx, &domain); err != nil {
+		err := r.domainClient().Create(ctx, &domain)
+		if err == nil {
+			loggerFrom(ctx, r.Log).Info("registered to new domain", "customdomain", domain.Name)
+			r.Recorder.Eventf(reg, corev1.EventTypeNormal, EventDomainRegistered, "Registered to domain %s", domain.Name)
+			return true, nil
+		} else if !apierrors.IsAlreadyExists(err) {
 			return false, err
 		}
-	} else {
-		if !slice.ContainsObjectReference(domain.Spec.Registrations, reg) {
-			patch := client.MergeFrom(domain.DeepCopy())
-			domain.Spec.Registrations = append(domain.Spec.Registrations, regRef)
-			if err := r.Patch(ctx, &domain, patch); err != nil {
-				return false, err
+
+		// The domain is created by a concurrent registration; register to
+		// it instead.
+		metrics.RegistrationConflictRetries.WithLabelValues(registrationOpRegister).Inc()
+		err = r.domainClient().Get(ctx, domainKey(reg.ASCIIDomainName()), &domain)
+		if apierrors.IsNotFound(err) {
+			// not observed yet, retry later
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+		if domain.DeletionTimestamp != nil {
+			return false, nil
+		}
+	}
+
+	added := false
+	_, err = updateDomainRegistrations(ctx, r.domainClient(), registrationOpRegister, &domain, func(refs []domainv1beta1.CustomDomainRegistrationReference) ([]domainv1beta1.CustomDomainRegistrationReference, bool) {
+		ref := slice.FindRegistrationReference(refs, reg)
+		if ref == nil {
+			added = true
+			return append(refs, regRef), true
+		}
+		// Heartbeat is refreshed when half of the interval passed, as the
+		// registration is requeued every interval.
+		heartbeatDue := regRef.LastHeartbeatTime != nil && (ref.LastHeartbeatTime == nil ||
+			!now.Time.Before(ref.LastHeartbeatTime.Add(RemoteRegistrationHeartbeatInterval/2)))
+		if ref.Role != regRef.Role || ref.Cluster != regRef.Cluster || ref.Verified != regRef.Verified ||
+			ref.LoadBalancerClass != regRef.LoadBalancerClass || ref.DedicatedIP != regRef.DedicatedIP || heartbeatDue {
+			ref.Role = regRef.Role
+			ref.Cluster = regRef.Cluster
+			ref.Verified = regRef.Verified
+			ref.LoadBalancerClass = regRef.LoadBalancerClass
+			ref.DedicatedIP = regRef.DedicatedIP
+			if heartbeatDue {
+				ref.LastHeartbeatTime = regRef.LastHeartbeatTime
 			}
+			return refs, true
 		}
+		return refs, false
+	})
+	if apierrors.IsConflict(err) {
+		r.Recorder.Eventf(reg, corev1.EventTypeWarning, EventRegistrationConflict,
+			"Registration to domain %s conflicted with concurrent updates, retrying", domain.Name)
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	if added {
+		r.Recorder.Eventf(reg, corev1.EventTypeNormal, EventDomainRegistered, "Registered to domain %s", domain.Name)
 	}
 
 	return true, nil
@@ -297,7 +1089,7 @@ func (r *CustomDomainRegistrationReconciler) registerDomain(ctx context.Context,
 
 func (r *CustomDomainRegistrationReconciler) unregisterDomain(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (registered bool, err error) {
 	var domain domainv1beta1.CustomDomain
-	err = r.Get(ctx, types.NamespacedName{Name: reg.Spec.DomainName}, &domain)
+	err = r.domainClient().Get(ctx, domainKey(reg.ASCIIDomainName()), &domain)
 	if apierrors.IsNotFound(err) {
 		return true, nil
 	}
@@ -305,136 +1097,744 @@ func (r *CustomDomainRegistrationReconciler) unregisterDomain(ctx context.Contex
 		return false, err
 	}
 
-	if slice.ContainsObjectReference(domain.Spec.Registrations, reg) {
-		patch := client.MergeFrom(domain.DeepCopy())
-		domain.Spec.Registrations = slice.RemoveObjectReference(domain.Spec.Registrations, reg)
-		if err := r.Patch(ctx, &domain, patch); err != nil {
-			return false, err
+	removed, err := updateDomainRegistrations(ctx, r.domainClient(), registrationOpUnregister, &domain, func(refs []domainv1beta1.CustomDomainRegistrationReference) ([]domainv1beta1.CustomDomainRegistrationReference, bool) {
+		if !slice.ContainsRegistrationReference(refs, reg) {
+			return refs, false
 		}
+		return slice.RemoveRegistrationReference(refs, reg), true
+	})
+	if apierrors.IsConflict(err) {
+		r.Recorder.Eventf(reg, corev1.EventTypeWarning, EventRegistrationConflict,
+			"Release of domain %s conflicted with concurrent updates, retrying", domain.Name)
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	if removed {
+		r.Recorder.Eventf(reg, corev1.EventTypeNormal, EventDomainReleased, "Released domain %s", domain.Name)
+		r.Audit.Record(&domain, AuditDomainReleased, registrationRef(reg), "registration is deleted")
+		r.notify(reg, notification.EventReleased, "registration is deleted")
 	}
 
-	registered = slice.ContainsObjectReference(domain.Spec.Registrations, reg)
+	registered = slice.ContainsRegistrationReference(domain.Spec.Registrations, reg)
 	return !registered, nil
 }
 
-func (r *CustomDomainRegistrationReconciler) verifyDomainIfNeeded(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (requeueTime *time.Time, verified bool, err error) {
+func (r *CustomDomainRegistrationReconciler) verifyDomainIfNeeded(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (requeueTime *time.Time, verified bool, reason string, err error) {
 	var domain domainv1beta1.CustomDomain
-	err = r.Get(ctx, types.NamespacedName{Name: reg.Spec.DomainName}, &domain)
+	err = r.domainClient().Get(ctx, domainKey(reg.ASCIIDomainName()), &domain)
 	if err != nil {
-		return nil, false, err
+		return nil, false, "", err
 	}
 
 	if domain.Spec.VerificationKey == nil ||
 		domain.Status.LoadBalancer == nil ||
 		len(domain.Status.LoadBalancer.DNSRecords) == 0 {
-		return nil, false, nil
+		return nil, false, "", nil
 	}
 
-	token := r.VerificationTokenGenerator(*domain.Spec.VerificationKey, string(reg.Namespace))
-	dnsRecordName, err := verification.MakeDNSRecordName(domain.Name)
-	if err != nil {
-		return nil, false, err
+	token := r.VerificationTokenGenerator.GenerateToken(*domain.Spec.VerificationKey, verificationNonce(reg))
+	method := verification.MethodDNS
+	if reg.Spec.Verification != nil && reg.Spec.Verification.Method != "" {
+		method = verification.Method(reg.Spec.Verification.Method)
+	}
+
+	var verificationTarget string
+	switch method {
+	case verification.MethodHTTP:
+		verificationTarget = verification.MakeHTTPVerificationURL(domain.Name, token)
+		reg.Status.DNSRecords = domain.Status.LoadBalancer.DNSRecords
+		reg.Status.VerificationURL = &verificationTarget
+	case verification.MethodCNAME:
+		if r.VerificationChallengeZone == "" {
+			return nil, false, "", fmt.Errorf("CNAME verification is not configured")
+		}
+		var recordName string
+		recordName, token = verification.MakeCNAMERecord(domain.Name, verificationNonce(reg), r.VerificationChallengeZone)
+		verificationTarget = recordName
+		records := append(
+			domain.Status.LoadBalancer.DNSRecords,
+			domainv1beta1.CustomDomainDNSRecord{Name: recordName, Type: "CNAME", Value: token},
+		)
+		reg.Status.DNSRecords = records
+		reg.Status.VerificationURL = nil
+	case verification.MethodEmail:
+		if r.EmailChallenger == nil {
+			return nil, false, "", fmt.Errorf("Email verification is not configured")
+		}
+		verificationTarget = "domain contact email"
+		reg.Status.DNSRecords = domain.Status.LoadBalancer.DNSRecords
+		reg.Status.VerificationURL = nil
+	default:
+		if reg.Spec.Verification != nil && reg.Spec.Verification.RecordType == domainv1beta1.VerificationRecordTypeCNAME {
+			if r.VerificationCNAMETarget == "" {
+				return nil, false, "", fmt.Errorf("CNAME record verification is not configured")
+			}
+			method = verification.MethodDNSCNAME
+			verificationTarget = verification.MakeTokenCNAMERecordName(domain.Name, token)
+			records := append(
+				domain.Status.LoadBalancer.DNSRecords,
+				domainv1beta1.CustomDomainDNSRecord{Name: verificationTarget, Type: "CNAME", Value: r.VerificationCNAMETarget},
+			)
+			reg.Status.DNSRecords = records
+			reg.Status.VerificationURL = nil
+			break
+		}
+
+		dnsRecordName, err := verification.MakeDNSRecordName(domain.Name, r.verificationRecordPrefix(&domain))
+		if err != nil {
+			return nil, false, "", err
+		}
+		verificationTarget = dnsRecordName
+		records := append(
+			domain.Status.LoadBalancer.DNSRecords,
+			domainv1beta1.CustomDomainDNSRecord{Name: dnsRecordName, Type: "TXT", Value: token},
+		)
+		reg.Status.DNSRecords = records
+		reg.Status.VerificationURL = nil
 	}
-	records := append(
-		domain.Status.LoadBalancer.DNSRecords,
-		domainv1beta1.CustomDomainDNSRecord{Name: dnsRecordName, Type: "TXT", Value: token},
-	)
-	reg.Status.DNSRecords = records
 
+	isDefaultDomain, err := r.isDefaultDomain(ctx, reg)
+	if err != nil {
+		return nil, false, "", err
+	}
 	currentVerified := false
 	for _, cond := range reg.Status.Conditions {
 		if cond.Type == string(domainv1beta1.RegistrationVerified) {
-			currentVerified = cond.Status == metav1.ConditionTrue
+			// Default domain of deselected namespace is no longer verified
+			currentVerified = cond.Status == metav1.ConditionTrue &&
+				(isDefaultDomain || cond.Reason != domainv1beta1.ReasonDefaultDomain)
 			break
 		}
 	}
 
+	if isDefaultDomain {
+		if !currentVerified {
+			r.Recorder.Eventf(reg, corev1.EventTypeNormal, EventVerificationSucceeded, "Verified default domain of namespace")
+			r.Audit.Record(&domain, AuditVerificationSucceeded, registrationRef(reg), "verified default domain of namespace")
+		}
+		reg.Status.VerificationFailureCount = 0
+		reg.Status.ConclusiveFailureCount = 0
+		reg.Status.VerificationFailure = nil
+		return nil, true, domainv1beta1.ReasonDefaultDomain, nil
+	}
+
+	if isAttested(reg) {
+		if !currentVerified {
+			r.Recorder.Eventf(reg, corev1.EventTypeNormal, EventVerificationSucceeded, "Verified domain by attestation of %s", attesterName(reg))
+			r.Audit.Record(&domain, AuditVerificationSucceeded, registrationRef(reg), fmt.Sprintf("verified domain by attestation of %s", attesterName(reg)))
+		}
+		reg.Status.VerificationFailureCount = 0
+		reg.Status.ConclusiveFailureCount = 0
+		reg.Status.VerificationFailure = nil
+		return nil, true, domainv1beta1.ReasonExternalAttestation, nil
+	}
+
+	if r.InheritParentVerification {
+		parent, err := r.verifiedParentDomain(ctx, reg)
+		if err != nil {
+			return nil, currentVerified, "", err
+		}
+		if parent != "" {
+			if !currentVerified {
+				r.Recorder.Eventf(reg, corev1.EventTypeNormal, EventVerificationSucceeded, "Verified domain by ownership of parent domain %s", parent)
+				r.Audit.Record(&domain, AuditVerificationSucceeded, registrationRef(reg), fmt.Sprintf("verified domain by ownership of parent domain %s", parent))
+			}
+			reg.Status.VerificationFailureCount = 0
+			reg.Status.ConclusiveFailureCount = 0
+			reg.Status.VerificationFailure = nil
+			return nil, true, domainv1beta1.ReasonParentDomainVerified, nil
+		}
+	}
+
+	if method == verification.MethodEmail {
+		// Verified by attestation when the confirmation link is opened.
+		return r.sendEmailChallengeIfNeeded(ctx, reg, &domain)
+	}
+
 	now := r.Now()
 	now = metav1.Unix(now.Unix(), 0) // truncate to seconds
-	if reg.Spec.VerifyAt == nil ||
-		(reg.Status.LastVerificationTime != nil && reg.Status.LastVerificationTime.After(reg.Spec.VerifyAt.Time)) {
-		return nil, currentVerified, nil
+	nextVerifyTime := r.nextVerificationTime(reg, currentVerified)
+	if nextVerifyTime == nil {
+		return nil, currentVerified, "", nil
 	}
-	verifyTime := reg.Spec.VerifyAt.Time
+	verifyTime := *nextVerifyTime
 	if reg.Status.LastVerificationTime != nil &&
 		verifyTime.Before(reg.Status.LastVerificationTime.Add(VerificationCooldown)) {
 		// Too quick, apply cooldown period
 		verifyTime = reg.Status.LastVerificationTime.Add(VerificationCooldown)
 	}
 	if !now.After(verifyTime) {
-		return &verifyTime, currentVerified, nil
+		loggerFrom(ctx, r.Log).V(1).Info("verification is not due", "verifyTime", verifyTime)
+		return &verifyTime, currentVerified, "", nil
 	}
 
-	err = func() error {
+	verify := func(token string) error {
 		verifyCtx, cancel := context.WithTimeout(ctx, VerificationTimeout)
 		defer cancel()
-		return r.DomainVerifier(verifyCtx, domain.Name, token)
-	}()
+		verifyCtx = verification.WithNameservers(verifyCtx, domain.Spec.VerificationNameservers)
+		verifyCtx = verification.WithRecordPrefix(verifyCtx, r.verificationRecordPrefix(&domain))
+		return r.DomainVerifier(verifyCtx, method, domain.Name, token)
+	}
+	err = verify(token)
+	if err != nil && method != verification.MethodCNAME {
+		// Accept tokens of rotated secrets, and tokens of previous key during
+		// grace period of key rotation
+		for _, acceptedToken := range r.acceptedTokens(&domain, reg) {
+			if acceptedToken != token && verify(acceptedToken) == nil {
+				err = nil
+				break
+			}
+		}
+	}
+	metrics.RecordVerification(err == nil, verification.FailureReason(err))
+	loggerFrom(ctx, r.Log).V(1).Info("verified domain", "method", method, "target", verificationTarget, "verified", err == nil, "error", err)
+	if err != nil {
+		r.Recorder.Eventf(reg, corev1.EventTypeWarning, EventVerificationFailed, "Verification of %s failed: %s", verificationTarget, err.Error())
+	} else if !currentVerified {
+		r.Recorder.Eventf(reg, corev1.EventTypeNormal, EventVerificationSucceeded, "Verified domain using %s", verificationTarget)
+		r.Audit.Record(&domain, AuditVerificationSucceeded, registrationRef(reg), fmt.Sprintf("verified domain using %s", verificationTarget))
+	}
 
-	// TODO(domain): re-verify periodically
+	reg.Status.LastVerificationTime = &now
+	reg.Status.VerificationView = r.VerificationView
+	if err == nil {
+		reg.Status.VerificationFailureCount = 0
+		reg.Status.ConclusiveFailureCount = 0
+		reg.Status.VerificationFailure = nil
+	} else {
+		reg.Status.VerificationFailureCount++
+		if verification.IsConclusive(err) {
+			reg.Status.ConclusiveFailureCount++
+		}
+		reg.Status.VerificationFailure = makeVerificationFailure(err)
+	}
+	return r.nextVerificationTime(reg, err == nil), err == nil, "", err
+}
+
+// sendEmailChallengeIfNeeded sends confirmation link to contacts of domain,
+// if no valid link is sent for current generation of registration.
+func (r *CustomDomainRegistrationReconciler) sendEmailChallengeIfNeeded(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration, domain *domainv1beta1.CustomDomain) (requeueTime *time.Time, verified bool, reason string, err error) {
+	now := r.Now()
+	now = metav1.Unix(now.Unix(), 0) // truncate to seconds
+	if c := reg.Status.EmailChallenge; c != nil {
+		sendTime := c.SentTime.Add(EmailChallengeInterval)
+		if c.ObservedGeneration == reg.Generation && c.ExpiryTime.After(sendTime) {
+			sendTime = c.ExpiryTime.Time
+		}
+		if now.Time.Before(sendTime) {
+			return &sendTime, false, "", nil
+		}
+	}
+	if reg.Status.LastVerificationTime != nil && reg.Status.VerificationFailureCount > 0 {
+		retryTime := reg.Status.LastVerificationTime.Add(verificationBackoff(reg.Status.VerificationFailureCount))
+		if now.Time.Before(retryTime) {
+			return &retryTime, false, "", nil
+		}
+	}
+
+	expiry := now.Add(EmailChallengeLinkTTL)
+	recipients, err := r.EmailChallenger.SendChallenge(ctx, *domain.Spec.VerificationKey, verification.EmailConfirmation{
+		Namespace:  reg.Namespace,
+		Name:       reg.Name,
+		Domain:     reg.ASCIIDomainName(),
+		Generation: reg.Generation,
+		Expiry:     expiry,
+	}, reg.IsApproved())
+	masked := make([]string, len(recipients))
+	for i, email := range recipients {
+		masked[i] = verification.MaskEmail(email)
+	}
+	if len(recipients) > 0 {
+		reg.Status.EmailChallenge = &domainv1beta1.CustomDomainEmailChallengeStatus{
+			Recipients:         masked,
+			SentTime:           now,
+			ExpiryTime:         metav1.NewTime(expiry),
+			ObservedGeneration: reg.Generation,
+		}
+		r.Recorder.Eventf(reg, corev1.EventTypeNormal, EventVerificationEmailSent, "Sent confirmation email to %s", strings.Join(masked, ", "))
+	}
 
 	reg.Status.LastVerificationTime = &now
-	return nil, err == nil, err
+	if err != nil {
+		loggerFrom(ctx, r.Log).V(1).Info("failed to send confirmation email", "error", err)
+		r.Recorder.Eventf(reg, corev1.EventTypeWarning, EventVerificationFailed, "Sending confirmation email failed: %s", err.Error())
+		reg.Status.VerificationFailureCount++
+		reg.Status.VerificationFailure = makeVerificationFailure(err)
+		retryTime := now.Add(verificationBackoff(reg.Status.VerificationFailureCount))
+		return &retryTime, false, "", err
+	}
+	reg.Status.VerificationFailureCount = 0
+	reg.Status.VerificationFailure = nil
+	return &expiry, false, "", nil
 }
 
-func (r *CustomDomainRegistrationReconciler) checkAcceptance(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (accepted bool, err error) {
-	var domain domainv1beta1.CustomDomain
-	err = r.Get(ctx, types.NamespacedName{Name: reg.Spec.DomainName}, &domain)
+func (r *CustomDomainRegistrationReconciler) verificationRecordPrefix(domain *domainv1beta1.CustomDomain) string {
+	if domain.Spec.VerificationRecordPrefix != nil {
+		return *domain.Spec.VerificationRecordPrefix
+	}
+	if r.VerificationRecordPrefix != "" {
+		return r.VerificationRecordPrefix
+	}
+	return verification.DefaultDNSRecordPrefix
+}
+
+func (r *CustomDomainRegistrationReconciler) acceptedTokens(domain *domainv1beta1.CustomDomain, reg *domainv1beta1.CustomDomainRegistration) []string {
+	nonce := verificationNonce(reg)
+	tokens := r.VerificationTokenGenerator.AcceptedTokens(*domain.Spec.VerificationKey, nonce)
+	if domain.Spec.PreviousVerificationKey != nil {
+		tokens = append(tokens, r.VerificationTokenGenerator.AcceptedTokens(*domain.Spec.PreviousVerificationKey, nonce)...)
+	}
+	return tokens
+}
+
+// verifiedParentDomain returns a parent domain of the registration that is
+// verified by another registration in the same namespace, or empty string if
+// none. Parent domains are looked up until the registrable domain.
+func (r *CustomDomainRegistrationReconciler) verifiedParentDomain(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (string, error) {
+	name := reg.ASCIIDomainName()
+	rootDomain, err := publicsuffix.EffectiveTLDPlusOne(dnsname.TrimWildcard(name))
 	if err != nil {
-		return false, err
+		return "", nil
+	}
+
+	var candidates []string
+	if dnsname.IsWildcard(name) {
+		candidates = append(candidates, dnsname.TrimWildcard(name))
+	}
+	for parent := dnsname.Parent(dnsname.TrimWildcard(name)); strings.HasSuffix(parent, rootDomain); parent = dnsname.Parent(parent) {
+		candidates = append(candidates, parent)
 	}
 
-	accepted = domain.Spec.OwnerApp != nil && *domain.Spec.OwnerApp == reg.Namespace
-	return accepted, nil
+	for _, candidate := range candidates {
+		var list domainv1beta1.CustomDomainRegistrationList
+		err := r.List(ctx, &list,
+			client.InNamespace(reg.Namespace),
+			client.MatchingFields{domainNameIndex: candidate},
+		)
+		if err != nil {
+			return "", err
+		}
+		for _, parentReg := range list.Items {
+			if parentReg.UID == reg.UID || parentReg.DeletionTimestamp != nil {
+				continue
+			}
+			if condition.IsTrue(parentReg.Status.Conditions, string(domainv1beta1.RegistrationVerified)) {
+				return candidate, nil
+			}
+		}
+	}
+	return "", nil
 }
 
-func (r *CustomDomainRegistrationReconciler) updateIngress(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (bool, error) {
-	ingress, err := r.IngressProvider.MakeIngress(reg)
+// isAttested returns whether the current generation of registration is
+// attested by an external verifier.
+func isAttested(reg *domainv1beta1.CustomDomainRegistration) bool {
+	a := reg.Status.Attestation
+	return a != nil && a.ObservedGeneration == reg.Generation
+}
+
+func attesterName(reg *domainv1beta1.CustomDomainRegistration) string {
+	if reg.Status.Attestation.Attester == "" {
+		return "external verifier"
+	}
+	return reg.Status.Attestation.Attester
+}
+
+// registrationExpiry returns the time that the registration is deleted, or
+// nil if it does not expire. Registrations of verified domains do not
+// expire.
+func registrationExpiry(reg *domainv1beta1.CustomDomainRegistration) *time.Time {
+	if condition.IsTrue(reg.Status.Conditions, string(domainv1beta1.RegistrationVerified)) {
+		return nil
+	}
+
+	var expiry *time.Time
+	if reg.Spec.ExpiresAt != nil {
+		t := reg.Spec.ExpiresAt.Time
+		expiry = &t
+	}
+	if ttl := reg.Spec.TTLSecondsAfterVerificationFailure; ttl != nil {
+		cond := condition.Lookup(reg.Status.Conditions, string(domainv1beta1.RegistrationFailed))
+		if cond != nil && cond.Status == metav1.ConditionTrue {
+			t := cond.LastTransitionTime.Add(time.Duration(*ttl) * time.Second)
+			if expiry == nil || t.Before(*expiry) {
+				expiry = &t
+			}
+		}
+	}
+	return expiry
+}
+
+// verificationDeadline returns the time that the domain must be verified
+// before, or nil if no deadline is set. The deadline starts at creation of
+// registration, and restarts when the domain becomes unverified.
+func verificationDeadline(reg *domainv1beta1.CustomDomainRegistration) *time.Time {
+	if reg.Spec.Verification == nil || reg.Spec.Verification.DeadlineSeconds == nil {
+		return nil
+	}
+
+	since := reg.CreationTimestamp.Time
+	cond := condition.Lookup(reg.Status.Conditions, string(domainv1beta1.RegistrationVerified))
+	if cond != nil && cond.Status == metav1.ConditionTrue {
+		// Domain is verified before this reconciliation; the deadline
+		// restarts when it becomes unverified.
+		return nil
+	}
+	if cond != nil && cond.LastTransitionTime.Time.After(since) {
+		since = cond.LastTransitionTime.Time
+	}
+
+	verifyBy := since.Add(time.Duration(*reg.Spec.Verification.DeadlineSeconds) * time.Second)
+	return &verifyBy
+}
+
+func makeVerificationFailure(err error) *domainv1beta1.CustomDomainVerificationFailure {
+	failure := &domainv1beta1.CustomDomainVerificationFailure{
+		Reason: verification.FailureReason(err),
+	}
+	if details := verification.GetFailureDetails(err); details != nil {
+		failure.RecordName = details.RecordName
+		failure.ExpectedValue = details.ExpectedValue
+		failure.ObservedValues = details.ObservedValues
+		failure.Resolver = details.Resolver
+	}
+	return failure
+}
+
+func (r *CustomDomainRegistrationReconciler) nextVerificationTime(reg *domainv1beta1.CustomDomainRegistration, verified bool) *time.Time {
+	var next *time.Time
+	if reg.Spec.VerifyAt != nil &&
+		(reg.Status.LastVerificationTime == nil || !reg.Status.LastVerificationTime.After(reg.Spec.VerifyAt.Time)) {
+		t := reg.Spec.VerifyAt.Time
+		next = &t
+	}
+	if t, ok := verifyNowTime(reg); ok &&
+		(reg.Status.LastVerificationTime == nil || !reg.Status.LastVerificationTime.After(t)) {
+		if next == nil || t.Before(*next) {
+			next = &t
+		}
+	}
+
+	// Re-verify verified domain periodically, so that ownership is revoked
+	// when the verification DNS record is removed.
+	interval := ReverificationInterval
+	if reg.Spec.ReverificationInterval != nil {
+		interval = reg.Spec.ReverificationInterval.Duration
+	}
+	if verified && interval > 0 && reg.Status.LastVerificationTime != nil {
+		t := reg.Status.LastVerificationTime.Add(interval)
+		if next == nil || t.Before(*next) {
+			next = &t
+		}
+	}
+
+	// Retry failed verification with exponential backoff.
+	if !verified && reg.Status.VerificationFailureCount > 0 && reg.Status.LastVerificationTime != nil {
+		t := reg.Status.LastVerificationTime.Add(verificationBackoff(reg.Status.VerificationFailureCount))
+		if next == nil || t.Before(*next) {
+			next = &t
+		}
+	}
+
+	return next
+}
+
+// verifyNowTime returns the time verification is requested by annotation.
+func verifyNowTime(reg *domainv1beta1.CustomDomainRegistration) (time.Time, bool) {
+	value, ok := reg.Annotations[api.VerifyNowAnnotation]
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, value)
 	if err != nil {
-		return false, err
+		return time.Time{}, false
 	}
+	return t, true
+}
 
-	existingIngress := &networkingv1beta1.Ingress{}
-	if err = r.Get(ctx, types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}, existingIngress); err != nil {
-		if !apierrors.IsNotFound(err) {
-			return false, err
+// checkCertificateExpiry records expiry of TLS certificate of the registration
+// in status, and reports whether it expires within CertificateExpiryWarningPeriod.
+func (r *CustomDomainRegistrationReconciler) checkCertificateExpiry(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (notAfter *time.Time, expiringSoon bool, err error) {
+	if reg.Status.CertSecretName == nil {
+		reg.Status.TLS = nil
+		return nil, false, nil
+	}
+
+	cert, err := tls.LoadCertificate(ctx, r.Client, reg.Namespace, *reg.Status.CertSecretName)
+	if err != nil {
+		return nil, false, err
+	}
+	if cert == nil {
+		reg.Status.TLS = nil
+		return nil, false, nil
+	}
+
+	issuer := cert.Issuer.CommonName
+	if issuer == "" {
+		issuer = cert.Issuer.String()
+	}
+	certNotAfter := metav1.NewTime(cert.NotAfter)
+	reg.Status.TLS = &domainv1beta1.CustomDomainTLSStatus{
+		NotAfter: &certNotAfter,
+		Issuer:   issuer,
+	}
+
+	expiringSoon = !r.Now().Time.Before(cert.NotAfter.Add(-CertificateExpiryWarningPeriod))
+	return &cert.NotAfter, expiringSoon, nil
+}
+
+// checkEndpointHealthIfNeeded probes the endpoint of the domain at most once
+// per health check interval. It returns the time of next probe and the
+// EndpointHealthy condition.
+func (r *CustomDomainRegistrationReconciler) checkEndpointHealthIfNeeded(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (*time.Time, api.Condition) {
+	hc := reg.Spec.HealthCheck
+	interval := HealthCheckInterval
+	if hc.Interval != nil && hc.Interval.Duration > 0 {
+		interval = hc.Interval.Duration
+	}
+
+	now := r.Now()
+	now = metav1.Unix(now.Unix(), 0) // truncate to seconds
+	if s := reg.Status.HealthCheck; s != nil && s.LastProbeTime != nil {
+		probeTime := s.LastProbeTime.Add(interval)
+		cond := condition.Lookup(reg.Status.Conditions, string(domainv1beta1.RegistrationEndpointHealthy))
+		if cond != nil && now.Time.Before(probeTime) {
+			return &probeTime, *cond
 		}
 	}
 
-	if apierrors.IsNotFound(err) {
-		if err = r.Create(ctx, ingress); err != nil {
-			return false, err
+	scheme := domainv1beta1.HealthCheckSchemeHTTPS
+	if hc.Scheme != "" {
+		scheme = hc.Scheme
+	}
+	url := healthcheck.MakeURL(reg.ASCIIDomainName(), string(scheme), hc.Path)
+
+	probeCtx, cancel := context.WithTimeout(ctx, HealthCheckTimeout)
+	defer cancel()
+	result, err := r.EndpointProber(probeCtx, url)
+
+	status := &domainv1beta1.CustomDomainHealthCheckStatus{LastProbeTime: &now}
+	if result != nil {
+		status.StatusCode = result.StatusCode
+		status.LatencyMilliseconds = result.Latency.Milliseconds()
+	}
+	reg.Status.HealthCheck = status
+
+	nextProbeTime := now.Add(interval)
+	if err != nil {
+		return &nextProbeTime, api.Condition{
+			Type:    string(domainv1beta1.RegistrationEndpointHealthy),
+			Status:  metav1.ConditionFalse,
+			Reason:  domainv1beta1.ReasonProbeFailed,
+			Message: fmt.Sprintf("GET %s: %s", url, err),
 		}
-	} else {
-		existingIngress = existingIngress.DeepCopy()
-		existingIngress.Labels = ingress.Labels
-		existingIngress.Annotations = ingress.Annotations
-		existingIngress.Spec = ingress.Spec
-		if err = r.Update(ctx, existingIngress); err != nil {
-			return false, err
+	}
+	return &nextProbeTime, api.Condition{
+		Type:   string(domainv1beta1.RegistrationEndpointHealthy),
+		Status: metav1.ConditionTrue,
+	}
+}
+
+// certificatePendingCondition returns the CertReady condition of
+// registration without certificate, with the reason of pending issuance if
+// the TLS provider reports it, e.g. failures of cert-manager Certificate.
+func (r *CustomDomainRegistrationReconciler) certificatePendingCondition(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) api.Condition {
+	cond := api.Condition{
+		Type:   string(domainv1beta1.RegistrationCertReady),
+		Status: metav1.ConditionFalse,
+	}
+	reporter, ok := r.TLSProvider.(tls.CertificateStatusReporter)
+	if !ok {
+		return cond
+	}
+	status, err := reporter.CertificateStatus(ctx, reg)
+	if err != nil {
+		cond.Status = metav1.ConditionUnknown
+		cond.Message = err.Error()
+	} else if status != nil && status.Status != metav1.ConditionTrue {
+		cond.Reason = status.Reason
+		cond.Message = status.Message
+	}
+	return cond
+}
+
+// dedicatedIPCondition returns the condition of dedicated IP address
+// allocation of the domain, or nil if the domain is not provisioned yet.
+func (r *CustomDomainRegistrationReconciler) dedicatedIPCondition(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (*api.Condition, error) {
+	var domain domainv1beta1.CustomDomain
+	err := r.domainClient().Get(ctx, domainKey(reg.ASCIIDomainName()), &domain)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	cond := condition.Lookup(domain.Status.Conditions, string(domainv1beta1.DomainDedicatedIPAllocated))
+	if cond == nil {
+		return nil, nil
+	}
+	return &api.Condition{
+		Type:    string(domainv1beta1.RegistrationDedicatedIPAllocated),
+		Status:  cond.Status,
+		Reason:  cond.Reason,
+		Message: cond.Message,
+	}, nil
+}
+
+// checkDNSConfigIfNeeded checks whether DNS records of the domain point to
+// the load balancer. The check is performed at most once per DNSCheckInterval,
+// and is independent of ownership verification.
+func (r *CustomDomainRegistrationReconciler) checkDNSConfigIfNeeded(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (requeueTime *time.Time, configured bool, err error) {
+	var domain domainv1beta1.CustomDomain
+	err = r.domainClient().Get(ctx, domainKey(reg.ASCIIDomainName()), &domain)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if domain.Status.LoadBalancer == nil || len(domain.Status.LoadBalancer.DNSRecords) == 0 {
+		reg.Status.DNSCheck = nil
+		return nil, false, nil
+	}
+
+	currentConfigured := false
+	if cond := condition.Lookup(reg.Status.Conditions, string(domainv1beta1.RegistrationDNSConfigured)); cond != nil {
+		currentConfigured = cond.Status == metav1.ConditionTrue
+	}
+
+	now := r.Now()
+	now = metav1.Unix(now.Unix(), 0) // truncate to seconds
+	if reg.Status.DNSCheck != nil && reg.Status.DNSCheck.LastCheckTime != nil {
+		checkTime := reg.Status.DNSCheck.LastCheckTime.Add(DNSCheckInterval)
+		if now.Time.Before(checkTime) {
+			return &checkTime, currentConfigured, nil
 		}
 	}
 
-	return true, nil
+	records := make([]verification.DNSRecord, len(domain.Status.LoadBalancer.DNSRecords))
+	for i, record := range domain.Status.LoadBalancer.DNSRecords {
+		records[i] = verification.DNSRecord{Name: record.Name, Type: record.Type, Value: record.Value}
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, VerificationTimeout)
+	defer cancel()
+	configured, results, err := r.DNSConfigChecker(checkCtx, domain.Name, records)
+
+	status := &domainv1beta1.CustomDomainDNSCheckStatus{LastCheckTime: &now}
+	for _, result := range results {
+		status.Records = append(status.Records, domainv1beta1.CustomDomainDNSRecordStatus{
+			Name:           result.Name,
+			Type:           result.Type,
+			Value:          result.Value,
+			ObservedValues: result.ObservedValues,
+			Configured:     result.Configured,
+		})
+	}
+	reg.Status.DNSCheck = status
+
+	nextCheckTime := now.Add(DNSCheckInterval)
+	return &nextCheckTime, configured, err
 }
 
-func (r *CustomDomainRegistrationReconciler) deleteIngress(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (bool, error) {
-	ingress, err := r.IngressProvider.MakeIngress(reg)
+func (r *CustomDomainRegistrationReconciler) checkAcceptance(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration, verified bool) (accepted bool, rejected bool, err error) {
+	var domain domainv1beta1.CustomDomain
+	err = r.domainClient().Get(ctx, domainKey(reg.ASCIIDomainName()), &domain)
 	if err != nil {
-		return false, err
+		return false, false, err
 	}
 
-	existingIngress := &networkingv1beta1.Ingress{}
-	if err = r.Get(ctx, types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}, existingIngress); err != nil {
-		if apierrors.IsNotFound(err) {
-			return true, nil
+	if domain.Spec.OwnerApp == nil {
+		return false, false, nil
+	}
+	if !reg.IsPrimary() {
+		// Secondary registration shares the domain with primary registration
+		// once verified.
+		return verified, false, nil
+	}
+	// Apps of same namespace in other workload clusters are different apps
+	accepted = domain.IsOwnedBy(r.ClusterName, reg.Namespace) &&
+		(domain.Spec.OwnerRef == nil || domain.Spec.OwnerRef.UID == reg.UID)
+	return accepted, !accepted, nil
+}
+
+// checkTransfer returns the state of ownership transfer requested by the
+// registration: pending while the registration still owns the domain, and
+// accepted once the domain is owned by the transfer target. Both are false
+// if no transfer is requested, or the domain is owned by another
+// registration.
+func (r *CustomDomainRegistrationReconciler) checkTransfer(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (pending bool, accepted bool, err error) {
+	namespace, name, ok := reg.TransferTarget()
+	if !ok {
+		return false, false, nil
+	}
+
+	var domain domainv1beta1.CustomDomain
+	err = r.domainClient().Get(ctx, domainKey(reg.ASCIIDomainName()), &domain)
+	if err != nil {
+		return false, false, err
+	}
+
+	owner := domain.Spec.OwnerRef
+	if owner == nil {
+		return false, false, nil
+	}
+	if owner.Namespace == namespace && owner.Name == name && domain.Spec.OwnerCluster == r.ClusterName {
+		return false, true, nil
+	}
+	pending = owner.UID == reg.UID
+	return pending, false, nil
+}
+
+// verificationNonce returns the nonce of verification token of registration.
+// Secondary registrations use a distinct token, so that changing role requires
+// verifying the domain again.
+func verificationNonce(reg *domainv1beta1.CustomDomainRegistration) string {
+	if reg.IsPrimary() {
+		return reg.Namespace
+	}
+	return fmt.Sprintf("%s/%s", reg.Namespace, strings.ToLower(string(reg.Spec.Role)))
+}
+
+func (r *CustomDomainRegistrationReconciler) checkConflicts(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (conflicts []string, err error) {
+	domains, err := r.overlappingDomains(ctx, reg.ASCIIDomainName())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, d := range domains {
+		if d.Spec.OwnerApp != nil && !d.IsOwnedBy(r.ClusterName, reg.Namespace) {
+			conflicts = append(conflicts, dnsname.DomainName(d.Name))
 		}
-		return false, err
 	}
+	return conflicts, nil
+}
 
-	if err = r.Delete(ctx, existingIngress); err != nil {
-		return false, err
+// overlappingDomains returns the wildcard domain covering the domain, or the
+// sub-domains covered by the wildcard domain.
+func (r *CustomDomainRegistrationReconciler) overlappingDomains(ctx context.Context, domainName string) ([]domainv1beta1.CustomDomain, error) {
+	if !dnsname.IsWildcard(domainName) {
+		parent := dnsname.Parent(domainName)
+		if parent == "" {
+			return nil, nil
+		}
+
+		var d domainv1beta1.CustomDomain
+		err := r.domainClient().Get(ctx, domainKey("*."+parent), &d)
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+		return []domainv1beta1.CustomDomain{d}, nil
 	}
 
-	return true, nil
+	var list domainv1beta1.CustomDomainList
+	err := r.domainClient().List(ctx, &list,
+		client.InNamespace(DomainNamespace),
+		client.MatchingFields{parentDomainIndex: dnsname.TrimWildcard(domainName)},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
 }
@@ -0,0 +1,269 @@
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/util/condition"
+)
+
+var _ = Describe("Custom Domain Registration", func() {
+	const timeout = time.Second * 10
+	const interval = time.Millisecond * 100
+
+	ctx := context.Background()
+
+	conditionStatus := func(key types.NamespacedName, condType domainv1beta1.CustomDomainRegistrationConditionType) func() metav1.ConditionStatus {
+		return func() metav1.ConditionStatus {
+			reg := &domainv1beta1.CustomDomainRegistration{}
+			Expect(k8sClient.Get(ctx, key, reg)).To(Succeed())
+			cond := condition.Lookup(reg.Status.Conditions, string(condType))
+			if cond == nil {
+				return ""
+			}
+			return cond.Status
+		}
+	}
+
+	ingressExists := func(key types.NamespacedName) func() bool {
+		return func() bool {
+			err := k8sClient.Get(ctx, key, &networkingv1beta1.Ingress{})
+			if apierrors.IsNotFound(err) {
+				return false
+			}
+			Expect(err).NotTo(HaveOccurred())
+			return true
+		}
+	}
+
+	// publishVerificationRecord publishes the verification TXT record of the
+	// registration in the DNS server.
+	publishVerificationRecord := func(key types.NamespacedName) {
+		Eventually(func() error {
+			reg := &domainv1beta1.CustomDomainRegistration{}
+			Expect(k8sClient.Get(ctx, key, reg)).To(Succeed())
+			for _, record := range reg.Status.DNSRecords {
+				if record.Type == "TXT" {
+					dnsServer.SetTXT(record.Name, record.Value)
+					return nil
+				}
+			}
+			return fmt.Errorf("verification record is not available: %#v", reg.Status.DNSRecords)
+		}, timeout, interval).Should(Succeed())
+	}
+
+	requestVerification := func(key types.NamespacedName) {
+		Eventually(func() error {
+			reg := &domainv1beta1.CustomDomainRegistration{}
+			Expect(k8sClient.Get(ctx, key, reg)).To(Succeed())
+			verifyAt := metav1.Unix(metav1.Now().Unix(), 0)
+			reg.Spec.VerifyAt = &verifyAt
+			return k8sClient.Update(ctx, reg)
+		}, timeout, interval).Should(Succeed())
+	}
+
+	// createServingRegistration creates a registration with its domain
+	// verified, and waits until the registration serves the domain.
+	createServingRegistration := func(reg *domainv1beta1.CustomDomainRegistration) types.NamespacedName {
+		key := types.NamespacedName{Namespace: reg.Namespace, Name: reg.Name}
+		if reg.Spec.DomainConfig.BackendServiceName == "" {
+			reg.Spec.DomainConfig = domainv1beta1.CustomDomainConfig{
+				BackendServiceName: "app",
+				BackendServicePort: 80,
+			}
+		}
+		Expect(k8sClient.Create(ctx, reg)).To(Succeed())
+		publishVerificationRecord(key)
+		requestVerification(key)
+
+		Eventually(conditionStatus(key, domainv1beta1.RegistrationIngressReady), timeout, interval).Should(Equal(metav1.ConditionTrue))
+		Eventually(conditionStatus(key, domainv1beta1.RegistrationCertReady), timeout, interval).Should(Equal(metav1.ConditionTrue))
+		Expect(ingressExists(key)()).To(BeTrue())
+		return key
+	}
+
+	newRegistration := func(namespace, domain string) *domainv1beta1.CustomDomainRegistration {
+		return &domainv1beta1.CustomDomainRegistration{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      domain,
+			},
+			Spec: domainv1beta1.CustomDomainRegistrationSpec{
+				DomainName: domain,
+			},
+		}
+	}
+
+	// deleteRegistrations deletes the registrations, and waits until they
+	// and their domains are cleaned up.
+	deleteRegistrations := func(keys ...types.NamespacedName) {
+		for _, key := range keys {
+			reg := &domainv1beta1.CustomDomainRegistration{}
+			Expect(k8sClient.Get(ctx, key, reg)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, reg)).To(Succeed())
+		}
+		for _, key := range keys {
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, key, &domainv1beta1.CustomDomainRegistration{})
+				return apierrors.IsNotFound(err)
+			}, timeout, interval).Should(BeTrue())
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: key.Name}, &domainv1beta1.CustomDomain{})
+				return apierrors.IsNotFound(err)
+			}, timeout, interval).Should(BeTrue())
+		}
+	}
+
+	// expectReleased expects the registration blocked from serving its
+	// domain releases routing and TLS, and keeps its verification status.
+	expectReleased := func(key types.NamespacedName) {
+		Eventually(conditionStatus(key, domainv1beta1.RegistrationAccepted), timeout, interval).Should(Equal(metav1.ConditionFalse))
+		Eventually(conditionStatus(key, domainv1beta1.RegistrationIngressReady), timeout, interval).Should(Equal(metav1.ConditionFalse))
+		Eventually(conditionStatus(key, domainv1beta1.RegistrationCertReady), timeout, interval).Should(Equal(metav1.ConditionFalse))
+		Eventually(ingressExists(key), timeout, interval).Should(BeFalse())
+		Expect(conditionStatus(key, domainv1beta1.RegistrationVerified)()).To(Equal(metav1.ConditionTrue))
+
+		reg := &domainv1beta1.CustomDomainRegistration{}
+		Expect(k8sClient.Get(ctx, key, reg)).To(Succeed())
+		Expect(reg.Status.CertSecretName).To(BeNil())
+	}
+
+	Context("Blocked registrations", func() {
+		It("Should release registrations exceeding lowered quota", func() {
+			first := createServingRegistration(newRegistration("quota", "quota-first.test"))
+			second := createServingRegistration(newRegistration("quota", "quota-second.test"))
+
+			maxRegistrations := 1
+			quota := &domainv1beta1.DomainQuota{
+				ObjectMeta: metav1.ObjectMeta{Name: "quota-test"},
+				Spec: domainv1beta1.DomainQuotaSpec{
+					Namespaces:       []string{"quota"},
+					MaxRegistrations: &maxRegistrations,
+				},
+			}
+			Expect(k8sClient.Create(ctx, quota)).To(Succeed())
+
+			Eventually(conditionStatus(second, domainv1beta1.RegistrationQuotaExceeded), timeout, interval).Should(Equal(metav1.ConditionTrue))
+			expectReleased(second)
+			// Registration created earlier is within quota
+			Expect(conditionStatus(first, domainv1beta1.RegistrationQuotaExceeded)()).To(Equal(metav1.ConditionFalse))
+			Expect(conditionStatus(first, domainv1beta1.RegistrationIngressReady)()).To(Equal(metav1.ConditionTrue))
+
+			Expect(k8sClient.Delete(ctx, quota)).To(Succeed())
+			Eventually(conditionStatus(second, domainv1beta1.RegistrationIngressReady), timeout, interval).Should(Equal(metav1.ConditionTrue))
+
+			deleteRegistrations(first, second)
+		})
+
+		It("Should release registrations violating added policy", func() {
+			allowed := createServingRegistration(newRegistration("policy", "policy-allowed.test"))
+			denied := createServingRegistration(newRegistration("policy", "policy-denied.test"))
+
+			policy := &domainv1beta1.DomainPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "policy-test"},
+				Spec: domainv1beta1.DomainPolicySpec{
+					Namespaces:   []string{"policy"},
+					DenyPatterns: []string{`^policy-denied\.test$`},
+				},
+			}
+			Expect(k8sClient.Create(ctx, policy)).To(Succeed())
+
+			Eventually(conditionStatus(denied, domainv1beta1.RegistrationPolicyViolation), timeout, interval).Should(Equal(metav1.ConditionTrue))
+			expectReleased(denied)
+			Expect(conditionStatus(allowed, domainv1beta1.RegistrationPolicyViolation)()).To(Equal(metav1.ConditionFalse))
+			Expect(conditionStatus(allowed, domainv1beta1.RegistrationIngressReady)()).To(Equal(metav1.ConditionTrue))
+
+			Expect(k8sClient.Delete(ctx, policy)).To(Succeed())
+			Eventually(conditionStatus(denied, domainv1beta1.RegistrationIngressReady), timeout, interval).Should(Equal(metav1.ConditionTrue))
+
+			deleteRegistrations(allowed, denied)
+		})
+
+		It("Should release registrations of domains reserved afterwards", func() {
+			key := createServingRegistration(newRegistration("reservation", "reserved.test"))
+
+			reservation := &domainv1beta1.DomainReservation{
+				ObjectMeta: metav1.ObjectMeta{Name: "reservation-test"},
+				Spec: domainv1beta1.DomainReservationSpec{
+					Hostnames: []string{"reserved.test"},
+				},
+			}
+			Expect(k8sClient.Create(ctx, reservation)).To(Succeed())
+
+			Eventually(conditionStatus(key, domainv1beta1.RegistrationPolicyViolation), timeout, interval).Should(Equal(metav1.ConditionTrue))
+			expectReleased(key)
+
+			Expect(k8sClient.Delete(ctx, reservation)).To(Succeed())
+			Eventually(conditionStatus(key, domainv1beta1.RegistrationIngressReady), timeout, interval).Should(Equal(metav1.ConditionTrue))
+
+			deleteRegistrations(key)
+		})
+
+		It("Should release registrations with approval revoked", func() {
+			policy := &domainv1beta1.DomainPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "approval-test"},
+				Spec: domainv1beta1.DomainPolicySpec{
+					Namespaces:      []string{"approval"},
+					RequireApproval: domainv1beta1.ApprovalModeAll,
+				},
+			}
+			Expect(k8sClient.Create(ctx, policy)).To(Succeed())
+
+			reg := newRegistration("approval", "approval.test")
+			reg.Spec.Approved = true
+			key := createServingRegistration(reg)
+			Expect(conditionStatus(key, domainv1beta1.RegistrationApproved)()).To(Equal(metav1.ConditionTrue))
+
+			setApproved := func(approved bool) {
+				Eventually(func() error {
+					reg := &domainv1beta1.CustomDomainRegistration{}
+					Expect(k8sClient.Get(ctx, key, reg)).To(Succeed())
+					reg.Spec.Approved = approved
+					return k8sClient.Update(ctx, reg)
+				}, timeout, interval).Should(Succeed())
+			}
+
+			setApproved(false)
+			expectReleased(key)
+			reg = &domainv1beta1.CustomDomainRegistration{}
+			Expect(k8sClient.Get(ctx, key, reg)).To(Succeed())
+			Expect(condition.Lookup(reg.Status.Conditions, string(domainv1beta1.RegistrationAccepted)).Reason).To(Equal(domainv1beta1.ReasonPendingApproval))
+
+			setApproved(true)
+			Eventually(conditionStatus(key, domainv1beta1.RegistrationIngressReady), timeout, interval).Should(Equal(metav1.ConditionTrue))
+
+			Expect(k8sClient.Delete(ctx, policy)).To(Succeed())
+			deleteRegistrations(key)
+		})
+	})
+
+	Context("Re-verification", func() {
+		It("Should unverify domain with verification record removed", func() {
+			reg := newRegistration("reverification", "reverification.test")
+			reg.Spec.ReverificationInterval = &metav1.Duration{Duration: 3 * time.Second}
+			key := createServingRegistration(reg)
+			Expect(conditionStatus(key, domainv1beta1.RegistrationVerified)()).To(Equal(metav1.ConditionTrue))
+
+			reg = &domainv1beta1.CustomDomainRegistration{}
+			Expect(k8sClient.Get(ctx, key, reg)).To(Succeed())
+			for _, record := range reg.Status.DNSRecords {
+				if record.Type == "TXT" {
+					dnsServer.SetTXT(record.Name)
+				}
+			}
+
+			Eventually(conditionStatus(key, domainv1beta1.RegistrationVerified), timeout, interval).Should(Equal(metav1.ConditionFalse))
+
+			deleteRegistrations(key)
+		})
+	})
+})
@@ -0,0 +1,211 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/tracing"
+	"github.com/skygeario/k8s-controller/pkg/util/dnsname"
+)
+
+// DomainImportLabel is the label of registrations created by domain imports,
+// valued the name of DomainImport.
+const DomainImportLabel = "domain.skygear.io/import"
+
+// domainImportBatchSize is the maximum number of registrations created in a
+// reconciliation, so that large imports are processed within reconcile
+// timeout.
+const domainImportBatchSize = 100
+
+// DomainImportReconciler creates registrations of domains in DomainImport,
+// attested by the import so that they are verified without verification
+// records.
+type DomainImportReconciler struct {
+	client.Client
+	Log      logr.Logger
+	Recorder record.EventRecorder
+	Now      func() metav1.Time
+}
+
+// +kubebuilder:rbac:groups=domain.skygear.io,resources=domainimports,verbs=get;list;watch
+// +kubebuilder:rbac:groups=domain.skygear.io,resources=domainimports/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=domain.skygear.io,resources=customdomainregistrations,verbs=get;list;watch;create;update;patch;delete;approve
+// +kubebuilder:rbac:groups=domain.skygear.io,resources=customdomainregistrations/status,verbs=get;update;patch
+
+func (r *DomainImportReconciler) Reconcile(req ctrl.Request) (result ctrl.Result, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ReconcileTimeout)
+	defer cancel()
+	ctx, span := tracing.Start(ctx, "Reconcile DomainImport", tracing.SpanKindInternal,
+		tracing.String("k8s.resource.name", req.Name),
+	)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+	log := r.Log.WithValues("domainimport", req.Name, "reconcileID", newReconcileID())
+	ctx = withLogger(ctx, log)
+
+	var imp domainv1beta1.DomainImport
+	if err := r.Get(ctx, req.NamespacedName, &imp); err != nil {
+		// Imported registrations are kept after the import is deleted.
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	oldStatus := imp.Status.DeepCopy()
+
+	created := 0
+	pending := false
+	imp.Status.Items = make([]domainv1beta1.DomainImportItemStatus, len(imp.Spec.Items))
+	imp.Status.Imported = 0
+	imp.Status.Failed = 0
+	for i, item := range imp.Spec.Items {
+		status, err := r.importItem(ctx, &imp, item, &created)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		imp.Status.Items[i] = *status
+		switch status.Phase {
+		case domainv1beta1.DomainImportItemImported:
+			imp.Status.Imported++
+		case domainv1beta1.DomainImportItemFailed:
+			imp.Status.Failed++
+		default:
+			pending = true
+		}
+	}
+	imp.Status.ObservedGeneration = imp.Generation
+
+	if !equality.Semantic.DeepEqual(&imp.Status, oldStatus) {
+		if err := r.Status().Update(ctx, &imp); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	if created > 0 {
+		log.Info("imported domains", "created", created, "imported", imp.Status.Imported, "failed", imp.Status.Failed)
+	}
+	return ctrl.Result{Requeue: pending}, nil
+}
+
+// importItem creates and attests registration of the item, unless more than
+// domainImportBatchSize registrations are created in this reconciliation.
+func (r *DomainImportReconciler) importItem(ctx context.Context, imp *domainv1beta1.DomainImport, item domainv1beta1.DomainImportItem, created *int) (*domainv1beta1.DomainImportItemStatus, error) {
+	status := &domainv1beta1.DomainImportItemStatus{
+		DomainName: item.DomainName,
+		Namespace:  item.Namespace,
+		Name:       item.Name,
+	}
+	failed := func(format string, args ...interface{}) (*domainv1beta1.DomainImportItemStatus, error) {
+		status.Phase = domainv1beta1.DomainImportItemFailed
+		status.Message = fmt.Sprintf(format, args...)
+		return status, nil
+	}
+
+	domainName, err := dnsname.Normalize(item.DomainName)
+	if err == nil {
+		err = dnsname.Validate(domainName)
+	}
+	if err != nil {
+		return failed("invalid domain name: %s", err.Error())
+	}
+	if status.Name == "" {
+		status.Name = dnsname.ResourceName(domainName)
+	}
+
+	var reg domainv1beta1.CustomDomainRegistration
+	err = r.Get(ctx, types.NamespacedName{Namespace: item.Namespace, Name: status.Name}, &reg)
+	if apierrors.IsNotFound(err) {
+		if *created >= domainImportBatchSize {
+			status.Phase = domainv1beta1.DomainImportItemPending
+			return status, nil
+		}
+		reg = domainv1beta1.CustomDomainRegistration{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: item.Namespace,
+				Name:      status.Name,
+				Labels:    map[string]string{DomainImportLabel: imp.Name},
+			},
+			Spec: domainv1beta1.CustomDomainRegistrationSpec{
+				DomainName:   item.DomainName,
+				DomainConfig: item.DomainConfig,
+				// Imported by operator, no further approval is needed.
+				Approved: true,
+			},
+		}
+		if err := r.Create(ctx, &reg); err != nil {
+			if apierrors.IsInvalid(err) || apierrors.IsNotFound(err) || apierrors.IsForbidden(err) {
+				return failed("cannot create registration: %s", err.Error())
+			}
+			return nil, err
+		}
+		*created++
+		r.Recorder.Eventf(&reg, corev1.EventTypeNormal, EventDomainImported, "Imported by DomainImport %s", imp.Name)
+	} else if err != nil {
+		return nil, err
+	}
+
+	if reg.Labels[DomainImportLabel] != imp.Name {
+		return failed("registration %s/%s already exists", reg.Namespace, reg.Name)
+	}
+	if reg.ASCIIDomainName() != domainName {
+		return failed("registration %s/%s is of another domain %s", reg.Namespace, reg.Name, reg.Spec.DomainName)
+	}
+
+	if reg.Status.Attestation == nil {
+		patch := client.MergeFrom(reg.DeepCopy())
+		reg.Status.Attestation = &domainv1beta1.CustomDomainAttestationStatus{
+			Time:               r.Now(),
+			ObservedGeneration: reg.Generation,
+			Attester:           "DomainImport/" + imp.Name,
+		}
+		if err := r.Status().Patch(ctx, &reg, patch); err != nil {
+			return nil, err
+		}
+	}
+	status.Phase = domainv1beta1.DomainImportItemImported
+	return status, nil
+}
+
+func (r *DomainImportReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&domainv1beta1.DomainImport{}).
+		Watches(
+			&source.Kind{Type: &domainv1beta1.CustomDomainRegistration{}},
+			&handler.EnqueueRequestsFromMapFunc{
+				ToRequests: handler.ToRequestsFunc(func(o handler.MapObject) []ctrl.Request {
+					name, ok := o.Meta.GetLabels()[DomainImportLabel]
+					if !ok {
+						return nil
+					}
+					return []ctrl.Request{{NamespacedName: types.NamespacedName{Name: name}}}
+				}),
+			},
+		).
+		Complete(r)
+}
@@ -0,0 +1,163 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+)
+
+func newFakeDomainImportReconciler(t *testing.T, now metav1.Time, objs ...runtime.Object) *DomainImportReconciler {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := domainv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	return &DomainImportReconciler{
+		Client:   fake.NewFakeClientWithScheme(scheme, objs...),
+		Log:      ctrl.Log.WithName("controllers").WithName("DomainImport"),
+		Recorder: record.NewFakeRecorder(domainImportBatchSize * 2),
+		Now:      func() metav1.Time { return now },
+	}
+}
+
+func reconcileDomainImport(t *testing.T, r *DomainImportReconciler, name string) (ctrl.Result, *domainv1beta1.DomainImport) {
+	result, err := r.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Name: name}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var imp domainv1beta1.DomainImport
+	if err := r.Get(context.Background(), types.NamespacedName{Name: name}, &imp); err != nil {
+		t.Fatal(err)
+	}
+	return result, &imp
+}
+
+func TestDomainImport(t *testing.T) {
+	now := metav1.NewTime(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))
+	imp := &domainv1beta1.DomainImport{
+		ObjectMeta: metav1.ObjectMeta{Name: "import"},
+		Spec: domainv1beta1.DomainImportSpec{
+			Items: []domainv1beta1.DomainImportItem{
+				{DomainName: "www.example.com", Namespace: "app"},
+				{DomainName: "bücher.example", Namespace: "app", Name: "books"},
+				{DomainName: "*.example.org", Namespace: "app"},
+				{DomainName: "invalid..example.com", Namespace: "app"},
+				{DomainName: "api.example.com", Namespace: "app"},
+				{DomainName: "shop.example.com", Namespace: "app", Name: "previous"},
+			},
+		},
+	}
+	// Registration not created by the import
+	existing := &domainv1beta1.CustomDomainRegistration{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "api.example.com"},
+		Spec:       domainv1beta1.CustomDomainRegistrationSpec{DomainName: "api.example.com"},
+	}
+	// Registration created by the import, before the item is changed
+	previous := &domainv1beta1.CustomDomainRegistration{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "app",
+			Name:      "previous",
+			Labels:    map[string]string{DomainImportLabel: "import"},
+		},
+		Spec: domainv1beta1.CustomDomainRegistrationSpec{DomainName: "blog.example.com"},
+	}
+	r := newFakeDomainImportReconciler(t, now, imp, existing, previous)
+
+	result, imp := reconcileDomainImport(t, r, "import")
+	if result.Requeue {
+		t.Error("completed import is requeued")
+	}
+
+	expected := []struct {
+		name    string
+		phase   domainv1beta1.DomainImportItemPhase
+		message string
+	}{
+		{"www.example.com", domainv1beta1.DomainImportItemImported, ""},
+		{"books", domainv1beta1.DomainImportItemImported, ""},
+		{"zz--wildcard.example.org", domainv1beta1.DomainImportItemImported, ""},
+		{"", domainv1beta1.DomainImportItemFailed, "invalid domain name: "},
+		{"api.example.com", domainv1beta1.DomainImportItemFailed, "registration app/api.example.com already exists"},
+		{"previous", domainv1beta1.DomainImportItemFailed, "registration app/previous is of another domain blog.example.com"},
+	}
+	if len(imp.Status.Items) != len(expected) {
+		t.Fatalf("items = %#v", imp.Status.Items)
+	}
+	for i, e := range expected {
+		item := imp.Status.Items[i]
+		if item.Name != e.name || item.Phase != e.phase {
+			t.Errorf("item %d = %#v", i, item)
+		}
+		if !strings.HasPrefix(item.Message, e.message) {
+			t.Errorf("item %d message = %q, expected %q", i, item.Message, e.message)
+		}
+	}
+	if imp.Status.Imported != 3 || imp.Status.Failed != 3 {
+		t.Errorf("imported = %d, failed = %d", imp.Status.Imported, imp.Status.Failed)
+	}
+
+	var reg domainv1beta1.CustomDomainRegistration
+	if err := r.Get(context.Background(), types.NamespacedName{Namespace: "app", Name: "books"}, &reg); err != nil {
+		t.Fatal(err)
+	}
+	if reg.Labels[DomainImportLabel] != "import" || !reg.Spec.Approved || reg.Spec.DomainName != "bücher.example" {
+		t.Errorf("registration = %#v", reg)
+	}
+	if a := reg.Status.Attestation; a == nil || !a.Time.Equal(&now) || a.Attester != "DomainImport/import" {
+		t.Errorf("attestation = %#v", a)
+	}
+
+	// Registrations not created by the import are not attested
+	var existingReg domainv1beta1.CustomDomainRegistration
+	if err := r.Get(context.Background(), types.NamespacedName{Namespace: "app", Name: "api.example.com"}, &existingReg); err != nil {
+		t.Fatal(err)
+	}
+	if existingReg.Status.Attestation != nil {
+		t.Errorf("existing registration is attested")
+	}
+}
+
+func TestDomainImportBatch(t *testing.T) {
+	now := metav1.NewTime(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))
+	imp := &domainv1beta1.DomainImport{ObjectMeta: metav1.ObjectMeta{Name: "import"}}
+	for i := 0; i < domainImportBatchSize+1; i++ {
+		imp.Spec.Items = append(imp.Spec.Items, domainv1beta1.DomainImportItem{
+			DomainName: fmt.Sprintf("app-%d.example.com", i),
+			Namespace:  "app",
+		})
+	}
+	r := newFakeDomainImportReconciler(t, now, imp)
+
+	result, imp := reconcileDomainImport(t, r, "import")
+	if !result.Requeue {
+		t.Error("pending import is not requeued")
+	}
+	if imp.Status.Imported != domainImportBatchSize {
+		t.Errorf("imported = %d, expected %d", imp.Status.Imported, domainImportBatchSize)
+	}
+	if last := imp.Status.Items[domainImportBatchSize]; last.Phase != domainv1beta1.DomainImportItemPending {
+		t.Errorf("last item = %#v", last)
+	}
+
+	result, imp = reconcileDomainImport(t, r, "import")
+	if result.Requeue {
+		t.Error("completed import is requeued")
+	}
+	if imp.Status.Imported != domainImportBatchSize+1 {
+		t.Errorf("imported = %d, expected %d", imp.Status.Imported, domainImportBatchSize+1)
+	}
+}
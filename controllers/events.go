@@ -0,0 +1,48 @@
+package controllers
+
+const (
+	// EventDomainRegistered is emitted when registration is added to the domain.
+	EventDomainRegistered = "DomainRegistered"
+	// EventVerificationSucceeded is emitted when domain is verified.
+	EventVerificationSucceeded = "VerificationSucceeded"
+	// EventVerificationFailed is emitted when domain verification fails.
+	EventVerificationFailed = "VerificationFailed"
+	// EventVerificationDeadlineExceeded is emitted when domain is not verified
+	// before the verification deadline.
+	EventVerificationDeadlineExceeded = "VerificationDeadlineExceeded"
+	// EventVerificationEmailSent is emitted when confirmation email of Email
+	// verification is sent.
+	EventVerificationEmailSent = "VerificationEmailSent"
+	// EventDomainImported is emitted when registration is created by
+	// DomainImport.
+	EventDomainImported = "DomainImported"
+	// EventRegistrationExpired is emitted when registration is deleted after
+	// expiry.
+	EventRegistrationExpired = "RegistrationExpired"
+	// EventRegistrationConflict is emitted when registration to domain keeps
+	// conflicting with concurrent updates, and is retried later.
+	EventRegistrationConflict = "RegistrationConflict"
+	// EventDomainReleased is emitted when domain resources are released.
+	EventDomainReleased = "DomainReleased"
+	// EventDNSRecordsDeleted is emitted when DNS records of domain are deleted.
+	EventDNSRecordsDeleted = "DNSRecordsDeleted"
+	// EventOwnershipTransferred is emitted when ownership of domain is
+	// transferred to another registration.
+	EventOwnershipTransferred = "OwnershipTransferred"
+	// EventSiblingRegistered is emitted when sibling registration redirecting
+	// to the domain is created.
+	EventSiblingRegistered = "SiblingRegistered"
+	// EventSiblingReleased is emitted when sibling registration is deleted.
+	EventSiblingReleased = "SiblingReleased"
+	// EventSiblingConflict is emitted when sibling domain is registered by
+	// another registration.
+	EventSiblingConflict = "SiblingConflict"
+	// EventDefaultDomainProvisioned is emitted on namespace when registration
+	// of its default domain is created.
+	EventDefaultDomainProvisioned = "DefaultDomainProvisioned"
+	// EventDefaultDomainConflict is emitted on namespace when its default
+	// domain cannot be provisioned.
+	EventDefaultDomainConflict = "DefaultDomainConflict"
+	// EventAudit is emitted on domain when its ownership changes.
+	EventAudit = "Audit"
+)
@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/util/dnsname"
+)
+
+const (
+	// secretNameIndex indexes registrations by names of referenced Secrets.
+	secretNameIndex = "secretName"
+	// domainNameIndex indexes registrations by ASCII domain name.
+	domainNameIndex = "domainName"
+	// parentDomainIndex indexes non-wildcard domains by parent domain name.
+	parentDomainIndex = "parentDomain"
+	// zoneIndex indexes registrations and domains by registrable zone.
+	zoneIndex = "zone"
+)
+
+// setupIndexes sets up indexes of registrations with indexer, and indexes of
+// domains with domainIndexer, which is nil if domains are not available.
+func setupIndexes(indexer client.FieldIndexer, domainIndexer client.FieldIndexer) error {
+	err := indexer.IndexField(&domainv1beta1.CustomDomainRegistration{}, secretNameIndex, func(o runtime.Object) []string {
+		return referencedSecretNames(o.(*domainv1beta1.CustomDomainRegistration))
+	})
+	if err != nil {
+		return err
+	}
+
+	err = indexer.IndexField(&domainv1beta1.CustomDomainRegistration{}, domainNameIndex, func(o runtime.Object) []string {
+		return []string{o.(*domainv1beta1.CustomDomainRegistration).ASCIIDomainName()}
+	})
+	if err != nil {
+		return err
+	}
+
+	err = indexer.IndexField(&domainv1beta1.CustomDomainRegistration{}, zoneIndex, func(o runtime.Object) []string {
+		return zoneIndexValues(o.(*domainv1beta1.CustomDomainRegistration).ASCIIDomainName())
+	})
+	if err != nil {
+		return err
+	}
+
+	if domainIndexer == nil {
+		return nil
+	}
+	err = domainIndexer.IndexField(&domainv1beta1.CustomDomain{}, parentDomainIndex, func(o runtime.Object) []string {
+		name := dnsname.DomainName(o.(*domainv1beta1.CustomDomain).Name)
+		if dnsname.IsWildcard(name) {
+			return nil
+		}
+		return []string{dnsname.Parent(name)}
+	})
+	if err != nil {
+		return err
+	}
+
+	return domainIndexer.IndexField(&domainv1beta1.CustomDomain{}, zoneIndex, func(o runtime.Object) []string {
+		return zoneIndexValues(dnsname.DomainName(o.(*domainv1beta1.CustomDomain).Name))
+	})
+}
+
+func zoneIndexValues(name string) []string {
+	zone := dnsname.Zone(name)
+	if zone == "" {
+		return nil
+	}
+	return []string{zone}
+}
+
+// referencedSecretNames returns names of TLS Secrets referenced by the
+// registration.
+func referencedSecretNames(reg *domainv1beta1.CustomDomainRegistration) []string {
+	var names []string
+	if reg.Spec.DomainConfig.CertSecretName != nil {
+		names = append(names, *reg.Spec.DomainConfig.CertSecretName)
+	}
+	if reg.Spec.TLS != nil && reg.Spec.TLS.SecretName != nil {
+		names = append(names, *reg.Spec.TLS.SecretName)
+	}
+	if reg.Status.CertSecretName != nil {
+		names = append(names, *reg.Status.CertSecretName)
+	}
+	return names
+}
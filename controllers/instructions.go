@@ -0,0 +1,43 @@
+package controllers
+
+import (
+	"fmt"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+)
+
+// makeDNSInstructions renders human-readable instructions to configure the
+// DNS records, for tenant dashboards to display as setup guides.
+func makeDNSInstructions(records []domainv1beta1.CustomDomainDNSRecord) []domainv1beta1.CustomDomainDNSInstruction {
+	if len(records) == 0 {
+		return nil
+	}
+	instructions := make([]domainv1beta1.CustomDomainDNSInstruction, len(records))
+	for i, record := range records {
+		var description string
+		switch record.Type {
+		case "A", "AAAA", "CNAME":
+			description = fmt.Sprintf("Add %s record for %s pointing to %s", article(record.Type), record.Name, record.Value)
+		default:
+			description = fmt.Sprintf("Add %s record for %s with value \"%s\"", article(record.Type), record.Name, record.Value)
+		}
+		instructions[i] = domainv1beta1.CustomDomainDNSInstruction{
+			Name:        record.Name,
+			Type:        record.Type,
+			Value:       record.Value,
+			Description: description,
+			Command:     fmt.Sprintf("dig +short %s %s", record.Type, record.Name),
+		}
+	}
+	return instructions
+}
+
+// article returns the record type prefixed with indefinite article.
+func article(recordType string) string {
+	switch recordType {
+	case "A", "AAAA":
+		return "an " + recordType
+	default:
+		return "a " + recordType
+	}
+}
@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+
+	"github.com/go-logr/logr"
+)
+
+// logDomains are domain names whose reconciliations are logged at all
+// verbosity levels, for debugging a domain without enabling verbose logs of
+// all domains.
+var (
+	logDomainsLock sync.RWMutex
+	logDomains     = map[string]bool{}
+)
+
+// SetLogDomains sets the domain names whose reconciliations are logged at
+// all verbosity levels.
+func SetLogDomains(domainNames []string) {
+	domains := map[string]bool{}
+	for _, name := range domainNames {
+		domains[name] = true
+	}
+	logDomainsLock.Lock()
+	defer logDomainsLock.Unlock()
+	logDomains = domains
+}
+
+func isLogDomain(domainName string) bool {
+	logDomainsLock.RLock()
+	defer logDomainsLock.RUnlock()
+	return logDomains[domainName]
+}
+
+type loggerKey struct{}
+
+// newReconcileLogger returns the logger of a reconciliation of the domain,
+// with an ID identifying log entries of the reconciliation.
+func newReconcileLogger(log logr.Logger, domainName string, keysAndValues ...interface{}) logr.Logger {
+	keysAndValues = append(keysAndValues, "domain", domainName, "reconcileID", newReconcileID())
+	log = log.WithValues(keysAndValues...)
+	if isLogDomain(domainName) {
+		log = verboseLogger{Logger: log}
+	}
+	return log
+}
+
+func newReconcileID() string {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(id)
+}
+
+// withLogger returns a context carrying the logger, so that helpers log
+// with values of the reconciliation.
+func withLogger(ctx context.Context, log logr.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, log)
+}
+
+// loggerFrom returns the logger carried by the context, or fallback if none.
+func loggerFrom(ctx context.Context, fallback logr.Logger) logr.Logger {
+	if log, ok := ctx.Value(loggerKey{}).(logr.Logger); ok {
+		return log
+	}
+	return fallback
+}
+
+// verboseLogger logs at all verbosity levels, regardless of configured
+// verbosity.
+type verboseLogger struct {
+	logr.Logger
+}
+
+func (l verboseLogger) V(level int) logr.InfoLogger {
+	return l.Logger
+}
+
+func (l verboseLogger) WithValues(keysAndValues ...interface{}) logr.Logger {
+	return verboseLogger{Logger: l.Logger.WithValues(keysAndValues...)}
+}
+
+func (l verboseLogger) WithName(name string) logr.Logger {
+	return verboseLogger{Logger: l.Logger.WithName(name)}
+}
@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/util/dnsname"
+)
+
+var registrationConditionsDesc = prometheus.NewDesc(
+	"domain_registrations",
+	"Number of custom domain registrations by condition",
+	[]string{"type", "status"},
+	nil,
+)
+
+var zoneRegistrationsDesc = prometheus.NewDesc(
+	"domain_zone_registrations",
+	"Number of custom domain registrations by registrable zone and phase",
+	[]string{"zone", "phase"},
+	nil,
+)
+
+var certificateExpiryDesc = prometheus.NewDesc(
+	"domain_certificate_expiry_days",
+	"Number of days until TLS certificate of custom domain registration expires",
+	[]string{"namespace", "name", "domain"},
+	nil,
+)
+
+// RegistrationCollector collects number of registrations by condition and by
+// zone, and days to expiry of TLS certificates of registrations.
+type RegistrationCollector struct {
+	Client client.Client
+}
+
+func NewRegistrationCollector(client client.Client) *RegistrationCollector {
+	return &RegistrationCollector{Client: client}
+}
+
+var _ prometheus.Collector = &RegistrationCollector{}
+
+func (c *RegistrationCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- registrationConditionsDesc
+	ch <- zoneRegistrationsDesc
+	ch <- certificateExpiryDesc
+}
+
+func (c *RegistrationCollector) Collect(ch chan<- prometheus.Metric) {
+	var list domainv1beta1.CustomDomainRegistrationList
+	if err := c.Client.List(context.Background(), &list); err != nil {
+		ch <- prometheus.NewInvalidMetric(registrationConditionsDesc, err)
+		ch <- prometheus.NewInvalidMetric(zoneRegistrationsDesc, err)
+		ch <- prometheus.NewInvalidMetric(certificateExpiryDesc, err)
+		return
+	}
+
+	type key struct{ condType, status string }
+	counts := map[key]int{}
+	type zoneKey struct{ zone, phase string }
+	zoneCounts := map[zoneKey]int{}
+	now := time.Now()
+	for _, reg := range list.Items {
+		for _, cond := range reg.Status.Conditions {
+			counts[key{cond.Type, string(cond.Status)}]++
+		}
+		if zone := dnsname.Zone(reg.ASCIIDomainName()); zone != "" {
+			zoneCounts[zoneKey{zone, string(reg.Status.Phase)}]++
+		}
+
+		if reg.Status.TLS != nil && reg.Status.TLS.NotAfter != nil {
+			ch <- prometheus.MustNewConstMetric(
+				certificateExpiryDesc,
+				prometheus.GaugeValue,
+				reg.Status.TLS.NotAfter.Sub(now).Hours()/24,
+				reg.Namespace, reg.Name, reg.ASCIIDomainName(),
+			)
+		}
+	}
+
+	for k, n := range counts {
+		ch <- prometheus.MustNewConstMetric(
+			registrationConditionsDesc,
+			prometheus.GaugeValue,
+			float64(n),
+			k.condType, k.status,
+		)
+	}
+	for k, n := range zoneCounts {
+		ch <- prometheus.MustNewConstMetric(
+			zoneRegistrationsDesc,
+			prometheus.GaugeValue,
+			float64(n),
+			k.zone, k.phase,
+		)
+	}
+}
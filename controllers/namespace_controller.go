@@ -0,0 +1,217 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/skygeario/k8s-controller/api"
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/tracing"
+	"github.com/skygeario/k8s-controller/pkg/util/dnsname"
+)
+
+// DefaultDomainLabel is the label of registrations of default domains
+// provisioned for namespaces, valued "true".
+const DefaultDomainLabel = "domain.skygear.io/default-domain"
+
+// NamespaceReconciler provisions registration of default domain
+// <namespace>.<suffix> for namespaces matching the selector.
+type NamespaceReconciler struct {
+	client.Client
+	Log      logr.Logger
+	Recorder record.EventRecorder
+	// Selector selects the namespaces provisioned with default domains.
+	Selector labels.Selector
+	// Suffix is the platform-owned domain suffix of default domains.
+	Suffix string
+	// BackendServiceName is the name of backend Service of default domains,
+	// overridden by annotation domain.skygear.io/default-domain-backend of
+	// namespace.
+	BackendServiceName string
+	// BackendServicePort is the port of backend Service of default domains.
+	BackendServicePort int
+}
+
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups=domain.skygear.io,resources=customdomainregistrations,verbs=get;list;watch;create;update;patch;delete
+
+func (r *NamespaceReconciler) Reconcile(req ctrl.Request) (result ctrl.Result, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ReconcileTimeout)
+	defer cancel()
+	domainName := DefaultDomainName(req.Name, r.Suffix)
+	ctx, span := tracing.Start(ctx, "Reconcile Namespace", tracing.SpanKindInternal,
+		tracing.String("k8s.namespace.name", req.Name),
+		tracing.String("domain", domainName),
+	)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+	log := newReconcileLogger(r.Log, domainName, "namespace", req.Name)
+	ctx = withLogger(ctx, log)
+
+	var ns corev1.Namespace
+	if err := r.Get(ctx, types.NamespacedName{Name: req.Name}, &ns); err != nil {
+		// Registrations are deleted with the namespace.
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if ns.DeletionTimestamp != nil {
+		return ctrl.Result{}, nil
+	}
+
+	var list domainv1beta1.CustomDomainRegistrationList
+	if err := r.List(ctx, &list, client.InNamespace(ns.Name), client.MatchingLabels{DefaultDomainLabel: "true"}); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	enabled := r.Selector.Matches(labels.Set(ns.Labels))
+	for i := range list.Items {
+		reg := &list.Items[i]
+		if enabled && reg.Name == dnsname.ResourceName(domainName) {
+			continue
+		}
+		if reg.DeletionTimestamp != nil {
+			continue
+		}
+		// Default domains of previous suffixes, or of namespaces no longer
+		// selected, are released. They are always verified and routed, so
+		// force deletion is required.
+		if reg.Annotations[api.ForceDeleteAnnotation] != "true" {
+			patch := client.MergeFrom(reg.DeepCopy())
+			if reg.Annotations == nil {
+				reg.Annotations = map[string]string{}
+			}
+			reg.Annotations[api.ForceDeleteAnnotation] = "true"
+			if err := r.Patch(ctx, reg, patch); err != nil {
+				return ctrl.Result{}, client.IgnoreNotFound(err)
+			}
+		}
+		if err := r.Delete(ctx, reg); err != nil {
+			return ctrl.Result{}, client.IgnoreNotFound(err)
+		}
+		log.Info("released default domain", "registration", reg.Name)
+	}
+	if !enabled {
+		return ctrl.Result{}, nil
+	}
+
+	serviceName, servicePort, err := r.backend(&ns)
+	if err != nil {
+		r.Recorder.Eventf(&ns, corev1.EventTypeWarning, EventDefaultDomainConflict, "Invalid backend of default domain: %s", err.Error())
+		return ctrl.Result{}, nil
+	}
+
+	var reg domainv1beta1.CustomDomainRegistration
+	err = r.Get(ctx, types.NamespacedName{Namespace: ns.Name, Name: dnsname.ResourceName(domainName)}, &reg)
+	if apierrors.IsNotFound(err) {
+		reg = domainv1beta1.CustomDomainRegistration{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: ns.Name,
+				Name:      dnsname.ResourceName(domainName),
+				Labels:    map[string]string{DefaultDomainLabel: "true"},
+			},
+			Spec: domainv1beta1.CustomDomainRegistrationSpec{
+				DomainName: domainName,
+				DomainConfig: domainv1beta1.CustomDomainConfig{
+					BackendServiceName: serviceName,
+					BackendServicePort: servicePort,
+				},
+			},
+		}
+		if err := r.Create(ctx, &reg); err != nil {
+			return ctrl.Result{}, err
+		}
+		log.Info("provisioned default domain")
+		r.Recorder.Eventf(&ns, corev1.EventTypeNormal, EventDefaultDomainProvisioned, "Provisioned default domain %s", domainName)
+		return ctrl.Result{}, nil
+	} else if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if reg.Labels[DefaultDomainLabel] != "true" {
+		r.Recorder.Eventf(&ns, corev1.EventTypeWarning, EventDefaultDomainConflict,
+			"Default domain %s is registered by another registration", domainName)
+		return ctrl.Result{}, nil
+	}
+
+	config := domainv1beta1.CustomDomainConfig{
+		BackendServiceName: serviceName,
+		BackendServicePort: servicePort,
+	}
+	if !reflect.DeepEqual(config, reg.Spec.DomainConfig) {
+		reg.Spec.DomainConfig = config
+		if err := r.Update(ctx, &reg); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+// backend returns the backend Service of default domain of namespace.
+func (r *NamespaceReconciler) backend(ns *corev1.Namespace) (name string, port int, err error) {
+	value, ok := ns.Annotations[api.DefaultDomainBackendAnnotation]
+	if !ok {
+		return r.BackendServiceName, r.BackendServicePort, nil
+	}
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return "", 0, fmt.Errorf("%s must be in format of name:port", api.DefaultDomainBackendAnnotation)
+	}
+	port, err = strconv.Atoi(parts[1])
+	if err != nil || port <= 0 || port > 65535 {
+		return "", 0, fmt.Errorf("invalid port %q", parts[1])
+	}
+	return parts[0], port, nil
+}
+
+func (r *NamespaceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&corev1.Namespace{}).
+		Watches(
+			&source.Kind{Type: &domainv1beta1.CustomDomainRegistration{}},
+			&handler.EnqueueRequestsFromMapFunc{
+				ToRequests: handler.ToRequestsFunc(func(o handler.MapObject) []ctrl.Request {
+					if o.Meta.GetLabels()[DefaultDomainLabel] != "true" {
+						return nil
+					}
+					return []ctrl.Request{{NamespacedName: types.NamespacedName{Name: o.Meta.GetNamespace()}}}
+				}),
+			},
+		).
+		Complete(r)
+}
+
+// DefaultDomainName returns the default domain of namespace under suffix.
+func DefaultDomainName(namespace string, suffix string) string {
+	return namespace + "." + strings.TrimSuffix(strings.ToLower(suffix), ".")
+}
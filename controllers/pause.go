@@ -0,0 +1,28 @@
+package controllers
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/skygeario/k8s-controller/api"
+)
+
+// isPaused reports whether reconciliation of the object is paused.
+func isPaused(obj metav1.Object) bool {
+	return obj.GetAnnotations()[api.PausedAnnotation] == "true"
+}
+
+// pausedConditions returns the conditions with paused condition added,
+// keeping other conditions unchanged.
+func pausedConditions(conds []api.Condition, condType string) []api.Condition {
+	var result []api.Condition
+	for _, cond := range conds {
+		if cond.Type != condType {
+			result = append(result, cond)
+		}
+	}
+	return append(result, api.Condition{
+		Type:    condType,
+		Status:  metav1.ConditionTrue,
+		Message: "reconciliation is paused by annotation " + api.PausedAnnotation,
+	})
+}
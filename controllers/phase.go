@@ -0,0 +1,40 @@
+package controllers
+
+import (
+	"github.com/skygeario/k8s-controller/api"
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/util/condition"
+)
+
+func registrationPhase(deleting bool, conds []api.Condition) domainv1beta1.CustomDomainRegistrationPhase {
+	switch {
+	case deleting:
+		return domainv1beta1.RegistrationPhaseTerminating
+	case condition.IsTrue(conds, string(domainv1beta1.RegistrationTransferAccepted)):
+		return domainv1beta1.RegistrationPhaseTransferred
+	case condition.IsTrue(conds, string(domainv1beta1.RegistrationRejected)),
+		condition.IsTrue(conds, string(domainv1beta1.RegistrationQuotaExceeded)),
+		condition.IsTrue(conds, string(domainv1beta1.RegistrationPolicyViolation)):
+		return domainv1beta1.RegistrationPhaseRejected
+	case condition.IsTrue(conds, string(domainv1beta1.RegistrationFailed)):
+		return domainv1beta1.RegistrationPhaseFailed
+	case !condition.IsTrue(conds, string(domainv1beta1.RegistrationAccepted)):
+		return domainv1beta1.RegistrationPhasePending
+	case condition.IsTrue(conds, string(domainv1beta1.RegistrationCertReady)) &&
+		condition.IsTrue(conds, string(domainv1beta1.RegistrationIngressReady)):
+		return domainv1beta1.RegistrationPhaseReady
+	default:
+		return domainv1beta1.RegistrationPhaseAccepted
+	}
+}
+
+func domainPhase(deleting bool, conds []api.Condition) domainv1beta1.CustomDomainPhase {
+	switch {
+	case deleting:
+		return domainv1beta1.DomainPhaseTerminating
+	case condition.IsTrue(conds, string(domainv1beta1.DomainLoadBalancerProvisioned)):
+		return domainv1beta1.DomainPhaseReady
+	default:
+		return domainv1beta1.DomainPhasePending
+	}
+}
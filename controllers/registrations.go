@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/metrics"
+)
+
+// Operations of updating domain registrations, as label of conflict retry
+// metric.
+const (
+	registrationOpRegister   = "register"
+	registrationOpUnregister = "unregister"
+	registrationOpCleanup    = "cleanup"
+)
+
+// updateDomainRegistrations updates registrations of the domain using mutate,
+// which returns the updated registrations and whether they are changed.
+//
+// Registrations are updated with optimistic concurrency: the update fails on
+// conflict and is retried with the latest domain, so that concurrent updates
+// from other registrations are never lost. Retries are counted by operation.
+func updateDomainRegistrations(
+	ctx context.Context,
+	c client.Client,
+	operation string,
+	d *domainv1beta1.CustomDomain,
+	mutate func(refs []domainv1beta1.CustomDomainRegistrationReference) ([]domainv1beta1.CustomDomainRegistrationReference, bool),
+) (updated bool, err error) {
+	refresh := false
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if refresh {
+			if err := c.Get(ctx, types.NamespacedName{Namespace: d.Namespace, Name: d.Name}, d); err != nil {
+				return err
+			}
+		}
+		refresh = true
+
+		refs, changed := mutate(d.Spec.Registrations)
+		if !changed {
+			updated = false
+			return nil
+		}
+		d.Spec.Registrations = refs
+		updated = true
+		err := c.Update(ctx, d)
+		if apierrors.IsConflict(err) {
+			metrics.RegistrationConflictRetries.WithLabelValues(operation).Inc()
+		}
+		return err
+	})
+	return updated, err
+}
@@ -0,0 +1,229 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+)
+
+func newFakeDomainReconciler(t *testing.T, now metav1.Time, objs ...runtime.Object) *CustomDomainReconciler {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := domainv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	return &CustomDomainReconciler{
+		Client: fake.NewFakeClientWithScheme(scheme, objs...),
+		Scheme: scheme,
+		Now:    func() metav1.Time { return now },
+	}
+}
+
+func remoteRef(cluster, namespace string, uid types.UID, heartbeat *metav1.Time) domainv1beta1.CustomDomainRegistrationReference {
+	return domainv1beta1.CustomDomainRegistrationReference{
+		ObjectReference: corev1.ObjectReference{
+			Namespace: namespace,
+			Name:      "example.com",
+			UID:       uid,
+		},
+		Cluster:           cluster,
+		Verified:          true,
+		LastHeartbeatTime: heartbeat,
+	}
+}
+
+func TestValidateRemoteRegistrations(t *testing.T) {
+	now := metav1.NewTime(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))
+	fresh := metav1.NewTime(now.Add(-RemoteRegistrationTTL / 2))
+	stale := metav1.NewTime(now.Add(-RemoteRegistrationTTL))
+
+	d := &domainv1beta1.CustomDomain{
+		ObjectMeta: metav1.ObjectMeta{Name: "example.com"},
+		Spec: domainv1beta1.CustomDomainSpec{
+			Registrations: []domainv1beta1.CustomDomainRegistrationReference{
+				remoteRef("fresh", "app", "fresh-uid", &fresh),
+				remoteRef("stale", "app", "stale-uid", &stale),
+				remoteRef("legacy", "app", "legacy-uid", nil),
+			},
+		},
+	}
+	r := newFakeDomainReconciler(t, now, d.DeepCopy())
+
+	expireTime, err := r.validateRegistrations(context.Background(), d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := fresh.Add(RemoteRegistrationTTL); expireTime == nil || !expireTime.Equal(expected) {
+		t.Errorf("expire time = %v, expected %v", expireTime, expected)
+	}
+
+	refs := map[types.UID]domainv1beta1.CustomDomainRegistrationReference{}
+	for _, ref := range d.Spec.Registrations {
+		refs[ref.UID] = ref
+	}
+	if _, ok := refs["stale-uid"]; ok {
+		t.Error("registration with expired heartbeat is not removed")
+	}
+	if ref, ok := refs["fresh-uid"]; !ok || !ref.LastHeartbeatTime.Equal(&fresh) {
+		t.Errorf("registration with fresh heartbeat is changed: %#v", ref)
+	}
+	if ref, ok := refs["legacy-uid"]; !ok || ref.LastHeartbeatTime == nil || !ref.LastHeartbeatTime.Equal(&now) {
+		t.Errorf("registration without heartbeat is not given heartbeat: %#v", ref)
+	}
+}
+
+func TestCheckOwnerCluster(t *testing.T) {
+	now := metav1.NewTime(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))
+	heartbeat := metav1.NewTime(now.Add(-time.Minute))
+	owner := remoteRef("cluster-a", "app", "owner-uid", &heartbeat)
+
+	tests := []struct {
+		name         string
+		ownerCluster string
+		ownerRef     *corev1.ObjectReference
+		refs         []domainv1beta1.CustomDomainRegistrationReference
+		ok           bool
+		expectedRef  types.UID
+	}{
+		{
+			name:         "owner registration",
+			ownerCluster: "cluster-a",
+			ownerRef:     &owner.ObjectReference,
+			refs:         []domainv1beta1.CustomDomainRegistrationReference{owner},
+			ok:           true,
+			expectedRef:  "owner-uid",
+		},
+		{
+			name:         "same namespace in other cluster",
+			ownerCluster: "cluster-a",
+			ownerRef:     &owner.ObjectReference,
+			refs: []domainv1beta1.CustomDomainRegistrationReference{
+				remoteRef("cluster-b", "app", "other-uid", &heartbeat),
+			},
+			ok: false,
+		},
+		{
+			name:         "re-created registration of owner app",
+			ownerCluster: "cluster-a",
+			ownerRef:     &owner.ObjectReference,
+			refs: []domainv1beta1.CustomDomainRegistrationReference{
+				remoteRef("cluster-a", "app", "new-uid", &heartbeat),
+			},
+			ok:          true,
+			expectedRef: "new-uid",
+		},
+		{
+			name:     "owner granted without owner cluster",
+			ownerRef: &owner.ObjectReference,
+			refs:     []domainv1beta1.CustomDomainRegistrationReference{owner},
+			ok:       true,
+		},
+		{
+			name:         "owner heartbeat expired",
+			ownerCluster: "cluster-a",
+			ownerRef:     &owner.ObjectReference,
+			refs: []domainv1beta1.CustomDomainRegistrationReference{
+				remoteRef("cluster-a", "app", "owner-uid", &metav1.Time{Time: now.Add(-RemoteRegistrationTTL)}),
+			},
+			ok: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &domainv1beta1.CustomDomain{
+				ObjectMeta: metav1.ObjectMeta{Name: "example.com"},
+				Spec: domainv1beta1.CustomDomainSpec{
+					OwnerApp:      pointer.StringPtr("app"),
+					OwnerCluster:  tt.ownerCluster,
+					OwnerRef:      tt.ownerRef.DeepCopy(),
+					Registrations: tt.refs,
+				},
+			}
+			r := newFakeDomainReconciler(t, now, d.DeepCopy())
+
+			ok, err := r.checkOwner(context.Background(), d)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != tt.ok {
+				t.Errorf("checkOwner = %v, expected %v", ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+			if d.Spec.OwnerCluster != "cluster-a" {
+				t.Errorf("owner cluster = %q", d.Spec.OwnerCluster)
+			}
+			if tt.expectedRef != "" && d.Spec.OwnerRef.UID != tt.expectedRef {
+				t.Errorf("owner ref = %s, expected %s", d.Spec.OwnerRef.UID, tt.expectedRef)
+			}
+		})
+	}
+}
+
+func TestReleaseQuarantineKeptAfterKeyRotation(t *testing.T) {
+	defer func(period time.Duration) { ReleaseQuarantinePeriod = period }(ReleaseQuarantinePeriod)
+	ReleaseQuarantinePeriod = time.Hour
+
+	now := metav1.NewTime(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))
+	d := &domainv1beta1.CustomDomain{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "example.com",
+			CreationTimestamp: metav1.NewTime(now.Add(-48 * time.Hour)),
+		},
+		Spec: domainv1beta1.CustomDomainSpec{
+			OwnerApp:                pointer.StringPtr("app"),
+			OwnerRef:                &corev1.ObjectReference{Namespace: "app", Name: "example.com", UID: "owner-uid"},
+			VerificationKey:         pointer.StringPtr("key"),
+			VerificationKeyRotation: &metav1.Duration{Duration: 24 * time.Hour},
+		},
+		// Stored status of previous release
+		Status: domainv1beta1.CustomDomainStatus{
+			ReleasedAt:      &metav1.Time{Time: now.Add(-48 * time.Hour)},
+			ReleasedApp:     "previous-app",
+			QuarantineUntil: &metav1.Time{Time: now.Add(-47 * time.Hour)},
+		},
+	}
+	r := newFakeDomainReconciler(t, now, d.DeepCopy())
+	r.VerificationKeyGenerator = func() string { return "rotated-key" }
+
+	ctx := context.Background()
+	// Owner registration is deleted
+	if _, err := r.processRegistrations(ctx, d); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.rotateVerificationKeyIfNeeded(ctx, d); err != nil {
+		t.Fatal(err)
+	}
+
+	if *d.Spec.VerificationKey != "rotated-key" {
+		t.Errorf("verification key is not rotated")
+	}
+	if d.Spec.OwnerApp != nil {
+		t.Errorf("ownership is not revoked")
+	}
+	if d.Status.ReleasedAt == nil || !d.Status.ReleasedAt.Equal(&now) {
+		t.Errorf("released at = %v, expected %v", d.Status.ReleasedAt, now)
+	}
+	if d.Status.ReleasedApp != "app" {
+		t.Errorf("released app = %q", d.Status.ReleasedApp)
+	}
+	if expected := metav1.NewTime(now.Add(time.Hour)); d.Status.QuarantineUntil == nil || !d.Status.QuarantineUntil.Equal(&expected) {
+		t.Errorf("quarantine until = %v, expected %v", d.Status.QuarantineUntil, expected)
+	}
+	if d.Status.VerificationKeyRotatedAt == nil || !d.Status.VerificationKeyRotatedAt.Equal(&now) {
+		t.Errorf("verification key rotated at = %v", d.Status.VerificationKeyRotatedAt)
+	}
+}
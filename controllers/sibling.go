@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"context"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/util/dnsname"
+	"github.com/skygeario/k8s-controller/pkg/util/slice"
+)
+
+// SiblingOfLabel is the label of sibling registrations, valued the name of
+// registration they redirect to.
+const SiblingOfLabel = "domain.skygear.io/sibling-of"
+
+// siblingRedirectStatusCode is the status code of redirect from sibling host
+// to the canonical host.
+const siblingRedirectStatusCode = 301
+
+// reconcileSibling creates or updates the sibling registration of reg
+// redirecting to its domain if enabled, or deletes it otherwise.
+//
+// Sibling registrations are owned by reg, but not controlled by it, since
+// registrations are controlled by their domains.
+func (r *CustomDomainRegistrationReconciler) reconcileSibling(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration, enabled bool) error {
+	siblingName, ok := dnsname.Sibling(reg.ASCIIDomainName())
+	if !ok {
+		return nil
+	}
+
+	var sibling domainv1beta1.CustomDomainRegistration
+	err := r.Get(ctx, types.NamespacedName{Namespace: reg.Namespace, Name: dnsname.ResourceName(siblingName)}, &sibling)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+
+	if exists && !isSiblingOf(&sibling, reg) {
+		if enabled {
+			r.Recorder.Eventf(reg, corev1.EventTypeWarning, EventSiblingConflict,
+				"Sibling domain %s is registered by another registration", siblingName)
+		}
+		return nil
+	}
+
+	if !enabled {
+		if exists && sibling.DeletionTimestamp == nil {
+			if err := r.Delete(ctx, &sibling); err != nil {
+				return client.IgnoreNotFound(err)
+			}
+			r.Recorder.Eventf(reg, corev1.EventTypeNormal, EventSiblingReleased, "Released sibling domain %s", siblingName)
+		}
+		return nil
+	}
+
+	if !exists {
+		sibling = domainv1beta1.CustomDomainRegistration{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:       reg.Namespace,
+				Name:            dnsname.ResourceName(siblingName),
+				Labels:          map[string]string{SiblingOfLabel: reg.Name},
+				OwnerReferences: []metav1.OwnerReference{siblingOwnerReference(reg)},
+			},
+		}
+		applySiblingSpec(&sibling.Spec, reg, siblingName)
+		if err := r.Create(ctx, &sibling); err != nil {
+			return err
+		}
+		loggerFrom(ctx, r.Log).Info("registered sibling domain", "sibling", siblingName)
+		r.Recorder.Eventf(reg, corev1.EventTypeNormal, EventSiblingRegistered,
+			"Registered sibling domain %s redirecting to %s", siblingName, reg.ASCIIDomainName())
+		return nil
+	}
+
+	spec := sibling.Spec.DeepCopy()
+	applySiblingSpec(spec, reg, siblingName)
+	if !reflect.DeepEqual(spec, &sibling.Spec) {
+		sibling.Spec = *spec
+		if err := r.Update(ctx, &sibling); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isSiblingOf(sibling *domainv1beta1.CustomDomainRegistration, reg *domainv1beta1.CustomDomainRegistration) bool {
+	return sibling.Labels[SiblingOfLabel] == reg.Name && slice.ContainsOwnerReference(sibling.OwnerReferences, reg)
+}
+
+func siblingOwnerReference(reg *domainv1beta1.CustomDomainRegistration) metav1.OwnerReference {
+	return metav1.OwnerReference{
+		APIVersion: domainv1beta1.GroupVersion.String(),
+		Kind:       "CustomDomainRegistration",
+		Name:       reg.Name,
+		UID:        reg.UID,
+	}
+}
+
+// applySiblingSpec sets fields of sibling registration derived from reg.
+// Other fields (e.g. verifyAt) are left for users to manage.
+func applySiblingSpec(spec *domainv1beta1.CustomDomainRegistrationSpec, reg *domainv1beta1.CustomDomainRegistration, siblingName string) {
+	spec.DomainName = siblingName
+	spec.Role = reg.Spec.Role
+	spec.LoadBalancerClass = reg.Spec.LoadBalancerClass
+	spec.DedicatedIP = reg.Spec.DedicatedIP
+	spec.DomainConfig = domainv1beta1.CustomDomainConfig{
+		BackendServiceName: reg.Spec.DomainConfig.BackendServiceName,
+		BackendServicePort: reg.Spec.DomainConfig.BackendServicePort,
+	}
+	spec.TLS = nil
+	if reg.Spec.TLS != nil && reg.Spec.TLS.IssuerRef != nil {
+		spec.TLS = &domainv1beta1.CustomDomainTLS{IssuerRef: reg.Spec.TLS.IssuerRef.DeepCopy()}
+	}
+	spec.Routing = reg.Spec.Routing.DeepCopy()
+	spec.Verification = reg.Spec.Verification.DeepCopy()
+	spec.Redirect = &domainv1beta1.CustomDomainRedirect{
+		URL:          "https://" + reg.ASCIIDomainName(),
+		StatusCode:   siblingRedirectStatusCode,
+		PreservePath: true,
+	}
+	spec.RedirectSibling = false
+}
@@ -16,6 +16,7 @@ limitations under the License.
 package controllers_test
 
 import (
+	"net/http"
 	"path/filepath"
 	"testing"
 	"time"
@@ -36,6 +37,7 @@ import (
 	"github.com/skygeario/k8s-controller/controllers"
 	internaltest "github.com/skygeario/k8s-controller/internal/test"
 	"github.com/skygeario/k8s-controller/pkg/domain/ingress/nginx"
+	routingingress "github.com/skygeario/k8s-controller/pkg/domain/routing/ingress"
 	"github.com/skygeario/k8s-controller/pkg/domain/verification"
 	// +kubebuilder:scaffold:imports
 )
@@ -45,10 +47,8 @@ import (
 
 var cfg *rest.Config
 var k8sClient client.Client
-var testEnv *envtest.Environment
-var mgrStop chan struct{}
-
-var domainChecker = internaltest.NewDomainChecker()
+var testEnv *internaltest.Environment
+var dnsServer *internaltest.DNSServer
 
 func TestAPIs(t *testing.T) {
 	RegisterFailHandler(Fail)
@@ -65,57 +65,28 @@ var _ = BeforeSuite(func(done Done) {
 	controllers.PollInterval = 1 * time.Second
 
 	By("bootstrapping test environment")
-	testEnv = &envtest.Environment{
-		CRDDirectoryPaths: []string{filepath.Join("..", "config", "crd", "bases")},
-	}
-
-	var err error
-	cfg, err = testEnv.Start()
-	Expect(err).ToNot(HaveOccurred())
-	Expect(cfg).ToNot(BeNil())
-
-	err = domainv1beta1.AddToScheme(scheme.Scheme)
+	err := domainv1beta1.AddToScheme(scheme.Scheme)
 	Expect(err).NotTo(HaveOccurred())
 
 	// +kubebuilder:scaffold:scheme
-	mgr, err := ctrl.NewManager(cfg, ctrl.Options{Scheme: scheme.Scheme})
-	Expect(err).ToNot(HaveOccurred())
 
-	tlsProvider := internaltest.NewTLSProvider(mgr.GetClient())
-	loadBalancer := internaltest.NewLoadBalancer()
-	ingressProvider, err := nginx.NewProvider()
+	testEnv = internaltest.NewEnvironment(filepath.Join("..", "config", "crd", "bases"))
+	err = testEnv.Start(scheme.Scheme)
 	Expect(err).ToNot(HaveOccurred())
+	cfg = testEnv.Config
+	Expect(cfg).ToNot(BeNil())
+	dnsServer = testEnv.DNS
 
-	err = (&controllers.CustomDomainRegistrationReconciler{
-		Client:                     mgr.GetClient(),
-		Log:                        ctrl.Log.WithName("controllers").WithName("CustomDomainRegistration"),
-		Scheme:                     mgr.GetScheme(),
-		Now:                        metav1.Now,
-		VerificationTokenGenerator: verification.GenerateDomainToken,
-		DomainVerifier:             domainChecker.VerifyDomain,
-		TLSProvider:                tlsProvider,
-		IngressProvider:            ingressProvider,
-	}).SetupWithManager(mgr)
+	mgr := testEnv.Manager
+	dnsResolver := testEnv.Resolver()
+
+	err = newRegistrationReconciler(mgr, dnsResolver).SetupWithManager(mgr)
 	Expect(err).ToNot(HaveOccurred())
 
-	err = (&controllers.CustomDomainReconciler{
-		Client:                   mgr.GetClient(),
-		Log:                      ctrl.Log.WithName("controllers").WithName("CustomDomain"),
-		Scheme:                   mgr.GetScheme(),
-		Now:                      metav1.Now,
-		LoadBalancer:             loadBalancer,
-		VerificationKeyGenerator: internaltest.DomainKeyGenerator,
-	}).SetupWithManager(mgr)
+	err = newDomainReconciler(mgr).SetupWithManager(mgr)
 	Expect(err).ToNot(HaveOccurred())
 
-	go func() {
-		if mgrStop != nil {
-			close(mgrStop)
-		}
-		mgrStop = make(chan struct{})
-		err = mgr.Start(mgrStop)
-		Expect(err).ToNot(HaveOccurred())
-	}()
+	Expect(testEnv.StartManager()).To(Succeed())
 
 	k8sClient = mgr.GetClient()
 	Expect(k8sClient).ToNot(BeNil())
@@ -124,8 +95,49 @@ var _ = BeforeSuite(func(done Done) {
 }, 60)
 
 var _ = AfterSuite(func() {
-	close(mgrStop)
 	By("tearing down the test environment")
 	err := testEnv.Stop()
 	Expect(err).ToNot(HaveOccurred())
 })
+
+// newRegistrationReconciler returns the registration reconciler of tests,
+// verifying domains with DNS resolver.
+func newRegistrationReconciler(mgr ctrl.Manager, dnsResolver *verification.RateLimitedResolver) *controllers.CustomDomainRegistrationReconciler {
+	tlsProvider := internaltest.NewTLSProvider(mgr.GetClient())
+	ingressProvider, err := nginx.NewProvider()
+	Expect(err).ToNot(HaveOccurred())
+	routingProvider, err := routingingress.NewProvider(mgr.GetClient(), ingressProvider)
+	Expect(err).ToNot(HaveOccurred())
+
+	domainVerifier := verification.NewVerifier(
+		verification.NewDNSVerifier(dnsResolver),
+		verification.NewHTTPVerifier(http.DefaultClient),
+		verification.NewCNAMEVerifier(dnsResolver),
+	)
+
+	return &controllers.CustomDomainRegistrationReconciler{
+		Client:                     mgr.GetClient(),
+		Log:                        ctrl.Log.WithName("controllers").WithName("CustomDomainRegistration"),
+		Scheme:                     mgr.GetScheme(),
+		Now:                        metav1.Now,
+		VerificationTokenGenerator: verification.HMACTokenGenerator{},
+		DomainVerifier:             domainVerifier.VerifyDomain,
+		DNSConfigChecker:           verification.NewDNSConfigChecker(dnsResolver).CheckDNSConfig,
+		TLSProvider:                tlsProvider,
+		RoutingProvider:            routingProvider,
+		Recorder:                   mgr.GetEventRecorderFor("customdomainregistration-controller"),
+	}
+}
+
+// newDomainReconciler returns the domain reconciler of tests.
+func newDomainReconciler(mgr ctrl.Manager) *controllers.CustomDomainReconciler {
+	return &controllers.CustomDomainReconciler{
+		Client:                   mgr.GetClient(),
+		Log:                      ctrl.Log.WithName("controllers").WithName("CustomDomain"),
+		Scheme:                   mgr.GetScheme(),
+		Now:                      metav1.Now,
+		LoadBalancer:             internaltest.NewLoadBalancer(),
+		VerificationKeyGenerator: internaltest.DomainKeyGenerator,
+		Recorder:                 mgr.GetEventRecorderFor("customdomain-controller"),
+	}
+}
@@ -0,0 +1,167 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+
+	"github.com/skygeario/k8s-controller/api"
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+)
+
+func TestTakeover(t *testing.T) {
+	now := metav1.NewTime(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	newRegistration := func(namespace string, uid types.UID, verified bool, since time.Duration) *domainv1beta1.CustomDomainRegistration {
+		status := metav1.ConditionFalse
+		if verified {
+			status = metav1.ConditionTrue
+		}
+		return &domainv1beta1.CustomDomainRegistration{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "example.com", UID: uid},
+			Spec:       domainv1beta1.CustomDomainRegistrationSpec{DomainName: "example.com"},
+			Status: domainv1beta1.CustomDomainRegistrationStatus{
+				Conditions: []api.Condition{{
+					Type:               string(domainv1beta1.RegistrationVerified),
+					Status:             status,
+					LastTransitionTime: metav1.NewTime(now.Add(-since)),
+				}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name               string
+		failures           int
+		conclusiveFailures int
+		ownerUnverifiedFor time.Duration
+		claimantFor        time.Duration
+		noClaimant         bool
+		expectedOwner      string
+		expectedCheckTime  *time.Time
+	}{
+		{
+			name:               "confirmed",
+			failures:           3,
+			conclusiveFailures: 3,
+			ownerUnverifiedFor: 2 * time.Hour,
+			claimantFor:        2 * time.Hour,
+			expectedOwner:      "claimant",
+		},
+		{
+			name:               "not enough failures",
+			failures:           2,
+			conclusiveFailures: 2,
+			ownerUnverifiedFor: 2 * time.Hour,
+			claimantFor:        2 * time.Hour,
+			expectedOwner:      "owner",
+		},
+		{
+			name:               "transient server failures",
+			failures:           10,
+			conclusiveFailures: 1,
+			ownerUnverifiedFor: 2 * time.Hour,
+			claimantFor:        2 * time.Hour,
+			expectedOwner:      "owner",
+		},
+		{
+			name:               "owner within window",
+			failures:           3,
+			conclusiveFailures: 3,
+			ownerUnverifiedFor: 20 * time.Minute,
+			claimantFor:        2 * time.Hour,
+			expectedOwner:      "owner",
+			expectedCheckTime:  timePtr(now.Add(40 * time.Minute)),
+		},
+		{
+			name:               "claimant within window",
+			failures:           3,
+			conclusiveFailures: 3,
+			ownerUnverifiedFor: 2 * time.Hour,
+			claimantFor:        30 * time.Minute,
+			expectedOwner:      "owner",
+			expectedCheckTime:  timePtr(now.Add(30 * time.Minute)),
+		},
+		{
+			name:               "no claimant",
+			failures:           3,
+			conclusiveFailures: 3,
+			ownerUnverifiedFor: 2 * time.Hour,
+			noClaimant:         true,
+			expectedOwner:      "owner",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			owner := newRegistration("owner", "owner-uid", false, tt.ownerUnverifiedFor)
+			owner.Status.VerificationFailureCount = tt.failures
+			owner.Status.ConclusiveFailureCount = tt.conclusiveFailures
+			ownerRef := corev1.ObjectReference{Namespace: "owner", Name: "example.com", UID: "owner-uid"}
+			d := &domainv1beta1.CustomDomain{
+				ObjectMeta: metav1.ObjectMeta{Name: "example.com"},
+				Spec: domainv1beta1.CustomDomainSpec{
+					OwnerApp:        pointer.StringPtr("owner"),
+					OwnerRef:        &ownerRef,
+					VerificationKey: pointer.StringPtr("key"),
+					Registrations: []domainv1beta1.CustomDomainRegistrationReference{
+						{ObjectReference: ownerRef},
+					},
+				},
+			}
+			objs := []runtime.Object{owner}
+			if !tt.noClaimant {
+				objs = append(objs, newRegistration("claimant", "claimant-uid", true, tt.claimantFor))
+				d.Spec.Registrations = append(d.Spec.Registrations, domainv1beta1.CustomDomainRegistrationReference{
+					ObjectReference: corev1.ObjectReference{Namespace: "claimant", Name: "example.com", UID: "claimant-uid"},
+				})
+			}
+			r := newFakeDomainReconciler(t, now, append(objs, d.DeepCopy())...)
+			r.TakeoverConfirmations = 3
+			r.TakeoverWindow = time.Hour
+
+			checkTime, err := r.processRegistrations(context.Background(), d)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d.Spec.OwnerApp == nil || *d.Spec.OwnerApp != tt.expectedOwner {
+				t.Fatalf("owner app = %v, expected %s", d.Spec.OwnerApp, tt.expectedOwner)
+			}
+			if d.Spec.OwnerRef.Namespace != tt.expectedOwner {
+				t.Errorf("owner ref = %#v", d.Spec.OwnerRef)
+			}
+			if tt.expectedCheckTime == nil {
+				if checkTime != nil {
+					t.Errorf("check time = %v, expected nil", *checkTime)
+				}
+			} else if checkTime == nil || !checkTime.Equal(*tt.expectedCheckTime) {
+				t.Errorf("check time = %v, expected %v", checkTime, *tt.expectedCheckTime)
+			}
+
+			if tt.expectedOwner != "owner" {
+				if d.Status.Takeover != nil {
+					t.Errorf("takeover status is not cleared: %#v", d.Status.Takeover)
+				}
+				if d.Status.ReleasedAt != nil {
+					t.Errorf("domain taken over is released")
+				}
+				return
+			}
+			takeover := d.Status.Takeover
+			if takeover == nil {
+				t.Fatal("takeover status is not recorded")
+			}
+			if takeover.OwnerFailureCount != tt.conclusiveFailures {
+				t.Errorf("owner failure count = %d, expected %d", takeover.OwnerFailureCount, tt.conclusiveFailures)
+			}
+			if tt.noClaimant != (takeover.Claimant == nil) {
+				t.Errorf("claimant = %#v", takeover.Claimant)
+			}
+		})
+	}
+}
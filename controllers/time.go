@@ -1,9 +1,77 @@
 package controllers
 
-import "time"
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
 
 var (
-	VerificationCooldown time.Duration = 60 * time.Second
-	VerificationTimeout  time.Duration = 5 * time.Second
-	PollInterval         time.Duration = 10 * time.Second
+	VerificationCooldown   time.Duration = 60 * time.Second
+	VerificationTimeout    time.Duration = 5 * time.Second
+	VerificationBackoffMin time.Duration = 1 * time.Minute
+	VerificationBackoffMax time.Duration = 1 * time.Hour
+	ReverificationInterval time.Duration = 1 * time.Hour
+	PollInterval           time.Duration = 10 * time.Second
+	DNSCheckInterval       time.Duration = 1 * time.Minute
+	HealthCheckInterval    time.Duration = 1 * time.Minute
+	HealthCheckTimeout     time.Duration = 10 * time.Second
+	ReconcileTimeout       time.Duration = 30 * time.Second
+
+	CertificateExpiryWarningPeriod time.Duration = 14 * 24 * time.Hour
+
+	VerificationKeyRotationInterval time.Duration = 0
+	VerificationKeyGracePeriod      time.Duration = 24 * time.Hour
+
+	OrphanedDomainTTL time.Duration = 0
+
+	// EmailChallengeInterval is the minimum interval between confirmation
+	// emails of a registration, so that contacts of domains are not flooded
+	// by changing registrations.
+	EmailChallengeInterval time.Duration = 1 * time.Hour
+	// EmailChallengeLinkTTL is the duration that confirmation links of Email
+	// verification are valid.
+	EmailChallengeLinkTTL time.Duration = 24 * time.Hour
+
+	// ReleaseQuarantinePeriod is the period that a domain released by
+	// deleting its verified owner registration cannot be claimed by other
+	// apps, disabled if zero.
+	ReleaseQuarantinePeriod time.Duration = 0
+
+	// RemoteRegistrationHeartbeatInterval is the interval that controllers of
+	// workload clusters refresh references of their registrations in domains
+	// of hub cluster.
+	RemoteRegistrationHeartbeatInterval time.Duration = 5 * time.Minute
+	// RemoteRegistrationTTL is the duration after last heartbeat that
+	// references of registrations in workload clusters are removed from
+	// domains, so that clusters gone do not hold ownership of domains.
+	RemoteRegistrationTTL time.Duration = 30 * time.Minute
+
+	// ResyncPeriod is the period of reconciling all resources to catch drift,
+	// disabled if zero. Each resource is requeued with a random jitter of up
+	// to ResyncJitter of the period, so resyncs are spread out.
+	ResyncPeriod time.Duration = 0
+	ResyncJitter float64       = 0.5
 )
+
+// resyncTime returns the time of next periodic resync, or nil if disabled.
+func resyncTime(now time.Time) *time.Time {
+	if ResyncPeriod <= 0 {
+		return nil
+	}
+	t := now.Add(wait.Jitter(ResyncPeriod, ResyncJitter))
+	return &t
+}
+
+// verificationBackoff returns the delay before retrying a failed verification,
+// doubling for each consecutive failure.
+func verificationBackoff(failures int) time.Duration {
+	backoff := VerificationBackoffMin
+	for i := 1; i < failures && backoff < VerificationBackoffMax; i++ {
+		backoff *= 2
+	}
+	if backoff > VerificationBackoffMax {
+		backoff = VerificationBackoffMax
+	}
+	return backoff
+}
@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+)
+
+func TestVerificationBackoff(t *testing.T) {
+	tests := []struct {
+		failures int
+		expected time.Duration
+	}{
+		{1, 1 * time.Minute},
+		{2, 2 * time.Minute},
+		{3, 4 * time.Minute},
+		{4, 8 * time.Minute},
+		{5, 16 * time.Minute},
+		{6, 32 * time.Minute},
+		{7, 1 * time.Hour},
+		{8, 1 * time.Hour},
+		{100, 1 * time.Hour},
+	}
+	for _, tt := range tests {
+		if backoff := verificationBackoff(tt.failures); backoff != tt.expected {
+			t.Errorf("verificationBackoff(%d) = %v, expected %v", tt.failures, backoff, tt.expected)
+		}
+	}
+}
+
+func TestNextVerificationTime(t *testing.T) {
+	r := &CustomDomainRegistrationReconciler{}
+	last := metav1.NewTime(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	tests := []struct {
+		name     string
+		failures int
+		verified bool
+		expected *time.Time
+	}{
+		{
+			name:     "first failure",
+			failures: 1,
+			expected: timePtr(last.Add(1 * time.Minute)),
+		},
+		{
+			name:     "consecutive failures",
+			failures: 4,
+			expected: timePtr(last.Add(8 * time.Minute)),
+		},
+		{
+			name:     "capped backoff",
+			failures: 20,
+			expected: timePtr(last.Add(1 * time.Hour)),
+		},
+		{
+			name:     "reset after success",
+			verified: true,
+			expected: timePtr(last.Add(ReverificationInterval)),
+		},
+		{
+			name: "unverified without failure",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg := &domainv1beta1.CustomDomainRegistration{
+				Status: domainv1beta1.CustomDomainRegistrationStatus{
+					LastVerificationTime:     &last,
+					VerificationFailureCount: tt.failures,
+				},
+			}
+			next := r.nextVerificationTime(reg, tt.verified)
+			if tt.expected == nil {
+				if next != nil {
+					t.Errorf("nextVerificationTime = %v, expected nil", *next)
+				}
+			} else if next == nil || !next.Equal(*tt.expected) {
+				t.Errorf("nextVerificationTime = %v, expected %v", next, *tt.expected)
+			}
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
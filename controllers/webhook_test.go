@@ -0,0 +1,188 @@
+package controllers_test
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/controllers"
+	internaltest "github.com/skygeario/k8s-controller/internal/test"
+	"github.com/skygeario/k8s-controller/pkg/util/condition"
+)
+
+var _ = Describe("Registration deletion with webhooks", func() {
+	const timeout = time.Second * 10
+	const interval = time.Millisecond * 100
+	const defaultDomainSuffix = "apps.test"
+
+	var env *internaltest.Environment
+	var c client.Client
+
+	BeforeEach(func() {
+		env = internaltest.NewEnvironment(filepath.Join("..", "config", "crd", "bases")).
+			WithWebhooks(filepath.Join("..", "config", "webhook"))
+		Expect(env.Start(scheme.Scheme)).To(Succeed())
+		mgr := env.Manager
+
+		selector := labels.SelectorFromSet(labels.Set{"default-domain": "enabled"})
+		regReconciler := newRegistrationReconciler(mgr, env.Resolver())
+		regReconciler.DefaultDomainSuffix = defaultDomainSuffix
+		regReconciler.DefaultDomainNamespaceSelector = selector
+		Expect(regReconciler.SetupWithManager(mgr)).To(Succeed())
+		Expect(newDomainReconciler(mgr).SetupWithManager(mgr)).To(Succeed())
+		Expect((&controllers.NamespaceReconciler{
+			Client:             mgr.GetClient(),
+			Log:                ctrl.Log.WithName("controllers").WithName("Namespace"),
+			Recorder:           mgr.GetEventRecorderFor("namespace-controller"),
+			Selector:           selector,
+			Suffix:             defaultDomainSuffix,
+			BackendServiceName: "app",
+			BackendServicePort: 80,
+		}).SetupWithManager(mgr)).To(Succeed())
+		Expect((&domainv1beta1.CustomDomainRegistration{}).SetupWebhookWithManager(mgr)).To(Succeed())
+		Expect((&domainv1beta1.CustomDomain{}).SetupWebhookWithManager(mgr)).To(Succeed())
+
+		Expect(env.StartManager()).To(Succeed())
+		c = mgr.GetClient()
+	})
+
+	AfterEach(func() {
+		Expect(env.Stop()).To(Succeed())
+	})
+
+	It("Should release serving default domain when namespace is deselected", func() {
+		ctx := context.Background()
+		ns := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "tenant",
+				Labels: map[string]string{"default-domain": "enabled"},
+			},
+		}
+		Expect(c.Create(ctx, ns)).To(Succeed())
+
+		key := types.NamespacedName{Namespace: "tenant", Name: "tenant." + defaultDomainSuffix}
+		Eventually(func() bool {
+			reg := &domainv1beta1.CustomDomainRegistration{}
+			if err := c.Get(ctx, key, reg); err != nil {
+				return false
+			}
+			return condition.IsTrue(reg.Status.Conditions, string(domainv1beta1.RegistrationVerified)) &&
+				condition.IsTrue(reg.Status.Conditions, string(domainv1beta1.RegistrationIngressReady))
+		}, timeout, interval).Should(BeTrue())
+		// Default domain is serving
+
+		reg := &domainv1beta1.CustomDomainRegistration{}
+		Expect(c.Get(ctx, key, reg)).To(Succeed())
+		// Denial by webhook of controller-runtime v0.4 carries the message as
+		// status reason, so check the status code instead of IsForbidden.
+		err := c.Delete(ctx, reg)
+		Expect(err).To(HaveOccurred())
+		status, ok := err.(apierrors.APIStatus)
+		Expect(ok).To(BeTrue())
+		Expect(status.Status().Code).To(BeEquivalentTo(http.StatusForbidden))
+		// Direct deletion is rejected
+
+		Expect(c.Get(ctx, types.NamespacedName{Name: ns.Name}, ns)).To(Succeed())
+		delete(ns.Labels, "default-domain")
+		Expect(c.Update(ctx, ns)).To(Succeed())
+
+		Eventually(func() bool {
+			err := c.Get(ctx, key, &domainv1beta1.CustomDomainRegistration{})
+			return apierrors.IsNotFound(err)
+		}, timeout, interval).Should(BeTrue())
+		// Default domain is released
+	})
+
+	It("Should not verify default domain of unselected namespace", func() {
+		ctx := context.Background()
+		Expect(c.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "unselected"}})).To(Succeed())
+
+		domainName := "unselected." + defaultDomainSuffix
+		reg := &domainv1beta1.CustomDomainRegistration{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "unselected",
+				Name:      domainName,
+			},
+			Spec: domainv1beta1.CustomDomainRegistrationSpec{
+				DomainName: domainName,
+				DomainConfig: domainv1beta1.CustomDomainConfig{
+					BackendServiceName: "app",
+					BackendServicePort: 80,
+				},
+			},
+		}
+		// Webhooks may not be ready right after manager is started.
+		Eventually(func() error {
+			return c.Create(ctx, reg.DeepCopy())
+		}, timeout, interval).Should(Succeed())
+
+		key := types.NamespacedName{Namespace: reg.Namespace, Name: reg.Name}
+		verifiedStatus := func() metav1.ConditionStatus {
+			reg := &domainv1beta1.CustomDomainRegistration{}
+			Expect(c.Get(ctx, key, reg)).To(Succeed())
+			if cond := condition.Lookup(reg.Status.Conditions, string(domainv1beta1.RegistrationVerified)); cond != nil {
+				return cond.Status
+			}
+			return ""
+		}
+		Eventually(verifiedStatus, timeout, interval).Should(Equal(metav1.ConditionFalse))
+		Consistently(verifiedStatus, 3*time.Second, interval).Should(Equal(metav1.ConditionFalse))
+		// Registration created by hand is not verified as default domain
+	})
+
+	It("Should delete unverified registration after expiry", func() {
+		ctx := context.Background()
+		Expect(c.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "expiry"}})).To(Succeed())
+
+		expiresAt := metav1.NewTime(time.Now().Add(3 * time.Second))
+		reg := &domainv1beta1.CustomDomainRegistration{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "expiry",
+				Name:      "expiring.test",
+			},
+			Spec: domainv1beta1.CustomDomainRegistrationSpec{
+				DomainName: "expiring.test",
+				DomainConfig: domainv1beta1.CustomDomainConfig{
+					BackendServiceName: "app",
+					BackendServicePort: 80,
+				},
+				ExpiresAt: &expiresAt,
+			},
+		}
+		// Webhooks may not be ready right after manager is started.
+		Eventually(func() error {
+			return c.Create(ctx, reg.DeepCopy())
+		}, timeout, interval).Should(Succeed())
+
+		Eventually(func() bool {
+			err := c.Get(ctx, types.NamespacedName{Namespace: reg.Namespace, Name: reg.Name}, &domainv1beta1.CustomDomainRegistration{})
+			return apierrors.IsNotFound(err)
+		}, timeout, interval).Should(BeTrue())
+		// Registration is deleted
+
+		Eventually(func() bool {
+			events := &corev1.EventList{}
+			Expect(c.List(ctx, events, client.InNamespace(reg.Namespace))).To(Succeed())
+			for _, event := range events.Items {
+				if event.InvolvedObject.Name == reg.Name && event.Reason == controllers.EventRegistrationExpired {
+					return true
+				}
+			}
+			return false
+		}, timeout, interval).Should(BeTrue())
+		// Expiry is recorded
+	})
+})
@@ -0,0 +1,45 @@
+#!/usr/bin/env bash
+
+# Generates typed clientset, listers and informers of the domain API group
+# in pkg/client. Requires client-gen, lister-gen and informer-gen of
+# k8s.io/code-generator v0.17.0 in PATH.
+
+set -o errexit
+set -o nounset
+set -o pipefail
+
+SCRIPT_ROOT=$(cd "$(dirname "${BASH_SOURCE[0]}")/.." && pwd)
+MODULE=github.com/skygeario/k8s-controller
+API_PKG=${MODULE}/api/v1beta1
+OUTPUT_PKG=${MODULE}/pkg/client
+HEADER=${SCRIPT_ROOT}/hack/boilerplate.go.txt
+
+OUTPUT_BASE=$(mktemp -d)
+trap 'rm -rf "${OUTPUT_BASE}"' EXIT
+
+cd "${SCRIPT_ROOT}"
+
+client-gen \
+  --clientset-name versioned \
+  --input-base "" \
+  --input "${API_PKG}" \
+  --output-package "${OUTPUT_PKG}/clientset" \
+  --output-base "${OUTPUT_BASE}" \
+  --go-header-file "${HEADER}"
+
+lister-gen \
+  --input-dirs "${API_PKG}" \
+  --output-package "${OUTPUT_PKG}/listers" \
+  --output-base "${OUTPUT_BASE}" \
+  --go-header-file "${HEADER}"
+
+informer-gen \
+  --input-dirs "${API_PKG}" \
+  --versioned-clientset-package "${OUTPUT_PKG}/clientset/versioned" \
+  --listers-package "${OUTPUT_PKG}/listers" \
+  --output-package "${OUTPUT_PKG}/informers" \
+  --output-base "${OUTPUT_BASE}" \
+  --go-header-file "${HEADER}"
+
+rm -rf "${SCRIPT_ROOT}/pkg/client"
+cp -R "${OUTPUT_BASE}/${OUTPUT_PKG}" "${SCRIPT_ROOT}/pkg/client"
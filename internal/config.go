@@ -1,11 +1,289 @@
 package internal
 
 import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/skygeario/k8s-controller/pkg/domain/dnsprovider/dnsendpoint"
+	"github.com/skygeario/k8s-controller/pkg/domain/externaldns"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer/kubernetes"
 	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer/staticip"
+	"github.com/skygeario/k8s-controller/pkg/domain/routing/gatewayapi"
+	"github.com/skygeario/k8s-controller/pkg/domain/routing/istio"
+	"github.com/skygeario/k8s-controller/pkg/domain/tls/acme"
 	"github.com/skygeario/k8s-controller/pkg/domain/tls/certmanager"
 )
 
+// ConfigurationAPIVersion and ConfigurationKind identify the configuration
+// file of the controller.
+const (
+	ConfigurationAPIVersion = "config.domain.skygear.io/v1alpha1"
+	ConfigurationKind       = "DomainControllerConfiguration"
+)
+
+// HotReloadableFlags are flags whose values in configuration file are
+// applied without restarting the controller.
+var HotReloadableFlags = []string{
+	"dns-qps",
+	"log-domains",
+	"trace-sample-ratio",
+}
+
+// Config is the configuration of providers.
 type Config struct {
 	StaticIP    *staticip.Config
+	Kubernetes  *kubernetes.Config
 	CertManager *certmanager.Config
+	ACME        *acme.Config
+	GatewayAPI  *gatewayapi.Config
+	Istio       *istio.Config
+	ExternalDNS *externaldns.Config
+	DNSEndpoint *dnsendpoint.Config
+}
+
+// DomainControllerConfiguration is the configuration file of the controller.
+// Settings in the file are defaults of the corresponding command line flags,
+// so flags given explicitly take precedence. Provider configuration is
+// inlined, so that configuration files without apiVersion and kind remain
+// valid.
+type DomainControllerConfiguration struct {
+	metav1.TypeMeta `json:",inline"`
+	Config
+
+	DNS           DNSConfiguration           `json:"dns,omitempty"`
+	Verification  VerificationConfiguration  `json:"verification,omitempty"`
+	Intervals     IntervalConfiguration      `json:"intervals,omitempty"`
+	Concurrency   ConcurrencyConfiguration   `json:"concurrency,omitempty"`
+	Tracing       TracingConfiguration       `json:"tracing,omitempty"`
+	DefaultDomain DefaultDomainConfiguration `json:"defaultDomain,omitempty"`
+
+	IngressGenerator string          `json:"ingressGenerator,omitempty"`
+	LogDomains       []string        `json:"logDomains,omitempty"`
+	EnableWebhooks   *bool           `json:"enableWebhooks,omitempty"`
+	DryRun           *bool           `json:"dryRun,omitempty"`
+	FeatureGates     map[string]bool `json:"featureGates,omitempty"`
+}
+
+type DNSConfiguration struct {
+	Servers       []string `json:"servers,omitempty"`
+	QPS           *float64 `json:"qps,omitempty"`
+	Burst         *int     `json:"burst,omitempty"`
+	Authoritative *bool    `json:"authoritative,omitempty"`
+	// View is the DNS view of verification, local or external.
+	View string `json:"view,omitempty"`
+	// ExternalServers are public resolvers used in external view.
+	ExternalServers []string `json:"externalServers,omitempty"`
+	// CacheTTL and NegativeCacheTTL are durations verification DNS answers
+	// and non-existent names are cached.
+	CacheTTL         *metav1.Duration `json:"cacheTTL,omitempty"`
+	NegativeCacheTTL *metav1.Duration `json:"negativeCacheTTL,omitempty"`
+}
+
+type VerificationConfiguration struct {
+	Resolvers      []string `json:"resolvers,omitempty"`
+	ResolverQuorum *int     `json:"resolverQuorum,omitempty"`
+	ChallengeZone  string   `json:"challengeZone,omitempty"`
+	CNAMETarget    string   `json:"cnameTarget,omitempty"`
+	RecordPrefix   string   `json:"recordPrefix,omitempty"`
+	TokenGenerator string   `json:"tokenGenerator,omitempty"`
+	// InheritParentVerification verifies subdomains of domains verified in
+	// the same namespace.
+	InheritParentVerification *bool `json:"inheritParentVerification,omitempty"`
+	// RequireApproval is the registrations requiring approval of operators:
+	// All or ApexDomains.
+	RequireApproval string `json:"requireApproval,omitempty"`
+	// TakeoverConfirmations is the number of consecutive failed
+	// verifications of owner registration, observing the verification
+	// record missing or mismatched, before ownership is revoked.
+	TakeoverConfirmations *int `json:"takeoverConfirmations,omitempty"`
+	// Email configures Email verification.
+	Email EmailVerificationConfiguration `json:"email,omitempty"`
+	// BlockedCIDRs are CIDRs that HTTP verification and health checks must
+	// not connect to, e.g. pod and service CIDRs of the cluster.
+	BlockedCIDRs []string `json:"blockedCIDRs,omitempty"`
+}
+
+// EmailVerificationConfiguration configures sending confirmation emails of
+// Email verification.
+type EmailVerificationConfiguration struct {
+	SMTPAddress      string           `json:"smtpAddress,omitempty"`
+	SMTPUsername     string           `json:"smtpUsername,omitempty"`
+	SMTPPasswordFile string           `json:"smtpPasswordFile,omitempty"`
+	From             string           `json:"from,omitempty"`
+	ConfirmationURL  string           `json:"confirmationURL,omitempty"`
+	RDAPBaseURL      string           `json:"rdapBaseURL,omitempty"`
+	LinkTTL          *metav1.Duration `json:"linkTTL,omitempty"`
+}
+
+type IntervalConfiguration struct {
+	Reverify                    *metav1.Duration `json:"reverify,omitempty"`
+	VerificationBackoffMin      *metav1.Duration `json:"verificationBackoffMin,omitempty"`
+	VerificationBackoffMax      *metav1.Duration `json:"verificationBackoffMax,omitempty"`
+	ReconcileTimeout            *metav1.Duration `json:"reconcileTimeout,omitempty"`
+	Resync                      *metav1.Duration `json:"resync,omitempty"`
+	DNSCheck                    *metav1.Duration `json:"dnsCheck,omitempty"`
+	CertificateExpiryWarning    *metav1.Duration `json:"certificateExpiryWarning,omitempty"`
+	VerificationKeyRotation     *metav1.Duration `json:"verificationKeyRotation,omitempty"`
+	VerificationKeyGracePeriod  *metav1.Duration `json:"verificationKeyGracePeriod,omitempty"`
+	OrphanedDomainTTL           *metav1.Duration `json:"orphanedDomainTTL,omitempty"`
+	ReleaseQuarantine           *metav1.Duration `json:"releaseQuarantine,omitempty"`
+	TakeoverWindow              *metav1.Duration `json:"takeoverWindow,omitempty"`
+	RemoteRegistrationHeartbeat *metav1.Duration `json:"remoteRegistrationHeartbeat,omitempty"`
+	RemoteRegistrationTTL       *metav1.Duration `json:"remoteRegistrationTTL,omitempty"`
+}
+
+type ConcurrencyConfiguration struct {
+	CustomDomain             int `json:"customDomain,omitempty"`
+	CustomDomainRegistration int `json:"customDomainRegistration,omitempty"`
+}
+
+type TracingConfiguration struct {
+	OTLPEndpoint string   `json:"otlpEndpoint,omitempty"`
+	SampleRatio  *float64 `json:"sampleRatio,omitempty"`
+}
+
+// DefaultDomainConfiguration configures provisioning of default domains
+// <namespace>.<suffix> for selected namespaces.
+type DefaultDomainConfiguration struct {
+	Suffix             string `json:"suffix,omitempty"`
+	NamespaceSelector  string `json:"namespaceSelector,omitempty"`
+	BackendServiceName string `json:"backendServiceName,omitempty"`
+	BackendServicePort *int   `json:"backendServicePort,omitempty"`
+}
+
+// LoadConfiguration reads the configuration file.
+func LoadConfiguration(path string) (*DomainControllerConfiguration, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseConfiguration(data)
+}
+
+// ParseConfiguration parses content of the configuration file.
+func ParseConfiguration(data []byte) (*DomainControllerConfiguration, error) {
+	var config DomainControllerConfiguration
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+	if config.APIVersion != "" && config.APIVersion != ConfigurationAPIVersion {
+		return nil, fmt.Errorf("unsupported configuration apiVersion %q", config.APIVersion)
+	}
+	if config.Kind != "" && config.Kind != ConfigurationKind {
+		return nil, fmt.Errorf("unsupported configuration kind %q", config.Kind)
+	}
+	return &config, nil
+}
+
+// Flags returns values of command line flags specified by the
+// configuration, keyed by flag name.
+func (c *DomainControllerConfiguration) Flags() map[string]string {
+	flags := map[string]string{}
+	setString := func(name string, value string) {
+		if value != "" {
+			flags[name] = value
+		}
+	}
+	setList := func(name string, values []string) {
+		if len(values) != 0 {
+			flags[name] = strings.Join(values, ",")
+		}
+	}
+	setDuration := func(name string, d *metav1.Duration) {
+		if d != nil {
+			flags[name] = d.Duration.String()
+		}
+	}
+	setFloat := func(name string, f *float64) {
+		if f != nil {
+			flags[name] = strconv.FormatFloat(*f, 'g', -1, 64)
+		}
+	}
+	setInt := func(name string, i *int) {
+		if i != nil {
+			flags[name] = strconv.Itoa(*i)
+		}
+	}
+	setBool := func(name string, b *bool) {
+		if b != nil {
+			flags[name] = strconv.FormatBool(*b)
+		}
+	}
+
+	setList("dns-servers", c.DNS.Servers)
+	setFloat("dns-qps", c.DNS.QPS)
+	setInt("dns-burst", c.DNS.Burst)
+	setBool("dns-authoritative", c.DNS.Authoritative)
+	setString("verification-view", c.DNS.View)
+	setList("external-dns-servers", c.DNS.ExternalServers)
+	setDuration("dns-cache-ttl", c.DNS.CacheTTL)
+	setDuration("dns-negative-cache-ttl", c.DNS.NegativeCacheTTL)
+
+	setList("verification-resolvers", c.Verification.Resolvers)
+	setInt("verification-resolver-quorum", c.Verification.ResolverQuorum)
+	setString("verification-challenge-zone", c.Verification.ChallengeZone)
+	setString("verification-cname-target", c.Verification.CNAMETarget)
+	setString("verification-record-prefix", c.Verification.RecordPrefix)
+	setString("verification-token-generator", c.Verification.TokenGenerator)
+	setBool("inherit-parent-verification", c.Verification.InheritParentVerification)
+	setString("require-approval", c.Verification.RequireApproval)
+	setInt("takeover-confirmations", c.Verification.TakeoverConfirmations)
+	setString("email-verification-smtp-address", c.Verification.Email.SMTPAddress)
+	setString("email-verification-smtp-username", c.Verification.Email.SMTPUsername)
+	setString("email-verification-smtp-password-file", c.Verification.Email.SMTPPasswordFile)
+	setString("email-verification-from", c.Verification.Email.From)
+	setString("email-verification-url", c.Verification.Email.ConfirmationURL)
+	setString("rdap-base-url", c.Verification.Email.RDAPBaseURL)
+	setDuration("email-verification-link-ttl", c.Verification.Email.LinkTTL)
+	setList("blocked-cidrs", c.Verification.BlockedCIDRs)
+
+	setDuration("reverify-interval", c.Intervals.Reverify)
+	setDuration("verification-backoff-min", c.Intervals.VerificationBackoffMin)
+	setDuration("verification-backoff-max", c.Intervals.VerificationBackoffMax)
+	setDuration("reconcile-timeout", c.Intervals.ReconcileTimeout)
+	setDuration("resync-period", c.Intervals.Resync)
+	setDuration("dns-check-interval", c.Intervals.DNSCheck)
+	setDuration("cert-expiry-warning-period", c.Intervals.CertificateExpiryWarning)
+	setDuration("verification-key-rotation-interval", c.Intervals.VerificationKeyRotation)
+	setDuration("verification-key-grace-period", c.Intervals.VerificationKeyGracePeriod)
+	setDuration("orphaned-domain-ttl", c.Intervals.OrphanedDomainTTL)
+	setDuration("release-quarantine-period", c.Intervals.ReleaseQuarantine)
+	setDuration("takeover-window", c.Intervals.TakeoverWindow)
+	setDuration("remote-registration-heartbeat-interval", c.Intervals.RemoteRegistrationHeartbeat)
+	setDuration("remote-registration-ttl", c.Intervals.RemoteRegistrationTTL)
+
+	if c.Concurrency.CustomDomain > 0 {
+		flags["domain-concurrency"] = strconv.Itoa(c.Concurrency.CustomDomain)
+	}
+	if c.Concurrency.CustomDomainRegistration > 0 {
+		flags["registration-concurrency"] = strconv.Itoa(c.Concurrency.CustomDomainRegistration)
+	}
+
+	setString("otlp-endpoint", c.Tracing.OTLPEndpoint)
+	setFloat("trace-sample-ratio", c.Tracing.SampleRatio)
+
+	setString("default-domain-suffix", c.DefaultDomain.Suffix)
+	setString("default-domain-namespace-selector", c.DefaultDomain.NamespaceSelector)
+	setString("default-domain-backend-service", c.DefaultDomain.BackendServiceName)
+	setInt("default-domain-backend-port", c.DefaultDomain.BackendServicePort)
+
+	setString("ingress-generator", c.IngressGenerator)
+	setList("log-domains", c.LogDomains)
+	setBool("enable-webhooks", c.EnableWebhooks)
+	setBool("dry-run", c.DryRun)
+	if len(c.FeatureGates) != 0 {
+		var gates []string
+		for name, enabled := range c.FeatureGates {
+			gates = append(gates, fmt.Sprintf("%s=%t", name, enabled))
+		}
+		sort.Strings(gates)
+		flags["feature-gates"] = strings.Join(gates, ",")
+	}
+	return flags
 }
@@ -0,0 +1,32 @@
+package internal
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/skygeario/k8s-controller/pkg/domain/dnsprovider"
+	"github.com/skygeario/k8s-controller/pkg/domain/dnsprovider/dnsendpoint"
+	"github.com/skygeario/k8s-controller/pkg/domain/dnsprovider/zoned"
+	"github.com/skygeario/k8s-controller/pkg/features"
+)
+
+// NewDNSProvider creates the configured DNS provider, or nil if DNS records
+// are not managed by the controller. If feature gate DNSProviderConfigs is
+// enabled, the configured provider is used for domains not in zones of any
+// DNSProviderConfig.
+func NewDNSProvider(client client.Client, config Config) (dnsprovider.Provider, error) {
+	var p dnsprovider.Provider
+	if config.DNSEndpoint != nil {
+		var err error
+		p, err = dnsendpoint.NewProvider(client, *config.DNSEndpoint)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create DNSEndpoint DNS provider: %w", err)
+		}
+	}
+
+	if features.Enabled(features.DNSProviderConfigs) {
+		return zoned.NewProvider(client, p), nil
+	}
+	return p, nil
+}
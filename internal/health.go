@@ -0,0 +1,54 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+
+	"github.com/skygeario/k8s-controller/pkg/domain/verification"
+)
+
+// resolverCheckTimeout is the timeout of checking DNS resolvers.
+const resolverCheckTimeout = 5 * time.Second
+
+// TokenSecretChecker fails if verification token secrets cannot be loaded.
+func TokenSecretChecker(g *verification.SecretTokenGenerator) healthz.Checker {
+	return func(_ *http.Request) error {
+		if err := g.LoadError(); err != nil {
+			return fmt.Errorf("cannot load verification token secrets: %w", err)
+		}
+		return nil
+	}
+}
+
+// ResolverChecker fails if any of the DNS resolvers is unreachable.
+func ResolverChecker(resolvers ...*verification.RateLimitedResolver) healthz.Checker {
+	return func(req *http.Request) error {
+		ctx, cancel := context.WithTimeout(req.Context(), resolverCheckTimeout)
+		defer cancel()
+		for _, r := range resolvers {
+			if err := r.Check(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// WebhookCertChecker fails if serving certificate of webhook server is
+// missing in the certificate directory.
+func WebhookCertChecker(certDir string) healthz.Checker {
+	return func(_ *http.Request) error {
+		for _, name := range []string{"tls.crt", "tls.key"} {
+			if _, err := os.Stat(filepath.Join(certDir, name)); err != nil {
+				return fmt.Errorf("webhook certificate is missing: %w", err)
+			}
+		}
+		return nil
+	}
+}
@@ -3,6 +3,7 @@ package internal
 import (
 	"fmt"
 
+	"github.com/skygeario/k8s-controller/pkg/domain/externaldns"
 	"github.com/skygeario/k8s-controller/pkg/domain/ingress"
 	"github.com/skygeario/k8s-controller/pkg/domain/ingress/nginx"
 )
@@ -13,5 +14,22 @@ func NewIngressProvider(config Config) (ingress.Provider, error) {
 		return nil, fmt.Errorf("cannot create nginx ingress provider: %w", err)
 	}
 
+	p.ExternalDNS, err = NewExternalDNSAnnotator(config)
+	if err != nil {
+		return nil, err
+	}
+
 	return p, nil
 }
+
+func NewExternalDNSAnnotator(config Config) (*externaldns.Annotator, error) {
+	if config.ExternalDNS == nil {
+		return nil, nil
+	}
+
+	a, err := externaldns.NewAnnotator(*config.ExternalDNS)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create external-dns annotator: %w", err)
+	}
+	return a, nil
+}
@@ -6,19 +6,28 @@ import (
 
 	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
 	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer/dedicatedip"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer/kubernetes"
 	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer/staticip"
+	"github.com/skygeario/k8s-controller/pkg/features"
 	"golang.org/x/net/publicsuffix"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
-	loadBalancerStaticIP string = "static-ip"
+	loadBalancerStaticIP    string = "static-ip"
+	loadBalancerKubernetes  string = "kubernetes"
+	loadBalancerDedicatedIP string = "dedicated-ip"
 )
 
 type LoadBalancer struct {
-	StaticIP *staticip.Provider
+	StaticIP    *staticip.Provider
+	Kubernetes  *kubernetes.Provider
+	DedicatedIP *dedicatedip.Provider
 }
 
-func NewLoadBalancer(config Config) (*LoadBalancer, error) {
+func NewLoadBalancer(client client.Client, config Config) (*LoadBalancer, error) {
 	var err error
 	var staticIP *staticip.Provider
 	if config.StaticIP != nil {
@@ -28,11 +37,32 @@ func NewLoadBalancer(config Config) (*LoadBalancer, error) {
 		}
 	}
 
+	var kube *kubernetes.Provider
+	if config.Kubernetes != nil {
+		kube, err = kubernetes.NewProvider(client, *config.Kubernetes)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create Kubernetes load balancer provider: %w", err)
+		}
+		kube.LookupEndpoints = features.Enabled(features.LoadBalancerEndpoints)
+	}
+
+	var dedicatedIP *dedicatedip.Provider
+	if features.Enabled(features.DedicatedIP) {
+		dedicatedIP = dedicatedip.NewProvider(client)
+	}
+
 	return &LoadBalancer{
-		StaticIP: staticIP,
+		StaticIP:    staticIP,
+		Kubernetes:  kube,
+		DedicatedIP: dedicatedIP,
 	}, nil
 }
 
+// IsSource reports whether the object is a source of load balancer DNS records.
+func (p *LoadBalancer) IsSource(kind string, obj metav1.Object) bool {
+	return p.Kubernetes != nil && p.Kubernetes.IsSource(kind, obj)
+}
+
 func (p *LoadBalancer) Provision(ctx context.Context, domain *domainv1beta1.CustomDomain) (string, *loadbalancer.ProvisionResult, error) {
 	providerType, provider, err := p.selectProvider(domain)
 	if err != nil {
@@ -40,7 +70,32 @@ func (p *LoadBalancer) Provision(ctx context.Context, domain *domainv1beta1.Cust
 	}
 
 	result, err := provider.Provision(ctx, domain)
-	return providerType, result, err
+	if err != nil || result == nil {
+		return providerType, result, err
+	}
+	if !features.Enabled(features.IPv6DNSRecords) {
+		result = withoutAAAARecords(result)
+	}
+	return providerType, result, nil
+}
+
+// withoutAAAARecords removes AAAA records from the result, for clusters
+// without IPv6 ingress. It returns nil if no records remain, as the load
+// balancer is not usable yet.
+func withoutAAAARecords(result *loadbalancer.ProvisionResult) *loadbalancer.ProvisionResult {
+	var records []loadbalancer.DNSRecord
+	for _, r := range result.DNSRecords {
+		if r.Type != "AAAA" {
+			records = append(records, r)
+		}
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	return &loadbalancer.ProvisionResult{
+		DNSRecords:  records,
+		AliasTarget: result.AliasTarget,
+	}
 }
 
 func (p *LoadBalancer) Release(ctx context.Context, domain *domainv1beta1.CustomDomain) (bool, error) {
@@ -61,6 +116,21 @@ func (p *LoadBalancer) selectProvider(domain *domainv1beta1.CustomDomain) (strin
 		return t, provider, nil
 	}
 
+	if domain.DedicatedIP() {
+		if p.DedicatedIP == nil {
+			return "", nil, fmt.Errorf("dedicated IP addresses are unavailable")
+		}
+		return loadBalancerDedicatedIP, p.DedicatedIP, nil
+	}
+
+	if class := domain.LoadBalancerClass(); class != "" {
+		// only Kubernetes load balancers have classes
+		if p.Kubernetes == nil {
+			return "", nil, fmt.Errorf("load balancer class %s is unavailable", class)
+		}
+		return loadBalancerKubernetes, p.Kubernetes, nil
+	}
+
 	rootDomain, err := publicsuffix.EffectiveTLDPlusOne(domain.Name)
 	if err != nil {
 		return "", nil, err
@@ -75,12 +145,23 @@ func (p *LoadBalancer) selectProvider(domain *domainv1beta1.CustomDomain) (strin
 		// allow CDN for sub-domains
 	}
 
+	if p.Kubernetes != nil {
+		return loadBalancerKubernetes, p.Kubernetes, nil
+	}
+
 	return "", nil, fmt.Errorf("no available load-balancer provider for the domain")
 }
 
 func (p *LoadBalancer) lookupProvider(providerType string) (loadbalancer.Provider, error) {
-	providers := map[string]loadbalancer.Provider{
-		loadBalancerStaticIP: p.StaticIP,
+	providers := map[string]loadbalancer.Provider{}
+	if p.StaticIP != nil {
+		providers[loadBalancerStaticIP] = p.StaticIP
+	}
+	if p.Kubernetes != nil {
+		providers[loadBalancerKubernetes] = p.Kubernetes
+	}
+	if p.DedicatedIP != nil {
+		providers[loadBalancerDedicatedIP] = p.DedicatedIP
 	}
 	for t, p := range providers {
 		if t == providerType {
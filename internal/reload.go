@@ -0,0 +1,58 @@
+package internal
+
+import (
+	"bytes"
+	"io/ioutil"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+// ConfigurationReloadInterval is the interval of checking the configuration
+// file for changes.
+const ConfigurationReloadInterval = 10 * time.Second
+
+// ConfigurationReloader watches the configuration file, and calls OnReload
+// with the new configuration when the file is changed. The file is polled,
+// so that updates of mounted ConfigMaps are detected.
+type ConfigurationReloader struct {
+	Path     string
+	Log      logr.Logger
+	OnReload func(config *DomainControllerConfiguration)
+}
+
+// Start implements manager.Runnable.
+func (r *ConfigurationReloader) Start(stop <-chan struct{}) error {
+	last, err := ioutil.ReadFile(r.Path)
+	if err != nil {
+		r.Log.Error(err, "cannot read configuration file")
+	}
+
+	ticker := time.NewTicker(ConfigurationReloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return nil
+		}
+
+		data, err := ioutil.ReadFile(r.Path)
+		if err != nil {
+			r.Log.Error(err, "cannot read configuration file")
+			continue
+		}
+		if bytes.Equal(data, last) {
+			continue
+		}
+		last = data
+
+		config, err := ParseConfiguration(data)
+		if err != nil {
+			r.Log.Error(err, "cannot load configuration file, keeping current configuration")
+			continue
+		}
+		r.Log.Info("configuration file changed, reloading")
+		r.OnReload(config)
+	}
+}
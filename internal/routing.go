@@ -0,0 +1,192 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/routing"
+	"github.com/skygeario/k8s-controller/pkg/domain/routing/gatewayapi"
+	"github.com/skygeario/k8s-controller/pkg/domain/routing/generator"
+	"github.com/skygeario/k8s-controller/pkg/domain/routing/generator/contour"
+	"github.com/skygeario/k8s-controller/pkg/domain/routing/generator/traefik"
+	routingingress "github.com/skygeario/k8s-controller/pkg/domain/routing/ingress"
+	"github.com/skygeario/k8s-controller/pkg/domain/routing/istio"
+	"github.com/skygeario/k8s-controller/pkg/features"
+)
+
+const (
+	IngressGeneratorNginx   = "nginx"
+	IngressGeneratorContour = "contour"
+	IngressGeneratorTraefik = "traefik"
+)
+
+type RoutingProvider struct {
+	// Ingress is the provider of Ingress routing mode, using the configured
+	// ingress generator.
+	Ingress    routing.Provider
+	GatewayAPI *gatewayapi.Provider
+	Istio      *istio.Provider
+}
+
+func NewRoutingProvider(client client.Client, config Config, ingressGenerator string) (*RoutingProvider, error) {
+	var err error
+
+	ingress, err := newIngressRoutingProvider(client, config, ingressGenerator)
+	if err != nil {
+		return nil, err
+	}
+
+	var gatewayAPI *gatewayapi.Provider
+	if config.GatewayAPI != nil {
+		if !features.Enabled(features.GatewayAPIRouting) {
+			return nil, fmt.Errorf("Gateway API routing is configured, but feature gate %s is disabled", features.GatewayAPIRouting)
+		}
+		gatewayAPI, err = gatewayapi.NewProvider(client, *config.GatewayAPI)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create Gateway API routing provider: %w", err)
+		}
+		gatewayAPI.ExternalDNS, err = NewExternalDNSAnnotator(config)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var istioProvider *istio.Provider
+	if config.Istio != nil {
+		istioProvider, err = istio.NewProvider(client, *config.Istio)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create Istio routing provider: %w", err)
+		}
+		istioProvider.ExternalDNS, err = NewExternalDNSAnnotator(config)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &RoutingProvider{
+		Ingress:    ingress,
+		GatewayAPI: gatewayAPI,
+		Istio:      istioProvider,
+	}, nil
+}
+
+func newIngressRoutingProvider(client client.Client, config Config, ingressGenerator string) (routing.Provider, error) {
+	externalDNS, err := NewExternalDNSAnnotator(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var g generator.Generator
+	switch ingressGenerator {
+	case IngressGeneratorNginx, "":
+		ingressProvider, err := NewIngressProvider(config)
+		if err != nil {
+			return nil, err
+		}
+		p, err := routingingress.NewProvider(client, ingressProvider)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create ingress routing provider: %w", err)
+		}
+		return p, nil
+	case IngressGeneratorContour:
+		contourGenerator, err := contour.NewGenerator()
+		if err != nil {
+			return nil, fmt.Errorf("cannot create Contour generator: %w", err)
+		}
+		contourGenerator.ExternalDNS = externalDNS
+		g = contourGenerator
+	case IngressGeneratorTraefik:
+		traefikGenerator, err := traefik.NewGenerator()
+		if err != nil {
+			return nil, fmt.Errorf("cannot create Traefik generator: %w", err)
+		}
+		traefikGenerator.ExternalDNS = externalDNS
+		g = traefikGenerator
+	default:
+		return nil, fmt.Errorf("unknown ingress generator '%s'", ingressGenerator)
+	}
+
+	p, err := generator.NewProvider(client, g)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create ingress routing provider: %w", err)
+	}
+	return p, nil
+}
+
+func (p *RoutingProvider) Provision(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (bool, error) {
+	mode, provider, err := p.selectProvider(reg)
+	if err != nil {
+		return false, err
+	}
+
+	// release provisioned resources from other providers
+	for m, p := range p.allProviders() {
+		if m == mode {
+			continue
+		}
+
+		released, err := p.Release(ctx, reg)
+		if err != nil {
+			return false, err
+		}
+		if !released {
+			return false, nil
+		}
+	}
+
+	return provider.Provision(ctx, reg)
+}
+
+func (p *RoutingProvider) Release(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (bool, error) {
+	// release provisioned resources from all providers
+	for _, p := range p.allProviders() {
+		released, err := p.Release(ctx, reg)
+		if err != nil {
+			return false, err
+		}
+		if !released {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (p *RoutingProvider) allProviders() map[domainv1beta1.CustomDomainRoutingMode]routing.Provider {
+	providers := map[domainv1beta1.CustomDomainRoutingMode]routing.Provider{
+		domainv1beta1.RoutingModeIngress: p.Ingress,
+	}
+	if p.GatewayAPI != nil {
+		providers[domainv1beta1.RoutingModeGatewayAPI] = p.GatewayAPI
+	}
+	if p.Istio != nil {
+		providers[domainv1beta1.RoutingModeIstio] = p.Istio
+	}
+	return providers
+}
+
+func (p *RoutingProvider) selectProvider(reg *domainv1beta1.CustomDomainRegistration) (domainv1beta1.CustomDomainRoutingMode, routing.Provider, error) {
+	mode := domainv1beta1.RoutingModeIngress
+	if reg.Spec.Routing != nil && reg.Spec.Routing.Mode != "" {
+		mode = reg.Spec.Routing.Mode
+	}
+
+	switch mode {
+	case domainv1beta1.RoutingModeIngress:
+		return mode, p.Ingress, nil
+	case domainv1beta1.RoutingModeGatewayAPI:
+		if p.GatewayAPI == nil {
+			return "", nil, fmt.Errorf("Gateway API routing is not configured")
+		}
+		return mode, p.GatewayAPI, nil
+	case domainv1beta1.RoutingModeIstio:
+		if p.Istio == nil {
+			return "", nil, fmt.Errorf("Istio routing is not configured")
+		}
+		return mode, p.Istio, nil
+	}
+
+	return "", nil, fmt.Errorf("unknown routing mode '%s'", mode)
+}
@@ -0,0 +1,195 @@
+package test
+
+import (
+	"net"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// DNSServer is an in-process DNS server serving records manipulated by
+// tests, so that verification is tested through real DNS resolution.
+type DNSServer struct {
+	conn net.PacketConn
+
+	lock         sync.RWMutex
+	txtRecords   map[string][]string
+	cnameRecords map[string]string
+	addresses    map[string][]string
+}
+
+func NewDNSServer() *DNSServer {
+	s := &DNSServer{}
+	s.Reset()
+	return s
+}
+
+// Start starts serving DNS queries over UDP on a random local port.
+func (s *DNSServer) Start() error {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+	s.conn = conn
+	go s.serve()
+	return nil
+}
+
+// Stop stops serving DNS queries.
+func (s *DNSServer) Stop() error {
+	return s.conn.Close()
+}
+
+// Addr returns the address (host:port) of the server.
+func (s *DNSServer) Addr() string {
+	return s.conn.LocalAddr().String()
+}
+
+// Reset removes all records.
+func (s *DNSServer) Reset() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.txtRecords = map[string][]string{}
+	s.cnameRecords = map[string]string{}
+	s.addresses = map[string][]string{}
+}
+
+// SetTXT sets TXT records of the name, or removes them if values is empty.
+func (s *DNSServer) SetTXT(name string, values ...string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if len(values) == 0 {
+		delete(s.txtRecords, fqdn(name))
+		return
+	}
+	s.txtRecords[fqdn(name)] = values
+}
+
+// SetCNAME sets CNAME record of the name, or removes it if target is empty.
+func (s *DNSServer) SetCNAME(name string, target string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if target == "" {
+		delete(s.cnameRecords, fqdn(name))
+		return
+	}
+	s.cnameRecords[fqdn(name)] = fqdn(target)
+}
+
+// SetAddresses sets A/AAAA records of the name, or removes them if addrs is
+// empty.
+func (s *DNSServer) SetAddresses(name string, addrs ...string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if len(addrs) == 0 {
+		delete(s.addresses, fqdn(name))
+		return
+	}
+	s.addresses[fqdn(name)] = addrs
+}
+
+func (s *DNSServer) serve() {
+	buf := make([]byte, 512)
+	for {
+		n, addr, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		resp, err := s.handle(buf[:n])
+		if err != nil {
+			continue
+		}
+		_, _ = s.conn.WriteTo(resp, addr)
+	}
+}
+
+func (s *DNSServer) handle(req []byte) ([]byte, error) {
+	var p dnsmessage.Parser
+	header, err := p.Start(req)
+	if err != nil {
+		return nil, err
+	}
+	q, err := p.Question()
+	if err != nil {
+		return nil, err
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	name := strings.ToLower(q.Name.String())
+	_, hasTXT := s.txtRecords[name]
+	_, hasCNAME := s.cnameRecords[name]
+	_, hasAddrs := s.addresses[name]
+
+	respHeader := dnsmessage.Header{
+		ID:                 header.ID,
+		Response:           true,
+		Authoritative:      true,
+		RecursionDesired:   header.RecursionDesired,
+		RecursionAvailable: true,
+	}
+	if !hasTXT && !hasCNAME && !hasAddrs {
+		respHeader.RCode = dnsmessage.RCodeNameError
+	}
+
+	b := dnsmessage.NewBuilder(nil, respHeader)
+	b.EnableCompression()
+	if err := b.StartQuestions(); err != nil {
+		return nil, err
+	}
+	if err := b.Question(q); err != nil {
+		return nil, err
+	}
+	if err := b.StartAnswers(); err != nil {
+		return nil, err
+	}
+
+	rh := dnsmessage.ResourceHeader{Name: q.Name, Class: dnsmessage.ClassINET, TTL: 0}
+	if target, ok := s.cnameRecords[name]; ok {
+		rh.Type = dnsmessage.TypeCNAME
+		if err := b.CNAMEResource(rh, dnsmessage.CNAMEResource{CNAME: dnsmessage.MustNewName(target)}); err != nil {
+			return nil, err
+		}
+		return b.Finish()
+	}
+
+	switch q.Type {
+	case dnsmessage.TypeTXT:
+		rh.Type = dnsmessage.TypeTXT
+		for _, value := range s.txtRecords[name] {
+			if err := b.TXTResource(rh, dnsmessage.TXTResource{TXT: []string{value}}); err != nil {
+				return nil, err
+			}
+		}
+	case dnsmessage.TypeA, dnsmessage.TypeAAAA:
+		for _, addr := range s.addresses[name] {
+			ip := net.ParseIP(addr)
+			if ip4 := ip.To4(); ip4 != nil && q.Type == dnsmessage.TypeA {
+				rh.Type = dnsmessage.TypeA
+				r := dnsmessage.AResource{}
+				copy(r.A[:], ip4)
+				if err := b.AResource(rh, r); err != nil {
+					return nil, err
+				}
+			} else if ip4 == nil && ip != nil && q.Type == dnsmessage.TypeAAAA {
+				rh.Type = dnsmessage.TypeAAAA
+				r := dnsmessage.AAAAResource{}
+				copy(r.AAAA[:], ip.To16())
+				if err := b.AAAAResource(rh, r); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return b.Finish()
+}
+
+func fqdn(name string) string {
+	name = strings.ToLower(name)
+	if !strings.HasSuffix(name, ".") {
+		name += "."
+	}
+	return name
+}
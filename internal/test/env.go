@@ -0,0 +1,127 @@
+package test
+
+import (
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+
+	"github.com/skygeario/k8s-controller/pkg/domain/verification"
+)
+
+// Environment is an envtest control plane with a manager and an in-process
+// DNS server, for integration tests of controllers.
+type Environment struct {
+	Env      *envtest.Environment
+	Webhooks WebhookInstallOptions
+	Config   *rest.Config
+	Manager  ctrl.Manager
+	DNS      *DNSServer
+
+	stop    chan struct{}
+	stopped chan error
+}
+
+func NewEnvironment(crdDirectoryPaths ...string) *Environment {
+	return &Environment{
+		Env: &envtest.Environment{CRDDirectoryPaths: crdDirectoryPaths},
+		DNS: NewDNSServer(),
+	}
+}
+
+// WithWebhooks installs webhook configurations in directoryPaths, served by
+// the manager. Webhooks should be set up with the manager before
+// StartManager.
+func (e *Environment) WithWebhooks(directoryPaths ...string) *Environment {
+	e.Webhooks.DirectoryPaths = directoryPaths
+	e.Env.KubeAPIServerFlags = webhookAPIServerFlags()
+	return e
+}
+
+// webhookAPIServerFlags returns the default API server flags of envtest, with
+// admission webhooks enabled instead of AlwaysAdmit.
+func webhookAPIServerFlags() []string {
+	var flags []string
+	for _, flag := range envtest.DefaultKubeAPIServerFlags {
+		if strings.HasPrefix(flag, "--admission-control=") {
+			continue
+		}
+		flags = append(flags, flag)
+	}
+	return append(flags, "--enable-admission-plugins=MutatingAdmissionWebhook,ValidatingAdmissionWebhook")
+}
+
+// Start starts the control plane and DNS server, and creates the manager.
+// Controllers should be set up with the manager before StartManager.
+func (e *Environment) Start(scheme *runtime.Scheme) error {
+	cfg, err := e.Env.Start()
+	if err != nil {
+		return err
+	}
+	e.Config = cfg
+
+	if err := e.DNS.Start(); err != nil {
+		return err
+	}
+
+	options := ctrl.Options{
+		Scheme:             scheme,
+		MetricsBindAddress: "0",
+	}
+	if len(e.Webhooks.DirectoryPaths) > 0 {
+		if err := e.Webhooks.setup(); err != nil {
+			return err
+		}
+		if err := e.Webhooks.install(cfg, scheme); err != nil {
+			return err
+		}
+		options.Host = e.Webhooks.LocalServingHost
+		options.Port = e.Webhooks.LocalServingPort
+		options.CertDir = e.Webhooks.LocalServingCertDir
+	}
+	e.Manager, err = ctrl.NewManager(cfg, options)
+	return err
+}
+
+// Resolver returns a resolver querying the DNS server.
+func (e *Environment) Resolver() *verification.RateLimitedResolver {
+	return verification.NewRateLimitedResolver(verification.RateLimitConfig{
+		Servers: []string{e.DNS.Addr()},
+	})
+}
+
+// StartManager starts the manager in background. If webhooks are installed,
+// it waits until the manager serves them.
+func (e *Environment) StartManager() error {
+	e.stop = make(chan struct{})
+	e.stopped = make(chan error, 1)
+	go func() {
+		e.stopped <- e.Manager.Start(e.stop)
+	}()
+	if len(e.Webhooks.DirectoryPaths) > 0 {
+		return e.Webhooks.waitForServing(10 * time.Second)
+	}
+	return nil
+}
+
+// Stop stops the manager, DNS server and control plane.
+func (e *Environment) Stop() error {
+	var mgrErr error
+	if e.stop != nil {
+		close(e.stop)
+		mgrErr = <-e.stopped
+	}
+	if err := e.DNS.Stop(); err != nil {
+		return err
+	}
+	if err := e.Env.Stop(); err != nil {
+		return err
+	}
+	if err := e.Webhooks.cleanup(); err != nil {
+		return err
+	}
+	return mgrErr
+}
@@ -2,9 +2,10 @@ package test
 
 import (
 	"context"
-	"fmt"
 	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
 	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
 	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer"
 )
@@ -43,6 +44,10 @@ func (p *LoadBalancer) Provision(ctx context.Context, domain *domainv1beta1.Cust
 	}}, nil
 }
 
+func (p *LoadBalancer) IsSource(kind string, obj metav1.Object) bool {
+	return false
+}
+
 func (p *LoadBalancer) Release(ctx context.Context, domain *domainv1beta1.CustomDomain) (ok bool, err error) {
 	if _, ok := p.ProvisionRequests[domain.Name]; !ok {
 		return true, nil
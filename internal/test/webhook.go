@@ -0,0 +1,222 @@
+package test
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// WebhookInstallOptions configures webhooks installed in the control plane
+// and served by the manager on a local address. envtest of controller-runtime
+// v0.4 does not install webhooks, so the harness generates a serving
+// certificate and points the webhook configurations to the manager.
+type WebhookInstallOptions struct {
+	// DirectoryPaths are directories of webhook configuration manifests.
+	DirectoryPaths []string
+
+	LocalServingHost    string
+	LocalServingPort    int
+	LocalServingCertDir string
+
+	caBundle []byte
+}
+
+// setup generates the serving certificate and selects a free local port.
+func (o *WebhookInstallOptions) setup() error {
+	o.LocalServingHost = "127.0.0.1"
+
+	l, err := net.Listen("tcp", net.JoinHostPort(o.LocalServingHost, "0"))
+	if err != nil {
+		return err
+	}
+	o.LocalServingPort = l.Addr().(*net.TCPAddr).Port
+	if err := l.Close(); err != nil {
+		return err
+	}
+
+	o.LocalServingCertDir, err = ioutil.TempDir("", "envtest-webhook-")
+	if err != nil {
+		return err
+	}
+	certPEM, keyPEM, err := generateServingCert(o.LocalServingHost)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(o.LocalServingCertDir, "tls.crt"), certPEM, 0600); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(o.LocalServingCertDir, "tls.key"), keyPEM, 0600); err != nil {
+		return err
+	}
+	o.caBundle = certPEM
+	return nil
+}
+
+// install creates the webhook configurations in the control plane.
+func (o *WebhookInstallOptions) install(cfg *rest.Config, scheme *runtime.Scheme) error {
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		return err
+	}
+	objs, err := o.readConfigurations(scheme)
+	if err != nil {
+		return err
+	}
+	for _, obj := range objs {
+		if err := c.Create(context.Background(), obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readConfigurations reads the webhook configurations in DirectoryPaths, with
+// client configs pointing to the local serving address.
+func (o *WebhookInstallOptions) readConfigurations(scheme *runtime.Scheme) ([]runtime.Object, error) {
+	decoder := serializer.NewCodecFactory(scheme).UniversalDeserializer()
+	var objs []runtime.Object
+	for _, dir := range o.DirectoryPaths {
+		files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			docs, err := readYAMLDocuments(file)
+			if err != nil {
+				return nil, err
+			}
+			for _, doc := range docs {
+				obj, _, err := decoder.Decode(doc, nil, nil)
+				if err != nil {
+					// Other resources, such as kustomization, are skipped.
+					continue
+				}
+				switch config := obj.(type) {
+				case *admissionregistrationv1beta1.MutatingWebhookConfiguration:
+					for i := range config.Webhooks {
+						o.setClientConfig(&config.Webhooks[i].ClientConfig)
+					}
+					objs = append(objs, config)
+				case *admissionregistrationv1beta1.ValidatingWebhookConfiguration:
+					for i := range config.Webhooks {
+						o.setClientConfig(&config.Webhooks[i].ClientConfig)
+					}
+					objs = append(objs, config)
+				}
+			}
+		}
+	}
+	return objs, nil
+}
+
+func (o *WebhookInstallOptions) setClientConfig(config *admissionregistrationv1beta1.WebhookClientConfig) {
+	u := url.URL{
+		Scheme: "https",
+		Host:   net.JoinHostPort(o.LocalServingHost, strconv.Itoa(o.LocalServingPort)),
+	}
+	if config.Service != nil && config.Service.Path != nil {
+		u.Path = *config.Service.Path
+	}
+	s := u.String()
+	config.URL = &s
+	config.Service = nil
+	config.CABundle = o.caBundle
+}
+
+// waitForServing waits until the manager accepts connections on the local
+// serving address.
+func (o *WebhookInstallOptions) waitForServing(timeout time.Duration) error {
+	addr := net.JoinHostPort(o.LocalServingHost, strconv.Itoa(o.LocalServingPort))
+	deadline := time.Now().Add(timeout)
+	for {
+		conn, err := net.DialTimeout("tcp", addr, time.Second)
+		if err == nil {
+			return conn.Close()
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("webhook server is not serving at %s: %w", addr, err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func (o *WebhookInstallOptions) cleanup() error {
+	if o.LocalServingCertDir == "" {
+		return nil
+	}
+	return os.RemoveAll(o.LocalServingCertDir)
+}
+
+func readYAMLDocuments(path string) ([][]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	reader := yaml.NewYAMLReader(bufio.NewReader(f))
+	var docs [][]byte
+	for {
+		doc, err := reader.Read()
+		if err == io.EOF {
+			return docs, nil
+		} else if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+}
+
+// generateServingCert generates a self-signed certificate for the host, used
+// as both the serving certificate and the CA bundle of webhooks.
+func generateServingCert(host string) (certPEM []byte, keyPEM []byte, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "envtest-webhook"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		IPAddresses:           []net.IP{net.ParseIP(host)},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM, nil
+}
@@ -8,17 +8,21 @@ import (
 
 	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
 	"github.com/skygeario/k8s-controller/pkg/domain/tls"
+	"github.com/skygeario/k8s-controller/pkg/domain/tls/acme"
 	"github.com/skygeario/k8s-controller/pkg/domain/tls/certmanager"
 	"github.com/skygeario/k8s-controller/pkg/domain/tls/usersecret"
+	"github.com/skygeario/k8s-controller/pkg/features"
 )
 
 const (
 	tlsCertManager string = "cert-manager"
+	tlsACME        string = "acme"
 	tlsUserSecret  string = "user-secret"
 )
 
 type TLSProvider struct {
 	CertManager *certmanager.Provider
+	ACME        *acme.Provider
 	UserSecret  *usersecret.Provider
 }
 
@@ -31,8 +35,21 @@ func NewTLSProvider(client client.Client, config Config) (*TLSProvider, error) {
 		if err != nil {
 			return nil, fmt.Errorf("cannot create cert-manager provider: %w", err)
 		}
-	} else {
-		return nil, fmt.Errorf("cert-manager config is missing")
+	}
+
+	var acmeProvider *acme.Provider
+	if config.ACME != nil {
+		if !features.Enabled(features.ACME) {
+			return nil, fmt.Errorf("ACME is configured, but feature gate %s is disabled", features.ACME)
+		}
+		acmeProvider, err = acme.NewProvider(client, *config.ACME)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create ACME provider: %w", err)
+		}
+	}
+
+	if certManager == nil && acmeProvider == nil {
+		return nil, fmt.Errorf("cert-manager or ACME config is missing")
 	}
 
 	var userSecret *usersecret.Provider
@@ -43,6 +60,7 @@ func NewTLSProvider(client client.Client, config Config) (*TLSProvider, error) {
 
 	return &TLSProvider{
 		CertManager: certManager,
+		ACME:        acmeProvider,
 		UserSecret:  userSecret,
 	}, nil
 }
@@ -86,16 +104,37 @@ func (p *TLSProvider) Release(ctx context.Context, reg *domainv1beta1.CustomDoma
 	return true, nil
 }
 
+func (p *TLSProvider) CertificateStatus(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (*tls.CertificateStatus, error) {
+	_, provider, err := p.selectProvider(reg)
+	if err != nil {
+		return nil, err
+	}
+	reporter, ok := provider.(tls.CertificateStatusReporter)
+	if !ok {
+		return nil, nil
+	}
+	return reporter.CertificateStatus(ctx, reg)
+}
+
 func (p *TLSProvider) allProviders() map[string]tls.Provider {
-	return map[string]tls.Provider{
-		tlsCertManager: p.CertManager,
-		tlsUserSecret:  p.UserSecret,
+	providers := map[string]tls.Provider{
+		tlsUserSecret: p.UserSecret,
+	}
+	if p.CertManager != nil {
+		providers[tlsCertManager] = p.CertManager
 	}
+	if p.ACME != nil {
+		providers[tlsACME] = p.ACME
+	}
+	return providers
 }
 
 func (p *TLSProvider) selectProvider(reg *domainv1beta1.CustomDomainRegistration) (string, tls.Provider, error) {
 	if reg.Spec.DomainConfig.CertSecretName != nil {
 		return tlsUserSecret, p.UserSecret, nil
 	}
-	return tlsCertManager, p.CertManager, nil
+	if p.CertManager != nil {
+		return tlsCertManager, p.CertManager, nil
+	}
+	return tlsACME, p.ACME, nil
 }
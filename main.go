@@ -16,26 +16,51 @@ limitations under the License.
 package main
 
 import (
-	"encoding/json"
+	"context"
 	"flag"
+	"fmt"
 	"io/ioutil"
+	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgokubernetes "k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
+	"k8s.io/client-go/tools/clientcmd"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	cm "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha2"
 
 	// +kubebuilder:scaffold:imports
 
 	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	domainv1beta2 "github.com/skygeario/k8s-controller/api/v1beta2"
 	"github.com/skygeario/k8s-controller/controllers"
 	"github.com/skygeario/k8s-controller/internal"
+	"github.com/skygeario/k8s-controller/pkg/domain/admin"
+	"github.com/skygeario/k8s-controller/pkg/domain/attestation"
+	"github.com/skygeario/k8s-controller/pkg/domain/dnsprovider"
+	"github.com/skygeario/k8s-controller/pkg/domain/healthcheck"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer/kubernetes"
+	"github.com/skygeario/k8s-controller/pkg/domain/portal"
+	"github.com/skygeario/k8s-controller/pkg/domain/tls/acme"
 	"github.com/skygeario/k8s-controller/pkg/domain/verification"
+	"github.com/skygeario/k8s-controller/pkg/features"
+	"github.com/skygeario/k8s-controller/pkg/notification"
+	"github.com/skygeario/k8s-controller/pkg/tracing"
+	"github.com/skygeario/k8s-controller/pkg/util/dnsname"
+	"github.com/skygeario/k8s-controller/pkg/util/dryrun"
+	"github.com/skygeario/k8s-controller/pkg/util/netguard"
 )
 
 var (
@@ -48,66 +73,670 @@ func init() {
 	_ = cm.AddToScheme(scheme)
 
 	_ = domainv1beta1.AddToScheme(scheme)
+	_ = domainv1beta2.AddToScheme(scheme)
 	// +kubebuilder:scaffold:scheme
 }
 
 func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
+	var leaderElectionID string
+	var leaderElectionNamespace string
+	var leaseDuration time.Duration
+	var renewDeadline time.Duration
+	var retryPeriod time.Duration
 	var enableWebhooks bool
 	var configFile string
+	var verificationChallengeZone string
+	var verificationCNAMETarget string
+	var verificationRecordPrefix string
+	var emailSMTPAddress string
+	var emailSMTPUsername string
+	var emailSMTPPasswordFile string
+	var emailFrom string
+	var emailConfirmationURL string
+	var emailNamespaceBudget int
+	var rdapBaseURL string
+	var dnsServers string
+	var dnsQPS float64
+	var dnsBurst int
+	var dnsCacheTTL time.Duration
+	var verificationView string
+	var requireApproval string
+	var defaultDomainSuffix string
+	var takeoverConfirmations int
+	var takeoverWindow time.Duration
+	var defaultDomainNamespaceSelector string
+	var defaultDomainBackendService string
+	var defaultDomainBackendPort int
+	var externalDNSServers string
+	var blockedCIDRs string
+	var dnsNegativeCacheTTL time.Duration
+	var dnsAuthoritative bool
+	var verificationResolvers string
+	var attestationAddr string
+	var adminAddr string
+	var adminTokenFile string
+	var portalAddr string
+	var portalTokenFile string
+	var notificationWebhookURL string
+	var probeAddr string
+	var inheritParentVerification bool
+	var webhookCertDir string
+	var verificationResolverQuorum int
+	var verificationTokenGenerator string
+	var ingressGenerator string
+	var logDomains string
+	var verificationTokenSecretFile string
+	var verificationKeySecret string
+	var dryRun bool
+	var hubKubeconfig string
+	var clusterName string
+	var otlpEndpoint string
+	var traceSampleRatio float64
+	var domainConcurrency int
+	var registrationConcurrency int
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
+	flag.StringVar(&probeAddr, "health-probe-addr", ":8081", "The address the health probe endpoints bind to.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
 		"Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&leaderElectionID, "leader-election-id", "k8s-controller-leader-election",
+		"Name of the resource used for leader election.")
+	flag.StringVar(&leaderElectionNamespace, "leader-election-namespace", "",
+		"Namespace of the resource used for leader election. Defaults to the namespace of controller manager.")
+	flag.DurationVar(&leaseDuration, "leader-election-lease-duration", 15*time.Second,
+		"Duration that non-leader candidates wait before acquiring leadership.")
+	flag.DurationVar(&renewDeadline, "leader-election-renew-deadline", 10*time.Second,
+		"Duration that the leader retries refreshing leadership before giving up.")
+	flag.DurationVar(&retryPeriod, "leader-election-retry-period", 2*time.Second,
+		"Duration between leader election actions.")
 	flag.BoolVar(&enableWebhooks, "enable-webhooks", true, "Enable CRD webhooks.")
-	flag.StringVar(&configFile, "config-file", "config.json", "Path to configuration JSON file.")
+	flag.StringVar(&webhookCertDir, "webhook-cert-dir", "/tmp/k8s-webhook-server/serving-certs",
+		"Directory containing serving certificate of webhook server.")
+	flag.StringVar(&configFile, "config-file", "config.json",
+		"Path to DomainControllerConfiguration JSON file. Settings in the file are overridden by flags given explicitly.")
+	flag.StringVar(&ingressGenerator, "ingress-generator", internal.IngressGeneratorNginx,
+		"Type of routing objects generated for Ingress routing mode, one of 'nginx' (Ingress), "+
+			"'contour' (HTTPProxy) or 'traefik' (IngressRoute).")
+	flag.DurationVar(&controllers.ReverificationInterval, "reverify-interval", controllers.ReverificationInterval,
+		"Interval between re-verification of verified domains. Zero disables re-verification.")
+	flag.DurationVar(&controllers.VerificationBackoffMin, "verification-backoff-min", controllers.VerificationBackoffMin,
+		"Minimum interval before retrying failed verification.")
+	flag.DurationVar(&controllers.VerificationBackoffMax, "verification-backoff-max", controllers.VerificationBackoffMax,
+		"Maximum interval before retrying failed verification.")
+	flag.DurationVar(&controllers.ReconcileTimeout, "reconcile-timeout", controllers.ReconcileTimeout,
+		"Timeout of a single reconcile, including calls to external services.")
+	flag.DurationVar(&controllers.ResyncPeriod, "resync-period", controllers.ResyncPeriod,
+		"Period of reconciling all resources to catch drift, such as deleted DNS records. Zero disables periodic resync.")
+	flag.Float64Var(&controllers.ResyncJitter, "resync-jitter", controllers.ResyncJitter,
+		"Maximum random jitter added to resync period, as a fraction of the period.")
+	flag.DurationVar(&controllers.DNSCheckInterval, "dns-check-interval", controllers.DNSCheckInterval,
+		"Interval between checking DNS records of registered domains point to load balancer.")
+	flag.DurationVar(&controllers.CertificateExpiryWarningPeriod, "cert-expiry-warning-period", controllers.CertificateExpiryWarningPeriod,
+		"Period before expiry of TLS certificates that registrations are reported as expiring soon.")
+	flag.DurationVar(&controllers.VerificationKeyRotationInterval, "verification-key-rotation-interval", controllers.VerificationKeyRotationInterval,
+		"Interval between rotation of domain verification keys. Zero disables rotation.")
+	flag.DurationVar(&controllers.VerificationKeyGracePeriod, "verification-key-grace-period", controllers.VerificationKeyGracePeriod,
+		"Period that previous domain verification key is accepted after rotation.")
+	flag.DurationVar(&controllers.OrphanedDomainTTL, "orphaned-domain-ttl", controllers.OrphanedDomainTTL,
+		"Period that custom domains without registrations are kept before deletion.")
+	flag.IntVar(&takeoverConfirmations, "takeover-confirmations", 0,
+		"Consecutive failed verifications of the owner registration, observing the verification record missing or mismatched, required before ownership of domain is revoked. Zero disables the check.")
+	flag.DurationVar(&takeoverWindow, "takeover-window", 0,
+		"Duration that the owner registration must stay unverified, and a claimant of another namespace verified, before ownership of domain is transferred to the claimant.")
+	flag.DurationVar(&controllers.ReleaseQuarantinePeriod, "release-quarantine-period", controllers.ReleaseQuarantinePeriod,
+		"Period that a domain released by deleting its verified owner registration cannot be claimed by other namespaces.")
+	flag.StringVar(&verificationRecordPrefix, "verification-record-prefix", verification.DefaultDNSRecordPrefix,
+		"Label prepended to root domain to form the name of verification TXT record, unless specified by the custom domain.")
+	flag.BoolVar(&inheritParentVerification, "inherit-parent-verification", true,
+		"Verify registrations of subdomains without verification records, if the parent domain is verified in the same namespace.")
+	flag.StringVar(&verificationChallengeZone, "verification-challenge-zone", "",
+		"DNS zone that challenge CNAME records delegate to. Empty disables CNAME verification.")
+	flag.StringVar(&verificationCNAMETarget, "verification-cname-target", "",
+		"Target of CNAME records encoding verification tokens, e.g. verify.<platform domain>, for DNS verification with record type CNAME. "+
+			"Empty disables CNAME record verification.")
+	flag.StringVar(&emailSMTPAddress, "email-verification-smtp-address", "",
+		"Address (host:port) of SMTP relay sending confirmation emails of Email verification, e.g. Amazon SES SMTP endpoint. "+
+			"Empty disables Email verification. Requires feature gate "+string(features.EmailVerification)+".")
+	flag.StringVar(&emailSMTPUsername, "email-verification-smtp-username", "", "Username of SMTP relay.")
+	flag.StringVar(&emailSMTPPasswordFile, "email-verification-smtp-password-file", "", "Path to file containing password of SMTP relay.")
+	flag.StringVar(&emailFrom, "email-verification-from", "", "Sender address of confirmation emails.")
+	flag.StringVar(&emailConfirmationURL, "email-verification-url", "",
+		"Public base URL of the attestation endpoint (see --attestation-bind-address), used in confirmation links.")
+	flag.StringVar(&rdapBaseURL, "rdap-base-url", verification.DefaultRDAPBaseURL,
+		"Base URL of RDAP service looking up contacts of domains in Email verification.")
+	flag.DurationVar(&controllers.EmailChallengeLinkTTL, "email-verification-link-ttl", controllers.EmailChallengeLinkTTL,
+		"Duration that confirmation links of Email verification are valid.")
+	flag.IntVar(&emailNamespaceBudget, "email-verification-namespace-budget", 10,
+		"Maximum confirmation emails sent per day for registrations not approved by operators in a namespace. "+
+			"Zero requires approval before sending confirmation emails.")
+	flag.StringVar(&dnsServers, "dns-servers", "",
+		"Comma-separated addresses of DNS resolvers used in verification: host:port, tls://host[:port] for DNS-over-TLS, "+
+			"or https://[user:password@]host/path for DNS-over-HTTPS. Empty uses local DNS configuration.")
+	flag.Float64Var(&dnsQPS, "dns-qps", 10, "Maximum DNS queries per second sent to each resolver. Zero disables rate limit.")
+	flag.IntVar(&dnsBurst, "dns-burst", 10, "Maximum burst of DNS queries sent to each resolver.")
+	flag.StringVar(&requireApproval, "require-approval", "",
+		"Registrations requiring approval of operators before accepted: All or ApexDomains. Empty requires approval only by domain policies.")
+	flag.StringVar(&defaultDomainSuffix, "default-domain-suffix", "",
+		"Platform-owned domain suffix of default domains <namespace>.<suffix> provisioned for selected namespaces. Empty disables provisioning.")
+	flag.StringVar(&defaultDomainNamespaceSelector, "default-domain-namespace-selector", controllers.DefaultDomainLabel+"=true",
+		"Label selector of namespaces provisioned with default domains.")
+	flag.StringVar(&defaultDomainBackendService, "default-domain-backend-service", "app",
+		"Name of backend Service of default domains.")
+	flag.IntVar(&defaultDomainBackendPort, "default-domain-backend-port", 80,
+		"Port of backend Service of default domains.")
+	flag.StringVar(&verificationView, "verification-view", "local",
+		"DNS view of verification: local uses --dns-servers, external uses --external-dns-servers, "+
+			"so that internal DNS overrides do not affect verification.")
+	flag.StringVar(&externalDNSServers, "external-dns-servers", strings.Join(verification.DefaultExternalServers, ","),
+		"Comma-separated addresses of public DNS resolvers used in external verification view (see --dns-servers).")
+	flag.StringVar(&blockedCIDRs, "blocked-cidrs", "",
+		"Comma-separated CIDRs, e.g. pod and service CIDRs of the cluster, that HTTP verification and health checks must not connect to, "+
+			"in addition to loopback, link-local and private addresses.")
+	flag.DurationVar(&dnsCacheTTL, "dns-cache-ttl", 30*time.Second, "Duration verification DNS answers are cached. Zero disables caching.")
+	flag.DurationVar(&dnsNegativeCacheTTL, "dns-negative-cache-ttl", 10*time.Second,
+		"Duration non-existent names in verification DNS lookups are cached.")
+	flag.BoolVar(&dnsAuthoritative, "dns-authoritative", false,
+		"Query authoritative nameservers of domain directly in verification, bypassing caching resolvers.")
+	flag.StringVar(&verificationResolvers, "verification-resolvers", "",
+		"Comma-separated addresses of DNS resolvers that are queried independently in verification (see --dns-servers), "+
+			"accepting only records returned by a quorum of resolvers.")
+	flag.IntVar(&verificationResolverQuorum, "verification-resolver-quorum", 0,
+		"Number of verification resolvers that must agree on records. Defaults to majority of resolvers.")
+	flag.StringVar(&verificationTokenGenerator, "verification-token-generator", verification.TokenGeneratorHMAC,
+		"Type of verification token generator, either 'hmac' or 'keyring'.")
+	flag.StringVar(&verificationTokenSecretFile, "verification-token-secret-file", "",
+		"Path to file containing secrets of verification token generator, one per line. "+
+			"The first secret is used to generate tokens, and others are accepted by 'keyring' generator only.")
+	flag.StringVar(&verificationKeySecret, "verification-key-secret", "",
+		"Reference (namespace/name) to Secret containing secrets of verification token generator in key '"+
+			verification.TokenSecretsKey+"', in same format as --verification-token-secret-file. "+
+			"The secrets are reloaded when the Secret is changed.")
+	flag.BoolVar(&dryRun, "dry-run", false,
+		"Perform writes of controllers in server-side dry-run mode and log the intended writes, without persisting changes.")
+	flag.StringVar(&attestationAddr, "attestation-bind-address", "",
+		"The address the attestation endpoint for external verifiers binds to. Empty disables the endpoint.")
+	flag.StringVar(&adminAddr, "admin-bind-address", "",
+		"The address the admin endpoint for support staff and dashboards binds to. Empty disables the endpoint.")
+	flag.StringVar(&adminTokenFile, "admin-token-file", "",
+		"Path to file containing bearer token of admin endpoint. Required with --admin-bind-address.")
+	flag.StringVar(&portalAddr, "portal-bind-address", "",
+		"The address the gRPC read API of domain state for the hosting platform portal binds to. Empty disables the endpoint.")
+	flag.StringVar(&portalTokenFile, "portal-token-file", "",
+		"Path to file containing bearer token of portal endpoint. Required with --portal-bind-address.")
+	flag.StringVar(&notificationWebhookURL, "notification-webhook-url", "",
+		"URL receiving JSON notifications of domain lifecycle events. Empty disables notifications.")
+	flag.StringVar(&controllers.DomainNamespace, "domain-namespace", "",
+		"Namespace of custom domains, if CustomDomain resource is installed as namespaced. Empty if it is cluster-scoped.")
+	flag.StringVar(&hubKubeconfig, "hub-kubeconfig", "",
+		"Path to kubeconfig of hub cluster. If set, custom domains are managed in the hub cluster, "+
+			"and only registrations are reconciled in this cluster.")
+	flag.StringVar(&logDomains, "log-domains", "",
+		"Comma-separated domain names whose reconciliations are logged at all verbosity levels, for debugging.")
+	flag.StringVar(&clusterName, "cluster-name", "",
+		"Name of this workload cluster, recorded in registration references of custom domains. Required with --hub-kubeconfig.")
+	flag.DurationVar(&controllers.RemoteRegistrationHeartbeatInterval, "remote-registration-heartbeat-interval", controllers.RemoteRegistrationHeartbeatInterval,
+		"Interval of refreshing references of registrations in custom domains of hub cluster.")
+	flag.DurationVar(&controllers.RemoteRegistrationTTL, "remote-registration-ttl", controllers.RemoteRegistrationTTL,
+		"Duration after last heartbeat that references of registrations in workload clusters are removed from custom domains of hub cluster.")
+	flag.StringVar(&otlpEndpoint, "otlp-endpoint", "",
+		"Base URL of OpenTelemetry collector receiving traces over OTLP/HTTP, e.g. http://otel-collector:4318. Empty disables tracing.")
+	flag.Float64Var(&traceSampleRatio, "trace-sample-ratio", 1.0, "Ratio of reconciliations traced, between 0 and 1.")
+	flag.IntVar(&domainConcurrency, "domain-concurrency", 1, "Maximum number of concurrent reconciliations of custom domains.")
+	flag.IntVar(&registrationConcurrency, "registration-concurrency", 1,
+		"Maximum number of concurrent reconciliations of custom domain registrations.")
+	flag.Var(features.DefaultFeatureGate, "feature-gates",
+		"Comma-separated key=value pairs enabling or disabling experimental features. Options are:\n"+
+			strings.Join(features.DefaultFeatureGate.KnownFeatures(), "\n"))
 	flag.Parse()
 
 	ctrl.SetLogger(zap.New(func(o *zap.Options) {
 		o.Development = true
 	}))
 
-	configJSON, err := ioutil.ReadFile(configFile)
+	configuration, err := internal.LoadConfiguration(configFile)
 	if err != nil {
-		setupLog.Error(err, "unable read configuration")
+		setupLog.Error(err, "unable load configuration")
 		os.Exit(1)
 	}
-	var config internal.Config
-	if err := json.Unmarshal(configJSON, &config); err != nil {
-		setupLog.Error(err, "unable parse configuration")
+	explicitFlags := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) { explicitFlags[f.Name] = true })
+	for name, value := range configuration.Flags() {
+		if explicitFlags[name] {
+			continue
+		}
+		if err := flag.Set(name, value); err != nil {
+			setupLog.Error(err, "invalid configuration", "flag", name)
+			os.Exit(1)
+		}
+	}
+	config := configuration.Config
+
+	logDomainNames, err := parseLogDomains(logDomains)
+	if err != nil {
+		setupLog.Error(err, "invalid domain name in --log-domains")
+		os.Exit(1)
+	}
+	controllers.SetLogDomains(logDomainNames)
+
+	if err := verification.ValidateDNSRecordPrefix(verificationRecordPrefix); err != nil {
+		setupLog.Error(err, "invalid verification record prefix")
 		os.Exit(1)
 	}
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
-		Scheme:             scheme,
-		MetricsBindAddress: metricsAddr,
-		LeaderElection:     enableLeaderElection,
-		Port:               9443,
+		Scheme:                  scheme,
+		MetricsBindAddress:      metricsAddr,
+		LeaderElection:          enableLeaderElection,
+		LeaderElectionID:        leaderElectionID,
+		LeaderElectionNamespace: leaderElectionNamespace,
+		LeaseDuration:           &leaseDuration,
+		RenewDeadline:           &renewDeadline,
+		RetryPeriod:             &retryPeriod,
+		Port:                    9443,
+		CertDir:                 webhookCertDir,
+		HealthProbeBindAddress:  probeAddr,
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
-	loadBalancer, err := internal.NewLoadBalancer(config)
+	kubeClient := mgr.GetClient()
+	if dryRun {
+		setupLog.Info("running in dry-run mode, changes are not persisted")
+		kubeClient = dryrun.NewClient(kubeClient, ctrl.Log.WithName("dry-run"))
+	}
+
+	domainClient := kubeClient
+	var domainCache cache.Cache
+	if hubKubeconfig != "" {
+		if clusterName == "" {
+			setupLog.Info("--cluster-name is required with --hub-kubeconfig")
+			os.Exit(1)
+		}
+		hubConfig, err := clientcmd.BuildConfigFromFlags("", hubKubeconfig)
+		if err != nil {
+			setupLog.Error(err, "unable to load hub cluster kubeconfig")
+			os.Exit(1)
+		}
+		hubMgr, err := ctrl.NewManager(hubConfig, ctrl.Options{
+			Scheme:             scheme,
+			MetricsBindAddress: "0",
+		})
+		if err != nil {
+			setupLog.Error(err, "unable to create hub cluster manager")
+			os.Exit(1)
+		}
+		// Hub manager only provides client and cache, and is started with
+		// the manager after acquiring leadership.
+		if err := mgr.Add(hubMgr); err != nil {
+			setupLog.Error(err, "unable to add hub cluster manager")
+			os.Exit(1)
+		}
+		domainClient = hubMgr.GetClient()
+		if dryRun {
+			domainClient = dryrun.NewClient(domainClient, ctrl.Log.WithName("dry-run").WithName("hub"))
+		}
+		domainCache = hubMgr.GetCache()
+	}
+
+	loadBalancer, err := internal.NewLoadBalancer(kubeClient, config)
 	if err != nil {
 		setupLog.Error(err, "unable create load balancer")
 		os.Exit(1)
 	}
+	if loadBalancer.Kubernetes != nil && loadBalancer.Kubernetes.LookupEndpoints {
+		if err := kubernetes.SetupIndexes(mgr.GetFieldIndexer()); err != nil {
+			setupLog.Error(err, "unable to set up load balancer indexes")
+			os.Exit(1)
+		}
+	}
 
-	tlsProvider, err := internal.NewTLSProvider(mgr.GetClient(), config)
+	tlsProvider, err := internal.NewTLSProvider(kubeClient, config)
 	if err != nil {
 		setupLog.Error(err, "unable create TLS provider")
 		os.Exit(1)
 	}
 
-	ingressProvider, err := internal.NewIngressProvider(config)
+	if tlsProvider.ACME != nil {
+		tlsProvider.ACME.DryRun = dryRun
+		if tlsProvider.ACME.Solver != nil {
+			clientset, err := clientgokubernetes.NewForConfig(mgr.GetConfig())
+			if err != nil {
+				setupLog.Error(err, "unable to create clientset")
+				os.Exit(1)
+			}
+			tlsProvider.ACME.Solver.UseInformer(clientset)
+			tlsProvider.ACME.Solver.Reader = mgr.GetAPIReader()
+			if err := mgr.Add(tlsProvider.ACME.Solver); err != nil {
+				setupLog.Error(err, "unable add ACME challenge solver")
+				os.Exit(1)
+			}
+		}
+	}
+
+	routingProvider, err := internal.NewRoutingProvider(kubeClient, config, ingressGenerator)
+	if err != nil {
+		setupLog.Error(err, "unable create routing provider")
+		os.Exit(1)
+	}
+
+	dnsProvider, err := internal.NewDNSProvider(kubeClient, config)
+	if err != nil {
+		setupLog.Error(err, "unable create DNS provider")
+		os.Exit(1)
+	}
+
+	if tlsProvider.ACME != nil && tlsProvider.ACME.Config.ChallengeType == acme.ChallengeDNS01 {
+		recordProvider, ok := dnsProvider.(dnsprovider.RecordProvider)
+		if !ok {
+			setupLog.Info("ACME DNS-01 challenge requires a DNS provider managing records")
+			os.Exit(1)
+		}
+		tlsProvider.ACME.DNS01Solver = acme.NewDNS01Solver(recordProvider)
+	}
+
+	defaultDomainSelector, err := labels.Parse(defaultDomainNamespaceSelector)
+	if err != nil {
+		setupLog.Error(err, "invalid --default-domain-namespace-selector")
+		os.Exit(1)
+	}
+
+	switch domainv1beta1.ApprovalMode(requireApproval) {
+	case "", domainv1beta1.ApprovalModeAll, domainv1beta1.ApprovalModeApexDomains:
+	default:
+		setupLog.Info("invalid --require-approval, must be All or ApexDomains", "mode", requireApproval)
+		os.Exit(1)
+	}
+
+	var guardCIDRs []string
+	if blockedCIDRs != "" {
+		guardCIDRs = strings.Split(blockedCIDRs, ",")
+	}
+	guard, err := netguard.New(guardCIDRs)
 	if err != nil {
-		setupLog.Error(err, "unable create ingress provider")
+		setupLog.Error(err, "invalid --blocked-cidrs")
+		os.Exit(1)
+	}
+
+	var resolverConfig verification.RateLimitConfig
+	// HTTP requests to tenant domains must not reach internal addresses
+	httpClient := guard.HTTPClient(nil)
+	switch verificationView {
+	case "local":
+		verificationView = verification.ViewLocal
+		if dnsServers != "" {
+			resolverConfig.Servers = strings.Split(dnsServers, ",")
+		}
+	case "external":
+		verificationView = verification.ViewExternal
+		resolverConfig.Servers = strings.Split(externalDNSServers, ",")
+		server, err := verification.ParseServer(resolverConfig.Servers[0])
+		if err != nil {
+			setupLog.Error(err, "invalid --external-dns-servers")
+			os.Exit(1)
+		}
+		httpClient = guard.HTTPClient(server.Resolver())
+	default:
+		setupLog.Info("invalid --verification-view, must be local or external", "view", verificationView)
+		os.Exit(1)
+	}
+	for _, server := range resolverConfig.Servers {
+		if _, err := verification.ParseServer(server); err != nil {
+			setupLog.Error(err, "invalid --dns-servers")
+			os.Exit(1)
+		}
+	}
+	resolverConfig.QPS = dnsQPS
+	resolverConfig.Burst = dnsBurst
+	rateLimitedResolver := verification.NewRateLimitedResolver(resolverConfig)
+	checkedResolvers := []*verification.RateLimitedResolver{rateLimitedResolver}
+	var resolver verification.Resolver = rateLimitedResolver
+	if dnsAuthoritative {
+		resolver = verification.NewAuthoritativeResolver(rateLimitedResolver, resolverConfig)
+	}
+	if verificationResolvers != "" {
+		var resolvers []verification.Resolver
+		for _, server := range strings.Split(verificationResolvers, ",") {
+			if _, err := verification.ParseServer(server); err != nil {
+				setupLog.Error(err, "invalid --verification-resolvers")
+				os.Exit(1)
+			}
+			r := verification.NewRateLimitedResolver(verification.RateLimitConfig{
+				Servers: []string{server},
+				QPS:     dnsQPS,
+				Burst:   dnsBurst,
+			})
+			resolvers = append(resolvers, r)
+			checkedResolvers = append(checkedResolvers, r)
+		}
+		resolver = verification.NewQuorumResolver(resolvers, verificationResolverQuorum)
+	}
+	cacheConfig := verification.CacheConfig{TTL: dnsCacheTTL, NegativeTTL: dnsNegativeCacheTTL}
+	resolver = verification.NewCachingResolver(resolver, cacheConfig)
+	dnsConfigResolver := verification.NewCachingResolver(rateLimitedResolver, cacheConfig)
+
+	var tokenGenerator verification.TokenGenerator
+	var verificationKeySecretName *types.NamespacedName
+	if verificationKeySecret != "" {
+		parts := strings.SplitN(verificationKeySecret, "/", 2)
+		if len(parts) != 2 {
+			setupLog.Error(nil, "invalid verification key secret reference", "secret", verificationKeySecret)
+			os.Exit(1)
+		}
+		verificationKeySecretName = &types.NamespacedName{Namespace: parts[0], Name: parts[1]}
+		secretTokenGenerator := &verification.SecretTokenGenerator{
+			Client:        mgr.GetClient(),
+			Log:           ctrl.Log.WithName("verification").WithName("SecretTokenGenerator"),
+			GeneratorType: verificationTokenGenerator,
+			SecretName:    *verificationKeySecretName,
+		}
+		// Load secrets before starting reconcilers, since cache is not
+		// started yet.
+		if err := secretTokenGenerator.Load(context.Background(), mgr.GetAPIReader()); err != nil {
+			setupLog.Error(err, "unable load verification token secrets")
+			os.Exit(1)
+		}
+		if err := secretTokenGenerator.SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "SecretTokenGenerator")
+			os.Exit(1)
+		}
+		if err := mgr.AddReadyzCheck("verification-key", internal.TokenSecretChecker(secretTokenGenerator)); err != nil {
+			setupLog.Error(err, "unable to add readiness check", "check", "verification-key")
+			os.Exit(1)
+		}
+		tokenGenerator = secretTokenGenerator
+	} else {
+		var tokenSecrets [][]byte
+		if verificationTokenSecretFile != "" {
+			secretFile, err := ioutil.ReadFile(verificationTokenSecretFile)
+			if err != nil {
+				setupLog.Error(err, "unable read verification token secrets")
+				os.Exit(1)
+			}
+			tokenSecrets = verification.ParseTokenSecrets(secretFile)
+		}
+		tokenGenerator, err = verification.NewTokenGenerator(verificationTokenGenerator, tokenSecrets)
+		if err != nil {
+			setupLog.Error(err, "unable create verification token generator")
+			os.Exit(1)
+		}
+	}
+
+	var emailChallenger *verification.EmailChallenger
+	if emailSMTPAddress != "" {
+		if !features.Enabled(features.EmailVerification) {
+			setupLog.Info("Email verification is configured, but feature gate is disabled", "feature", features.EmailVerification)
+			os.Exit(1)
+		}
+		if attestationAddr == "" || emailConfirmationURL == "" || emailFrom == "" {
+			setupLog.Info("--attestation-bind-address, --email-verification-url and --email-verification-from are required with --email-verification-smtp-address")
+			os.Exit(1)
+		}
+		var smtpPassword []byte
+		if emailSMTPPasswordFile != "" {
+			smtpPassword, err = ioutil.ReadFile(emailSMTPPasswordFile)
+			if err != nil {
+				setupLog.Error(err, "unable read SMTP password")
+				os.Exit(1)
+			}
+		}
+		emailChallenger = &verification.EmailChallenger{
+			RDAP: verification.NewRDAPClient(rdapBaseURL, &http.Client{}),
+			Mailer: &verification.SMTPMailer{
+				Address:  emailSMTPAddress,
+				Username: emailSMTPUsername,
+				Password: strings.TrimSpace(string(smtpPassword)),
+				From:     emailFrom,
+			},
+			ConfirmationURL: strings.TrimSuffix(emailConfirmationURL, "/") + attestation.EmailConfirmationPath,
+			NamespaceBudget: emailNamespaceBudget,
+		}
+	}
+
+	if attestationAddr != "" {
+		if err := mgr.Add(&attestation.Server{
+			ListenAddress:     attestationAddr,
+			Client:            kubeClient,
+			DomainReader:      domainClient,
+			DomainNamespace:   controllers.DomainNamespace,
+			Log:               ctrl.Log.WithName("attestation"),
+			Now:               metav1.Now,
+			EmailConfirmation: emailChallenger != nil,
+		}); err != nil {
+			setupLog.Error(err, "unable add attestation server")
+			os.Exit(1)
+		}
+	}
+
+	if adminAddr != "" {
+		if adminTokenFile == "" {
+			setupLog.Info("--admin-token-file is required with --admin-bind-address")
+			os.Exit(1)
+		}
+		adminToken, err := ioutil.ReadFile(adminTokenFile)
+		if err != nil {
+			setupLog.Error(err, "unable read admin token")
+			os.Exit(1)
+		}
+		if err := mgr.Add(&admin.Server{
+			ListenAddress: adminAddr,
+			Token:         strings.TrimSpace(string(adminToken)),
+			Client:        kubeClient,
+			Log:           ctrl.Log.WithName("admin"),
+			Now:           metav1.Now,
+		}); err != nil {
+			setupLog.Error(err, "unable add admin server")
+			os.Exit(1)
+		}
+	}
+
+	if portalAddr != "" {
+		if portalTokenFile == "" {
+			setupLog.Info("--portal-token-file is required with --portal-bind-address")
+			os.Exit(1)
+		}
+		portalToken, err := ioutil.ReadFile(portalTokenFile)
+		if err != nil {
+			setupLog.Error(err, "unable read portal token")
+			os.Exit(1)
+		}
+		if err := mgr.Add(&portal.Server{
+			ListenAddress: portalAddr,
+			Token:         strings.TrimSpace(string(portalToken)),
+			Client:        mgr.GetClient(),
+			Informers:     mgr.GetCache(),
+			Log:           ctrl.Log.WithName("portal"),
+		}); err != nil {
+			setupLog.Error(err, "unable add portal server")
+			os.Exit(1)
+		}
+	}
+
+	if otlpEndpoint != "" {
+		exporter := tracing.NewExporter(otlpEndpoint, "k8s-domain-controller", ctrl.Log.WithName("tracing"))
+		if err := mgr.Add(exporter); err != nil {
+			setupLog.Error(err, "unable add trace exporter")
+			os.Exit(1)
+		}
+		tracing.SetExporter(exporter, traceSampleRatio)
+	}
+
+	if err := mgr.Add(&internal.ConfigurationReloader{
+		Path: configFile,
+		Log:  ctrl.Log.WithName("configuration"),
+		OnReload: func(configuration *internal.DomainControllerConfiguration) {
+			flags := configuration.Flags()
+			for _, name := range internal.HotReloadableFlags {
+				if explicitFlags[name] {
+					continue
+				}
+				value, ok := flags[name]
+				if !ok {
+					value = flag.Lookup(name).DefValue
+				}
+				if err := flag.Set(name, value); err != nil {
+					setupLog.Error(err, "invalid configuration, ignored", "flag", name)
+				}
+			}
+			for _, r := range checkedResolvers {
+				r.SetQPS(dnsQPS)
+			}
+			if names, err := parseLogDomains(logDomains); err != nil {
+				setupLog.Error(err, "invalid domain name in log domains, ignored")
+			} else {
+				controllers.SetLogDomains(names)
+			}
+			tracing.SetSampleRatio(traceSampleRatio)
+		},
+	}); err != nil {
+		setupLog.Error(err, "unable add configuration reloader")
+		os.Exit(1)
+	}
+
+	var notifier notification.Notifier
+	if notificationWebhookURL != "" {
+		webhookNotifier := notification.NewWebhookNotifier(notificationWebhookURL, ctrl.Log.WithName("notification"))
+		if err := mgr.Add(webhookNotifier); err != nil {
+			setupLog.Error(err, "unable add notification webhook")
+			os.Exit(1)
+		}
+		notifier = webhookNotifier
+	}
+
+	var httpVerifier *verification.HTTPVerifier
+	if features.Enabled(features.HTTPVerification) {
+		httpVerifier = verification.NewHTTPVerifier(httpClient)
+	}
+	domainVerifier := verification.NewVerifier(
+		verification.NewDNSVerifier(resolver),
+		httpVerifier,
+		verification.NewCNAMEVerifier(resolver),
+	)
+	domainVerifier.TokenCNAMETarget = verificationCNAMETarget
+	domainVerifier.NewResolver = func(servers []string) verification.Resolver {
+		return verification.NewCachingResolver(verification.NewRateLimitedResolver(verification.RateLimitConfig{
+			Servers: servers,
+			QPS:     dnsQPS,
+			Burst:   dnsBurst,
+		}), cacheConfig)
+	}
+
+	if err := mgr.AddHealthzCheck("ping", healthz.Ping); err != nil {
+		setupLog.Error(err, "unable to add health check", "check", "ping")
+		os.Exit(1)
+	}
+	if err := mgr.AddReadyzCheck("dns", internal.ResolverChecker(checkedResolvers...)); err != nil {
+		setupLog.Error(err, "unable to add readiness check", "check", "dns")
 		os.Exit(1)
 	}
 
 	if enableWebhooks {
+		if err := mgr.AddReadyzCheck("webhook-certs", internal.WebhookCertChecker(webhookCertDir)); err != nil {
+			setupLog.Error(err, "unable to add readiness check", "check", "webhook-certs")
+			os.Exit(1)
+		}
 		if err = (&domainv1beta1.CustomDomainRegistration{}).SetupWebhookWithManager(mgr); err != nil {
 			setupLog.Error(err, "unable to create webhook", "webhook", "CustomDomainRegistration")
 			os.Exit(1)
@@ -117,30 +746,98 @@ func main() {
 			os.Exit(1)
 		}
 	}
+	auditLogger := &controllers.AuditLogger{
+		Log:      ctrl.Log.WithName("audit"),
+		Recorder: mgr.GetEventRecorderFor("domain-audit"),
+	}
 	if err = (&controllers.CustomDomainRegistrationReconciler{
-		Client:                     mgr.GetClient(),
-		Log:                        ctrl.Log.WithName("controllers").WithName("CustomDomainRegistration"),
-		Scheme:                     mgr.GetScheme(),
-		Now:                        metav1.Now,
-		VerificationTokenGenerator: verification.GenerateDomainToken,
-		DomainVerifier:             verification.VerifyDomain,
-		TLSProvider:                tlsProvider,
-		IngressProvider:            ingressProvider,
+		Client:                         kubeClient,
+		Log:                            ctrl.Log.WithName("controllers").WithName("CustomDomainRegistration"),
+		Scheme:                         mgr.GetScheme(),
+		Now:                            metav1.Now,
+		VerificationTokenGenerator:     tokenGenerator,
+		DomainVerifier:                 domainVerifier.VerifyDomain,
+		DNSConfigChecker:               verification.NewDNSConfigChecker(dnsConfigResolver).CheckDNSConfig,
+		VerificationChallengeZone:      verificationChallengeZone,
+		TLSProvider:                    tlsProvider,
+		RoutingProvider:                routingProvider,
+		Recorder:                       mgr.GetEventRecorderFor("customdomainregistration-controller"),
+		Audit:                          auditLogger,
+		VerificationKeySecret:          verificationKeySecretName,
+		DomainClient:                   domainClient,
+		DomainCache:                    domainCache,
+		ClusterName:                    clusterName,
+		Notifier:                       notifier,
+		InheritParentVerification:      inheritParentVerification,
+		VerificationView:               verificationView,
+		VerificationCNAMETarget:        verificationCNAMETarget,
+		VerificationRecordPrefix:       verificationRecordPrefix,
+		EndpointProber:                 healthcheck.NewProber(httpClient).Probe,
+		RequireApproval:                domainv1beta1.ApprovalMode(requireApproval),
+		DefaultDomainSuffix:            defaultDomainSuffix,
+		DefaultDomainNamespaceSelector: defaultDomainSelector,
+		EmailChallenger:                emailChallenger,
+		MaxConcurrentReconciles:        registrationConcurrency,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "CustomDomainRegistration")
 		os.Exit(1)
 	}
-	if err = (&controllers.CustomDomainReconciler{
-		Client:                   mgr.GetClient(),
-		Log:                      ctrl.Log.WithName("controllers").WithName("CustomDomain"),
-		Scheme:                   mgr.GetScheme(),
-		Now:                      metav1.Now,
-		LoadBalancer:             loadBalancer,
-		VerificationKeyGenerator: verification.GenerateDomainKey,
+	domainInstalled, err := controllers.IsCustomDomainInstalled(mgr.GetRESTMapper())
+	if err != nil {
+		setupLog.Error(err, "unable to discover CustomDomain resource")
+		os.Exit(1)
+	}
+	if hubKubeconfig != "" {
+		setupLog.Info("custom domains are reconciled in hub cluster, skipping controller", "controller", "CustomDomain")
+	} else if !domainInstalled {
+		setupLog.Info("CustomDomain resource is not installed, skipping controller", "controller", "CustomDomain")
+	} else if err = (&controllers.CustomDomainReconciler{
+		Client:                     kubeClient,
+		Log:                        ctrl.Log.WithName("controllers").WithName("CustomDomain"),
+		Scheme:                     mgr.GetScheme(),
+		Now:                        metav1.Now,
+		LoadBalancer:               loadBalancer,
+		DNSProvider:                dnsProvider,
+		VerificationKeyGenerator:   verification.GenerateDomainKey,
+		Recorder:                   mgr.GetEventRecorderFor("customdomain-controller"),
+		Audit:                      auditLogger,
+		TakeoverConfirmations:      takeoverConfirmations,
+		TakeoverWindow:             takeoverWindow,
+		MaxConcurrentReconciles:    domainConcurrency,
+		WatchDNSProviderConfigs:    features.Enabled(features.DNSProviderConfigs),
+		WatchLoadBalancerEndpoints: features.Enabled(features.LoadBalancerEndpoints),
+		WatchIPPools:               features.Enabled(features.DedicatedIP),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "CustomDomain")
 		os.Exit(1)
 	}
+	if defaultDomainSuffix != "" {
+		if err = (&controllers.NamespaceReconciler{
+			Client:             kubeClient,
+			Log:                ctrl.Log.WithName("controllers").WithName("Namespace"),
+			Recorder:           mgr.GetEventRecorderFor("namespace-controller"),
+			Selector:           defaultDomainSelector,
+			Suffix:             defaultDomainSuffix,
+			BackendServiceName: defaultDomainBackendService,
+			BackendServicePort: defaultDomainBackendPort,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "Namespace")
+			os.Exit(1)
+		}
+	}
+	if err = (&controllers.DomainImportReconciler{
+		Client:   kubeClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("DomainImport"),
+		Recorder: mgr.GetEventRecorderFor("domainimport-controller"),
+		Now:      metav1.Now,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "DomainImport")
+		os.Exit(1)
+	}
+	if err = metrics.Registry.Register(controllers.NewRegistrationCollector(mgr.GetClient())); err != nil {
+		setupLog.Error(err, "unable to register metrics collector")
+		os.Exit(1)
+	}
 	// +kubebuilder:scaffold:builder
 
 	setupLog.Info("starting manager")
@@ -149,3 +846,19 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// parseLogDomains parses comma-separated domain names of --log-domains.
+func parseLogDomains(value string) ([]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+	var domainNames []string
+	for _, name := range strings.Split(value, ",") {
+		domainName, err := dnsname.Normalize(strings.TrimSpace(name))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		domainNames = append(domainNames, domainName)
+	}
+	return domainNames, nil
+}
@@ -0,0 +1,93 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/skygeario/k8s-controller/api"
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/util/bearer"
+	"github.com/skygeario/k8s-controller/pkg/util/httpserver"
+)
+
+// VerifyPath is the path prefix requesting verification of registrations,
+// in form of /verify/{namespace}/{name}.
+const VerifyPath = "/verify/"
+
+// Server serves administrative operations of registrations for support
+// staff and dashboards. Requests are authenticated by bearer token.
+type Server struct {
+	ListenAddress string
+	// Token is the bearer token of requests.
+	Token  string
+	Client client.Client
+	Log    logr.Logger
+	Now    func() metav1.Time
+}
+
+func (s *Server) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	if !bearer.Authenticate(r, s.Token) {
+		rw.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+	if !strings.HasPrefix(r.URL.Path, VerifyPath) {
+		http.NotFound(rw, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, VerifyPath), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		http.NotFound(rw, r)
+		return
+	}
+
+	status, err := s.requestVerification(r.Context(), types.NamespacedName{Namespace: parts[0], Name: parts[1]})
+	if err != nil {
+		s.Log.Error(err, "failed to request verification", "namespace", parts[0], "name", parts[1])
+	}
+	if status != http.StatusAccepted {
+		http.Error(rw, http.StatusText(status), status)
+		return
+	}
+	rw.WriteHeader(http.StatusAccepted)
+}
+
+// requestVerification annotates the registration with the request time, so
+// that it is verified by the reconciler.
+func (s *Server) requestVerification(ctx context.Context, name types.NamespacedName) (int, error) {
+	var reg domainv1beta1.CustomDomainRegistration
+	err := s.Client.Get(ctx, name, &reg)
+	if errors.IsNotFound(err) {
+		return http.StatusNotFound, nil
+	} else if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	patch := client.MergeFrom(reg.DeepCopy())
+	if reg.Annotations == nil {
+		reg.Annotations = map[string]string{}
+	}
+	reg.Annotations[api.VerifyNowAnnotation] = s.Now().UTC().Format(time.RFC3339)
+	if err := s.Client.Patch(ctx, &reg, patch); err != nil {
+		return http.StatusInternalServerError, err
+	}
+	s.Log.Info("requested verification", "namespace", name.Namespace, "name", name.Name)
+	return http.StatusAccepted, nil
+}
+
+// Start implements manager.Runnable, serving requests until stop is closed.
+func (s *Server) Start(stop <-chan struct{}) error {
+	return httpserver.Run(&http.Server{Addr: s.ListenAddress, Handler: s}, stop, 0)
+}
@@ -0,0 +1,86 @@
+package admin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/skygeario/k8s-controller/api"
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+)
+
+func TestServer(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := domainv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	now := metav1.NewTime(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	newServer := func() *Server {
+		return &Server{
+			Token: "secret",
+			Client: fake.NewFakeClientWithScheme(scheme, &domainv1beta1.CustomDomainRegistration{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "www.example.com"},
+			}),
+			Log: ctrl.Log.WithName("admin"),
+			Now: func() metav1.Time { return now },
+		}
+	}
+
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		authorization string
+		status        int
+		annotated     bool
+	}{
+		{"missing token", http.MethodPost, "/verify/app/www.example.com", "", http.StatusUnauthorized, false},
+		{"wrong token", http.MethodPost, "/verify/app/www.example.com", "Bearer wrong", http.StatusUnauthorized, false},
+		{"wrong scheme", http.MethodPost, "/verify/app/www.example.com", "Basic secret", http.StatusUnauthorized, false},
+		{"unknown path", http.MethodPost, "/unknown/app/www.example.com", "Bearer secret", http.StatusNotFound, false},
+		{"wrong method", http.MethodGet, "/verify/app/www.example.com", "Bearer secret", http.StatusMethodNotAllowed, false},
+		{"missing name", http.MethodPost, "/verify/app/", "Bearer secret", http.StatusNotFound, false},
+		{"missing namespace", http.MethodPost, "/verify//www.example.com", "Bearer secret", http.StatusNotFound, false},
+		{"extra segment", http.MethodPost, "/verify/app/www.example.com/extra", "Bearer secret", http.StatusNotFound, false},
+		{"unknown registration", http.MethodPost, "/verify/app/unknown.example.com", "Bearer secret", http.StatusNotFound, false},
+		{"verify", http.MethodPost, "/verify/app/www.example.com", "Bearer secret", http.StatusAccepted, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newServer()
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rw := httptest.NewRecorder()
+			s.ServeHTTP(rw, req)
+
+			if rw.Code != tt.status {
+				t.Errorf("status = %d, expected %d", rw.Code, tt.status)
+			}
+			if tt.status == http.StatusUnauthorized && rw.Header().Get("WWW-Authenticate") != "Bearer" {
+				t.Errorf("missing WWW-Authenticate challenge")
+			}
+
+			var reg domainv1beta1.CustomDomainRegistration
+			if err := s.Client.Get(req.Context(), types.NamespacedName{Namespace: "app", Name: "www.example.com"}, &reg); err != nil {
+				t.Fatal(err)
+			}
+			annotation, annotated := reg.Annotations[api.VerifyNowAnnotation]
+			if annotated != tt.annotated {
+				t.Errorf("annotated = %v, expected %v", annotated, tt.annotated)
+			}
+			if annotated && annotation != "2020-01-02T03:04:05Z" {
+				t.Errorf("annotation = %q, expected request time", annotation)
+			}
+		})
+	}
+}
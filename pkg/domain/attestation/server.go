@@ -0,0 +1,297 @@
+package attestation
+
+import (
+	"context"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/verification"
+	"github.com/skygeario/k8s-controller/pkg/util/dnsname"
+	"github.com/skygeario/k8s-controller/pkg/util/httpserver"
+	"github.com/skygeario/k8s-controller/pkg/util/slice"
+)
+
+// AttestationPath is the path accepting attestations from external verifiers.
+const AttestationPath = "/v1/attestations"
+
+// EmailConfirmationPath is the path of confirmation links sent in Email
+// verification.
+const EmailConfirmationPath = "/v1/email-confirmations"
+
+// EmailConfirmationAttester is the attester of attestations recorded by
+// confirmation links.
+const EmailConfirmationAttester = "domain contact email"
+
+// MaxClockSkew is the maximum difference between attestation timestamp and
+// current time.
+const MaxClockSkew = 5 * time.Minute
+
+const maxRequestSize = 64 * 1024
+
+// Request is an attestation of domain ownership of a registration, signed
+// with verification key of the domain.
+type Request struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Domain    string `json:"domain"`
+	Timestamp int64  `json:"timestamp"`
+	Signature string `json:"signature"`
+	Attester  string `json:"attester,omitempty"`
+}
+
+// Server accepts attestations of domain ownership from external verifiers,
+// and records them in status of registrations.
+type Server struct {
+	ListenAddress string
+	// Client reads and updates registrations.
+	Client client.Client
+	// DomainReader reads custom domains.
+	DomainReader client.Reader
+	// DomainNamespace is the namespace of custom domains, if namespaced.
+	DomainNamespace string
+	Log             logr.Logger
+	Now             func() metav1.Time
+	// EmailConfirmation enables serving confirmation links of Email
+	// verification.
+	EmailConfirmation bool
+}
+
+func (s *Server) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == AttestationPath:
+		s.serveAttestation(rw, r)
+	case r.URL.Path == EmailConfirmationPath && s.EmailConfirmation:
+		s.serveEmailConfirmation(rw, r)
+	default:
+		http.NotFound(rw, r)
+	}
+}
+
+func (s *Server) serveAttestation(rw http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req Request
+	if err := json.NewDecoder(http.MaxBytesReader(rw, r.Body, maxRequestSize)).Decode(&req); err != nil {
+		http.Error(rw, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Namespace == "" || req.Name == "" || req.Domain == "" || req.Signature == "" {
+		http.Error(rw, "missing required fields", http.StatusBadRequest)
+		return
+	}
+
+	status, err := s.attest(r.Context(), &req)
+	if err != nil {
+		s.Log.Error(err, "failed to process attestation", "namespace", req.Namespace, "name", req.Name)
+	}
+	if status != http.StatusAccepted {
+		http.Error(rw, http.StatusText(status), status)
+		return
+	}
+	rw.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) attest(ctx context.Context, req *Request) (int, error) {
+	now := s.Now()
+	timestamp := time.Unix(req.Timestamp, 0)
+	if skew := now.Sub(timestamp); skew > MaxClockSkew || skew < -MaxClockSkew {
+		return http.StatusBadRequest, nil
+	}
+
+	message := func(domainName string) []byte {
+		return verification.AttestationMessage(req.Namespace, req.Name, domainName, timestamp)
+	}
+	reg, status, err := s.checkSignature(ctx, req.Namespace, req.Name, req.Domain, message, req.Signature)
+	if status != http.StatusOK {
+		return status, err
+	}
+	// Attestations are accepted in order of timestamp, so that replayed
+	// attestations cannot attest registrations changed since recorded.
+	if a := reg.Status.Attestation; a != nil && !timestamp.After(a.Time.Time) {
+		return http.StatusConflict, nil
+	}
+
+	patch := client.MergeFrom(reg.DeepCopy())
+	reg.Status.Attestation = &domainv1beta1.CustomDomainAttestationStatus{
+		Time:               metav1.NewTime(timestamp),
+		ObservedGeneration: reg.Generation,
+		Attester:           req.Attester,
+	}
+	if err := s.Client.Status().Patch(ctx, reg, patch); err != nil {
+		return http.StatusInternalServerError, err
+	}
+	s.Log.Info("accepted attestation", "namespace", req.Namespace, "name", req.Name, "attester", req.Attester)
+	return http.StatusAccepted, nil
+}
+
+var emailConfirmationPage = template.Must(template.New("email-confirmation").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Confirm use of domain</title></head>
+<body>
+{{- if .Form }}
+<p>Confirm use of the domain <strong>{{ .Domain }}</strong>?</p>
+<form method="POST" action="{{ .Path }}">
+{{- range $key, $values := .Form }}{{ range $values }}
+<input type="hidden" name="{{ $key }}" value="{{ . }}">
+{{- end }}{{ end }}
+<button type="submit">Confirm</button>
+</form>
+{{- else }}
+<p>{{ .Message }}</p>
+{{- end }}
+</body>
+</html>
+`))
+
+type emailConfirmationPageData struct {
+	Path    string
+	Domain  string
+	Form    map[string][]string
+	Message string
+}
+
+// serveEmailConfirmation asks for confirmation on GET, and confirms on POST,
+// so that links prefetched by email scanners are not confirmed.
+func (s *Server) serveEmailConfirmation(rw http.ResponseWriter, r *http.Request) {
+	var status int
+	data := emailConfirmationPageData{Path: EmailConfirmationPath}
+	switch r.Method {
+	case http.MethodGet:
+		c, _, err := verification.ParseEmailConfirmation(r.URL.Query())
+		if err != nil {
+			status, data.Message = http.StatusBadRequest, "The confirmation link is invalid."
+			break
+		}
+		status, data.Domain, data.Form = http.StatusOK, c.Domain, r.URL.Query()
+
+	case http.MethodPost:
+		r.Body = http.MaxBytesReader(rw, r.Body, maxRequestSize)
+		if err := r.ParseForm(); err != nil {
+			status, data.Message = http.StatusBadRequest, "The confirmation link is invalid."
+			break
+		}
+		c, signature, err := verification.ParseEmailConfirmation(r.PostForm)
+		if err != nil {
+			status, data.Message = http.StatusBadRequest, "The confirmation link is invalid."
+			break
+		}
+		status, err = s.confirmEmail(r.Context(), c, signature)
+		if err != nil {
+			s.Log.Error(err, "failed to process email confirmation", "namespace", c.Namespace, "name", c.Name)
+		}
+		switch status {
+		case http.StatusOK:
+			data.Message = "The use of domain is confirmed. You can close this page."
+		case http.StatusGone:
+			data.Message = "The confirmation link has expired."
+		case http.StatusInternalServerError:
+			data.Message = "The confirmation cannot be processed. Please try again later."
+		default:
+			data.Message = "The confirmation link is invalid."
+		}
+
+	default:
+		http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+	rw.WriteHeader(status)
+	if err := emailConfirmationPage.Execute(rw, data); err != nil {
+		s.Log.Error(err, "failed to render email confirmation page")
+	}
+}
+
+func (s *Server) confirmEmail(ctx context.Context, c *verification.EmailConfirmation, signature string) (int, error) {
+	now := s.Now()
+	if !now.Time.Before(c.Expiry) {
+		return http.StatusGone, nil
+	}
+
+	message := func(domainName string) []byte {
+		confirmation := *c
+		confirmation.Domain = domainName
+		return confirmation.Message()
+	}
+	reg, status, err := s.checkSignature(ctx, c.Namespace, c.Name, c.Domain, message, signature)
+	if status != http.StatusOK {
+		return status, err
+	}
+	if reg.Generation != c.Generation {
+		// Registration is changed since the link is sent.
+		return http.StatusGone, nil
+	}
+
+	patch := client.MergeFrom(reg.DeepCopy())
+	reg.Status.Attestation = &domainv1beta1.CustomDomainAttestationStatus{
+		Time:               now,
+		ObservedGeneration: reg.Generation,
+		Attester:           EmailConfirmationAttester,
+	}
+	if err := s.Client.Status().Patch(ctx, reg, patch); err != nil {
+		return http.StatusInternalServerError, err
+	}
+	s.Log.Info("accepted email confirmation", "namespace", c.Namespace, "name", c.Name)
+	return http.StatusOK, nil
+}
+
+// checkSignature returns the registration of the domain, if the message is
+// signed by verification key of the domain. It returns http.StatusOK if
+// the signature is valid, or the status code of response otherwise.
+func (s *Server) checkSignature(
+	ctx context.Context,
+	namespace string,
+	name string,
+	domain string,
+	message func(domainName string) []byte,
+	signature string,
+) (*domainv1beta1.CustomDomainRegistration, int, error) {
+	var reg domainv1beta1.CustomDomainRegistration
+	err := s.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &reg)
+	if errors.IsNotFound(err) {
+		return nil, http.StatusNotFound, nil
+	} else if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	domainName, err := dnsname.Normalize(domain)
+	if err != nil || domainName != reg.ASCIIDomainName() {
+		return nil, http.StatusNotFound, nil
+	}
+
+	var customDomain domainv1beta1.CustomDomain
+	err = s.DomainReader.Get(ctx, types.NamespacedName{Namespace: s.DomainNamespace, Name: dnsname.ResourceName(domainName)}, &customDomain)
+	if errors.IsNotFound(err) {
+		return nil, http.StatusNotFound, nil
+	} else if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	if !slice.ContainsRegistrationReference(customDomain.Spec.Registrations, &reg) {
+		return nil, http.StatusNotFound, nil
+	}
+
+	msg := message(domainName)
+	for _, key := range []*string{customDomain.Spec.VerificationKey, customDomain.Spec.PreviousVerificationKey} {
+		if key != nil && verification.VerifyAttestation(*key, msg, signature) {
+			return &reg, http.StatusOK, nil
+		}
+	}
+	return nil, http.StatusUnauthorized, nil
+}
+
+// Start implements manager.Runnable, serving attestations until stop is
+// closed.
+func (s *Server) Start(stop <-chan struct{}) error {
+	return httpserver.Run(&http.Server{Addr: s.ListenAddress, Handler: s}, stop, 0)
+}
@@ -0,0 +1,270 @@
+package attestation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/verification"
+)
+
+var testNow = metav1.NewTime(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))
+
+func newTestServer(t *testing.T, objs ...runtime.Object) *Server {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := domainv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	c := fake.NewFakeClientWithScheme(scheme, objs...)
+	return &Server{
+		Client:            c,
+		DomainReader:      c,
+		Log:               ctrl.Log.WithName("attestation"),
+		Now:               func() metav1.Time { return testNow },
+		EmailConfirmation: true,
+	}
+}
+
+// newTestObjects returns a registration of example.com, and its domain with
+// verification keys.
+func newTestObjects() (*domainv1beta1.CustomDomainRegistration, *domainv1beta1.CustomDomain) {
+	reg := &domainv1beta1.CustomDomainRegistration{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "example.com", UID: "reg-uid", Generation: 2},
+		Spec:       domainv1beta1.CustomDomainRegistrationSpec{DomainName: "example.com"},
+	}
+	domain := &domainv1beta1.CustomDomain{
+		ObjectMeta: metav1.ObjectMeta{Name: "example.com"},
+		Spec: domainv1beta1.CustomDomainSpec{
+			VerificationKey:         pointer.StringPtr("key"),
+			PreviousVerificationKey: pointer.StringPtr("previous-key"),
+			Registrations: []domainv1beta1.CustomDomainRegistrationReference{{
+				ObjectReference: corev1.ObjectReference{Namespace: "app", Name: "example.com", UID: "reg-uid"},
+			}},
+		},
+	}
+	return reg, domain
+}
+
+func signedRequest(key string, timestamp time.Time) Request {
+	message := verification.AttestationMessage("app", "example.com", "example.com", timestamp)
+	return Request{
+		Namespace: "app",
+		Name:      "example.com",
+		Domain:    "example.com",
+		Timestamp: timestamp.Unix(),
+		Signature: verification.SignAttestation(key, message),
+		Attester:  "registrar",
+	}
+}
+
+func postAttestation(s *Server, req Request) int {
+	body, _ := json.Marshal(req)
+	rw := httptest.NewRecorder()
+	s.ServeHTTP(rw, httptest.NewRequest(http.MethodPost, AttestationPath, bytes.NewReader(body)))
+	return rw.Code
+}
+
+func getAttestation(t *testing.T, s *Server) *domainv1beta1.CustomDomainAttestationStatus {
+	var reg domainv1beta1.CustomDomainRegistration
+	if err := s.Client.Get(context.Background(), types.NamespacedName{Namespace: "app", Name: "example.com"}, &reg); err != nil {
+		t.Fatal(err)
+	}
+	return reg.Status.Attestation
+}
+
+func TestAttestation(t *testing.T) {
+	tests := []struct {
+		name    string
+		request func() Request
+		status  int
+	}{
+		{"signed by key", func() Request { return signedRequest("key", testNow.Time) }, http.StatusAccepted},
+		{"signed by previous key", func() Request { return signedRequest("previous-key", testNow.Time) }, http.StatusAccepted},
+		{"within clock skew", func() Request { return signedRequest("key", testNow.Add(-MaxClockSkew)) }, http.StatusAccepted},
+		{"signed by other key", func() Request { return signedRequest("other-key", testNow.Time) }, http.StatusUnauthorized},
+		{"invalid signature", func() Request {
+			req := signedRequest("key", testNow.Time)
+			req.Signature = "not-hex"
+			return req
+		}, http.StatusUnauthorized},
+		{"signature of other domain", func() Request {
+			req := signedRequest("key", testNow.Time)
+			req.Domain = "www.example.com"
+			return req
+		}, http.StatusNotFound},
+		{"tampered timestamp", func() Request {
+			req := signedRequest("key", testNow.Add(-time.Minute))
+			req.Timestamp = testNow.Unix()
+			return req
+		}, http.StatusUnauthorized},
+		{"timestamp too old", func() Request { return signedRequest("key", testNow.Add(-MaxClockSkew-time.Second)) }, http.StatusBadRequest},
+		{"timestamp in future", func() Request { return signedRequest("key", testNow.Add(MaxClockSkew+time.Second)) }, http.StatusBadRequest},
+		{"registration not found", func() Request {
+			req := signedRequest("key", testNow.Time)
+			req.Name = "other"
+			return req
+		}, http.StatusNotFound},
+		{"missing signature", func() Request {
+			req := signedRequest("key", testNow.Time)
+			req.Signature = ""
+			return req
+		}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg, domain := newTestObjects()
+			s := newTestServer(t, reg, domain)
+			req := tt.request()
+
+			if status := postAttestation(s, req); status != tt.status {
+				t.Fatalf("status = %d, expected %d", status, tt.status)
+			}
+			a := getAttestation(t, s)
+			if tt.status != http.StatusAccepted {
+				if a != nil {
+					t.Errorf("attestation is recorded: %#v", a)
+				}
+				return
+			}
+			if a == nil || a.Time.Unix() != req.Timestamp || a.ObservedGeneration != 2 || a.Attester != "registrar" {
+				t.Errorf("attestation = %#v", a)
+			}
+		})
+	}
+}
+
+func TestAttestationUnreferencedRegistration(t *testing.T) {
+	reg, domain := newTestObjects()
+	domain.Spec.Registrations = nil
+	s := newTestServer(t, reg, domain)
+
+	if status := postAttestation(s, signedRequest("key", testNow.Time)); status != http.StatusNotFound {
+		t.Errorf("status = %d, expected %d", status, http.StatusNotFound)
+	}
+}
+
+func TestAttestationReplay(t *testing.T) {
+	reg, domain := newTestObjects()
+	s := newTestServer(t, reg, domain)
+	ctx := context.Background()
+
+	req := signedRequest("key", testNow.Add(-time.Minute))
+	if status := postAttestation(s, req); status != http.StatusAccepted {
+		t.Fatalf("status = %d", status)
+	}
+
+	// Registration is changed after the attestation
+	var current domainv1beta1.CustomDomainRegistration
+	if err := s.Client.Get(ctx, types.NamespacedName{Namespace: "app", Name: "example.com"}, &current); err != nil {
+		t.Fatal(err)
+	}
+	current.Generation = 3
+	if err := s.Client.Update(ctx, &current); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, replayed := range []Request{req, signedRequest("key", testNow.Add(-2*time.Minute))} {
+		if status := postAttestation(s, replayed); status != http.StatusConflict {
+			t.Errorf("status = %d, expected %d", status, http.StatusConflict)
+		}
+	}
+	if a := getAttestation(t, s); a.ObservedGeneration != 2 {
+		t.Errorf("replayed attestation attests generation %d", a.ObservedGeneration)
+	}
+
+	// New attestations are accepted
+	if status := postAttestation(s, signedRequest("key", testNow.Time)); status != http.StatusAccepted {
+		t.Errorf("status = %d", status)
+	}
+	if a := getAttestation(t, s); a.ObservedGeneration != 3 {
+		t.Errorf("attestation = %#v", a)
+	}
+}
+
+func TestAttestationMethod(t *testing.T) {
+	s := newTestServer(t)
+	rw := httptest.NewRecorder()
+	s.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, AttestationPath, nil))
+	if rw.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d", rw.Code)
+	}
+}
+
+func TestEmailConfirmation(t *testing.T) {
+	confirmation := func(generation int64, expiry time.Time) url.Values {
+		c := verification.EmailConfirmation{
+			Namespace:  "app",
+			Name:       "example.com",
+			Domain:     "example.com",
+			Generation: generation,
+			Expiry:     expiry,
+		}
+		return c.Query(verification.SignAttestation("key", c.Message()))
+	}
+	valid := confirmation(2, testNow.Add(time.Hour))
+
+	tests := []struct {
+		name   string
+		method string
+		query  url.Values
+		status int
+	}{
+		{"confirm", http.MethodPost, valid, http.StatusOK},
+		{"prefetch", http.MethodGet, valid, http.StatusOK},
+		{"expired", http.MethodPost, confirmation(2, testNow.Time), http.StatusGone},
+		{"registration changed", http.MethodPost, confirmation(1, testNow.Add(time.Hour)), http.StatusGone},
+		{"attestation signature", http.MethodPost, func() url.Values {
+			q := confirmation(2, testNow.Add(time.Hour))
+			q.Set("signature", signedRequest("key", testNow.Time).Signature)
+			return q
+		}(), http.StatusUnauthorized},
+		{"malformed", http.MethodPost, url.Values{"generation": {"x"}}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg, domain := newTestObjects()
+			s := newTestServer(t, reg, domain)
+
+			var r *http.Request
+			if tt.method == http.MethodGet {
+				r = httptest.NewRequest(tt.method, EmailConfirmationPath+"?"+tt.query.Encode(), nil)
+			} else {
+				r = httptest.NewRequest(tt.method, EmailConfirmationPath, strings.NewReader(tt.query.Encode()))
+				r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			}
+			rw := httptest.NewRecorder()
+			s.ServeHTTP(rw, r)
+			if rw.Code != tt.status {
+				t.Fatalf("status = %d, expected %d", rw.Code, tt.status)
+			}
+
+			a := getAttestation(t, s)
+			if tt.method == http.MethodPost && tt.status == http.StatusOK {
+				if a == nil || !a.Time.Equal(&testNow) || a.ObservedGeneration != 2 || a.Attester != EmailConfirmationAttester {
+					t.Errorf("attestation = %#v", a)
+				}
+			} else if a != nil {
+				t.Errorf("attestation is recorded: %#v", a)
+			}
+		})
+	}
+}
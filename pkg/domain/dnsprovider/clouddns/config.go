@@ -0,0 +1,23 @@
+package clouddns
+
+import (
+	"context"
+	"net/http"
+)
+
+type Config struct {
+	// Token returns the OAuth 2.0 access token. It is called for every
+	// request, so refreshed tokens are picked up.
+	Token func(ctx context.Context) (string, error)
+	// Project is the Google Cloud project of the managed zone.
+	Project string
+	// Zone is the DNS name of the managed zone of records.
+	Zone string
+	// RecordTTL is the TTL in seconds of records, defaults to 300.
+	RecordTTL int64
+	// BaseURL is the base URL of Cloud DNS API, defaults to DefaultBaseURL.
+	BaseURL string
+	// HTTPClient is the client of API requests, defaults to
+	// http.DefaultClient.
+	HTTPClient *http.Client
+}
@@ -0,0 +1,482 @@
+// Package clouddns provides a DNS provider managing records of a zone using
+// Google Cloud DNS API.
+package clouddns
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/dnsprovider"
+	"github.com/skygeario/k8s-controller/pkg/util/dnsname"
+)
+
+const DefaultBaseURL = "https://dns.googleapis.com/dns/v1"
+
+// defaultTTL is the TTL in seconds of records if not configured.
+const defaultTTL = 300
+
+// Cloud DNS records have no comments, so records created for a domain are
+// listed in a TXT record at ownerLabel under the domain. Records not listed
+// are never modified or deleted.
+const (
+	ownerLabel         = "_skygear-dns"
+	wildcardOwnerLabel = "_skygear-dns-wildcard"
+	ownerValuePrefix   = "domain.skygear.io/domain="
+)
+
+// Individual records are owned by a TXT record at recordOwnerLabel under the
+// record name.
+const (
+	recordOwnerLabel       = "_skygear-record"
+	recordOwnerValuePrefix = "domain.skygear.io/record="
+)
+
+type Provider struct {
+	Config Config
+
+	lock        sync.Mutex
+	managedZone string
+}
+
+func NewProvider(config Config) (*Provider, error) {
+	if config.Token == nil {
+		return nil, fmt.Errorf("Google Cloud credentials are not configured")
+	}
+	if config.Project == "" {
+		return nil, fmt.Errorf("Google Cloud project is not configured")
+	}
+	if config.Zone == "" {
+		return nil, fmt.Errorf("Cloud DNS zone is not configured")
+	}
+	if config.RecordTTL < 0 {
+		return nil, fmt.Errorf("record TTL must not be negative")
+	}
+	if config.RecordTTL == 0 {
+		config.RecordTTL = defaultTTL
+	}
+	if config.BaseURL == "" {
+		config.BaseURL = DefaultBaseURL
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = http.DefaultClient
+	}
+	return &Provider{Config: config}, nil
+}
+
+var _ dnsprovider.Provider = &Provider{}
+var _ dnsprovider.RecordProvider = &Provider{}
+
+type recordSet struct {
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	TTL     int64    `json:"ttl"`
+	RRDatas []string `json:"rrdatas"`
+}
+
+type recordSetKey struct{ name, recordType string }
+
+func (r recordSet) key() recordSetKey {
+	return recordSetKey{name: fqdn(r.Name), recordType: r.Type}
+}
+
+func (r recordSet) equal(o recordSet) bool {
+	if r.key() != o.key() || r.TTL != o.TTL || len(r.RRDatas) != len(o.RRDatas) {
+		return false
+	}
+	a := append([]string(nil), r.RRDatas...)
+	b := append([]string(nil), o.RRDatas...)
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+type change struct {
+	Additions []recordSet `json:"additions,omitempty"`
+	Deletions []recordSet `json:"deletions,omitempty"`
+	Status    string      `json:"status,omitempty"`
+}
+
+func (p *Provider) EnsureRecords(ctx context.Context, domain *domainv1beta1.CustomDomain) (bool, error) {
+	// Only domains owned by a verified registration are published.
+	if domain.Spec.OwnerRef == nil {
+		return p.DeleteRecords(ctx, domain)
+	}
+
+	zone, err := p.getManagedZone(ctx)
+	if err != nil {
+		return false, err
+	}
+	owner, owned, err := p.getOwnerRecord(ctx, zone, domain)
+	if err != nil {
+		return false, err
+	}
+	isOwned := map[recordSetKey]bool{}
+	for _, key := range owned {
+		isOwned[key] = true
+	}
+
+	var c change
+	desired := p.makeRecordSets(domain)
+	isDesired := map[recordSetKey]bool{}
+	for _, r := range desired {
+		isDesired[r.key()] = true
+		current, err := p.getRecordSet(ctx, zone, r.key())
+		if err != nil {
+			return false, err
+		}
+		switch {
+		case current == nil:
+			c.Additions = append(c.Additions, r)
+		case !isOwned[r.key()]:
+			return false, fmt.Errorf("%s record %s exists and is not managed by the controller", r.Type, r.Name)
+		case !current.equal(r):
+			c.Deletions = append(c.Deletions, *current)
+			c.Additions = append(c.Additions, r)
+		}
+	}
+	for _, key := range owned {
+		if isDesired[key] {
+			continue
+		}
+		current, err := p.getRecordSet(ctx, zone, key)
+		if err != nil {
+			return false, err
+		}
+		if current != nil {
+			c.Deletions = append(c.Deletions, *current)
+		}
+	}
+
+	newOwner := p.makeOwnerRecord(domain, desired)
+	if owner == nil || !owner.equal(newOwner) {
+		if owner != nil {
+			c.Deletions = append(c.Deletions, *owner)
+		}
+		c.Additions = append(c.Additions, newOwner)
+	}
+	return p.applyChange(ctx, zone, c)
+}
+
+func (p *Provider) DeleteRecords(ctx context.Context, domain *domainv1beta1.CustomDomain) (bool, error) {
+	zone, err := p.getManagedZone(ctx)
+	if err != nil {
+		return false, err
+	}
+	owner, owned, err := p.getOwnerRecord(ctx, zone, domain)
+	if err != nil {
+		return false, err
+	}
+	if owner == nil {
+		return true, nil
+	}
+
+	var c change
+	for _, key := range owned {
+		current, err := p.getRecordSet(ctx, zone, key)
+		if err != nil {
+			return false, err
+		}
+		if current != nil {
+			c.Deletions = append(c.Deletions, *current)
+		}
+	}
+	c.Deletions = append(c.Deletions, *owner)
+	return p.applyChange(ctx, zone, c)
+}
+
+func (p *Provider) EnsureRecord(ctx context.Context, record dnsprovider.Record) (bool, error) {
+	zone, err := p.getManagedZone(ctx)
+	if err != nil {
+		return false, err
+	}
+	owner, current, err := p.getOwnedRecordSet(ctx, zone, record)
+	if err != nil {
+		return false, err
+	}
+
+	desired := recordSet{Name: fqdn(record.Name), Type: record.Type, TTL: p.Config.RecordTTL}
+	for _, value := range record.Values {
+		if record.Type == "TXT" {
+			value = strconv.Quote(value)
+		}
+		desired.RRDatas = append(desired.RRDatas, value)
+	}
+
+	var c change
+	if current != nil && !current.equal(desired) {
+		c.Deletions = append(c.Deletions, *current)
+	}
+	if current == nil || !current.equal(desired) {
+		c.Additions = append(c.Additions, desired)
+	}
+	if owner == nil {
+		c.Additions = append(c.Additions, p.makeRecordOwner(record))
+	}
+	return p.applyChange(ctx, zone, c)
+}
+
+func (p *Provider) DeleteRecord(ctx context.Context, record dnsprovider.Record) (bool, error) {
+	zone, err := p.getManagedZone(ctx)
+	if err != nil {
+		return false, err
+	}
+	owner, current, err := p.getOwnedRecordSet(ctx, zone, record)
+	if err != nil {
+		return false, err
+	}
+	if owner == nil {
+		return true, nil
+	}
+
+	var c change
+	if current != nil {
+		c.Deletions = append(c.Deletions, *current)
+	}
+	c.Deletions = append(c.Deletions, *owner)
+	return p.applyChange(ctx, zone, c)
+}
+
+func (p *Provider) makeRecordOwner(record dnsprovider.Record) recordSet {
+	return recordSet{
+		Name:    fqdn(recordOwnerLabel + "." + record.Name),
+		Type:    "TXT",
+		TTL:     p.Config.RecordTTL,
+		RRDatas: []string{strconv.Quote(recordOwnerValuePrefix + record.Owner)},
+	}
+}
+
+// getOwnedRecordSet returns the TXT record owning the record and the current
+// record set, or nil if the record is not created. Record sets not owned by
+// the owner of record are rejected.
+func (p *Provider) getOwnedRecordSet(ctx context.Context, zone string, record dnsprovider.Record) (*recordSet, *recordSet, error) {
+	ownerKey := p.makeRecordOwner(record).key()
+	owner, err := p.getRecordSet(ctx, zone, ownerKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	if owner != nil && !owner.equal(p.makeRecordOwner(record)) {
+		return nil, nil, fmt.Errorf("TXT record %s exists and is not managed by the controller", owner.Name)
+	}
+	current, err := p.getRecordSet(ctx, zone, recordSetKey{name: fqdn(record.Name), recordType: record.Type})
+	if err != nil {
+		return nil, nil, err
+	}
+	if current != nil && owner == nil {
+		return nil, nil, fmt.Errorf("%s record %s exists and is not managed by the controller", current.Type, current.Name)
+	}
+	return owner, current, nil
+}
+
+func (p *Provider) makeRecordSets(domain *domainv1beta1.CustomDomain) []recordSet {
+	domainName := dnsname.DomainName(domain.Name)
+	lb := domain.Status.LoadBalancer
+	if lb == nil {
+		return nil
+	}
+
+	// Cloud DNS does not support alias records to external targets, so
+	// resolved A/AAAA records are published for alias targets.
+	var sets []recordSet
+	index := map[recordSetKey]int{}
+	for _, r := range lb.DNSRecords {
+		name := r.Name
+		if name == "@" || name == "" {
+			name = domainName
+		}
+		value := r.Value
+		switch r.Type {
+		case "A", "AAAA":
+			if ip := net.ParseIP(value); ip != nil {
+				value = ip.String()
+			}
+		case "CNAME":
+			value = fqdn(value)
+		case "TXT":
+			value = strconv.Quote(value)
+		}
+
+		key := recordSetKey{name: fqdn(name), recordType: r.Type}
+		i, ok := index[key]
+		if !ok {
+			i = len(sets)
+			index[key] = i
+			sets = append(sets, recordSet{Name: key.name, Type: r.Type, TTL: p.Config.RecordTTL})
+		}
+		sets[i].RRDatas = append(sets[i].RRDatas, value)
+	}
+	return sets
+}
+
+func ownerRecordName(domain *domainv1beta1.CustomDomain) string {
+	domainName := dnsname.DomainName(domain.Name)
+	if dnsname.IsWildcard(domainName) {
+		return fqdn(wildcardOwnerLabel + "." + dnsname.TrimWildcard(domainName))
+	}
+	return fqdn(ownerLabel + "." + domainName)
+}
+
+func (p *Provider) makeOwnerRecord(domain *domainv1beta1.CustomDomain, sets []recordSet) recordSet {
+	rrdatas := []string{strconv.Quote(ownerValuePrefix + domain.Name)}
+	for _, r := range sets {
+		rrdatas = append(rrdatas, strconv.Quote(r.Type+" "+r.Name))
+	}
+	return recordSet{
+		Name:    ownerRecordName(domain),
+		Type:    "TXT",
+		TTL:     p.Config.RecordTTL,
+		RRDatas: rrdatas,
+	}
+}
+
+// getOwnerRecord returns the TXT record listing records created for the
+// domain, or nil if no records are created.
+func (p *Provider) getOwnerRecord(ctx context.Context, zone string, domain *domainv1beta1.CustomDomain) (*recordSet, []recordSetKey, error) {
+	owner, err := p.getRecordSet(ctx, zone, recordSetKey{name: ownerRecordName(domain), recordType: "TXT"})
+	if err != nil || owner == nil {
+		return nil, nil, err
+	}
+
+	var owned []recordSetKey
+	isOwner := false
+	for _, rrdata := range owner.RRDatas {
+		value, err := strconv.Unquote(rrdata)
+		if err != nil {
+			value = rrdata
+		}
+		if value == ownerValuePrefix+domain.Name {
+			isOwner = true
+			continue
+		}
+		parts := strings.SplitN(value, " ", 2)
+		if len(parts) == 2 {
+			owned = append(owned, recordSetKey{name: fqdn(parts[1]), recordType: parts[0]})
+		}
+	}
+	if !isOwner {
+		return nil, nil, fmt.Errorf("TXT record %s exists and is not managed by the controller", owner.Name)
+	}
+	return owner, owned, nil
+}
+
+func (p *Provider) getManagedZone(ctx context.Context) (string, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.managedZone != "" {
+		return p.managedZone, nil
+	}
+
+	var resp struct {
+		ManagedZones []struct {
+			Name string `json:"name"`
+		} `json:"managedZones"`
+	}
+	query := url.Values{"dnsName": {fqdn(p.Config.Zone)}}
+	if err := p.do(ctx, http.MethodGet, "/managedZones", query, nil, &resp); err != nil {
+		return "", err
+	}
+	if len(resp.ManagedZones) == 0 {
+		return "", fmt.Errorf("Cloud DNS managed zone of %s is not found", p.Config.Zone)
+	}
+	p.managedZone = resp.ManagedZones[0].Name
+	return p.managedZone, nil
+}
+
+func (p *Provider) getRecordSet(ctx context.Context, zone string, key recordSetKey) (*recordSet, error) {
+	var resp struct {
+		RRSets []recordSet `json:"rrsets"`
+	}
+	query := url.Values{"name": {key.name}, "type": {key.recordType}}
+	if err := p.do(ctx, http.MethodGet, "/managedZones/"+url.PathEscape(zone)+"/rrsets", query, nil, &resp); err != nil {
+		return nil, err
+	}
+	for _, r := range resp.RRSets {
+		// Guard against filter not applied by API.
+		if r.key() == key {
+			return &r, nil
+		}
+	}
+	return nil, nil
+}
+
+// applyChange applies the change atomically, and returns whether the change
+// is applied to Cloud DNS name servers.
+func (p *Provider) applyChange(ctx context.Context, zone string, c change) (bool, error) {
+	if len(c.Additions) == 0 && len(c.Deletions) == 0 {
+		return true, nil
+	}
+	var result change
+	if err := p.do(ctx, http.MethodPost, "/managedZones/"+url.PathEscape(zone)+"/changes", nil, c, &result); err != nil {
+		return false, err
+	}
+	return result.Status == "done", nil
+}
+
+func (p *Provider) do(ctx context.Context, method string, path string, query url.Values, body interface{}, result interface{}) error {
+	token, err := p.Config.Token(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot get Google Cloud access token: %w", err)
+	}
+
+	u := strings.TrimSuffix(p.Config.BaseURL, "/") + "/projects/" + url.PathEscape(p.Config.Project) + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	var reqBody bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reqBody).Encode(body); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, &reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := p.Config.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("Cloud DNS API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		var errResp struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
+			return fmt.Errorf("Cloud DNS API request failed (status %d)", resp.StatusCode)
+		}
+		return fmt.Errorf("Cloud DNS API request failed (status %d): %s", resp.StatusCode, errResp.Error.Message)
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("cannot decode Cloud DNS API response: %w", err)
+	}
+	return nil
+}
+
+func fqdn(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, ".")) + "."
+}
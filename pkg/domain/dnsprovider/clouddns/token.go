@@ -0,0 +1,83 @@
+package clouddns
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// MetadataTokenURL is the endpoint of the metadata server issuing access
+// tokens of the Google service account of the pod. With GKE Workload
+// Identity, it is the service account bound to the Kubernetes service
+// account.
+const MetadataTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+
+// tokenRefreshMargin is the duration before expiry that access tokens are
+// refreshed.
+const tokenRefreshMargin = 5 * time.Minute
+
+// MetadataTokenSource returns access tokens issued by the metadata server,
+// cached until shortly before expiry.
+type MetadataTokenSource struct {
+	URL        string
+	HTTPClient *http.Client
+	Now        func() time.Time
+
+	lock   sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+func NewMetadataTokenSource() *MetadataTokenSource {
+	return &MetadataTokenSource{
+		URL:        MetadataTokenURL,
+		HTTPClient: http.DefaultClient,
+		Now:        time.Now,
+	}
+}
+
+// Token returns the cached access token, or a new token if the cached one
+// is about to expire.
+func (s *MetadataTokenSource) Token(ctx context.Context) (string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := s.Now()
+	if s.token != "" && now.Add(tokenRefreshMargin).Before(s.expiry) {
+		return s.token, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("metadata server request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("metadata server request failed (status %d): %s", resp.StatusCode, body)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("cannot decode metadata server response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return "", fmt.Errorf("metadata server returned no access token")
+	}
+	s.token = token.AccessToken
+	s.expiry = now.Add(time.Duration(token.ExpiresIn) * time.Second)
+	return s.token, nil
+}
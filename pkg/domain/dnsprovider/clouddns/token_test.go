@@ -0,0 +1,53 @@
+package clouddns
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMetadataTokenSourceRefresh(t *testing.T) {
+	issued := 0
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata-Flavor") != "Google" {
+			http.Error(rw, "missing Metadata-Flavor", http.StatusForbidden)
+			return
+		}
+		issued++
+		fmt.Fprintf(rw, `{"access_token":"token-%d","expires_in":3600,"token_type":"Bearer"}`, issued)
+	}))
+	defer server.Close()
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	source := &MetadataTokenSource{
+		URL:        server.URL,
+		HTTPClient: server.Client(),
+		Now:        func() time.Time { return now },
+	}
+
+	tests := []struct {
+		elapsed time.Duration
+		token   string
+	}{
+		{0, "token-1"},
+		{30 * time.Minute, "token-1"},
+		{54 * time.Minute, "token-1"},
+		// refreshed within margin of expiry
+		{56 * time.Minute, "token-2"},
+		{60 * time.Minute, "token-2"},
+	}
+	start := now
+	for _, tt := range tests {
+		now = start.Add(tt.elapsed)
+		token, err := source.Token(context.Background())
+		if err != nil {
+			t.Fatalf("after %s: %v", tt.elapsed, err)
+		}
+		if token != tt.token {
+			t.Errorf("after %s: expected %s, got %s", tt.elapsed, tt.token, token)
+		}
+	}
+}
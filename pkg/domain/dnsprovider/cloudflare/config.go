@@ -0,0 +1,24 @@
+package cloudflare
+
+import (
+	"context"
+	"net/http"
+)
+
+type Config struct {
+	// Token returns the API token. It is called for every request, so
+	// rotated tokens are picked up without restarting.
+	Token func(ctx context.Context) (string, error)
+	// Zone is the name of the Cloudflare zone of records.
+	Zone string
+	// RecordTTL is the TTL in seconds of records; zero means automatic TTL.
+	RecordTTL int64
+	// Proxied is whether traffic of A/AAAA/CNAME records is proxied by
+	// Cloudflare.
+	Proxied bool
+	// BaseURL is the base URL of Cloudflare API, defaults to DefaultBaseURL.
+	BaseURL string
+	// HTTPClient is the client of API requests, defaults to
+	// http.DefaultClient.
+	HTTPClient *http.Client
+}
@@ -0,0 +1,386 @@
+// Package cloudflare provides a DNS provider managing records of a zone
+// using Cloudflare API.
+package cloudflare
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/dnsprovider"
+	"github.com/skygeario/k8s-controller/pkg/util/dnsname"
+)
+
+const DefaultBaseURL = "https://api.cloudflare.com/client/v4"
+
+// commentPrefix prefixes comment of records created by the provider,
+// followed by the domain name. Records without the comment are never
+// modified or deleted.
+const commentPrefix = "domain.skygear.io/domain="
+
+// recordCommentPrefix prefixes comment of individual records created by the
+// provider, followed by the owner of record.
+const recordCommentPrefix = "domain.skygear.io/record="
+
+// autoTTL is the TTL value denoting automatic TTL.
+const autoTTL = 1
+
+type Provider struct {
+	Config Config
+
+	lock   sync.Mutex
+	zoneID string
+}
+
+func NewProvider(config Config) (*Provider, error) {
+	if config.Token == nil {
+		return nil, fmt.Errorf("Cloudflare API token is not configured")
+	}
+	if config.Zone == "" {
+		return nil, fmt.Errorf("Cloudflare zone is not configured")
+	}
+	if config.RecordTTL < 0 {
+		return nil, fmt.Errorf("record TTL must not be negative")
+	}
+	if config.BaseURL == "" {
+		config.BaseURL = DefaultBaseURL
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = http.DefaultClient
+	}
+	return &Provider{Config: config}, nil
+}
+
+var _ dnsprovider.Provider = &Provider{}
+var _ dnsprovider.RecordProvider = &Provider{}
+
+type dnsRecord struct {
+	ID      string `json:"id,omitempty"`
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Content string `json:"content"`
+	TTL     int64  `json:"ttl"`
+	Proxied *bool  `json:"proxied,omitempty"`
+	Comment string `json:"comment"`
+}
+
+type recordKey struct{ recordType, name, content string }
+
+func (r dnsRecord) key() recordKey {
+	return recordKey{
+		recordType: r.Type,
+		name:       strings.ToLower(strings.TrimSuffix(r.Name, ".")),
+		content:    strings.TrimSuffix(r.Content, "."),
+	}
+}
+
+func (p *Provider) EnsureRecords(ctx context.Context, domain *domainv1beta1.CustomDomain) (bool, error) {
+	// Only domains owned by a verified registration are published.
+	if domain.Spec.OwnerRef == nil {
+		return p.DeleteRecords(ctx, domain)
+	}
+
+	zoneID, err := p.getZoneID(ctx)
+	if err != nil {
+		return false, err
+	}
+	existing, err := p.listRecords(ctx, zoneID, commentPrefix+domain.Name)
+	if err != nil {
+		return false, err
+	}
+	return p.applyRecords(ctx, zoneID, existing, p.makeRecords(domain))
+}
+
+// applyRecords creates, updates and deletes existing records of the
+// provider to match the desired records.
+func (p *Provider) applyRecords(ctx context.Context, zoneID string, existing []dnsRecord, desired []dnsRecord) (bool, error) {
+	var err error
+	existingByKey := map[recordKey]dnsRecord{}
+	for _, r := range existing {
+		existingByKey[r.key()] = r
+	}
+	desiredKeys := map[recordKey]bool{}
+	for _, r := range desired {
+		key := r.key()
+		desiredKeys[key] = true
+		current, ok := existingByKey[key]
+		switch {
+		case !ok:
+			err = p.request(ctx, http.MethodPost, "/zones/"+zoneID+"/dns_records", nil, r, nil)
+		case current.TTL != r.TTL || proxied(current) != proxied(r):
+			err = p.request(ctx, http.MethodPut, "/zones/"+zoneID+"/dns_records/"+current.ID, nil, r, nil)
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+	for _, r := range existing {
+		if desiredKeys[r.key()] {
+			continue
+		}
+		if err := p.request(ctx, http.MethodDelete, "/zones/"+zoneID+"/dns_records/"+r.ID, nil, nil, nil); err != nil {
+			return false, err
+		}
+	}
+
+	// Changes are applied to Cloudflare name servers immediately.
+	return true, nil
+}
+
+func (p *Provider) DeleteRecords(ctx context.Context, domain *domainv1beta1.CustomDomain) (bool, error) {
+	zoneID, err := p.getZoneID(ctx)
+	if err != nil {
+		return false, err
+	}
+	existing, err := p.listRecords(ctx, zoneID, commentPrefix+domain.Name)
+	if err != nil {
+		return false, err
+	}
+	for _, r := range existing {
+		if err := p.request(ctx, http.MethodDelete, "/zones/"+zoneID+"/dns_records/"+r.ID, nil, nil, nil); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (p *Provider) EnsureRecord(ctx context.Context, record dnsprovider.Record) (bool, error) {
+	zoneID, err := p.getZoneID(ctx)
+	if err != nil {
+		return false, err
+	}
+	existing, err := p.listOwnedRecords(ctx, zoneID, record)
+	if err != nil {
+		return false, err
+	}
+
+	ttl := p.Config.RecordTTL
+	if ttl == 0 {
+		ttl = autoTTL
+	}
+	var desired []dnsRecord
+	for _, value := range record.Values {
+		desired = append(desired, dnsRecord{
+			Type:    record.Type,
+			Name:    record.Name,
+			Content: value,
+			TTL:     ttl,
+			Comment: recordCommentPrefix + record.Owner,
+		})
+	}
+	return p.applyRecords(ctx, zoneID, existing, desired)
+}
+
+func (p *Provider) DeleteRecord(ctx context.Context, record dnsprovider.Record) (bool, error) {
+	zoneID, err := p.getZoneID(ctx)
+	if err != nil {
+		return false, err
+	}
+	existing, err := p.listOwnedRecords(ctx, zoneID, record)
+	if err != nil {
+		return false, err
+	}
+	return p.applyRecords(ctx, zoneID, existing, nil)
+}
+
+// listOwnedRecords lists existing records with same name and type created
+// for the owner of record.
+func (p *Provider) listOwnedRecords(ctx context.Context, zoneID string, record dnsprovider.Record) ([]dnsRecord, error) {
+	existing, err := p.listRecords(ctx, zoneID, recordCommentPrefix+record.Owner)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.ToLower(strings.TrimSuffix(record.Name, "."))
+	var owned []dnsRecord
+	for _, r := range existing {
+		if key := r.key(); key.recordType == record.Type && key.name == name {
+			owned = append(owned, r)
+		}
+	}
+	return owned, nil
+}
+
+func (p *Provider) makeRecords(domain *domainv1beta1.CustomDomain) []dnsRecord {
+	domainName := dnsname.DomainName(domain.Name)
+	lb := domain.Status.LoadBalancer
+	if lb == nil {
+		return nil
+	}
+
+	ttl := p.Config.RecordTTL
+	if ttl == 0 {
+		ttl = autoTTL
+	}
+	makeRecord := func(recordType, name, content string) dnsRecord {
+		r := dnsRecord{
+			Type:    recordType,
+			Name:    name,
+			Content: content,
+			TTL:     ttl,
+			Comment: commentPrefix + domain.Name,
+		}
+		switch recordType {
+		case "A", "AAAA", "CNAME":
+			proxied := p.Config.Proxied
+			r.Proxied = &proxied
+		}
+		return r
+	}
+
+	var records []dnsRecord
+	if lb.AliasTarget != nil {
+		// Cloudflare flattens CNAME records at zone apex.
+		records = append(records, makeRecord("CNAME", domainName, *lb.AliasTarget))
+	}
+	for _, r := range lb.DNSRecords {
+		if lb.AliasTarget != nil && (r.Type == "A" || r.Type == "AAAA") {
+			continue
+		}
+		name := r.Name
+		if name == "@" || name == "" {
+			name = domainName
+		}
+		records = append(records, makeRecord(r.Type, name, r.Value))
+	}
+	return records
+}
+
+func proxied(r dnsRecord) bool {
+	return r.Proxied != nil && *r.Proxied
+}
+
+func (p *Provider) getZoneID(ctx context.Context) (string, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.zoneID != "" {
+		return p.zoneID, nil
+	}
+
+	var zones []struct {
+		ID string `json:"id"`
+	}
+	query := url.Values{"name": {p.Config.Zone}}
+	if _, err := p.requestList(ctx, "/zones", query, 1, &zones); err != nil {
+		return "", err
+	}
+	if len(zones) == 0 {
+		return "", fmt.Errorf("Cloudflare zone %s is not found", p.Config.Zone)
+	}
+	p.zoneID = zones[0].ID
+	return p.zoneID, nil
+}
+
+// listRecords lists records created by the provider with the comment.
+func (p *Provider) listRecords(ctx context.Context, zoneID string, comment string) ([]dnsRecord, error) {
+	var records []dnsRecord
+	for page := 1; ; page++ {
+		var result []dnsRecord
+		query := url.Values{"comment.exact": {comment}, "per_page": {"100"}}
+		totalPages, err := p.requestList(ctx, "/zones/"+zoneID+"/dns_records", query, page, &result)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range result {
+			// Guard against filter not applied by API.
+			if r.Comment == comment {
+				records = append(records, r)
+			}
+		}
+		if page >= totalPages {
+			break
+		}
+	}
+	return records, nil
+}
+
+type response struct {
+	Success bool `json:"success"`
+	Errors  []struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+	Result     json.RawMessage `json:"result"`
+	ResultInfo *struct {
+		TotalPages int `json:"total_pages"`
+	} `json:"result_info"`
+}
+
+func (p *Provider) requestList(ctx context.Context, path string, query url.Values, page int, result interface{}) (totalPages int, err error) {
+	query.Set("page", strconv.Itoa(page))
+	var resp response
+	if err := p.do(ctx, http.MethodGet, path, query, nil, &resp); err != nil {
+		return 0, err
+	}
+	if err := json.Unmarshal(resp.Result, result); err != nil {
+		return 0, fmt.Errorf("cannot decode Cloudflare API response: %w", err)
+	}
+	if resp.ResultInfo == nil {
+		return 1, nil
+	}
+	return resp.ResultInfo.TotalPages, nil
+}
+
+func (p *Provider) request(ctx context.Context, method string, path string, query url.Values, body interface{}, result interface{}) error {
+	var resp response
+	if err := p.do(ctx, method, path, query, body, &resp); err != nil {
+		return err
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(resp.Result, result); err != nil {
+		return fmt.Errorf("cannot decode Cloudflare API response: %w", err)
+	}
+	return nil
+}
+
+func (p *Provider) do(ctx context.Context, method string, path string, query url.Values, body interface{}, resp *response) error {
+	token, err := p.Config.Token(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot get Cloudflare API token: %w", err)
+	}
+
+	u := strings.TrimSuffix(p.Config.BaseURL, "/") + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	var reqBody bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reqBody).Encode(body); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, &reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	httpResp, err := p.Config.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("Cloudflare API request failed: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	if err := json.NewDecoder(httpResp.Body).Decode(resp); err != nil {
+		return fmt.Errorf("cannot decode Cloudflare API response (status %d): %w", httpResp.StatusCode, err)
+	}
+	if !resp.Success || httpResp.StatusCode >= 400 {
+		var msgs []string
+		for _, e := range resp.Errors {
+			msgs = append(msgs, fmt.Sprintf("%d: %s", e.Code, e.Message))
+		}
+		return fmt.Errorf("Cloudflare API request failed (status %d): %s", httpResp.StatusCode, strings.Join(msgs, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,307 @@
+package cloudflare
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/dnsprovider"
+)
+
+const testZoneID = "zone-id"
+
+// fakeAPI serves DNS records of a single zone over a subset of Cloudflare
+// API, returning one record per page to exercise pagination.
+type fakeAPI struct {
+	lock    sync.Mutex
+	records map[string]dnsRecord
+	nextID  int
+	changes int
+}
+
+func newFakeAPI(records ...dnsRecord) *fakeAPI {
+	api := &fakeAPI{records: map[string]dnsRecord{}}
+	for _, r := range records {
+		api.add(r)
+	}
+	return api
+}
+
+func (a *fakeAPI) add(r dnsRecord) string {
+	a.nextID++
+	r.ID = strconv.Itoa(a.nextID)
+	a.records[r.ID] = r
+	return r.ID
+}
+
+// list returns records sorted by name, type and content.
+func (a *fakeAPI) list() []dnsRecord {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	var records []dnsRecord
+	for _, r := range a.records {
+		records = append(records, r)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		ki, kj := records[i].key(), records[j].key()
+		if ki.name != kj.name {
+			return ki.name < kj.name
+		}
+		if ki.recordType != kj.recordType {
+			return ki.recordType < kj.recordType
+		}
+		return ki.content < kj.content
+	})
+	return records
+}
+
+func (a *fakeAPI) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer token" {
+		writeResponse(rw, http.StatusForbidden, nil, nil)
+		return
+	}
+
+	recordsPath := "/zones/" + testZoneID + "/dns_records"
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/zones":
+		var zones []interface{}
+		if r.URL.Query().Get("name") == "example.com" {
+			zones = append(zones, map[string]string{"id": testZoneID})
+		}
+		writeResponse(rw, http.StatusOK, zones, nil)
+
+	case r.Method == http.MethodGet && r.URL.Path == recordsPath:
+		comment := r.URL.Query().Get("comment.exact")
+		var matched []dnsRecord
+		for _, record := range a.records {
+			if record.Comment == comment {
+				matched = append(matched, record)
+			}
+		}
+		sort.Slice(matched, func(i, j int) bool { return matched[i].ID < matched[j].ID })
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		result := []dnsRecord{}
+		if page >= 1 && page <= len(matched) {
+			result = append(result, matched[page-1])
+		}
+		writeResponse(rw, http.StatusOK, result, map[string]int{"total_pages": len(matched)})
+
+	case r.Method == http.MethodPost && r.URL.Path == recordsPath:
+		var record dnsRecord
+		if err := json.NewDecoder(r.Body).Decode(&record); err != nil {
+			writeResponse(rw, http.StatusBadRequest, nil, nil)
+			return
+		}
+		record.ID = a.add(record)
+		a.changes++
+		writeResponse(rw, http.StatusOK, record, nil)
+
+	case strings.HasPrefix(r.URL.Path, recordsPath+"/"):
+		id := strings.TrimPrefix(r.URL.Path, recordsPath+"/")
+		if _, ok := a.records[id]; !ok {
+			writeResponse(rw, http.StatusNotFound, nil, nil)
+			return
+		}
+		switch r.Method {
+		case http.MethodPut:
+			var record dnsRecord
+			if err := json.NewDecoder(r.Body).Decode(&record); err != nil {
+				writeResponse(rw, http.StatusBadRequest, nil, nil)
+				return
+			}
+			record.ID = id
+			a.records[id] = record
+		case http.MethodDelete:
+			delete(a.records, id)
+		default:
+			writeResponse(rw, http.StatusMethodNotAllowed, nil, nil)
+			return
+		}
+		a.changes++
+		writeResponse(rw, http.StatusOK, map[string]string{"id": id}, nil)
+
+	default:
+		writeResponse(rw, http.StatusNotFound, nil, nil)
+	}
+}
+
+func writeResponse(rw http.ResponseWriter, status int, result interface{}, resultInfo interface{}) {
+	resp := map[string]interface{}{
+		"success": status < 400,
+		"errors":  []interface{}{},
+		"result":  result,
+	}
+	if status >= 400 {
+		resp["errors"] = []interface{}{map[string]interface{}{"code": status, "message": http.StatusText(status)}}
+	}
+	if resultInfo != nil {
+		resp["result_info"] = resultInfo
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	_ = json.NewEncoder(rw).Encode(resp)
+}
+
+func newTestProvider(t *testing.T, api *fakeAPI, config Config) *Provider {
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+
+	config.Token = func(ctx context.Context) (string, error) { return "token", nil }
+	config.Zone = "example.com"
+	config.BaseURL = server.URL
+	config.HTTPClient = server.Client()
+	p, err := NewProvider(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func recordStrings(records []dnsRecord) []string {
+	var s []string
+	for _, r := range records {
+		s = append(s, fmt.Sprintf("%s %s %s ttl=%d proxied=%v comment=%s", r.Name, r.Type, r.Content, r.TTL, proxied(r), r.Comment))
+	}
+	return s
+}
+
+func checkRecords(t *testing.T, api *fakeAPI, expected ...string) {
+	t.Helper()
+	actual := recordStrings(api.list())
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("records = \n%s\nexpected\n%s", strings.Join(actual, "\n"), strings.Join(expected, "\n"))
+	}
+}
+
+func TestEnsureRecords(t *testing.T) {
+	// Record not created by the provider
+	unmanaged := dnsRecord{Type: "TXT", Name: "www.example.com", Content: "unmanaged", TTL: 300}
+	api := newFakeAPI(unmanaged)
+	p := newTestProvider(t, api, Config{Proxied: true})
+	ctx := context.Background()
+
+	domain := &domainv1beta1.CustomDomain{
+		ObjectMeta: metav1.ObjectMeta{Name: "www.example.com"},
+		Spec: domainv1beta1.CustomDomainSpec{
+			OwnerRef: &corev1.ObjectReference{Namespace: "app", Name: "www.example.com"},
+		},
+		Status: domainv1beta1.CustomDomainStatus{
+			LoadBalancer: &domainv1beta1.CustomDomainStatusLoadBalancer{
+				DNSRecords: []domainv1beta1.CustomDomainDNSRecord{
+					{Name: "@", Type: "A", Value: "192.0.2.1"},
+					{Name: "@", Type: "A", Value: "192.0.2.2"},
+					{Name: "_acme.www.example.com", Type: "TXT", Value: "value"},
+				},
+			},
+		},
+	}
+	ok, err := p.EnsureRecords(ctx, domain)
+	if err != nil || !ok {
+		t.Fatalf("EnsureRecords = %v, %v", ok, err)
+	}
+	checkRecords(t, api,
+		"_acme.www.example.com TXT value ttl=1 proxied=false comment=domain.skygear.io/domain=www.example.com",
+		"www.example.com A 192.0.2.1 ttl=1 proxied=true comment=domain.skygear.io/domain=www.example.com",
+		"www.example.com A 192.0.2.2 ttl=1 proxied=true comment=domain.skygear.io/domain=www.example.com",
+		"www.example.com TXT unmanaged ttl=300 proxied=false comment=",
+	)
+
+	// Unchanged records are not updated
+	changes := api.changes
+	if _, err := p.EnsureRecords(ctx, domain); err != nil {
+		t.Fatal(err)
+	}
+	if api.changes != changes {
+		t.Errorf("unchanged records are updated")
+	}
+
+	// Load balancer with alias target
+	domain.Status.LoadBalancer.AliasTarget = pointer.StringPtr("lb.example.net")
+	domain.Status.LoadBalancer.DNSRecords = domain.Status.LoadBalancer.DNSRecords[:1]
+	if _, err := p.EnsureRecords(ctx, domain); err != nil {
+		t.Fatal(err)
+	}
+	checkRecords(t, api,
+		"www.example.com CNAME lb.example.net ttl=1 proxied=true comment=domain.skygear.io/domain=www.example.com",
+		"www.example.com TXT unmanaged ttl=300 proxied=false comment=",
+	)
+
+	// Records are deleted once ownership is revoked
+	domain.Spec.OwnerRef = nil
+	if _, err := p.EnsureRecords(ctx, domain); err != nil {
+		t.Fatal(err)
+	}
+	checkRecords(t, api, "www.example.com TXT unmanaged ttl=300 proxied=false comment=")
+}
+
+func TestEnsureRecord(t *testing.T) {
+	// Same record of another owner
+	other := dnsRecord{
+		Type:    "TXT",
+		Name:    "_acme-challenge.example.com",
+		Content: "other",
+		TTL:     60,
+		Comment: recordCommentPrefix + "other",
+	}
+	api := newFakeAPI(other)
+	p := newTestProvider(t, api, Config{RecordTTL: 60, Proxied: true})
+	ctx := context.Background()
+
+	record := dnsprovider.Record{
+		Name:   "_acme-challenge.example.com",
+		Type:   "TXT",
+		Values: []string{"token-1"},
+		Owner:  "acme/app/example.com",
+	}
+	if ok, err := p.EnsureRecord(ctx, record); err != nil || !ok {
+		t.Fatalf("EnsureRecord = %v, %v", ok, err)
+	}
+	checkRecords(t, api,
+		"_acme-challenge.example.com TXT other ttl=60 proxied=false comment=domain.skygear.io/record=other",
+		"_acme-challenge.example.com TXT token-1 ttl=60 proxied=false comment=domain.skygear.io/record=acme/app/example.com",
+	)
+
+	record.Values = []string{"token-2", "token-3"}
+	if _, err := p.EnsureRecord(ctx, record); err != nil {
+		t.Fatal(err)
+	}
+	checkRecords(t, api,
+		"_acme-challenge.example.com TXT other ttl=60 proxied=false comment=domain.skygear.io/record=other",
+		"_acme-challenge.example.com TXT token-2 ttl=60 proxied=false comment=domain.skygear.io/record=acme/app/example.com",
+		"_acme-challenge.example.com TXT token-3 ttl=60 proxied=false comment=domain.skygear.io/record=acme/app/example.com",
+	)
+
+	if ok, err := p.DeleteRecord(ctx, dnsprovider.Record{Name: record.Name, Type: record.Type, Owner: record.Owner}); err != nil || !ok {
+		t.Fatalf("DeleteRecord = %v, %v", ok, err)
+	}
+	checkRecords(t, api,
+		"_acme-challenge.example.com TXT other ttl=60 proxied=false comment=domain.skygear.io/record=other",
+	)
+}
+
+func TestZoneNotFound(t *testing.T) {
+	p := newTestProvider(t, newFakeAPI(), Config{})
+	p.Config.Zone = "example.org"
+
+	_, err := p.DeleteRecords(context.Background(), &domainv1beta1.CustomDomain{
+		ObjectMeta: metav1.ObjectMeta{Name: "example.org"},
+	})
+	if err == nil || err.Error() != "Cloudflare zone example.org is not found" {
+		t.Errorf("error = %v", err)
+	}
+}
@@ -0,0 +1,12 @@
+package dnsendpoint
+
+type Config struct {
+	// Namespace is the namespace of DNSEndpoint resources created.
+	Namespace string
+	// RecordTTL is the TTL in seconds of records; zero means using
+	// external-dns default.
+	RecordTTL int64
+	// Labels are additional labels of DNSEndpoint resources, e.g. to match
+	// label filter of external-dns.
+	Labels map[string]string
+}
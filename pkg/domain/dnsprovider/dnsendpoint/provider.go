@@ -0,0 +1,240 @@
+// Package dnsendpoint provides a DNS provider emitting DNSEndpoint resources,
+// to be consumed by CRD source of external-dns.
+package dnsendpoint
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/dnsprovider"
+	"github.com/skygeario/k8s-controller/pkg/util/dnsname"
+)
+
+var DNSEndpointGVK = schema.GroupVersionKind{
+	Group:   "externaldns.k8s.io",
+	Version: "v1alpha1",
+	Kind:    "DNSEndpoint",
+}
+
+// DomainLabel is the label identifying the domain of DNSEndpoint resources.
+const DomainLabel = "domain.skygear.io/domain"
+
+// RecordOwnerLabel is the label identifying the owner of individual records
+// of DNSEndpoint resources, hashed to be a valid label value.
+const RecordOwnerLabel = "domain.skygear.io/record-owner"
+
+type Provider struct {
+	KubeClient client.Client
+	Config     Config
+}
+
+func NewProvider(client client.Client, config Config) (*Provider, error) {
+	if config.Namespace == "" {
+		return nil, fmt.Errorf("DNSEndpoint namespace is not configured")
+	}
+	if config.RecordTTL < 0 {
+		return nil, fmt.Errorf("record TTL must not be negative")
+	}
+	return &Provider{
+		KubeClient: client,
+		Config:     config,
+	}, nil
+}
+
+var _ dnsprovider.Provider = &Provider{}
+var _ dnsprovider.RecordProvider = &Provider{}
+
+func (p *Provider) EnsureRecords(ctx context.Context, domain *domainv1beta1.CustomDomain) (bool, error) {
+	// Only domains owned by a verified registration are published.
+	if domain.Spec.OwnerRef == nil {
+		return p.DeleteRecords(ctx, domain)
+	}
+
+	return p.applyDNSEndpoint(ctx, p.makeDNSEndpoint(domain), DomainLabel)
+}
+
+// applyDNSEndpoint creates or updates the DNSEndpoint resource, if existing
+// resource has same value of the owner label.
+func (p *Provider) applyDNSEndpoint(ctx context.Context, endpoint *unstructured.Unstructured, ownerLabel string) (bool, error) {
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(DNSEndpointGVK)
+	err := p.KubeClient.Get(ctx, types.NamespacedName{Namespace: endpoint.GetNamespace(), Name: endpoint.GetName()}, existing)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return false, err
+	}
+
+	if apierrors.IsNotFound(err) {
+		if err := p.KubeClient.Create(ctx, endpoint); err != nil {
+			return false, err
+		}
+		return false, nil
+	}
+
+	if existing.GetLabels()[ownerLabel] != endpoint.GetLabels()[ownerLabel] {
+		return false, fmt.Errorf("DNSEndpoint %s/%s exists and is not managed by the controller", existing.GetNamespace(), existing.GetName())
+	}
+
+	if !reflect.DeepEqual(existing.Object["spec"], endpoint.Object["spec"]) ||
+		!reflect.DeepEqual(existing.GetLabels(), endpoint.GetLabels()) {
+		existing.Object["spec"] = endpoint.Object["spec"]
+		existing.SetLabels(endpoint.GetLabels())
+		if err := p.KubeClient.Update(ctx, existing); err != nil {
+			return false, err
+		}
+		return false, nil
+	}
+
+	return isObserved(existing), nil
+}
+
+func (p *Provider) DeleteRecords(ctx context.Context, domain *domainv1beta1.CustomDomain) (bool, error) {
+	return p.deleteDNSEndpoint(ctx, domain.Name, DomainLabel, domain.Name)
+}
+
+// deleteDNSEndpoint deletes the DNSEndpoint resource, if it has the value of
+// the owner label.
+func (p *Provider) deleteDNSEndpoint(ctx context.Context, name string, ownerLabel string, owner string) (bool, error) {
+	endpoint := &unstructured.Unstructured{}
+	endpoint.SetGroupVersionKind(DNSEndpointGVK)
+	err := p.KubeClient.Get(ctx, types.NamespacedName{Namespace: p.Config.Namespace, Name: name}, endpoint)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}
+
+	if endpoint.GetLabels()[ownerLabel] != owner {
+		return true, nil
+	}
+
+	if err := p.KubeClient.Delete(ctx, endpoint); err != nil && !apierrors.IsNotFound(err) {
+		return false, err
+	}
+	// Records are removed by external-dns asynchronously; deletion of
+	// resource is considered done.
+	return true, nil
+}
+
+func (p *Provider) EnsureRecord(ctx context.Context, record dnsprovider.Record) (bool, error) {
+	return p.applyDNSEndpoint(ctx, p.makeRecordDNSEndpoint(record), RecordOwnerLabel)
+}
+
+func (p *Provider) DeleteRecord(ctx context.Context, record dnsprovider.Record) (bool, error) {
+	return p.deleteDNSEndpoint(ctx, recordResourceName(record), RecordOwnerLabel, hashValue(record.Owner))
+}
+
+// recordResourceName returns name of DNSEndpoint resource of the record,
+// unique to its name and type.
+func recordResourceName(record dnsprovider.Record) string {
+	return "record-" + hashValue(record.Type+" "+recordName(record))
+}
+
+func recordName(record dnsprovider.Record) string {
+	return strings.ToLower(strings.TrimSuffix(record.Name, "."))
+}
+
+func hashValue(value string) string {
+	h := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(h[:16])
+}
+
+func (p *Provider) makeRecordDNSEndpoint(record dnsprovider.Record) *unstructured.Unstructured {
+	targets := []interface{}{}
+	for _, value := range record.Values {
+		targets = append(targets, value)
+	}
+	e := map[string]interface{}{
+		"dnsName":    recordName(record),
+		"recordType": record.Type,
+		"targets":    targets,
+	}
+	if p.Config.RecordTTL > 0 {
+		e["recordTTL"] = p.Config.RecordTTL
+	}
+
+	labels := map[string]string{}
+	for k, v := range p.Config.Labels {
+		labels[k] = v
+	}
+	labels[RecordOwnerLabel] = hashValue(record.Owner)
+
+	endpoint := &unstructured.Unstructured{}
+	endpoint.SetGroupVersionKind(DNSEndpointGVK)
+	endpoint.SetNamespace(p.Config.Namespace)
+	endpoint.SetName(recordResourceName(record))
+	endpoint.SetLabels(labels)
+	endpoint.Object["spec"] = map[string]interface{}{
+		"endpoints": []interface{}{e},
+	}
+	return endpoint
+}
+
+func (p *Provider) makeDNSEndpoint(domain *domainv1beta1.CustomDomain) *unstructured.Unstructured {
+	domainName := dnsname.DomainName(domain.Name)
+
+	type recordKey struct{ name, recordType string }
+	var keys []recordKey
+	targets := map[recordKey][]interface{}{}
+	if lb := domain.Status.LoadBalancer; lb != nil {
+		for _, r := range lb.DNSRecords {
+			name := r.Name
+			if name == "@" || name == "" {
+				name = domainName
+			}
+			key := recordKey{name: name, recordType: r.Type}
+			if _, ok := targets[key]; !ok {
+				keys = append(keys, key)
+			}
+			targets[key] = append(targets[key], r.Value)
+		}
+	}
+
+	endpoints := []interface{}{}
+	for _, key := range keys {
+		e := map[string]interface{}{
+			"dnsName":    key.name,
+			"recordType": key.recordType,
+			"targets":    targets[key],
+		}
+		if p.Config.RecordTTL > 0 {
+			e["recordTTL"] = p.Config.RecordTTL
+		}
+		endpoints = append(endpoints, e)
+	}
+
+	labels := map[string]string{}
+	for k, v := range p.Config.Labels {
+		labels[k] = v
+	}
+	labels[DomainLabel] = domain.Name
+
+	endpoint := &unstructured.Unstructured{}
+	endpoint.SetGroupVersionKind(DNSEndpointGVK)
+	endpoint.SetNamespace(p.Config.Namespace)
+	endpoint.SetName(domain.Name)
+	endpoint.SetLabels(labels)
+	endpoint.Object["spec"] = map[string]interface{}{
+		"endpoints": endpoints,
+	}
+	return endpoint
+}
+
+func isObserved(endpoint *unstructured.Unstructured) bool {
+	observed, found, err := unstructured.NestedInt64(endpoint.Object, "status", "observedGeneration")
+	if err != nil || !found {
+		return false
+	}
+	return observed >= endpoint.GetGeneration()
+}
@@ -0,0 +1,196 @@
+package dnsendpoint
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/dnsprovider"
+)
+
+func newTestProvider(t *testing.T, objs ...runtime.Object) *Provider {
+	p, err := NewProvider(fake.NewFakeClientWithScheme(runtime.NewScheme(), objs...), Config{
+		Namespace: "external-dns",
+		RecordTTL: 60,
+		Labels:    map[string]string{"dns": "public"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func getDNSEndpoint(t *testing.T, c client.Client, name string) *unstructured.Unstructured {
+	endpoint := &unstructured.Unstructured{}
+	endpoint.SetGroupVersionKind(DNSEndpointGVK)
+	if err := c.Get(context.Background(), types.NamespacedName{Namespace: "external-dns", Name: name}, endpoint); err != nil {
+		t.Fatal(err)
+	}
+	return endpoint
+}
+
+func exists(t *testing.T, c client.Client, name string) bool {
+	endpoint := &unstructured.Unstructured{}
+	endpoint.SetGroupVersionKind(DNSEndpointGVK)
+	err := c.Get(context.Background(), types.NamespacedName{Namespace: "external-dns", Name: name}, endpoint)
+	if apierrors.IsNotFound(err) {
+		return false
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	return true
+}
+
+func endpointsOf(t *testing.T, endpoint *unstructured.Unstructured) []interface{} {
+	endpoints, _, err := unstructured.NestedSlice(endpoint.Object, "spec", "endpoints")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return endpoints
+}
+
+func TestEnsureRecords(t *testing.T) {
+	p := newTestProvider(t)
+	ctx := context.Background()
+
+	domain := &domainv1beta1.CustomDomain{
+		ObjectMeta: metav1.ObjectMeta{Name: "www.example.com"},
+		Spec: domainv1beta1.CustomDomainSpec{
+			OwnerRef: &corev1.ObjectReference{Namespace: "app", Name: "www.example.com"},
+		},
+		Status: domainv1beta1.CustomDomainStatus{
+			LoadBalancer: &domainv1beta1.CustomDomainStatusLoadBalancer{
+				DNSRecords: []domainv1beta1.CustomDomainDNSRecord{
+					{Name: "@", Type: "A", Value: "192.0.2.1"},
+					{Name: "@", Type: "A", Value: "192.0.2.2"},
+				},
+			},
+		},
+	}
+	ok, err := p.EnsureRecords(ctx, domain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Error("records are ready before observed by external-dns")
+	}
+
+	endpoint := getDNSEndpoint(t, p.KubeClient, "www.example.com")
+	expectedLabels := map[string]string{"dns": "public", DomainLabel: "www.example.com"}
+	if !reflect.DeepEqual(endpoint.GetLabels(), expectedLabels) {
+		t.Errorf("labels = %v", endpoint.GetLabels())
+	}
+	expectedEndpoints := []interface{}{
+		map[string]interface{}{
+			"dnsName":    "www.example.com",
+			"recordType": "A",
+			"targets":    []interface{}{"192.0.2.1", "192.0.2.2"},
+			"recordTTL":  int64(60),
+		},
+	}
+	if endpoints := endpointsOf(t, endpoint); !reflect.DeepEqual(endpoints, expectedEndpoints) {
+		t.Errorf("endpoints = %#v", endpoints)
+	}
+
+	// Ready once observed by external-dns
+	endpoint.SetGeneration(1)
+	if err := unstructured.SetNestedField(endpoint.Object, int64(1), "status", "observedGeneration"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.KubeClient.Update(ctx, endpoint); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := p.EnsureRecords(ctx, domain); err != nil || !ok {
+		t.Errorf("EnsureRecords = %v, %v", ok, err)
+	}
+
+	// Deleted once ownership is revoked
+	domain.Spec.OwnerRef = nil
+	if ok, err := p.EnsureRecords(ctx, domain); err != nil || !ok {
+		t.Errorf("EnsureRecords = %v, %v", ok, err)
+	}
+	if exists(t, p.KubeClient, "www.example.com") {
+		t.Error("DNSEndpoint is not deleted")
+	}
+}
+
+func TestEnsureRecordsUnmanaged(t *testing.T) {
+	unmanaged := &unstructured.Unstructured{}
+	unmanaged.SetGroupVersionKind(DNSEndpointGVK)
+	unmanaged.SetNamespace("external-dns")
+	unmanaged.SetName("www.example.com")
+	p := newTestProvider(t, unmanaged)
+
+	domain := &domainv1beta1.CustomDomain{
+		ObjectMeta: metav1.ObjectMeta{Name: "www.example.com"},
+		Spec: domainv1beta1.CustomDomainSpec{
+			OwnerRef: &corev1.ObjectReference{Namespace: "app", Name: "www.example.com"},
+		},
+	}
+	if _, err := p.EnsureRecords(context.Background(), domain); err == nil {
+		t.Error("unmanaged DNSEndpoint is updated")
+	}
+	if ok, err := p.DeleteRecords(context.Background(), domain); err != nil || !ok {
+		t.Errorf("DeleteRecords = %v, %v", ok, err)
+	}
+	getDNSEndpoint(t, p.KubeClient, "www.example.com")
+}
+
+func TestEnsureRecord(t *testing.T) {
+	p := newTestProvider(t)
+	ctx := context.Background()
+
+	record := dnsprovider.Record{
+		Name:   "_acme-challenge.Example.com.",
+		Type:   "TXT",
+		Values: []string{"token"},
+		Owner:  "acme/app/example.com",
+	}
+	if _, err := p.EnsureRecord(ctx, record); err != nil {
+		t.Fatal(err)
+	}
+	name := recordResourceName(record)
+	endpoint := getDNSEndpoint(t, p.KubeClient, name)
+	if owner := endpoint.GetLabels()[RecordOwnerLabel]; owner != hashValue(record.Owner) {
+		t.Errorf("owner label = %q", owner)
+	}
+	expectedEndpoints := []interface{}{
+		map[string]interface{}{
+			"dnsName":    "_acme-challenge.example.com",
+			"recordType": "TXT",
+			"targets":    []interface{}{"token"},
+			"recordTTL":  int64(60),
+		},
+	}
+	if endpoints := endpointsOf(t, endpoint); !reflect.DeepEqual(endpoints, expectedEndpoints) {
+		t.Errorf("endpoints = %#v", endpoints)
+	}
+
+	// Same record of another owner is neither updated nor deleted
+	other := record
+	other.Owner = "acme/other/example.com"
+	if _, err := p.EnsureRecord(ctx, other); err == nil {
+		t.Error("record of another owner is updated")
+	}
+	if _, err := p.DeleteRecord(ctx, other); err != nil {
+		t.Fatal(err)
+	}
+	getDNSEndpoint(t, p.KubeClient, name)
+
+	if ok, err := p.DeleteRecord(ctx, record); err != nil || !ok {
+		t.Errorf("DeleteRecord = %v, %v", ok, err)
+	}
+	if exists(t, p.KubeClient, name) {
+		t.Error("DNSEndpoint is not deleted")
+	}
+}
@@ -0,0 +1,226 @@
+// Package fake provides an in-memory DNS provider for testing configurations
+// without a real DNS provider.
+package fake
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/dnsprovider"
+	"github.com/skygeario/k8s-controller/pkg/util/dnsname"
+)
+
+type zoneRecords struct {
+	records     []domainv1beta1.CustomDomainDNSRecord
+	aliasTarget string
+	changedAt   time.Time
+}
+
+type recordKey struct{ name, recordType string }
+
+type storedRecord struct {
+	record    dnsprovider.Record
+	changedAt time.Time
+}
+
+// Provider stores DNS records of domains in memory. Changes of records are
+// reported as not done until PropagationDelay has elapsed, simulating
+// propagation delay of real DNS providers.
+type Provider struct {
+	// PropagationDelay is the delay before changes of records are done.
+	PropagationDelay time.Duration
+	// SupportsAlias indicates alias records are created for domains with
+	// alias target, instead of A/AAAA records.
+	SupportsAlias bool
+	// Err is returned by all operations if not nil, simulating provider
+	// failures.
+	Err error
+	// Now returns current time, defaults to time.Now.
+	Now func() time.Time
+
+	lock           sync.Mutex
+	domains        map[string]*zoneRecords
+	deleted        map[string]time.Time
+	records        map[recordKey]*storedRecord
+	deletedRecords map[recordKey]time.Time
+}
+
+var _ dnsprovider.Provider = &Provider{}
+var _ dnsprovider.RecordProvider = &Provider{}
+
+func NewProvider() *Provider {
+	return &Provider{
+		Now:            time.Now,
+		domains:        map[string]*zoneRecords{},
+		deleted:        map[string]time.Time{},
+		records:        map[recordKey]*storedRecord{},
+		deletedRecords: map[recordKey]time.Time{},
+	}
+}
+
+func (p *Provider) EnsureRecords(ctx context.Context, domain *domainv1beta1.CustomDomain) (bool, error) {
+	if p.Err != nil {
+		return false, p.Err
+	}
+	name := dnsname.DomainName(domain.Name)
+	desired := &zoneRecords{}
+	if lb := domain.Status.LoadBalancer; lb != nil {
+		if p.SupportsAlias && lb.AliasTarget != nil {
+			desired.aliasTarget = *lb.AliasTarget
+			for _, r := range lb.DNSRecords {
+				if r.Type != "A" && r.Type != "AAAA" {
+					desired.records = append(desired.records, r)
+				}
+			}
+		} else {
+			desired.records = append(desired.records, lb.DNSRecords...)
+		}
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	now := p.Now()
+	delete(p.deleted, name)
+	current, ok := p.domains[name]
+	if !ok || !sameRecords(current, desired) {
+		desired.changedAt = now
+		p.domains[name] = desired
+		current = desired
+	}
+	return !now.Before(current.changedAt.Add(p.PropagationDelay)), nil
+}
+
+func (p *Provider) DeleteRecords(ctx context.Context, domain *domainv1beta1.CustomDomain) (bool, error) {
+	if p.Err != nil {
+		return false, p.Err
+	}
+	name := dnsname.DomainName(domain.Name)
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	now := p.Now()
+	if _, ok := p.domains[name]; ok {
+		delete(p.domains, name)
+		p.deleted[name] = now
+	}
+	deletedAt, ok := p.deleted[name]
+	if !ok {
+		return true, nil
+	}
+	return !now.Before(deletedAt.Add(p.PropagationDelay)), nil
+}
+
+func (p *Provider) EnsureRecord(ctx context.Context, record dnsprovider.Record) (bool, error) {
+	if p.Err != nil {
+		return false, p.Err
+	}
+	key := recordKey{name: record.Name, recordType: record.Type}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	now := p.Now()
+	current, ok := p.records[key]
+	if ok && current.record.Owner != record.Owner {
+		return false, fmt.Errorf("%s record %s exists and is not managed by %s", record.Type, record.Name, record.Owner)
+	}
+	delete(p.deletedRecords, key)
+	if !ok || !sameValues(current.record.Values, record.Values) {
+		record.Values = append([]string(nil), record.Values...)
+		current = &storedRecord{record: record, changedAt: now}
+		p.records[key] = current
+	}
+	return !now.Before(current.changedAt.Add(p.PropagationDelay)), nil
+}
+
+func (p *Provider) DeleteRecord(ctx context.Context, record dnsprovider.Record) (bool, error) {
+	if p.Err != nil {
+		return false, p.Err
+	}
+	key := recordKey{name: record.Name, recordType: record.Type}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	now := p.Now()
+	if current, ok := p.records[key]; ok && current.record.Owner == record.Owner {
+		delete(p.records, key)
+		p.deletedRecords[key] = now
+	}
+	deletedAt, ok := p.deletedRecords[key]
+	if !ok {
+		return true, nil
+	}
+	return !now.Before(deletedAt.Add(p.PropagationDelay)), nil
+}
+
+// Record returns the record stored in the provider, or nil if not found.
+func (p *Provider) Record(name string, recordType string) *dnsprovider.Record {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	current, ok := p.records[recordKey{name: name, recordType: recordType}]
+	if !ok {
+		return nil
+	}
+	record := current.record
+	record.Values = append([]string(nil), record.Values...)
+	return &record
+}
+
+// Records returns the DNS records of the domain stored in the provider.
+func (p *Provider) Records(domainName string) []domainv1beta1.CustomDomainDNSRecord {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	z, ok := p.domains[domainName]
+	if !ok {
+		return nil
+	}
+	return append([]domainv1beta1.CustomDomainDNSRecord(nil), z.records...)
+}
+
+// AliasTarget returns the alias target of the domain stored in the provider,
+// or empty string if none.
+func (p *Provider) AliasTarget(domainName string) string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	z, ok := p.domains[domainName]
+	if !ok {
+		return ""
+	}
+	return z.aliasTarget
+}
+
+// Reset removes all stored records.
+func (p *Provider) Reset() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.domains = map[string]*zoneRecords{}
+	p.deleted = map[string]time.Time{}
+	p.records = map[recordKey]*storedRecord{}
+	p.deletedRecords = map[recordKey]time.Time{}
+}
+
+func sameRecords(a, b *zoneRecords) bool {
+	if a.aliasTarget != b.aliasTarget || len(a.records) != len(b.records) {
+		return false
+	}
+	for i := range a.records {
+		if a.records[i] != b.records[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func sameValues(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,41 @@
+package dnsprovider
+
+import (
+	"context"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+)
+
+type Provider interface {
+	// EnsureRecords creates or updates DNS records of the domain to point to
+	// its load balancer. For apex domains with alias target, providers
+	// supporting alias records (e.g. Route 53 alias records, Cloudflare CNAME
+	// flattening) should create an alias record to the target instead of
+	// the resolved A/AAAA records.
+	EnsureRecords(ctx context.Context, domain *domainv1beta1.CustomDomain) (ok bool, err error)
+	// DeleteRecords deletes DNS records of the domain created by the provider.
+	DeleteRecords(ctx context.Context, domain *domainv1beta1.CustomDomain) (ok bool, err error)
+}
+
+// Record is a DNS record managed independently of domains, e.g. ACME DNS-01
+// challenge records.
+type Record struct {
+	// Name is the fully-qualified name of record.
+	Name string
+	// Type is the type of record.
+	Type string
+	// Values are the values of record; empty when deleting the record.
+	Values []string
+	// Owner identifies the creator of record. Existing records of other
+	// owners, or not created by the provider, are never modified or deleted.
+	Owner string
+}
+
+// RecordProvider manages individual DNS records.
+type RecordProvider interface {
+	// EnsureRecord creates or updates the record, and returns whether the
+	// change is propagated.
+	EnsureRecord(ctx context.Context, record Record) (ok bool, err error)
+	// DeleteRecord deletes the record with same name, type and owner.
+	DeleteRecord(ctx context.Context, record Record) (ok bool, err error)
+}
@@ -0,0 +1,197 @@
+// Package zoned provides a DNS provider delegating to providers configured by
+// DNSProviderConfig resources, according to zone of domains.
+package zoned
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/dnsprovider"
+	"github.com/skygeario/k8s-controller/pkg/domain/dnsprovider/clouddns"
+	"github.com/skygeario/k8s-controller/pkg/domain/dnsprovider/cloudflare"
+	"github.com/skygeario/k8s-controller/pkg/domain/dnsprovider/dnsendpoint"
+	"github.com/skygeario/k8s-controller/pkg/util/dnsname"
+)
+
+type cachedProvider struct {
+	resourceVersion string
+	provider        dnsprovider.Provider
+}
+
+type Provider struct {
+	KubeClient client.Client
+	// Default is the provider of domains not in zones of any
+	// DNSProviderConfig. Records of such domains are not managed if nil.
+	Default dnsprovider.Provider
+
+	lock      sync.Mutex
+	providers map[string]cachedProvider
+	// googleTokens is the ambient credentials of Google Cloud, shared by
+	// providers so that access tokens are cached across zones.
+	googleTokens *clouddns.MetadataTokenSource
+}
+
+func NewProvider(client client.Client, defaultProvider dnsprovider.Provider) *Provider {
+	return &Provider{
+		KubeClient: client,
+		Default:    defaultProvider,
+		providers:  map[string]cachedProvider{},
+	}
+}
+
+var _ dnsprovider.Provider = &Provider{}
+var _ dnsprovider.RecordProvider = &Provider{}
+
+func (p *Provider) EnsureRecords(ctx context.Context, domain *domainv1beta1.CustomDomain) (bool, error) {
+	provider, err := p.providerFor(ctx, dnsname.DomainName(domain.Name))
+	if err != nil {
+		return false, err
+	}
+	if provider == nil {
+		return true, nil
+	}
+	return provider.EnsureRecords(ctx, domain)
+}
+
+// DeleteRecords deletes DNS records using the provider currently managing
+// the zone of the domain.
+func (p *Provider) DeleteRecords(ctx context.Context, domain *domainv1beta1.CustomDomain) (bool, error) {
+	provider, err := p.providerFor(ctx, dnsname.DomainName(domain.Name))
+	if err != nil {
+		return false, err
+	}
+	if provider == nil {
+		return true, nil
+	}
+	return provider.DeleteRecords(ctx, domain)
+}
+
+func (p *Provider) EnsureRecord(ctx context.Context, record dnsprovider.Record) (bool, error) {
+	provider, err := p.recordProviderFor(ctx, record)
+	if err != nil {
+		return false, err
+	}
+	return provider.EnsureRecord(ctx, record)
+}
+
+func (p *Provider) DeleteRecord(ctx context.Context, record dnsprovider.Record) (bool, error) {
+	provider, err := p.recordProviderFor(ctx, record)
+	if err != nil {
+		return false, err
+	}
+	return provider.DeleteRecord(ctx, record)
+}
+
+func (p *Provider) recordProviderFor(ctx context.Context, record dnsprovider.Record) (dnsprovider.RecordProvider, error) {
+	name := strings.ToLower(strings.TrimSuffix(record.Name, "."))
+	provider, err := p.providerFor(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if provider == nil {
+		return nil, fmt.Errorf("no DNS provider is configured for %s", name)
+	}
+	recordProvider, ok := provider.(dnsprovider.RecordProvider)
+	if !ok {
+		return nil, fmt.Errorf("DNS provider of %s does not support managing records", name)
+	}
+	return recordProvider, nil
+}
+
+func (p *Provider) providerFor(ctx context.Context, name string) (dnsprovider.Provider, error) {
+	config, zone, err := domainv1beta1.FindDNSProviderConfig(ctx, p.KubeClient, name)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return p.Default, nil
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	// Providers are per zone, since some providers (e.g. Cloudflare) look up
+	// and cache zone.
+	key := config.Name + "/" + zone
+	if cached, ok := p.providers[key]; ok && cached.resourceVersion == config.ResourceVersion {
+		return cached.provider, nil
+	}
+	provider, err := p.newProvider(config, zone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DNSProviderConfig %s: %w", config.Name, err)
+	}
+	p.providers[key] = cachedProvider{resourceVersion: config.ResourceVersion, provider: provider}
+	return provider, nil
+}
+
+func (p *Provider) newProvider(config *domainv1beta1.DNSProviderConfig, zone string) (dnsprovider.Provider, error) {
+	configured := 0
+	for _, c := range []bool{
+		config.Spec.DNSEndpoint != nil,
+		config.Spec.Cloudflare != nil,
+		config.Spec.GoogleCloudDNS != nil,
+	} {
+		if c {
+			configured++
+		}
+	}
+	if configured > 1 {
+		return nil, fmt.Errorf("exactly one provider should be configured")
+	}
+
+	switch {
+	case config.Spec.DNSEndpoint != nil:
+		c := config.Spec.DNSEndpoint
+		return dnsendpoint.NewProvider(p.KubeClient, dnsendpoint.Config{
+			Namespace: c.Namespace,
+			RecordTTL: c.RecordTTL,
+			Labels:    c.Labels,
+		})
+
+	case config.Spec.Cloudflare != nil:
+		c := config.Spec.Cloudflare
+		return cloudflare.NewProvider(cloudflare.Config{
+			Token:     p.secretValue(c.APITokenSecretRef),
+			Zone:      zone,
+			RecordTTL: c.RecordTTL,
+			Proxied:   c.Proxied,
+		})
+
+	case config.Spec.GoogleCloudDNS != nil:
+		c := config.Spec.GoogleCloudDNS
+		if p.googleTokens == nil {
+			p.googleTokens = clouddns.NewMetadataTokenSource()
+		}
+		return clouddns.NewProvider(clouddns.Config{
+			Token:     p.googleTokens.Token,
+			Project:   c.Project,
+			Zone:      zone,
+			RecordTTL: c.RecordTTL,
+		})
+
+	default:
+		return nil, fmt.Errorf("no provider is configured")
+	}
+}
+
+func (p *Provider) secretValue(ref domainv1beta1.SecretKeyReference) func(ctx context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		var secret corev1.Secret
+		err := p.KubeClient.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, &secret)
+		if err != nil {
+			return "", err
+		}
+		value, ok := secret.Data[ref.Key]
+		if !ok {
+			return "", fmt.Errorf("key %s not found in secret %s/%s", ref.Key, ref.Namespace, ref.Name)
+		}
+		return string(value), nil
+	}
+}
@@ -0,0 +1,182 @@
+package zoned
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/dnsprovider"
+	"github.com/skygeario/k8s-controller/pkg/domain/dnsprovider/dnsendpoint"
+	fakedns "github.com/skygeario/k8s-controller/pkg/domain/dnsprovider/fake"
+)
+
+func newFakeClient(t *testing.T, objs ...runtime.Object) client.Client {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := domainv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	return fake.NewFakeClientWithScheme(scheme, objs...)
+}
+
+func dnsEndpointConfig(name string, namespace string, zones ...string) *domainv1beta1.DNSProviderConfig {
+	return &domainv1beta1.DNSProviderConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: domainv1beta1.DNSProviderConfigSpec{
+			Zones:       zones,
+			DNSEndpoint: &domainv1beta1.DNSEndpointProviderConfig{Namespace: namespace},
+		},
+	}
+}
+
+func ownedDomain(name string) *domainv1beta1.CustomDomain {
+	return &domainv1beta1.CustomDomain{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: domainv1beta1.CustomDomainSpec{
+			OwnerRef: &corev1.ObjectReference{Namespace: "app", Name: name},
+		},
+		Status: domainv1beta1.CustomDomainStatus{
+			LoadBalancer: &domainv1beta1.CustomDomainStatusLoadBalancer{
+				DNSRecords: []domainv1beta1.CustomDomainDNSRecord{{Name: "@", Type: "A", Value: "192.0.2.1"}},
+			},
+		},
+	}
+}
+
+func hasDNSEndpoint(t *testing.T, c client.Client, namespace string, name string) bool {
+	endpoint := &unstructured.Unstructured{}
+	endpoint.SetGroupVersionKind(dnsendpoint.DNSEndpointGVK)
+	err := c.Get(context.Background(), types.NamespacedName{Namespace: namespace, Name: name}, endpoint)
+	return err == nil
+}
+
+func TestEnsureRecords(t *testing.T) {
+	c := newFakeClient(t,
+		dnsEndpointConfig("parent", "parent-dns", "Example.com."),
+		dnsEndpointConfig("child", "child-dns", "sub.example.com"),
+	)
+	defaultProvider := fakedns.NewProvider()
+	p := NewProvider(c, defaultProvider)
+	ctx := context.Background()
+
+	tests := []struct {
+		domain    string
+		namespace string
+	}{
+		{"www.example.com", "parent-dns"},
+		{"www.sub.example.com", "child-dns"},
+		{"sub.example.com", "child-dns"},
+		{"www.notexample.com", ""},
+	}
+	for _, tt := range tests {
+		if _, err := p.EnsureRecords(ctx, ownedDomain(tt.domain)); err != nil {
+			t.Fatalf("%s: %v", tt.domain, err)
+		}
+		for _, namespace := range []string{"parent-dns", "child-dns"} {
+			if hasDNSEndpoint(t, c, namespace, tt.domain) != (namespace == tt.namespace) {
+				t.Errorf("%s: expected records managed by DNSEndpoint in %q", tt.domain, tt.namespace)
+			}
+		}
+		if records := defaultProvider.Records(tt.domain); (len(records) != 0) != (tt.namespace == "") {
+			t.Errorf("%s: records of default provider = %v", tt.domain, records)
+		}
+	}
+}
+
+func TestProviderConfigUpdated(t *testing.T) {
+	config := dnsEndpointConfig("config", "old-dns", "example.com")
+	c := newFakeClient(t, config)
+	p := NewProvider(c, nil)
+	ctx := context.Background()
+
+	if _, err := p.EnsureRecords(ctx, ownedDomain("www.example.com")); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Get(ctx, types.NamespacedName{Name: "config"}, config); err != nil {
+		t.Fatal(err)
+	}
+	config.Spec.DNSEndpoint.Namespace = "new-dns"
+	if err := c.Update(ctx, config); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.EnsureRecords(ctx, ownedDomain("www.example.com")); err != nil {
+		t.Fatal(err)
+	}
+	if !hasDNSEndpoint(t, c, "new-dns", "www.example.com") {
+		t.Error("provider of updated config is not used")
+	}
+}
+
+func TestInvalidProviderConfig(t *testing.T) {
+	config := dnsEndpointConfig("config", "dns", "example.com")
+	config.Spec.Cloudflare = &domainv1beta1.CloudflareProviderConfig{}
+	p := NewProvider(newFakeClient(t, config), nil)
+
+	_, err := p.EnsureRecords(context.Background(), ownedDomain("www.example.com"))
+	if err == nil || err.Error() != "invalid DNSProviderConfig config: exactly one provider should be configured" {
+		t.Errorf("error = %v", err)
+	}
+}
+
+// domainProvider manages records of domains only.
+type domainProvider struct{}
+
+func (domainProvider) EnsureRecords(ctx context.Context, domain *domainv1beta1.CustomDomain) (bool, error) {
+	return true, nil
+}
+
+func (domainProvider) DeleteRecords(ctx context.Context, domain *domainv1beta1.CustomDomain) (bool, error) {
+	return true, nil
+}
+
+func TestEnsureRecord(t *testing.T) {
+	c := newFakeClient(t, dnsEndpointConfig("config", "dns", "example.com"))
+	record := func(name string) dnsprovider.Record {
+		return dnsprovider.Record{Name: name, Type: "TXT", Values: []string{"token"}, Owner: "owner"}
+	}
+	ctx := context.Background()
+
+	defaultProvider := fakedns.NewProvider()
+	p := NewProvider(c, defaultProvider)
+	if _, err := p.EnsureRecord(ctx, record("_acme-challenge.example.com.")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.EnsureRecord(ctx, record("_acme-challenge.example.org")); err != nil {
+		t.Fatal(err)
+	}
+	if defaultProvider.Record("_acme-challenge.example.com", "TXT") != nil {
+		t.Error("record of configured zone is managed by default provider")
+	}
+	if defaultProvider.Record("_acme-challenge.example.org", "TXT") == nil {
+		t.Error("record of other zones is not managed by default provider")
+	}
+
+	tests := []struct {
+		name            string
+		defaultProvider dnsprovider.Provider
+		err             string
+	}{
+		{"no provider", nil, "no DNS provider is configured for _acme-challenge.example.org"},
+		{"unsupported provider", domainProvider{}, "DNS provider of _acme-challenge.example.org does not support managing records"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewProvider(c, tt.defaultProvider)
+			_, err := p.EnsureRecord(ctx, record("_acme-challenge.example.org"))
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("error = %v, expected %q", err, tt.err)
+			}
+		})
+	}
+}
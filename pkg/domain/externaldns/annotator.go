@@ -0,0 +1,70 @@
+package externaldns
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"text/template"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+)
+
+const (
+	HostnameAnnotation = "external-dns.alpha.kubernetes.io/hostname"
+	TTLAnnotation      = "external-dns.alpha.kubernetes.io/ttl"
+)
+
+const DefaultHostnameTemplate = "{{.DomainName}}"
+
+type templateData struct {
+	// DomainName is the ASCII domain name of the registration.
+	DomainName string
+	Namespace  string
+	Name       string
+}
+
+// Annotator computes external-dns annotations of resources generated for
+// registrations.
+type Annotator struct {
+	hostnameTemplate *template.Template
+	ttl              int
+}
+
+func NewAnnotator(config Config) (*Annotator, error) {
+	source := config.HostnameTemplate
+	if source == "" {
+		source = DefaultHostnameTemplate
+	}
+	tpl, err := template.New("hostname").Option("missingkey=error").Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hostname template: %w", err)
+	}
+	if config.TTL < 0 {
+		return nil, fmt.Errorf("TTL must not be negative")
+	}
+
+	return &Annotator{
+		hostnameTemplate: tpl,
+		ttl:              config.TTL,
+	}, nil
+}
+
+// Annotate sets external-dns annotations for the registration on the
+// annotations map.
+func (a *Annotator) Annotate(reg *domainv1beta1.CustomDomainRegistration, annotations map[string]string) error {
+	data := templateData{
+		DomainName: reg.ASCIIDomainName(),
+		Namespace:  reg.Namespace,
+		Name:       reg.Name,
+	}
+	buf := &bytes.Buffer{}
+	if err := a.hostnameTemplate.Execute(buf, data); err != nil {
+		return fmt.Errorf("cannot render hostname annotation: %w", err)
+	}
+	annotations[HostnameAnnotation] = buf.String()
+
+	if a.ttl > 0 {
+		annotations[TTLAnnotation] = strconv.Itoa(a.ttl)
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+package externaldns
+
+type Config struct {
+	// HostnameTemplate is the Go template of hostname annotation value.
+	// The template is executed with the registration; defaults to
+	// "{{.DomainName}}".
+	HostnameTemplate string
+	// TTL is the TTL in seconds of DNS records created by external-dns;
+	// zero means using external-dns default.
+	TTL int
+}
@@ -0,0 +1,113 @@
+// Package healthcheck probes HTTP(S) endpoints of custom domains.
+package healthcheck
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/skygeario/k8s-controller/pkg/metrics"
+	"github.com/skygeario/k8s-controller/pkg/util/dnsname"
+	"github.com/skygeario/k8s-controller/pkg/util/netguard"
+)
+
+// probeLabel is the label substituting wildcard when probing wildcard
+// domains.
+const probeLabel = "skygear-health-check"
+
+// maxResponseSize limits the size of probe response body read.
+const maxResponseSize = 4096
+
+// Result is the result of a health probe.
+type Result struct {
+	// StatusCode is the HTTP status code of response, zero if the request
+	// failed.
+	StatusCode int
+	// Latency is the duration until the response is received.
+	Latency time.Duration
+}
+
+// Prober probes endpoints of domains with GET requests. An endpoint is
+// healthy if it responds with a status code below 400; redirects are not
+// followed, as they are responses of the endpoint itself.
+type Prober struct {
+	Client *http.Client
+}
+
+// NewProber returns a prober sending requests with a copy of client.
+func NewProber(client *http.Client) *Prober {
+	c := *client
+	c.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return &Prober{Client: &c}
+}
+
+// MakeURL returns the URL probed for the domain.
+func MakeURL(domain string, scheme string, path string) string {
+	host := dnsname.DomainName(domain)
+	if dnsname.IsWildcard(host) {
+		host = probeLabel + "." + dnsname.TrimWildcard(host)
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return fmt.Sprintf("%s://%s%s", strings.ToLower(scheme), host, path)
+}
+
+// Probe requests the URL and reports the result. The returned error
+// describes why the endpoint is unhealthy; errors of the request are
+// summarized, so that details of the network, e.g. addresses connected, are
+// not reported to tenants.
+func (p *Prober) Probe(ctx context.Context, url string) (*Result, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot request endpoint: %w", err)
+	}
+
+	start := time.Now()
+	resp, err := p.Client.Do(req)
+	result := &Result{Latency: time.Since(start)}
+	if err != nil {
+		metrics.EndpointProbeDuration.WithLabelValues("false").Observe(result.Latency.Seconds())
+		return result, describeError(err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, maxResponseSize))
+
+	result.StatusCode = resp.StatusCode
+	if resp.StatusCode >= http.StatusBadRequest {
+		metrics.EndpointProbeDuration.WithLabelValues("false").Observe(result.Latency.Seconds())
+		return result, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	metrics.EndpointProbeDuration.WithLabelValues("true").Observe(result.Latency.Seconds())
+	return result, nil
+}
+
+// describeError summarizes the error of probe request.
+func describeError(err error) error {
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	var certErr x509.CertificateInvalidError
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	switch {
+	case errors.Is(err, netguard.ErrBlockedAddress):
+		return errors.New("endpoint address is not allowed")
+	case errors.As(err, &dnsErr):
+		return errors.New("cannot resolve endpoint host")
+	case errors.As(err, &certErr), errors.As(err, &unknownAuthorityErr), errors.As(err, &hostnameErr):
+		return errors.New("invalid TLS certificate")
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return errors.New("request timed out")
+	default:
+		return errors.New("request failed")
+	}
+}
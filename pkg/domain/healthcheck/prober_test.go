@@ -0,0 +1,87 @@
+package healthcheck
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/skygeario/k8s-controller/pkg/util/netguard"
+)
+
+func TestProbe(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/healthy":
+			rw.WriteHeader(http.StatusOK)
+		case "/redirect":
+			http.Redirect(rw, r, "/unhealthy", http.StatusFound)
+		default:
+			rw.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+	prober := NewProber(server.Client())
+
+	tests := []struct {
+		path       string
+		statusCode int
+		err        string
+	}{
+		{"/healthy", http.StatusOK, ""},
+		{"/redirect", http.StatusFound, ""},
+		{"/unhealthy", http.StatusInternalServerError, "unexpected status code 500"},
+	}
+	for _, tt := range tests {
+		result, err := prober.Probe(context.Background(), server.URL+tt.path)
+		if result == nil || result.StatusCode != tt.statusCode {
+			t.Errorf("%s: result = %#v, expected status code %d", tt.path, result, tt.statusCode)
+		}
+		if tt.err == "" && err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.path, err)
+		} else if tt.err != "" && (err == nil || err.Error() != tt.err) {
+			t.Errorf("%s: error = %v, expected %q", tt.path, err, tt.err)
+		}
+	}
+}
+
+func TestProbeErrorSummarized(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	// Address of closed listener refuses connections
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedAddr := l.Addr().String()
+	l.Close()
+
+	guard, err := netguard.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		prober *Prober
+		url    string
+		err    string
+	}{
+		{"blocked address", NewProber(guard.HTTPClient(nil)), server.URL, "endpoint address is not allowed"},
+		{"connection refused", NewProber(http.DefaultClient), "http://" + closedAddr, "request failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.prober.Probe(context.Background(), tt.url)
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("error = %v, expected %q", err, tt.err)
+			}
+			if err != nil && strings.Contains(err.Error(), "127.0.0.1") {
+				t.Errorf("error reveals address: %v", err)
+			}
+		})
+	}
+}
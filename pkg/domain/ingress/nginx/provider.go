@@ -1,6 +1,8 @@
 package nginx
 
 import (
+	"strconv"
+
 	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
 	networkingv1beta1 "k8s.io/api/networking/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -8,7 +10,9 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 	ctrl "sigs.k8s.io/controller-runtime"
 
+	"github.com/skygeario/k8s-controller/pkg/domain/externaldns"
 	"github.com/skygeario/k8s-controller/pkg/domain/ingress"
+	"github.com/skygeario/k8s-controller/pkg/domain/routing"
 )
 
 var scheme = runtime.NewScheme()
@@ -18,6 +22,8 @@ func init() {
 }
 
 type Provider struct {
+	// ExternalDNS annotates generated ingresses for external-dns, if set.
+	ExternalDNS *externaldns.Annotator
 }
 
 func NewProvider() (*Provider, error) {
@@ -29,7 +35,7 @@ var _ ingress.Provider = &Provider{}
 func (p *Provider) MakeIngress(reg *domainv1beta1.CustomDomainRegistration) (*networkingv1beta1.Ingress, error) {
 	ingress := networkingv1beta1.Ingress{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      reg.Spec.DomainName,
+			Name:      reg.Name,
 			Namespace: reg.Namespace,
 			Annotations: map[string]string{
 				"kubernetes.io/ingress.class": "nginx",
@@ -38,7 +44,7 @@ func (p *Provider) MakeIngress(reg *domainv1beta1.CustomDomainRegistration) (*ne
 		Spec: networkingv1beta1.IngressSpec{
 			Rules: []networkingv1beta1.IngressRule{
 				networkingv1beta1.IngressRule{
-					Host: reg.Spec.DomainName,
+					Host: reg.ASCIIDomainName(),
 					IngressRuleValue: networkingv1beta1.IngressRuleValue{
 						HTTP: &networkingv1beta1.HTTPIngressRuleValue{
 							Paths: []networkingv1beta1.HTTPIngressPath{
@@ -56,7 +62,7 @@ func (p *Provider) MakeIngress(reg *domainv1beta1.CustomDomainRegistration) (*ne
 			},
 			TLS: []networkingv1beta1.IngressTLS{
 				networkingv1beta1.IngressTLS{
-					Hosts:      []string{reg.Spec.DomainName},
+					Hosts:      []string{reg.ASCIIDomainName()},
 					SecretName: "",
 				},
 			},
@@ -67,15 +73,32 @@ func (p *Provider) MakeIngress(reg *domainv1beta1.CustomDomainRegistration) (*ne
 		return nil, err
 	}
 
-	if reg.Spec.DomainConfig.RedirectToURL != nil {
-		url := *reg.Spec.DomainConfig.RedirectToURL
+	redirect, err := routing.MakeRedirect(reg)
+	if err != nil {
+		return nil, err
+	}
+	if redirect != nil {
+		url := redirect.URL.String()
+		if redirect.PreservePath {
+			url += "$request_uri"
+		}
+		code := 307
+		if redirect.StatusCode != 0 {
+			code = redirect.StatusCode
+		}
 		ingress.Annotations["nginx.ingress.kubernetes.io/permanent-redirect"] = url
-		ingress.Annotations["nginx.ingress.kubernetes.io/permanent-redirect-code"] = "307"
+		ingress.Annotations["nginx.ingress.kubernetes.io/permanent-redirect-code"] = strconv.Itoa(code)
 	}
 
 	if reg.Status.CertSecretName != nil {
 		ingress.Spec.TLS[0].SecretName = *reg.Status.CertSecretName
 	}
 
+	if p.ExternalDNS != nil {
+		if err := p.ExternalDNS.Annotate(reg, ingress.Annotations); err != nil {
+			return nil, err
+		}
+	}
+
 	return &ingress, nil
 }
@@ -0,0 +1,186 @@
+package dedicatedip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer"
+	"github.com/skygeario/k8s-controller/pkg/util/dnsname"
+)
+
+// ErrPoolExhausted is returned when no addresses of IP pools are available.
+var ErrPoolExhausted = errors.New("no available addresses in IP pools")
+
+// Provider provides DNS records of dedicated IP addresses allocated from
+// IPPool resources. Allocations are recorded in status of IPPool, and the
+// allocated addresses are listed in annotation of the load balancer Service
+// of the pool, so that the cloud load balancer serves them.
+type Provider struct {
+	KubeClient client.Client
+}
+
+func NewProvider(client client.Client) *Provider {
+	return &Provider{KubeClient: client}
+}
+
+var _ loadbalancer.Provider = &Provider{}
+
+func (p *Provider) Provision(ctx context.Context, domain *domainv1beta1.CustomDomain) (*loadbalancer.ProvisionResult, error) {
+	domainName := dnsname.DomainName(domain.Name)
+	rootDomain, err := publicsuffix.EffectiveTLDPlusOne(dnsname.TrimWildcard(domainName))
+	if err != nil {
+		return nil, err
+	}
+
+	pools, err := p.listPools(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pool, address := findAllocation(pools, domainName)
+	if pool == nil {
+		pool, address, err = p.allocate(ctx, pools, domainName)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := p.annotateService(ctx, pool); err != nil {
+		return nil, err
+	}
+
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return nil, fmt.Errorf("IP address '%s' of IP pool %s is not valid", address, pool.Name)
+	}
+	recordType := "A"
+	if ip.To4() == nil {
+		recordType = "AAAA"
+	}
+	name := domainName
+	if name == rootDomain {
+		name = "@"
+	}
+	return &loadbalancer.ProvisionResult{DNSRecords: []loadbalancer.DNSRecord{
+		{Name: name, Type: recordType, Value: ip.String()},
+	}}, nil
+}
+
+func (p *Provider) Release(ctx context.Context, domain *domainv1beta1.CustomDomain) (bool, error) {
+	pools, err := p.listPools(ctx)
+	if err != nil {
+		return false, err
+	}
+	pool, _ := findAllocation(pools, dnsname.DomainName(domain.Name))
+	if pool == nil {
+		return true, nil
+	}
+
+	var allocations []domainv1beta1.IPPoolAllocation
+	for _, a := range pool.Status.Allocations {
+		if a.Domain != dnsname.DomainName(domain.Name) {
+			allocations = append(allocations, a)
+		}
+	}
+	pool.Status.Allocations = allocations
+	if err := p.KubeClient.Status().Update(ctx, pool); err != nil {
+		return false, err
+	}
+	if err := p.annotateService(ctx, pool); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// listPools returns IP pools ordered by name.
+func (p *Provider) listPools(ctx context.Context) ([]domainv1beta1.IPPool, error) {
+	var list domainv1beta1.IPPoolList
+	if err := p.KubeClient.List(ctx, &list); err != nil {
+		return nil, err
+	}
+	pools := list.Items
+	sort.Slice(pools, func(i, j int) bool { return pools[i].Name < pools[j].Name })
+	return pools, nil
+}
+
+// allocate allocates the first free address of the pools to the domain. The
+// allocation is rejected with a conflict if the pool is updated
+// concurrently.
+func (p *Provider) allocate(ctx context.Context, pools []domainv1beta1.IPPool, domainName string) (*domainv1beta1.IPPool, string, error) {
+	for i := range pools {
+		pool := &pools[i]
+		allocated := map[string]bool{}
+		for _, a := range pool.Status.Allocations {
+			allocated[a.Address] = true
+		}
+		for _, address := range pool.Spec.Addresses {
+			if allocated[address] {
+				continue
+			}
+			pool.Status.Allocations = append(pool.Status.Allocations, domainv1beta1.IPPoolAllocation{
+				Address: address,
+				Domain:  domainName,
+			})
+			if err := p.KubeClient.Status().Update(ctx, pool); err != nil {
+				return nil, "", err
+			}
+			return pool, address, nil
+		}
+	}
+	return nil, "", ErrPoolExhausted
+}
+
+// annotateService lists the allocated addresses of the pool in annotation of
+// its load balancer Service.
+func (p *Provider) annotateService(ctx context.Context, pool *domainv1beta1.IPPool) error {
+	allocated := map[string]bool{}
+	for _, a := range pool.Status.Allocations {
+		allocated[a.Address] = true
+	}
+	var addresses []string
+	for _, address := range pool.Spec.Addresses {
+		if allocated[address] {
+			addresses = append(addresses, address)
+		}
+	}
+	value := strings.Join(addresses, ",")
+
+	var service corev1.Service
+	name := types.NamespacedName{Namespace: pool.Spec.Service.Namespace, Name: pool.Spec.Service.Name}
+	err := p.KubeClient.Get(ctx, name, &service)
+	if apierrors.IsNotFound(err) {
+		return fmt.Errorf("load balancer Service %s of IP pool %s not found", name, pool.Name)
+	} else if err != nil {
+		return err
+	}
+	if service.Annotations[pool.Spec.Annotation] == value {
+		return nil
+	}
+
+	patch := client.MergeFrom(service.DeepCopy())
+	if service.Annotations == nil {
+		service.Annotations = map[string]string{}
+	}
+	service.Annotations[pool.Spec.Annotation] = value
+	return p.KubeClient.Patch(ctx, &service, patch)
+}
+
+func findAllocation(pools []domainv1beta1.IPPool, domainName string) (*domainv1beta1.IPPool, string) {
+	for i, pool := range pools {
+		for _, a := range pool.Status.Allocations {
+			if a.Domain == domainName {
+				return &pools[i], a.Address
+			}
+		}
+	}
+	return nil, ""
+}
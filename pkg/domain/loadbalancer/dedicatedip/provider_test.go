@@ -0,0 +1,174 @@
+package dedicatedip
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer"
+)
+
+const testAnnotation = "metallb.universe.tf/loadBalancerIPs"
+
+func newPool(name string, service string, addresses ...string) *domainv1beta1.IPPool {
+	return &domainv1beta1.IPPool{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: domainv1beta1.IPPoolSpec{
+			Addresses:  addresses,
+			Service:    domainv1beta1.LoadBalancerEndpointReference{Namespace: "ingress", Name: service},
+			Annotation: testAnnotation,
+		},
+	}
+}
+
+func newService(name string) *corev1.Service {
+	return &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "ingress", Name: name}}
+}
+
+func newDomain(name string) *domainv1beta1.CustomDomain {
+	return &domainv1beta1.CustomDomain{ObjectMeta: metav1.ObjectMeta{Name: name}}
+}
+
+func newTestProvider(t *testing.T, objs ...runtime.Object) *Provider {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := domainv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	return NewProvider(fake.NewFakeClientWithScheme(scheme, objs...))
+}
+
+func annotationOf(t *testing.T, p *Provider, service string) string {
+	var svc corev1.Service
+	if err := p.KubeClient.Get(context.Background(), types.NamespacedName{Namespace: "ingress", Name: service}, &svc); err != nil {
+		t.Fatal(err)
+	}
+	return svc.Annotations[testAnnotation]
+}
+
+func allocationsOf(t *testing.T, p *Provider, pool string) []domainv1beta1.IPPoolAllocation {
+	var ipPool domainv1beta1.IPPool
+	if err := p.KubeClient.Get(context.Background(), types.NamespacedName{Name: pool}, &ipPool); err != nil {
+		t.Fatal(err)
+	}
+	return ipPool.Status.Allocations
+}
+
+func TestProvision(t *testing.T) {
+	p := newTestProvider(t,
+		// Pools are allocated in order of name
+		newPool("b-pool", "lb-b", "2001:db8::1"),
+		newPool("a-pool", "lb-a", "192.0.2.1", "192.0.2.2"),
+		newService("lb-a"),
+		newService("lb-b"),
+	)
+	ctx := context.Background()
+
+	tests := []struct {
+		domain string
+		record loadbalancer.DNSRecord
+	}{
+		{"example.com", loadbalancer.DNSRecord{Name: "@", Type: "A", Value: "192.0.2.1"}},
+		{"www.example.com", loadbalancer.DNSRecord{Name: "www.example.com", Type: "A", Value: "192.0.2.2"}},
+		{"example.org", loadbalancer.DNSRecord{Name: "@", Type: "AAAA", Value: "2001:db8::1"}},
+		// Existing allocation is reused
+		{"example.com", loadbalancer.DNSRecord{Name: "@", Type: "A", Value: "192.0.2.1"}},
+	}
+	for _, tt := range tests {
+		result, err := p.Provision(ctx, newDomain(tt.domain))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.domain, err)
+		}
+		if expected := []loadbalancer.DNSRecord{tt.record}; !reflect.DeepEqual(result.DNSRecords, expected) {
+			t.Errorf("%s: records = %v, expected %v", tt.domain, result.DNSRecords, expected)
+		}
+	}
+
+	expected := []domainv1beta1.IPPoolAllocation{
+		{Address: "192.0.2.1", Domain: "example.com"},
+		{Address: "192.0.2.2", Domain: "www.example.com"},
+	}
+	if allocations := allocationsOf(t, p, "a-pool"); !reflect.DeepEqual(allocations, expected) {
+		t.Errorf("allocations = %v", allocations)
+	}
+	if annotation := annotationOf(t, p, "lb-a"); annotation != "192.0.2.1,192.0.2.2" {
+		t.Errorf("annotation = %q", annotation)
+	}
+	if annotation := annotationOf(t, p, "lb-b"); annotation != "2001:db8::1" {
+		t.Errorf("annotation = %q", annotation)
+	}
+
+	if _, err := p.Provision(ctx, newDomain("example.net")); err != ErrPoolExhausted {
+		t.Errorf("error = %v, expected %v", err, ErrPoolExhausted)
+	}
+}
+
+func TestRelease(t *testing.T) {
+	pool := newPool("pool", "lb", "192.0.2.1", "192.0.2.2")
+	pool.Status.Allocations = []domainv1beta1.IPPoolAllocation{
+		{Address: "192.0.2.1", Domain: "example.com"},
+		{Address: "192.0.2.2", Domain: "example.org"},
+	}
+	service := newService("lb")
+	service.Annotations = map[string]string{testAnnotation: "192.0.2.1,192.0.2.2"}
+	p := newTestProvider(t, pool, service)
+	ctx := context.Background()
+
+	if ok, err := p.Release(ctx, newDomain("example.com")); err != nil || !ok {
+		t.Fatalf("Release = %v, %v", ok, err)
+	}
+	expected := []domainv1beta1.IPPoolAllocation{{Address: "192.0.2.2", Domain: "example.org"}}
+	if allocations := allocationsOf(t, p, "pool"); !reflect.DeepEqual(allocations, expected) {
+		t.Errorf("allocations = %v", allocations)
+	}
+	if annotation := annotationOf(t, p, "lb"); annotation != "192.0.2.2" {
+		t.Errorf("annotation = %q", annotation)
+	}
+
+	// Released address is allocated again
+	result, err := p.Provision(ctx, newDomain("example.net"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value := result.DNSRecords[0].Value; value != "192.0.2.1" {
+		t.Errorf("allocated address = %s", value)
+	}
+
+	// Domains without allocation are released
+	if ok, err := p.Release(ctx, newDomain("example.com")); err != nil || !ok {
+		t.Errorf("Release = %v, %v", ok, err)
+	}
+}
+
+func TestProvisionServiceNotFound(t *testing.T) {
+	p := newTestProvider(t, newPool("pool", "lb", "192.0.2.1"))
+
+	_, err := p.Provision(context.Background(), newDomain("example.com"))
+	if err == nil || err.Error() != "load balancer Service ingress/lb of IP pool pool not found" {
+		t.Errorf("error = %v", err)
+	}
+	// Allocation is kept, and the Service is annotated once created
+	expected := []domainv1beta1.IPPoolAllocation{{Address: "192.0.2.1", Domain: "example.com"}}
+	if allocations := allocationsOf(t, p, "pool"); !reflect.DeepEqual(allocations, expected) {
+		t.Errorf("allocations = %v", allocations)
+	}
+	if err := p.KubeClient.Create(context.Background(), newService("lb")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Provision(context.Background(), newDomain("example.com")); err != nil {
+		t.Fatal(err)
+	}
+	if annotation := annotationOf(t, p, "lb"); annotation != "192.0.2.1" {
+		t.Errorf("annotation = %q", annotation)
+	}
+}
@@ -0,0 +1,11 @@
+package kubernetes
+
+type Config struct {
+	// Namespace is the namespace of load balancer Service or Ingress.
+	Namespace string
+	// ServiceName is the name of load balancer Service.
+	ServiceName string
+	// IngressName is the name of load balancer Ingress, used when
+	// ServiceName is empty.
+	IngressName string
+}
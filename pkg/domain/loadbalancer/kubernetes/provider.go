@@ -0,0 +1,285 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/go-logr/logr"
+	"golang.org/x/net/publicsuffix"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer"
+	"github.com/skygeario/k8s-controller/pkg/util/dnsname"
+)
+
+const (
+	KindService = "Service"
+	KindIngress = "Ingress"
+)
+
+// ClassLabel is the label of load balancer Services in the configured
+// namespace, whose value is the load balancer class served by the Service.
+const ClassLabel = "domain.skygear.io/load-balancer-class"
+
+// endpointSourceIndex indexes LoadBalancerEndpoints by referenced Service or
+// Ingress, in form of kind/namespace/name.
+const endpointSourceIndex = "loadBalancerSource"
+
+// Provider provides DNS records from load balancer status of a Service or an
+// Ingress. Domains requesting a load balancer class use the load balancer of
+// the class instead of the configured one.
+type Provider struct {
+	KubeClient client.Client
+	Config     Config
+	// LookupIPAddr resolves load balancer hostname for apex domains, which
+	// cannot have CNAME records.
+	LookupIPAddr func(ctx context.Context, host string) ([]net.IPAddr, error)
+	// LookupEndpoints enables looking up load balancers of classes from
+	// LoadBalancerEndpoint resources, in preference to labeled Services.
+	// The endpoint index must be set up with SetupIndexes.
+	LookupEndpoints bool
+	Log             logr.Logger
+}
+
+func NewProvider(client client.Client, config Config) (*Provider, error) {
+	if config.Namespace == "" || (config.ServiceName == "" && config.IngressName == "") {
+		return nil, fmt.Errorf("load balancer Service or Ingress is not configured")
+	}
+	return &Provider{
+		KubeClient:   client,
+		Config:       config,
+		LookupIPAddr: net.DefaultResolver.LookupIPAddr,
+		Log:          ctrl.Log.WithName("loadbalancer").WithName("kubernetes"),
+	}, nil
+}
+
+// SetupIndexes sets up the index of LoadBalancerEndpoints by referenced load
+// balancers, used when LookupEndpoints is enabled.
+func SetupIndexes(indexer client.FieldIndexer) error {
+	return indexer.IndexField(&domainv1beta1.LoadBalancerEndpoint{}, endpointSourceIndex, func(o runtime.Object) []string {
+		endpoint := o.(*domainv1beta1.LoadBalancerEndpoint)
+		var values []string
+		if ref := endpoint.Spec.Service; ref != nil {
+			values = append(values, endpointSourceKey(KindService, ref.Namespace, ref.Name))
+		}
+		if ref := endpoint.Spec.Ingress; ref != nil {
+			values = append(values, endpointSourceKey(KindIngress, ref.Namespace, ref.Name))
+		}
+		return values
+	})
+}
+
+func endpointSourceKey(kind string, namespace string, name string) string {
+	return kind + "/" + namespace + "/" + name
+}
+
+var _ loadbalancer.Provider = &Provider{}
+
+// IsSource reports whether the object is the configured load balancer, or a
+// load balancer of a class.
+func (p *Provider) IsSource(kind string, obj metav1.Object) bool {
+	if obj.GetNamespace() == p.Config.Namespace {
+		if kind == KindService && obj.GetLabels()[ClassLabel] != "" {
+			return true
+		}
+		if p.Config.ServiceName != "" {
+			if kind == KindService && obj.GetName() == p.Config.ServiceName {
+				return true
+			}
+		} else if kind == KindIngress && obj.GetName() == p.Config.IngressName {
+			return true
+		}
+	}
+	if !p.LookupEndpoints {
+		return false
+	}
+
+	// Watch events are mapped without a context, so the lookup is served by
+	// index of the cached client.
+	var endpoints domainv1beta1.LoadBalancerEndpointList
+	key := endpointSourceKey(kind, obj.GetNamespace(), obj.GetName())
+	if err := p.KubeClient.List(context.TODO(), &endpoints, client.MatchingFields{endpointSourceIndex: key}); err != nil {
+		p.Log.Error(err, "cannot list load balancer endpoints", "kind", kind, "namespace", obj.GetNamespace(), "name", obj.GetName())
+		return false
+	}
+	for _, endpoint := range endpoints.Items {
+		ref := endpoint.Spec.Service
+		if kind == KindIngress {
+			ref = endpoint.Spec.Ingress
+		}
+		if ref != nil && ref.Namespace == obj.GetNamespace() && ref.Name == obj.GetName() {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Provider) Provision(ctx context.Context, domain *domainv1beta1.CustomDomain) (*loadbalancer.ProvisionResult, error) {
+	kind, name, err := p.loadBalancerOf(ctx, domain.LoadBalancerClass())
+	if err != nil {
+		return nil, err
+	}
+	ingresses, err := p.loadBalancerIngresses(ctx, kind, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(ingresses) == 0 {
+		// load balancer is not ready yet
+		return nil, nil
+	}
+
+	domainName := dnsname.DomainName(domain.Name)
+	rootDomain, err := publicsuffix.EffectiveTLDPlusOne(dnsname.TrimWildcard(domainName))
+	if err != nil {
+		return nil, err
+	}
+	recordName := domainName
+	if recordName == rootDomain {
+		recordName = "@"
+	}
+
+	var dnsRecords []loadbalancer.DNSRecord
+	for _, ingress := range ingresses {
+		if ingress.IP != "" {
+			ip := net.ParseIP(ingress.IP)
+			if ip == nil {
+				continue
+			}
+			recordType := "A"
+			if ip.To4() == nil {
+				recordType = "AAAA"
+			}
+			dnsRecords = append(dnsRecords, loadbalancer.DNSRecord{
+				Name:  recordName,
+				Type:  recordType,
+				Value: ip.String(),
+			})
+		} else if ingress.Hostname != "" {
+			if recordName == "@" {
+				// root domain cannot have CNAME records
+				return p.provisionApex(ctx, ingress.Hostname)
+			}
+			// only one CNAME record is allowed for a name
+			return &loadbalancer.ProvisionResult{DNSRecords: []loadbalancer.DNSRecord{
+				{Name: recordName, Type: "CNAME", Value: ingress.Hostname},
+			}}, nil
+		}
+	}
+
+	if len(dnsRecords) == 0 {
+		return nil, nil
+	}
+	return &loadbalancer.ProvisionResult{
+		DNSRecords: dnsRecords,
+	}, nil
+}
+
+// provisionApex provides A/AAAA records resolved from load balancer hostname
+// for apex domain. The hostname is returned as alias target, so that ALIAS
+// records can be used instead when supported by DNS provider.
+func (p *Provider) provisionApex(ctx context.Context, hostname string) (*loadbalancer.ProvisionResult, error) {
+	addrs, err := p.LookupIPAddr(ctx, hostname)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve load balancer hostname for root domain: %w", err)
+	}
+
+	var dnsRecords []loadbalancer.DNSRecord
+	for _, addr := range addrs {
+		recordType := "A"
+		if addr.IP.To4() == nil {
+			recordType = "AAAA"
+		}
+		dnsRecords = append(dnsRecords, loadbalancer.DNSRecord{
+			Name:  "@",
+			Type:  recordType,
+			Value: addr.IP.String(),
+		})
+	}
+	if len(dnsRecords) == 0 {
+		return nil, nil
+	}
+	return &loadbalancer.ProvisionResult{
+		DNSRecords:  dnsRecords,
+		AliasTarget: hostname,
+	}, nil
+}
+
+func (p *Provider) Release(ctx context.Context, domain *domainv1beta1.CustomDomain) (bool, error) {
+	// Nothing to do.
+	return true, nil
+}
+
+// loadBalancerOf returns the kind and name of load balancer of the class. The
+// configured load balancer is returned if class is empty.
+func (p *Provider) loadBalancerOf(ctx context.Context, class string) (string, types.NamespacedName, error) {
+	if class == "" {
+		if p.Config.ServiceName != "" {
+			return KindService, types.NamespacedName{Namespace: p.Config.Namespace, Name: p.Config.ServiceName}, nil
+		}
+		return KindIngress, types.NamespacedName{Namespace: p.Config.Namespace, Name: p.Config.IngressName}, nil
+	}
+
+	if p.LookupEndpoints {
+		var endpoint domainv1beta1.LoadBalancerEndpoint
+		err := p.KubeClient.Get(ctx, types.NamespacedName{Name: class}, &endpoint)
+		if err == nil {
+			if ref := endpoint.Spec.Service; ref != nil {
+				return KindService, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, nil
+			}
+			if ref := endpoint.Spec.Ingress; ref != nil {
+				return KindIngress, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, nil
+			}
+			return "", types.NamespacedName{}, fmt.Errorf("load balancer endpoint %s has no Service or Ingress", class)
+		} else if !apierrors.IsNotFound(err) {
+			return "", types.NamespacedName{}, err
+		}
+	}
+
+	var services corev1.ServiceList
+	err := p.KubeClient.List(ctx, &services,
+		client.InNamespace(p.Config.Namespace),
+		client.MatchingLabels{ClassLabel: class},
+	)
+	if err != nil {
+		return "", types.NamespacedName{}, err
+	}
+	switch len(services.Items) {
+	case 0:
+		return "", types.NamespacedName{}, fmt.Errorf("load balancer class %s not found", class)
+	case 1:
+		return KindService, types.NamespacedName{Namespace: services.Items[0].Namespace, Name: services.Items[0].Name}, nil
+	default:
+		return "", types.NamespacedName{}, fmt.Errorf("load balancer class %s is served by %d Services", class, len(services.Items))
+	}
+}
+
+func (p *Provider) loadBalancerIngresses(ctx context.Context, kind string, name types.NamespacedName) ([]corev1.LoadBalancerIngress, error) {
+	if kind == KindService {
+		var service corev1.Service
+		err := p.KubeClient.Get(ctx, name, &service)
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("load balancer Service %s not found", name)
+		} else if err != nil {
+			return nil, err
+		}
+		return service.Status.LoadBalancer.Ingress, nil
+	}
+
+	var ingress networkingv1beta1.Ingress
+	err := p.KubeClient.Get(ctx, name, &ingress)
+	if apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("load balancer Ingress %s not found", name)
+	} else if err != nil {
+		return nil, err
+	}
+	return ingress.Status.LoadBalancer.Ingress, nil
+}
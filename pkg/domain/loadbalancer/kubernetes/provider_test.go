@@ -0,0 +1,171 @@
+package kubernetes
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer"
+)
+
+func loadBalancerService(name string, class string, ingress corev1.LoadBalancerIngress) *corev1.Service {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ingress", Name: name},
+		Status: corev1.ServiceStatus{
+			LoadBalancer: corev1.LoadBalancerStatus{Ingress: []corev1.LoadBalancerIngress{ingress}},
+		},
+	}
+	if class != "" {
+		svc.Labels = map[string]string{ClassLabel: class}
+	}
+	return svc
+}
+
+func TestProvision(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := domainv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	kubeClient := fake.NewFakeClientWithScheme(scheme,
+		loadBalancerService("default", "", corev1.LoadBalancerIngress{IP: "192.0.2.1"}),
+		loadBalancerService("internal", "internal", corev1.LoadBalancerIngress{Hostname: "internal.lb.example.net"}),
+	)
+	provider, err := NewProvider(kubeClient, Config{Namespace: "ingress", ServiceName: "default"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider.LookupIPAddr = func(ctx context.Context, host string) ([]net.IPAddr, error) {
+		if host != "internal.lb.example.net" {
+			t.Errorf("unexpected lookup of %s", host)
+		}
+		return []net.IPAddr{{IP: net.ParseIP("192.0.2.2")}, {IP: net.ParseIP("2001:db8::2")}}, nil
+	}
+
+	newDomain := func(name string, class string) *domainv1beta1.CustomDomain {
+		return &domainv1beta1.CustomDomain{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: domainv1beta1.CustomDomainSpec{
+				Registrations: []domainv1beta1.CustomDomainRegistrationReference{
+					{LoadBalancerClass: class},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		domain   *domainv1beta1.CustomDomain
+		expected *loadbalancer.ProvisionResult
+	}{
+		{
+			name:   "default subdomain",
+			domain: newDomain("www.example.com", ""),
+			expected: &loadbalancer.ProvisionResult{DNSRecords: []loadbalancer.DNSRecord{
+				{Name: "www.example.com", Type: "A", Value: "192.0.2.1"},
+			}},
+		},
+		{
+			name:   "default apex domain",
+			domain: newDomain("example.com", ""),
+			expected: &loadbalancer.ProvisionResult{DNSRecords: []loadbalancer.DNSRecord{
+				{Name: "@", Type: "A", Value: "192.0.2.1"},
+			}},
+		},
+		{
+			name:   "classed subdomain",
+			domain: newDomain("www.example.com", "internal"),
+			expected: &loadbalancer.ProvisionResult{DNSRecords: []loadbalancer.DNSRecord{
+				{Name: "www.example.com", Type: "CNAME", Value: "internal.lb.example.net"},
+			}},
+		},
+		{
+			name:   "classed apex domain",
+			domain: newDomain("example.com", "internal"),
+			expected: &loadbalancer.ProvisionResult{
+				DNSRecords: []loadbalancer.DNSRecord{
+					{Name: "@", Type: "A", Value: "192.0.2.2"},
+					{Name: "@", Type: "AAAA", Value: "2001:db8::2"},
+				},
+				AliasTarget: "internal.lb.example.net",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := provider.Provision(context.Background(), tt.domain)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Provision() = %+v, expected %+v", result, tt.expected)
+			}
+		})
+	}
+
+	if _, err := provider.Provision(context.Background(), newDomain("example.com", "unknown")); err == nil {
+		t.Errorf("expected error for unknown load balancer class")
+	}
+}
+
+func TestIsSource(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := domainv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	kubeClient := fake.NewFakeClientWithScheme(scheme,
+		&domainv1beta1.LoadBalancerEndpoint{
+			ObjectMeta: metav1.ObjectMeta{Name: "edge"},
+			Spec: domainv1beta1.LoadBalancerEndpointSpec{
+				Service: &domainv1beta1.LoadBalancerEndpointReference{Namespace: "edge", Name: "gateway"},
+			},
+		},
+	)
+	provider, err := NewProvider(kubeClient, Config{Namespace: "ingress", ServiceName: "default"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	object := func(namespace string, name string, labels map[string]string) metav1.Object {
+		return &metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: labels}
+	}
+
+	tests := []struct {
+		name            string
+		kind            string
+		obj             metav1.Object
+		lookupEndpoints bool
+		expected        bool
+	}{
+		{"configured service", KindService, object("ingress", "default", nil), false, true},
+		{"classed service", KindService, object("ingress", "internal", map[string]string{ClassLabel: "internal"}), false, true},
+		{"unrelated service", KindService, object("ingress", "other", nil), false, false},
+		{"endpoint service without lookup", KindService, object("edge", "gateway", nil), false, false},
+		{"endpoint service", KindService, object("edge", "gateway", nil), true, true},
+		{"endpoint service as ingress", KindIngress, object("edge", "gateway", nil), true, false},
+		{"unrelated service with lookup", KindService, object("edge", "other", nil), true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider.LookupEndpoints = tt.lookupEndpoints
+			if actual := provider.IsSource(tt.kind, tt.obj); actual != tt.expected {
+				t.Errorf("IsSource() = %v, expected %v", actual, tt.expected)
+			}
+		})
+	}
+}
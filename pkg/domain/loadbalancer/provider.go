@@ -13,6 +13,9 @@ type Provider interface {
 
 type ProvisionResult struct {
 	DNSRecords []DNSRecord
+	// AliasTarget is the hostname of load balancer, when DNSRecords of an
+	// apex domain are resolved from it.
+	AliasTarget string
 }
 
 type DNSRecord struct {
@@ -9,6 +9,7 @@ import (
 
 	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
 	"github.com/skygeario/k8s-controller/pkg/domain/loadbalancer"
+	"github.com/skygeario/k8s-controller/pkg/util/dnsname"
 )
 
 type Provider struct {
@@ -47,7 +48,7 @@ func (p *Provider) Provision(ctx context.Context, domain *domainv1beta1.CustomDo
 			recordType = "A"
 		}
 
-		name := domain.Name
+		name := dnsname.DomainName(domain.Name)
 		if name == rootDomain {
 			name = "@"
 		}
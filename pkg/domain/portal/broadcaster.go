@@ -0,0 +1,75 @@
+package portal
+
+import "sync"
+
+// subscriberBufferSize is the number of events buffered for a subscriber.
+// Subscribers falling behind are disconnected, so that informers are never
+// blocked by slow clients.
+const subscriberBufferSize = 64
+
+type subscriber struct {
+	namespace string
+	events    chan DomainEvent
+}
+
+// broadcaster fans out domain events to subscribers.
+type broadcaster struct {
+	lock        sync.Mutex
+	subscribers map[*subscriber]struct{}
+	closed      bool
+}
+
+func newBroadcaster() *broadcaster {
+	return &broadcaster{subscribers: map[*subscriber]struct{}{}}
+}
+
+// Subscribe returns events of registrations in namespace, or all namespaces
+// if namespace is empty. The channel is closed if the subscriber falls
+// behind or the broadcaster is closed.
+func (b *broadcaster) Subscribe(namespace string) (events <-chan DomainEvent, cancel func()) {
+	sub := &subscriber{namespace: namespace, events: make(chan DomainEvent, subscriberBufferSize)}
+	b.lock.Lock()
+	if b.closed {
+		close(sub.events)
+	} else {
+		b.subscribers[sub] = struct{}{}
+	}
+	b.lock.Unlock()
+
+	return sub.events, func() {
+		b.lock.Lock()
+		defer b.lock.Unlock()
+		if _, ok := b.subscribers[sub]; ok {
+			delete(b.subscribers, sub)
+			close(sub.events)
+		}
+	}
+}
+
+func (b *broadcaster) Publish(event DomainEvent) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for sub := range b.subscribers {
+		if sub.namespace != "" && sub.namespace != event.Domain.Namespace {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			delete(b.subscribers, sub)
+			close(sub.events)
+		}
+	}
+}
+
+// Close closes channels of all subscribers, so that streams end on
+// shutdown.
+func (b *broadcaster) Close() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.closed = true
+	for sub := range b.subscribers {
+		delete(b.subscribers, sub)
+		close(sub.events)
+	}
+}
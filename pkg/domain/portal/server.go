@@ -0,0 +1,192 @@
+package portal
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/util/bearer"
+	"github.com/skygeario/k8s-controller/pkg/util/httpserver"
+)
+
+// ServiceName is the full name of the gRPC service defined in portal.proto.
+const ServiceName = "skygear.domain.portal.v1.DomainPortal"
+
+// shutdownTimeout is the duration waiting for in-flight requests on
+// shutdown.
+const shutdownTimeout = 10 * time.Second
+
+// Server serves the DomainPortal gRPC service of portal.proto over plaintext
+// HTTP/2, for the hosting platform portal. Reads are served from the cache
+// of the manager so that portal instances do not query the Kubernetes API
+// directly. Requests are authenticated by bearer token in metadata
+// authorization.
+type Server struct {
+	ListenAddress string
+	// Token is the bearer token of requests.
+	Token string
+	// Client reads registrations, usually from the manager cache.
+	Client client.Reader
+	// Informers notifies changes of registrations for event streams.
+	Informers cache.Informers
+	Log       logr.Logger
+
+	broadcaster *broadcaster
+}
+
+func (s *Server) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	if r.ProtoMajor != 2 || r.Method != http.MethodPost {
+		http.Error(rw, "gRPC over HTTP/2 is required", http.StatusBadRequest)
+		return
+	}
+	if ct := r.Header.Get("Content-Type"); ct != "application/grpc" && ct != "application/grpc+proto" {
+		http.Error(rw, http.StatusText(http.StatusUnsupportedMediaType), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/grpc")
+	// Trailers are declared, so that they are sent for responses without
+	// messages.
+	rw.Header().Set("Trailer", "Grpc-Status, Grpc-Message")
+	rw.WriteHeader(http.StatusOK)
+	writeStatus(rw, s.serveRPC(rw, r))
+}
+
+func (s *Server) serveRPC(rw http.ResponseWriter, r *http.Request) error {
+	if !bearer.Authenticate(r, s.Token) {
+		return newStatusError(codeUnauthenticated, "invalid bearer token")
+	}
+	prefix := "/" + ServiceName + "/"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		return newStatusError(codeUnimplemented, "unknown service")
+	}
+
+	msg, err := readMessage(r.Body)
+	if err != nil {
+		return newStatusError(codeInvalidArgument, "cannot read request: %s", err)
+	}
+	req, err := decodeStrings(msg)
+	if err != nil {
+		return newStatusError(codeInvalidArgument, "cannot decode request: %s", err)
+	}
+
+	switch method := strings.TrimPrefix(r.URL.Path, prefix); method {
+	case "GetDomainStatus":
+		return s.getDomainStatus(r.Context(), rw, types.NamespacedName{Namespace: req[1], Name: req[2]})
+	case "ListDomainsByNamespace":
+		return s.listDomainsByNamespace(r.Context(), rw, req[1])
+	case "StreamDomainEvents":
+		return s.streamDomainEvents(r.Context(), rw, req[1])
+	default:
+		return newStatusError(codeUnimplemented, "unknown method %s", method)
+	}
+}
+
+func (s *Server) getDomainStatus(ctx context.Context, rw http.ResponseWriter, name types.NamespacedName) error {
+	if name.Namespace == "" || name.Name == "" {
+		return newStatusError(codeInvalidArgument, "namespace and name are required")
+	}
+
+	var reg domainv1beta1.CustomDomainRegistration
+	err := s.Client.Get(ctx, name, &reg)
+	if errors.IsNotFound(err) {
+		return newStatusError(codeNotFound, "registration %s not found", name)
+	} else if err != nil {
+		s.Log.Error(err, "failed to get registration", "namespace", name.Namespace, "name", name.Name)
+		return err
+	}
+	return writeMessage(rw, NewDomainStatus(&reg))
+}
+
+func (s *Server) listDomainsByNamespace(ctx context.Context, rw http.ResponseWriter, namespace string) error {
+	if namespace == "" {
+		return newStatusError(codeInvalidArgument, "namespace is required")
+	}
+
+	var list domainv1beta1.CustomDomainRegistrationList
+	if err := s.Client.List(ctx, &list, client.InNamespace(namespace)); err != nil {
+		s.Log.Error(err, "failed to list registrations", "namespace", namespace)
+		return err
+	}
+
+	var resp DomainList
+	for i := range list.Items {
+		resp.Domains = append(resp.Domains, NewDomainStatus(&list.Items[i]))
+	}
+	return writeMessage(rw, resp)
+}
+
+func (s *Server) streamDomainEvents(ctx context.Context, rw http.ResponseWriter, namespace string) error {
+	flusher, ok := rw.(http.Flusher)
+	if !ok || s.broadcaster == nil {
+		return newStatusError(codeUnimplemented, "streaming unsupported")
+	}
+
+	events, cancel := s.broadcaster.Subscribe(namespace)
+	defer cancel()
+	flusher.Flush()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				// subscriber is too slow or server is shutting down;
+				// client should reconnect
+				return newStatusError(codeUnavailable, "event stream closed")
+			}
+			if err := writeMessage(rw, event); err != nil {
+				return nil
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// Start implements manager.Runnable, serving requests until stop is closed.
+func (s *Server) Start(stop <-chan struct{}) error {
+	s.broadcaster = newBroadcaster()
+	informer, err := s.Informers.GetInformer(&domainv1beta1.CustomDomainRegistration{})
+	if err != nil {
+		return err
+	}
+	informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			s.broadcast(EventAdded, obj)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			s.broadcast(EventModified, newObj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			s.broadcast(EventDeleted, obj)
+		},
+	})
+
+	server := &http.Server{Addr: s.ListenAddress, Handler: h2c.NewHandler(s, &http2.Server{})}
+	// Shutdown does not cancel requests, so end event streams on shutdown.
+	server.RegisterOnShutdown(s.broadcaster.Close)
+	return httpserver.Run(server, stop, shutdownTimeout)
+}
+
+func (s *Server) broadcast(eventType EventType, obj interface{}) {
+	reg, ok := obj.(*domainv1beta1.CustomDomainRegistration)
+	if !ok {
+		return
+	}
+	s.broadcaster.Publish(DomainEvent{Type: eventType, Domain: NewDomainStatus(reg)})
+}
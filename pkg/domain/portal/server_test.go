@@ -0,0 +1,224 @@
+package portal
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/hex"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+)
+
+const testToken = "portal-token"
+
+func newTestServer(t *testing.T, objs ...runtime.Object) (*Server, *httptest.Server) {
+	scheme := runtime.NewScheme()
+	if err := domainv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{
+		Token:       testToken,
+		Client:      fake.NewFakeClientWithScheme(scheme, objs...),
+		Log:         logr.Logger(log.NullLogger{}),
+		broadcaster: newBroadcaster(),
+	}
+	server := httptest.NewServer(h2c.NewHandler(s, &http2.Server{}))
+	t.Cleanup(server.Close)
+	return s, server
+}
+
+// h2cClient returns a client sending requests over plaintext HTTP/2.
+func h2cClient() *http.Client {
+	return &http.Client{
+		Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLS: func(network, addr string, cfg *tls.Config) (net.Conn, error) {
+				return net.Dial(network, addr)
+			},
+		},
+	}
+}
+
+// frame returns the gRPC message of the hex-encoded protobuf message.
+func frame(msg string) []byte {
+	b, _ := hex.DecodeString(msg)
+	framed := make([]byte, 5, 5+len(b))
+	binary.BigEndian.PutUint32(framed[1:], uint32(len(b)))
+	return append(framed, b...)
+}
+
+func call(t *testing.T, server *httptest.Server, method string, token string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/"+ServiceName+"/"+method, bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return h2cClient().Do(req)
+}
+
+// readResponse reads messages and gRPC status of the response.
+func readResponse(t *testing.T, resp *http.Response) ([]string, code) {
+	defer resp.Body.Close()
+	var msgs []string
+	for {
+		msg, err := readMessage(resp.Body)
+		if err != nil {
+			break
+		}
+		msgs = append(msgs, hex.EncodeToString(msg))
+	}
+	_, _ = ioutil.ReadAll(resp.Body)
+	c, err := strconv.Atoi(resp.Trailer.Get("Grpc-Status"))
+	if err != nil {
+		t.Fatalf("invalid grpc-status: %q", resp.Trailer.Get("Grpc-Status"))
+	}
+	return msgs, code(c)
+}
+
+func TestServerUnary(t *testing.T) {
+	newRegistration := func(name string) *domainv1beta1.CustomDomainRegistration {
+		return &domainv1beta1.CustomDomainRegistration{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: name},
+		}
+	}
+	_, server := newTestServer(t, newRegistration("a.example.com"), newRegistration("b.example.com"))
+
+	tests := []struct {
+		name     string
+		method   string
+		token    string
+		body     []byte
+		code     code
+		expected []string
+	}{
+		{
+			name:     "GetDomainStatus",
+			method:   "GetDomainStatus",
+			token:    testToken,
+			body:     frame(hex.EncodeToString([]byte("\x0a\x03app\x12\x0da.example.com"))),
+			code:     codeOK,
+			expected: []string{hex.EncodeToString([]byte("\x0a\x03app\x12\x0da.example.com"))},
+		},
+		{
+			name:   "GetDomainStatus not found",
+			method: "GetDomainStatus",
+			token:  testToken,
+			body:   frame(goldenGetDomainStatusRequest),
+			code:   codeNotFound,
+		},
+		{
+			name:     "ListDomainsByNamespace",
+			method:   "ListDomainsByNamespace",
+			token:    testToken,
+			body:     frame(hex.EncodeToString([]byte("\x0a\x03app"))),
+			code:     codeOK,
+			expected: []string{goldenDomainList},
+		},
+		{
+			name:   "unauthenticated",
+			method: "ListDomainsByNamespace",
+			token:  "invalid",
+			body:   frame(hex.EncodeToString([]byte("\x0a\x03app"))),
+			code:   codeUnauthenticated,
+		},
+		{
+			name:   "no token",
+			method: "GetDomainStatus",
+			body:   frame(goldenGetDomainStatusRequest),
+			code:   codeUnauthenticated,
+		},
+		{
+			name:   "oversized request",
+			method: "GetDomainStatus",
+			token:  testToken,
+			body:   frame(hex.EncodeToString(make([]byte, maxRequestSize+1))),
+			code:   codeInvalidArgument,
+		},
+		{
+			name:   "unknown method",
+			method: "DeleteDomain",
+			token:  testToken,
+			body:   frame(goldenGetDomainStatusRequest),
+			code:   codeUnimplemented,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := call(t, server, tt.method, tt.token, tt.body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.ProtoMajor != 2 {
+				t.Errorf("response protocol = %s", resp.Proto)
+			}
+			msgs, c := readResponse(t, resp)
+			if c != tt.code {
+				t.Errorf("status = %d, expected %d", c, tt.code)
+			}
+			if len(msgs) != len(tt.expected) {
+				t.Fatalf("messages = %v, expected %v", msgs, tt.expected)
+			}
+			for i := range msgs {
+				if msgs[i] != tt.expected[i] {
+					t.Errorf("message %d = %s, expected %s", i, msgs[i], tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestServerStreamDomainEvents(t *testing.T) {
+	s, server := newTestServer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL+"/"+ServiceName+"/StreamDomainEvents", bytes.NewReader(frame(hex.EncodeToString([]byte("\x0a\x03app")))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	// Response headers are sent after subscribed.
+	resp, err := h2cClient().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.broadcaster.Publish(DomainEvent{Type: EventAdded, Domain: DomainStatus{Namespace: "other", Name: "example.com"}})
+	s.broadcaster.Publish(DomainEvent{Type: EventDeleted, Domain: DomainStatus{Namespace: "app", Name: "example.com"}})
+	msg, err := readMessage(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual := hex.EncodeToString(msg); actual != goldenDomainEvent {
+		t.Errorf("event = %s, expected %s", actual, goldenDomainEvent)
+	}
+
+	// Streams end on shutdown.
+	s.broadcaster.Close()
+	msgs, c := readResponse(t, resp)
+	if len(msgs) != 0 {
+		t.Errorf("unexpected events: %v", msgs)
+	}
+	if c != codeUnavailable {
+		t.Errorf("status = %d, expected %d", c, codeUnavailable)
+	}
+}
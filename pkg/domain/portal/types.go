@@ -0,0 +1,108 @@
+package portal
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/skygeario/k8s-controller/api"
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+)
+
+// DomainStatus is the state of a registration shown in the portal, message
+// DomainStatus of portal.proto.
+type DomainStatus struct {
+	Namespace            string
+	Name                 string
+	DomainName           string
+	Phase                domainv1beta1.CustomDomainRegistrationPhase
+	Conditions           []api.Condition
+	Instructions         []domainv1beta1.CustomDomainDNSInstruction
+	VerificationURL      *string
+	LastVerificationTime *metav1.Time
+	NextVerificationTime *metav1.Time
+}
+
+// NewDomainStatus returns the state of the registration.
+func NewDomainStatus(reg *domainv1beta1.CustomDomainRegistration) DomainStatus {
+	return DomainStatus{
+		Namespace:            reg.Namespace,
+		Name:                 reg.Name,
+		DomainName:           reg.Spec.DomainName,
+		Phase:                reg.Status.Phase,
+		Conditions:           reg.Status.Conditions,
+		Instructions:         reg.Status.Instructions,
+		VerificationURL:      reg.Status.VerificationURL,
+		LastVerificationTime: reg.Status.LastVerificationTime,
+		NextVerificationTime: reg.Status.NextVerificationTime,
+	}
+}
+
+func (s DomainStatus) marshalProto(e *encoder) {
+	e.String(1, s.Namespace)
+	e.String(2, s.Name)
+	e.String(3, s.DomainName)
+	e.String(4, string(s.Phase))
+	for _, c := range s.Conditions {
+		e.Message(5, condition(c))
+	}
+	for _, i := range s.Instructions {
+		e.Message(6, instruction(i))
+	}
+	if s.VerificationURL != nil {
+		e.String(7, *s.VerificationURL)
+	}
+	e.Timestamp(8, s.LastVerificationTime)
+	e.Timestamp(9, s.NextVerificationTime)
+}
+
+type condition api.Condition
+
+func (c condition) marshalProto(e *encoder) {
+	e.String(1, c.Type)
+	e.String(2, string(c.Status))
+	e.String(3, c.Reason)
+	e.String(4, c.Message)
+	e.Timestamp(5, &c.LastTransitionTime)
+}
+
+type instruction domainv1beta1.CustomDomainDNSInstruction
+
+func (i instruction) marshalProto(e *encoder) {
+	e.String(1, i.Name)
+	e.String(2, i.Type)
+	e.String(3, i.Value)
+	e.String(4, i.Description)
+	e.String(5, i.Command)
+}
+
+// DomainList is the response of ListDomainsByNamespace, message
+// ListDomainsByNamespaceResponse of portal.proto.
+type DomainList struct {
+	Domains []DomainStatus
+}
+
+func (l DomainList) marshalProto(e *encoder) {
+	for _, d := range l.Domains {
+		e.Message(1, d)
+	}
+}
+
+// EventType is the type of change of a registration, enum DomainEvent.Type
+// of portal.proto.
+type EventType int
+
+const (
+	EventAdded    EventType = 1
+	EventModified EventType = 2
+	EventDeleted  EventType = 3
+)
+
+// DomainEvent is a change of a registration in StreamDomainEvents.
+type DomainEvent struct {
+	Type   EventType
+	Domain DomainStatus
+}
+
+func (ev DomainEvent) marshalProto(e *encoder) {
+	e.Int64(1, int64(ev.Type))
+	e.Message(2, ev.Domain)
+}
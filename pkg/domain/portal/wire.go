@@ -0,0 +1,220 @@
+package portal
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Messages of portal.proto are encoded by hand and framed as gRPC messages,
+// so that the portal API does not depend on gRPC and protobuf runtime
+// libraries. Only the wire types used by portal.proto are supported.
+
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// maxRequestSize limits the size of request messages, which contain a few
+// short strings only.
+const maxRequestSize = 1 << 16
+
+type marshaler interface {
+	marshalProto(e *encoder)
+}
+
+// encoder encodes fields of a protobuf message. Fields with default values
+// are omitted, as in proto3.
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) varint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(b[:], v)
+	e.buf = append(e.buf, b[:n]...)
+}
+
+func (e *encoder) tag(field int, wireType int) {
+	e.varint(uint64(field)<<3 | uint64(wireType))
+}
+
+func (e *encoder) Int64(field int, v int64) {
+	if v == 0 {
+		return
+	}
+	e.tag(field, wireVarint)
+	e.varint(uint64(v))
+}
+
+func (e *encoder) String(field int, s string) {
+	if s == "" {
+		return
+	}
+	e.tag(field, wireBytes)
+	e.varint(uint64(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+func (e *encoder) Message(field int, m marshaler) {
+	var inner encoder
+	m.marshalProto(&inner)
+	e.tag(field, wireBytes)
+	e.varint(uint64(len(inner.buf)))
+	e.buf = append(e.buf, inner.buf...)
+}
+
+// Timestamp encodes t as google.protobuf.Timestamp.
+func (e *encoder) Timestamp(field int, t *metav1.Time) {
+	if t == nil || t.IsZero() {
+		return
+	}
+	e.Message(field, timestamp(t.Time))
+}
+
+type timestamp time.Time
+
+func (t timestamp) marshalProto(e *encoder) {
+	e.Int64(1, time.Time(t).Unix())
+	e.Int64(2, int64(time.Time(t).Nanosecond()))
+}
+
+// decodeStrings decodes string fields of a message by field number. Fields
+// of other types are skipped.
+func decodeStrings(b []byte) (map[int]string, error) {
+	fields := map[int]string{}
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		if n <= 0 {
+			return nil, errors.New("malformed field key")
+		}
+		b = b[n:]
+		field := int(key >> 3)
+		switch key & 7 {
+		case wireVarint:
+			if _, n = binary.Uvarint(b); n <= 0 {
+				return nil, errors.New("malformed varint")
+			}
+			b = b[n:]
+		case wireFixed64:
+			if len(b) < 8 {
+				return nil, io.ErrUnexpectedEOF
+			}
+			b = b[8:]
+		case wireBytes:
+			l, n := binary.Uvarint(b)
+			if n <= 0 || l > uint64(len(b)-n) {
+				return nil, errors.New("malformed length")
+			}
+			fields[field] = string(b[n : n+int(l)])
+			b = b[n+int(l):]
+		case wireFixed32:
+			if len(b) < 4 {
+				return nil, io.ErrUnexpectedEOF
+			}
+			b = b[4:]
+		default:
+			return nil, fmt.Errorf("unsupported wire type %d", key&7)
+		}
+	}
+	return fields, nil
+}
+
+// readMessage reads a length-prefixed gRPC message.
+func readMessage(r io.Reader) ([]byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	if header[0] != 0 {
+		return nil, errors.New("compressed messages are not supported")
+	}
+	size := binary.BigEndian.Uint32(header[1:])
+	if size > maxRequestSize {
+		return nil, fmt.Errorf("message of %d bytes exceeds limit", size)
+	}
+	msg := make([]byte, size)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// writeMessage writes a length-prefixed gRPC message.
+func writeMessage(w io.Writer, m marshaler) error {
+	var e encoder
+	m.marshalProto(&e)
+	msg := make([]byte, 5, 5+len(e.buf))
+	binary.BigEndian.PutUint32(msg[1:], uint32(len(e.buf)))
+	msg = append(msg, e.buf...)
+	_, err := w.Write(msg)
+	return err
+}
+
+// code is a gRPC status code.
+type code int
+
+const (
+	codeOK              code = 0
+	codeInvalidArgument code = 3
+	codeNotFound        code = 5
+	codeUnimplemented   code = 12
+	codeInternal        code = 13
+	codeUnavailable     code = 14
+	codeUnauthenticated code = 16
+)
+
+// statusError is an error returned to clients as gRPC status.
+type statusError struct {
+	Code    code
+	Message string
+}
+
+func newStatusError(c code, format string, args ...interface{}) error {
+	return &statusError{Code: c, Message: fmt.Sprintf(format, args...)}
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("rpc error: code = %d desc = %s", e.Code, e.Message)
+}
+
+// writeStatus sets the gRPC status of err in response trailers, which should
+// be declared before writing headers. Errors other than statusError are
+// reported as internal errors without details.
+func writeStatus(rw http.ResponseWriter, err error) {
+	c, message := codeOK, ""
+	if err != nil {
+		c, message = codeInternal, "internal error"
+		var statusErr *statusError
+		if errors.As(err, &statusErr) {
+			c, message = statusErr.Code, statusErr.Message
+		}
+	}
+	rw.Header().Set("Grpc-Status", strconv.Itoa(int(c)))
+	if message != "" {
+		rw.Header().Set("Grpc-Message", percentEncode(message))
+	}
+}
+
+// percentEncode encodes grpc-message as specified by gRPC over HTTP/2.
+func percentEncode(s string) string {
+	const hex = "0123456789ABCDEF"
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 0x20 && c <= 0x7e && c != '%' {
+			b = append(b, c)
+			continue
+		}
+		b = append(b, '%', hex[c>>4], hex[c&0xf])
+	}
+	return string(b)
+}
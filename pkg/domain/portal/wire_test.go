@@ -0,0 +1,118 @@
+package portal
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	"github.com/skygeario/k8s-controller/api"
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+)
+
+// Golden messages are encoded by github.com/golang/protobuf v1.3.2 with
+// messages of portal.proto.
+const (
+	goldenDomainStatus = "0a03617070120b6578616d706c652e636f6d1a0b6578616d706c652e636f6d2208566572696669" +
+		"65642a220a0856657269666965641204547275651a0856657269666965642a0608a5bbb5f005322" +
+		"20a145f736b79676561722e6578616d706c652e636f6d12035458541a05746f6b656e3a1a687474" +
+		"70733a2f2f6578616d706c652e636f6d2f766572696679420608a5bbb5f0054a0908b5d7b5f0051" +
+		"0f403"
+	goldenDomainList             = "0a140a03617070120d612e6578616d706c652e636f6d0a140a03617070120d622e6578616d706c652e636f6d"
+	goldenDomainEvent            = "080312120a03617070120b6578616d706c652e636f6d"
+	goldenGetDomainStatusRequest = "0a03617070120b6578616d706c652e636f6d"
+)
+
+func encode(m marshaler) string {
+	var e encoder
+	m.marshalProto(&e)
+	return hex.EncodeToString(e.buf)
+}
+
+func TestMarshalGolden(t *testing.T) {
+	lastVerificationTime := metav1.NewTime(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))
+	nextVerificationTime := metav1.NewTime(time.Date(2020, 1, 2, 4, 4, 5, 500, time.UTC))
+	status := DomainStatus{
+		Namespace:  "app",
+		Name:       "example.com",
+		DomainName: "example.com",
+		Phase:      domainv1beta1.CustomDomainRegistrationPhase("Verified"),
+		Conditions: []api.Condition{{
+			Type:               "Verified",
+			Status:             metav1.ConditionTrue,
+			Reason:             "Verified",
+			LastTransitionTime: lastVerificationTime,
+		}},
+		Instructions: []domainv1beta1.CustomDomainDNSInstruction{
+			{Name: "_skygear.example.com", Type: "TXT", Value: "token"},
+		},
+		VerificationURL:      pointer.StringPtr("https://example.com/verify"),
+		LastVerificationTime: &lastVerificationTime,
+		NextVerificationTime: &nextVerificationTime,
+	}
+
+	tests := []struct {
+		name     string
+		message  marshaler
+		expected string
+	}{
+		{"DomainStatus", status, goldenDomainStatus},
+		{"ListDomainsByNamespaceResponse", DomainList{Domains: []DomainStatus{
+			{Namespace: "app", Name: "a.example.com"},
+			{Namespace: "app", Name: "b.example.com"},
+		}}, goldenDomainList},
+		{"DomainEvent", DomainEvent{Type: EventDeleted, Domain: DomainStatus{Namespace: "app", Name: "example.com"}}, goldenDomainEvent},
+	}
+	for _, tt := range tests {
+		if actual := encode(tt.message); actual != tt.expected {
+			t.Errorf("%s: encoded %s, expected %s", tt.name, actual, tt.expected)
+		}
+	}
+}
+
+func TestDecodeGolden(t *testing.T) {
+	msg, _ := hex.DecodeString(goldenGetDomainStatusRequest)
+	fields, err := decodeStrings(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fields[1] != "app" || fields[2] != "example.com" {
+		t.Errorf("unexpected fields: %v", fields)
+	}
+
+	// Fields of other wire types are skipped.
+	msg, _ = hex.DecodeString(goldenDomainEvent)
+	fields, err = decodeStrings(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fields) != 1 || fields[2] != "\x0a\x03app\x12\x0bexample.com" {
+		t.Errorf("unexpected fields: %q", fields)
+	}
+
+	if _, err := decodeStrings(msg[:len(msg)-1]); err == nil {
+		t.Error("expected error for truncated message")
+	}
+}
+
+func TestReadMessage(t *testing.T) {
+	msg, _ := hex.DecodeString(goldenGetDomainStatusRequest)
+	framed := append([]byte{0, 0, 0, 0, byte(len(msg))}, msg...)
+	read, err := readMessage(bytes.NewReader(framed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(read, msg) {
+		t.Errorf("read %x, expected %x", read, msg)
+	}
+
+	if _, err := readMessage(bytes.NewReader([]byte{0, 0, 1, 0, 1})); err == nil {
+		t.Error("expected error for oversized message")
+	}
+	if _, err := readMessage(bytes.NewReader(append([]byte{1}, framed[1:]...))); err == nil {
+		t.Error("expected error for compressed message")
+	}
+}
@@ -0,0 +1,10 @@
+package gatewayapi
+
+type Config struct {
+	// GatewayNamespace is the namespace of Gateway routes attached to.
+	GatewayNamespace string
+	// GatewayName is the name of Gateway routes attached to.
+	GatewayName string
+	// SectionName is the name of Gateway listener routes attached to.
+	SectionName string
+}
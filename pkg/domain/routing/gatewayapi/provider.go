@@ -0,0 +1,211 @@
+package gatewayapi
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/externaldns"
+	"github.com/skygeario/k8s-controller/pkg/domain/routing"
+)
+
+var HTTPRouteGVK = schema.GroupVersionKind{
+	Group:   "gateway.networking.k8s.io",
+	Version: "v1beta1",
+	Kind:    "HTTPRoute",
+}
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	_ = domainv1beta1.AddToScheme(scheme)
+}
+
+type Provider struct {
+	KubeClient client.Client
+	Config     Config
+	// ExternalDNS annotates generated routes for external-dns, if set.
+	ExternalDNS *externaldns.Annotator
+}
+
+func NewProvider(client client.Client, config Config) (*Provider, error) {
+	if config.GatewayName == "" {
+		return nil, fmt.Errorf("gateway name is not configured")
+	}
+	return &Provider{
+		KubeClient: client,
+		Config:     config,
+	}, nil
+}
+
+var _ routing.Provider = &Provider{}
+
+func (p *Provider) Provision(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (bool, error) {
+	route, err := p.makeHTTPRoute(reg)
+	if err != nil {
+		return false, err
+	}
+
+	existingRoute := &unstructured.Unstructured{}
+	existingRoute.SetGroupVersionKind(HTTPRouteGVK)
+	err = p.KubeClient.Get(ctx, types.NamespacedName{Namespace: route.GetNamespace(), Name: route.GetName()}, existingRoute)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return false, err
+	}
+
+	if apierrors.IsNotFound(err) {
+		if err := p.KubeClient.Create(ctx, route); err != nil {
+			return false, err
+		}
+		return false, nil
+	}
+
+	if !reflect.DeepEqual(existingRoute.Object["spec"], route.Object["spec"]) ||
+		!reflect.DeepEqual(existingRoute.GetAnnotations(), route.GetAnnotations()) {
+		existingRoute.Object["spec"] = route.Object["spec"]
+		existingRoute.SetAnnotations(route.GetAnnotations())
+		if err := p.KubeClient.Update(ctx, existingRoute); err != nil {
+			return false, err
+		}
+		return false, nil
+	}
+
+	return isAccepted(existingRoute), nil
+}
+
+func (p *Provider) Release(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (bool, error) {
+	route := &unstructured.Unstructured{}
+	route.SetGroupVersionKind(HTTPRouteGVK)
+	err := p.KubeClient.Get(ctx, types.NamespacedName{Namespace: reg.Namespace, Name: reg.Name}, route)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}
+
+	if !metav1.IsControlledBy(route, reg) {
+		return true, nil
+	}
+
+	if err := p.KubeClient.Delete(ctx, route); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (p *Provider) makeHTTPRoute(reg *domainv1beta1.CustomDomainRegistration) (*unstructured.Unstructured, error) {
+	parentRef := map[string]interface{}{
+		"name": p.Config.GatewayName,
+	}
+	if p.Config.GatewayNamespace != "" {
+		parentRef["namespace"] = p.Config.GatewayNamespace
+	}
+	if p.Config.SectionName != "" {
+		parentRef["sectionName"] = p.Config.SectionName
+	}
+
+	redirect, err := routing.MakeRedirect(reg)
+	if err != nil {
+		return nil, err
+	}
+
+	var rule map[string]interface{}
+	if redirect != nil {
+		rule = map[string]interface{}{
+			"filters": []interface{}{
+				map[string]interface{}{
+					"type":            "RequestRedirect",
+					"requestRedirect": makeRequestRedirect(redirect),
+				},
+			},
+		}
+	} else {
+		rule = map[string]interface{}{
+			"backendRefs": []interface{}{
+				map[string]interface{}{
+					"name": reg.Spec.DomainConfig.BackendServiceName,
+					"port": int64(reg.Spec.DomainConfig.BackendServicePort),
+				},
+			},
+		}
+	}
+
+	route := &unstructured.Unstructured{}
+	route.SetGroupVersionKind(HTTPRouteGVK)
+	route.SetNamespace(reg.Namespace)
+	route.SetName(reg.Name)
+	route.Object["spec"] = map[string]interface{}{
+		"parentRefs": []interface{}{parentRef},
+		"hostnames":  []interface{}{reg.ASCIIDomainName()},
+		"rules":      []interface{}{rule},
+	}
+
+	if p.ExternalDNS != nil {
+		annotations := map[string]string{}
+		if err := p.ExternalDNS.Annotate(reg, annotations); err != nil {
+			return nil, err
+		}
+		route.SetAnnotations(annotations)
+	}
+
+	if err := ctrl.SetControllerReference(reg, route, scheme); err != nil {
+		return nil, err
+	}
+	return route, nil
+}
+
+func makeRequestRedirect(r *routing.Redirect) map[string]interface{} {
+	u := r.URL
+	redirect := map[string]interface{}{
+		"statusCode": r.SimpleStatusCode(),
+	}
+	if u.Scheme != "" {
+		redirect["scheme"] = u.Scheme
+	}
+	if u.Hostname() != "" {
+		redirect["hostname"] = u.Hostname()
+	}
+	if !r.PreservePath {
+		path := u.Path
+		if path == "" {
+			path = "/"
+		}
+		redirect["path"] = map[string]interface{}{
+			"type":            "ReplaceFullPath",
+			"replaceFullPath": path,
+		}
+	}
+	return redirect
+}
+
+func isAccepted(route *unstructured.Unstructured) bool {
+	parents, _, _ := unstructured.NestedSlice(route.Object, "status", "parents")
+	for _, parent := range parents {
+		parent, ok := parent.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		conditions, _, _ := unstructured.NestedSlice(parent, "conditions")
+		for _, cond := range conditions {
+			cond, ok := cond.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if cond["type"] == "Accepted" && cond["status"] == string(metav1.ConditionTrue) {
+				return true
+			}
+		}
+	}
+	return false
+}
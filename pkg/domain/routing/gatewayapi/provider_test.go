@@ -0,0 +1,257 @@
+package gatewayapi
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/externaldns"
+)
+
+func newRegistration() *domainv1beta1.CustomDomainRegistration {
+	return &domainv1beta1.CustomDomainRegistration{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "example.com", UID: "reg-uid"},
+		Spec: domainv1beta1.CustomDomainRegistrationSpec{
+			DomainName: "example.com",
+			DomainConfig: domainv1beta1.CustomDomainConfig{
+				BackendServiceName: "web",
+				BackendServicePort: 8080,
+			},
+		},
+	}
+}
+
+func newTestProvider(t *testing.T, objs ...runtime.Object) *Provider {
+	p, err := NewProvider(fake.NewFakeClientWithScheme(runtime.NewScheme(), objs...), Config{
+		GatewayNamespace: "gateway",
+		GatewayName:      "public",
+		SectionName:      "https",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func getRoute(t *testing.T, p *Provider) *unstructured.Unstructured {
+	route := &unstructured.Unstructured{}
+	route.SetGroupVersionKind(HTTPRouteGVK)
+	err := p.KubeClient.Get(context.Background(), types.NamespacedName{Namespace: "app", Name: "example.com"}, route)
+	if apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	return route
+}
+
+func rulesOf(t *testing.T, route *unstructured.Unstructured) []interface{} {
+	rules, _, err := unstructured.NestedSlice(route.Object, "spec", "rules")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rules
+}
+
+func TestNewProvider(t *testing.T) {
+	if _, err := NewProvider(nil, Config{}); err == nil {
+		t.Error("expected error without gateway name")
+	}
+}
+
+func TestProvision(t *testing.T) {
+	annotator, err := externaldns.NewAnnotator(externaldns.Config{TTL: 60})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := newTestProvider(t)
+	p.ExternalDNS = annotator
+	reg := newRegistration()
+	ctx := context.Background()
+
+	ready, err := p.Provision(ctx, reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ready {
+		t.Error("created route is ready")
+	}
+
+	route := getRoute(t, p)
+	if route == nil {
+		t.Fatal("route is not created")
+	}
+	if !metav1.IsControlledBy(route, reg) {
+		t.Error("route is not controlled by registration")
+	}
+	expectedAnnotations := map[string]string{
+		externaldns.HostnameAnnotation: "example.com",
+		externaldns.TTLAnnotation:      "60",
+	}
+	if !reflect.DeepEqual(route.GetAnnotations(), expectedAnnotations) {
+		t.Errorf("annotations = %v", route.GetAnnotations())
+	}
+	expectedSpec := map[string]interface{}{
+		"parentRefs": []interface{}{
+			map[string]interface{}{"namespace": "gateway", "name": "public", "sectionName": "https"},
+		},
+		"hostnames": []interface{}{"example.com"},
+		"rules": []interface{}{
+			map[string]interface{}{
+				"backendRefs": []interface{}{
+					map[string]interface{}{"name": "web", "port": int64(8080)},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(route.Object["spec"], expectedSpec) {
+		t.Errorf("spec = %#v", route.Object["spec"])
+	}
+
+	// Ready once accepted by the gateway
+	if ready, err := p.Provision(ctx, reg); err != nil || ready {
+		t.Errorf("Provision = %v, %v", ready, err)
+	}
+	route.Object["status"] = map[string]interface{}{
+		"parents": []interface{}{
+			map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "Accepted", "status": "True"},
+				},
+			},
+		},
+	}
+	if err := p.KubeClient.Update(ctx, route); err != nil {
+		t.Fatal(err)
+	}
+	if ready, err := p.Provision(ctx, reg); err != nil || !ready {
+		t.Errorf("Provision = %v, %v", ready, err)
+	}
+}
+
+func TestProvisionRedirect(t *testing.T) {
+	tests := []struct {
+		name     string
+		redirect domainv1beta1.CustomDomainRedirect
+		expected map[string]interface{}
+	}{
+		{
+			name:     "default status code",
+			redirect: domainv1beta1.CustomDomainRedirect{URL: "https://www.example.com"},
+			expected: map[string]interface{}{
+				"statusCode": int64(302),
+				"scheme":     "https",
+				"hostname":   "www.example.com",
+				"path":       map[string]interface{}{"type": "ReplaceFullPath", "replaceFullPath": "/"},
+			},
+		},
+		{
+			name:     "permanent redirect preserving path",
+			redirect: domainv1beta1.CustomDomainRedirect{URL: "https://www.example.com/", StatusCode: 308, PreservePath: true},
+			expected: map[string]interface{}{
+				"statusCode": int64(301),
+				"scheme":     "https",
+				"hostname":   "www.example.com",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestProvider(t)
+			reg := newRegistration()
+			reg.Spec.Redirect = &tt.redirect
+			if _, err := p.Provision(context.Background(), reg); err != nil {
+				t.Fatal(err)
+			}
+
+			expectedRules := []interface{}{
+				map[string]interface{}{
+					"filters": []interface{}{
+						map[string]interface{}{"type": "RequestRedirect", "requestRedirect": tt.expected},
+					},
+				},
+			}
+			if rules := rulesOf(t, getRoute(t, p)); !reflect.DeepEqual(rules, expectedRules) {
+				t.Errorf("rules = %#v", rules)
+			}
+		})
+	}
+}
+
+func TestProvisionUpdate(t *testing.T) {
+	p := newTestProvider(t)
+	reg := newRegistration()
+	ctx := context.Background()
+	if _, err := p.Provision(ctx, reg); err != nil {
+		t.Fatal(err)
+	}
+
+	reg.Spec.DomainConfig.BackendServicePort = 9090
+	if ready, err := p.Provision(ctx, reg); err != nil || ready {
+		t.Errorf("Provision = %v, %v", ready, err)
+	}
+	expectedRules := []interface{}{
+		map[string]interface{}{
+			"backendRefs": []interface{}{
+				map[string]interface{}{"name": "web", "port": int64(9090)},
+			},
+		},
+	}
+	if rules := rulesOf(t, getRoute(t, p)); !reflect.DeepEqual(rules, expectedRules) {
+		t.Errorf("rules = %#v", rules)
+	}
+}
+
+func TestProvisionInvalidRedirect(t *testing.T) {
+	p := newTestProvider(t)
+	reg := newRegistration()
+	reg.Spec.Redirect = &domainv1beta1.CustomDomainRedirect{URL: "https://%zz"}
+
+	if _, err := p.Provision(context.Background(), reg); err == nil {
+		t.Error("expected error")
+	}
+	if getRoute(t, p) != nil {
+		t.Error("route is created")
+	}
+}
+
+func TestRelease(t *testing.T) {
+	// Route not created by the provider
+	unmanaged := &unstructured.Unstructured{}
+	unmanaged.SetGroupVersionKind(HTTPRouteGVK)
+	unmanaged.SetNamespace("app")
+	unmanaged.SetName("example.com")
+
+	p := newTestProvider(t, unmanaged)
+	reg := newRegistration()
+	ctx := context.Background()
+	if ok, err := p.Release(ctx, reg); err != nil || !ok {
+		t.Errorf("Release = %v, %v", ok, err)
+	}
+	if getRoute(t, p) == nil {
+		t.Error("route not controlled by registration is deleted")
+	}
+
+	p = newTestProvider(t)
+	if _, err := p.Provision(ctx, reg); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := p.Release(ctx, reg); err != nil || !ok {
+		t.Errorf("Release = %v, %v", ok, err)
+	}
+	if getRoute(t, p) != nil {
+		t.Error("route is not deleted")
+	}
+	// Releasing again succeeds without the route
+	if ok, err := p.Release(ctx, reg); err != nil || !ok {
+		t.Errorf("Release = %v, %v", ok, err)
+	}
+}
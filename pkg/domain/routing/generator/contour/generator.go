@@ -0,0 +1,134 @@
+package contour
+
+import (
+	"fmt"
+	"net"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/externaldns"
+	"github.com/skygeario/k8s-controller/pkg/domain/routing"
+	"github.com/skygeario/k8s-controller/pkg/domain/routing/generator"
+)
+
+var HTTPProxyGVK = schema.GroupVersionKind{
+	Group:   "projectcontour.io",
+	Version: "v1",
+	Kind:    "HTTPProxy",
+}
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	_ = domainv1beta1.AddToScheme(scheme)
+}
+
+// Generator generates Contour HTTPProxy for registrations.
+type Generator struct {
+	// ExternalDNS annotates generated proxies for external-dns, if set.
+	ExternalDNS *externaldns.Annotator
+}
+
+func NewGenerator() (*Generator, error) {
+	return &Generator{}, nil
+}
+
+var _ generator.Generator = &Generator{}
+
+func (g *Generator) Kinds() []schema.GroupVersionKind {
+	return []schema.GroupVersionKind{HTTPProxyGVK}
+}
+
+func (g *Generator) Generate(reg *domainv1beta1.CustomDomainRegistration) ([]*unstructured.Unstructured, error) {
+	virtualHost := map[string]interface{}{
+		"fqdn": reg.ASCIIDomainName(),
+	}
+	if reg.Status.CertSecretName != nil {
+		virtualHost["tls"] = map[string]interface{}{
+			"secretName": *reg.Status.CertSecretName,
+		}
+	}
+
+	route := map[string]interface{}{
+		"conditions": []interface{}{
+			map[string]interface{}{"prefix": "/"},
+		},
+	}
+	redirect, err := routing.MakeRedirect(reg)
+	if err != nil {
+		return nil, err
+	}
+	if redirect != nil {
+		policy, err := makeRedirectPolicy(redirect)
+		if err != nil {
+			return nil, err
+		}
+		route["requestRedirectPolicy"] = policy
+	} else {
+		route["services"] = []interface{}{
+			map[string]interface{}{
+				"name": reg.Spec.DomainConfig.BackendServiceName,
+				"port": int64(reg.Spec.DomainConfig.BackendServicePort),
+			},
+		}
+	}
+
+	proxy := &unstructured.Unstructured{}
+	proxy.SetGroupVersionKind(HTTPProxyGVK)
+	proxy.SetNamespace(reg.Namespace)
+	proxy.SetName(reg.Name)
+	proxy.Object["spec"] = map[string]interface{}{
+		"virtualhost": virtualHost,
+		"routes":      []interface{}{route},
+	}
+
+	if g.ExternalDNS != nil {
+		annotations := map[string]string{}
+		if err := g.ExternalDNS.Annotate(reg, annotations); err != nil {
+			return nil, err
+		}
+		proxy.SetAnnotations(annotations)
+	}
+
+	if err := ctrl.SetControllerReference(reg, proxy, scheme); err != nil {
+		return nil, err
+	}
+	return []*unstructured.Unstructured{proxy}, nil
+}
+
+func (g *Generator) IsReady(obj *unstructured.Unstructured) bool {
+	status, _, _ := unstructured.NestedString(obj.Object, "status", "currentStatus")
+	return status == "valid"
+}
+
+func makeRedirectPolicy(r *routing.Redirect) (map[string]interface{}, error) {
+	u := r.URL
+	redirect := map[string]interface{}{
+		"statusCode": r.SimpleStatusCode(),
+	}
+	if u.Scheme != "" {
+		redirect["scheme"] = u.Scheme
+	}
+	if u.Hostname() != "" {
+		redirect["hostname"] = u.Hostname()
+	}
+	if u.Port() != "" {
+		port, err := net.LookupPort("tcp", u.Port())
+		if err != nil {
+			return nil, fmt.Errorf("invalid redirect URL: %w", err)
+		}
+		redirect["port"] = int64(port)
+	}
+	if !r.PreservePath {
+		path := u.Path
+		if path == "" {
+			path = "/"
+		}
+		redirect["path"] = path
+	}
+	return redirect, nil
+}
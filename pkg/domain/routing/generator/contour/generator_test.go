@@ -0,0 +1,220 @@
+package contour
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/externaldns"
+	"github.com/skygeario/k8s-controller/pkg/domain/routing/generator"
+)
+
+func newRegistration() *domainv1beta1.CustomDomainRegistration {
+	return &domainv1beta1.CustomDomainRegistration{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "example.com", UID: "reg-uid"},
+		Spec: domainv1beta1.CustomDomainRegistrationSpec{
+			DomainName: "example.com",
+			DomainConfig: domainv1beta1.CustomDomainConfig{
+				BackendServiceName: "web",
+				BackendServicePort: 8080,
+			},
+		},
+	}
+}
+
+func newTestProvider(t *testing.T, g *Generator, objs ...runtime.Object) *generator.Provider {
+	p, err := generator.NewProvider(fake.NewFakeClientWithScheme(runtime.NewScheme(), objs...), g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func getProxy(t *testing.T, p *generator.Provider) *unstructured.Unstructured {
+	proxy := &unstructured.Unstructured{}
+	proxy.SetGroupVersionKind(HTTPProxyGVK)
+	err := p.KubeClient.Get(context.Background(), types.NamespacedName{Namespace: "app", Name: "example.com"}, proxy)
+	if apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	return proxy
+}
+
+func routesOf(t *testing.T, proxy *unstructured.Unstructured) []interface{} {
+	routes, _, err := unstructured.NestedSlice(proxy.Object, "spec", "routes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return routes
+}
+
+func TestProvision(t *testing.T) {
+	annotator, err := externaldns.NewAnnotator(externaldns.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := newTestProvider(t, &Generator{ExternalDNS: annotator})
+	reg := newRegistration()
+	reg.Status.CertSecretName = pointer.StringPtr("example-com-tls")
+	ctx := context.Background()
+
+	if ready, err := p.Provision(ctx, reg); err != nil || ready {
+		t.Errorf("Provision = %v, %v", ready, err)
+	}
+
+	proxy := getProxy(t, p)
+	if proxy == nil || !metav1.IsControlledBy(proxy, reg) {
+		t.Fatalf("proxy = %v", proxy)
+	}
+	if hostname := proxy.GetAnnotations()[externaldns.HostnameAnnotation]; hostname != "example.com" {
+		t.Errorf("hostname annotation = %q", hostname)
+	}
+	expectedSpec := map[string]interface{}{
+		"virtualhost": map[string]interface{}{
+			"fqdn": "example.com",
+			"tls":  map[string]interface{}{"secretName": "example-com-tls"},
+		},
+		"routes": []interface{}{
+			map[string]interface{}{
+				"conditions": []interface{}{map[string]interface{}{"prefix": "/"}},
+				"services": []interface{}{
+					map[string]interface{}{"name": "web", "port": int64(8080)},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(proxy.Object["spec"], expectedSpec) {
+		t.Errorf("spec = %#v", proxy.Object["spec"])
+	}
+
+	// Ready once proxy is valid
+	if ready, err := p.Provision(ctx, reg); err != nil || ready {
+		t.Errorf("Provision = %v, %v", ready, err)
+	}
+	proxy.Object["status"] = map[string]interface{}{"currentStatus": "valid"}
+	if err := p.KubeClient.Update(ctx, proxy); err != nil {
+		t.Fatal(err)
+	}
+	if ready, err := p.Provision(ctx, reg); err != nil || !ready {
+		t.Errorf("Provision = %v, %v", ready, err)
+	}
+
+	// Proxy is updated when backend changed
+	reg.Spec.DomainConfig.BackendServicePort = 9090
+	if ready, err := p.Provision(ctx, reg); err != nil || ready {
+		t.Errorf("Provision = %v, %v", ready, err)
+	}
+	expectedRoutes := []interface{}{
+		map[string]interface{}{
+			"conditions": []interface{}{map[string]interface{}{"prefix": "/"}},
+			"services": []interface{}{
+				map[string]interface{}{"name": "web", "port": int64(9090)},
+			},
+		},
+	}
+	if routes := routesOf(t, getProxy(t, p)); !reflect.DeepEqual(routes, expectedRoutes) {
+		t.Errorf("routes = %#v", routes)
+	}
+}
+
+func TestProvisionRedirect(t *testing.T) {
+	tests := []struct {
+		name     string
+		redirect domainv1beta1.CustomDomainRedirect
+		expected map[string]interface{}
+	}{
+		{
+			name:     "temporary redirect",
+			redirect: domainv1beta1.CustomDomainRedirect{URL: "https://www.example.com:8443/landing"},
+			expected: map[string]interface{}{
+				"statusCode": int64(302),
+				"scheme":     "https",
+				"hostname":   "www.example.com",
+				"port":       int64(8443),
+				"path":       "/landing",
+			},
+		},
+		{
+			name:     "permanent redirect preserving path",
+			redirect: domainv1beta1.CustomDomainRedirect{URL: "https://www.example.com", StatusCode: 308, PreservePath: true},
+			expected: map[string]interface{}{
+				"statusCode": int64(301),
+				"scheme":     "https",
+				"hostname":   "www.example.com",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestProvider(t, &Generator{})
+			reg := newRegistration()
+			reg.Spec.Redirect = &tt.redirect
+			if _, err := p.Provision(context.Background(), reg); err != nil {
+				t.Fatal(err)
+			}
+
+			expected := []interface{}{
+				map[string]interface{}{
+					"conditions":            []interface{}{map[string]interface{}{"prefix": "/"}},
+					"requestRedirectPolicy": tt.expected,
+				},
+			}
+			if routes := routesOf(t, getProxy(t, p)); !reflect.DeepEqual(routes, expected) {
+				t.Errorf("routes = %#v", routes)
+			}
+		})
+	}
+}
+
+func TestProvisionInvalidRedirect(t *testing.T) {
+	p := newTestProvider(t, &Generator{})
+	reg := newRegistration()
+	reg.Spec.Redirect = &domainv1beta1.CustomDomainRedirect{URL: "https://%zz"}
+
+	if _, err := p.Provision(context.Background(), reg); err == nil {
+		t.Error("expected error")
+	}
+	if getProxy(t, p) != nil {
+		t.Error("proxy is created")
+	}
+}
+
+func TestRelease(t *testing.T) {
+	// Proxy not created by the provider
+	unmanaged := &unstructured.Unstructured{}
+	unmanaged.SetGroupVersionKind(HTTPProxyGVK)
+	unmanaged.SetNamespace("app")
+	unmanaged.SetName("example.com")
+
+	p := newTestProvider(t, &Generator{}, unmanaged)
+	reg := newRegistration()
+	ctx := context.Background()
+	if ok, err := p.Release(ctx, reg); err != nil || !ok {
+		t.Errorf("Release = %v, %v", ok, err)
+	}
+	if getProxy(t, p) == nil {
+		t.Error("proxy not controlled by registration is deleted")
+	}
+
+	p = newTestProvider(t, &Generator{})
+	if _, err := p.Provision(ctx, reg); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := p.Release(ctx, reg); err != nil || !ok {
+		t.Errorf("Release = %v, %v", ok, err)
+	}
+	if getProxy(t, p) != nil {
+		t.Error("proxy is not deleted")
+	}
+}
@@ -0,0 +1,129 @@
+// Package generator provides a routing provider creating native routing
+// objects of ingress controllers, produced by pluggable generators.
+package generator
+
+import (
+	"context"
+	"reflect"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/routing"
+)
+
+type Generator interface {
+	// Kinds returns kinds of objects may be generated, so that they can be
+	// released.
+	Kinds() []schema.GroupVersionKind
+	// Generate generates objects routing traffic of the registration. The
+	// objects must be in namespace of registration and controlled by it.
+	Generate(reg *domainv1beta1.CustomDomainRegistration) ([]*unstructured.Unstructured, error)
+	// IsReady returns whether the generated object is accepted by the
+	// ingress controller.
+	IsReady(obj *unstructured.Unstructured) bool
+}
+
+type Provider struct {
+	KubeClient client.Client
+	Generator  Generator
+}
+
+func NewProvider(client client.Client, generator Generator) (*Provider, error) {
+	return &Provider{
+		KubeClient: client,
+		Generator:  generator,
+	}, nil
+}
+
+var _ routing.Provider = &Provider{}
+
+func (p *Provider) Provision(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (bool, error) {
+	objs, err := p.Generator.Generate(reg)
+	if err != nil {
+		return false, err
+	}
+
+	generated := map[schema.GroupVersionKind]bool{}
+	ready := true
+	for _, obj := range objs {
+		generated[obj.GroupVersionKind()] = true
+		ok, err := p.apply(ctx, obj)
+		if err != nil {
+			return false, err
+		}
+		ready = ready && ok
+	}
+
+	// release objects no longer generated, e.g. redirect middlewares
+	for _, gvk := range p.Generator.Kinds() {
+		if generated[gvk] {
+			continue
+		}
+		if err := p.delete(ctx, reg, gvk); err != nil {
+			return false, err
+		}
+	}
+
+	return ready, nil
+}
+
+func (p *Provider) Release(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (bool, error) {
+	for _, gvk := range p.Generator.Kinds() {
+		if err := p.delete(ctx, reg, gvk); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (p *Provider) apply(ctx context.Context, obj *unstructured.Unstructured) (bool, error) {
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(obj.GroupVersionKind())
+	err := p.KubeClient.Get(ctx, types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}, existing)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return false, err
+	}
+
+	if apierrors.IsNotFound(err) {
+		if err := p.KubeClient.Create(ctx, obj); err != nil {
+			return false, err
+		}
+		return false, nil
+	}
+
+	if !reflect.DeepEqual(existing.Object["spec"], obj.Object["spec"]) ||
+		!reflect.DeepEqual(existing.GetAnnotations(), obj.GetAnnotations()) {
+		existing.Object["spec"] = obj.Object["spec"]
+		existing.SetAnnotations(obj.GetAnnotations())
+		if err := p.KubeClient.Update(ctx, existing); err != nil {
+			return false, err
+		}
+		return false, nil
+	}
+
+	return p.Generator.IsReady(existing), nil
+}
+
+func (p *Provider) delete(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration, gvk schema.GroupVersionKind) error {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	err := p.KubeClient.Get(ctx, types.NamespacedName{Namespace: reg.Namespace, Name: reg.Name}, obj)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	if !metav1.IsControlledBy(obj, reg) {
+		return nil
+	}
+
+	return client.IgnoreNotFound(p.KubeClient.Delete(ctx, obj))
+}
@@ -0,0 +1,139 @@
+package traefik
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/externaldns"
+	"github.com/skygeario/k8s-controller/pkg/domain/routing"
+	"github.com/skygeario/k8s-controller/pkg/domain/routing/generator"
+)
+
+var IngressRouteGVK = schema.GroupVersionKind{
+	Group:   "traefik.containo.us",
+	Version: "v1alpha1",
+	Kind:    "IngressRoute",
+}
+
+var MiddlewareGVK = schema.GroupVersionKind{
+	Group:   "traefik.containo.us",
+	Version: "v1alpha1",
+	Kind:    "Middleware",
+}
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	_ = domainv1beta1.AddToScheme(scheme)
+}
+
+// Generator generates Traefik IngressRoute for registrations. Redirects are
+// implemented using a RedirectRegex Middleware.
+type Generator struct {
+	// ExternalDNS annotates generated routes for external-dns, if set.
+	ExternalDNS *externaldns.Annotator
+}
+
+func NewGenerator() (*Generator, error) {
+	return &Generator{}, nil
+}
+
+var _ generator.Generator = &Generator{}
+
+func (g *Generator) Kinds() []schema.GroupVersionKind {
+	return []schema.GroupVersionKind{IngressRouteGVK, MiddlewareGVK}
+}
+
+func (g *Generator) Generate(reg *domainv1beta1.CustomDomainRegistration) ([]*unstructured.Unstructured, error) {
+	var objs []*unstructured.Unstructured
+
+	route := map[string]interface{}{
+		"kind":  "Rule",
+		"match": fmt.Sprintf("Host(`%s`)", reg.ASCIIDomainName()),
+	}
+	redirect, err := routing.MakeRedirect(reg)
+	if err != nil {
+		return nil, err
+	}
+	if redirect != nil {
+		regex, replacement := "^.*$", escapeReplacement(redirect.URL.String())
+		if redirect.PreservePath {
+			regex, replacement = "^[a-z]+://[^/]+(.*)$", replacement+"${1}"
+		}
+
+		middleware := &unstructured.Unstructured{}
+		middleware.SetGroupVersionKind(MiddlewareGVK)
+		middleware.SetNamespace(reg.Namespace)
+		middleware.SetName(reg.Name)
+		middleware.Object["spec"] = map[string]interface{}{
+			"redirectRegex": map[string]interface{}{
+				"regex":       regex,
+				"replacement": replacement,
+				"permanent":   redirect.IsPermanent(),
+			},
+		}
+		if err := ctrl.SetControllerReference(reg, middleware, scheme); err != nil {
+			return nil, err
+		}
+		objs = append(objs, middleware)
+
+		route["middlewares"] = []interface{}{
+			map[string]interface{}{"name": reg.Name},
+		}
+		// Requests are redirected by middleware before reaching service.
+		route["services"] = []interface{}{
+			map[string]interface{}{"name": "noop@internal", "kind": "TraefikService"},
+		}
+	} else {
+		route["services"] = []interface{}{
+			map[string]interface{}{
+				"name": reg.Spec.DomainConfig.BackendServiceName,
+				"port": int64(reg.Spec.DomainConfig.BackendServicePort),
+			},
+		}
+	}
+
+	spec := map[string]interface{}{
+		"routes": []interface{}{route},
+	}
+	if reg.Status.CertSecretName != nil {
+		spec["tls"] = map[string]interface{}{
+			"secretName": *reg.Status.CertSecretName,
+		}
+	}
+
+	ingressRoute := &unstructured.Unstructured{}
+	ingressRoute.SetGroupVersionKind(IngressRouteGVK)
+	ingressRoute.SetNamespace(reg.Namespace)
+	ingressRoute.SetName(reg.Name)
+	ingressRoute.Object["spec"] = spec
+
+	if g.ExternalDNS != nil {
+		annotations := map[string]string{}
+		if err := g.ExternalDNS.Annotate(reg, annotations); err != nil {
+			return nil, err
+		}
+		ingressRoute.SetAnnotations(annotations)
+	}
+
+	if err := ctrl.SetControllerReference(reg, ingressRoute, scheme); err != nil {
+		return nil, err
+	}
+	return append(objs, ingressRoute), nil
+}
+
+func (g *Generator) IsReady(obj *unstructured.Unstructured) bool {
+	// Traefik does not report status of routes.
+	return true
+}
+
+// escapeReplacement escapes the URL for use as regex replacement.
+func escapeReplacement(s string) string {
+	return strings.ReplaceAll(s, "$", "$$")
+}
@@ -0,0 +1,241 @@
+package traefik
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/externaldns"
+	"github.com/skygeario/k8s-controller/pkg/domain/routing/generator"
+)
+
+func newRegistration() *domainv1beta1.CustomDomainRegistration {
+	return &domainv1beta1.CustomDomainRegistration{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "example.com", UID: "reg-uid"},
+		Spec: domainv1beta1.CustomDomainRegistrationSpec{
+			DomainName: "example.com",
+			DomainConfig: domainv1beta1.CustomDomainConfig{
+				BackendServiceName: "web",
+				BackendServicePort: 8080,
+			},
+		},
+	}
+}
+
+func newTestProvider(t *testing.T, g *Generator, objs ...runtime.Object) *generator.Provider {
+	p, err := generator.NewProvider(fake.NewFakeClientWithScheme(runtime.NewScheme(), objs...), g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func getObject(t *testing.T, p *generator.Provider, gvk schema.GroupVersionKind) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	err := p.KubeClient.Get(context.Background(), types.NamespacedName{Namespace: "app", Name: "example.com"}, obj)
+	if apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	return obj
+}
+
+func TestProvision(t *testing.T) {
+	annotator, err := externaldns.NewAnnotator(externaldns.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := newTestProvider(t, &Generator{ExternalDNS: annotator})
+	reg := newRegistration()
+	reg.Status.CertSecretName = pointer.StringPtr("example-com-tls")
+	ctx := context.Background()
+
+	if ready, err := p.Provision(ctx, reg); err != nil || ready {
+		t.Errorf("Provision = %v, %v", ready, err)
+	}
+
+	route := getObject(t, p, IngressRouteGVK)
+	if route == nil || !metav1.IsControlledBy(route, reg) {
+		t.Fatalf("route = %v", route)
+	}
+	if hostname := route.GetAnnotations()[externaldns.HostnameAnnotation]; hostname != "example.com" {
+		t.Errorf("hostname annotation = %q", hostname)
+	}
+	expectedSpec := map[string]interface{}{
+		"routes": []interface{}{
+			map[string]interface{}{
+				"kind":  "Rule",
+				"match": "Host(`example.com`)",
+				"services": []interface{}{
+					map[string]interface{}{"name": "web", "port": int64(8080)},
+				},
+			},
+		},
+		"tls": map[string]interface{}{"secretName": "example-com-tls"},
+	}
+	if !reflect.DeepEqual(route.Object["spec"], expectedSpec) {
+		t.Errorf("spec = %#v", route.Object["spec"])
+	}
+	if getObject(t, p, MiddlewareGVK) != nil {
+		t.Error("middleware is created without redirect")
+	}
+
+	// Ready once up to date
+	if ready, err := p.Provision(ctx, reg); err != nil || !ready {
+		t.Errorf("Provision = %v, %v", ready, err)
+	}
+}
+
+func TestProvisionRedirect(t *testing.T) {
+	tests := []struct {
+		name     string
+		redirect domainv1beta1.CustomDomainRedirect
+		expected map[string]interface{}
+	}{
+		{
+			name:     "temporary redirect",
+			redirect: domainv1beta1.CustomDomainRedirect{URL: "https://www.example.com/$landing"},
+			expected: map[string]interface{}{
+				"regex":       "^.*$",
+				"replacement": "https://www.example.com/$$landing",
+				"permanent":   false,
+			},
+		},
+		{
+			name:     "permanent redirect preserving path",
+			redirect: domainv1beta1.CustomDomainRedirect{URL: "https://www.example.com", StatusCode: 308, PreservePath: true},
+			expected: map[string]interface{}{
+				"regex":       "^[a-z]+://[^/]+(.*)$",
+				"replacement": "https://www.example.com${1}",
+				"permanent":   true,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestProvider(t, &Generator{})
+			reg := newRegistration()
+			reg.Spec.Redirect = &tt.redirect
+			if _, err := p.Provision(context.Background(), reg); err != nil {
+				t.Fatal(err)
+			}
+
+			middleware := getObject(t, p, MiddlewareGVK)
+			if middleware == nil || !metav1.IsControlledBy(middleware, reg) {
+				t.Fatalf("middleware = %v", middleware)
+			}
+			expectedSpec := map[string]interface{}{"redirectRegex": tt.expected}
+			if !reflect.DeepEqual(middleware.Object["spec"], expectedSpec) {
+				t.Errorf("middleware spec = %#v", middleware.Object["spec"])
+			}
+
+			routes, _, err := unstructured.NestedSlice(getObject(t, p, IngressRouteGVK).Object, "spec", "routes")
+			if err != nil {
+				t.Fatal(err)
+			}
+			expectedRoutes := []interface{}{
+				map[string]interface{}{
+					"kind":        "Rule",
+					"match":       "Host(`example.com`)",
+					"middlewares": []interface{}{map[string]interface{}{"name": "example.com"}},
+					"services": []interface{}{
+						map[string]interface{}{"name": "noop@internal", "kind": "TraefikService"},
+					},
+				},
+			}
+			if !reflect.DeepEqual(routes, expectedRoutes) {
+				t.Errorf("routes = %#v", routes)
+			}
+		})
+	}
+}
+
+func TestProvisionRedirectRemoved(t *testing.T) {
+	p := newTestProvider(t, &Generator{})
+	reg := newRegistration()
+	reg.Spec.Redirect = &domainv1beta1.CustomDomainRedirect{URL: "https://www.example.com"}
+	ctx := context.Background()
+	if _, err := p.Provision(ctx, reg); err != nil {
+		t.Fatal(err)
+	}
+	if getObject(t, p, MiddlewareGVK) == nil {
+		t.Fatal("middleware is not created")
+	}
+
+	reg.Spec.Redirect = nil
+	if ready, err := p.Provision(ctx, reg); err != nil || ready {
+		t.Errorf("Provision = %v, %v", ready, err)
+	}
+	if getObject(t, p, MiddlewareGVK) != nil {
+		t.Error("middleware is not deleted")
+	}
+	routes, _, err := unstructured.NestedSlice(getObject(t, p, IngressRouteGVK).Object, "spec", "routes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := routes[0].(map[string]interface{})["middlewares"]; ok {
+		t.Error("route still references middleware")
+	}
+}
+
+func TestProvisionInvalidRedirect(t *testing.T) {
+	p := newTestProvider(t, &Generator{})
+	reg := newRegistration()
+	reg.Spec.Redirect = &domainv1beta1.CustomDomainRedirect{URL: "https://%zz"}
+
+	if _, err := p.Provision(context.Background(), reg); err == nil {
+		t.Error("expected error")
+	}
+	if getObject(t, p, IngressRouteGVK) != nil || getObject(t, p, MiddlewareGVK) != nil {
+		t.Error("objects are created")
+	}
+}
+
+func TestRelease(t *testing.T) {
+	// Middleware not created by the provider
+	unmanaged := &unstructured.Unstructured{}
+	unmanaged.SetGroupVersionKind(MiddlewareGVK)
+	unmanaged.SetNamespace("app")
+	unmanaged.SetName("example.com")
+
+	p := newTestProvider(t, &Generator{}, unmanaged)
+	reg := newRegistration()
+	ctx := context.Background()
+	// Unmanaged middleware is kept even if no longer generated
+	if _, err := p.Provision(ctx, reg); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := p.Release(ctx, reg); err != nil || !ok {
+		t.Errorf("Release = %v, %v", ok, err)
+	}
+	if getObject(t, p, MiddlewareGVK) == nil {
+		t.Error("middleware not controlled by registration is deleted")
+	}
+	if getObject(t, p, IngressRouteGVK) != nil {
+		t.Error("route is not deleted")
+	}
+
+	p = newTestProvider(t, &Generator{})
+	reg.Spec.Redirect = &domainv1beta1.CustomDomainRedirect{URL: "https://www.example.com"}
+	if _, err := p.Provision(ctx, reg); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := p.Release(ctx, reg); err != nil || !ok {
+		t.Errorf("Release = %v, %v", ok, err)
+	}
+	if getObject(t, p, IngressRouteGVK) != nil || getObject(t, p, MiddlewareGVK) != nil {
+		t.Error("generated objects are not deleted")
+	}
+}
@@ -0,0 +1,79 @@
+package ingress
+
+import (
+	"context"
+
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/ingress"
+	"github.com/skygeario/k8s-controller/pkg/domain/routing"
+)
+
+type Provider struct {
+	KubeClient client.Client
+	Ingress    ingress.Provider
+}
+
+func NewProvider(client client.Client, ingress ingress.Provider) (*Provider, error) {
+	return &Provider{
+		KubeClient: client,
+		Ingress:    ingress,
+	}, nil
+}
+
+var _ routing.Provider = &Provider{}
+
+func (p *Provider) Provision(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (bool, error) {
+	ingress, err := p.Ingress.MakeIngress(reg)
+	if err != nil {
+		return false, err
+	}
+
+	existingIngress := &networkingv1beta1.Ingress{}
+	if err = p.KubeClient.Get(ctx, types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}, existingIngress); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return false, err
+		}
+	}
+
+	if apierrors.IsNotFound(err) {
+		if err = p.KubeClient.Create(ctx, ingress); err != nil {
+			return false, err
+		}
+	} else {
+		existingIngress = existingIngress.DeepCopy()
+		existingIngress.Labels = ingress.Labels
+		existingIngress.Annotations = ingress.Annotations
+		existingIngress.Spec = ingress.Spec
+		if err = p.KubeClient.Update(ctx, existingIngress); err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+func (p *Provider) Release(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (bool, error) {
+	ingress, err := p.Ingress.MakeIngress(reg)
+	if err != nil {
+		return false, err
+	}
+
+	existingIngress := &networkingv1beta1.Ingress{}
+	if err = p.KubeClient.Get(ctx, types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}, existingIngress); err != nil {
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}
+
+	if err = p.KubeClient.Delete(ctx, existingIngress); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,7 @@
+package istio
+
+type Config struct {
+	// GatewaySelector is the label selector of Istio ingress gateway
+	// workloads. Defaults to {"istio": "ingressgateway"}.
+	GatewaySelector map[string]string
+}
@@ -0,0 +1,253 @@
+package istio
+
+import (
+	"context"
+	"reflect"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/externaldns"
+	"github.com/skygeario/k8s-controller/pkg/domain/routing"
+)
+
+var GatewayGVK = schema.GroupVersionKind{
+	Group:   "networking.istio.io",
+	Version: "v1beta1",
+	Kind:    "Gateway",
+}
+
+var VirtualServiceGVK = schema.GroupVersionKind{
+	Group:   "networking.istio.io",
+	Version: "v1beta1",
+	Kind:    "VirtualService",
+}
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	_ = domainv1beta1.AddToScheme(scheme)
+}
+
+type Provider struct {
+	KubeClient client.Client
+	Config     Config
+	// ExternalDNS annotates generated gateways for external-dns, if set.
+	ExternalDNS *externaldns.Annotator
+}
+
+func NewProvider(client client.Client, config Config) (*Provider, error) {
+	if len(config.GatewaySelector) == 0 {
+		config.GatewaySelector = map[string]string{"istio": "ingressgateway"}
+	}
+	return &Provider{
+		KubeClient: client,
+		Config:     config,
+	}, nil
+}
+
+var _ routing.Provider = &Provider{}
+
+func (p *Provider) Provision(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (bool, error) {
+	gateway, err := p.makeGateway(reg)
+	if err != nil {
+		return false, err
+	}
+	virtualService, err := p.makeVirtualService(reg)
+	if err != nil {
+		return false, err
+	}
+
+	ready := true
+	for _, obj := range []*unstructured.Unstructured{gateway, virtualService} {
+		ok, err := p.apply(ctx, obj)
+		if err != nil {
+			return false, err
+		}
+		ready = ready && ok
+	}
+	return ready, nil
+}
+
+func (p *Provider) Release(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (bool, error) {
+	for _, gvk := range []schema.GroupVersionKind{VirtualServiceGVK, GatewayGVK} {
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(gvk)
+		err := p.KubeClient.Get(ctx, types.NamespacedName{Namespace: reg.Namespace, Name: reg.Name}, obj)
+		if apierrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return false, err
+		}
+
+		if !metav1.IsControlledBy(obj, reg) {
+			continue
+		}
+
+		if err := p.KubeClient.Delete(ctx, obj); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// apply creates or updates the object, and returns whether the object is
+// up to date.
+func (p *Provider) apply(ctx context.Context, obj *unstructured.Unstructured) (bool, error) {
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(obj.GroupVersionKind())
+	err := p.KubeClient.Get(ctx, types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}, existing)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return false, err
+	}
+
+	if apierrors.IsNotFound(err) {
+		if err := p.KubeClient.Create(ctx, obj); err != nil {
+			return false, err
+		}
+		return false, nil
+	}
+
+	if !reflect.DeepEqual(existing.Object["spec"], obj.Object["spec"]) ||
+		!reflect.DeepEqual(existing.GetAnnotations(), obj.GetAnnotations()) {
+		existing.Object["spec"] = obj.Object["spec"]
+		existing.SetAnnotations(obj.GetAnnotations())
+		if err := p.KubeClient.Update(ctx, existing); err != nil {
+			return false, err
+		}
+		return false, nil
+	}
+
+	return true, nil
+}
+
+func (p *Provider) makeGateway(reg *domainv1beta1.CustomDomainRegistration) (*unstructured.Unstructured, error) {
+	host := reg.ASCIIDomainName()
+	selector := map[string]interface{}{}
+	for k, v := range p.Config.GatewaySelector {
+		selector[k] = v
+	}
+
+	httpServer := map[string]interface{}{
+		"port": map[string]interface{}{
+			"number":   int64(80),
+			"name":     "http",
+			"protocol": "HTTP",
+		},
+		"hosts": []interface{}{host},
+	}
+	servers := []interface{}{httpServer}
+	if reg.Status.CertSecretName != nil {
+		httpServer["tls"] = map[string]interface{}{
+			"httpsRedirect": true,
+		}
+		servers = append(servers, map[string]interface{}{
+			"port": map[string]interface{}{
+				"number":   int64(443),
+				"name":     "https",
+				"protocol": "HTTPS",
+			},
+			"hosts": []interface{}{host},
+			"tls": map[string]interface{}{
+				"mode":           "SIMPLE",
+				"credentialName": *reg.Status.CertSecretName,
+			},
+		})
+	}
+
+	gateway := &unstructured.Unstructured{}
+	gateway.SetGroupVersionKind(GatewayGVK)
+	gateway.SetNamespace(reg.Namespace)
+	gateway.SetName(reg.Name)
+	gateway.Object["spec"] = map[string]interface{}{
+		"selector": selector,
+		"servers":  servers,
+	}
+
+	if p.ExternalDNS != nil {
+		annotations := map[string]string{}
+		if err := p.ExternalDNS.Annotate(reg, annotations); err != nil {
+			return nil, err
+		}
+		gateway.SetAnnotations(annotations)
+	}
+
+	if err := ctrl.SetControllerReference(reg, gateway, scheme); err != nil {
+		return nil, err
+	}
+	return gateway, nil
+}
+
+func (p *Provider) makeVirtualService(reg *domainv1beta1.CustomDomainRegistration) (*unstructured.Unstructured, error) {
+	redirect, err := routing.MakeRedirect(reg)
+	if err != nil {
+		return nil, err
+	}
+
+	var route map[string]interface{}
+	if redirect != nil {
+		route = map[string]interface{}{
+			"redirect": makeRedirect(redirect),
+		}
+	} else {
+		route = map[string]interface{}{
+			"route": []interface{}{
+				map[string]interface{}{
+					"destination": map[string]interface{}{
+						"host": reg.Spec.DomainConfig.BackendServiceName,
+						"port": map[string]interface{}{
+							"number": int64(reg.Spec.DomainConfig.BackendServicePort),
+						},
+					},
+				},
+			},
+		}
+	}
+
+	virtualService := &unstructured.Unstructured{}
+	virtualService.SetGroupVersionKind(VirtualServiceGVK)
+	virtualService.SetNamespace(reg.Namespace)
+	virtualService.SetName(reg.Name)
+	virtualService.Object["spec"] = map[string]interface{}{
+		"hosts":    []interface{}{reg.ASCIIDomainName()},
+		"gateways": []interface{}{reg.Name},
+		"http":     []interface{}{route},
+	}
+
+	if err := ctrl.SetControllerReference(reg, virtualService, scheme); err != nil {
+		return nil, err
+	}
+	return virtualService, nil
+}
+
+func makeRedirect(r *routing.Redirect) map[string]interface{} {
+	u := r.URL
+	code := int64(domainv1beta1.DefaultRedirectStatusCode)
+	if r.StatusCode != 0 {
+		code = int64(r.StatusCode)
+	}
+	redirect := map[string]interface{}{
+		"redirectCode": code,
+	}
+	if u.Scheme != "" {
+		redirect["scheme"] = u.Scheme
+	}
+	if u.Host != "" {
+		redirect["authority"] = u.Host
+	}
+	if !r.PreservePath {
+		path := u.Path
+		if path == "" {
+			path = "/"
+		}
+		redirect["uri"] = path
+	}
+	return redirect
+}
@@ -0,0 +1,239 @@
+package istio
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/externaldns"
+)
+
+func newRegistration() *domainv1beta1.CustomDomainRegistration {
+	return &domainv1beta1.CustomDomainRegistration{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "example.com", UID: "reg-uid"},
+		Spec: domainv1beta1.CustomDomainRegistrationSpec{
+			DomainName: "example.com",
+			DomainConfig: domainv1beta1.CustomDomainConfig{
+				BackendServiceName: "web",
+				BackendServicePort: 8080,
+			},
+		},
+	}
+}
+
+func newTestProvider(t *testing.T, objs ...runtime.Object) *Provider {
+	p, err := NewProvider(fake.NewFakeClientWithScheme(runtime.NewScheme(), objs...), Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func getObject(t *testing.T, p *Provider, gvk schema.GroupVersionKind) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	err := p.KubeClient.Get(context.Background(), types.NamespacedName{Namespace: "app", Name: "example.com"}, obj)
+	if apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	return obj
+}
+
+func TestProvision(t *testing.T) {
+	annotator, err := externaldns.NewAnnotator(externaldns.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := newTestProvider(t)
+	p.ExternalDNS = annotator
+	reg := newRegistration()
+	ctx := context.Background()
+
+	if ready, err := p.Provision(ctx, reg); err != nil || ready {
+		t.Errorf("Provision = %v, %v", ready, err)
+	}
+
+	gateway := getObject(t, p, GatewayGVK)
+	if gateway == nil || !metav1.IsControlledBy(gateway, reg) {
+		t.Fatalf("gateway = %v", gateway)
+	}
+	if hostname := gateway.GetAnnotations()[externaldns.HostnameAnnotation]; hostname != "example.com" {
+		t.Errorf("hostname annotation = %q", hostname)
+	}
+	expectedGatewaySpec := map[string]interface{}{
+		"selector": map[string]interface{}{"istio": "ingressgateway"},
+		"servers": []interface{}{
+			map[string]interface{}{
+				"port":  map[string]interface{}{"number": int64(80), "name": "http", "protocol": "HTTP"},
+				"hosts": []interface{}{"example.com"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(gateway.Object["spec"], expectedGatewaySpec) {
+		t.Errorf("gateway spec = %#v", gateway.Object["spec"])
+	}
+
+	virtualService := getObject(t, p, VirtualServiceGVK)
+	if virtualService == nil || !metav1.IsControlledBy(virtualService, reg) {
+		t.Fatalf("virtual service = %v", virtualService)
+	}
+	expectedVirtualServiceSpec := map[string]interface{}{
+		"hosts":    []interface{}{"example.com"},
+		"gateways": []interface{}{"example.com"},
+		"http": []interface{}{
+			map[string]interface{}{
+				"route": []interface{}{
+					map[string]interface{}{
+						"destination": map[string]interface{}{
+							"host": "web",
+							"port": map[string]interface{}{"number": int64(8080)},
+						},
+					},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(virtualService.Object["spec"], expectedVirtualServiceSpec) {
+		t.Errorf("virtual service spec = %#v", virtualService.Object["spec"])
+	}
+
+	// Ready once up to date
+	if ready, err := p.Provision(ctx, reg); err != nil || !ready {
+		t.Errorf("Provision = %v, %v", ready, err)
+	}
+}
+
+func TestProvisionTLS(t *testing.T) {
+	p := newTestProvider(t)
+	reg := newRegistration()
+	ctx := context.Background()
+	if _, err := p.Provision(ctx, reg); err != nil {
+		t.Fatal(err)
+	}
+
+	// Gateway is updated once certificate is issued
+	reg.Status.CertSecretName = pointer.StringPtr("example-com-tls")
+	if ready, err := p.Provision(ctx, reg); err != nil || ready {
+		t.Errorf("Provision = %v, %v", ready, err)
+	}
+	servers, _, err := unstructured.NestedSlice(getObject(t, p, GatewayGVK).Object, "spec", "servers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedServers := []interface{}{
+		map[string]interface{}{
+			"port":  map[string]interface{}{"number": int64(80), "name": "http", "protocol": "HTTP"},
+			"hosts": []interface{}{"example.com"},
+			"tls":   map[string]interface{}{"httpsRedirect": true},
+		},
+		map[string]interface{}{
+			"port":  map[string]interface{}{"number": int64(443), "name": "https", "protocol": "HTTPS"},
+			"hosts": []interface{}{"example.com"},
+			"tls":   map[string]interface{}{"mode": "SIMPLE", "credentialName": "example-com-tls"},
+		},
+	}
+	if !reflect.DeepEqual(servers, expectedServers) {
+		t.Errorf("servers = %#v", servers)
+	}
+}
+
+func TestProvisionRedirect(t *testing.T) {
+	tests := []struct {
+		name     string
+		redirect domainv1beta1.CustomDomainRedirect
+		expected map[string]interface{}
+	}{
+		{
+			name:     "default status code",
+			redirect: domainv1beta1.CustomDomainRedirect{URL: "https://www.example.com:8443/landing"},
+			expected: map[string]interface{}{
+				"redirectCode": int64(302),
+				"scheme":       "https",
+				"authority":    "www.example.com:8443",
+				"uri":          "/landing",
+			},
+		},
+		{
+			name:     "preserving path",
+			redirect: domainv1beta1.CustomDomainRedirect{URL: "https://www.example.com", StatusCode: 308, PreservePath: true},
+			expected: map[string]interface{}{
+				"redirectCode": int64(308),
+				"scheme":       "https",
+				"authority":    "www.example.com",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestProvider(t)
+			reg := newRegistration()
+			reg.Spec.Redirect = &tt.redirect
+			if _, err := p.Provision(context.Background(), reg); err != nil {
+				t.Fatal(err)
+			}
+
+			routes, _, err := unstructured.NestedSlice(getObject(t, p, VirtualServiceGVK).Object, "spec", "http")
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := []interface{}{map[string]interface{}{"redirect": tt.expected}}
+			if !reflect.DeepEqual(routes, expected) {
+				t.Errorf("routes = %#v", routes)
+			}
+		})
+	}
+}
+
+func TestProvisionInvalidRedirect(t *testing.T) {
+	p := newTestProvider(t)
+	reg := newRegistration()
+	reg.Spec.Redirect = &domainv1beta1.CustomDomainRedirect{URL: "https://%zz"}
+
+	if _, err := p.Provision(context.Background(), reg); err == nil {
+		t.Error("expected error")
+	}
+	if getObject(t, p, GatewayGVK) != nil {
+		t.Error("gateway is created")
+	}
+}
+
+func TestRelease(t *testing.T) {
+	// Gateway not created by the provider
+	unmanaged := &unstructured.Unstructured{}
+	unmanaged.SetGroupVersionKind(GatewayGVK)
+	unmanaged.SetNamespace("app")
+	unmanaged.SetName("example.com")
+
+	p := newTestProvider(t, unmanaged)
+	reg := newRegistration()
+	ctx := context.Background()
+	if ok, err := p.Release(ctx, reg); err != nil || !ok {
+		t.Errorf("Release = %v, %v", ok, err)
+	}
+	if getObject(t, p, GatewayGVK) == nil {
+		t.Error("gateway not controlled by registration is deleted")
+	}
+
+	p = newTestProvider(t)
+	if _, err := p.Provision(ctx, reg); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := p.Release(ctx, reg); err != nil || !ok {
+		t.Errorf("Release = %v, %v", ok, err)
+	}
+	if getObject(t, p, GatewayGVK) != nil || getObject(t, p, VirtualServiceGVK) != nil {
+		t.Error("generated objects are not deleted")
+	}
+}
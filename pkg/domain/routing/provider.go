@@ -0,0 +1,12 @@
+package routing
+
+import (
+	"context"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+)
+
+type Provider interface {
+	Provision(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (ready bool, err error)
+	Release(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (ok bool, err error)
+}
@@ -0,0 +1,56 @@
+package routing
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+)
+
+// Redirect is the redirect to be rendered by routing providers.
+type Redirect struct {
+	// URL is the parsed target URL.
+	URL *url.URL
+	// StatusCode is the HTTP status code of redirect, zero if not specified.
+	StatusCode int
+	// PreservePath indicates the request path should be kept, in which case
+	// the target URL has no path.
+	PreservePath bool
+}
+
+// MakeRedirect returns the redirect of the registration, or nil if requests
+// are served by backend Service.
+func MakeRedirect(reg *domainv1beta1.CustomDomainRegistration) (*Redirect, error) {
+	config := reg.RedirectConfig()
+	if config == nil {
+		return nil, nil
+	}
+	u, err := url.Parse(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redirect URL: %w", err)
+	}
+	if config.PreservePath {
+		u.Path = strings.TrimSuffix(u.Path, "/")
+	}
+	return &Redirect{
+		URL:          u,
+		StatusCode:   config.StatusCode,
+		PreservePath: config.PreservePath,
+	}, nil
+}
+
+// IsPermanent returns whether the redirect is permanent, for routing
+// backends supporting only permanent (301) and temporary (302) redirects.
+func (r *Redirect) IsPermanent() bool {
+	return r.StatusCode == 301 || r.StatusCode == 308
+}
+
+// SimpleStatusCode returns 301 or 302, for routing backends supporting
+// only these status codes.
+func (r *Redirect) SimpleStatusCode() int64 {
+	if r.IsPermanent() {
+		return 301
+	}
+	return 302
+}
@@ -0,0 +1,622 @@
+// Package acmetest provides an in-memory ACME server implementing the subset
+// of RFC 8555 used by the ACME TLS provider, for testing configurations
+// without a real certificate authority.
+package acmetest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	statusPending = "pending"
+	statusReady   = "ready"
+	statusValid   = "valid"
+	statusInvalid = "invalid"
+)
+
+// DefaultCertificateLifetime is the default lifetime of issued certificates.
+const DefaultCertificateLifetime = 90 * 24 * time.Hour
+
+// ChallengeValidator validates the HTTP-01 challenge of the domain, by
+// checking the key authorization is served for the token.
+type ChallengeValidator func(domain string, token string, keyAuthorization string) error
+
+// DNS01ChallengeValidator validates the DNS-01 challenge of the domain, by
+// checking the TXT record at _acme-challenge of the domain has the value.
+type DNS01ChallengeValidator func(domain string, value string) error
+
+const (
+	challengeHTTP01 = "http-01"
+	challengeDNS01  = "dns-01"
+)
+
+type account struct {
+	url        string
+	thumbprint string
+	contact    []string
+}
+
+type authorization struct {
+	url        string
+	domain     string
+	wildcard   bool
+	status     string
+	challenges []*challenge
+	thumbprint string
+}
+
+type challenge struct {
+	url    string
+	typ    string
+	token  string
+	status string
+	err    string
+	authz  *authorization
+}
+
+type order struct {
+	url            string
+	status         string
+	domains        []string
+	authorizations []*authorization
+	certURL        string
+	cert           []byte
+}
+
+// Server is an in-memory ACME server. Challenges are accepted without
+// validation, unless validator of the challenge type is set. Authorizations
+// of wildcard domains offer DNS-01 challenges only.
+type Server struct {
+	// Validator validates HTTP-01 challenges, if not nil.
+	Validator ChallengeValidator
+	// DNS01Validator validates DNS-01 challenges, if not nil.
+	DNS01Validator DNS01ChallengeValidator
+	// CertificateLifetime is the lifetime of issued certificates.
+	CertificateLifetime time.Duration
+
+	server *httptest.Server
+	caKey  *ecdsa.PrivateKey
+	caCert *x509.Certificate
+
+	lock     sync.Mutex
+	nextID   int
+	accounts map[string]*account
+	authzs   map[string]*authorization
+	chals    map[string]*challenge
+	orders   map[string]*order
+	certs    map[string]*order
+}
+
+// NewServer starts an in-memory ACME server. It should be closed after use.
+func NewServer() (*Server, error) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "acmetest root CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(10 * 365 * 24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, template, template, caKey.Public(), caKey)
+	if err != nil {
+		return nil, err
+	}
+	caCert, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		CertificateLifetime: DefaultCertificateLifetime,
+		caKey:               caKey,
+		caCert:              caCert,
+		accounts:            map[string]*account{},
+		authzs:              map[string]*authorization{},
+		chals:               map[string]*challenge{},
+		orders:              map[string]*order{},
+		certs:               map[string]*order{},
+	}
+	s.server = httptest.NewServer(s)
+	return s, nil
+}
+
+// DirectoryURL returns the ACME directory URL of the server.
+func (s *Server) DirectoryURL() string {
+	return s.server.URL + "/directory"
+}
+
+// CACertificate returns the CA certificate signing issued certificates.
+func (s *Server) CACertificate() *x509.Certificate {
+	return s.caCert
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+func (s *Server) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	rw.Header().Set("Replay-Nonce", newNonce())
+	rw.Header().Set("Cache-Control", "no-store")
+
+	switch {
+	case r.URL.Path == "/directory":
+		s.handleDirectory(rw)
+		return
+	case r.URL.Path == "/new-nonce":
+		rw.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		writeProblem(rw, http.StatusMethodNotAllowed, "malformed", "method not allowed")
+		return
+	}
+	req, err := parseJWS(r)
+	if err != nil {
+		writeProblem(rw, http.StatusBadRequest, "malformed", err.Error())
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if r.URL.Path == "/new-account" {
+		s.handleNewAccount(rw, req)
+		return
+	}
+
+	acct, ok := s.accounts[req.kid]
+	if !ok {
+		writeProblem(rw, http.StatusBadRequest, "accountDoesNotExist", "unknown account")
+		return
+	}
+
+	url := s.server.URL + r.URL.Path
+	switch {
+	case r.URL.Path == "/new-order":
+		s.handleNewOrder(rw, acct, req)
+	case strings.HasPrefix(r.URL.Path, "/authz/"):
+		s.handleAuthorization(rw, url)
+	case strings.HasPrefix(r.URL.Path, "/chal/"):
+		s.handleChallenge(rw, url)
+	case strings.HasPrefix(r.URL.Path, "/order/") && strings.HasSuffix(r.URL.Path, "/finalize"):
+		s.handleFinalize(rw, strings.TrimSuffix(url, "/finalize"), req)
+	case strings.HasPrefix(r.URL.Path, "/order/"):
+		s.handleOrder(rw, url)
+	case strings.HasPrefix(r.URL.Path, "/cert/"):
+		s.handleCertificate(rw, url)
+	case strings.HasPrefix(r.URL.Path, "/account/"):
+		s.writeAccount(rw, http.StatusOK, acct)
+	default:
+		writeProblem(rw, http.StatusNotFound, "malformed", "not found")
+	}
+}
+
+func (s *Server) handleDirectory(rw http.ResponseWriter) {
+	writeJSON(rw, http.StatusOK, map[string]interface{}{
+		"newNonce":   s.server.URL + "/new-nonce",
+		"newAccount": s.server.URL + "/new-account",
+		"newOrder":   s.server.URL + "/new-order",
+		"revokeCert": s.server.URL + "/revoke-cert",
+		"keyChange":  s.server.URL + "/key-change",
+		"meta": map[string]interface{}{
+			"termsOfService": s.server.URL + "/terms",
+		},
+	})
+}
+
+func (s *Server) handleNewAccount(rw http.ResponseWriter, req *jwsRequest) {
+	if req.jwk == nil {
+		writeProblem(rw, http.StatusBadRequest, "malformed", "missing JWK")
+		return
+	}
+	thumbprint, err := jwkThumbprint(req.jwk)
+	if err != nil {
+		writeProblem(rw, http.StatusBadRequest, "badPublicKey", err.Error())
+		return
+	}
+	for _, acct := range s.accounts {
+		if acct.thumbprint == thumbprint {
+			s.writeAccount(rw, http.StatusOK, acct)
+			return
+		}
+	}
+
+	var payload struct {
+		Contact            []string `json:"contact"`
+		OnlyReturnExisting bool     `json:"onlyReturnExisting"`
+	}
+	_ = json.Unmarshal(req.payload, &payload)
+	if payload.OnlyReturnExisting {
+		writeProblem(rw, http.StatusBadRequest, "accountDoesNotExist", "account does not exist")
+		return
+	}
+
+	acct := &account{
+		url:        s.newURL("account"),
+		thumbprint: thumbprint,
+		contact:    payload.Contact,
+	}
+	s.accounts[acct.url] = acct
+	s.writeAccount(rw, http.StatusCreated, acct)
+}
+
+func (s *Server) writeAccount(rw http.ResponseWriter, status int, acct *account) {
+	rw.Header().Set("Location", acct.url)
+	writeJSON(rw, status, map[string]interface{}{
+		"status":  statusValid,
+		"contact": acct.contact,
+		"orders":  acct.url + "/orders",
+	})
+}
+
+func (s *Server) handleNewOrder(rw http.ResponseWriter, acct *account, req *jwsRequest) {
+	var payload struct {
+		Identifiers []struct {
+			Type  string `json:"type"`
+			Value string `json:"value"`
+		} `json:"identifiers"`
+	}
+	if err := json.Unmarshal(req.payload, &payload); err != nil || len(payload.Identifiers) == 0 {
+		writeProblem(rw, http.StatusBadRequest, "malformed", "invalid order")
+		return
+	}
+
+	o := &order{url: s.newURL("order"), status: statusPending}
+	for _, id := range payload.Identifiers {
+		if id.Type != "dns" {
+			writeProblem(rw, http.StatusBadRequest, "unsupportedIdentifier", "unsupported identifier type "+id.Type)
+			return
+		}
+		authz := &authorization{
+			url:        s.newURL("authz"),
+			domain:     strings.TrimPrefix(id.Value, "*."),
+			wildcard:   strings.HasPrefix(id.Value, "*."),
+			status:     statusPending,
+			thumbprint: acct.thumbprint,
+		}
+		types := []string{challengeHTTP01, challengeDNS01}
+		if authz.wildcard {
+			types = []string{challengeDNS01}
+		}
+		for _, typ := range types {
+			chal := &challenge{
+				url:    s.newURL("chal"),
+				typ:    typ,
+				token:  newNonce(),
+				status: statusPending,
+				authz:  authz,
+			}
+			authz.challenges = append(authz.challenges, chal)
+			s.chals[chal.url] = chal
+		}
+		s.authzs[authz.url] = authz
+		o.domains = append(o.domains, id.Value)
+		o.authorizations = append(o.authorizations, authz)
+	}
+	s.orders[o.url] = o
+	s.writeOrder(rw, http.StatusCreated, o)
+}
+
+func (s *Server) handleAuthorization(rw http.ResponseWriter, url string) {
+	authz, ok := s.authzs[url]
+	if !ok {
+		writeProblem(rw, http.StatusNotFound, "malformed", "authorization not found")
+		return
+	}
+	var challenges []interface{}
+	for _, chal := range authz.challenges {
+		challenges = append(challenges, challengeJSON(chal))
+	}
+	writeJSON(rw, http.StatusOK, map[string]interface{}{
+		"identifier": map[string]string{"type": "dns", "value": authz.domain},
+		"status":     authz.status,
+		"expires":    time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339),
+		"wildcard":   authz.wildcard,
+		"challenges": challenges,
+	})
+}
+
+func (s *Server) handleChallenge(rw http.ResponseWriter, url string) {
+	chal, ok := s.chals[url]
+	if !ok {
+		writeProblem(rw, http.StatusNotFound, "malformed", "challenge not found")
+		return
+	}
+	if chal.status == statusPending {
+		authz := chal.authz
+		keyAuthorization := chal.token + "." + authz.thumbprint
+		var err error
+		switch {
+		case chal.typ == challengeHTTP01 && s.Validator != nil:
+			err = s.Validator(authz.domain, chal.token, keyAuthorization)
+		case chal.typ == challengeDNS01 && s.DNS01Validator != nil:
+			digest := sha256.Sum256([]byte(keyAuthorization))
+			err = s.DNS01Validator(authz.domain, base64.RawURLEncoding.EncodeToString(digest[:]))
+		}
+		if err != nil {
+			chal.status = statusInvalid
+			chal.err = err.Error()
+			authz.status = statusInvalid
+		} else {
+			chal.status = statusValid
+			authz.status = statusValid
+		}
+		s.updateOrders()
+	}
+	rw.Header().Set("Link", fmt.Sprintf("<%s>;rel=\"up\"", chal.authz.url))
+	writeJSON(rw, http.StatusOK, challengeJSON(chal))
+}
+
+func (s *Server) handleOrder(rw http.ResponseWriter, url string) {
+	o, ok := s.orders[url]
+	if !ok {
+		writeProblem(rw, http.StatusNotFound, "malformed", "order not found")
+		return
+	}
+	s.writeOrder(rw, http.StatusOK, o)
+}
+
+func (s *Server) handleFinalize(rw http.ResponseWriter, url string, req *jwsRequest) {
+	o, ok := s.orders[url]
+	if !ok {
+		writeProblem(rw, http.StatusNotFound, "malformed", "order not found")
+		return
+	}
+	if o.status != statusReady {
+		writeProblem(rw, http.StatusForbidden, "orderNotReady", "order is "+o.status)
+		return
+	}
+
+	var payload struct {
+		CSR string `json:"csr"`
+	}
+	if err := json.Unmarshal(req.payload, &payload); err != nil {
+		writeProblem(rw, http.StatusBadRequest, "malformed", "invalid finalize request")
+		return
+	}
+	der, err := base64.RawURLEncoding.DecodeString(payload.CSR)
+	if err != nil {
+		writeProblem(rw, http.StatusBadRequest, "badCSR", "invalid CSR encoding")
+		return
+	}
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil || csr.CheckSignature() != nil {
+		writeProblem(rw, http.StatusBadRequest, "badCSR", "invalid CSR")
+		return
+	}
+	if !sameNames(csr.DNSNames, o.domains) {
+		writeProblem(rw, http.StatusBadRequest, "badCSR", "CSR names do not match order identifiers")
+		return
+	}
+
+	cert, err := s.issue(csr)
+	if err != nil {
+		writeProblem(rw, http.StatusInternalServerError, "serverInternal", err.Error())
+		return
+	}
+	o.cert = cert
+	o.certURL = s.newURL("cert")
+	o.status = statusValid
+	s.certs[o.certURL] = o
+	s.writeOrder(rw, http.StatusOK, o)
+}
+
+func (s *Server) handleCertificate(rw http.ResponseWriter, url string) {
+	o, ok := s.certs[url]
+	if !ok {
+		writeProblem(rw, http.StatusNotFound, "malformed", "certificate not found")
+		return
+	}
+	rw.Header().Set("Content-Type", "application/pem-certificate-chain")
+	rw.WriteHeader(http.StatusOK)
+	_ = pem.Encode(rw, &pem.Block{Type: "CERTIFICATE", Bytes: o.cert})
+	_ = pem.Encode(rw, &pem.Block{Type: "CERTIFICATE", Bytes: s.caCert.Raw})
+}
+
+func (s *Server) writeOrder(rw http.ResponseWriter, status int, o *order) {
+	var identifiers []map[string]string
+	for _, d := range o.domains {
+		identifiers = append(identifiers, map[string]string{"type": "dns", "value": d})
+	}
+	var authzURLs []string
+	for _, authz := range o.authorizations {
+		authzURLs = append(authzURLs, authz.url)
+	}
+	body := map[string]interface{}{
+		"status":         o.status,
+		"expires":        time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339),
+		"identifiers":    identifiers,
+		"authorizations": authzURLs,
+		"finalize":       o.url + "/finalize",
+	}
+	if o.certURL != "" {
+		body["certificate"] = o.certURL
+	}
+	rw.Header().Set("Location", o.url)
+	writeJSON(rw, status, body)
+}
+
+// updateOrders updates status of pending orders from their authorizations.
+func (s *Server) updateOrders() {
+	for _, o := range s.orders {
+		if o.status != statusPending {
+			continue
+		}
+		ready := true
+		for _, authz := range o.authorizations {
+			switch authz.status {
+			case statusInvalid:
+				o.status = statusInvalid
+			case statusPending:
+				ready = false
+			}
+		}
+		if o.status == statusPending && ready {
+			o.status = statusReady
+		}
+	}
+}
+
+func (s *Server) issue(csr *x509.CertificateRequest) ([]byte, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 64))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: csr.DNSNames[0]},
+		DNSNames:     csr.DNSNames,
+		NotBefore:    now.Add(-time.Minute),
+		NotAfter:     now.Add(s.CertificateLifetime),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	return x509.CreateCertificate(rand.Reader, template, s.caCert, csr.PublicKey, s.caKey)
+}
+
+func (s *Server) newURL(kind string) string {
+	s.nextID++
+	return fmt.Sprintf("%s/%s/%d", s.server.URL, kind, s.nextID)
+}
+
+func challengeJSON(c *challenge) map[string]interface{} {
+	body := map[string]interface{}{
+		"type":   c.typ,
+		"url":    c.url,
+		"token":  c.token,
+		"status": c.status,
+	}
+	if c.err != "" {
+		body["error"] = map[string]string{
+			"type":   "urn:ietf:params:acme:error:unauthorized",
+			"detail": c.err,
+		}
+	}
+	return body
+}
+
+type jwsRequest struct {
+	kid     string
+	jwk     map[string]interface{}
+	payload []byte
+}
+
+// parseJWS parses the flattened JWS request. Signatures are not verified.
+func parseJWS(r *http.Request) (*jwsRequest, error) {
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	var jws struct {
+		Protected string `json:"protected"`
+		Payload   string `json:"payload"`
+	}
+	if err := json.Unmarshal(body, &jws); err != nil {
+		return nil, fmt.Errorf("invalid JWS: %w", err)
+	}
+	protected, err := base64.RawURLEncoding.DecodeString(jws.Protected)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWS protected header: %w", err)
+	}
+	var header struct {
+		KID string                 `json:"kid"`
+		JWK map[string]interface{} `json:"jwk"`
+	}
+	if err := json.Unmarshal(protected, &header); err != nil {
+		return nil, fmt.Errorf("invalid JWS protected header: %w", err)
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(jws.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWS payload: %w", err)
+	}
+	return &jwsRequest{kid: header.KID, jwk: header.JWK, payload: payload}, nil
+}
+
+// jwkThumbprint computes the RFC 7638 thumbprint of the JWK.
+func jwkThumbprint(jwk map[string]interface{}) (string, error) {
+	var members []string
+	switch jwk["kty"] {
+	case "EC":
+		members = []string{"crv", "kty", "x", "y"}
+	case "RSA":
+		members = []string{"e", "kty", "n"}
+	default:
+		return "", fmt.Errorf("unsupported key type %v", jwk["kty"])
+	}
+	var fields []string
+	for _, m := range members {
+		v, ok := jwk[m].(string)
+		if !ok {
+			return "", fmt.Errorf("invalid JWK member %s", m)
+		}
+		fields = append(fields, fmt.Sprintf("%q:%q", m, v))
+	}
+	h := sha256.New()
+	h.Write([]byte("{" + strings.Join(fields, ",") + "}"))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil)), nil
+}
+
+func newNonce() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func sameNames(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	names := map[string]bool{}
+	for _, n := range a {
+		names[strings.ToLower(n)] = true
+	}
+	for _, n := range b {
+		if !names[strings.ToLower(n)] {
+			return false
+		}
+	}
+	return true
+}
+
+func writeJSON(rw http.ResponseWriter, status int, body interface{}) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	_ = json.NewEncoder(rw).Encode(body)
+}
+
+func writeProblem(rw http.ResponseWriter, status int, problemType string, detail string) {
+	rw.Header().Set("Content-Type", "application/problem+json")
+	rw.WriteHeader(status)
+	_ = json.NewEncoder(rw).Encode(map[string]interface{}{
+		"type":   "urn:ietf:params:acme:error:" + problemType,
+		"detail": detail,
+		"status": status,
+	})
+}
@@ -0,0 +1,28 @@
+package acme
+
+type Config struct {
+	// DirectoryURL is the ACME directory URL, defaults to Let's Encrypt.
+	DirectoryURL string
+	// Email is the contact email of ACME account.
+	Email string
+	// AccountSecretNamespace is the namespace of Secret storing ACME account key.
+	AccountSecretNamespace string
+	// AccountSecretName is the name of Secret storing ACME account key.
+	AccountSecretName string
+	// SolverListenAddress is the address HTTP-01 challenge solver listens on.
+	SolverListenAddress string
+	// SolverServiceNamespace is the namespace of Service exposing the solver.
+	SolverServiceNamespace string
+	// SolverServiceName is the name of Service exposing the solver.
+	SolverServiceName string
+	// SolverServicePort is the port of Service exposing the solver.
+	SolverServicePort int
+	// IngressClass is the ingress class of challenge solver Ingress.
+	IngressClass string
+	// RenewBeforeDays is the number of days before expiry to renew certificate.
+	RenewBeforeDays int
+	// ChallengeType is the challenge type solved for authorizations, either
+	// http-01 or dns-01. Defaults to http-01. DNS-01 challenge records are
+	// published with the DNS provider.
+	ChallengeType string
+}
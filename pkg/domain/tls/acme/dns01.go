@@ -0,0 +1,67 @@
+package acme
+
+import (
+	"context"
+	"time"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/dnsprovider"
+	"github.com/skygeario/k8s-controller/pkg/util/dnsname"
+)
+
+const dns01ChallengeLabel = "_acme-challenge"
+
+// DNS01PollInterval is the interval polling DNS provider for propagation of
+// challenge records.
+var DNS01PollInterval = 5 * time.Second
+
+// DNS01Solver presents ACME DNS-01 challenge records with a DNS provider.
+type DNS01Solver struct {
+	DNSProvider dnsprovider.RecordProvider
+}
+
+func NewDNS01Solver(provider dnsprovider.RecordProvider) *DNS01Solver {
+	return &DNS01Solver{DNSProvider: provider}
+}
+
+// Present publishes the challenge record of the registration, and waits
+// until the record is propagated.
+func (s *DNS01Solver) Present(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration, value string) error {
+	record := challengeRecord(reg)
+	record.Values = []string{value}
+	return s.wait(ctx, func(ctx context.Context) (bool, error) {
+		return s.DNSProvider.EnsureRecord(ctx, record)
+	})
+}
+
+// CleanUp deletes the challenge record of the registration.
+func (s *DNS01Solver) CleanUp(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) error {
+	return s.wait(ctx, func(ctx context.Context) (bool, error) {
+		return s.DNSProvider.DeleteRecord(ctx, challengeRecord(reg))
+	})
+}
+
+func (s *DNS01Solver) wait(ctx context.Context, f func(ctx context.Context) (bool, error)) error {
+	for {
+		ok, err := f(ctx)
+		if err != nil || ok {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(DNS01PollInterval):
+		}
+	}
+}
+
+// challengeRecord returns the challenge record of the registration, owned by
+// the registration so that challenges of other registrations of the domain
+// are never overwritten.
+func challengeRecord(reg *domainv1beta1.CustomDomainRegistration) dnsprovider.Record {
+	return dnsprovider.Record{
+		Name:  dns01ChallengeLabel + "." + dnsname.TrimWildcard(reg.ASCIIDomainName()),
+		Type:  "TXT",
+		Owner: "acme/" + reg.Namespace + "/" + reg.Name,
+	}
+}
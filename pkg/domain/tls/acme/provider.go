@@ -0,0 +1,574 @@
+package acme
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/acme"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/tls"
+	"github.com/skygeario/k8s-controller/pkg/tracing"
+	"github.com/skygeario/k8s-controller/pkg/util/dnsname"
+)
+
+const (
+	// ChallengeHTTP01 solves HTTP-01 challenges with the built-in solver
+	// exposed by Ingress.
+	ChallengeHTTP01 = "http-01"
+	// ChallengeDNS01 solves DNS-01 challenges with the DNS provider, and is
+	// required for wildcard domains.
+	ChallengeDNS01 = "dns-01"
+)
+
+const (
+	defaultDirectoryURL    = "https://acme-v02.api.letsencrypt.org/directory"
+	defaultRenewBeforeDays = 30
+	accountKeySecretKey    = "tls.key"
+)
+
+var (
+	// IssueTimeout is the maximum duration of a certificate issuance.
+	IssueTimeout = 5 * time.Minute
+	// IssueRetryInterval is the interval before retrying failed issuance.
+	IssueRetryInterval = 1 * time.Hour
+)
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	_ = domainv1beta1.AddToScheme(scheme)
+}
+
+type issuance struct {
+	done   bool
+	doneAt time.Time
+	err    error
+}
+
+type Provider struct {
+	KubeClient client.Client
+	// Solver solves HTTP-01 challenges, and is nil for DNS-01 challenges.
+	Solver *HTTP01Solver
+	// DNS01Solver solves DNS-01 challenges, and should be set with the DNS
+	// provider if DNS-01 challenge is configured.
+	DNS01Solver *DNS01Solver
+	Config      Config
+	// DryRun skips issuance of certificates from ACME server.
+	DryRun bool
+	// Now returns current time, defaults to time.Now.
+	Now func() time.Time
+
+	lock      sync.Mutex
+	issuances map[types.NamespacedName]*issuance
+
+	clientLock sync.Mutex
+	client     *acme.Client
+}
+
+func NewProvider(client client.Client, config Config) (*Provider, error) {
+	if config.DirectoryURL == "" {
+		config.DirectoryURL = defaultDirectoryURL
+	}
+	if config.RenewBeforeDays == 0 {
+		config.RenewBeforeDays = defaultRenewBeforeDays
+	}
+	if config.IngressClass == "" {
+		config.IngressClass = "nginx"
+	}
+	if config.AccountSecretNamespace == "" || config.AccountSecretName == "" {
+		return nil, fmt.Errorf("ACME account secret is not configured")
+	}
+	if config.ChallengeType == "" {
+		config.ChallengeType = ChallengeHTTP01
+	}
+
+	var solver *HTTP01Solver
+	switch config.ChallengeType {
+	case ChallengeHTTP01:
+		if config.SolverServiceNamespace == "" || config.SolverServiceName == "" || config.SolverServicePort == 0 {
+			return nil, fmt.Errorf("ACME challenge solver service is not configured")
+		}
+		solver = NewHTTP01Solver(client, config.SolverServiceNamespace, config.SolverListenAddress)
+	case ChallengeDNS01:
+	default:
+		return nil, fmt.Errorf("unsupported ACME challenge type %s", config.ChallengeType)
+	}
+
+	return &Provider{
+		KubeClient: client,
+		Solver:     solver,
+		Config:     config,
+		Now:        time.Now,
+		issuances:  map[types.NamespacedName]*issuance{},
+	}, nil
+}
+
+var _ tls.Provider = &Provider{}
+
+func SecretName(reg *domainv1beta1.CustomDomainRegistration) string {
+	if reg.Spec.TLS != nil && reg.Spec.TLS.SecretName != nil {
+		return *reg.Spec.TLS.SecretName
+	}
+	return reg.Name + "-tls"
+}
+
+func (p *Provider) Provision(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (*tls.ProvisionResult, error) {
+	if dnsname.IsWildcard(reg.ASCIIDomainName()) && p.Config.ChallengeType != ChallengeDNS01 {
+		// wildcard certificates can only be issued with DNS-01 challenge
+		return nil, fmt.Errorf("wildcard domain is not supported by ACME HTTP-01 challenge")
+	}
+
+	secretName := SecretName(reg)
+	var secret corev1.Secret
+	err := p.KubeClient.Get(ctx, types.NamespacedName{Namespace: reg.Namespace, Name: secretName}, &secret)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	var cert *x509.Certificate
+	if err == nil {
+		if !metav1.IsControlledBy(&secret, reg) {
+			return nil, secretNotOwnedError(&secret)
+		}
+		cert, _ = tls.ParseCertificate(secret.Data[corev1.TLSCertKey])
+		if cert != nil && !sameDNSNames(cert.DNSNames, []string{reg.ASCIIDomainName()}) {
+			cert = nil
+		}
+	}
+
+	now := p.Now()
+	valid := cert != nil && now.Before(cert.NotAfter)
+	renewAt := time.Time{}
+	if cert != nil {
+		renewAt = cert.NotAfter.AddDate(0, 0, -p.Config.RenewBeforeDays)
+	}
+
+	if !valid || now.After(renewAt) {
+		if err := p.issueIfNeeded(reg); err != nil {
+			return nil, err
+		}
+	}
+
+	if !valid {
+		return nil, nil
+	}
+	return &tls.ProvisionResult{CertSecretName: secretName}, nil
+}
+
+func (p *Provider) Release(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (bool, error) {
+	n := types.NamespacedName{Namespace: reg.Namespace, Name: reg.Name}
+	p.lock.Lock()
+	iss, ok := p.issuances[n]
+	inProgress := ok && !iss.done
+	if ok && !inProgress {
+		delete(p.issuances, n)
+	}
+	p.lock.Unlock()
+	if inProgress {
+		// Wait for in-progress issuance
+		return false, nil
+	}
+
+	var secret corev1.Secret
+	err := p.KubeClient.Get(ctx, types.NamespacedName{Namespace: reg.Namespace, Name: SecretName(reg)}, &secret)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}
+
+	if !metav1.IsControlledBy(&secret, reg) {
+		return true, nil
+	}
+
+	if err := p.KubeClient.Delete(ctx, &secret); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (p *Provider) issueIfNeeded(reg *domainv1beta1.CustomDomainRegistration) error {
+	n := types.NamespacedName{Namespace: reg.Namespace, Name: reg.Name}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if iss, ok := p.issuances[n]; ok {
+		if !iss.done {
+			return nil
+		}
+		if iss.err != nil && p.Now().Before(iss.doneAt.Add(IssueRetryInterval)) {
+			return iss.err
+		}
+		delete(p.issuances, n)
+	}
+
+	if p.DryRun {
+		return fmt.Errorf("certificate issuance is skipped in dry-run mode")
+	}
+
+	iss := &issuance{}
+	p.issuances[n] = iss
+	reg = reg.DeepCopy()
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), IssueTimeout)
+		defer cancel()
+		err := p.issue(ctx, reg)
+
+		p.lock.Lock()
+		defer p.lock.Unlock()
+		iss.done = true
+		iss.doneAt = p.Now()
+		iss.err = err
+		if err == nil && p.issuances[n] == iss {
+			// Only failures are kept, to delay retries.
+			delete(p.issuances, n)
+		}
+	}()
+	return nil
+}
+
+func (p *Provider) issue(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (err error) {
+	ctx, span := tracing.Start(ctx, "ACME Issue", tracing.SpanKindClient, tracing.String("domain", reg.ASCIIDomainName()))
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
+	acmeClient, err := p.acmeClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	order, err := acmeClient.AuthorizeOrder(ctx, acme.DomainIDs(reg.ASCIIDomainName()))
+	if err != nil {
+		return fmt.Errorf("cannot create ACME order: %w", err)
+	}
+
+	for _, authzURL := range order.AuthzURLs {
+		if err := p.authorize(ctx, acmeClient, reg, authzURL); err != nil {
+			return err
+		}
+	}
+
+	order, err = acmeClient.WaitOrder(ctx, order.URI)
+	if err != nil {
+		return fmt.Errorf("cannot complete ACME order: %w", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		DNSNames: []string{reg.ASCIIDomainName()},
+	}, key)
+	if err != nil {
+		return err
+	}
+
+	chain, _, err := acmeClient.CreateOrderCert(ctx, order.FinalizeURL, csr, true)
+	if err != nil {
+		return fmt.Errorf("cannot issue certificate: %w", err)
+	}
+
+	return p.storeCertificate(ctx, reg, chain, key)
+}
+
+func (p *Provider) authorize(ctx context.Context, acmeClient *acme.Client, reg *domainv1beta1.CustomDomainRegistration, authzURL string) (err error) {
+	ctx, span := tracing.Start(ctx, "ACME Authorize", tracing.SpanKindClient, tracing.String("acme.authorization", authzURL))
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
+	authz, err := acmeClient.GetAuthorization(ctx, authzURL)
+	if err != nil {
+		return fmt.Errorf("cannot get ACME authorization: %w", err)
+	}
+	if authz.Status == acme.StatusValid {
+		return nil
+	}
+
+	var challenge *acme.Challenge
+	for _, c := range authz.Challenges {
+		if c.Type == p.Config.ChallengeType {
+			challenge = c
+			break
+		}
+	}
+	if challenge == nil {
+		return fmt.Errorf("%s challenge is not available", p.Config.ChallengeType)
+	}
+
+	if p.Config.ChallengeType == ChallengeDNS01 {
+		err = p.presentDNS01(ctx, acmeClient, reg, challenge)
+		defer p.cleanUpDNS01(reg)
+	} else {
+		err = p.presentHTTP01(ctx, acmeClient, reg, challenge)
+		defer p.cleanUpHTTP01(reg, challenge)
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := acmeClient.Accept(ctx, challenge); err != nil {
+		return fmt.Errorf("cannot accept ACME challenge: %w", err)
+	}
+	if _, err := acmeClient.WaitAuthorization(ctx, authz.URI); err != nil {
+		return fmt.Errorf("ACME authorization failed: %w", err)
+	}
+	return nil
+}
+
+func (p *Provider) presentHTTP01(ctx context.Context, acmeClient *acme.Client, reg *domainv1beta1.CustomDomainRegistration, challenge *acme.Challenge) error {
+	response, err := acmeClient.HTTP01ChallengeResponse(challenge.Token)
+	if err != nil {
+		return err
+	}
+	if err := p.Solver.Present(ctx, challenge.Token, response); err != nil {
+		return err
+	}
+	return p.exposeSolver(ctx, reg, challenge.Token)
+}
+
+func (p *Provider) cleanUpHTTP01(reg *domainv1beta1.CustomDomainRegistration, challenge *acme.Challenge) {
+	ctx := context.Background()
+	// Leftover responses are harmless, as challenge tokens are not reused.
+	_ = p.Solver.CleanUp(ctx, challenge.Token)
+	p.cleanUpSolver(ctx, reg)
+}
+
+func (p *Provider) presentDNS01(ctx context.Context, acmeClient *acme.Client, reg *domainv1beta1.CustomDomainRegistration, challenge *acme.Challenge) error {
+	if p.DNS01Solver == nil {
+		return fmt.Errorf("DNS provider is not configured for DNS-01 challenge")
+	}
+	value, err := acmeClient.DNS01ChallengeRecord(challenge.Token)
+	if err != nil {
+		return err
+	}
+	return p.DNS01Solver.Present(ctx, reg, value)
+}
+
+func (p *Provider) cleanUpDNS01(reg *domainv1beta1.CustomDomainRegistration) {
+	if p.DNS01Solver == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), IssueTimeout)
+	defer cancel()
+	// Leftover records are deleted on next issuance of the registration.
+	_ = p.DNS01Solver.CleanUp(ctx, reg)
+}
+
+func (p *Provider) acmeClient(ctx context.Context) (*acme.Client, error) {
+	p.clientLock.Lock()
+	defer p.clientLock.Unlock()
+	if p.client != nil {
+		return p.client, nil
+	}
+
+	key, err := p.accountKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	acmeClient := &acme.Client{Key: key, DirectoryURL: p.Config.DirectoryURL}
+	account := &acme.Account{}
+	if p.Config.Email != "" {
+		account.Contact = []string{"mailto:" + p.Config.Email}
+	}
+	if _, err := acmeClient.Register(ctx, account, acme.AcceptTOS); err != nil && !errors.Is(err, acme.ErrAccountAlreadyExists) {
+		return nil, fmt.Errorf("cannot register ACME account: %w", err)
+	}
+
+	p.client = acmeClient
+	return acmeClient, nil
+}
+
+func (p *Provider) accountKey(ctx context.Context) (crypto.Signer, error) {
+	var secret corev1.Secret
+	err := p.KubeClient.Get(ctx, types.NamespacedName{
+		Namespace: p.Config.AccountSecretNamespace,
+		Name:      p.Config.AccountSecretName,
+	}, &secret)
+	if err == nil {
+		return parsePrivateKey(secret.Data[accountKeySecretKey])
+	} else if !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	keyPEM, err := encodePrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	secret = corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: p.Config.AccountSecretNamespace,
+			Name:      p.Config.AccountSecretName,
+		},
+		Data: map[string][]byte{accountKeySecretKey: keyPEM},
+	}
+	if err := p.KubeClient.Create(ctx, &secret); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func (p *Provider) storeCertificate(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration, chain [][]byte, key *ecdsa.PrivateKey) error {
+	var certPEM []byte
+	for _, der := range chain {
+		certPEM = append(certPEM, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	}
+	keyPEM, err := encodePrivateKey(key)
+	if err != nil {
+		return err
+	}
+
+	var secret corev1.Secret
+	err = p.KubeClient.Get(ctx, types.NamespacedName{Namespace: reg.Namespace, Name: SecretName(reg)}, &secret)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	if err == nil && !metav1.IsControlledBy(&secret, reg) {
+		// Secret may be created by others during issuance.
+		return secretNotOwnedError(&secret)
+	}
+	if apierrors.IsNotFound(err) {
+		secret = corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: reg.Namespace,
+				Name:      SecretName(reg),
+			},
+			Type: corev1.SecretTypeTLS,
+		}
+		if err := ctrl.SetControllerReference(reg, &secret, scheme); err != nil {
+			return err
+		}
+	}
+	secret.Data = map[string][]byte{
+		corev1.TLSCertKey:       certPEM,
+		corev1.TLSPrivateKeyKey: keyPEM,
+	}
+
+	if secret.ResourceVersion == "" {
+		return p.KubeClient.Create(ctx, &secret)
+	}
+	return p.KubeClient.Update(ctx, &secret)
+}
+
+func (p *Provider) solverObjectMeta(reg *domainv1beta1.CustomDomainRegistration) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: reg.Namespace,
+		Name:      reg.Name + "-acme-solver",
+	}
+}
+
+func (p *Provider) exposeSolver(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration, token string) error {
+	service := &corev1.Service{
+		ObjectMeta: p.solverObjectMeta(reg),
+		Spec: corev1.ServiceSpec{
+			Type:         corev1.ServiceTypeExternalName,
+			ExternalName: fmt.Sprintf("%s.%s.svc", p.Config.SolverServiceName, p.Config.SolverServiceNamespace),
+		},
+	}
+	ingress := &networkingv1beta1.Ingress{
+		ObjectMeta: p.solverObjectMeta(reg),
+		Spec: networkingv1beta1.IngressSpec{
+			Rules: []networkingv1beta1.IngressRule{{
+				Host: reg.ASCIIDomainName(),
+				IngressRuleValue: networkingv1beta1.IngressRuleValue{
+					HTTP: &networkingv1beta1.HTTPIngressRuleValue{
+						Paths: []networkingv1beta1.HTTPIngressPath{{
+							Path: http01ChallengePath + token,
+							Backend: networkingv1beta1.IngressBackend{
+								ServiceName: service.Name,
+								ServicePort: intstr.FromInt(p.Config.SolverServicePort),
+							},
+						}},
+					},
+				},
+			}},
+		},
+	}
+	ingress.Annotations = map[string]string{
+		"kubernetes.io/ingress.class": p.Config.IngressClass,
+	}
+
+	for _, obj := range []runtime.Object{service, ingress} {
+		meta := obj.(metav1.Object)
+		if err := ctrl.SetControllerReference(reg, meta, scheme); err != nil {
+			return err
+		}
+		if err := p.KubeClient.Create(ctx, obj); err != nil && !apierrors.IsAlreadyExists(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Provider) cleanUpSolver(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) {
+	service := &corev1.Service{ObjectMeta: p.solverObjectMeta(reg)}
+	ingress := &networkingv1beta1.Ingress{ObjectMeta: p.solverObjectMeta(reg)}
+	for _, obj := range []runtime.Object{ingress, service} {
+		// Leftovers are garbage-collected with the registration.
+		_ = client.IgnoreNotFound(p.KubeClient.Delete(ctx, obj))
+	}
+}
+
+func secretNotOwnedError(secret *corev1.Secret) error {
+	return fmt.Errorf("%w: Secret %s exists and is not created by ACME provider", tls.ErrSecretNotOwned, secret.Name)
+}
+
+func parsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "EC PRIVATE KEY" {
+		return nil, fmt.Errorf("invalid private key")
+	}
+	return x509.ParseECPrivateKey(block.Bytes)
+}
+
+func encodePrivateKey(key *ecdsa.PrivateKey) ([]byte, error) {
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), nil
+}
+
+func sameDNSNames(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
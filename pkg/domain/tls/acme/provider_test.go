@@ -0,0 +1,119 @@
+package acme
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	fakedns "github.com/skygeario/k8s-controller/pkg/domain/dnsprovider/fake"
+	"github.com/skygeario/k8s-controller/pkg/domain/tls/acme/acmetest"
+)
+
+func newDNS01TestProvider(t *testing.T, validate acmetest.DNS01ChallengeValidator) (*Provider, *fakedns.Provider) {
+	server, err := acmetest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Close)
+	server.DNS01Validator = validate
+
+	p, err := NewProvider(fake.NewFakeClientWithScheme(clientgoscheme.Scheme), Config{
+		DirectoryURL:           server.DirectoryURL(),
+		AccountSecretNamespace: "domain-system",
+		AccountSecretName:      "acme-account",
+		ChallengeType:          ChallengeDNS01,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dns := fakedns.NewProvider()
+	p.DNS01Solver = NewDNS01Solver(dns)
+	return p, dns
+}
+
+// waitIssuance waits until issuance of the registration is done, and returns
+// whether the issuance is still tracked.
+func waitIssuance(t *testing.T, p *Provider, reg *domainv1beta1.CustomDomainRegistration) bool {
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		p.lock.Lock()
+		iss, ok := p.issuances[types.NamespacedName{Namespace: reg.Namespace, Name: reg.Name}]
+		done := !ok || iss.done
+		p.lock.Unlock()
+		if done {
+			return ok
+		}
+	}
+	t.Fatal("issuance is not done")
+	return false
+}
+
+func newTestRegistration() *domainv1beta1.CustomDomainRegistration {
+	return &domainv1beta1.CustomDomainRegistration{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "example.com", UID: "reg-uid"},
+		Spec:       domainv1beta1.CustomDomainRegistrationSpec{DomainName: "example.com"},
+	}
+}
+
+func TestProvisionDNS01(t *testing.T) {
+	var p *Provider
+	var dns *fakedns.Provider
+	p, dns = newDNS01TestProvider(t, func(domain string, value string) error {
+		record := dns.Record("_acme-challenge."+domain, "TXT")
+		if record == nil || len(record.Values) != 1 || record.Values[0] != value {
+			return errors.New("challenge record not found")
+		}
+		return nil
+	})
+	ctx := context.Background()
+	reg := newTestRegistration()
+
+	if result, err := p.Provision(ctx, reg); err != nil || result != nil {
+		t.Fatalf("Provision = %v, %v; expected issuance started", result, err)
+	}
+	if waitIssuance(t, p, reg) {
+		t.Error("successful issuance is kept")
+	}
+	if record := dns.Record("_acme-challenge.example.com", "TXT"); record != nil {
+		t.Errorf("challenge record is not cleaned up: %#v", record)
+	}
+
+	result, err := p.Provision(ctx, reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result == nil || result.CertSecretName != "example.com-tls" {
+		t.Errorf("unexpected result: %#v", result)
+	}
+}
+
+func TestReleaseFailedIssuance(t *testing.T) {
+	p, _ := newDNS01TestProvider(t, func(domain string, value string) error {
+		return errors.New("challenge record not found")
+	})
+	ctx := context.Background()
+	reg := newTestRegistration()
+
+	if _, err := p.Provision(ctx, reg); err != nil {
+		t.Fatal(err)
+	}
+	if !waitIssuance(t, p, reg) {
+		t.Fatal("failed issuance is not kept")
+	}
+	if _, err := p.Provision(ctx, reg); err == nil {
+		t.Error("expected failed issuance not retried")
+	}
+
+	if ok, err := p.Release(ctx, reg); err != nil || !ok {
+		t.Fatalf("Release = %v, %v", ok, err)
+	}
+	if waitIssuance(t, p, reg) {
+		t.Error("failed issuance is kept after release")
+	}
+}
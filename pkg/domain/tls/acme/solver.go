@@ -0,0 +1,172 @@
+package acme
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"golang.org/x/time/rate"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/skygeario/k8s-controller/pkg/util/httpserver"
+)
+
+const (
+	http01ChallengePath = "/.well-known/acme-challenge/"
+	// challengeLabel labels Secrets storing HTTP-01 challenge responses.
+	challengeLabel = "domain.skygear.io/acme-challenge"
+	// challengeResponseKey is the Secret key of challenge response.
+	challengeResponseKey = "response"
+)
+
+var (
+	// SolverMissQPS is the maximum rate of reading challenge responses
+	// missing in cache from Reader.
+	SolverMissQPS = 5.0
+	// SolverMissBurst is the maximum burst of reading challenge responses
+	// missing in cache from Reader.
+	SolverMissBurst = 10
+)
+
+// HTTP01Solver serves ACME HTTP-01 challenge responses. Responses are stored
+// in Secrets of Namespace, so that challenges presented by the leader are
+// served by all replicas behind the solver Service.
+type HTTP01Solver struct {
+	ListenAddress string
+	Namespace     string
+	Client        client.Client
+	// Reader reads challenge responses, defaults to Client. If the solver
+	// uses an informer, Reader reads responses missing in cache only, as
+	// responses are requested right after presented, at the rate limited
+	// by MissLimiter.
+	Reader client.Reader
+	// MissLimiter limits the rate of reading responses missing in cache, so
+	// that public requests of unknown tokens are not sent to the API server.
+	MissLimiter *rate.Limiter
+
+	informers informers.SharedInformerFactory
+	secrets   corelisters.SecretNamespaceLister
+}
+
+func NewHTTP01Solver(client client.Client, namespace string, listenAddress string) *HTTP01Solver {
+	return &HTTP01Solver{
+		ListenAddress: listenAddress,
+		Namespace:     namespace,
+		Client:        client,
+		Reader:        client,
+		MissLimiter:   rate.NewLimiter(rate.Limit(SolverMissQPS), SolverMissBurst),
+	}
+}
+
+// UseInformer serves challenge responses from an informer cache of
+// challenge Secrets in Namespace, which is started with the solver.
+func (s *HTTP01Solver) UseInformer(clientset kubernetes.Interface) {
+	s.informers = informers.NewSharedInformerFactoryWithOptions(clientset, 0,
+		informers.WithNamespace(s.Namespace),
+		informers.WithTweakListOptions(func(o *metav1.ListOptions) {
+			o.LabelSelector = challengeLabel
+		}),
+	)
+	s.secrets = s.informers.Core().V1().Secrets().Lister().Secrets(s.Namespace)
+}
+
+func (s *HTTP01Solver) secretName(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return "acme-challenge-" + hex.EncodeToString(hash[:])
+}
+
+func (s *HTTP01Solver) Present(ctx context.Context, token string, response string) error {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: s.Namespace,
+			Name:      s.secretName(token),
+			Labels:    map[string]string{challengeLabel: "http-01"},
+		},
+		Data: map[string][]byte{challengeResponseKey: []byte(response)},
+	}
+	err := s.Client.Create(ctx, secret)
+	if apierrors.IsAlreadyExists(err) {
+		return s.Client.Update(ctx, secret)
+	}
+	return err
+}
+
+func (s *HTTP01Solver) CleanUp(ctx context.Context, token string) error {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: s.Namespace,
+			Name:      s.secretName(token),
+		},
+	}
+	return client.IgnoreNotFound(s.Client.Delete(ctx, secret))
+}
+
+func (s *HTTP01Solver) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.URL.Path, http01ChallengePath) {
+		http.NotFound(rw, r)
+		return
+	}
+	token := strings.TrimPrefix(r.URL.Path, http01ChallengePath)
+
+	secret, err := s.getSecret(r.Context(), token)
+	if apierrors.IsNotFound(err) {
+		http.NotFound(rw, r)
+		return
+	} else if err != nil {
+		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	response, ok := secret.Data[challengeResponseKey]
+	if !ok {
+		http.NotFound(rw, r)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "text/plain")
+	_, _ = rw.Write(response)
+}
+
+// getSecret returns the Secret storing response of the token.
+func (s *HTTP01Solver) getSecret(ctx context.Context, token string) (*corev1.Secret, error) {
+	name := s.secretName(token)
+	if s.secrets != nil {
+		secret, err := s.secrets.Get(name)
+		if !apierrors.IsNotFound(err) {
+			return secret, err
+		}
+		// Response presented by the leader may not be observed yet.
+		if s.MissLimiter != nil && !s.MissLimiter.Allow() {
+			return nil, err
+		}
+	}
+
+	var secret corev1.Secret
+	if err := s.Reader.Get(ctx, types.NamespacedName{Namespace: s.Namespace, Name: name}, &secret); err != nil {
+		return nil, err
+	}
+	return &secret, nil
+}
+
+// Start implements manager.Runnable, serving challenges until stop is closed.
+func (s *HTTP01Solver) Start(stop <-chan struct{}) error {
+	if s.informers != nil {
+		s.informers.Start(stop)
+		s.informers.WaitForCacheSync(stop)
+	}
+	return httpserver.Run(&http.Server{Addr: s.ListenAddress, Handler: s}, stop, 0)
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. Challenges
+// are served by all replicas, as the solver Service routes to any of them.
+func (s *HTTP01Solver) NeedLeaderElection() bool {
+	return false
+}
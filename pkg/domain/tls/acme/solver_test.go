@@ -0,0 +1,125 @@
+package acme
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestHTTP01Solver(t *testing.T) {
+	ctx := context.Background()
+	c := fake.NewFakeClientWithScheme(clientgoscheme.Scheme)
+	leader := NewHTTP01Solver(c, "domain-system", ":8089")
+	// Other replicas share challenge responses with the leader.
+	replica := NewHTTP01Solver(c, "domain-system", ":8089")
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		rw := httptest.NewRecorder()
+		replica.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, path, nil))
+		return rw
+	}
+
+	if err := leader.Present(ctx, "token", "token.thumbprint"); err != nil {
+		t.Fatal(err)
+	}
+	if rw := serve("/.well-known/acme-challenge/token"); rw.Code != http.StatusOK || rw.Body.String() != "token.thumbprint" {
+		t.Errorf("unexpected response: %d %q", rw.Code, rw.Body.String())
+	}
+	if rw := serve("/.well-known/acme-challenge/other"); rw.Code != http.StatusNotFound {
+		t.Errorf("expected not found for unknown token, got %d", rw.Code)
+	}
+	if rw := serve("/token"); rw.Code != http.StatusNotFound {
+		t.Errorf("expected not found outside challenge path, got %d", rw.Code)
+	}
+
+	// Presenting again replaces the response.
+	if err := leader.Present(ctx, "token", "token.thumbprint2"); err != nil {
+		t.Fatal(err)
+	}
+	if rw := serve("/.well-known/acme-challenge/token"); rw.Body.String() != "token.thumbprint2" {
+		t.Errorf("unexpected response: %q", rw.Body.String())
+	}
+
+	if err := leader.CleanUp(ctx, "token"); err != nil {
+		t.Fatal(err)
+	}
+	if rw := serve("/.well-known/acme-challenge/token"); rw.Code != http.StatusNotFound {
+		t.Errorf("expected not found after clean up, got %d", rw.Code)
+	}
+	if err := leader.CleanUp(ctx, "token"); err != nil {
+		t.Errorf("expected clean up to be idempotent, got %v", err)
+	}
+}
+
+type countingReader struct {
+	client.Reader
+	reads int
+}
+
+func (r *countingReader) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	r.reads++
+	return r.Reader.Get(ctx, key, obj)
+}
+
+func TestHTTP01SolverInformer(t *testing.T) {
+	ctx := context.Background()
+	c := fake.NewFakeClientWithScheme(clientgoscheme.Scheme)
+	leader := NewHTTP01Solver(c, "domain-system", ":8089")
+	if err := leader.Present(ctx, "token", "token.thumbprint"); err != nil {
+		t.Fatal(err)
+	}
+	var cached corev1.Secret
+	if err := c.Get(ctx, types.NamespacedName{Namespace: "domain-system", Name: leader.secretName("token")}, &cached); err != nil {
+		t.Fatal(err)
+	}
+
+	reader := &countingReader{Reader: c}
+	replica := NewHTTP01Solver(c, "domain-system", ":8089")
+	replica.Reader = reader
+	replica.MissLimiter = rate.NewLimiter(rate.Every(time.Hour), 1)
+	replica.UseInformer(kubefake.NewSimpleClientset(&cached))
+	stop := make(chan struct{})
+	defer close(stop)
+	replica.informers.Start(stop)
+	replica.informers.WaitForCacheSync(stop)
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		rw := httptest.NewRecorder()
+		replica.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, path, nil))
+		return rw
+	}
+
+	if rw := serve("/.well-known/acme-challenge/token"); rw.Code != http.StatusOK || rw.Body.String() != "token.thumbprint" {
+		t.Errorf("unexpected response: %d %q", rw.Code, rw.Body.String())
+	}
+	if reader.reads != 0 {
+		t.Errorf("cached response is read from reader")
+	}
+
+	// Responses missing in cache are read at limited rate.
+	if err := leader.Present(ctx, "new-token", "new-token.thumbprint"); err != nil {
+		t.Fatal(err)
+	}
+	if rw := serve("/.well-known/acme-challenge/new-token"); rw.Code != http.StatusOK || rw.Body.String() != "new-token.thumbprint" {
+		t.Errorf("unexpected response: %d %q", rw.Code, rw.Body.String())
+	}
+	for i := 0; i < 10; i++ {
+		if rw := serve("/.well-known/acme-challenge/unknown"); rw.Code != http.StatusNotFound {
+			t.Errorf("expected not found for unknown token, got %d", rw.Code)
+		}
+	}
+	if reader.reads != 1 {
+		t.Errorf("reads = %d, expected 1", reader.reads)
+	}
+}
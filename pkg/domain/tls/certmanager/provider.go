@@ -2,6 +2,7 @@ package certmanager
 
 import (
 	"context"
+	"reflect"
 
 	cmutil "github.com/jetstack/cert-manager/pkg/api/util"
 	cm "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha2"
@@ -17,6 +18,10 @@ import (
 	"github.com/skygeario/k8s-controller/pkg/domain/tls"
 )
 
+// reasonPending is the reason of certificate not yet observed by
+// cert-manager.
+const reasonPending = "Pending"
+
 var scheme = runtime.NewScheme()
 
 func init() {
@@ -36,6 +41,7 @@ func NewProvider(client client.Client, config Config) (*Provider, error) {
 }
 
 var _ tls.Provider = &Provider{}
+var _ tls.CertificateStatusReporter = &Provider{}
 
 func (p *Provider) Provision(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (*tls.ProvisionResult, error) {
 	var cert cm.Certificate
@@ -46,21 +52,34 @@ func (p *Provider) Provision(ctx context.Context, reg *domainv1beta1.CustomDomai
 		}
 	}
 
+	issuerRef := p.issuerRef(reg)
+	secretName := reg.Name + "-tls"
+	dnsNames := []string{reg.ASCIIDomainName()}
+
 	if apierrors.IsNotFound(err) {
 		cert.Namespace = reg.Namespace
 		cert.Name = reg.Name
-		cert.Spec.IssuerRef = cmmeta.ObjectReference{
-			Kind: "ClusterIssuer",
-			Name: p.ClusterIssuerName,
-		}
-		cert.Spec.SecretName = reg.Name + "-tls"
-		cert.Spec.DNSNames = []string{reg.Spec.DomainName}
+		cert.Spec.IssuerRef = issuerRef
+		cert.Spec.SecretName = secretName
+		cert.Spec.DNSNames = dnsNames
 		if err := ctrl.SetControllerReference(reg, &cert, scheme); err != nil {
 			return nil, err
 		}
 		if err := p.KubeClient.Create(ctx, &cert); err != nil {
 			return nil, err
 		}
+	} else if metav1.IsControlledBy(&cert, reg) &&
+		(cert.Spec.IssuerRef.Kind != issuerRef.Kind ||
+			cert.Spec.IssuerRef.Name != issuerRef.Name ||
+			cert.Spec.SecretName != secretName ||
+			!reflect.DeepEqual(cert.Spec.DNSNames, dnsNames)) {
+		cert.Spec.IssuerRef = issuerRef
+		cert.Spec.SecretName = secretName
+		cert.Spec.DNSNames = dnsNames
+		if err := p.KubeClient.Update(ctx, &cert); err != nil {
+			return nil, err
+		}
+		return nil, nil
 	}
 
 	if !cmutil.CertificateHasCondition(&cert, cm.CertificateCondition{Type: cm.CertificateConditionReady, Status: cmmeta.ConditionTrue}) {
@@ -91,3 +110,50 @@ func (p *Provider) Release(ctx context.Context, reg *domainv1beta1.CustomDomainR
 	}
 	return true, nil
 }
+
+// CertificateStatus returns status of Ready condition of the Certificate of
+// the registration.
+func (p *Provider) CertificateStatus(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (*tls.CertificateStatus, error) {
+	var cert cm.Certificate
+	err := p.KubeClient.Get(ctx, types.NamespacedName{Namespace: reg.Namespace, Name: reg.Name}, &cert)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if !metav1.IsControlledBy(&cert, reg) {
+		return nil, nil
+	}
+
+	for _, cond := range cert.Status.Conditions {
+		if cond.Type == cm.CertificateConditionReady {
+			return &tls.CertificateStatus{
+				Status:  metav1.ConditionStatus(cond.Status),
+				Reason:  cond.Reason,
+				Message: cond.Message,
+			}, nil
+		}
+	}
+	return &tls.CertificateStatus{
+		Status:  metav1.ConditionUnknown,
+		Reason:  reasonPending,
+		Message: "certificate is not yet observed by cert-manager",
+	}, nil
+}
+
+func (p *Provider) issuerRef(reg *domainv1beta1.CustomDomainRegistration) cmmeta.ObjectReference {
+	if reg.Spec.TLS != nil && reg.Spec.TLS.IssuerRef != nil {
+		kind := reg.Spec.TLS.IssuerRef.Kind
+		if kind == "" {
+			kind = "ClusterIssuer"
+		}
+		return cmmeta.ObjectReference{
+			Kind: kind,
+			Name: reg.Spec.TLS.IssuerRef.Name,
+		}
+	}
+	return cmmeta.ObjectReference{
+		Kind: "ClusterIssuer",
+		Name: p.ClusterIssuerName,
+	}
+}
@@ -0,0 +1,100 @@
+package certmanager
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	cm "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha2"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+	"github.com/skygeario/k8s-controller/pkg/domain/tls"
+)
+
+func TestCertificateStatus(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := cm.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := domainv1beta1.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+
+	reg := &domainv1beta1.CustomDomainRegistration{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "example.com", UID: "reg-uid"},
+	}
+	controller := true
+	newCert := func(conds ...cm.CertificateCondition) *cm.Certificate {
+		return &cm.Certificate{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "app",
+				Name:      "example.com",
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: domainv1beta1.GroupVersion.String(),
+					Kind:       "CustomDomainRegistration",
+					Name:       "example.com",
+					UID:        "reg-uid",
+					Controller: &controller,
+				}},
+			},
+			Status: cm.CertificateStatus{Conditions: conds},
+		}
+	}
+	foreign := newCert()
+	foreign.OwnerReferences = nil
+
+	tests := []struct {
+		name     string
+		objects  []runtime.Object
+		expected *tls.CertificateStatus
+	}{
+		{
+			name: "not created",
+		},
+		{
+			name:    "not owned",
+			objects: []runtime.Object{foreign},
+		},
+		{
+			name:     "not observed",
+			objects:  []runtime.Object{newCert()},
+			expected: &tls.CertificateStatus{Status: metav1.ConditionUnknown, Reason: "Pending", Message: "certificate is not yet observed by cert-manager"},
+		},
+		{
+			name: "not ready",
+			objects: []runtime.Object{newCert(cm.CertificateCondition{
+				Type:    cm.CertificateConditionReady,
+				Status:  cmmeta.ConditionFalse,
+				Reason:  "Failed",
+				Message: "order failed",
+			})},
+			expected: &tls.CertificateStatus{Status: metav1.ConditionFalse, Reason: "Failed", Message: "order failed"},
+		},
+		{
+			name: "ready",
+			objects: []runtime.Object{newCert(cm.CertificateCondition{
+				Type:    cm.CertificateConditionReady,
+				Status:  cmmeta.ConditionTrue,
+				Reason:  "Ready",
+				Message: "Certificate is up to date and has not expired",
+			})},
+			expected: &tls.CertificateStatus{Status: metav1.ConditionTrue, Reason: "Ready", Message: "Certificate is up to date and has not expired"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Provider{KubeClient: fake.NewFakeClientWithScheme(s, tt.objects...)}
+			status, err := p.CertificateStatus(context.Background(), reg)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(status, tt.expected) {
+				t.Errorf("CertificateStatus = %#v, expected %#v", status, tt.expected)
+			}
+		})
+	}
+}
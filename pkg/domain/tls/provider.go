@@ -2,10 +2,17 @@ package tls
 
 import (
 	"context"
+	"errors"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
 )
 
+// ErrSecretNotOwned is returned when the certificate Secret of registration
+// exists and is not managed by the provider, so it is never overwritten.
+var ErrSecretNotOwned = errors.New("certificate Secret is not owned by the registration")
+
 type Provider interface {
 	Provision(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (*ProvisionResult, error)
 	Release(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (ok bool, err error)
@@ -14,3 +21,18 @@ type Provider interface {
 type ProvisionResult struct {
 	CertSecretName string
 }
+
+// CertificateStatus is the status of certificate issuance of registration.
+type CertificateStatus struct {
+	Status  metav1.ConditionStatus
+	Reason  string
+	Message string
+}
+
+// CertificateStatusReporter is implemented by providers issuing certificates
+// through resources with status, e.g. cert-manager Certificate.
+type CertificateStatusReporter interface {
+	// CertificateStatus returns status of certificate of the registration, or
+	// nil if the certificate is not issued through the provider.
+	CertificateStatus(ctx context.Context, reg *domainv1beta1.CustomDomainRegistration) (*CertificateStatus, error)
+}
@@ -0,0 +1,34 @@
+package verification
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// AttestationMessage returns the message signed by external verifiers to
+// attest ownership of domain of the registration.
+func AttestationMessage(namespace string, name string, domain string, timestamp time.Time) []byte {
+	return []byte(fmt.Sprintf("%s/%s\n%s\n%d", namespace, name, domain, timestamp.Unix()))
+}
+
+// SignAttestation signs the attestation message using HMAC-SHA256, keyed by
+// the verification key of domain.
+func SignAttestation(key string, message []byte) string {
+	h := hmac.New(sha256.New, []byte(key))
+	h.Write(message)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// VerifyAttestation checks the attestation signature is signed by the key.
+func VerifyAttestation(key string, message []byte, signature string) bool {
+	sig, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	h := hmac.New(sha256.New, []byte(key))
+	h.Write(message)
+	return hmac.Equal(h.Sum(nil), sig)
+}
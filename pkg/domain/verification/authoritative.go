@@ -0,0 +1,124 @@
+package verification
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+// NSResolver resolves NS records of a zone.
+type NSResolver interface {
+	LookupNS(ctx context.Context, name string) ([]*net.NS, error)
+}
+
+// AuthoritativeResolver queries authoritative nameservers of the domain
+// directly, bypassing caching resolvers, so that newly created records are
+// visible immediately.
+type AuthoritativeResolver struct {
+	// Upstream resolves NS records of the domain.
+	Upstream NSResolver
+	// Config limits the rate of queries sent to each authoritative
+	// nameserver; Servers is ignored.
+	Config RateLimitConfig
+
+	lock     sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+func NewAuthoritativeResolver(upstream NSResolver, config RateLimitConfig) *AuthoritativeResolver {
+	return &AuthoritativeResolver{
+		Upstream: upstream,
+		Config:   config,
+		limiters: map[string]*rate.Limiter{},
+	}
+}
+
+var _ Resolver = &AuthoritativeResolver{}
+
+func (r *AuthoritativeResolver) String() string {
+	return "authoritative"
+}
+
+func (r *AuthoritativeResolver) LookupTXT(ctx context.Context, name string) (records []string, err error) {
+	err = r.query(ctx, name, func(resolver *net.Resolver) error {
+		records, err = resolver.LookupTXT(ctx, name)
+		return err
+	})
+	return
+}
+
+func (r *AuthoritativeResolver) LookupCNAME(ctx context.Context, name string) (cname string, err error) {
+	err = r.query(ctx, name, func(resolver *net.Resolver) error {
+		cname, err = resolver.LookupCNAME(ctx, name)
+		return err
+	})
+	return
+}
+
+// query performs the lookup using authoritative nameservers of the name in
+// turn, until a nameserver answers.
+func (r *AuthoritativeResolver) query(ctx context.Context, name string, lookup func(resolver *net.Resolver) error) error {
+	nameservers, err := r.lookupNameservers(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	for _, ns := range nameservers {
+		server := net.JoinHostPort(strings.TrimSuffix(ns.Host, "."), "53")
+		if err = r.limiter(server).Wait(ctx); err != nil {
+			return err
+		}
+
+		resolver := &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, server)
+			},
+		}
+		err = lookup(resolver)
+		if dnsErr, ok := err.(*net.DNSError); ok && (dnsErr.Timeout() || dnsErr.Temporary()) {
+			// try next nameserver
+			continue
+		}
+		return err
+	}
+	return err
+}
+
+// lookupNameservers returns the NS records of the closest zone enclosing name.
+func (r *AuthoritativeResolver) lookupNameservers(ctx context.Context, name string) ([]*net.NS, error) {
+	zone := strings.TrimSuffix(name, ".")
+	for strings.Contains(zone, ".") {
+		nameservers, err := r.Upstream.LookupNS(ctx, zone)
+		if err == nil && len(nameservers) > 0 {
+			return nameservers, nil
+		}
+		zone = zone[strings.Index(zone, ".")+1:]
+	}
+	return nil, fmt.Errorf("cannot find authoritative nameservers of %s", name)
+}
+
+func (r *AuthoritativeResolver) limiter(server string) *rate.Limiter {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	limiter, ok := r.limiters[server]
+	if !ok {
+		limit := rate.Limit(r.Config.QPS)
+		if r.Config.QPS <= 0 {
+			limit = rate.Inf
+		}
+		burst := r.Config.Burst
+		if burst <= 0 {
+			burst = 1
+		}
+		limiter = rate.NewLimiter(limit, burst)
+		r.limiters[server] = limiter
+	}
+	return limiter
+}
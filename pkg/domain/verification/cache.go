@@ -0,0 +1,150 @@
+package verification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/skygeario/k8s-controller/pkg/metrics"
+)
+
+// CacheConfig configures caching of DNS answers.
+//
+// Resolvers of Go standard library do not expose TTL of records, so answers
+// are cached for a fixed TTL. It should not exceed TTL of verification
+// records, so that changes of records are observed timely.
+type CacheConfig struct {
+	// TTL is the duration answers are cached. Caching is disabled if zero.
+	TTL time.Duration
+	// NegativeTTL is the duration non-existent names are cached.
+	NegativeTTL time.Duration
+}
+
+type cacheKey struct {
+	recordType string
+	name       string
+}
+
+type cacheEntry struct {
+	value     interface{}
+	err       error
+	expiresAt time.Time
+}
+
+// CachingResolver caches DNS answers of upstream resolver, so that
+// registrations sharing a domain, or requeued rapidly, do not repeat
+// lookups.
+type CachingResolver struct {
+	Upstream Resolver
+	Config   CacheConfig
+
+	lock      sync.Mutex
+	entries   map[cacheKey]cacheEntry
+	nextPurge time.Time
+	now       func() time.Time
+}
+
+func NewCachingResolver(upstream Resolver, config CacheConfig) *CachingResolver {
+	return &CachingResolver{
+		Upstream: upstream,
+		Config:   config,
+		entries:  map[cacheKey]cacheEntry{},
+		now:      time.Now,
+	}
+}
+
+var _ Resolver = &CachingResolver{}
+var _ AddressResolver = &CachingResolver{}
+
+func (r *CachingResolver) String() string {
+	return ResolverName(r.Upstream)
+}
+
+func (r *CachingResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	value, err := r.lookup(cacheKey{recordType: "TXT", name: name}, func() (interface{}, error) {
+		return r.Upstream.LookupTXT(ctx, name)
+	})
+	records, _ := value.([]string)
+	return records, err
+}
+
+func (r *CachingResolver) LookupCNAME(ctx context.Context, name string) (string, error) {
+	value, err := r.lookup(cacheKey{recordType: "CNAME", name: name}, func() (interface{}, error) {
+		return r.Upstream.LookupCNAME(ctx, name)
+	})
+	cname, _ := value.(string)
+	return cname, err
+}
+
+func (r *CachingResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	upstream, ok := r.Upstream.(AddressResolver)
+	if !ok {
+		return nil, fmt.Errorf("resolver %s cannot resolve addresses", ResolverName(r.Upstream))
+	}
+	value, err := r.lookup(cacheKey{recordType: "A", name: host}, func() (interface{}, error) {
+		return upstream.LookupIPAddr(ctx, host)
+	})
+	addrs, _ := value.([]net.IPAddr)
+	return addrs, err
+}
+
+func (r *CachingResolver) lookup(key cacheKey, resolve func() (interface{}, error)) (interface{}, error) {
+	if r.Config.TTL <= 0 {
+		return resolve()
+	}
+
+	now := r.now()
+	r.lock.Lock()
+	entry, ok := r.entries[key]
+	r.lock.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		metrics.DNSCacheRequests.WithLabelValues("hit").Inc()
+		return entry.value, entry.err
+	}
+	metrics.DNSCacheRequests.WithLabelValues("miss").Inc()
+
+	value, err := resolve()
+	ttl := r.Config.TTL
+	if err == nil && isEmptyAnswer(value) {
+		ttl = r.Config.NegativeTTL
+	} else if err != nil {
+		var dnsErr *net.DNSError
+		if !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+			// Transient errors are not cached.
+			return value, err
+		}
+		ttl = r.Config.NegativeTTL
+	}
+
+	if ttl <= 0 {
+		return value, err
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.entries[key] = cacheEntry{value: value, err: err, expiresAt: now.Add(ttl)}
+	if now.After(r.nextPurge) {
+		for k, e := range r.entries {
+			if !now.Before(e.expiresAt) {
+				delete(r.entries, k)
+			}
+		}
+		r.nextPurge = now.Add(r.Config.TTL)
+	}
+	return value, err
+}
+
+func isEmptyAnswer(value interface{}) bool {
+	switch v := value.(type) {
+	case []string:
+		return len(v) == 0
+	case []net.IPAddr:
+		return len(v) == 0
+	case string:
+		return v == ""
+	}
+	return false
+}
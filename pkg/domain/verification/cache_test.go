@@ -0,0 +1,74 @@
+package verification
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCachingResolver(t *testing.T) {
+	const (
+		ttl         = time.Minute
+		negativeTTL = 10 * time.Second
+	)
+
+	tests := []struct {
+		name     string
+		config   CacheConfig
+		upstream *fakeResolver
+		query    string
+		// elapsed is the time between the two lookups.
+		elapsed time.Duration
+		lookups int
+	}{
+		{"answer within TTL", CacheConfig{TTL: ttl, NegativeTTL: negativeTTL}, txtResolver("a"), "example.com", ttl - time.Second, 1},
+		{"answer after TTL", CacheConfig{TTL: ttl, NegativeTTL: negativeTTL}, txtResolver("a"), "example.com", ttl, 2},
+		{"not found within negative TTL", CacheConfig{TTL: ttl, NegativeTTL: negativeTTL}, txtResolver("a"), "missing.example.com", negativeTTL - time.Second, 1},
+		{"not found after negative TTL", CacheConfig{TTL: ttl, NegativeTTL: negativeTTL}, txtResolver("a"), "missing.example.com", negativeTTL, 2},
+		{"empty answer after negative TTL", CacheConfig{TTL: ttl, NegativeTTL: negativeTTL}, txtResolver(), "example.com", negativeTTL, 2},
+		{"not found without negative TTL", CacheConfig{TTL: ttl}, txtResolver("a"), "missing.example.com", 0, 2},
+		{"transient error", CacheConfig{TTL: ttl, NegativeTTL: negativeTTL}, &fakeResolver{err: errors.New("timeout")}, "example.com", 0, 2},
+		{"disabled", CacheConfig{NegativeTTL: negativeTTL}, txtResolver("a"), "example.com", 0, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+			r := NewCachingResolver(tt.upstream, tt.config)
+			r.now = func() time.Time { return now }
+
+			first, firstErr := r.LookupTXT(context.Background(), tt.query)
+			now = now.Add(tt.elapsed)
+			second, secondErr := r.LookupTXT(context.Background(), tt.query)
+
+			if tt.upstream.lookups != tt.lookups {
+				t.Errorf("upstream lookups = %d, expected %d", tt.upstream.lookups, tt.lookups)
+			}
+			if len(first) != len(second) || (firstErr == nil) != (secondErr == nil) {
+				t.Errorf("answers differ: %v, %v and %v, %v", first, firstErr, second, secondErr)
+			}
+		})
+	}
+}
+
+func TestCachingResolverKeys(t *testing.T) {
+	upstream := &fakeResolver{
+		txt:   map[string][]string{"example.com": {"a"}},
+		cname: map[string]string{"example.com": "target.example.net"},
+	}
+	r := NewCachingResolver(upstream, CacheConfig{TTL: time.Minute})
+
+	if _, err := r.LookupTXT(context.Background(), "example.com"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cname, err := r.LookupCNAME(context.Background(), "example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cname != "target.example.net" {
+		t.Errorf("cname = %q, expected %q", cname, "target.example.net")
+	}
+	if upstream.lookups != 2 {
+		t.Errorf("upstream lookups = %d, expected records of different types cached separately", upstream.lookups)
+	}
+}
@@ -3,20 +3,40 @@ package verification
 import (
 	"context"
 	"fmt"
-	"net"
+	"time"
+
+	"github.com/skygeario/k8s-controller/pkg/metrics"
 )
 
-var resolver = &net.Resolver{}
+// DNSVerifier verifies domain ownership by looking up verification TXT record.
+type DNSVerifier struct {
+	Resolver Resolver
+}
+
+func NewDNSVerifier(resolver Resolver) *DNSVerifier {
+	return &DNSVerifier{Resolver: resolver}
+}
 
-func VerifyDomain(ctx context.Context, domain string, token string) error {
-	recordName, err := MakeDNSRecordName(domain)
+func (v *DNSVerifier) VerifyDomain(ctx context.Context, domain string, token string) error {
+	recordName, err := MakeDNSRecordName(domain, recordPrefix(ctx))
 	if err != nil {
 		return fmt.Errorf("cannot lookup verification DNS record: %w", err)
 	}
 
-	records, err := resolver.LookupTXT(ctx, recordName)
+	details := FailureDetails{
+		RecordName:    recordName,
+		ExpectedValue: token,
+		Resolver:      ResolverName(v.Resolver),
+	}
+
+	lookupStart := time.Now()
+	records, err := v.Resolver.LookupTXT(ctx, recordName)
+	metrics.DNSLookupDuration.Observe(time.Since(lookupStart).Seconds())
 	if err != nil {
-		return fmt.Errorf("cannot lookup verification DNS record: %w", err)
+		return withDetails(newLookupError(err), details)
+	}
+	if len(records) == 0 {
+		return withDetails(errEmptyAnswer, details)
 	}
 
 	for _, value := range records {
@@ -24,5 +44,6 @@ func VerifyDomain(ctx context.Context, domain string, token string) error {
 			return nil
 		}
 	}
-	return fmt.Errorf("verification DNS record not found")
+	details.ObservedValues = records
+	return withDetails(errRecordNotFound, details)
 }
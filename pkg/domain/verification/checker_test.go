@@ -0,0 +1,41 @@
+package verification
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDNSVerifierRecordPrefix(t *testing.T) {
+	resolver := &fakeResolver{txt: map[string][]string{
+		"_skygear.example.com": {"default-token"},
+		"_acme.example.com":    {"branded-token"},
+	}}
+	verifier := NewDNSVerifier(resolver)
+
+	tests := []struct {
+		name   string
+		prefix string
+		domain string
+		token  string
+		reason string
+	}{
+		{"default prefix", "", "www.example.com", "default-token", ""},
+		{"domain prefix", "_acme", "www.example.com", "branded-token", ""},
+		{"record of default prefix ignored", "_acme", "example.com", "default-token", ReasonRecordNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := WithRecordPrefix(context.Background(), tt.prefix)
+			err := verifier.VerifyDomain(ctx, tt.domain, tt.token)
+			if tt.reason == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if reason := FailureReason(err); reason != tt.reason {
+				t.Errorf("FailureReason = %q, expected %q", reason, tt.reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package verification
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/skygeario/k8s-controller/pkg/util/dnsname"
+)
+
+const cnameChallengeLabel = "_skygear-challenge"
+
+// MakeCNAMERecord returns the CNAME record delegating verification of the
+// domain to the challenge zone. The target does not depend on verification
+// key, so the record remains valid when the key is rotated.
+func MakeCNAMERecord(domain string, nonce string, zone string) (name string, target string) {
+	// wildcard domains are verified at the apex domain
+	domain = dnsname.TrimWildcard(dnsname.DomainName(domain))
+
+	h := sha256.Sum256([]byte(domain + "/" + nonce))
+	name = fmt.Sprintf("%s.%s", cnameChallengeLabel, domain)
+	target = fmt.Sprintf("%s.%s", hex.EncodeToString(h[:16]), strings.TrimSuffix(zone, "."))
+	return
+}
+
+// CNAMEVerifier verifies domain ownership by looking up the CNAME record
+// delegating to the challenge zone.
+type CNAMEVerifier struct {
+	Resolver Resolver
+}
+
+func NewCNAMEVerifier(resolver Resolver) *CNAMEVerifier {
+	return &CNAMEVerifier{Resolver: resolver}
+}
+
+// MakeTokenCNAMERecordName returns the name of CNAME record encoding the
+// verification token, for DNS hosting that does not allow underscore TXT
+// records. The record points to the verification target of the platform.
+func MakeTokenCNAMERecordName(domain string, token string) string {
+	// wildcard domains are verified at the apex domain
+	domain = dnsname.TrimWildcard(dnsname.DomainName(domain))
+
+	h := sha256.Sum256([]byte(token))
+	return fmt.Sprintf("%s.%s", hex.EncodeToString(h[:16]), domain)
+}
+
+// VerifyDomain verifies the challenge CNAME record of domain points to target.
+func (v *CNAMEVerifier) VerifyDomain(ctx context.Context, domain string, target string) error {
+	domain = dnsname.TrimWildcard(dnsname.DomainName(domain))
+	return v.VerifyRecord(ctx, fmt.Sprintf("%s.%s", cnameChallengeLabel, domain), target)
+}
+
+// VerifyTokenRecord verifies the CNAME record encoding token of domain, see
+// MakeTokenCNAMERecordName, points to target.
+func (v *CNAMEVerifier) VerifyTokenRecord(ctx context.Context, domain string, token string, target string) error {
+	return v.VerifyRecord(ctx, MakeTokenCNAMERecordName(domain, token), target)
+}
+
+// VerifyRecord verifies the CNAME record points to target.
+func (v *CNAMEVerifier) VerifyRecord(ctx context.Context, recordName string, target string) error {
+	details := FailureDetails{
+		RecordName:    recordName,
+		ExpectedValue: target,
+		Resolver:      ResolverName(v.Resolver),
+	}
+
+	cname, err := v.Resolver.LookupCNAME(ctx, recordName)
+	if err != nil {
+		return withDetails(newLookupError(err), details)
+	}
+	if cname == "" {
+		return withDetails(errEmptyAnswer, details)
+	}
+
+	if !strings.EqualFold(strings.TrimSuffix(cname, "."), strings.TrimSuffix(target, ".")) {
+		details.ObservedValues = []string{cname}
+		return withDetails(errRecordNotFound, details)
+	}
+	return nil
+}
@@ -1,15 +1,58 @@
 package verification
 
 import (
+	"context"
 	"fmt"
+	"regexp"
+	"strings"
 
 	"golang.org/x/net/publicsuffix"
+
+	"github.com/skygeario/k8s-controller/pkg/util/dnsname"
 )
 
-func MakeDNSRecordName(domain string) (string, error) {
+// DefaultDNSRecordPrefix is the label prepended to root domain to form the
+// name of verification TXT record, when no prefix is configured.
+const DefaultDNSRecordPrefix = "_skygear"
+
+var recordPrefixPattern = regexp.MustCompile(`^[A-Za-z0-9_]([A-Za-z0-9_-]{0,61}[A-Za-z0-9_])?$`)
+
+// ValidateDNSRecordPrefix checks the prefix is a single valid DNS label.
+func ValidateDNSRecordPrefix(prefix string) error {
+	if !recordPrefixPattern.MatchString(prefix) {
+		return fmt.Errorf("invalid verification DNS record prefix '%s'", prefix)
+	}
+	return nil
+}
+
+type recordPrefixKey struct{}
+
+// WithRecordPrefix returns a context using the prefix to form the name of
+// verification TXT record.
+func WithRecordPrefix(ctx context.Context, prefix string) context.Context {
+	if prefix == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, recordPrefixKey{}, prefix)
+}
+
+func recordPrefix(ctx context.Context) string {
+	if prefix, ok := ctx.Value(recordPrefixKey{}).(string); ok {
+		return prefix
+	}
+	return DefaultDNSRecordPrefix
+}
+
+func MakeDNSRecordName(domain string, prefix string) (string, error) {
+	// wildcard domains are verified at the apex domain
+	domain = dnsname.TrimWildcard(dnsname.DomainName(domain))
 	rootDomain, err := publicsuffix.EffectiveTLDPlusOne(domain)
 	if err != nil {
 		return "", err
 	}
-	return fmt.Sprintf("_skygear.%s", rootDomain), nil
+	name := fmt.Sprintf("%s.%s", prefix, rootDomain)
+	if err := dnsname.ValidateRecordName(strings.ToLower(name)); err != nil {
+		return "", err
+	}
+	return name, nil
 }
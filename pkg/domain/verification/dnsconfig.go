@@ -0,0 +1,180 @@
+package verification
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/skygeario/k8s-controller/pkg/util/dnsname"
+)
+
+// dnsCheckProbeLabel is the label substituting wildcard when checking DNS
+// records of wildcard domains.
+const dnsCheckProbeLabel = "skygear-dns-check"
+
+// AddressResolver resolves addresses and canonical names of hosts.
+type AddressResolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+	LookupCNAME(ctx context.Context, host string) (string, error)
+}
+
+// DNSRecord is a DNS record expected to be configured for the domain.
+type DNSRecord struct {
+	Name  string
+	Type  string
+	Value string
+}
+
+// DNSRecordResult is the result of checking a DNS record.
+type DNSRecordResult struct {
+	DNSRecord
+	ObservedValues []string
+	Configured     bool
+}
+
+// DNSConfigChecker checks whether DNS records of domain point to the
+// expected load balancer targets.
+type DNSConfigChecker struct {
+	Resolver AddressResolver
+}
+
+func NewDNSConfigChecker(resolver AddressResolver) *DNSConfigChecker {
+	return &DNSConfigChecker{Resolver: resolver}
+}
+
+// CheckDNSConfig checks the A, AAAA and CNAME records of domain. The domain
+// is configured if it resolves only to expected targets.
+func (c *DNSConfigChecker) CheckDNSConfig(ctx context.Context, domain string, records []DNSRecord) (configured bool, results []DNSRecordResult, err error) {
+	host := dnsname.DomainName(domain)
+	if dnsname.IsWildcard(host) {
+		host = dnsCheckProbeLabel + "." + dnsname.TrimWildcard(host)
+	}
+
+	addrs, err := c.lookupAddresses(ctx, host)
+	if err != nil {
+		return false, nil, newDNSConfigLookupError(err)
+	}
+
+	expectedAddrs := map[string]bool{}
+	hasCNAME := false
+	for _, record := range records {
+		switch record.Type {
+		case "A", "AAAA":
+			expectedAddrs[canonicalIP(record.Value)] = true
+		case "CNAME":
+			hasCNAME = true
+		}
+	}
+
+	configured = len(addrs) > 0
+	for _, record := range records {
+		result := DNSRecordResult{DNSRecord: record}
+		switch record.Type {
+		case "A", "AAAA":
+			result.ObservedValues = addressesOfType(addrs, record.Type)
+			result.Configured = containsString(result.ObservedValues, canonicalIP(record.Value))
+		case "CNAME":
+			result.Configured, result.ObservedValues, err = c.checkCNAME(ctx, host, addrs, record.Value)
+			if err != nil {
+				return false, nil, newDNSConfigLookupError(err)
+			}
+			configured = configured && result.Configured
+		default:
+			continue
+		}
+		results = append(results, result)
+	}
+
+	if !hasCNAME {
+		for _, addr := range addrs {
+			if !expectedAddrs[addr] {
+				configured = false
+			}
+		}
+	}
+	return configured, results, nil
+}
+
+func (c *DNSConfigChecker) checkCNAME(ctx context.Context, host string, addrs []string, target string) (bool, []string, error) {
+	cname, err := c.Resolver.LookupCNAME(ctx, host)
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return false, nil, nil
+	} else if err != nil {
+		return false, nil, err
+	}
+
+	cname = strings.TrimSuffix(cname, ".")
+	if strings.EqualFold(cname, host) {
+		// no CNAME record
+		return false, addrs, nil
+	}
+	if strings.EqualFold(cname, strings.TrimSuffix(target, ".")) {
+		return true, []string{cname}, nil
+	}
+
+	// The target may be an alias of another name, e.g. a CDN; accept if
+	// the domain resolves to the addresses of target.
+	targetAddrs, err := c.lookupAddresses(ctx, target)
+	if err != nil {
+		return false, nil, err
+	}
+	configured := len(addrs) > 0
+	for _, addr := range addrs {
+		if !containsString(targetAddrs, addr) {
+			configured = false
+		}
+	}
+	return configured, []string{cname}, nil
+}
+
+func (c *DNSConfigChecker) lookupAddresses(ctx context.Context, host string) ([]string, error) {
+	ipAddrs, err := c.Resolver.LookupIPAddr(ctx, host)
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	addrs := make([]string, len(ipAddrs))
+	for i, addr := range ipAddrs {
+		addrs[i] = addr.IP.String()
+	}
+	return addrs, nil
+}
+
+// addressesOfType returns the addresses of the family of record type, i.e.
+// IPv4 addresses for A records and IPv6 addresses for AAAA records.
+func addressesOfType(addrs []string, recordType string) []string {
+	var result []string
+	for _, addr := range addrs {
+		ip := net.ParseIP(addr)
+		if ip == nil {
+			continue
+		}
+		if (ip.To4() != nil) == (recordType == "A") {
+			result = append(result, addr)
+		}
+	}
+	return result
+}
+
+// canonicalIP returns the canonical form of IP address, so that IPv6
+// addresses written differently are compared equal.
+func canonicalIP(value string) string {
+	if ip := net.ParseIP(value); ip != nil {
+		return ip.String()
+	}
+	return value
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,176 @@
+package verification
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/skygeario/k8s-controller/pkg/util/dnsname"
+)
+
+// EmailConfirmation identifies the registration confirmed by a link sent in
+// Email verification.
+type EmailConfirmation struct {
+	Namespace  string
+	Name       string
+	Domain     string
+	Generation int64
+	Expiry     time.Time
+}
+
+// Message returns the message signed with verification key of the domain.
+// It is prefixed so that signatures are not valid attestations.
+func (c *EmailConfirmation) Message() []byte {
+	return []byte(fmt.Sprintf("email-confirmation\n%s/%s\n%s\n%d\n%d", c.Namespace, c.Name, c.Domain, c.Generation, c.Expiry.Unix()))
+}
+
+// Query returns the query parameters of confirmation link.
+func (c *EmailConfirmation) Query(signature string) url.Values {
+	return url.Values{
+		"namespace":  {c.Namespace},
+		"name":       {c.Name},
+		"domain":     {c.Domain},
+		"generation": {strconv.FormatInt(c.Generation, 10)},
+		"expires":    {strconv.FormatInt(c.Expiry.Unix(), 10)},
+		"signature":  {signature},
+	}
+}
+
+// ParseEmailConfirmation parses query parameters of confirmation link.
+func ParseEmailConfirmation(query url.Values) (c *EmailConfirmation, signature string, err error) {
+	generation, err := strconv.ParseInt(query.Get("generation"), 10, 64)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid generation: %w", err)
+	}
+	expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid expiry: %w", err)
+	}
+	c = &EmailConfirmation{
+		Namespace:  query.Get("namespace"),
+		Name:       query.Get("name"),
+		Domain:     query.Get("domain"),
+		Generation: generation,
+		Expiry:     time.Unix(expires, 0),
+	}
+	signature = query.Get("signature")
+	if c.Namespace == "" || c.Name == "" || c.Domain == "" || signature == "" {
+		return nil, "", fmt.Errorf("missing required parameters")
+	}
+	return c, signature, nil
+}
+
+// MaskEmail masks the local part of email address, so that contacts are not
+// disclosed in status of registrations.
+func MaskEmail(email string) string {
+	i := strings.LastIndex(email, "@")
+	if i <= 0 {
+		return "***"
+	}
+	return email[:1] + "***" + email[i:]
+}
+
+// DefaultEmailBudgetPeriod is the default period of NamespaceBudget.
+const DefaultEmailBudgetPeriod = 24 * time.Hour
+
+// EmailChallenger sends confirmation links to contact emails of domains
+// found in RDAP. The links are served by the attestation endpoint, which
+// attests ownership of the domain when a link is confirmed.
+type EmailChallenger struct {
+	RDAP   *RDAPClient
+	Mailer Mailer
+	// ConfirmationURL is the public URL of email confirmation endpoint.
+	ConfirmationURL string
+	// NamespaceBudget is the maximum number of challenges sent for
+	// registrations not approved by operators in a namespace per
+	// BudgetPeriod, so that tenants cannot send emails to contacts of
+	// arbitrary domains at will. Challenges of unapproved registrations are
+	// never sent if zero.
+	NamespaceBudget int
+	// BudgetPeriod is the period of NamespaceBudget, defaults to
+	// DefaultEmailBudgetPeriod.
+	BudgetPeriod time.Duration
+
+	lock    sync.Mutex
+	budgets map[string]*rate.Limiter
+}
+
+// allow returns whether a challenge of unapproved registration in the
+// namespace can be sent, consuming the budget of namespace.
+func (c *EmailChallenger) allow(namespace string) bool {
+	if c.NamespaceBudget <= 0 {
+		return false
+	}
+	period := c.BudgetPeriod
+	if period <= 0 {
+		period = DefaultEmailBudgetPeriod
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.budgets == nil {
+		c.budgets = map[string]*rate.Limiter{}
+	}
+	budget, ok := c.budgets[namespace]
+	if !ok {
+		budget = rate.NewLimiter(rate.Every(period/time.Duration(c.NamespaceBudget)), c.NamespaceBudget)
+		c.budgets[namespace] = budget
+	}
+	return budget.Allow()
+}
+
+// SendChallenge sends confirmation link of the registration to contacts of
+// the registrable domain, signed with verification key of the domain. It
+// returns the recipients that the link is sent to. Challenges of
+// registrations not approved by operators are limited by NamespaceBudget.
+func (c *EmailChallenger) SendChallenge(ctx context.Context, key string, confirmation EmailConfirmation, approved bool) (recipients []string, err error) {
+	zone := dnsname.Zone(confirmation.Domain)
+	if zone == "" {
+		return nil, &Error{
+			Reason: ReasonContactNotFound,
+			Err:    fmt.Errorf("domain %s is not under a registrable domain", confirmation.Domain),
+		}
+	}
+	emails, err := c.RDAP.LookupContactEmails(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+	if len(emails) == 0 {
+		return nil, &Error{
+			Reason: ReasonContactNotFound,
+			Err:    fmt.Errorf("no contact emails of %s are found in RDAP", zone),
+		}
+	}
+
+	if !approved && !c.allow(confirmation.Namespace) {
+		return nil, &Error{
+			Reason: ReasonEmailBudgetExceeded,
+			Err:    fmt.Errorf("confirmation email budget of namespace %s is exhausted; approval of operators is required", confirmation.Namespace),
+		}
+	}
+
+	signature := SignAttestation(key, confirmation.Message())
+	link := c.ConfirmationURL + "?" + confirmation.Query(signature).Encode()
+	subject := fmt.Sprintf("Confirm use of domain %s", confirmation.Domain)
+	body := fmt.Sprintf(
+		"A request was made to use the domain %s, and you are listed as a contact of %s.\n\n"+
+			"If you made or approve this request, confirm it by opening the link below before %s:\n\n"+
+			"%s\n\n"+
+			"If you do not recognize this request, you can ignore this email.\n",
+		confirmation.Domain, zone, confirmation.Expiry.UTC().Format(time.RFC1123), link,
+	)
+
+	for _, email := range emails {
+		if err := c.Mailer.SendMail(ctx, email, subject, body); err != nil {
+			return recipients, newEmailError(err)
+		}
+		recipients = append(recipients, email)
+	}
+	return recipients, nil
+}
@@ -0,0 +1,148 @@
+package verification
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+type sentMail struct {
+	to   string
+	body string
+}
+
+type fakeMailer struct {
+	sent []sentMail
+}
+
+func (m *fakeMailer) SendMail(ctx context.Context, to string, subject string, body string) error {
+	m.sent = append(m.sent, sentMail{to: to, body: body})
+	return nil
+}
+
+const rdapResponse = `{
+  "objectClassName": "domain",
+  "entities": [
+    {
+      "roles": ["registrant"],
+      "vcardArray": ["vcard", [["version", {}, "text", "4.0"], ["email", {}, "text", "Owner@Example.com"]]]
+    },
+    {
+      "roles": ["registrar"],
+      "vcardArray": ["vcard", [["email", {}, "text", "abuse@registrar.test"]]],
+      "entities": [
+        {
+          "roles": ["technical"],
+          "vcardArray": ["vcard", [["email", {}, "text", "tech@example.com"]]]
+        }
+      ]
+    }
+  ]
+}`
+
+func newTestEmailChallenger(t *testing.T, budget int) (*EmailChallenger, *fakeMailer) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/domain/example.com" {
+			http.NotFound(rw, r)
+			return
+		}
+		rw.Header().Set("Content-Type", "application/rdap+json")
+		_, _ = rw.Write([]byte(rdapResponse))
+	}))
+	t.Cleanup(server.Close)
+
+	mailer := &fakeMailer{}
+	return &EmailChallenger{
+		RDAP:            NewRDAPClient(server.URL, server.Client()),
+		Mailer:          mailer,
+		ConfirmationURL: "https://attestation.test/email-confirmation",
+		NamespaceBudget: budget,
+	}, mailer
+}
+
+func TestSendChallenge(t *testing.T) {
+	c, mailer := newTestEmailChallenger(t, 1)
+	confirmation := EmailConfirmation{
+		Namespace:  "app",
+		Name:       "www.example.com",
+		Domain:     "www.example.com",
+		Generation: 1,
+		Expiry:     time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	recipients, err := c.SendChallenge(context.Background(), "key", confirmation, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(recipients, ",") != "owner@example.com,tech@example.com" {
+		t.Errorf("recipients = %v", recipients)
+	}
+	if len(mailer.sent) != 2 {
+		t.Fatalf("sent %d emails, expected 2", len(mailer.sent))
+	}
+
+	// Confirmation link is signed with the key.
+	body := mailer.sent[0].body
+	i := strings.Index(body, c.ConfirmationURL+"?")
+	if i < 0 {
+		t.Fatalf("confirmation link not found: %s", body)
+	}
+	link, err := url.Parse(strings.Fields(body[i:])[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, signature, err := ParseEmailConfirmation(link.Query())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(parsed.Message()) != string(confirmation.Message()) {
+		t.Errorf("confirmation = %#v, expected %#v", parsed, confirmation)
+	}
+	if signature != SignAttestation("key", confirmation.Message()) {
+		t.Errorf("invalid signature")
+	}
+}
+
+func TestSendChallengeBudget(t *testing.T) {
+	c, mailer := newTestEmailChallenger(t, 2)
+	send := func(namespace string, approved bool) error {
+		_, err := c.SendChallenge(context.Background(), "key", EmailConfirmation{
+			Namespace: namespace,
+			Name:      "example.com",
+			Domain:    "example.com",
+			Expiry:    time.Now().Add(time.Hour),
+		}, approved)
+		return err
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := send("app", false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := send("app", false); FailureReason(err) != ReasonEmailBudgetExceeded {
+		t.Errorf("expected budget exceeded, got %v", err)
+	}
+	if err := send("app", true); err != nil {
+		t.Errorf("approved registration is limited: %v", err)
+	}
+	if err := send("other", false); err != nil {
+		t.Errorf("budget is shared across namespaces: %v", err)
+	}
+	if len(mailer.sent) != 8 {
+		t.Errorf("sent %d emails, expected 8", len(mailer.sent))
+	}
+
+	// Unapproved registrations cannot send without budget.
+	c, mailer = newTestEmailChallenger(t, 0)
+	if err := send("app", false); FailureReason(err) != ReasonEmailBudgetExceeded {
+		t.Errorf("expected budget exceeded, got %v", err)
+	}
+	if len(mailer.sent) != 0 {
+		t.Errorf("sent %d emails without budget", len(mailer.sent))
+	}
+}
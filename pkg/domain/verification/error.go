@@ -0,0 +1,179 @@
+package verification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+)
+
+const (
+	// ReasonRecordNotFound indicates the verification DNS record is not found.
+	ReasonRecordNotFound = "RecordNotFound"
+	// ReasonEmptyAnswer indicates the DNS server answered without any record.
+	ReasonEmptyAnswer = "EmptyAnswer"
+	// ReasonLookupTimeout indicates the DNS server did not answer in time.
+	ReasonLookupTimeout = "LookupTimeout"
+	// ReasonServerFailure indicates the DNS server failed to answer the query
+	// (e.g. SERVFAIL or REFUSED), usually due to misconfigured nameservers.
+	ReasonServerFailure = "ServerFailure"
+	// ReasonLookupFailed indicates the verification DNS record cannot be looked up.
+	ReasonLookupFailed = "LookupFailed"
+	// ReasonRequestFailed indicates the verification token cannot be fetched.
+	ReasonRequestFailed = "RequestFailed"
+	// ReasonTokenMismatch indicates the fetched verification token is incorrect.
+	ReasonTokenMismatch = "TokenMismatch"
+	// ReasonRDAPLookupFailed indicates contacts of domain cannot be looked up
+	// in RDAP.
+	ReasonRDAPLookupFailed = "RDAPLookupFailed"
+	// ReasonContactNotFound indicates no contact emails of domain are found
+	// in RDAP, e.g. redacted by the registry.
+	ReasonContactNotFound = "ContactNotFound"
+	// ReasonEmailFailed indicates the confirmation email cannot be sent.
+	ReasonEmailFailed = "EmailFailed"
+	// ReasonEmailBudgetExceeded indicates the confirmation email is not sent,
+	// since the namespace has sent too many confirmation emails for
+	// registrations not approved by operators.
+	ReasonEmailBudgetExceeded = "EmailBudgetExceeded"
+)
+
+// FailureDetails describes what is observed in a failed verification.
+type FailureDetails struct {
+	// RecordName is the DNS record name or URL queried.
+	RecordName string
+	// ExpectedValue is the expected record value or token.
+	ExpectedValue string
+	// ObservedValues are the record values actually observed. HTTP response
+	// bodies are never recorded.
+	ObservedValues []string
+	// Resolver is the DNS resolver used.
+	Resolver string
+}
+
+// Error is a domain verification failure.
+type Error struct {
+	Reason  string
+	Err     error
+	Details *FailureDetails
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// FailureReason returns the reason of verification failure, or empty string
+// if err is not a verification failure.
+func FailureReason(err error) string {
+	var verr *Error
+	if errors.As(err, &verr) {
+		return verr.Reason
+	}
+	return ""
+}
+
+// IsConclusive returns whether the verification failure shows the
+// verification record is missing or mismatched, rather than cannot be
+// checked, e.g. due to lookup timeouts or server failures.
+func IsConclusive(err error) bool {
+	switch FailureReason(err) {
+	case ReasonRecordNotFound, ReasonTokenMismatch:
+		return true
+	}
+	return false
+}
+
+// GetFailureDetails returns the details of verification failure, or nil if
+// not available.
+func GetFailureDetails(err error) *FailureDetails {
+	var verr *Error
+	if errors.As(err, &verr) {
+		return verr.Details
+	}
+	return nil
+}
+
+func withDetails(err error, details FailureDetails) error {
+	var verr *Error
+	if !errors.As(err, &verr) {
+		return err
+	}
+	return &Error{Reason: verr.Reason, Err: verr.Err, Details: &details}
+}
+
+// dnsServerFailureMessage is the error message of Go resolvers for SERVFAIL
+// and REFUSED responses.
+const dnsServerFailureMessage = "server misbehaving"
+
+// lookupFailureReason classifies the DNS lookup error. Go resolvers report
+// both NXDOMAIN and answers without records of the queried type as not
+// found.
+func lookupFailureReason(err error) string {
+	var dnsErr *net.DNSError
+	switch {
+	case errors.As(err, &dnsErr) && dnsErr.IsNotFound:
+		return ReasonRecordNotFound
+	case errors.As(err, &dnsErr) && dnsErr.IsTimeout, errors.Is(err, context.DeadlineExceeded):
+		return ReasonLookupTimeout
+	case errors.As(err, &dnsErr) && dnsErr.Err == dnsServerFailureMessage:
+		return ReasonServerFailure
+	}
+	return ReasonLookupFailed
+}
+
+func newLookupError(err error) error {
+	reason := lookupFailureReason(err)
+	if reason == ReasonRecordNotFound {
+		return errRecordNotFound
+	}
+	return &Error{
+		Reason: reason,
+		Err:    fmt.Errorf("cannot lookup verification DNS record: %w", err),
+	}
+}
+
+func newDNSConfigLookupError(err error) error {
+	return &Error{
+		Reason: lookupFailureReason(err),
+		Err:    fmt.Errorf("cannot lookup DNS records of domain: %w", err),
+	}
+}
+
+var errRecordNotFound = &Error{
+	Reason: ReasonRecordNotFound,
+	Err:    errors.New("verification DNS record not found"),
+}
+
+var errEmptyAnswer = &Error{
+	Reason: ReasonEmptyAnswer,
+	Err:    errors.New("verification DNS record has no values"),
+}
+
+func newRequestError(err error) error {
+	return &Error{
+		Reason: ReasonRequestFailed,
+		Err:    fmt.Errorf("cannot fetch verification token: %w", err),
+	}
+}
+
+func newRDAPError(err error) error {
+	return &Error{
+		Reason: ReasonRDAPLookupFailed,
+		Err:    fmt.Errorf("cannot lookup domain contacts in RDAP: %w", err),
+	}
+}
+
+func newEmailError(err error) error {
+	return &Error{
+		Reason: ReasonEmailFailed,
+		Err:    fmt.Errorf("cannot send confirmation email: %w", err),
+	}
+}
+
+var errTokenMismatch = &Error{
+	Reason: ReasonTokenMismatch,
+	Err:    errors.New("verification token mismatch"),
+}
@@ -0,0 +1,69 @@
+package verification
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/skygeario/k8s-controller/pkg/util/dnsname"
+)
+
+const httpVerificationPathPrefix = "/.well-known/skygear-domain-verification/"
+
+// maxHTTPResponseSize limits the size of verification response body read.
+const maxHTTPResponseSize = 4096
+
+// MakeHTTPVerificationURL returns the URL serving the verification token.
+func MakeHTTPVerificationURL(domain string, token string) string {
+	// wildcard domains are verified at the apex domain
+	domain = dnsname.TrimWildcard(dnsname.DomainName(domain))
+	return fmt.Sprintf("http://%s%s%s", domain, httpVerificationPathPrefix, token)
+}
+
+// HTTPVerifier verifies domain ownership by fetching verification token
+// served by the domain.
+type HTTPVerifier struct {
+	Client *http.Client
+}
+
+func NewHTTPVerifier(client *http.Client) *HTTPVerifier {
+	return &HTTPVerifier{Client: client}
+}
+
+func (v *HTTPVerifier) VerifyDomain(ctx context.Context, domain string, token string) error {
+	url := MakeHTTPVerificationURL(domain, token)
+	details := FailureDetails{
+		RecordName:    url,
+		ExpectedValue: token,
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("cannot request verification token: %w", err)
+	}
+
+	resp, err := v.Client.Do(req)
+	if err != nil {
+		return withDetails(newRequestError(err), details)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return withDetails(newRequestError(fmt.Errorf("unexpected status code %d", resp.StatusCode)), details)
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxHTTPResponseSize))
+	if err != nil {
+		return withDetails(newRequestError(err), details)
+	}
+
+	// Response body is not recorded in details, as it may be content of
+	// arbitrary URL that the domain redirects to.
+	if strings.TrimSpace(string(body)) != token {
+		return withDetails(errTokenMismatch, details)
+	}
+	return nil
+}
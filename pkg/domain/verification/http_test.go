@@ -0,0 +1,71 @@
+package verification
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// serverClient returns a HTTP client sending requests of any host to the
+// server.
+func serverClient(server *httptest.Server) *http.Client {
+	u, _ := url.Parse(server.URL)
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, u.Host)
+			},
+		},
+	}
+}
+
+func TestHTTPVerifier(t *testing.T) {
+	const token = "verification-token"
+
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		reason string
+	}{
+		{"token served", http.StatusOK, token + "\n", ""},
+		{"token mismatch", http.StatusOK, "aws-secret-access-key", ReasonTokenMismatch},
+		{"not found", http.StatusNotFound, "", ReasonRequestFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var path string
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				path = r.URL.Path
+				rw.WriteHeader(tt.status)
+				_, _ = rw.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			err := NewHTTPVerifier(serverClient(server)).VerifyDomain(context.Background(), "example.com", token)
+			if path != httpVerificationPathPrefix+token {
+				t.Errorf("requested path = %q", path)
+			}
+			if tt.reason == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if reason := FailureReason(err); reason != tt.reason {
+				t.Errorf("FailureReason = %q, expected %q", reason, tt.reason)
+			}
+			details := GetFailureDetails(err)
+			if details == nil {
+				t.Fatal("expected failure details")
+			}
+			if len(details.ObservedValues) != 0 {
+				t.Errorf("response body is recorded: %v", details.ObservedValues)
+			}
+		})
+	}
+}
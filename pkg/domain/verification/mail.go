@@ -0,0 +1,55 @@
+package verification
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"time"
+)
+
+// Mailer sends emails.
+type Mailer interface {
+	SendMail(ctx context.Context, to string, subject string, body string) error
+}
+
+// SMTPMailer sends emails through an SMTP relay, e.g. Amazon SES SMTP
+// interface. STARTTLS is used if supported by the relay.
+type SMTPMailer struct {
+	// Address is the host:port of SMTP relay.
+	Address string
+	// Username and Password authenticate with the relay using PLAIN
+	// authentication, if Username is not empty.
+	Username string
+	Password string
+	// From is the sender address.
+	From string
+}
+
+var _ Mailer = &SMTPMailer{}
+
+// SendMail sends a plain text email. The context is not honored, since
+// net/smtp does not support cancellation.
+func (m *SMTPMailer) SendMail(ctx context.Context, to string, subject string, body string) error {
+	var auth smtp.Auth
+	if m.Username != "" {
+		host, _, err := net.SplitHostPort(m.Address)
+		if err != nil {
+			return err
+		}
+		auth = smtp.PlainAuth("", m.Username, m.Password, host)
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", m.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: text/plain; charset=utf-8\r\n")
+	fmt.Fprintf(&msg, "\r\n%s", body)
+
+	return smtp.SendMail(m.Address, auth, m.From, []string{to}, msg.Bytes())
+}
@@ -0,0 +1,118 @@
+package verification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+)
+
+// QuorumResolver queries multiple resolvers, and accepts only records
+// returned by at least Quorum resolvers. This guards against a single stale
+// or poisoned resolver, or split-horizon DNS, affecting verification result.
+type QuorumResolver struct {
+	Resolvers []Resolver
+	Quorum    int
+}
+
+// NewQuorumResolver creates a QuorumResolver. Quorum defaults to majority of
+// resolvers if not positive, and is at most the number of resolvers.
+func NewQuorumResolver(resolvers []Resolver, quorum int) *QuorumResolver {
+	if quorum <= 0 {
+		quorum = len(resolvers)/2 + 1
+	}
+	if quorum > len(resolvers) {
+		quorum = len(resolvers)
+	}
+	return &QuorumResolver{Resolvers: resolvers, Quorum: quorum}
+}
+
+var _ Resolver = &QuorumResolver{}
+
+func (r *QuorumResolver) String() string {
+	names := make([]string, len(r.Resolvers))
+	for i, resolver := range r.Resolvers {
+		names[i] = ResolverName(resolver)
+	}
+	return fmt.Sprintf("quorum of %d (%s)", r.Quorum, strings.Join(names, "; "))
+}
+
+type quorumResult struct {
+	values []string
+	err    error
+}
+
+func (r *QuorumResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	results := r.lookup(func(resolver Resolver) quorumResult {
+		records, err := resolver.LookupTXT(ctx, name)
+		return quorumResult{values: records, err: err}
+	})
+	return r.agree(name, results)
+}
+
+func (r *QuorumResolver) LookupCNAME(ctx context.Context, name string) (string, error) {
+	results := r.lookup(func(resolver Resolver) quorumResult {
+		cname, err := resolver.LookupCNAME(ctx, name)
+		if err != nil {
+			return quorumResult{err: err}
+		}
+		return quorumResult{values: []string{strings.ToLower(strings.TrimSuffix(cname, "."))}}
+	})
+	values, err := r.agree(name, results)
+	if err != nil {
+		return "", err
+	}
+	return values[0], nil
+}
+
+func (r *QuorumResolver) lookup(lookup func(resolver Resolver) quorumResult) []quorumResult {
+	results := make([]quorumResult, len(r.Resolvers))
+	var wg sync.WaitGroup
+	for i, resolver := range r.Resolvers {
+		wg.Add(1)
+		go func(i int, resolver Resolver) {
+			defer wg.Done()
+			results[i] = lookup(resolver)
+		}(i, resolver)
+	}
+	wg.Wait()
+	return results
+}
+
+// agree returns the values returned by at least quorum of resolvers.
+func (r *QuorumResolver) agree(name string, results []quorumResult) ([]string, error) {
+	counts := map[string]int{}
+	var agreed []string
+	answered := 0
+	var lastErr error
+	for _, result := range results {
+		var dnsErr *net.DNSError
+		if result.err != nil && !(errors.As(result.err, &dnsErr) && dnsErr.IsNotFound) {
+			lastErr = result.err
+			continue
+		}
+		answered++
+
+		seen := map[string]bool{}
+		for _, value := range result.values {
+			if seen[value] {
+				continue
+			}
+			seen[value] = true
+			counts[value]++
+			if counts[value] == r.Quorum {
+				agreed = append(agreed, value)
+			}
+		}
+	}
+
+	if len(agreed) > 0 {
+		return agreed, nil
+	}
+	if answered < r.Quorum {
+		return nil, fmt.Errorf("only %d of %d resolvers answered, quorum is %d: %w", answered, len(results), r.Quorum, lastErr)
+	}
+	return nil, &net.DNSError{Err: "no records agreed by quorum of resolvers", Name: name, IsNotFound: true}
+}
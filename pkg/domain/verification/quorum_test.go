@@ -0,0 +1,186 @@
+package verification
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// fakeResolver answers lookups from fixed records, and counts lookups.
+type fakeResolver struct {
+	txt     map[string][]string
+	cname   map[string]string
+	err     error
+	lookups int
+}
+
+var _ Resolver = &fakeResolver{}
+
+func (r *fakeResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	r.lookups++
+	if r.err != nil {
+		return nil, r.err
+	}
+	records, ok := r.txt[name]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return records, nil
+}
+
+func (r *fakeResolver) LookupCNAME(ctx context.Context, name string) (string, error) {
+	r.lookups++
+	if r.err != nil {
+		return "", r.err
+	}
+	cname, ok := r.cname[name]
+	if !ok {
+		return "", &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return cname, nil
+}
+
+func txtResolver(records ...string) *fakeResolver {
+	return &fakeResolver{txt: map[string][]string{"example.com": records}}
+}
+
+func TestNewQuorumResolver(t *testing.T) {
+	resolvers := func(n int) []Resolver {
+		r := make([]Resolver, n)
+		for i := range r {
+			r[i] = &fakeResolver{}
+		}
+		return r
+	}
+	tests := []struct {
+		resolvers int
+		quorum    int
+		expected  int
+	}{
+		{1, 0, 1},
+		{2, 0, 2},
+		{3, 0, 2},
+		{4, 0, 3},
+		{3, 1, 1},
+		{3, 5, 3},
+	}
+	for _, tt := range tests {
+		r := NewQuorumResolver(resolvers(tt.resolvers), tt.quorum)
+		if r.Quorum != tt.expected {
+			t.Errorf("NewQuorumResolver(%d resolvers, %d).Quorum = %d, expected %d", tt.resolvers, tt.quorum, r.Quorum, tt.expected)
+		}
+	}
+}
+
+func TestQuorumResolverLookupTXT(t *testing.T) {
+	// Resolvers are queried concurrently, so each is used only once.
+	unreachable := func() Resolver { return &fakeResolver{err: errors.New("connection refused")} }
+	notFound := func() Resolver { return &fakeResolver{} }
+
+	tests := []struct {
+		name      string
+		resolvers []Resolver
+		expected  []string
+		notFound  bool
+		err       bool
+	}{
+		{
+			name:      "all agree",
+			resolvers: []Resolver{txtResolver("a", "b"), txtResolver("b", "a"), txtResolver("a", "b")},
+			expected:  []string{"a", "b"},
+		},
+		{
+			name:      "one disagrees",
+			resolvers: []Resolver{txtResolver("a"), txtResolver("poisoned"), txtResolver("a")},
+			expected:  []string{"a"},
+		},
+		{
+			name:      "partial agreement",
+			resolvers: []Resolver{txtResolver("a", "b"), txtResolver("a"), txtResolver("b", "c")},
+			expected:  []string{"a", "b"},
+		},
+		{
+			name:      "no agreement",
+			resolvers: []Resolver{txtResolver("a"), txtResolver("b"), txtResolver("c")},
+			notFound:  true,
+		},
+		{
+			name:      "duplicated records counted once",
+			resolvers: []Resolver{txtResolver("a", "a"), txtResolver("b"), txtResolver("c")},
+			notFound:  true,
+		},
+		{
+			name:      "not found counts as answer",
+			resolvers: []Resolver{txtResolver("a"), notFound(), notFound()},
+			notFound:  true,
+		},
+		{
+			name:      "tolerates unreachable minority",
+			resolvers: []Resolver{txtResolver("a"), unreachable(), txtResolver("a")},
+			expected:  []string{"a"},
+		},
+		{
+			name:      "unreachable majority",
+			resolvers: []Resolver{txtResolver("a"), unreachable(), unreachable()},
+			err:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewQuorumResolver(tt.resolvers, 0)
+			records, err := r.LookupTXT(context.Background(), "example.com")
+
+			var dnsErr *net.DNSError
+			isNotFound := errors.As(err, &dnsErr) && dnsErr.IsNotFound
+			switch {
+			case tt.notFound:
+				if !isNotFound {
+					t.Errorf("expected not found error, got %v, %v", records, err)
+				}
+			case tt.err:
+				if err == nil || isNotFound {
+					t.Errorf("expected lookup error, got %v, %v", records, err)
+				}
+			default:
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				sort.Strings(records)
+				if !reflect.DeepEqual(records, tt.expected) {
+					t.Errorf("records = %v, expected %v", records, tt.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestQuorumResolverLookupCNAME(t *testing.T) {
+	cnameResolver := func(cname string) Resolver {
+		return &fakeResolver{cname: map[string]string{"www.example.com": cname}}
+	}
+
+	r := NewQuorumResolver([]Resolver{
+		cnameResolver("Target.Example.NET."),
+		cnameResolver("target.example.net"),
+		cnameResolver("other.example.net."),
+	}, 0)
+	cname, err := r.LookupCNAME(context.Background(), "www.example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cname != "target.example.net" {
+		t.Errorf("cname = %q, expected %q", cname, "target.example.net")
+	}
+
+	r = NewQuorumResolver([]Resolver{
+		cnameResolver("a.example.net"),
+		cnameResolver("b.example.net"),
+		cnameResolver("c.example.net"),
+	}, 0)
+	if cname, err := r.LookupCNAME(context.Background(), "www.example.com"); err == nil {
+		t.Errorf("expected error for disagreeing resolvers, got %q", cname)
+	}
+}
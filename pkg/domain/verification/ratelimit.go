@@ -0,0 +1,187 @@
+package verification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync/atomic"
+
+	"golang.org/x/time/rate"
+
+	"github.com/skygeario/k8s-controller/pkg/tracing"
+)
+
+// RateLimitConfig configures rate limit of DNS queries.
+type RateLimitConfig struct {
+	// Servers are addresses of upstream DNS resolvers, see ParseServer.
+	// Local DNS configuration is used if empty.
+	Servers []string
+	// QPS is the maximum queries per second sent to each resolver.
+	QPS float64
+	// Burst is the maximum burst of queries sent to each resolver.
+	Burst int
+}
+
+type rateLimitedEndpoint struct {
+	resolver *net.Resolver
+	limiter  *rate.Limiter
+}
+
+// RateLimitedResolver distributes DNS queries across upstream resolvers,
+// limiting the rate of queries sent to each resolver using a token bucket.
+type RateLimitedResolver struct {
+	servers   []string
+	endpoints []rateLimitedEndpoint
+	next      uint32
+}
+
+func NewRateLimitedResolver(config RateLimitConfig) *RateLimitedResolver {
+	limit := rate.Limit(config.QPS)
+	if config.QPS <= 0 {
+		limit = rate.Inf
+	}
+	burst := config.Burst
+	if burst <= 0 {
+		burst = 1
+	}
+
+	var servers []string
+	var endpoints []rateLimitedEndpoint
+	for _, s := range config.Servers {
+		var resolver *net.Resolver
+		server, err := ParseServer(s)
+		if err != nil {
+			// Invalid servers fail all queries, and are reported by Check.
+			resolver = &net.Resolver{
+				PreferGo: true,
+				Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+					return nil, err
+				},
+			}
+			servers = append(servers, s)
+		} else {
+			resolver = server.Resolver()
+			servers = append(servers, server.String())
+		}
+		endpoints = append(endpoints, rateLimitedEndpoint{
+			resolver: resolver,
+			limiter:  rate.NewLimiter(limit, burst),
+		})
+	}
+	if len(endpoints) == 0 {
+		endpoints = append(endpoints, rateLimitedEndpoint{
+			resolver: &net.Resolver{},
+			limiter:  rate.NewLimiter(limit, burst),
+		})
+	}
+
+	return &RateLimitedResolver{servers: servers, endpoints: endpoints}
+}
+
+// SetQPS changes the maximum queries per second sent to each resolver.
+func (r *RateLimitedResolver) SetQPS(qps float64) {
+	limit := rate.Limit(qps)
+	if qps <= 0 {
+		limit = rate.Inf
+	}
+	for _, endpoint := range r.endpoints {
+		endpoint.limiter.SetLimit(limit)
+	}
+}
+
+func (r *RateLimitedResolver) String() string {
+	if len(r.servers) == 0 {
+		return "system"
+	}
+	return strings.Join(r.servers, ",")
+}
+
+var _ Resolver = &RateLimitedResolver{}
+var _ NSResolver = &RateLimitedResolver{}
+var _ AddressResolver = &RateLimitedResolver{}
+
+func (r *RateLimitedResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	ctx, span := tracing.Start(ctx, "DNS LookupTXT", tracing.SpanKindClient, tracing.String("dns.question.name", name))
+	defer span.End()
+	endpoint, err := r.acquire(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	result, err := endpoint.resolver.LookupTXT(ctx, name)
+	span.RecordError(err)
+	return result, err
+}
+
+func (r *RateLimitedResolver) LookupNS(ctx context.Context, name string) ([]*net.NS, error) {
+	ctx, span := tracing.Start(ctx, "DNS LookupNS", tracing.SpanKindClient, tracing.String("dns.question.name", name))
+	defer span.End()
+	endpoint, err := r.acquire(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	result, err := endpoint.resolver.LookupNS(ctx, name)
+	span.RecordError(err)
+	return result, err
+}
+
+func (r *RateLimitedResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	ctx, span := tracing.Start(ctx, "DNS LookupIPAddr", tracing.SpanKindClient, tracing.String("dns.question.name", host))
+	defer span.End()
+	endpoint, err := r.acquire(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	result, err := endpoint.resolver.LookupIPAddr(ctx, host)
+	span.RecordError(err)
+	return result, err
+}
+
+func (r *RateLimitedResolver) LookupCNAME(ctx context.Context, name string) (string, error) {
+	ctx, span := tracing.Start(ctx, "DNS LookupCNAME", tracing.SpanKindClient, tracing.String("dns.question.name", name))
+	defer span.End()
+	endpoint, err := r.acquire(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return "", err
+	}
+	result, err := endpoint.resolver.LookupCNAME(ctx, name)
+	span.RecordError(err)
+	return result, err
+}
+
+// Check checks all upstream resolvers are reachable, by querying NS records
+// of the root zone. Queries are not subject to rate limit.
+func (r *RateLimitedResolver) Check(ctx context.Context) error {
+	for i, endpoint := range r.endpoints {
+		_, err := endpoint.resolver.LookupNS(ctx, ".")
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			// Resolver is reachable, but zone is not served
+			continue
+		}
+		if err != nil {
+			server := "system"
+			if i < len(r.servers) {
+				server = r.servers[i]
+			}
+			return fmt.Errorf("resolver %s is unreachable: %w", server, err)
+		}
+	}
+	return nil
+}
+
+// acquire selects the next resolver in round-robin order, and waits until
+// the query is allowed by its rate limit.
+func (r *RateLimitedResolver) acquire(ctx context.Context) (*rateLimitedEndpoint, error) {
+	n := atomic.AddUint32(&r.next, 1)
+	endpoint := &r.endpoints[int(n-1)%len(r.endpoints)]
+	if err := endpoint.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return endpoint, nil
+}
@@ -0,0 +1,64 @@
+package verification
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestNewRateLimitedResolver(t *testing.T) {
+	tests := []struct {
+		servers   []string
+		name      string
+		endpoints int
+	}{
+		{nil, "system", 1},
+		{[]string{"1.1.1.1:53"}, "1.1.1.1:53", 1},
+		{[]string{"udp://8.8.8.8:53", "tls://1.1.1.1"}, "8.8.8.8:53,tls://1.1.1.1:853", 2},
+		{[]string{"1.1.1.1", "8.8.8.8:53"}, "1.1.1.1,8.8.8.8:53", 2},
+	}
+	for _, tt := range tests {
+		r := NewRateLimitedResolver(RateLimitConfig{Servers: tt.servers})
+		if name := r.String(); name != tt.name {
+			t.Errorf("NewRateLimitedResolver(%v).String() = %q, expected %q", tt.servers, name, tt.name)
+		}
+		if len(r.endpoints) != tt.endpoints {
+			t.Errorf("NewRateLimitedResolver(%v) has %d endpoints, expected %d", tt.servers, len(r.endpoints), tt.endpoints)
+		}
+	}
+}
+
+func TestRateLimitedResolverAcquire(t *testing.T) {
+	r := NewRateLimitedResolver(RateLimitConfig{
+		Servers: []string{"192.0.2.1:53", "192.0.2.2:53"},
+		QPS:     1,
+		Burst:   1,
+	})
+
+	// Queries are distributed across resolvers in round-robin order.
+	for i, expected := range []int{0, 1} {
+		endpoint, err := r.acquire(context.Background())
+		if err != nil {
+			t.Fatalf("acquire #%d: unexpected error: %v", i, err)
+		}
+		if endpoint != &r.endpoints[expected] {
+			t.Errorf("acquire #%d: expected endpoint %d", i, expected)
+		}
+	}
+
+	// Burst of each resolver is used up, so the next query would wait for
+	// about a second.
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := r.acquire(ctx); err == nil {
+		t.Errorf("expected acquire to be rate limited")
+	}
+
+	// Rate limit is lifted when QPS is not positive.
+	r.SetQPS(0)
+	for i := 0; i < 4; i++ {
+		if _, err := r.acquire(context.Background()); err != nil {
+			t.Fatalf("acquire without rate limit: unexpected error: %v", err)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package verification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"strings"
+)
+
+// DefaultRDAPBaseURL is the RDAP bootstrap service redirecting queries to
+// the authoritative RDAP server of domains.
+const DefaultRDAPBaseURL = "https://rdap.org"
+
+// maxRDAPResponseSize limits the size of RDAP response body read.
+const maxRDAPResponseSize = 1024 * 1024
+
+// rdapContactRoles are roles of RDAP entities whose emails are domain
+// contacts. Registrar and abuse contacts are excluded.
+var rdapContactRoles = map[string]bool{
+	"registrant":     true,
+	"administrative": true,
+	"technical":      true,
+}
+
+type rdapEntity struct {
+	Roles      []string          `json:"roles"`
+	VCardArray []json.RawMessage `json:"vcardArray"`
+	Entities   []rdapEntity      `json:"entities"`
+}
+
+type rdapDomain struct {
+	Entities []rdapEntity `json:"entities"`
+}
+
+// RDAPClient looks up contacts of domains using RDAP.
+type RDAPClient struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+func NewRDAPClient(baseURL string, client *http.Client) *RDAPClient {
+	if baseURL == "" {
+		baseURL = DefaultRDAPBaseURL
+	}
+	return &RDAPClient{BaseURL: strings.TrimSuffix(baseURL, "/"), Client: client}
+}
+
+// LookupContactEmails returns email addresses of registrant, administrative
+// and technical contacts of the registrable domain. Redacted contacts are
+// omitted.
+func (c *RDAPClient) LookupContactEmails(ctx context.Context, domain string) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.BaseURL+"/domain/"+domain, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/rdap+json")
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, newRDAPError(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, newRDAPError(fmt.Errorf("unexpected status code %d", resp.StatusCode))
+	}
+
+	var result rdapDomain
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxRDAPResponseSize)).Decode(&result); err != nil {
+		return nil, newRDAPError(err)
+	}
+
+	var emails []string
+	seen := map[string]bool{}
+	var collect func(entities []rdapEntity, isContact bool)
+	collect = func(entities []rdapEntity, isContact bool) {
+		for _, e := range entities {
+			contact := isContact
+			for _, role := range e.Roles {
+				if rdapContactRoles[role] {
+					contact = true
+				}
+			}
+			if contact {
+				for _, email := range vcardEmails(e.VCardArray) {
+					if !seen[email] {
+						seen[email] = true
+						emails = append(emails, email)
+					}
+				}
+			}
+			collect(e.Entities, contact)
+		}
+	}
+	collect(result.Entities, false)
+	return emails, nil
+}
+
+// vcardEmails returns valid email addresses in jCard (RFC 7095) of entity.
+func vcardEmails(vcard []json.RawMessage) []string {
+	if len(vcard) != 2 {
+		return nil
+	}
+	var props [][]json.RawMessage
+	if err := json.Unmarshal(vcard[1], &props); err != nil {
+		return nil
+	}
+
+	var emails []string
+	for _, prop := range props {
+		if len(prop) < 4 {
+			continue
+		}
+		var name, value string
+		if json.Unmarshal(prop[0], &name) != nil || name != "email" {
+			continue
+		}
+		if json.Unmarshal(prop[3], &value) != nil {
+			continue
+		}
+		addr, err := mail.ParseAddress(value)
+		if err != nil {
+			continue
+		}
+		emails = append(emails, strings.ToLower(addr.Address))
+	}
+	return emails
+}
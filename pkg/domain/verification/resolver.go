@@ -0,0 +1,24 @@
+package verification
+
+import (
+	"context"
+	"fmt"
+	"net"
+)
+
+// Resolver resolves DNS records for domain verification.
+type Resolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+	LookupCNAME(ctx context.Context, name string) (string, error)
+}
+
+// DefaultResolver is the resolver using local DNS configuration.
+var DefaultResolver Resolver = &net.Resolver{}
+
+// ResolverName returns a description of the resolver for diagnosis.
+func ResolverName(r Resolver) string {
+	if s, ok := r.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return "system"
+}
@@ -0,0 +1,133 @@
+package verification
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// TokenSecretsKey is the key of token generator secrets in Secret data.
+const TokenSecretsKey = "secrets"
+
+// ParseTokenSecrets parses token generator secrets, one per line.
+func ParseTokenSecrets(data []byte) [][]byte {
+	var secrets [][]byte
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			secrets = append(secrets, []byte(line))
+		}
+	}
+	return secrets
+}
+
+// SecretTokenGenerator generates tokens using secrets stored in a Secret,
+// and reloads the secrets when the Secret is changed.
+type SecretTokenGenerator struct {
+	Client        client.Client
+	Log           logr.Logger
+	GeneratorType string
+	SecretName    types.NamespacedName
+
+	lock      sync.RWMutex
+	generator TokenGenerator
+	loadErr   error
+}
+
+var _ TokenGenerator = &SecretTokenGenerator{}
+var _ reconcile.Reconciler = &SecretTokenGenerator{}
+
+// Load loads secrets from the Secret.
+func (g *SecretTokenGenerator) Load(ctx context.Context, reader client.Reader) error {
+	generator, err := g.load(ctx, reader)
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.loadErr = err
+	if err != nil {
+		return err
+	}
+	g.generator = generator
+	return nil
+}
+
+func (g *SecretTokenGenerator) load(ctx context.Context, reader client.Reader) (TokenGenerator, error) {
+	var secret corev1.Secret
+	if err := reader.Get(ctx, g.SecretName, &secret); err != nil {
+		return nil, err
+	}
+
+	generator, err := NewTokenGenerator(g.GeneratorType, ParseTokenSecrets(secret.Data[TokenSecretsKey]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid secret %s: %w", g.SecretName, err)
+	}
+	return generator, nil
+}
+
+// LoadError returns the error of last load of secrets, or nil if secrets
+// are loaded successfully.
+func (g *SecretTokenGenerator) LoadError() error {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+	return g.loadErr
+}
+
+func (g *SecretTokenGenerator) current() TokenGenerator {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+	if g.generator == nil {
+		panic("verification: token secrets are not loaded")
+	}
+	return g.generator
+}
+
+func (g *SecretTokenGenerator) GenerateToken(key string, nonce string) string {
+	return g.current().GenerateToken(key, nonce)
+}
+
+func (g *SecretTokenGenerator) AcceptedTokens(key string, nonce string) []string {
+	return g.current().AcceptedTokens(key, nonce)
+}
+
+func (g *SecretTokenGenerator) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	if err := g.Load(context.Background(), g.Client); err != nil {
+		// Keep using loaded secrets until the Secret is fixed.
+		g.Log.Error(err, "unable reload token secrets", "secret", req.NamespacedName)
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	g.Log.Info("reloaded token secrets", "secret", req.NamespacedName)
+	return ctrl.Result{}, nil
+}
+
+func (g *SecretTokenGenerator) SetupWithManager(mgr ctrl.Manager) error {
+	isSecret := func(name types.NamespacedName) bool {
+		return name == g.SecretName
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("verification-token-secret").
+		For(&corev1.Secret{}).
+		WithEventFilter(predicate.Funcs{
+			CreateFunc: func(e event.CreateEvent) bool {
+				return isSecret(types.NamespacedName{Namespace: e.Meta.GetNamespace(), Name: e.Meta.GetName()})
+			},
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				return isSecret(types.NamespacedName{Namespace: e.MetaNew.GetNamespace(), Name: e.MetaNew.GetName()})
+			},
+			DeleteFunc: func(e event.DeleteEvent) bool {
+				return false
+			},
+			GenericFunc: func(e event.GenericEvent) bool {
+				return false
+			},
+		}).
+		Complete(g)
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
+	"fmt"
 )
 
 const KeyLength = 32
@@ -24,3 +25,90 @@ func GenerateDomainToken(key string, nonce string) string {
 	mac := h.Sum(nil)
 	return hex.EncodeToString(mac)
 }
+
+// TokenGenerator generates verification tokens of domains.
+type TokenGenerator interface {
+	// GenerateToken returns the token to be published by users.
+	GenerateToken(key string, nonce string) string
+	// AcceptedTokens returns all tokens accepted in verification, including
+	// the token to be published.
+	AcceptedTokens(key string, nonce string) []string
+}
+
+// HMACTokenGenerator generates HMAC-SHA256 tokens from domain key, keyed by
+// a secret. Tokens are generated using domain key only if secret is empty.
+type HMACTokenGenerator struct {
+	Secret []byte
+}
+
+var _ TokenGenerator = HMACTokenGenerator{}
+
+func (g HMACTokenGenerator) GenerateToken(key string, nonce string) string {
+	if len(g.Secret) == 0 {
+		return GenerateDomainToken(key, nonce)
+	}
+	h := hmac.New(sha256.New, g.Secret)
+	h.Write([]byte(key))
+	return GenerateDomainToken(hex.EncodeToString(h.Sum(nil)), nonce)
+}
+
+func (g HMACTokenGenerator) AcceptedTokens(key string, nonce string) []string {
+	return []string{g.GenerateToken(key, nonce)}
+}
+
+// KeyRingTokenGenerator generates HMAC-SHA256 tokens using the first secret,
+// and accepts tokens of all secrets, so that secrets can be rotated without
+// invalidating published tokens.
+type KeyRingTokenGenerator struct {
+	Secrets [][]byte
+}
+
+var _ TokenGenerator = KeyRingTokenGenerator{}
+
+func (g KeyRingTokenGenerator) GenerateToken(key string, nonce string) string {
+	var secret []byte
+	if len(g.Secrets) > 0 {
+		secret = g.Secrets[0]
+	}
+	return HMACTokenGenerator{Secret: secret}.GenerateToken(key, nonce)
+}
+
+func (g KeyRingTokenGenerator) AcceptedTokens(key string, nonce string) []string {
+	if len(g.Secrets) == 0 {
+		return []string{g.GenerateToken(key, nonce)}
+	}
+	tokens := make([]string, len(g.Secrets))
+	for i, secret := range g.Secrets {
+		tokens[i] = HMACTokenGenerator{Secret: secret}.GenerateToken(key, nonce)
+	}
+	return tokens
+}
+
+const (
+	TokenGeneratorHMAC    = "hmac"
+	TokenGeneratorKeyRing = "keyring"
+)
+
+// NewTokenGenerator creates token generator of the type. The first secret
+// is used to generate tokens, and other secrets are accepted by key-ring
+// generator only.
+func NewTokenGenerator(generatorType string, secrets [][]byte) (TokenGenerator, error) {
+	switch generatorType {
+	case TokenGeneratorHMAC:
+		if len(secrets) > 1 {
+			return nil, fmt.Errorf("HMAC token generator accepts single secret only")
+		}
+		var secret []byte
+		if len(secrets) == 1 {
+			secret = secrets[0]
+		}
+		return HMACTokenGenerator{Secret: secret}, nil
+	case TokenGeneratorKeyRing:
+		if len(secrets) == 0 {
+			return nil, fmt.Errorf("key-ring token generator requires at least one secret")
+		}
+		return KeyRingTokenGenerator{Secrets: secrets}, nil
+	default:
+		return nil, fmt.Errorf("unknown token generator type '%s'", generatorType)
+	}
+}
@@ -0,0 +1,231 @@
+package verification
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// ProtocolDNS is plain DNS over UDP, falling back to TCP.
+	ProtocolDNS = "dns"
+	// ProtocolDoT is DNS-over-TLS (RFC 7858).
+	ProtocolDoT = "tls"
+	// ProtocolDoH is DNS-over-HTTPS (RFC 8484).
+	ProtocolDoH = "https"
+)
+
+const dohMediaType = "application/dns-message"
+
+// Server is an upstream DNS resolver.
+type Server struct {
+	Protocol string
+	// Address is host:port of plain DNS and DNS-over-TLS servers.
+	Address string
+	// URL is the endpoint of DNS-over-HTTPS servers.
+	URL *url.URL
+}
+
+// ParseServer parses address of upstream DNS resolver, in form of host:port
+// for plain DNS, tls://host[:port] for DNS-over-TLS, or
+// https://[user:password@]host/path for DNS-over-HTTPS. Credentials in
+// DNS-over-HTTPS URL are sent using basic authentication.
+func ParseServer(s string) (*Server, error) {
+	if !strings.Contains(s, "://") {
+		if _, _, err := net.SplitHostPort(s); err != nil {
+			return nil, fmt.Errorf("invalid DNS server %q: %w", s, err)
+		}
+		return &Server{Protocol: ProtocolDNS, Address: s}, nil
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DNS server %q: %w", s, err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid DNS server %q: missing host", s)
+	}
+	switch u.Scheme {
+	case "udp", "tcp":
+		return ParseServer(u.Host)
+	case ProtocolDoT:
+		address := u.Host
+		if u.Port() == "" {
+			address = net.JoinHostPort(u.Hostname(), "853")
+		}
+		return &Server{Protocol: ProtocolDoT, Address: address}, nil
+	case ProtocolDoH:
+		return &Server{Protocol: ProtocolDoH, URL: u}, nil
+	}
+	return nil, fmt.Errorf("invalid DNS server %q: unsupported protocol %s", s, u.Scheme)
+}
+
+func (s *Server) String() string {
+	switch s.Protocol {
+	case ProtocolDoT:
+		return "tls://" + s.Address
+	case ProtocolDoH:
+		u := *s.URL
+		u.User = nil
+		return u.String()
+	}
+	return s.Address
+}
+
+// Resolver returns a resolver sending queries to the server.
+func (s *Server) Resolver() *net.Resolver {
+	return &net.Resolver{PreferGo: true, Dial: s.dial}
+}
+
+// dial returns a connection to the server. Go resolver uses stream framing
+// for connections other than net.PacketConn, so that DNS-over-TLS and
+// DNS-over-HTTPS connections are used as TCP connections.
+func (s *Server) dial(ctx context.Context, network, address string) (net.Conn, error) {
+	switch s.Protocol {
+	case ProtocolDoT:
+		return s.dialTLS(ctx)
+	case ProtocolDoH:
+		return &dohConn{ctx: ctx, url: s.URL, client: http.DefaultClient}, nil
+	}
+	var d net.Dialer
+	return d.DialContext(ctx, network, s.Address)
+}
+
+func (s *Server) dialTLS(ctx context.Context) (net.Conn, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", s.Address)
+	if err != nil {
+		return nil, err
+	}
+	host, _, _ := net.SplitHostPort(s.Address)
+	tlsConn := tls.Client(conn, &tls.Config{ServerName: host})
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = tlsConn.SetDeadline(deadline)
+	}
+	if err := tlsConn.Handshake(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	_ = tlsConn.SetDeadline(time.Time{})
+	return tlsConn, nil
+}
+
+// dohConn is a DNS stream connection sending each query in a
+// DNS-over-HTTPS request.
+type dohConn struct {
+	ctx    context.Context
+	url    *url.URL
+	client *http.Client
+
+	lock     sync.Mutex
+	query    bytes.Buffer
+	response bytes.Buffer
+	deadline time.Time
+}
+
+var _ net.Conn = &dohConn{}
+
+func (c *dohConn) Write(b []byte) (int, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.query.Write(b)
+}
+
+func (c *dohConn) Read(b []byte) (int, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.response.Len() == 0 && c.query.Len() > 0 {
+		if err := c.roundTrip(); err != nil {
+			return 0, err
+		}
+	}
+	if c.response.Len() == 0 {
+		return 0, io.EOF
+	}
+	return c.response.Read(b)
+}
+
+// roundTrip sends the buffered length-prefixed query, and buffers the
+// length-prefixed response.
+func (c *dohConn) roundTrip() error {
+	query := c.query.Bytes()
+	if len(query) < 2 {
+		return errors.New("incomplete DNS query")
+	}
+	length := int(query[0])<<8 | int(query[1])
+	if len(query) < 2+length {
+		return errors.New("incomplete DNS query")
+	}
+	msg := query[2 : 2+length]
+
+	ctx := c.ctx
+	if !c.deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, c.deadline)
+		defer cancel()
+	}
+
+	u := *c.url
+	u.User = nil
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), bytes.NewReader(msg))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", dohMediaType)
+	req.Header.Set("Accept", dohMediaType)
+	if user := c.url.User; user != nil {
+		password, _ := user.Password()
+		req.SetBasicAuth(user.Username(), password)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected DNS-over-HTTPS response status %d", resp.StatusCode)
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, 65535))
+	if err != nil {
+		return err
+	}
+
+	c.query.Next(2 + length)
+	c.response.WriteByte(byte(len(body) >> 8))
+	c.response.WriteByte(byte(len(body)))
+	c.response.Write(body)
+	return nil
+}
+
+func (c *dohConn) Close() error { return nil }
+
+func (c *dohConn) LocalAddr() net.Addr { return dohAddr("") }
+
+func (c *dohConn) RemoteAddr() net.Addr { return dohAddr(c.url.Host) }
+
+func (c *dohConn) SetDeadline(t time.Time) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.deadline = t
+	return nil
+}
+
+func (c *dohConn) SetReadDeadline(t time.Time) error { return c.SetDeadline(t) }
+
+func (c *dohConn) SetWriteDeadline(t time.Time) error { return nil }
+
+type dohAddr string
+
+func (a dohAddr) Network() string { return ProtocolDoH }
+func (a dohAddr) String() string  { return string(a) }
@@ -0,0 +1,103 @@
+package verification
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Method is the method of domain verification.
+type Method string
+
+const (
+	// MethodDNS verifies domain using DNS TXT record.
+	MethodDNS Method = "DNS"
+	// MethodHTTP verifies domain using token served over HTTP.
+	MethodHTTP Method = "HTTP"
+	// MethodCNAME verifies domain using CNAME record delegating to
+	// challenge zone.
+	MethodCNAME Method = "CNAME"
+	// MethodDNSCNAME verifies domain using CNAME record encoding the token,
+	// i.e. DNS verification with record type CNAME. It points to
+	// TokenCNAMETarget of Verifier.
+	MethodDNSCNAME Method = "DNSCNAME"
+	// MethodEmail verifies domain by confirmation link sent to contacts of
+	// domain, see EmailChallenger. Confirmations are recorded as
+	// attestations, so it is not verified by Verifier.
+	MethodEmail Method = "Email"
+)
+
+// Verifier verifies domain using the requested method.
+type Verifier struct {
+	DNS   *DNSVerifier
+	HTTP  *HTTPVerifier
+	CNAME *CNAMEVerifier
+	// TokenCNAMETarget is the target of CNAME records encoding tokens.
+	// MethodDNSCNAME is disabled if empty.
+	TokenCNAMETarget string
+	// NewResolver creates resolver of nameservers pinned by domains, see
+	// WithNameservers. Pinned nameservers are ignored if nil.
+	NewResolver func(servers []string) Resolver
+
+	lock      sync.Mutex
+	resolvers map[string]Resolver
+}
+
+type nameserversKey struct{}
+
+// WithNameservers returns a context pinning the DNS resolvers used to
+// verify the domain.
+func WithNameservers(ctx context.Context, servers []string) context.Context {
+	if len(servers) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, nameserversKey{}, servers)
+}
+
+func NewVerifier(dns *DNSVerifier, http *HTTPVerifier, cname *CNAMEVerifier) *Verifier {
+	return &Verifier{DNS: dns, HTTP: http, CNAME: cname}
+}
+
+func (v *Verifier) VerifyDomain(ctx context.Context, method Method, domain string, token string) error {
+	dns, cname := v.DNS, v.CNAME
+	if servers, ok := ctx.Value(nameserversKey{}).([]string); ok && v.NewResolver != nil {
+		resolver := v.resolver(servers)
+		dns, cname = NewDNSVerifier(resolver), NewCNAMEVerifier(resolver)
+	}
+
+	switch method {
+	case MethodDNS, "":
+		return dns.VerifyDomain(ctx, domain, token)
+	case MethodHTTP:
+		if v.HTTP == nil {
+			return fmt.Errorf("HTTP verification is disabled")
+		}
+		return v.HTTP.VerifyDomain(ctx, domain, token)
+	case MethodCNAME:
+		return cname.VerifyDomain(ctx, domain, token)
+	case MethodDNSCNAME:
+		if v.TokenCNAMETarget == "" {
+			return fmt.Errorf("CNAME record verification is disabled")
+		}
+		return cname.VerifyTokenRecord(ctx, domain, token, v.TokenCNAMETarget)
+	}
+	return fmt.Errorf("unknown verification method '%s'", method)
+}
+
+// resolver returns the resolver of the nameservers, reusing resolvers so
+// that rate limit and cache are shared by verifications.
+func (v *Verifier) resolver(servers []string) Resolver {
+	key := strings.Join(servers, ",")
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	if v.resolvers == nil {
+		v.resolvers = map[string]Resolver{}
+	}
+	if r, ok := v.resolvers[key]; ok {
+		return r
+	}
+	r := v.NewResolver(servers)
+	v.resolvers[key] = r
+	return r
+}
@@ -0,0 +1,16 @@
+package verification
+
+const (
+	// ViewLocal is the DNS view of the cluster, which may include internal
+	// overrides of public names (split-horizon DNS).
+	ViewLocal = "Local"
+	// ViewExternal is the DNS view of public internet.
+	ViewExternal = "External"
+)
+
+// DefaultExternalServers are public DNS-over-HTTPS resolvers used for
+// external view, reachable from clusters allowing only HTTPS egress.
+var DefaultExternalServers = []string{
+	"https://cloudflare-dns.com/dns-query",
+	"https://dns.google/dns-query",
+}
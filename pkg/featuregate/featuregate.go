@@ -0,0 +1,142 @@
+// Package featuregate provides named gates of features, so that experimental
+// features can be shipped disabled and enabled per cluster. It follows the
+// conventions of feature gates of Kubernetes components.
+package featuregate
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Feature is the name of a feature gate.
+type Feature string
+
+// PreRelease is the maturity of a feature.
+type PreRelease string
+
+const (
+	Alpha = PreRelease("ALPHA")
+	Beta  = PreRelease("BETA")
+	GA    = PreRelease("")
+)
+
+// FeatureSpec is the specification of a feature.
+type FeatureSpec struct {
+	Default    bool
+	PreRelease PreRelease
+}
+
+// FeatureGate is a set of features which can be enabled or disabled. It
+// implements flag.Value, parsing values in form of
+// "Feature1=true,Feature2=false".
+type FeatureGate struct {
+	lock    sync.RWMutex
+	known   map[Feature]FeatureSpec
+	enabled map[Feature]bool
+}
+
+// NewFeatureGate returns an empty feature gate.
+func NewFeatureGate() *FeatureGate {
+	return &FeatureGate{
+		known:   map[Feature]FeatureSpec{},
+		enabled: map[Feature]bool{},
+	}
+}
+
+// Add adds features to the gate.
+func (g *FeatureGate) Add(features map[Feature]FeatureSpec) error {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	for name, spec := range features {
+		if existing, ok := g.known[name]; ok && existing != spec {
+			return fmt.Errorf("feature gate %s is already added with different specification", name)
+		}
+		g.known[name] = spec
+	}
+	return nil
+}
+
+// SetFromMap enables or disables the features.
+func (g *FeatureGate) SetFromMap(m map[string]bool) error {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	for name := range m {
+		if _, ok := g.known[Feature(name)]; !ok {
+			return fmt.Errorf("unknown feature gate %s", name)
+		}
+	}
+	for name, enabled := range m {
+		g.enabled[Feature(name)] = enabled
+	}
+	return nil
+}
+
+// Set implements flag.Value.
+func (g *FeatureGate) Set(value string) error {
+	m := map[string]bool{}
+	for _, s := range strings.Split(value, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		parts := strings.SplitN(s, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("missing bool value for feature gate %s", s)
+		}
+		enabled, err := strconv.ParseBool(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return fmt.Errorf("invalid value of feature gate %s: %w", parts[0], err)
+		}
+		m[strings.TrimSpace(parts[0])] = enabled
+	}
+	return g.SetFromMap(m)
+}
+
+// String implements flag.Value.
+func (g *FeatureGate) String() string {
+	if g == nil {
+		return ""
+	}
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+	var pairs []string
+	for name, enabled := range g.enabled {
+		pairs = append(pairs, fmt.Sprintf("%s=%t", name, enabled))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// Enabled returns whether the feature is enabled. It panics if the feature
+// is unknown, since it is a programming error.
+func (g *FeatureGate) Enabled(name Feature) bool {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+	if enabled, ok := g.enabled[name]; ok {
+		return enabled
+	}
+	spec, ok := g.known[name]
+	if !ok {
+		panic(fmt.Sprintf("unknown feature gate %s", name))
+	}
+	return spec.Default
+}
+
+// KnownFeatures returns descriptions of known features, for usage of
+// command line flag.
+func (g *FeatureGate) KnownFeatures() []string {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+	var features []string
+	for name, spec := range g.known {
+		if spec.PreRelease == GA {
+			continue
+		}
+		features = append(features, fmt.Sprintf("%s=true|false (%s - default=%t)", name, spec.PreRelease, spec.Default))
+	}
+	sort.Strings(features)
+	return features
+}
@@ -0,0 +1,110 @@
+package featuregate
+
+import (
+	"reflect"
+	"testing"
+)
+
+const (
+	alphaFeature Feature = "AlphaFeature"
+	betaFeature  Feature = "BetaFeature"
+	gaFeature    Feature = "GAFeature"
+)
+
+func newTestFeatureGate(t *testing.T) *FeatureGate {
+	g := NewFeatureGate()
+	err := g.Add(map[Feature]FeatureSpec{
+		alphaFeature: {Default: false, PreRelease: Alpha},
+		betaFeature:  {Default: true, PreRelease: Beta},
+		gaFeature:    {Default: true, PreRelease: GA},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return g
+}
+
+func TestFeatureGateSet(t *testing.T) {
+	tests := []struct {
+		value    string
+		err      bool
+		enabled  map[Feature]bool
+		asString string
+	}{
+		{
+			value:   "",
+			enabled: map[Feature]bool{alphaFeature: false, betaFeature: true, gaFeature: true},
+		},
+		{
+			value:    "AlphaFeature=true",
+			enabled:  map[Feature]bool{alphaFeature: true, betaFeature: true, gaFeature: true},
+			asString: "AlphaFeature=true",
+		},
+		{
+			value:    " AlphaFeature = true , BetaFeature=false,",
+			enabled:  map[Feature]bool{alphaFeature: true, betaFeature: false, gaFeature: true},
+			asString: "AlphaFeature=true,BetaFeature=false",
+		},
+		{value: "AlphaFeature", err: true},
+		{value: "AlphaFeature=yes", err: true},
+		{value: "UnknownFeature=true", err: true},
+		{value: "AlphaFeature=true,UnknownFeature=true", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			g := newTestFeatureGate(t)
+			err := g.Set(tt.value)
+			if tt.err {
+				if err == nil {
+					t.Fatalf("expected error")
+				}
+				if s := g.String(); s != "" {
+					t.Errorf("features are set on error: %q", s)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for feature, expected := range tt.enabled {
+				if enabled := g.Enabled(feature); enabled != expected {
+					t.Errorf("Enabled(%s) = %t, expected %t", feature, enabled, expected)
+				}
+			}
+			if s := g.String(); s != tt.asString {
+				t.Errorf("String() = %q, expected %q", s, tt.asString)
+			}
+		})
+	}
+}
+
+func TestFeatureGateAdd(t *testing.T) {
+	g := newTestFeatureGate(t)
+	if err := g.Add(map[Feature]FeatureSpec{betaFeature: {Default: true, PreRelease: Beta}}); err != nil {
+		t.Errorf("adding same specification: unexpected error: %v", err)
+	}
+	if err := g.Add(map[Feature]FeatureSpec{betaFeature: {Default: false, PreRelease: Beta}}); err == nil {
+		t.Errorf("adding different specification: expected error")
+	}
+}
+
+func TestFeatureGateEnabledUnknown(t *testing.T) {
+	g := newTestFeatureGate(t)
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic for unknown feature")
+		}
+	}()
+	g.Enabled("UnknownFeature")
+}
+
+func TestFeatureGateKnownFeatures(t *testing.T) {
+	g := newTestFeatureGate(t)
+	expected := []string{
+		"AlphaFeature=true|false (ALPHA - default=false)",
+		"BetaFeature=true|false (BETA - default=true)",
+	}
+	if features := g.KnownFeatures(); !reflect.DeepEqual(features, expected) {
+		t.Errorf("KnownFeatures() = %v, expected %v", features, expected)
+	}
+}
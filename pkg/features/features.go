@@ -0,0 +1,57 @@
+// Package features defines feature gates of the controller.
+package features
+
+import (
+	"github.com/skygeario/k8s-controller/pkg/featuregate"
+)
+
+const (
+	// HTTPVerification enables verifying domains by HTTP challenge.
+	HTTPVerification featuregate.Feature = "HTTPVerification"
+	// ACME enables issuing certificates by the built-in ACME client.
+	ACME featuregate.Feature = "ACME"
+	// GatewayAPIRouting enables routing domains with Gateway API HTTPRoutes.
+	GatewayAPIRouting featuregate.Feature = "GatewayAPIRouting"
+	// DNSProviderConfigs enables configuring DNS providers per zone with
+	// DNSProviderConfig resources.
+	DNSProviderConfigs featuregate.Feature = "DNSProviderConfigs"
+	// EmailVerification enables verifying domains by confirmation links sent
+	// to contact emails of domains found in RDAP.
+	EmailVerification featuregate.Feature = "EmailVerification"
+	// LoadBalancerEndpoints enables configuring load balancers of load
+	// balancer classes with LoadBalancerEndpoint resources.
+	LoadBalancerEndpoints featuregate.Feature = "LoadBalancerEndpoints"
+	// DedicatedIP enables allocating dedicated IP addresses of domains from
+	// IPPool resources.
+	DedicatedIP featuregate.Feature = "DedicatedIP"
+	// IPv6DNSRecords enables publishing AAAA records of IPv6 addresses of
+	// load balancers. It should be disabled for clusters without IPv6
+	// ingress.
+	IPv6DNSRecords featuregate.Feature = "IPv6DNSRecords"
+)
+
+var defaultFeatures = map[featuregate.Feature]featuregate.FeatureSpec{
+	HTTPVerification:      {Default: true, PreRelease: featuregate.Beta},
+	ACME:                  {Default: true, PreRelease: featuregate.Beta},
+	GatewayAPIRouting:     {Default: true, PreRelease: featuregate.Beta},
+	DNSProviderConfigs:    {Default: false, PreRelease: featuregate.Alpha},
+	EmailVerification:     {Default: false, PreRelease: featuregate.Alpha},
+	LoadBalancerEndpoints: {Default: false, PreRelease: featuregate.Alpha},
+	DedicatedIP:           {Default: false, PreRelease: featuregate.Alpha},
+	IPv6DNSRecords:        {Default: true, PreRelease: featuregate.Beta},
+}
+
+// DefaultFeatureGate is the feature gate of the controller, set by
+// --feature-gates.
+var DefaultFeatureGate = featuregate.NewFeatureGate()
+
+func init() {
+	if err := DefaultFeatureGate.Add(defaultFeatures); err != nil {
+		panic(err)
+	}
+}
+
+// Enabled returns whether the feature is enabled in DefaultFeatureGate.
+func Enabled(feature featuregate.Feature) bool {
+	return DefaultFeatureGate.Enabled(feature)
+}
@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Reconcile durations per controller are exported by controller-runtime as
+// controller_runtime_reconcile_time_seconds.
+
+const reasonUnknown = "Unknown"
+
+var (
+	// VerificationAttempts counts domain verification attempts.
+	VerificationAttempts = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "domain_verification_attempts_total",
+		Help: "Total number of domain verification attempts",
+	})
+	// VerificationSuccesses counts successful domain verifications.
+	VerificationSuccesses = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "domain_verification_successes_total",
+		Help: "Total number of successful domain verifications",
+	})
+	// VerificationFailures counts failed domain verifications by reason.
+	VerificationFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "domain_verification_failures_total",
+		Help: "Total number of failed domain verifications",
+	}, []string{"reason"})
+	// DNSLookupDuration observes latency of verification DNS lookups.
+	DNSLookupDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "domain_verification_dns_lookup_duration_seconds",
+		Help:    "Latency of verification DNS lookups",
+		Buckets: prometheus.DefBuckets,
+	})
+	// DNSCacheRequests counts verification DNS lookups served by cache, by
+	// result (hit or miss).
+	DNSCacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "domain_verification_dns_cache_requests_total",
+		Help: "Total number of verification DNS lookups by cache result",
+	}, []string{"result"})
+	// StatusUpdatesSkipped counts status updates skipped as status is
+	// unchanged, by resource.
+	StatusUpdatesSkipped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "domain_status_updates_skipped_total",
+		Help: "Total number of status updates skipped as status is unchanged",
+	}, []string{"resource"})
+	// RegistrationConflictRetries counts updates of domain registrations
+	// retried after conflicting with concurrent updates, by operation.
+	RegistrationConflictRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "domain_registration_conflict_retries_total",
+		Help: "Total number of domain registration updates retried after conflict",
+	}, []string{"operation"})
+	// EndpointProbeDuration observes latency of health probes against
+	// domain endpoints, by whether the endpoint is healthy.
+	EndpointProbeDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "domain_endpoint_probe_duration_seconds",
+		Help:    "Latency of health probes against domain endpoints",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"healthy"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		VerificationAttempts,
+		VerificationSuccesses,
+		VerificationFailures,
+		DNSLookupDuration,
+		DNSCacheRequests,
+		StatusUpdatesSkipped,
+		RegistrationConflictRetries,
+		EndpointProbeDuration,
+	)
+}
+
+// RecordVerification records outcome of a domain verification attempt.
+func RecordVerification(succeeded bool, failureReason string) {
+	VerificationAttempts.Inc()
+	if succeeded {
+		VerificationSuccesses.Inc()
+		return
+	}
+	if failureReason == "" {
+		failureReason = reasonUnknown
+	}
+	VerificationFailures.WithLabelValues(failureReason).Inc()
+}
@@ -0,0 +1,121 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+// EventType is the type of domain lifecycle event.
+type EventType string
+
+const (
+	// EventVerified is sent when a registration verifies the domain.
+	EventVerified EventType = "verified"
+	// EventFailed is sent when a registration fails permanently.
+	EventFailed EventType = "failed"
+	// EventReleased is sent when a registration releases the domain.
+	EventReleased EventType = "released"
+	// EventCertificateIssued is sent when certificate of the domain becomes
+	// ready.
+	EventCertificateIssued EventType = "certificate_issued"
+)
+
+// Event is a domain lifecycle event of a registration.
+type Event struct {
+	Type      EventType `json:"type"`
+	Time      time.Time `json:"time"`
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	Domain    string    `json:"domain"`
+	Message   string    `json:"message,omitempty"`
+}
+
+// Notifier sends domain lifecycle events to external systems.
+type Notifier interface {
+	Notify(event Event)
+}
+
+// DefaultQueueSize is the default number of pending events of webhook
+// notifier.
+const DefaultQueueSize = 100
+
+// DefaultTimeout is the default timeout of webhook requests.
+const DefaultTimeout = 10 * time.Second
+
+// WebhookNotifier POSTs events as JSON to a webhook URL. Events are sent
+// asynchronously, so that reconciliation is not blocked by slow webhooks;
+// events are dropped if the queue is full, or the webhook request fails.
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+	Log    logr.Logger
+
+	queue chan Event
+}
+
+var _ Notifier = &WebhookNotifier{}
+
+func NewWebhookNotifier(url string, log logr.Logger) *WebhookNotifier {
+	return &WebhookNotifier{
+		URL:    url,
+		Client: &http.Client{Timeout: DefaultTimeout},
+		Log:    log,
+		queue:  make(chan Event, DefaultQueueSize),
+	}
+}
+
+func (n *WebhookNotifier) Notify(event Event) {
+	select {
+	case n.queue <- event:
+	default:
+		n.Log.Info("notification queue is full, dropping event",
+			"type", event.Type, "namespace", event.Namespace, "name", event.Name)
+	}
+}
+
+// Start implements manager.Runnable, sending queued events until stop is
+// closed.
+func (n *WebhookNotifier) Start(stop <-chan struct{}) error {
+	for {
+		select {
+		case event := <-n.queue:
+			if err := n.send(event); err != nil {
+				n.Log.Error(err, "failed to send notification",
+					"type", event.Type, "namespace", event.Namespace, "name", event.Name)
+			}
+		case <-stop:
+			return nil
+		}
+	}
+}
+
+func (n *WebhookNotifier) send(event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected webhook response status %d", resp.StatusCode)
+	}
+	return nil
+}
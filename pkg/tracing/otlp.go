@@ -0,0 +1,212 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+const (
+	// DefaultQueueSize is the default number of pending spans of exporter.
+	DefaultQueueSize = 2048
+	// DefaultBatchSize is the maximum number of spans in an export request.
+	DefaultBatchSize = 512
+	// DefaultFlushInterval is the interval between export requests.
+	DefaultFlushInterval = 5 * time.Second
+	// DefaultTimeout is the default timeout of export requests.
+	DefaultTimeout = 10 * time.Second
+)
+
+// Exporter exports spans to an OTLP/HTTP endpoint (e.g. OpenTelemetry
+// Collector, Jaeger or Tempo) using JSON encoding. Spans are exported
+// asynchronously in batches; spans are dropped if the queue is full, or the
+// export request fails.
+type Exporter struct {
+	// Endpoint is the base URL of OTLP/HTTP receiver, e.g.
+	// http://otel-collector:4318.
+	Endpoint    string
+	ServiceName string
+	Client      *http.Client
+	Log         logr.Logger
+
+	queue chan *Span
+}
+
+func NewExporter(endpoint string, serviceName string, log logr.Logger) *Exporter {
+	return &Exporter{
+		Endpoint:    strings.TrimSuffix(endpoint, "/"),
+		ServiceName: serviceName,
+		Client:      &http.Client{Timeout: DefaultTimeout},
+		Log:         log,
+		queue:       make(chan *Span, DefaultQueueSize),
+	}
+}
+
+func (e *Exporter) export(span *Span) {
+	select {
+	case e.queue <- span:
+	default:
+		e.Log.V(1).Info("span queue is full, dropping span", "span", span.name)
+	}
+}
+
+// Start implements manager.Runnable, exporting queued spans until stop is
+// closed.
+func (e *Exporter) Start(stop <-chan struct{}) error {
+	ticker := time.NewTicker(DefaultFlushInterval)
+	defer ticker.Stop()
+
+	var batch []*Span
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := e.send(batch); err != nil {
+			e.Log.Error(err, "failed to export spans", "count", len(batch))
+		}
+		batch = nil
+	}
+
+	for {
+		select {
+		case span := <-e.queue:
+			batch = append(batch, span)
+			if len(batch) >= DefaultBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-stop:
+			flush()
+			return nil
+		}
+	}
+}
+
+func (e *Exporter) send(spans []*Span) error {
+	body, err := json.Marshal(e.makeRequest(spans))
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.Endpoint+"/v1/traces", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected OTLP response status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// OTLP JSON encoding of ExportTraceServiceRequest, see
+// https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+const otlpStatusCodeError = 2
+
+func (e *Exporter) makeRequest(spans []*Span) otlpRequest {
+	var otlpSpans []otlpSpan
+	for _, s := range spans {
+		s.lock.Lock()
+		span := otlpSpan{
+			TraceID:           hex.EncodeToString(s.traceID[:]),
+			SpanID:            hex.EncodeToString(s.spanID[:]),
+			Name:              s.name,
+			Kind:              int(s.kind),
+			StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+		}
+		if s.parentID != ([8]byte{}) {
+			span.ParentSpanID = hex.EncodeToString(s.parentID[:])
+		}
+		for _, attr := range s.attributes {
+			span.Attributes = append(span.Attributes, makeAttribute(attr.Key, attr.Value))
+		}
+		if s.err != nil {
+			span.Status = otlpStatus{Code: otlpStatusCodeError, Message: s.err.Error()}
+		}
+		s.lock.Unlock()
+		otlpSpans = append(otlpSpans, span)
+	}
+
+	return otlpRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{
+				Attributes: []otlpAttribute{makeAttribute("service.name", e.ServiceName)},
+			},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: "github.com/skygeario/k8s-controller"},
+				Spans: otlpSpans,
+			}},
+		}},
+	}
+}
+
+func makeAttribute(key, value string) otlpAttribute {
+	return otlpAttribute{Key: key, Value: otlpValue{StringValue: value}}
+}
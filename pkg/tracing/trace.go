@@ -0,0 +1,149 @@
+// Package tracing traces reconciliations and external calls, and exports
+// spans to OpenTelemetry collectors using OTLP/HTTP with JSON encoding.
+//
+// The API follows OpenTelemetry, so that it can be replaced by the
+// OpenTelemetry SDK when dependencies of the controller allow.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	mathrand "math/rand"
+	"sync"
+	"time"
+)
+
+// Attribute is a string attribute of span.
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+// String returns a string attribute.
+func String(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// SpanKind is the kind of span, as defined by OpenTelemetry.
+type SpanKind int
+
+const (
+	SpanKindInternal SpanKind = 1
+	SpanKindClient   SpanKind = 3
+)
+
+// Span is an operation in a trace. Methods of nil span are no-op, so that
+// callers need not check whether tracing is enabled.
+type Span struct {
+	traceID  [16]byte
+	spanID   [8]byte
+	parentID [8]byte
+	sampled  bool
+	exporter *Exporter
+
+	lock       sync.Mutex
+	name       string
+	kind       SpanKind
+	start      time.Time
+	end        time.Time
+	attributes []Attribute
+	err        error
+}
+
+type spanKey struct{}
+
+var (
+	globalLock     sync.RWMutex
+	globalExporter *Exporter
+	sampleRatio    = 1.0
+)
+
+// SetExporter sets the exporter of spans, and the ratio of traces sampled.
+// Spans are not created before exporter is set.
+func SetExporter(exporter *Exporter, ratio float64) {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+	globalExporter = exporter
+	sampleRatio = ratio
+}
+
+// SetSampleRatio sets the ratio of traces sampled.
+func SetSampleRatio(ratio float64) {
+	globalLock.Lock()
+	defer globalLock.Unlock()
+	sampleRatio = ratio
+}
+
+// SpanFromContext returns the current span in the context, or nil if none.
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// Start starts a span as child of current span in the context, and returns
+// a context with the new span as current span.
+func Start(ctx context.Context, name string, kind SpanKind, attrs ...Attribute) (context.Context, *Span) {
+	globalLock.RLock()
+	exporter, ratio := globalExporter, sampleRatio
+	globalLock.RUnlock()
+	if exporter == nil {
+		return ctx, nil
+	}
+
+	span := &Span{
+		exporter:   exporter,
+		name:       name,
+		kind:       kind,
+		start:      time.Now(),
+		attributes: attrs,
+	}
+	if parent := SpanFromContext(ctx); parent != nil {
+		span.traceID = parent.traceID
+		span.parentID = parent.spanID
+		span.sampled = parent.sampled
+	} else {
+		_, _ = rand.Read(span.traceID[:])
+		span.sampled = mathrand.Float64() < ratio
+	}
+	_, _ = rand.Read(span.spanID[:])
+
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// SetAttributes adds attributes to the span.
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.attributes = append(s.attributes, attrs...)
+}
+
+// RecordError marks the span as failed with the error, if not nil.
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.err = err
+}
+
+// End ends the span, and exports it if sampled.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	if !s.end.IsZero() {
+		s.lock.Unlock()
+		return
+	}
+	s.end = time.Now()
+	s.lock.Unlock()
+
+	if s.sampled {
+		s.exporter.export(s)
+	}
+}
@@ -0,0 +1,20 @@
+package bearer
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+const prefix = "Bearer "
+
+// Authenticate returns whether the Authorization header of request carries
+// the bearer token. Requests are never authenticated if token is empty.
+func Authenticate(r *http.Request, token string) bool {
+	auth := r.Header.Get("Authorization")
+	if token == "" || !strings.HasPrefix(auth, prefix) {
+		return false
+	}
+	given := strings.TrimPrefix(auth, prefix)
+	return subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1
+}
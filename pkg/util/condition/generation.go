@@ -0,0 +1,9 @@
+package condition
+
+import "github.com/skygeario/k8s-controller/api"
+
+func SetObservedGeneration(conds []api.Condition, generation int64) {
+	for i := range conds {
+		conds[i].ObservedGeneration = generation
+	}
+}
@@ -1,6 +1,10 @@
 package condition
 
-import "github.com/skygeario/k8s-controller/api"
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/skygeario/k8s-controller/api"
+)
 
 func Lookup(conds []api.Condition, condType string) *api.Condition {
 	for _, cond := range conds {
@@ -10,3 +14,8 @@ func Lookup(conds []api.Condition, condType string) *api.Condition {
 	}
 	return nil
 }
+
+func IsTrue(conds []api.Condition, condType string) bool {
+	cond := Lookup(conds, condType)
+	return cond != nil && cond.Status == metav1.ConditionTrue
+}
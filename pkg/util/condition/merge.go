@@ -6,29 +6,37 @@ import (
 	"github.com/skygeario/k8s-controller/api"
 )
 
+// MergeFrom sets last transition time of the new conditions from the old
+// conditions. It is kept unless status of the condition changes. Reason and
+// message are not merged: the new conditions describe the current state
+// fully. Reason is required, and conditions without reason are given the
+// default reason of their status.
 func MergeFrom(newConds, oldConds []api.Condition) {
 	for i, cond := range newConds {
-		updated := false
-		for _, old := range oldConds {
-			if old.Type != cond.Type {
-				continue
-			}
+		if cond.Reason == "" {
+			cond.Reason = DefaultReason(cond.Type, cond.Status)
+		}
 
-			if cond.Status != old.Status {
-				cond.LastTransitionTime = metav1.Now()
-			} else {
+		cond.LastTransitionTime = metav1.Now()
+		for _, old := range oldConds {
+			if old.Type == cond.Type && old.Status == cond.Status {
 				cond.LastTransitionTime = old.LastTransitionTime
-				if cond.Message == "" {
-					cond.Message = old.Message
-					cond.Reason = old.Reason
-				}
+				break
 			}
-			updated = true
-			break
-		}
-		if !updated {
-			cond.LastTransitionTime = metav1.Now()
 		}
 		newConds[i] = cond
 	}
 }
+
+// DefaultReason returns the reason of condition without specific reason,
+// e.g. NotVerified for condition Verified with status False.
+func DefaultReason(condType string, status metav1.ConditionStatus) string {
+	switch status {
+	case metav1.ConditionTrue:
+		return condType
+	case metav1.ConditionFalse:
+		return "Not" + condType
+	default:
+		return condType + "Unknown"
+	}
+}
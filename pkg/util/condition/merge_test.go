@@ -0,0 +1,80 @@
+package condition
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/skygeario/k8s-controller/api"
+)
+
+func TestMergeFrom(t *testing.T) {
+	past := metav1.NewTime(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))
+	old := []api.Condition{{
+		Type:               "Verified",
+		Status:             metav1.ConditionFalse,
+		LastTransitionTime: past,
+		Reason:             "RecordNotFound",
+		Message:            "verification DNS record not found",
+	}}
+
+	tests := []struct {
+		name          string
+		cond          api.Condition
+		expected      api.Condition
+		keepsPastTime bool
+	}{
+		{
+			name:          "status unchanged",
+			cond:          api.Condition{Type: "Verified", Status: metav1.ConditionFalse, Reason: "Timeout", Message: "timed out"},
+			expected:      api.Condition{Type: "Verified", Status: metav1.ConditionFalse, Reason: "Timeout", Message: "timed out"},
+			keepsPastTime: true,
+		},
+		{
+			name:          "status unchanged without reason and message",
+			cond:          api.Condition{Type: "Verified", Status: metav1.ConditionFalse},
+			expected:      api.Condition{Type: "Verified", Status: metav1.ConditionFalse, Reason: "NotVerified"},
+			keepsPastTime: true,
+		},
+		{
+			name:     "status changed",
+			cond:     api.Condition{Type: "Verified", Status: metav1.ConditionTrue, Reason: "DefaultDomain"},
+			expected: api.Condition{Type: "Verified", Status: metav1.ConditionTrue, Reason: "DefaultDomain"},
+		},
+		{
+			name:     "status changed without reason",
+			cond:     api.Condition{Type: "Verified", Status: metav1.ConditionTrue},
+			expected: api.Condition{Type: "Verified", Status: metav1.ConditionTrue, Reason: "Verified"},
+		},
+		{
+			name:     "status changed to unknown",
+			cond:     api.Condition{Type: "Verified", Status: metav1.ConditionUnknown, Message: "error"},
+			expected: api.Condition{Type: "Verified", Status: metav1.ConditionUnknown, Reason: "VerifiedUnknown", Message: "error"},
+		},
+		{
+			name:     "new condition",
+			cond:     api.Condition{Type: "Accepted", Status: metav1.ConditionFalse, Reason: "PendingApproval"},
+			expected: api.Condition{Type: "Accepted", Status: metav1.ConditionFalse, Reason: "PendingApproval"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := time.Now().Add(-time.Second)
+			conds := []api.Condition{tt.cond}
+			MergeFrom(conds, old)
+
+			cond := conds[0]
+			if tt.keepsPastTime && !cond.LastTransitionTime.Equal(&past) {
+				t.Errorf("LastTransitionTime = %v, expected %v", cond.LastTransitionTime, past)
+			} else if !tt.keepsPastTime && cond.LastTransitionTime.Time.Before(before) {
+				t.Errorf("LastTransitionTime = %v, expected now", cond.LastTransitionTime)
+			}
+
+			cond.LastTransitionTime = metav1.Time{}
+			if cond != tt.expected {
+				t.Errorf("MergeFrom = %#v, expected %#v", cond, tt.expected)
+			}
+		})
+	}
+}
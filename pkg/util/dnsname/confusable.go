@@ -0,0 +1,69 @@
+package dnsname
+
+import (
+	"strings"
+	"unicode"
+)
+
+// confusables maps characters to the Latin letters they are visually
+// confusable with. It is a subset of the Unicode confusables table (UTS #39)
+// covering characters commonly used to spoof Latin domain names, with
+// accented Latin letters folded to their base letters.
+var confusables = map[rune]string{
+	// Digits
+	'0': "o", '1': "l",
+	// Latin letters with diacritics
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ă': "a", 'ą': "a",
+	'ç': "c", 'ć': "c", 'č': "c",
+	'ď': "d", 'đ': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ė': "e", 'ę': "e", 'ě': "e",
+	'ğ': "g", 'ģ': "g",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ī': "i", 'į': "i", 'ı': "i",
+	'ķ': "k",
+	'ĺ': "l", 'ļ': "l", 'ľ': "l", 'ł': "l",
+	'ñ': "n", 'ń': "n", 'ņ': "n", 'ň': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o", 'ő': "o",
+	'ŕ': "r", 'ř': "r",
+	'ś': "s", 'ş': "s", 'š': "s",
+	'ţ': "t", 'ť': "t",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ū': "u", 'ů': "u", 'ű': "u", 'ų': "u",
+	'ý': "y", 'ÿ': "y",
+	'ź': "z", 'ż': "z", 'ž': "z",
+	// Latin letters of IPA and other extensions
+	'ɑ': "a", 'ɡ': "g", 'ɩ': "i", 'ɪ': "i", 'ʟ': "l", 'ɴ': "n", 'ɵ': "o", 'ʀ': "r", 'ʏ': "y",
+	// Greek
+	'α': "a", 'β': "b", 'γ': "y", 'ι': "i", 'κ': "k", 'ν': "v", 'ο': "o", 'ρ': "p",
+	'τ': "t", 'υ': "u", 'χ': "x", 'ω': "w",
+	// Cyrillic
+	'а': "a", 'в': "b", 'г': "r", 'е': "e", 'ё': "e", 'һ': "h", 'і': "i", 'ї': "i",
+	'ј': "j", 'к': "k", 'ӏ': "l", 'м': "m", 'н': "h", 'о': "o", 'п': "n", 'р': "p",
+	'с': "c", 'ѕ': "s", 'т': "t", 'у': "y", 'х': "x", 'ԁ': "d", 'ԛ': "q", 'ԝ': "w",
+	'ь': "b",
+}
+
+// confusableSequences are sequences of Latin letters confusable with a
+// single letter, replaced in skeletons so that both forms compare equal.
+var confusableSequences = strings.NewReplacer("m", "rn", "w", "vv", "d", "cl")
+
+// Skeleton returns the form of the label used to detect visually confusable
+// labels, in the spirit of the skeleton of UTS #39: labels are confusable
+// if they have the same skeleton.
+func Skeleton(label string) string {
+	var b strings.Builder
+	for _, r := range label {
+		r = unicode.ToLower(r)
+		if s, ok := confusables[r]; ok {
+			b.WriteString(s)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return confusableSequences.Replace(b.String())
+}
+
+// Confusable reports whether the labels are different but visually
+// confusable with each other.
+func Confusable(a, b string) bool {
+	a, b = strings.ToLower(a), strings.ToLower(b)
+	return a != b && Skeleton(a) == Skeleton(b)
+}
@@ -0,0 +1,23 @@
+package dnsname
+
+import "testing"
+
+func TestConfusable(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected bool
+	}{
+		{"paypal", "pаypal", true}, // Cyrillic а
+		{"google", "g00gle", true},
+		{"microsoft", "rnicrosoft", true},
+		{"cafe", "café", true},
+		{"example", "example", false},
+		{"Example", "example", false},
+		{"apple", "banana", false},
+	}
+	for _, tt := range tests {
+		if confusable := Confusable(tt.a, tt.b); confusable != tt.expected {
+			t.Errorf("Confusable(%q, %q) = %t, expected %t", tt.a, tt.b, confusable, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package dnsname
+
+import (
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// Normalize converts the domain name to its lowercase ASCII (punycode) form.
+func Normalize(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	if IsWildcard(name) {
+		parent, err := idna.Lookup.ToASCII(TrimWildcard(name))
+		if err != nil {
+			return "", err
+		}
+		return wildcardPrefix + parent, nil
+	}
+	return idna.Lookup.ToASCII(name)
+}
+
+// ToUnicode converts the domain name to its Unicode form.
+func ToUnicode(name string) (string, error) {
+	if IsWildcard(name) {
+		parent, err := idna.Lookup.ToUnicode(TrimWildcard(name))
+		if err != nil {
+			return "", err
+		}
+		return wildcardPrefix + parent, nil
+	}
+	return idna.Lookup.ToUnicode(name)
+}
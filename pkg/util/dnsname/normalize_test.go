@@ -0,0 +1,52 @@
+package dnsname
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		err      bool
+	}{
+		{"example.com", "example.com", false},
+		{"Example.COM.", "example.com", false},
+		{"bücher.example", "xn--bcher-kva.example", false},
+		{"BÜCHER.example", "xn--bcher-kva.example", false},
+		{"*.bücher.example", "*.xn--bcher-kva.example", false},
+		{"xn--bcher-kva.example", "xn--bcher-kva.example", false},
+		{"exa mple.com", "", true},
+	}
+	for _, tt := range tests {
+		name, err := Normalize(tt.name)
+		if tt.err {
+			if err == nil {
+				t.Errorf("Normalize(%q) = %q, expected error", tt.name, name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Normalize(%q): unexpected error: %v", tt.name, err)
+		} else if name != tt.expected {
+			t.Errorf("Normalize(%q) = %q, expected %q", tt.name, name, tt.expected)
+		}
+	}
+}
+
+func TestToUnicode(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"example.com", "example.com"},
+		{"xn--bcher-kva.example", "bücher.example"},
+		{"*.xn--bcher-kva.example", "*.bücher.example"},
+	}
+	for _, tt := range tests {
+		name, err := ToUnicode(tt.name)
+		if err != nil {
+			t.Errorf("ToUnicode(%q): unexpected error: %v", tt.name, err)
+		} else if name != tt.expected {
+			t.Errorf("ToUnicode(%q) = %q, expected %q", tt.name, name, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package dnsname
+
+import (
+	"golang.org/x/net/publicsuffix"
+)
+
+const wwwPrefix = "www."
+
+// Sibling returns the www subdomain of an apex domain, or the apex domain of
+// its www subdomain. Other domain names have no sibling.
+func Sibling(name string) (string, bool) {
+	if IsWildcard(name) {
+		return "", false
+	}
+	apex, err := publicsuffix.EffectiveTLDPlusOne(name)
+	if err != nil {
+		return "", false
+	}
+	switch name {
+	case apex:
+		return wwwPrefix + apex, true
+	case wwwPrefix + apex:
+		return apex, true
+	}
+	return "", false
+}
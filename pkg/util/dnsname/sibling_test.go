@@ -0,0 +1,25 @@
+package dnsname
+
+import "testing"
+
+func TestSibling(t *testing.T) {
+	tests := []struct {
+		name     string
+		sibling  string
+		expected bool
+	}{
+		{"example.com", "www.example.com", true},
+		{"www.example.com", "example.com", true},
+		{"example.co.uk", "www.example.co.uk", true},
+		{"api.example.com", "", false},
+		{"www.www.example.com", "", false},
+		{"*.example.com", "", false},
+		{"com", "", false},
+	}
+	for _, tt := range tests {
+		sibling, ok := Sibling(tt.name)
+		if sibling != tt.sibling || ok != tt.expected {
+			t.Errorf("Sibling(%q) = %q, %t, expected %q, %t", tt.name, sibling, ok, tt.sibling, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package dnsname
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// MaxLength is the maximum length of a domain name in text form,
+	// without the trailing dot.
+	MaxLength = 253
+	// MaxLabelLength is the maximum length of a label of domain name.
+	MaxLabelLength = 63
+)
+
+// Validate checks the normalized domain name follows the syntax of RFC 1035
+// host names: letters, digits and hyphens in labels of at most 63
+// characters, without leading or trailing hyphens, and at most 253
+// characters in total. A wildcard is allowed as the first label.
+func Validate(name string) error {
+	return validate(name, false)
+}
+
+// ValidateRecordName checks the name of DNS record as Validate, but also
+// allows underscores in labels with leading underscore, such as the
+// verification labels.
+func ValidateRecordName(name string) error {
+	return validate(name, true)
+}
+
+func validate(name string, allowUnderscore bool) error {
+	if name == "" {
+		return fmt.Errorf("domain name is empty")
+	}
+	if len(name) > MaxLength {
+		return fmt.Errorf("domain name is longer than %d characters", MaxLength)
+	}
+
+	for _, label := range strings.Split(TrimWildcard(name), ".") {
+		if err := validateLabel(label, allowUnderscore); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateLabel(label string, allowUnderscore bool) error {
+	if label == "" {
+		return fmt.Errorf("domain name contains empty label")
+	}
+	if len(label) > MaxLabelLength {
+		return fmt.Errorf("label %q is longer than %d characters", label, MaxLabelLength)
+	}
+	if label == "*" {
+		return fmt.Errorf("wildcard is only allowed as the first label")
+	}
+
+	// Underscore labels are service or verification labels, e.g.
+	// _skygear-challenge, and are not host names.
+	underscoreLabel := allowUnderscore && strings.HasPrefix(label, "_")
+	if label[0] == '-' || label[len(label)-1] == '-' {
+		return fmt.Errorf("label %q cannot start or end with hyphen", label)
+	}
+	for _, c := range label {
+		switch {
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '-':
+		case c == '_':
+			if !underscoreLabel {
+				return fmt.Errorf("label %q cannot contain underscore", label)
+			}
+		default:
+			return fmt.Errorf("label %q contains invalid character %q", label, c)
+		}
+	}
+	return nil
+}
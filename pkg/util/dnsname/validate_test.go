@@ -0,0 +1,47 @@
+package dnsname
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	label := func(c string, n int) string { return strings.Repeat(c, n) }
+	// 63 * 3 + 61 + 3 dots = 253 characters
+	longest := label("a", 63) + "." + label("b", 63) + "." + label("c", 63) + "." + label("d", 61)
+
+	tests := []struct {
+		name       string
+		valid      bool
+		validAsRec bool
+	}{
+		{"example.com", true, true},
+		{"*.example.com", true, true},
+		{"xn--bcher-kva.example", true, true},
+		{"a-b.example.com", true, true},
+		{label("a", 63) + ".com", true, true},
+		{label("a", 64) + ".com", false, false},
+		{longest, true, true},
+		{longest + "d", false, false},
+		{"-a.example.com", false, false},
+		{"a-.example.com", false, false},
+		{"example.-com", false, false},
+		{"a..example.com", false, false},
+		{"", false, false},
+		{"a.*.example.com", false, false},
+		{"_skygear-challenge.example.com", false, true},
+		{"_acme-challenge.*.example.com", false, false},
+		{"a_b.example.com", false, false},
+		{"_a-.example.com", false, false},
+		{"exa mple.com", false, false},
+		{"Example.com", false, false},
+	}
+	for _, tt := range tests {
+		if err := Validate(tt.name); (err == nil) != tt.valid {
+			t.Errorf("Validate(%q): expected valid = %v, got %v", tt.name, tt.valid, err)
+		}
+		if err := ValidateRecordName(tt.name); (err == nil) != tt.validAsRec {
+			t.Errorf("ValidateRecordName(%q): expected valid = %v, got %v", tt.name, tt.validAsRec, err)
+		}
+	}
+}
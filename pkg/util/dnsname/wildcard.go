@@ -0,0 +1,47 @@
+package dnsname
+
+import (
+	"strings"
+)
+
+const wildcardPrefix = "*."
+
+// wildcardResourceLabel replaces the wildcard label in resource names, since
+// '*' is not allowed in resource names. Labels with hyphens in the 3rd and 4th
+// positions are reserved, so it would not collide with a real domain label.
+const wildcardResourceLabel = "zz--wildcard"
+
+// IsWildcard reports whether the domain name is a wildcard domain name.
+func IsWildcard(name string) bool {
+	return strings.HasPrefix(name, wildcardPrefix)
+}
+
+// TrimWildcard returns the domain name without the wildcard label.
+func TrimWildcard(name string) string {
+	return strings.TrimPrefix(name, wildcardPrefix)
+}
+
+// Parent returns the domain name without the first label.
+func Parent(name string) string {
+	i := strings.Index(name, ".")
+	if i < 0 {
+		return ""
+	}
+	return name[i+1:]
+}
+
+// ResourceName returns the resource name representing the domain name.
+func ResourceName(name string) string {
+	if IsWildcard(name) {
+		return wildcardResourceLabel + "." + TrimWildcard(name)
+	}
+	return name
+}
+
+// DomainName returns the domain name represented by the resource name.
+func DomainName(resourceName string) string {
+	if strings.HasPrefix(resourceName, wildcardResourceLabel+".") {
+		return wildcardPrefix + strings.TrimPrefix(resourceName, wildcardResourceLabel+".")
+	}
+	return resourceName
+}
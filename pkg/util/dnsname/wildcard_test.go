@@ -0,0 +1,39 @@
+package dnsname
+
+import "testing"
+
+func TestResourceName(t *testing.T) {
+	tests := []struct {
+		domainName   string
+		resourceName string
+	}{
+		{"example.com", "example.com"},
+		{"*.example.com", "zz--wildcard.example.com"},
+		{"www.example.com", "www.example.com"},
+	}
+	for _, tt := range tests {
+		if name := ResourceName(tt.domainName); name != tt.resourceName {
+			t.Errorf("ResourceName(%q) = %q, expected %q", tt.domainName, name, tt.resourceName)
+		}
+		if name := DomainName(tt.resourceName); name != tt.domainName {
+			t.Errorf("DomainName(%q) = %q, expected %q", tt.resourceName, name, tt.domainName)
+		}
+	}
+}
+
+func TestParent(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"www.example.com", "example.com"},
+		{"*.example.com", "example.com"},
+		{"example.com", "com"},
+		{"com", ""},
+	}
+	for _, tt := range tests {
+		if parent := Parent(tt.name); parent != tt.expected {
+			t.Errorf("Parent(%q) = %q, expected %q", tt.name, parent, tt.expected)
+		}
+	}
+}
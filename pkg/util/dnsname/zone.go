@@ -0,0 +1,16 @@
+package dnsname
+
+import (
+	"golang.org/x/net/publicsuffix"
+)
+
+// Zone returns the registrable domain (public suffix plus one label) that
+// the domain name is under, or empty string if the domain name is itself a
+// public suffix.
+func Zone(name string) string {
+	zone, err := publicsuffix.EffectiveTLDPlusOne(TrimWildcard(name))
+	if err != nil {
+		return ""
+	}
+	return zone
+}
@@ -0,0 +1,23 @@
+package dnsname
+
+import "testing"
+
+func TestZone(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"example.com", "example.com"},
+		{"www.example.com", "example.com"},
+		{"*.example.com", "example.com"},
+		{"a.b.example.co.uk", "example.co.uk"},
+		{"app.appspot.com", "app.appspot.com"},
+		{"co.uk", ""},
+		{"com", ""},
+	}
+	for _, tt := range tests {
+		if zone := Zone(tt.name); zone != tt.expected {
+			t.Errorf("Zone(%q) = %q, expected %q", tt.name, zone, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package dryrun
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Client performs writes in server-side dry-run mode, so that writes are
+// validated by API server but not persisted. Intended writes are logged.
+type Client struct {
+	client.Client
+	Log logr.Logger
+}
+
+func NewClient(c client.Client, log logr.Logger) *Client {
+	return &Client{Client: c, Log: log}
+}
+
+var _ client.Client = &Client{}
+
+func (c *Client) Create(ctx context.Context, obj runtime.Object, opts ...client.CreateOption) error {
+	logWrite(c.Log, "create", obj)
+	return c.Client.Create(ctx, obj, append(opts, client.DryRunAll)...)
+}
+
+func (c *Client) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	logWrite(c.Log, "update", obj)
+	return c.Client.Update(ctx, obj, append(opts, client.DryRunAll)...)
+}
+
+func (c *Client) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	logPatch(c.Log, "patch", obj, patch)
+	return c.Client.Patch(ctx, obj, patch, append(opts, client.DryRunAll)...)
+}
+
+func (c *Client) Delete(ctx context.Context, obj runtime.Object, opts ...client.DeleteOption) error {
+	logWrite(c.Log, "delete", obj)
+	return c.Client.Delete(ctx, obj, append(opts, client.DryRunAll)...)
+}
+
+func (c *Client) Status() client.StatusWriter {
+	return &statusWriter{StatusWriter: c.Client.Status(), log: c.Log}
+}
+
+type statusWriter struct {
+	client.StatusWriter
+	log logr.Logger
+}
+
+func (w *statusWriter) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	logWrite(w.log, "update status", obj)
+	return w.StatusWriter.Update(ctx, obj, append(opts, client.DryRunAll)...)
+}
+
+func (w *statusWriter) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	logPatch(w.log, "patch status", obj, patch)
+	return w.StatusWriter.Patch(ctx, obj, patch, append(opts, client.DryRunAll)...)
+}
+
+func describe(obj runtime.Object) []interface{} {
+	kv := []interface{}{"type", fmt.Sprintf("%T", obj)}
+	if accessor, err := meta.Accessor(obj); err == nil {
+		kv = append(kv, "namespace", accessor.GetNamespace(), "name", accessor.GetName())
+	}
+	return kv
+}
+
+func logWrite(log logr.Logger, action string, obj runtime.Object) {
+	log.Info("dry-run: "+action, describe(obj)...)
+}
+
+func logPatch(log logr.Logger, action string, obj runtime.Object, patch client.Patch) {
+	kv := describe(obj)
+	if data, err := patch.Data(obj); err == nil {
+		kv = append(kv, "patch", string(data))
+	}
+	log.Info("dry-run: "+action, kv...)
+}
@@ -0,0 +1,34 @@
+package httpserver
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Run serves requests with server until stop is closed, and then shuts down
+// the server gracefully. It is the body of manager.Runnable of HTTP servers.
+// Shutdown waits for in-flight requests up to shutdownTimeout, or without
+// limit if shutdownTimeout is zero. Long-lived requests should be ended by
+// functions registered with server.RegisterOnShutdown.
+func Run(server *http.Server, stop <-chan struct{}, shutdownTimeout time.Duration) error {
+	errCh := make(chan error, 1)
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			errCh <- err
+		}
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-stop:
+		ctx := context.Background()
+		if shutdownTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, shutdownTimeout)
+			defer cancel()
+		}
+		return server.Shutdown(ctx)
+	}
+}
@@ -0,0 +1,99 @@
+// Package netguard restricts outgoing connections to hosts chosen by
+// tenants, so that they cannot be used to reach internal addresses of the
+// cluster or the cloud provider.
+package netguard
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// ErrBlockedAddress is returned when connecting to a blocked address.
+var ErrBlockedAddress = errors.New("address is not allowed")
+
+// DefaultBlockedCIDRs are the non-public address ranges: loopback,
+// link-local (including cloud metadata endpoints), private, shared and
+// reserved addresses.
+var DefaultBlockedCIDRs = []string{
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.0.0.0/24",
+	"192.168.0.0/16",
+	"198.18.0.0/15",
+	"224.0.0.0/4",
+	"240.0.0.0/4",
+	"::/128",
+	"::1/128",
+	"fc00::/7",
+	"fe80::/10",
+	"ff00::/8",
+}
+
+// Guard rejects connections to blocked addresses.
+type Guard struct {
+	Blocked []*net.IPNet
+}
+
+// New returns a guard blocking the default CIDRs and the additional CIDRs,
+// e.g. pod and service CIDRs of the cluster.
+func New(cidrs []string) (*Guard, error) {
+	g := &Guard{}
+	for _, cidr := range append(DefaultBlockedCIDRs, cidrs...) {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q: %w", cidr, err)
+		}
+		g.Blocked = append(g.Blocked, ipNet)
+	}
+	return g, nil
+}
+
+// Allowed returns whether connecting to the IP is allowed.
+func (g *Guard) Allowed(ip net.IP) bool {
+	for _, ipNet := range g.Blocked {
+		if ipNet.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// Control implements net.Dialer.Control. It is called with the resolved
+// address of each connection, so that names resolving to blocked addresses
+// are rejected.
+func (g *Guard) Control(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !g.Allowed(ip) {
+		return fmt.Errorf("cannot connect to %s: %w", host, ErrBlockedAddress)
+	}
+	return nil
+}
+
+// HTTPClient returns a HTTP client connecting to allowed addresses only,
+// resolving hosts using resolver if not nil. Proxies are not used, as
+// connections to proxies cannot be checked. Redirects are followed, as
+// every hop is dialed through the guard.
+func (g *Guard) HTTPClient(resolver *net.Resolver) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Resolver:  resolver,
+		Control:   g.Control,
+	}
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Transport: transport}
+}
@@ -0,0 +1,83 @@
+package netguard
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAllowed(t *testing.T) {
+	g, err := New([]string{"203.0.113.0/24"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		ip      string
+		allowed bool
+	}{
+		{"8.8.8.8", true},
+		{"2001:4860:4860::8888", true},
+		{"127.0.0.1", false},
+		{"169.254.169.254", false},
+		{"10.96.0.1", false},
+		{"172.20.0.1", false},
+		{"192.168.1.1", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"::1", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:169.254.169.254", false},
+		{"fd00::1", false},
+		{"fe80::1", false},
+		{"203.0.113.10", false},
+	}
+	for _, tt := range tests {
+		if allowed := g.Allowed(net.ParseIP(tt.ip)); allowed != tt.allowed {
+			t.Errorf("Allowed(%s) = %v, expected %v", tt.ip, allowed, tt.allowed)
+		}
+	}
+}
+
+func TestNewInvalidCIDR(t *testing.T) {
+	if _, err := New([]string{"10.0.0.0"}); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestHTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	g, err := New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = g.HTTPClient(nil).Get(server.URL)
+	if !errors.Is(err, ErrBlockedAddress) {
+		t.Errorf("expected blocked address error, got %v", err)
+	}
+}
+
+func TestHTTPClientRedirect(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+	defer target.Close()
+	// Redirect to another loopback address, which is blocked
+	location := strings.Replace(target.URL, "127.0.0.1", "127.0.0.2", 1)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		http.Redirect(rw, r, location, http.StatusFound)
+	}))
+	defer server.Close()
+
+	_, blocked, _ := net.ParseCIDR("127.0.0.2/32")
+	g := &Guard{Blocked: []*net.IPNet{blocked}}
+	req, _ := http.NewRequestWithContext(context.Background(), "GET", server.URL, nil)
+	_, err := g.HTTPClient(nil).Do(req)
+	if !errors.Is(err, ErrBlockedAddress) {
+		t.Errorf("expected blocked address error, got %v", err)
+	}
+}
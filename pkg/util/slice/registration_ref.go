@@ -0,0 +1,30 @@
+package slice
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	domainv1beta1 "github.com/skygeario/k8s-controller/api/v1beta1"
+)
+
+func FindRegistrationReference(slice []domainv1beta1.CustomDomainRegistrationReference, m metav1.Object) *domainv1beta1.CustomDomainRegistrationReference {
+	for i, elem := range slice {
+		if elem.UID == m.GetUID() {
+			return &slice[i]
+		}
+	}
+	return nil
+}
+
+func ContainsRegistrationReference(slice []domainv1beta1.CustomDomainRegistrationReference, m metav1.Object) bool {
+	return FindRegistrationReference(slice, m) != nil
+}
+
+func RemoveRegistrationReference(slice []domainv1beta1.CustomDomainRegistrationReference, m metav1.Object) []domainv1beta1.CustomDomainRegistrationReference {
+	newSlice := []domainv1beta1.CustomDomainRegistrationReference{}
+	for _, elem := range slice {
+		if elem.UID != m.GetUID() {
+			newSlice = append(newSlice, elem)
+		}
+	}
+	return newSlice
+}